@@ -1,6 +1,7 @@
 package v1
 
 import (
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -89,8 +90,258 @@ type ElasticsearchSpec struct {
 	// +nullable
 	// +optional
 	IndexManagement *IndexManagementSpec `json:"indexManagement"`
+
+	// Discovery configures how the Elasticsearch nodes find each other
+	//
+	// +nullable
+	// +optional
+	Discovery *ElasticsearchDiscoverySpec `json:"discovery,omitempty"`
+
+	// Allow the operator to delete and recreate a node deployment that can no longer
+	// be updated, e.g. because an immutable field like the selector was changed
+	//
+	// +optional
+	AllowRecreateOnImmutableError bool `json:"allowRecreateOnImmutableError,omitempty"`
+
+	// Services configures the services of the Elasticsearch cluster per role
+	//
+	// +nullable
+	// +optional
+	Services *ElasticsearchServicesSpec `json:"services,omitempty"`
+
+	// The time to wait after a full cluster restart for the expected nodes to join before
+	// the shards are recovered, as an Elasticsearch time value, e.g. 5m. Defaults to 5m
+	//
+	// +optional
+	RecoverAfterTime string `json:"recoverAfterTime,omitempty"`
+
+	// A custom log4j2.properties used verbatim instead of the one rendered by the
+	// operator. Must define the rootLogger
+	//
+	// +nullable
+	// +optional
+	Log4j2Properties *string `json:"log4j2Properties,omitempty"`
+
+	// The maximum number of pending cluster tasks for a rollout to restart the next
+	// node, so that restarts do not pile up work on a busy master. Defaults to 5
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxPendingTasks *int32 `json:"maxPendingTasks,omitempty"`
+
+	// The number of routing shards of new indices, i.e. index.number_of_routing_shards of
+	// the index management templates, allowing to split them later. Must be a multiple of
+	// the number of primary shards, otherwise it is not set and reported by a condition
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +nullable
+	// +optional
+	RoutingShards *int32 `json:"routingShards,omitempty"`
+
+	// The Elasticsearch cluster name, i.e. cluster.name, defaults to the name of the custom
+	// resource, e.g. to match the data of a migrated cluster. The names of the Kubernetes
+	// resources are derived from the custom resource name in any case. The data path depends
+	// on the cluster name, thus changing it starts the nodes without their previous data
+	//
+	// +optional
+	ClusterName string `json:"clusterName,omitempty"`
+
+	// The ingest pipelines provisioned on the cluster. Pipelines changed on the
+	// cluster are reverted to their definition
+	//
+	// +optional
+	IngestPipelines []ElasticsearchIngestPipeline `json:"ingestPipelines,omitempty"`
+
+	// Monitoring configures the ServiceMonitor scraping the metrics of the cluster
+	//
+	// +nullable
+	// +optional
+	Monitoring *ElasticsearchMonitoringSpec `json:"monitoring,omitempty"`
+
+	// The data tiers the shards of the matching indices are allocated to, i.e.
+	// index.routing.allocation.require.data. Each tier must be declared by a data node group
+	// and no index may match more than one pattern
+	//
+	// +optional
+	IndexTiers []ElasticsearchIndexTier `json:"indexTiers,omitempty"`
+
+	// Defer node rollouts until they are approved by annotating the cluster with
+	// elasticsearch.openshift.io/approve-rollout set to the ID of the pending rollout
+	// reported in the status, e.g. in change-controlled environments. The approval holds
+	// until every node of the rollout is updated
+	//
+	// +optional
+	RequireRolloutApproval bool `json:"requireRolloutApproval,omitempty"`
+
+	// Defer node rollouts and cert redeploys until the maintenance window opens. Rollouts
+	// started in the window complete after it closed and changes not rolling the nodes
+	// apply at any time
+	//
+	// +nullable
+	// +optional
+	MaintenanceWindow *ElasticsearchMaintenanceWindow `json:"maintenanceWindow,omitempty"`
+
+	// The keys of the annotations of the cluster copied to the objects the operator
+	// creates for it, e.g. for cost allocation. Keys ending in / select all annotations
+	// with the prefix. Annotations set by the operator take precedence
+	//
+	// +optional
+	PropagatedAnnotations []string `json:"propagatedAnnotations,omitempty"`
+
+	// The index patterns clients may create indices for implicitly, i.e.
+	// action.auto_create_index. Removing it resets the setting to the default of
+	// the configuration
+	//
+	// +nullable
+	// +optional
+	AutoCreateIndex *ElasticsearchAutoCreateIndexSpec `json:"autoCreateIndex,omitempty"`
+}
+
+// ElasticsearchAutoCreateIndexSpec restricts the indices created implicitly by writing
+// documents to them
+type ElasticsearchAutoCreateIndexSpec struct {
+	// The patterns of the indices allowed (+) or denied (-) to be created implicitly, matched
+	// in order, e.g. +app-*, -*. A pattern without a prefix allows. Indices named like the
+	// write aliases, i.e. *-write, are always denied first. Implicit creation is disabled
+	// if empty
+	//
+	// +optional
+	Patterns []string `json:"patterns,omitempty"`
 }
 
+// ElasticsearchMaintenanceWindow is the recurring time window node rollouts may start in
+type ElasticsearchMaintenanceWindow struct {
+	// The days of the week the window opens on. The window opens every day if empty
+	//
+	// +optional
+	Days []ElasticsearchWeekday `json:"days,omitempty"`
+
+	// The time of the day in UTC the window opens at, as HH:MM
+	//
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+
+	// How long the window stays open, e.g. 4h
+	Duration metav1.Duration `json:"duration"`
+}
+
+// ElasticsearchWeekday is a day of the week a maintenance window opens on
+//
+// +kubebuilder:validation:Enum=Monday;Tuesday;Wednesday;Thursday;Friday;Saturday;Sunday
+type ElasticsearchWeekday string
+
+// ElasticsearchIndexTier pins the indices matching a pattern to a data tier
+type ElasticsearchIndexTier struct {
+	// The index pattern, e.g. app-*
+	IndexPattern string `json:"indexPattern"`
+
+	// The data tier the shards of the matching indices are allocated to
+	//
+	// +kubebuilder:validation:Enum=hot;warm;cold
+	Tier ElasticsearchDataTier `json:"tier"`
+}
+
+// ElasticsearchMonitoringSpec represents the configuration of the ServiceMonitor
+type ElasticsearchMonitoringSpec struct {
+	// Additional labels of the ServiceMonitor, e.g. to match the serviceMonitorSelector of
+	// a Prometheus in another namespace. Labels set by the operator take precedence
+	//
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// The labels of the metrics service transferred to the scraped metrics
+	//
+	// +optional
+	TargetLabels []string `json:"targetLabels,omitempty"`
+
+	// The labels of the Elasticsearch pods transferred to the scraped metrics
+	//
+	// +optional
+	PodTargetLabels []string `json:"podTargetLabels,omitempty"`
+}
+
+// ElasticsearchIngestPipeline defines an ingest pipeline of the cluster
+type ElasticsearchIngestPipeline struct {
+	// The name of the pipeline
+	Name string `json:"name"`
+
+	// The JSON definition of the pipeline, i.e. its description and processors
+	Definition string `json:"definition"`
+}
+
+// ElasticsearchServicesSpec represents the configuration of the services per role
+type ElasticsearchServicesSpec struct {
+	// The services used by the nodes to discover each other, i.e. the cluster and
+	// the headless discovery service. Publishes not ready addresses by default
+	//
+	// +optional
+	Discovery *ElasticsearchServiceSpec `json:"discovery,omitempty"`
+
+	// The service used by clients to reach the REST API. Publishes ready addresses only by default
+	//
+	// +optional
+	Client *ElasticsearchServiceSpec `json:"client,omitempty"`
+
+	// The service exposing the metrics to be scraped. Publishes ready addresses only by default
+	//
+	// +optional
+	Metrics *ElasticsearchServiceSpec `json:"metrics,omitempty"`
+}
+
+// ElasticsearchServiceSpec represents the configuration of a service
+type ElasticsearchServiceSpec struct {
+	// Publish the addresses of pods which are not ready yet
+	//
+	// +optional
+	PublishNotReadyAddresses *bool `json:"publishNotReadyAddresses,omitempty"`
+
+	// Additional annotations of the service, e.g. to configure a load balancer or the
+	// metrics scraping. Annotations set by the operator take precedence
+	//
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// The port number of the client service, e.g. for a gateway in front of the cluster.
+	// Defaults to 9200, which the service keeps publishing for the operator and its
+	// components. Not configurable for the other services
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// The name of the port of the client service, e.g. to match it by name. Defaults to
+	// the name of the cluster. Not configurable for the other services
+	//
+	// +optional
+	PortName string `json:"portName,omitempty"`
+}
+
+// ElasticsearchDiscoverySpec represents the seed hosts used by the nodes to discover the cluster
+type ElasticsearchDiscoverySpec struct {
+	// The provider of the operator managed seed host. Defaults to the cluster service
+	//
+	// +optional
+	Provider DiscoveryProviderType `json:"provider,omitempty"`
+
+	// Additional seed hosts as DNS names with an optional port, e.g. es.example.com:9300
+	//
+	// +optional
+	SeedHosts []string `json:"seedHosts,omitempty"`
+}
+
+// DiscoveryProviderType is the provider of the operator managed seed host
+//
+// +kubebuilder:validation:Enum=Service;HeadlessService
+type DiscoveryProviderType string
+
+const (
+	// DiscoveryProviderService resolves the seed host to the virtual IP of the cluster service
+	DiscoveryProviderService DiscoveryProviderType = "Service"
+	// DiscoveryProviderHeadlessService resolves the seed host to all master pods via a headless service
+	DiscoveryProviderHeadlessService DiscoveryProviderType = "HeadlessService"
+)
+
 // ElasticsearchStatus defines the observed state of Elasticsearch
 // +k8s:openapi-gen=true
 type ElasticsearchStatus struct {
@@ -110,6 +361,138 @@ type ElasticsearchStatus struct {
 	Conditions ClusterConditions `json:"conditions,omitempty"`
 	// +optional
 	IndexManagementStatus *IndexManagementStatus `json:"indexManagement,omitempty"`
+	// The progress of replacing node groups by other ones
+	//
+	// +nullable
+	// +optional
+	Replacements []ElasticsearchNodeReplacementStatus `json:"replacements,omitempty"`
+	// The hash of the rendered Elasticsearch configuration restarting the nodes when changed.
+	// The index settings are excluded as they apply without restarting the nodes
+	//
+	// +optional
+	ConfigHash string `json:"configHash,omitempty"`
+	// The node rollout awaiting approval, see requireRolloutApproval
+	//
+	// +optional
+	PendingRollout *ElasticsearchPendingRollout `json:"pendingRollout,omitempty"`
+	// The ID of the node rollout started in the maintenance window, which completes even
+	// if the window closes meanwhile
+	//
+	// +optional
+	WindowRolloutID string `json:"windowRolloutID,omitempty"`
+	// The addresses of the services of the cluster for consumers to connect to
+	//
+	// +optional
+	Endpoints *ElasticsearchEndpointsStatus `json:"endpoints,omitempty"`
+	// The master set growing or shrinking one node at a time towards the requested master count
+	//
+	// +optional
+	MasterTransition *ElasticsearchMasterTransition `json:"masterTransition,omitempty"`
+	// The license of the cluster, if the distribution provides licensing
+	//
+	// +optional
+	License *ElasticsearchLicenseStatus `json:"license,omitempty"`
+	// The time the cluster was first seen with unassigned shards, cleared once they are assigned
+	//
+	// +optional
+	UnassignedShardsSince *metav1.Time `json:"unassignedShardsSince,omitempty"`
+	// The last runs of the index management started on demand, one per policy mapping
+	//
+	// +optional
+	IndexManagementRuns []IndexManagementRunStatus `json:"indexManagementRuns,omitempty"`
+}
+
+// ElasticsearchLicenseStatus is the license the cluster runs with
+type ElasticsearchLicenseStatus struct {
+	// The type of the license, e.g. basic, trial or platinum
+	//
+	// +optional
+	Type string `json:"type,omitempty"`
+	// The status of the license, e.g. active or expired
+	//
+	// +optional
+	Status string `json:"status,omitempty"`
+	// The time the license expires. Unset for licenses not expiring
+	//
+	// +optional
+	ExpiryDate *metav1.Time `json:"expiryDate,omitempty"`
+}
+
+// ElasticsearchMasterTransition is the change of the number of master nodes in progress
+type ElasticsearchMasterTransition struct {
+	// The number of master nodes the cluster runs
+	Current int32 `json:"current"`
+	// The number of master nodes requested by the spec
+	Desired int32 `json:"desired"`
+	// The number of master nodes the cluster ran when the change started. The quorum in
+	// the rendered configuration stays at it until the change completes.
+	//
+	// +optional
+	Initial int32 `json:"initial,omitempty"`
+	// The time the change started
+	Since metav1.Time `json:"since"`
+}
+
+// ElasticsearchEndpointsStatus holds the addresses of the services the operator maintains
+// for the cluster
+// +k8s:openapi-gen=true
+type ElasticsearchEndpointsStatus struct {
+	// The host and transport port of the service the nodes discover the masters through
+	//
+	// +optional
+	DiscoveryService string `json:"discoveryService,omitempty"`
+	// The host and REST port of the service of the client nodes
+	//
+	// +optional
+	ClientService string `json:"clientService,omitempty"`
+	// The HTTPS URL of the service of the client nodes
+	//
+	// +optional
+	ClientServiceHTTPS string `json:"clientServiceHTTPS,omitempty"`
+}
+
+// ElasticsearchPendingRollout is a node rollout deferred until it is approved
+type ElasticsearchPendingRollout struct {
+	// The ID approving the rollout, which changes with the desired state of the nodes
+	ID string `json:"id"`
+	// The time the rollout first became pending
+	Since metav1.Time `json:"since"`
+	// The nodes to roll out and their changes
+	Nodes []ElasticsearchPendingNodeRollout `json:"nodes"`
+}
+
+// ElasticsearchPendingNodeRollout describes the pending changes of a node
+type ElasticsearchPendingNodeRollout struct {
+	// The name of the deployment or statefulset of the node
+	Name string `json:"name"`
+	// The fields of the pod template to change, e.g. containers[elasticsearch].env
+	//
+	// +optional
+	Changes []string `json:"changes,omitempty"`
+}
+
+// ElasticsearchNodeReplacementPhase is the phase of replacing a node group
+type ElasticsearchNodeReplacementPhase string
+
+const (
+	// NodeReplacementWaitingForNodes waits for the nodes of the new group to join the cluster
+	NodeReplacementWaitingForNodes ElasticsearchNodeReplacementPhase = "WaitingForNodes"
+	// NodeReplacementDraining waits for the shards to be relocated off the replaced nodes
+	NodeReplacementDraining ElasticsearchNodeReplacementPhase = "Draining"
+	// NodeReplacementCompleted denotes the replaced group is decommissioned
+	NodeReplacementCompleted ElasticsearchNodeReplacementPhase = "Completed"
+)
+
+// ElasticsearchNodeReplacementStatus represents the progress of replacing a node group
+type ElasticsearchNodeReplacementStatus struct {
+	// The GenUUID of the replaced node group
+	GenUUID string `json:"genUUID"`
+	// The GenUUID of the node group replacing it
+	ReplacedBy string `json:"replacedBy"`
+	// +optional
+	Phase ElasticsearchNodeReplacementPhase `json:"phase,omitempty"`
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 type ClusterHealth struct {
@@ -154,7 +537,8 @@ type ElasticsearchNode struct {
 	// +optional
 	NodeCount int32 `json:"nodeCount"`
 
-	// The resource requirements for the Elasticsearch node
+	// The resource requirements for the Elasticsearch node. Requests and limits of
+	// ephemeral-storage apply as well, none is requested unless set
 	//
 	// +nullable
 	// +optional
@@ -176,6 +560,201 @@ type ElasticsearchNode struct {
 
 	// The resource requirements for the Elasticsearch proxy
 	ProxyResources corev1.ResourceRequirements `json:"proxyResources,omitempty"`
+
+	// Additional JVM options for the nodes of this group
+	//
+	// +nullable
+	// +optional
+	JvmOptions *ElasticsearchJvmOptionsSpec `json:"jvmOptions,omitempty"`
+
+	// The name of the PriorityClass assigned to the pods of this group,
+	// overrides the one of the common node spec
+	//
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// The seconds the pods of this group tolerate their node being not ready or
+	// unreachable before being evicted, overrides the one of the common node spec
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	NodeFailureTolerationSeconds *int64 `json:"nodeFailureTolerationSeconds,omitempty"`
+
+	// The seconds the pods of this group keep serving in-flight requests after being
+	// removed from the services before Elasticsearch shuts down, overrides the one of the
+	// common node spec. Applies to groups with the client role only
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ShutdownDelaySeconds *int64 `json:"shutdownDelaySeconds,omitempty"`
+
+	// Replaces another node group by this one. The replaced group is decommissioned
+	// once all nodes of this group joined the cluster and the shards are relocated
+	//
+	// +nullable
+	// +optional
+	Replaces *ElasticsearchNodeReplacementSpec `json:"replaces,omitempty"`
+
+	// Overrides the readiness probe of the Elasticsearch container of this group.
+	// Unset fields default depending on the roles of the group. Groups without the
+	// data role are only probed if requested
+	//
+	// +nullable
+	// +optional
+	ReadinessProbe *ElasticsearchProbeSpec `json:"readinessProbe,omitempty"`
+
+	// Annotations of the pods of this group, e.g. for Prometheus to scrape them. Changes of
+	// only prometheus.io/ annotations do not roll the nodes but take effect with their next rollout
+	//
+	// +optional
+	PodAnnotations map[string]string `json:"podAnnotations,omitempty"`
+
+	// The data tier of the nodes of this group, published as the node attribute data for
+	// index allocation filtering. Once any group declares a tier, every data group must declare one
+	//
+	// +kubebuilder:validation:Enum=hot;warm;cold
+	// +optional
+	DataTier ElasticsearchDataTier `json:"dataTier,omitempty"`
+
+	// The strategy replacing the pods of this group on changes. Only Recreate is supported:
+	// the operator replaces data nodes one at a time, and the other groups run as statefulsets,
+	// which replace their pods in order without surge or unavailability bounds
+	//
+	// +nullable
+	// +optional
+	UpdateStrategy *ElasticsearchNodeUpdateStrategy `json:"updateStrategy,omitempty"`
+
+	// Scales the nodes of this group with their load by a HorizontalPodAutoscaler. Only
+	// groups of coordinating only nodes may autoscale, their node count is then left to
+	// the autoscaler
+	//
+	// +nullable
+	// +optional
+	Autoscale *ElasticsearchNodeAutoscaleSpec `json:"autoscale,omitempty"`
+}
+
+// ElasticsearchNodeAutoscaleSpec defines the bounds and metrics of the autoscaling of a
+// node group
+type ElasticsearchNodeAutoscaleSpec struct {
+	// The lower limit of the nodes, defaults to the node count or 1
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// The upper limit of the nodes
+	//
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// The average CPU utilization of the nodes in percent of their request to scale to.
+	// Defaults to 80 unless metrics are set
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+
+	// Further metrics to scale by, e.g. custom metrics of the query rate
+	//
+	// +optional
+	Metrics []autoscalingv2beta2.MetricSpec `json:"metrics,omitempty"`
+}
+
+// ElasticsearchTrustedCASpec references the PEM encoded CA certificates imported into the
+// truststore of the JVM. Each key may hold one or more certificates. Exactly one of the
+// config map and the secret must be set.
+type ElasticsearchTrustedCASpec struct {
+	// The name of the config map holding the CA certificates
+	//
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// The name of the secret holding the CA certificates
+	//
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// ElasticsearchProxyTokenSpec defines the projected service account token of the proxy
+type ElasticsearchProxyTokenSpec struct {
+	// The intended audience of the token. Defaults to the identifier of the API server,
+	// which the token must be valid for to authenticate the token reviews of the proxy
+	//
+	// +optional
+	Audience string `json:"audience,omitempty"`
+
+	// The seconds after which the token expires and is rotated. Defaults to 3607
+	//
+	// +kubebuilder:validation:Minimum=600
+	// +optional
+	ExpirationSeconds *int64 `json:"expirationSeconds,omitempty"`
+}
+
+// ElasticsearchNodeUpdateStrategy defines how the pods of a node group are replaced
+type ElasticsearchNodeUpdateStrategy struct {
+	// The type of the strategy, only Recreate is supported
+	//
+	// +kubebuilder:validation:Enum=Recreate
+	// +optional
+	Type ElasticsearchNodeUpdateStrategyType `json:"type,omitempty"`
+}
+
+// ElasticsearchProbeSpec defines the readiness probe of the Elasticsearch container
+type ElasticsearchProbeSpec struct {
+	// The command run in the container to check its readiness, defaults to the
+	// readiness script of the Elasticsearch image
+	//
+	// +optional
+	Command []string `json:"command,omitempty"`
+
+	// The seconds after the container started before the probe is run
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+
+	// The seconds between two runs of the probe
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+
+	// The seconds after which the probe times out
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+
+	// The consecutive failures after which the container is marked not ready
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+}
+
+// ElasticsearchNodeReplacementSpec defines the node group replaced by another one
+type ElasticsearchNodeReplacementSpec struct {
+	// The GenUUID of the node group to replace
+	GenUUID string `json:"genUUID"`
+
+	// Delete the persistent volume claims of the replaced nodes once they are decommissioned
+	//
+	// +optional
+	DeletePersistentVolumeClaims bool `json:"deletePersistentVolumeClaims,omitempty"`
+}
+
+// ElasticsearchJvmOptionsSpec represents the JVM options passed to Elasticsearch through
+// the ES_JAVA_OPTS environment variable
+type ElasticsearchJvmOptionsSpec struct {
+	// The JVM flags, one per entry, e.g. -XX:+UseG1GC
+	//
+	// +optional
+	Options []string `json:"options,omitempty"`
+
+	// Allow overriding flags computed by the operator, e.g. the heap size
+	//
+	// +optional
+	AllowReservedOptions bool `json:"allowReservedOptions,omitempty"`
 }
 
 // ElasticsearchNodeSpec represents configuration of an individual Elasticsearch node
@@ -204,6 +783,149 @@ type ElasticsearchNodeSpec struct {
 	// +nullable
 	// +optional
 	ProxyResources corev1.ResourceRequirements `json:"proxyResources,omitempty"`
+
+	// The user and group the Elasticsearch pods are run as. If omitted the platform
+	// assigns them, otherwise unset fields default to the Elasticsearch user and group (1000)
+	//
+	// +nullable
+	// +optional
+	SecurityContext *ElasticsearchSecurityContext `json:"securityContext,omitempty"`
+
+	// Namespaced kernel parameters set for the Elasticsearch pods, e.g. net.core.somaxconn.
+	// Node level parameters like vm.max_map_count cannot be set per pod. Sysctls outside the
+	// safe set of the platform are unsafe and additionally require allowUnsafeSysctls
+	//
+	// +optional
+	Sysctls []corev1.Sysctl `json:"sysctls,omitempty"`
+
+	// Allow unsafe sysctls. The kubelets of the nodes must allow them too, e.g. by
+	// --allowed-unsafe-sysctls, otherwise the pods are rejected by their node
+	//
+	// +optional
+	AllowUnsafeSysctls bool `json:"allowUnsafeSysctls,omitempty"`
+
+	// Change the ownership of the persistent data volume to the user and fsGroup of the
+	// security context (default: 1000) by an init container running as root before
+	// Elasticsearch starts, for storage backends not honoring the fsGroup. Nodes with
+	// ephemeral storage are skipped
+	//
+	// +optional
+	ChownDataVolume bool `json:"chownDataVolume,omitempty"`
+
+	// Plugins installed by an init container before Elasticsearch starts, either names of
+	// official plugins, e.g. repository-s3, or URLs of plugin archives. Changing the plugins
+	// rolls the nodes
+	//
+	// +optional
+	Plugins []string `json:"plugins,omitempty"`
+
+	// The URL of a mirror of the official plugins for air-gapped installs, serving the
+	// archives as <mirror>/<name>/<name>-<version>.zip like artifacts.elastic.co
+	//
+	// +optional
+	PluginMirror string `json:"pluginMirror,omitempty"`
+
+	// Additional CA certificates trusted by the JVM of the Elasticsearch nodes, e.g. of
+	// snapshot repositories or LDAP servers signed by a private CA
+	//
+	// +nullable
+	// +optional
+	TrustedCA *ElasticsearchTrustedCASpec `json:"trustedCA,omitempty"`
+
+	// Additional volumes to add to the Elasticsearch pods, e.g. a custom trust store
+	//
+	// +optional
+	ExtraVolumes []corev1.Volume `json:"extraVolumes,omitempty"`
+
+	// Additional volume mounts for the Elasticsearch container. Mounts replacing
+	// the operator managed configuration, certificates or data paths are ignored
+	//
+	// +optional
+	ExtraVolumeMounts []corev1.VolumeMount `json:"extraVolumeMounts,omitempty"`
+
+	// Additional environment variables for the Elasticsearch container. Variables
+	// managed by the operator take precedence over the ones defined here
+	//
+	// +optional
+	ExtraEnv []corev1.EnvVar `json:"extraEnv,omitempty"`
+
+	// Mount the service account token into all containers of the Elasticsearch pods. If disabled
+	// only the proxy container gets a token via a projected volume. Defaults to true
+	//
+	// +optional
+	AutomountServiceAccountToken *bool `json:"automountServiceAccountToken,omitempty"`
+
+	// The bound service account token of the proxy container validating the tokens of
+	// requests. If set, the proxy gets a projected token of the audience and expiration
+	// even if the token is mounted into all containers
+	//
+	// +nullable
+	// +optional
+	ProxyServiceAccountToken *ElasticsearchProxyTokenSpec `json:"proxyServiceAccountToken,omitempty"`
+
+	// The name of the PriorityClass assigned to the Elasticsearch pods, e.g. to
+	// prevent them from being preempted under resource pressure
+	//
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// The seconds the Elasticsearch pods tolerate their node being not ready or
+	// unreachable before being evicted. Defaults to the 300 seconds of the platform
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	NodeFailureTolerationSeconds *int64 `json:"nodeFailureTolerationSeconds,omitempty"`
+
+	// The seconds the pods of nodes with the client role keep serving in-flight requests
+	// after being removed from the services before Elasticsearch shuts down. The
+	// termination grace period is extended by the delay. Defaults to 20 for coordinating
+	// only nodes and to 0 for any other node, 0 disables it
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ShutdownDelaySeconds *int64 `json:"shutdownDelaySeconds,omitempty"`
+
+	// The DNS policy of the Elasticsearch pods. Defaults to ClusterFirst
+	//
+	// +kubebuilder:validation:Enum:=ClusterFirstWithHostNet;ClusterFirst;Default;None
+	// +optional
+	DNSPolicy corev1.DNSPolicy `json:"dnsPolicy,omitempty"`
+
+	// Additional DNS parameters of the Elasticsearch pods, e.g. search domains or nameservers
+	//
+	// +nullable
+	// +optional
+	DNSConfig *corev1.PodDNSConfig `json:"dnsConfig,omitempty"`
+
+	// The name of the RuntimeClass the Elasticsearch pods run with, e.g. a sandboxed
+	// runtime like gVisor or Kata Containers
+	//
+	// +optional
+	RuntimeClassName *string `json:"runtimeClassName,omitempty"`
+}
+
+// ElasticsearchSecurityContext represents the identity the Elasticsearch pods are run as
+type ElasticsearchSecurityContext struct {
+	// The UID to run the entrypoint of the containers
+	//
+	// +optional
+	RunAsUser *int64 `json:"runAsUser,omitempty"`
+
+	// The GID to run the entrypoint of the containers
+	//
+	// +optional
+	RunAsGroup *int64 `json:"runAsGroup,omitempty"`
+
+	// The supplemental group owning the volumes mounted into the pod, e.g. the data directory
+	//
+	// +optional
+	FSGroup *int64 `json:"fsGroup,omitempty"`
+
+	// Require the containers to run as a non-root user, as namespaces enforcing the
+	// restricted pod security standard do
+	//
+	// +optional
+	RunAsNonRoot *bool `json:"runAsNonRoot,omitempty"`
 }
 
 type ElasticsearchStorageSpec struct {
@@ -214,6 +936,20 @@ type ElasticsearchStorageSpec struct {
 
 	// The max storage capacity for the node to provision.
 	Size *resource.Quantity `json:"size,omitempty"`
+
+	// The volume mode of the node's PVC. Only Filesystem is supported, Elasticsearch
+	// writes its data to the filesystem mounted as the data directory.
+	//
+	// +kubebuilder:validation:Enum=Filesystem
+	// +optional
+	VolumeMode *corev1.PersistentVolumeMode `json:"volumeMode,omitempty"`
+
+	// The access modes of the node's PVC, ReadWriteOnce or ReadWriteMany. Defaults to
+	// ReadWriteOnce. The access modes and the volume mode of existing PVCs are
+	// immutable, changing them is not applied.
+	//
+	// +optional
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
 }
 
 // ElasticsearchNodeStatus represents the status of individual Elasticsearch node
@@ -222,6 +958,25 @@ type ElasticsearchNodeStatus struct {
 	DeploymentName string `json:"deploymentName,omitempty"`
 	// +optional
 	StatefulSetName string `json:"statefulSetName,omitempty"`
+	// The revision of the deployment or statefulset the node currently runs
+	//
+	// +optional
+	Revision string `json:"revision,omitempty"`
+	// The default resource requirements applied to the Elasticsearch container
+	// since none were requested for the node
+	//
+	// +optional
+	DefaultedResources *corev1.ResourceRequirements `json:"defaultedResources,omitempty"`
+	// The hash of the desired pod template last rolled out on the node together with
+	// the live pod template of its deployment or statefulset at that time
+	//
+	// +optional
+	AppliedTemplateHash string `json:"appliedTemplateHash,omitempty"`
+	// The hash of the rendered Elasticsearch configuration the node last restarted with,
+	// see the configHash of the cluster status
+	//
+	// +optional
+	LastAppliedConfigHash string `json:"lastAppliedConfigHash,omitempty"`
 	// +optional
 	Status string `json:"status,omitempty"`
 	// +optional
@@ -269,13 +1024,34 @@ const (
 	ZeroRedundancy RedundancyPolicyType = "ZeroRedundancy"
 )
 
-// +kubebuilder:validation:Enum:=master;client;data
+// +kubebuilder:validation:Enum:=master;client;data;ingest
 type ElasticsearchNodeRole string
 
 const (
 	ElasticsearchRoleClient ElasticsearchNodeRole = "client"
 	ElasticsearchRoleData   ElasticsearchNodeRole = "data"
 	ElasticsearchRoleMaster ElasticsearchNodeRole = "master"
+	ElasticsearchRoleIngest ElasticsearchNodeRole = "ingest"
+)
+
+// ElasticsearchDataTier is the tier of data nodes, e.g. hot nodes on fast storage
+// holding the indices being written to
+type ElasticsearchDataTier string
+
+const (
+	ElasticsearchDataTierHot  ElasticsearchDataTier = "hot"
+	ElasticsearchDataTierWarm ElasticsearchDataTier = "warm"
+	ElasticsearchDataTierCold ElasticsearchDataTier = "cold"
+)
+
+// ElasticsearchNodeUpdateStrategyType is the type of the deployment strategy of a node group
+type ElasticsearchNodeUpdateStrategyType string
+
+const (
+	ElasticsearchNodeUpdateRecreate ElasticsearchNodeUpdateStrategyType = "Recreate"
+	// ElasticsearchNodeUpdateRollingUpdate is rejected, it is only kept to report clusters
+	// still requesting it
+	ElasticsearchNodeUpdateRollingUpdate ElasticsearchNodeUpdateStrategyType = "RollingUpdate"
 )
 
 type ShardAllocationState string
@@ -332,15 +1108,54 @@ const (
 	InvalidData              ClusterConditionType = "InvalidData"
 	InvalidRedundancy        ClusterConditionType = "InvalidRedundancy"
 	InvalidUUID              ClusterConditionType = "InvalidUUID"
+	InvalidNodeNames         ClusterConditionType = "InvalidNodeNames"
+	InvalidDiscoveryHosts    ClusterConditionType = "InvalidDiscoveryHosts"
+	InvalidJvmOptions        ClusterConditionType = "InvalidJvmOptions"
+	InsufficientQuota        ClusterConditionType = "InsufficientQuota"
+	InvalidRecoverAfterTime  ClusterConditionType = "InvalidRecoverAfterTime"
+	InvalidLog4j2Properties  ClusterConditionType = "InvalidLog4j2Properties"
+	InvalidIngestPipelines   ClusterConditionType = "InvalidIngestPipelines"
+	InvalidRoutingShards     ClusterConditionType = "InvalidRoutingShards"
+	NoMasterElected          ClusterConditionType = "NoMasterElected"
+	RecoveryInProgress       ClusterConditionType = "RecoveryInProgress"
+	InvalidClusterName       ClusterConditionType = "InvalidClusterName"
+	ReconcileWaiting         ClusterConditionType = "ReconcileWaiting"
+	InvalidDataTiers         ClusterConditionType = "InvalidDataTiers"
+	InvalidPlugins           ClusterConditionType = "InvalidPlugins"
+	InvalidUpdateStrategy    ClusterConditionType = "InvalidUpdateStrategy"
+	InvalidTrustedCA         ClusterConditionType = "InvalidTrustedCA"
+	InvalidStorage           ClusterConditionType = "InvalidStorage"
+	NodeDraining             ClusterConditionType = "NodeDraining"
+	UnsafeNodeRemoval        ClusterConditionType = "UnsafeNodeRemoval"
+	UnassignedShards         ClusterConditionType = "UnassignedShards"
+	ConfigVersionMismatch    ClusterConditionType = "ConfigVersionMismatch"
+	NodesDegraded            ClusterConditionType = "NodesDegraded"
+	NodesProgressing         ClusterConditionType = "NodesProgressing"
 	ESContainerWaiting       ClusterConditionType = "ElasticsearchContainerWaiting"
 	ESContainerTerminated    ClusterConditionType = "ElasticsearchContainerTerminated"
 	ProxyContainerWaiting    ClusterConditionType = "ProxyContainerWaiting"
 	ProxyContainerTerminated ClusterConditionType = "ProxyContainerTerminated"
 	Unschedulable            ClusterConditionType = "Unschedulable"
+	PodsUnschedulable        ClusterConditionType = "PodsUnschedulable"
 	NodeStorage              ClusterConditionType = "NodeStorage"
 	CustomImage              ClusterConditionType = "CustomImageIgnored"
 	DegradedState            ClusterConditionType = "Degraded"
+	ProgressingState         ClusterConditionType = "Progressing"
+	ReadyState               ClusterConditionType = "Ready"
+	AvailableState           ClusterConditionType = "Available"
 	StorageClassName         ClusterConditionType = "StorageClassNameChangeIgnored"
 	StorageSize              ClusterConditionType = "StorageSizeChangeIgnored"
 	StorageStructure         ClusterConditionType = "StorageStructureChangeIgnored"
+	StorageMode              ClusterConditionType = "StorageModeChangeIgnored"
+	InvalidMaintenanceWindow ClusterConditionType = "InvalidMaintenanceWindow"
+	DeferredOutsideWindow    ClusterConditionType = "DeferredOutsideWindow"
+	InvalidSysctls           ClusterConditionType = "InvalidSysctls"
+	SysctlsForbidden         ClusterConditionType = "SysctlsForbidden"
+	LicenseExpiring          ClusterConditionType = "LicenseExpiring"
+	InvalidServicePorts      ClusterConditionType = "InvalidServicePorts"
+	ClusterNameConflict      ClusterConditionType = "ClusterNameConflict"
+	InvalidAutoscaling       ClusterConditionType = "InvalidAutoscaling"
+	Debugging                ClusterConditionType = "Debugging"
+	InvalidAutoCreateIndex   ClusterConditionType = "InvalidAutoCreateIndex"
+	ServiceReadiness         ClusterConditionType = "ServiceReadinessOverridden"
 )
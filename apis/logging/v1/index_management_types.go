@@ -17,8 +17,32 @@ type IndexManagementSpec struct {
 	//
 	// +optional
 	Mappings []IndexManagementPolicyMappingSpec `json:"mappings"`
+
+	// Additional labels added to the index management cronjobs and their job pods,
+	// e.g. to select them in network policies
+	//
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// How the operator manages the role and rolebinding allowing the index management
+	// cronjobs to manage the indices. CreateOnly leaves changes to existing ones in place,
+	// e.g. for environments layering their own RBAC policy on top
+	//
+	// +kubebuilder:validation:Enum=Reconcile;CreateOnly
+	// +optional
+	RBACPolicy IndexManagementRBACPolicy `json:"rbacPolicy,omitempty"`
 }
 
+// IndexManagementRBACPolicy defines how the RBAC of the index management is managed
+type IndexManagementRBACPolicy string
+
+const (
+	// IndexManagementRBACReconcile reverts any change to the role and rolebinding, the default
+	IndexManagementRBACReconcile IndexManagementRBACPolicy = "Reconcile"
+	// IndexManagementRBACCreateOnly creates the role and rolebinding if missing only
+	IndexManagementRBACCreateOnly IndexManagementRBACPolicy = "CreateOnly"
+)
+
 // TimeUnit is a time unit like h,m,d
 //
 // +kubebuilder:validation:Pattern:="^([0-9]+)([yMwdhHms]{0,1})$"
@@ -119,6 +143,35 @@ func NewIndexManagementStatus() *IndexManagementStatus {
 	}
 }
 
+// IndexManagementRunStatus is the last run of the index management of a policy mapping
+// started on demand
+type IndexManagementRunStatus struct {
+	// The name of the policy mapping
+	Mapping string `json:"mapping"`
+	// The name of the job running the index management
+	Job string `json:"job"`
+	// The state of the run
+	State IndexManagementRunState `json:"state"`
+	// The time the job was created
+	StartTime metav1.Time `json:"startTime"`
+	// The time the job completed, unset while it runs
+	//
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// IndexManagementRunState is the state of a run of the index management started on demand
+type IndexManagementRunState string
+
+const (
+	// IndexManagementRunStateRunning while the job runs
+	IndexManagementRunStateRunning IndexManagementRunState = "Running"
+	// IndexManagementRunStateSucceeded once the job completed successfully
+	IndexManagementRunStateSucceeded IndexManagementRunState = "Succeeded"
+	// IndexManagementRunStateFailed once the job failed
+	IndexManagementRunStateFailed IndexManagementRunState = "Failed"
+)
+
 // IndexManagementState of IndexManagment
 type IndexManagementState string
 
@@ -35,8 +35,41 @@ type KibanaSpec struct {
 	//
 	// +optional
 	ProxySpec `json:"proxy,omitempty"`
+
+	// Affinity of the Kibana pods toward the Elasticsearch client nodes
+	//
+	// +nullable
+	// +optional
+	ClientNodeAffinity *KibanaClientNodeAffinitySpec `json:"clientNodeAffinity,omitempty"`
 }
 
+// KibanaClientNodeAffinitySpec represents the co-location of the Kibana pods with the
+// Elasticsearch client nodes
+type KibanaClientNodeAffinitySpec struct {
+	// Whether the co-location is preferred or required to schedule the Kibana pods. Defaults
+	// to Preferred so that Kibana is scheduled if no client node is available
+	//
+	// +optional
+	Type KibanaAffinityType `json:"type,omitempty"`
+
+	// The topology key of the nodes considered co-located. Defaults to kubernetes.io/hostname
+	//
+	// +optional
+	TopologyKey string `json:"topologyKey,omitempty"`
+}
+
+// KibanaAffinityType is the type of the affinity toward the Elasticsearch client nodes
+//
+// +kubebuilder:validation:Enum=Preferred;Required
+type KibanaAffinityType string
+
+const (
+	// KibanaAffinityPreferred prefers nodes running an Elasticsearch client node
+	KibanaAffinityPreferred KibanaAffinityType = "Preferred"
+	// KibanaAffinityRequired schedules Kibana on nodes running an Elasticsearch client node only
+	KibanaAffinityRequired KibanaAffinityType = "Required"
+)
+
 type ProxySpec struct {
 	// The resource requirements for Kibana proxy
 	//
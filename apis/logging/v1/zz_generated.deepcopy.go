@@ -5,6 +5,7 @@
 package v1
 
 import (
+	"k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -88,6 +89,130 @@ func (in *Elasticsearch) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchAutoCreateIndexSpec) DeepCopyInto(out *ElasticsearchAutoCreateIndexSpec) {
+	*out = *in
+	if in.Patterns != nil {
+		in, out := &in.Patterns, &out.Patterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchAutoCreateIndexSpec.
+func (in *ElasticsearchAutoCreateIndexSpec) DeepCopy() *ElasticsearchAutoCreateIndexSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchAutoCreateIndexSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchDiscoverySpec) DeepCopyInto(out *ElasticsearchDiscoverySpec) {
+	*out = *in
+	if in.SeedHosts != nil {
+		in, out := &in.SeedHosts, &out.SeedHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchDiscoverySpec.
+func (in *ElasticsearchDiscoverySpec) DeepCopy() *ElasticsearchDiscoverySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchDiscoverySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchEndpointsStatus) DeepCopyInto(out *ElasticsearchEndpointsStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchEndpointsStatus.
+func (in *ElasticsearchEndpointsStatus) DeepCopy() *ElasticsearchEndpointsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchEndpointsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchIndexTier) DeepCopyInto(out *ElasticsearchIndexTier) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchIndexTier.
+func (in *ElasticsearchIndexTier) DeepCopy() *ElasticsearchIndexTier {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchIndexTier)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchIngestPipeline) DeepCopyInto(out *ElasticsearchIngestPipeline) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchIngestPipeline.
+func (in *ElasticsearchIngestPipeline) DeepCopy() *ElasticsearchIngestPipeline {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchIngestPipeline)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchJvmOptionsSpec) DeepCopyInto(out *ElasticsearchJvmOptionsSpec) {
+	*out = *in
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchJvmOptionsSpec.
+func (in *ElasticsearchJvmOptionsSpec) DeepCopy() *ElasticsearchJvmOptionsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchJvmOptionsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchLicenseStatus) DeepCopyInto(out *ElasticsearchLicenseStatus) {
+	*out = *in
+	if in.ExpiryDate != nil {
+		in, out := &in.ExpiryDate, &out.ExpiryDate
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchLicenseStatus.
+func (in *ElasticsearchLicenseStatus) DeepCopy() *ElasticsearchLicenseStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchLicenseStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ElasticsearchList) DeepCopyInto(out *ElasticsearchList) {
 	*out = *in
@@ -120,6 +245,75 @@ func (in *ElasticsearchList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchMaintenanceWindow) DeepCopyInto(out *ElasticsearchMaintenanceWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]ElasticsearchWeekday, len(*in))
+		copy(*out, *in)
+	}
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchMaintenanceWindow.
+func (in *ElasticsearchMaintenanceWindow) DeepCopy() *ElasticsearchMaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchMaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchMasterTransition) DeepCopyInto(out *ElasticsearchMasterTransition) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchMasterTransition.
+func (in *ElasticsearchMasterTransition) DeepCopy() *ElasticsearchMasterTransition {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchMasterTransition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchMonitoringSpec) DeepCopyInto(out *ElasticsearchMonitoringSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TargetLabels != nil {
+		in, out := &in.TargetLabels, &out.TargetLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PodTargetLabels != nil {
+		in, out := &in.PodTargetLabels, &out.PodTargetLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchMonitoringSpec.
+func (in *ElasticsearchMonitoringSpec) DeepCopy() *ElasticsearchMonitoringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchMonitoringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ElasticsearchNode) DeepCopyInto(out *ElasticsearchNode) {
 	*out = *in
@@ -150,6 +344,48 @@ func (in *ElasticsearchNode) DeepCopyInto(out *ElasticsearchNode) {
 		**out = **in
 	}
 	in.ProxyResources.DeepCopyInto(&out.ProxyResources)
+	if in.JvmOptions != nil {
+		in, out := &in.JvmOptions, &out.JvmOptions
+		*out = new(ElasticsearchJvmOptionsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeFailureTolerationSeconds != nil {
+		in, out := &in.NodeFailureTolerationSeconds, &out.NodeFailureTolerationSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ShutdownDelaySeconds != nil {
+		in, out := &in.ShutdownDelaySeconds, &out.ShutdownDelaySeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Replaces != nil {
+		in, out := &in.Replaces, &out.Replaces
+		*out = new(ElasticsearchNodeReplacementSpec)
+		**out = **in
+	}
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = new(ElasticsearchProbeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodAnnotations != nil {
+		in, out := &in.PodAnnotations, &out.PodAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.UpdateStrategy != nil {
+		in, out := &in.UpdateStrategy, &out.UpdateStrategy
+		*out = new(ElasticsearchNodeUpdateStrategy)
+		**out = **in
+	}
+	if in.Autoscale != nil {
+		in, out := &in.Autoscale, &out.Autoscale
+		*out = new(ElasticsearchNodeAutoscaleSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchNode.
@@ -162,6 +398,68 @@ func (in *ElasticsearchNode) DeepCopy() *ElasticsearchNode {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchNodeAutoscaleSpec) DeepCopyInto(out *ElasticsearchNodeAutoscaleSpec) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetCPUUtilizationPercentage != nil {
+		in, out := &in.TargetCPUUtilizationPercentage, &out.TargetCPUUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]v2beta2.MetricSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchNodeAutoscaleSpec.
+func (in *ElasticsearchNodeAutoscaleSpec) DeepCopy() *ElasticsearchNodeAutoscaleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchNodeAutoscaleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchNodeReplacementSpec) DeepCopyInto(out *ElasticsearchNodeReplacementSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchNodeReplacementSpec.
+func (in *ElasticsearchNodeReplacementSpec) DeepCopy() *ElasticsearchNodeReplacementSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchNodeReplacementSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchNodeReplacementStatus) DeepCopyInto(out *ElasticsearchNodeReplacementStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchNodeReplacementStatus.
+func (in *ElasticsearchNodeReplacementStatus) DeepCopy() *ElasticsearchNodeReplacementStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchNodeReplacementStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ElasticsearchNodeSpec) DeepCopyInto(out *ElasticsearchNodeSpec) {
 	*out = *in
@@ -181,6 +479,77 @@ func (in *ElasticsearchNodeSpec) DeepCopyInto(out *ElasticsearchNodeSpec) {
 		}
 	}
 	in.ProxyResources.DeepCopyInto(&out.ProxyResources)
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(ElasticsearchSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Sysctls != nil {
+		in, out := &in.Sysctls, &out.Sysctls
+		*out = make([]corev1.Sysctl, len(*in))
+		copy(*out, *in)
+	}
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TrustedCA != nil {
+		in, out := &in.TrustedCA, &out.TrustedCA
+		*out = new(ElasticsearchTrustedCASpec)
+		**out = **in
+	}
+	if in.ExtraVolumes != nil {
+		in, out := &in.ExtraVolumes, &out.ExtraVolumes
+		*out = make([]corev1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraVolumeMounts != nil {
+		in, out := &in.ExtraVolumeMounts, &out.ExtraVolumeMounts
+		*out = make([]corev1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraEnv != nil {
+		in, out := &in.ExtraEnv, &out.ExtraEnv
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AutomountServiceAccountToken != nil {
+		in, out := &in.AutomountServiceAccountToken, &out.AutomountServiceAccountToken
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ProxyServiceAccountToken != nil {
+		in, out := &in.ProxyServiceAccountToken, &out.ProxyServiceAccountToken
+		*out = new(ElasticsearchProxyTokenSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeFailureTolerationSeconds != nil {
+		in, out := &in.NodeFailureTolerationSeconds, &out.NodeFailureTolerationSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ShutdownDelaySeconds != nil {
+		in, out := &in.ShutdownDelaySeconds, &out.ShutdownDelaySeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.DNSConfig != nil {
+		in, out := &in.DNSConfig, &out.DNSConfig
+		*out = new(corev1.PodDNSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RuntimeClassName != nil {
+		in, out := &in.RuntimeClassName, &out.RuntimeClassName
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchNodeSpec.
@@ -196,6 +565,11 @@ func (in *ElasticsearchNodeSpec) DeepCopy() *ElasticsearchNodeSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ElasticsearchNodeStatus) DeepCopyInto(out *ElasticsearchNodeStatus) {
 	*out = *in
+	if in.DefaultedResources != nil {
+		in, out := &in.DefaultedResources, &out.DefaultedResources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
 	out.UpgradeStatus = in.UpgradeStatus
 	if in.Roles != nil {
 		in, out := &in.Roles, &out.Roles
@@ -221,6 +595,21 @@ func (in *ElasticsearchNodeStatus) DeepCopy() *ElasticsearchNodeStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchNodeUpdateStrategy) DeepCopyInto(out *ElasticsearchNodeUpdateStrategy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchNodeUpdateStrategy.
+func (in *ElasticsearchNodeUpdateStrategy) DeepCopy() *ElasticsearchNodeUpdateStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchNodeUpdateStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ElasticsearchNodeUpgradeStatus) DeepCopyInto(out *ElasticsearchNodeUpgradeStatus) {
 	*out = *in
@@ -236,6 +625,201 @@ func (in *ElasticsearchNodeUpgradeStatus) DeepCopy() *ElasticsearchNodeUpgradeSt
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchPendingNodeRollout) DeepCopyInto(out *ElasticsearchPendingNodeRollout) {
+	*out = *in
+	if in.Changes != nil {
+		in, out := &in.Changes, &out.Changes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchPendingNodeRollout.
+func (in *ElasticsearchPendingNodeRollout) DeepCopy() *ElasticsearchPendingNodeRollout {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchPendingNodeRollout)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchPendingRollout) DeepCopyInto(out *ElasticsearchPendingRollout) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]ElasticsearchPendingNodeRollout, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchPendingRollout.
+func (in *ElasticsearchPendingRollout) DeepCopy() *ElasticsearchPendingRollout {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchPendingRollout)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchProbeSpec) DeepCopyInto(out *ElasticsearchProbeSpec) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.InitialDelaySeconds != nil {
+		in, out := &in.InitialDelaySeconds, &out.InitialDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PeriodSeconds != nil {
+		in, out := &in.PeriodSeconds, &out.PeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchProbeSpec.
+func (in *ElasticsearchProbeSpec) DeepCopy() *ElasticsearchProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchProxyTokenSpec) DeepCopyInto(out *ElasticsearchProxyTokenSpec) {
+	*out = *in
+	if in.ExpirationSeconds != nil {
+		in, out := &in.ExpirationSeconds, &out.ExpirationSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchProxyTokenSpec.
+func (in *ElasticsearchProxyTokenSpec) DeepCopy() *ElasticsearchProxyTokenSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchProxyTokenSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchSecurityContext) DeepCopyInto(out *ElasticsearchSecurityContext) {
+	*out = *in
+	if in.RunAsUser != nil {
+		in, out := &in.RunAsUser, &out.RunAsUser
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RunAsGroup != nil {
+		in, out := &in.RunAsGroup, &out.RunAsGroup
+		*out = new(int64)
+		**out = **in
+	}
+	if in.FSGroup != nil {
+		in, out := &in.FSGroup, &out.FSGroup
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RunAsNonRoot != nil {
+		in, out := &in.RunAsNonRoot, &out.RunAsNonRoot
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchSecurityContext.
+func (in *ElasticsearchSecurityContext) DeepCopy() *ElasticsearchSecurityContext {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchSecurityContext)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchServiceSpec) DeepCopyInto(out *ElasticsearchServiceSpec) {
+	*out = *in
+	if in.PublishNotReadyAddresses != nil {
+		in, out := &in.PublishNotReadyAddresses, &out.PublishNotReadyAddresses
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchServiceSpec.
+func (in *ElasticsearchServiceSpec) DeepCopy() *ElasticsearchServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchServicesSpec) DeepCopyInto(out *ElasticsearchServicesSpec) {
+	*out = *in
+	if in.Discovery != nil {
+		in, out := &in.Discovery, &out.Discovery
+		*out = new(ElasticsearchServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Client != nil {
+		in, out := &in.Client, &out.Client
+		*out = new(ElasticsearchServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = new(ElasticsearchServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchServicesSpec.
+func (in *ElasticsearchServicesSpec) DeepCopy() *ElasticsearchServicesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchServicesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ElasticsearchSpec) DeepCopyInto(out *ElasticsearchSpec) {
 	*out = *in
@@ -252,6 +836,61 @@ func (in *ElasticsearchSpec) DeepCopyInto(out *ElasticsearchSpec) {
 		*out = new(IndexManagementSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Discovery != nil {
+		in, out := &in.Discovery, &out.Discovery
+		*out = new(ElasticsearchDiscoverySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = new(ElasticsearchServicesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Log4j2Properties != nil {
+		in, out := &in.Log4j2Properties, &out.Log4j2Properties
+		*out = new(string)
+		**out = **in
+	}
+	if in.MaxPendingTasks != nil {
+		in, out := &in.MaxPendingTasks, &out.MaxPendingTasks
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RoutingShards != nil {
+		in, out := &in.RoutingShards, &out.RoutingShards
+		*out = new(int32)
+		**out = **in
+	}
+	if in.IngestPipelines != nil {
+		in, out := &in.IngestPipelines, &out.IngestPipelines
+		*out = make([]ElasticsearchIngestPipeline, len(*in))
+		copy(*out, *in)
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(ElasticsearchMonitoringSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IndexTiers != nil {
+		in, out := &in.IndexTiers, &out.IndexTiers
+		*out = make([]ElasticsearchIndexTier, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(ElasticsearchMaintenanceWindow)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PropagatedAnnotations != nil {
+		in, out := &in.PropagatedAnnotations, &out.PropagatedAnnotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AutoCreateIndex != nil {
+		in, out := &in.AutoCreateIndex, &out.AutoCreateIndex
+		*out = new(ElasticsearchAutoCreateIndexSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchSpec.
@@ -312,6 +951,42 @@ func (in *ElasticsearchStatus) DeepCopyInto(out *ElasticsearchStatus) {
 		*out = new(IndexManagementStatus)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Replacements != nil {
+		in, out := &in.Replacements, &out.Replacements
+		*out = make([]ElasticsearchNodeReplacementStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.PendingRollout != nil {
+		in, out := &in.PendingRollout, &out.PendingRollout
+		*out = new(ElasticsearchPendingRollout)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = new(ElasticsearchEndpointsStatus)
+		**out = **in
+	}
+	if in.MasterTransition != nil {
+		in, out := &in.MasterTransition, &out.MasterTransition
+		*out = new(ElasticsearchMasterTransition)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.License != nil {
+		in, out := &in.License, &out.License
+		*out = new(ElasticsearchLicenseStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UnassignedShardsSince != nil {
+		in, out := &in.UnassignedShardsSince, &out.UnassignedShardsSince
+		*out = (*in).DeepCopy()
+	}
+	if in.IndexManagementRuns != nil {
+		in, out := &in.IndexManagementRuns, &out.IndexManagementRuns
+		*out = make([]IndexManagementRunStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchStatus.
@@ -337,6 +1012,16 @@ func (in *ElasticsearchStorageSpec) DeepCopyInto(out *ElasticsearchStorageSpec)
 		x := (*in).DeepCopy()
 		*out = &x
 	}
+	if in.VolumeMode != nil {
+		in, out := &in.VolumeMode, &out.VolumeMode
+		*out = new(corev1.PersistentVolumeMode)
+		**out = **in
+	}
+	if in.AccessModes != nil {
+		in, out := &in.AccessModes, &out.AccessModes
+		*out = make([]corev1.PersistentVolumeAccessMode, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchStorageSpec.
@@ -349,6 +1034,21 @@ func (in *ElasticsearchStorageSpec) DeepCopy() *ElasticsearchStorageSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchTrustedCASpec) DeepCopyInto(out *ElasticsearchTrustedCASpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchTrustedCASpec.
+func (in *ElasticsearchTrustedCASpec) DeepCopy() *ElasticsearchTrustedCASpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchTrustedCASpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IndexManagementActionSpec) DeepCopyInto(out *IndexManagementActionSpec) {
 	*out = *in
@@ -548,6 +1248,26 @@ func (in *IndexManagementPolicyStatus) DeepCopy() *IndexManagementPolicyStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexManagementRunStatus) DeepCopyInto(out *IndexManagementRunStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexManagementRunStatus.
+func (in *IndexManagementRunStatus) DeepCopy() *IndexManagementRunStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexManagementRunStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IndexManagementSpec) DeepCopyInto(out *IndexManagementSpec) {
 	*out = *in
@@ -565,6 +1285,13 @@ func (in *IndexManagementSpec) DeepCopyInto(out *IndexManagementSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexManagementSpec.
@@ -640,6 +1367,21 @@ func (in *Kibana) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KibanaClientNodeAffinitySpec) DeepCopyInto(out *KibanaClientNodeAffinitySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaClientNodeAffinitySpec.
+func (in *KibanaClientNodeAffinitySpec) DeepCopy() *KibanaClientNodeAffinitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KibanaClientNodeAffinitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KibanaList) DeepCopyInto(out *KibanaList) {
 	*out = *in
@@ -695,6 +1437,11 @@ func (in *KibanaSpec) DeepCopyInto(out *KibanaSpec) {
 		}
 	}
 	in.ProxySpec.DeepCopyInto(&out.ProxySpec)
+	if in.ClientNodeAffinity != nil {
+		in, out := &in.ClientNodeAffinity, &out.ClientNodeAffinity
+		*out = new(KibanaClientNodeAffinitySpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaSpec.
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	"reflect"
 	"time"
 
 	"github.com/openshift/elasticsearch-operator/internal/indexmanagement"
@@ -9,12 +10,20 @@ import (
 
 	"github.com/ViaQ/logerr/log"
 	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
 	"github.com/openshift/elasticsearch-operator/internal/elasticsearch"
@@ -25,17 +34,66 @@ type ElasticsearchReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+
+	// APIReader reads the objects not cached by the manager, e.g. namespaces
+	APIReader client.Reader
+
+	// Recorder is used to emit events for the reconciled clusters
+	Recorder record.EventRecorder
+
+	// RequeueInterval is the interval after which a converged cluster is reconciled again
+	RequeueInterval time.Duration
 }
 
-// Reconcile reads that state of the cluster for a Elasticsearch object and makes changes based on the state read
-// and what is in the Elasticsearch.Spec
+// DefaultRequeueInterval is used if no requeue interval is configured for the reconciler
+const DefaultRequeueInterval = 3 * time.Minute
+
 var (
+	// reconcilePeriod is used on errors
 	reconcilePeriod = 30 * time.Second
 	// reconcileResult = reconcile.Result{RequeueAfter: reconcilePeriod}
 	reconcileResult = ctrl.Result{RequeueAfter: reconcilePeriod}
 )
 
+// outcomeResult returns the result for the outcome of a successful reconcile. Clusters the
+// operator waits on, e.g. with a rollout in progress, are requeued sooner to keep track of
+// the progress, converged ones after the requeue interval.
+func (r *ElasticsearchReconciler) outcomeResult(outcome elasticsearch.ReconcileOutcome) ctrl.Result {
+	if outcome.IsWaiting() && outcome.RequeueAfter > 0 {
+		return ctrl.Result{RequeueAfter: outcome.RequeueAfter}
+	}
+
+	interval := r.RequeueInterval
+	if interval <= 0 {
+		interval = DefaultRequeueInterval
+	}
+
+	return ctrl.Result{RequeueAfter: interval}
+}
+
+// Reconcile reads that state of the cluster for a Elasticsearch object and makes changes based on the state read
+// and what is in the Elasticsearch.Spec
 func (r *ElasticsearchReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
+	timer := metrics.NewReconcileTimer()
+
+	result, outcome, err := r.reconcile(request)
+	switch {
+	case err != nil:
+		timer.ObserveDuration(metrics.ReconcileError)
+	case outcome.IsWaiting():
+		timer.ObserveDuration(metrics.ReconcileRequeue)
+		log.Info("Requeueing Elasticsearch cluster", "objectKey", request.NamespacedName,
+			"reason", outcome.Reason, "message", outcome.Message)
+	default:
+		timer.ObserveDuration(metrics.ReconcileSuccess)
+	}
+
+	return result, err
+}
+
+func (r *ElasticsearchReconciler) reconcile(request ctrl.Request) (ctrl.Result, elasticsearch.ReconcileOutcome, error) {
+	outcome := elasticsearch.ReconcileOutcome{}
+
 	// Fetch the Elasticsearch instance
 	cluster := &loggingv1.Elasticsearch{}
 
@@ -45,16 +103,24 @@ func (r *ElasticsearchReconciler) Reconcile(request ctrl.Request) (ctrl.Result,
 			log.Info("Flushing nodes", "objectKey", request.NamespacedName)
 			elasticsearch.FlushNodes(request.NamespacedName.Name, request.NamespacedName.Namespace)
 			elasticsearch.RemoveDashboardConfigMap(r.Client)
-			return ctrl.Result{}, nil
+			return ctrl.Result{}, outcome, nil
 		}
 
-		return ctrl.Result{}, err
+		return ctrl.Result{}, outcome, err
+	}
+
+	if cluster.GetDeletionTimestamp() != nil {
+		log.Info("Tearing down Elasticsearch cluster", "objectKey", request.NamespacedName)
+		if err = elasticsearch.Teardown(context.TODO(), cluster, r.Client); err != nil {
+			return reconcileResult, outcome, err
+		}
+		return ctrl.Result{}, outcome, nil
 	}
 
 	if cluster.Spec.ManagementState == loggingv1.ManagementStateUnmanaged {
 		// Cluster state changes from Managed -> Unmanaged, so set "unmanaged" as 1 and set "managed" as 0.
 		metrics.SetEsClusterManagementStateUnmanaged()
-		return ctrl.Result{}, nil
+		return ctrl.Result{}, outcome, nil
 	}
 	// Cluster state changes from Unmanaged -> Managed, so set "managed" as 1 and set "unmanaged" as 0.
 	metrics.SetEsClusterManagementStateManaged()
@@ -82,20 +148,74 @@ func (r *ElasticsearchReconciler) Reconcile(request ctrl.Request) (ctrl.Result,
 
 	}
 
-	if err = elasticsearch.Reconcile(cluster, r.Client); err != nil {
-		return reconcileResult, err
+	if err = elasticsearch.EnsureFinalizer(context.TODO(), cluster, r.Client); err != nil {
+		return reconcileResult, outcome, err
+	}
+
+	outcome, err = elasticsearch.Reconcile(cluster, r.Client, r.APIReader, r.Recorder)
+	if err != nil {
+		return reconcileResult, outcome, err
+	}
+
+	// the cluster is reconciled again once the operator stopped waiting on it
+	if outcome.IsWaiting() && outcome.Reason != elasticsearch.RequeueRolloutInProgress &&
+		outcome.Reason != elasticsearch.RequeueWaitingForMaintenanceWindow {
+		return r.outcomeResult(outcome), outcome, nil
 	}
 
-	if err = indexmanagement.Reconcile(cluster, r.Client); err != nil {
-		return reconcileResult, err
+	if err = indexmanagement.Reconcile(cluster, r.Client, r.Recorder); err != nil {
+		return reconcileResult, outcome, err
 	}
 
-	return reconcileResult, nil
+	return r.outcomeResult(outcome), outcome, nil
+}
+
+// esCredentialSecretPredicate filters the secret events to the credential secrets of the
+// clusters, i.e. the secret named after the cluster. Updates are only passed on
+// if the secret data changed, so that cert redeploys are picked up right away.
+func esCredentialSecretPredicate(r client.Client) predicate.Predicate {
+	isClusterSecret := func(meta metav1.Object) bool {
+		key := types.NamespacedName{Name: meta.GetName(), Namespace: meta.GetNamespace()}
+		return r.Get(context.TODO(), key, &loggingv1.Elasticsearch{}) == nil
+	}
+
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldSecret, ok := e.ObjectOld.(*v1.Secret)
+			if !ok {
+				return false
+			}
+			newSecret, ok := e.ObjectNew.(*v1.Secret)
+			if !ok {
+				return false
+			}
+			if reflect.DeepEqual(oldSecret.Data, newSecret.Data) {
+				return false
+			}
+			return isClusterSecret(e.MetaNew)
+		},
+		CreateFunc: func(e event.CreateEvent) bool {
+			return isClusterSecret(e.Meta)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
 }
 
 func (r *ElasticsearchReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		Named("elasticsearch-controller").
 		For(&loggingv1.Elasticsearch{}).
+		// the on demand index management runs are completed once their jobs finish
+		Owns(&batchv1.Job{}).
+		Watches(
+			&source.Kind{Type: &v1.Secret{}},
+			&handler.EnqueueRequestForObject{},
+			builder.WithPredicates(esCredentialSecretPredicate(r.Client)),
+		).
 		Complete(r)
 }
@@ -23,6 +23,13 @@ const (
 
 	EOCertManagementLabel = "logging.openshift.io/elasticsearch-cert-management"
 	EOComponentCertPrefix = "logging.openshift.io/elasticsearch-cert."
+
+	// ElasticsearchFinalizer is the finalizer guarding the teardown of an Elasticsearch cluster
+	ElasticsearchFinalizer = "logging.openshift.io/elasticsearch-teardown"
+
+	OpenshiftLoggingNamespace        = "openshift-logging"
+	KibanaConsoleLinkName            = "kibana-public-url"
+	KibanaConsoleExternalLogLinkName = "kibana"
 )
 
 var (
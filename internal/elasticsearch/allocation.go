@@ -0,0 +1,110 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	estypes "github.com/openshift/elasticsearch-operator/internal/types/elasticsearch"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxAllocationExplanationLength truncates the reported reason of unassigned shards
+const maxAllocationExplanationLength = 512
+
+// unassignedShardsExplainAfter is how long a cluster has unassigned shards before the
+// reason is explained in its status, so that shards merely recovering are not reported
+var unassignedShardsExplainAfter = 5 * time.Minute
+
+// updateUnassignedShardsCondition explains why shards of the cluster are unassigned once
+// they are for longer than unassignedShardsExplainAfter, e.g. because of disk watermarks
+// or nodes matching no allocation filter
+func (er *ElasticsearchRequest) updateUnassignedShardsCondition(status *api.ElasticsearchStatus) {
+	health := status.Cluster
+	if health.Status == healthUnknown || health.Status == "" {
+		return
+	}
+
+	if health.UnassignedShards <= 0 {
+		status.UnassignedShardsSince = nil
+		updateESNodeCondition(status, &api.ClusterCondition{
+			Type:   api.UnassignedShards,
+			Status: v1.ConditionFalse,
+		})
+		return
+	}
+
+	// the time is kept in the status to survive restarts of the operator
+	if status.UnassignedShardsSince == nil {
+		now := metav1.Now()
+		status.UnassignedShardsSince = &now
+		return
+	}
+	if time.Since(status.UnassignedShardsSince.Time) < unassignedShardsExplainAfter {
+		return
+	}
+
+	explanation, err := er.esClient.AllocationExplain()
+	if err != nil {
+		er.L().Info("Unable to explain unassigned shards", "error", err)
+		return
+	}
+	if explanation == nil {
+		return
+	}
+
+	message := truncateExplanation(describeAllocation(explanation))
+	changed := updateESNodeCondition(status, &api.ClusterCondition{
+		Type:    api.UnassignedShards,
+		Status:  v1.ConditionTrue,
+		Reason:  "Shards Not Allocated",
+		Message: message,
+	})
+	if changed {
+		er.L().Info("Shards are unassigned", "unassignedShards", health.UnassignedShards, "reason", message)
+		recordEvent(er.recorder, er.cluster, v1.EventTypeWarning, eventReasonShardsUnassigned, message)
+	}
+}
+
+// describeAllocation returns a human readable reason of the unassigned shard, including
+// the first decider refusing the shard on each node
+func describeAllocation(explanation *estypes.AllocationExplainResponse) string {
+	kind := "replica"
+	if explanation.Primary {
+		kind = "primary"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "The %s shard [%s][%d] is %s", kind, explanation.Index, explanation.Shard, strings.ToLower(explanation.CurrentState))
+	if info := explanation.UnassignedInfo; info != nil && info.Reason != "" {
+		fmt.Fprintf(&sb, " (%s)", info.Reason)
+	}
+	if explanation.AllocateExplanation != "" {
+		fmt.Fprintf(&sb, ": %s", explanation.AllocateExplanation)
+	}
+
+	decisions := []string{}
+	for _, node := range explanation.NodeAllocationDecisions {
+		for _, decider := range node.Deciders {
+			if decider.Decision != "NO" {
+				continue
+			}
+			decisions = append(decisions, fmt.Sprintf("%s: [%s] %s", node.NodeName, decider.Decider, decider.Explanation))
+			break
+		}
+	}
+	if len(decisions) > 0 {
+		fmt.Fprintf(&sb, ". %s", strings.Join(decisions, "; "))
+	}
+
+	return sb.String()
+}
+
+func truncateExplanation(message string) string {
+	if len(message) <= maxAllocationExplanationLength {
+		return message
+	}
+	return message[:maxAllocationExplanationLength-3] + "..."
+}
@@ -0,0 +1,95 @@
+package elasticsearch
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	estypes "github.com/openshift/elasticsearch-operator/internal/types/elasticsearch"
+	"github.com/openshift/elasticsearch-operator/test/helpers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestUpdateUnassignedShardsCondition(t *testing.T) {
+	unassignedShardsExplainAfter = 0
+	defer func() { unassignedShardsExplainAfter = 5 * time.Minute }()
+
+	const (
+		esCluster   = "elasticsearch"
+		esNamespace = "openshift-logging"
+	)
+
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"_cluster/allocation/explain": {
+			{
+				StatusCode: 200,
+				Body: `{"index": "app-000001", "shard": 2, "primary": true, "current_state": "unassigned",
+					"unassigned_info": {"reason": "ALLOCATION_FAILED"},
+					"allocate_explanation": "cannot allocate because allocation is not permitted to any of the nodes",
+					"node_allocation_decisions": [{"node_name": "elasticsearch-cdm-1-deadbeef", "deciders": [
+						{"decider": "filter", "decision": "NO", "explanation": "node does not match index setting [index.routing.allocation.require] filters [data:\"cold\"]"}]}]}`,
+			},
+		},
+	})
+
+	k8sClient := fake.NewFakeClient()
+	recorder := record.NewFakeRecorder(2)
+	er := &ElasticsearchRequest{
+		cluster: &loggingv1.Elasticsearch{
+			ObjectMeta: metav1.ObjectMeta{Name: esCluster, Namespace: esNamespace},
+		},
+		client:   k8sClient,
+		esClient: helpers.NewFakeElasticsearchClient(esCluster, esNamespace, k8sClient, chatter),
+		recorder: recorder,
+	}
+
+	status := &loggingv1.ElasticsearchStatus{
+		Cluster: loggingv1.ClusterHealth{Status: "yellow", UnassignedShards: 1},
+	}
+
+	// the first reconcile seeing unassigned shards only starts measuring
+	er.updateUnassignedShardsCondition(status)
+	if _, condition := getESNodeCondition(status.Conditions, loggingv1.UnassignedShards); condition != nil {
+		t.Fatalf("Exp. no condition before the threshold elapsed but got %v", condition)
+	}
+	if status.UnassignedShardsSince == nil {
+		t.Fatal("Exp. the time of the first unassigned shards to be kept in the status")
+	}
+
+	er.updateUnassignedShardsCondition(status)
+	_, condition := getESNodeCondition(status.Conditions, loggingv1.UnassignedShards)
+	want := `The primary shard [app-000001][2] is unassigned (ALLOCATION_FAILED): cannot allocate because allocation is not permitted to any of the nodes. ` +
+		`elasticsearch-cdm-1-deadbeef: [filter] node does not match index setting [index.routing.allocation.require] filters [data:"cold"]`
+	if condition == nil || condition.Status != corev1.ConditionTrue || condition.Message != want {
+		t.Fatalf("Exp. the condition to explain the unassigned shard with %q but got %v", want, condition)
+	}
+	if event := <-recorder.Events; !strings.Contains(event, eventReasonShardsUnassigned) {
+		t.Errorf("Exp. a warning event for the unassigned shards but got %q", event)
+	}
+
+	status.Cluster = loggingv1.ClusterHealth{Status: "green"}
+	er.updateUnassignedShardsCondition(status)
+	if _, condition := getESNodeCondition(status.Conditions, loggingv1.UnassignedShards); condition != nil {
+		t.Errorf("Exp. the condition to be removed once all shards are assigned but got %v", condition)
+	}
+	if status.UnassignedShardsSince != nil {
+		t.Errorf("Exp. the time of the unassigned shards to be cleared but got %v", status.UnassignedShardsSince)
+	}
+}
+
+func TestTruncateExplanation(t *testing.T) {
+	explanation := &estypes.AllocationExplainResponse{
+		Index:               "app-000001",
+		CurrentState:        "unassigned",
+		AllocateExplanation: strings.Repeat("x", 2*maxAllocationExplanationLength),
+	}
+
+	got := truncateExplanation(describeAllocation(explanation))
+	if len(got) != maxAllocationExplanationLength || !strings.HasSuffix(got, "...") {
+		t.Errorf("Exp. the explanation to be truncated to %d characters but got %d", maxAllocationExplanationLength, len(got))
+	}
+}
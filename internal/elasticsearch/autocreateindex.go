@@ -0,0 +1,86 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"strings"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+)
+
+const autoCreateIndexSetting = "action.auto_create_index"
+
+// denyWriteIndexPattern keeps clients from creating indices named like the write aliases
+// of the rollover, which then could not be created anymore. It is the first pattern of
+// the setting, whether rendered into the configuration or set through the spec.
+const denyWriteIndexPattern = "-*-write"
+
+// forbiddenIndexNameChars are the characters Elasticsearch rejects in index names, the
+// comma also separating the patterns of the setting
+const forbiddenIndexNameChars = `\/?"<>| ,#`
+
+// getInvalidAutoCreateIndexPatterns returns the patterns which match no valid index name,
+// i.e. are empty or contain uppercase or forbidden characters
+func getInvalidAutoCreateIndexPatterns(dpl *api.Elasticsearch) []string {
+	invalid := []string{}
+	if dpl.Spec.AutoCreateIndex == nil {
+		return invalid
+	}
+
+	for _, pattern := range dpl.Spec.AutoCreateIndex.Patterns {
+		name := strings.TrimLeft(pattern, "+-")
+		switch {
+		case len(pattern)-len(name) > 1:
+			invalid = append(invalid, fmt.Sprintf("%q (more than one prefix)", pattern))
+		case name == "":
+			invalid = append(invalid, fmt.Sprintf("%q (empty)", pattern))
+		case strings.ContainsAny(name, forbiddenIndexNameChars):
+			invalid = append(invalid, fmt.Sprintf("%q (forbidden character)", pattern))
+		case strings.ToLower(name) != name:
+			invalid = append(invalid, fmt.Sprintf("%q (uppercase)", pattern))
+		}
+	}
+	return invalid
+}
+
+// autoCreateIndexValue returns the value of action.auto_create_index allowing the
+// patterns after denying the write aliases, false to disable the implicit creation of
+// indices, or nil to reset the setting to the one of the configuration if unmanaged
+func autoCreateIndexValue(spec *api.ElasticsearchAutoCreateIndexSpec) interface{} {
+	if spec == nil {
+		return nil
+	}
+	if len(spec.Patterns) == 0 {
+		return "false"
+	}
+	return strings.Join(append([]string{denyWriteIndexPattern}, spec.Patterns...), ",")
+}
+
+// UpdateAutoCreateIndex sets the persistent action.auto_create_index to the patterns of
+// the spec if the cluster drifted from them, and resets it once the spec does not manage
+// it anymore.
+func (er *ElasticsearchRequest) UpdateAutoCreateIndex() error {
+	if !er.AnyNodeReady() {
+		return nil
+	}
+
+	current, err := er.esClient.GetClusterSetting(autoCreateIndexSetting)
+	if err != nil {
+		return err
+	}
+
+	desired := autoCreateIndexValue(er.cluster.Spec.AutoCreateIndex)
+	if desired == nil && current == nil {
+		return nil
+	}
+	if desired != nil && current != nil && fmt.Sprint(current) == desired {
+		return nil
+	}
+
+	if err := er.esClient.UpdateClusterSettings(map[string]interface{}{autoCreateIndexSetting: desired}); err != nil {
+		return err
+	}
+	er.L().Info("Updated auto create index patterns",
+		"previous", current,
+		"patterns", desired)
+	return nil
+}
@@ -0,0 +1,138 @@
+package elasticsearch
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/test/helpers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestGetInvalidAutoCreateIndexPatterns(t *testing.T) {
+	tests := []struct {
+		desc     string
+		patterns []string
+		want     []string
+	}{
+		{desc: "allow and deny patterns", patterns: []string{"+app-*", "-*", "infra-*"}, want: []string{}},
+		{desc: "disabled", patterns: []string{}, want: []string{}},
+		{desc: "empty patterns", patterns: []string{"", "+"}, want: []string{`"" (empty)`, `"+" (empty)`}},
+		{desc: "several prefixes", patterns: []string{"+-app-*"}, want: []string{`"+-app-*" (more than one prefix)`}},
+		{desc: "forbidden characters", patterns: []string{"app,infra", "app logs", "app#1"}, want: []string{`"app,infra" (forbidden character)`, `"app logs" (forbidden character)`, `"app#1" (forbidden character)`}},
+		{desc: "uppercase", patterns: []string{"+App-*"}, want: []string{`"+App-*" (uppercase)`}},
+	}
+	for _, test := range tests {
+		dpl := &loggingv1.Elasticsearch{
+			Spec: loggingv1.ElasticsearchSpec{
+				AutoCreateIndex: &loggingv1.ElasticsearchAutoCreateIndexSpec{Patterns: test.patterns},
+			},
+		}
+		if got := getInvalidAutoCreateIndexPatterns(dpl); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.desc, got, test.want)
+		}
+	}
+
+	if got := getInvalidAutoCreateIndexPatterns(&loggingv1.Elasticsearch{}); len(got) != 0 {
+		t.Errorf("Exp. no invalid patterns if unmanaged but got %v", got)
+	}
+}
+
+func TestUpdateAutoCreateIndex(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch", Namespace: "openshift-logging"},
+	}
+	ready := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch-cdm-1",
+			Namespace: cluster.Namespace,
+			Labels: map[string]string{
+				"component":    "elasticsearch",
+				"cluster-name": cluster.Name,
+				"es-node-data": "true",
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{Ready: true}},
+		},
+	}
+	k8sClient := fake.NewFakeClient(cluster, ready)
+
+	tests := []struct {
+		desc    string
+		spec    *loggingv1.ElasticsearchAutoCreateIndexSpec
+		current string
+		want    string
+	}{
+		{
+			desc:    "unset setting",
+			spec:    &loggingv1.ElasticsearchAutoCreateIndexSpec{Patterns: []string{"+app-*", "-*"}},
+			current: `{"persistent": {}, "transient": {}}`,
+			want:    `{"persistent":{"action.auto_create_index":"-*-write,+app-*,-*"}}`,
+		},
+		{
+			desc:    "drifted setting",
+			spec:    &loggingv1.ElasticsearchAutoCreateIndexSpec{Patterns: []string{"+app-*", "-*"}},
+			current: `{"persistent": {"action.auto_create_index": "+app-*,-*"}, "transient": {}}`,
+			want:    `{"persistent":{"action.auto_create_index":"-*-write,+app-*,-*"}}`,
+		},
+		{
+			desc:    "matching setting",
+			spec:    &loggingv1.ElasticsearchAutoCreateIndexSpec{Patterns: []string{"+app-*", "-*"}},
+			current: `{"persistent": {"action.auto_create_index": "-*-write,+app-*,-*"}, "transient": {}}`,
+		},
+		{
+			desc:    "disabled",
+			spec:    &loggingv1.ElasticsearchAutoCreateIndexSpec{},
+			current: `{"persistent": {"action.auto_create_index": "-*-write,+app-*,-*"}, "transient": {}}`,
+			want:    `{"persistent":{"action.auto_create_index":"false"}}`,
+		},
+		{
+			desc:    "removed spec",
+			current: `{"persistent": {"action.auto_create_index": "-*-write,+app-*,-*"}, "transient": {}}`,
+			want:    `{"persistent":{"action.auto_create_index":null}}`,
+		},
+		{
+			desc:    "unmanaged setting",
+			current: `{"persistent": {}, "transient": {}}`,
+		},
+	}
+	for _, test := range tests {
+		cluster.Spec.AutoCreateIndex = test.spec
+		chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+			"_cluster/settings?flat_settings=true": {
+				{StatusCode: http.StatusOK, Body: test.current},
+			},
+			"_cluster/settings": {
+				{StatusCode: http.StatusOK, Body: `{"acknowledged": true}`},
+			},
+		})
+		er := &ElasticsearchRequest{
+			cluster:  cluster,
+			client:   k8sClient,
+			esClient: helpers.NewFakeElasticsearchClient(cluster.Name, cluster.Namespace, k8sClient, chatter),
+			ll:       log.Log.WithValues("cluster", cluster.Name, "namespace", cluster.Namespace),
+		}
+
+		if err := er.UpdateAutoCreateIndex(); err != nil {
+			t.Errorf("%s: failed with error: %s", test.desc, err)
+			continue
+		}
+		req, updated := chatter.GetRequest("_cluster/settings")
+		if updated != (test.want != "") {
+			t.Errorf("%s: Exp. the setting to be updated %t but got %t", test.desc, test.want != "", updated)
+			continue
+		}
+		if updated && req.Body != test.want {
+			t.Errorf("%s: Exp. the update %s but got %s", test.desc, test.want, req.Body)
+		}
+	}
+}
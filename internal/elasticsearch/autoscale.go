@@ -0,0 +1,186 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ViaQ/logerr/kverrors"
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/hpa"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
+	apps "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultTargetCPUUtilization is the average CPU utilization autoscaled nodes are scaled
+// to unless other metrics are set
+const defaultTargetCPUUtilization int32 = 80
+
+// isAutoscaled returns true if the nodes of the group are scaled by an autoscaler. It is
+// ignored for groups holding shards or voting for the master, which must be scaled by the
+// operator.
+func isAutoscaled(node api.ElasticsearchNode) bool {
+	return node.Autoscale != nil && getNodeRoles(node).IsCoordinatingOnly()
+}
+
+// autoscaleMinReplicas returns the lower limit of the nodes of an autoscaled group
+func autoscaleMinReplicas(node api.ElasticsearchNode) int32 {
+	if node.Autoscale.MinReplicas != nil {
+		return *node.Autoscale.MinReplicas
+	}
+	if node.NodeCount > 0 {
+		return node.NodeCount
+	}
+	return 1
+}
+
+// autoscaleMetrics returns the metrics an autoscaled group is scaled by, the CPU
+// utilization being added if set or no other metric is
+func autoscaleMetrics(node api.ElasticsearchNode) []autoscalingv2beta2.MetricSpec {
+	metrics := append([]autoscalingv2beta2.MetricSpec{}, node.Autoscale.Metrics...)
+
+	target := node.Autoscale.TargetCPUUtilizationPercentage
+	if target == nil && len(metrics) > 0 {
+		return metrics
+	}
+	if target == nil {
+		utilization := defaultTargetCPUUtilization
+		target = &utilization
+	}
+
+	return append([]autoscalingv2beta2.MetricSpec{{
+		Type: autoscalingv2beta2.ResourceMetricSourceType,
+		Resource: &autoscalingv2beta2.ResourceMetricSource{
+			Name: v1.ResourceCPU,
+			Target: autoscalingv2beta2.MetricTarget{
+				Type:               autoscalingv2beta2.UtilizationMetricType,
+				AverageUtilization: target,
+			},
+		},
+	}}, metrics...)
+}
+
+// getInvalidAutoscaling returns the node groups autoscaled though they are not coordinating
+// only, or bounded by replicas below 1 or a lower limit above the upper one
+func getInvalidAutoscaling(dpl *api.Elasticsearch) []string {
+	invalid := []string{}
+
+	for i, node := range dpl.Spec.Nodes {
+		autoscale := node.Autoscale
+		if autoscale == nil {
+			continue
+		}
+		if !getNodeRoles(node).IsCoordinatingOnly() {
+			invalid = append(invalid, fmt.Sprintf("nodes[%d] (autoscaling of nodes not coordinating only)", i))
+			continue
+		}
+
+		minReplicas := autoscaleMinReplicas(node)
+		switch {
+		case autoscale.MaxReplicas < 1:
+			invalid = append(invalid, fmt.Sprintf("nodes[%d] (maxReplicas %d below 1)", i, autoscale.MaxReplicas))
+		case minReplicas < 1:
+			invalid = append(invalid, fmt.Sprintf("nodes[%d] (minReplicas %d below 1)", i, minReplicas))
+		case minReplicas > autoscale.MaxReplicas:
+			invalid = append(invalid, fmt.Sprintf("nodes[%d] (minReplicas %d above maxReplicas %d)", i, minReplicas, autoscale.MaxReplicas))
+		case autoscale.TargetCPUUtilizationPercentage != nil && *autoscale.TargetCPUUtilizationPercentage < 1:
+			invalid = append(invalid, fmt.Sprintf("nodes[%d] (targetCPUUtilizationPercentage %d below 1)", i, *autoscale.TargetCPUUtilizationPercentage))
+		}
+	}
+
+	return invalid
+}
+
+// newHorizontalPodAutoscaler returns the autoscaler scaling the statefulset of the nodes of
+// the group
+func newHorizontalPodAutoscaler(cluster *api.Elasticsearch, nodeName string, node api.ElasticsearchNode) *autoscalingv2beta2.HorizontalPodAutoscaler {
+	labels := appendDefaultLabel(cluster.Name, map[string]string{
+		"component": "elasticsearch",
+	})
+
+	autoscaler := hpa.New(nodeName, cluster.Namespace, labels).
+		WithAnnotations(utils.WithPropagatedAnnotations(nil, propagatedAnnotations(cluster))).
+		WithScaleTargetRef(apps.SchemeGroupVersion.String(), "StatefulSet", nodeName).
+		WithReplicas(autoscaleMinReplicas(node), node.Autoscale.MaxReplicas).
+		WithMetrics(autoscaleMetrics(node)...).
+		Build()
+
+	cluster.AddOwnerRefTo(autoscaler)
+	return autoscaler
+}
+
+// CreateOrUpdateAutoscalers ensures the autoscalers of the autoscaled node groups exist
+// and removes those of the groups no longer autoscaled
+func (er *ElasticsearchRequest) CreateOrUpdateAutoscalers() error {
+	cluster := er.cluster
+
+	desired := sets.NewString()
+	for _, node := range cluster.Spec.Nodes {
+		if !isAutoscaled(node) || node.GenUUID == nil || isNodeGroupReplaced(cluster, node) {
+			continue
+		}
+
+		nodeName := getGeneratedNodeNames(cluster.Name, *node.GenUUID, node)[0]
+		autoscaler := newHorizontalPodAutoscaler(cluster, nodeName, node)
+		if err := hpa.CreateOrUpdate(context.TODO(), er.client, autoscaler, autoscalerEqual, autoscalerMutate); err != nil {
+			return kverrors.Wrap(err, "failed to create or update elasticsearch node autoscaler",
+				"cluster", cluster.Name,
+				"node", nodeName,
+			)
+		}
+		desired.Insert(nodeName)
+	}
+
+	selector := appendDefaultLabel(cluster.Name, map[string]string{
+		"component": "elasticsearch",
+	})
+	autoscalers, err := hpa.List(context.TODO(), er.client, cluster.Namespace, selector)
+	if err != nil {
+		return kverrors.Wrap(err, "failed to list elasticsearch node autoscalers",
+			"cluster", cluster.Name,
+		)
+	}
+
+	for _, autoscaler := range autoscalers {
+		if desired.Has(autoscaler.Name) {
+			continue
+		}
+
+		er.L().Info("Removing autoscaler of node group no longer autoscaled", "node", autoscaler.Name)
+		key := client.ObjectKey{Name: autoscaler.Name, Namespace: autoscaler.Namespace}
+		if err := hpa.Delete(context.TODO(), er.client, key); err != nil && !apierrors.IsNotFound(kverrors.Root(err)) {
+			return kverrors.Wrap(err, "failed to delete elasticsearch node autoscaler",
+				"cluster", cluster.Name,
+				"node", autoscaler.Name,
+			)
+		}
+	}
+
+	return nil
+}
+
+// autoscalerEqual compares the operator managed fields of the autoscalers, so that fields
+// defaulted by the API server, e.g. the scaling behavior, do not cause updates
+func autoscalerEqual(current, desired *autoscalingv2beta2.HorizontalPodAutoscaler) bool {
+	return equality.Semantic.DeepEqual(current.Labels, desired.Labels) &&
+		equality.Semantic.DeepEqual(current.Spec.ScaleTargetRef, desired.Spec.ScaleTargetRef) &&
+		equality.Semantic.DeepEqual(current.Spec.MinReplicas, desired.Spec.MinReplicas) &&
+		current.Spec.MaxReplicas == desired.Spec.MaxReplicas &&
+		equality.Semantic.DeepEqual(current.Spec.Metrics, desired.Spec.Metrics) &&
+		utils.ContainsPropagatedAnnotations(current.Annotations, desired.Annotations)
+}
+
+// autoscalerMutate updates the operator managed fields of the autoscalers
+func autoscalerMutate(current, desired *autoscalingv2beta2.HorizontalPodAutoscaler) {
+	current.Labels = desired.Labels
+	current.Spec.ScaleTargetRef = desired.Spec.ScaleTargetRef
+	current.Spec.MinReplicas = desired.Spec.MinReplicas
+	current.Spec.MaxReplicas = desired.Spec.MaxReplicas
+	current.Spec.Metrics = desired.Spec.Metrics
+	current.Annotations = utils.MergePropagatedAnnotations(current.Annotations, desired.Annotations)
+}
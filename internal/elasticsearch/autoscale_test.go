@@ -0,0 +1,186 @@
+package elasticsearch
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestGetInvalidAutoscaling(t *testing.T) {
+	int32Ptr := func(v int32) *int32 { return &v }
+	client := []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleClient}
+
+	tests := []struct {
+		desc    string
+		nodes   []loggingv1.ElasticsearchNode
+		invalid []string
+	}{
+		{
+			desc: "coordinating only nodes",
+			nodes: []loggingv1.ElasticsearchNode{
+				{Roles: client, NodeCount: 2, Autoscale: &loggingv1.ElasticsearchNodeAutoscaleSpec{MaxReplicas: 5}},
+			},
+		},
+		{
+			desc: "data and master nodes",
+			nodes: []loggingv1.ElasticsearchNode{
+				{Roles: []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleClient, loggingv1.ElasticsearchRoleData}, Autoscale: &loggingv1.ElasticsearchNodeAutoscaleSpec{MaxReplicas: 5}},
+				{Roles: []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleMaster}, Autoscale: &loggingv1.ElasticsearchNodeAutoscaleSpec{MaxReplicas: 5}},
+			},
+			invalid: []string{"nodes[0] (autoscaling of nodes not coordinating only)", "nodes[1] (autoscaling of nodes not coordinating only)"},
+		},
+		{
+			desc: "invalid bounds",
+			nodes: []loggingv1.ElasticsearchNode{
+				{Roles: client, Autoscale: &loggingv1.ElasticsearchNodeAutoscaleSpec{}},
+				{Roles: client, NodeCount: 4, Autoscale: &loggingv1.ElasticsearchNodeAutoscaleSpec{MaxReplicas: 3}},
+				{Roles: client, Autoscale: &loggingv1.ElasticsearchNodeAutoscaleSpec{MinReplicas: int32Ptr(0), MaxReplicas: 3}},
+				{Roles: client, Autoscale: &loggingv1.ElasticsearchNodeAutoscaleSpec{MaxReplicas: 3, TargetCPUUtilizationPercentage: int32Ptr(0)}},
+			},
+			invalid: []string{
+				"nodes[0] (maxReplicas 0 below 1)",
+				"nodes[1] (minReplicas 4 above maxReplicas 3)",
+				"nodes[2] (minReplicas 0 below 1)",
+				"nodes[3] (targetCPUUtilizationPercentage 0 below 1)",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			cluster := &loggingv1.Elasticsearch{Spec: loggingv1.ElasticsearchSpec{Nodes: test.nodes}}
+			invalid := getInvalidAutoscaling(cluster)
+			if strings.Join(invalid, ", ") != strings.Join(test.invalid, ", ") {
+				t.Errorf("Exp. invalid autoscaling %v but got %v", test.invalid, invalid)
+			}
+		})
+	}
+}
+
+func TestAutoscaleMetrics(t *testing.T) {
+	utilization := int32(60)
+	requests := autoscalingv2beta2.MetricSpec{
+		Type: autoscalingv2beta2.PodsMetricSourceType,
+		Pods: &autoscalingv2beta2.PodsMetricSource{
+			Metric: autoscalingv2beta2.MetricIdentifier{Name: "es_search_requests_per_second"},
+		},
+	}
+
+	tests := []struct {
+		desc      string
+		autoscale loggingv1.ElasticsearchNodeAutoscaleSpec
+		cpu       int32
+		count     int
+	}{
+		{desc: "default CPU utilization", autoscale: loggingv1.ElasticsearchNodeAutoscaleSpec{}, cpu: defaultTargetCPUUtilization, count: 1},
+		{desc: "custom metrics only", autoscale: loggingv1.ElasticsearchNodeAutoscaleSpec{Metrics: []autoscalingv2beta2.MetricSpec{requests}}, count: 1},
+		{desc: "CPU utilization and custom metrics", autoscale: loggingv1.ElasticsearchNodeAutoscaleSpec{TargetCPUUtilizationPercentage: &utilization, Metrics: []autoscalingv2beta2.MetricSpec{requests}}, cpu: utilization, count: 2},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			metrics := autoscaleMetrics(loggingv1.ElasticsearchNode{Autoscale: &test.autoscale})
+			if len(metrics) != test.count {
+				t.Fatalf("Exp. %d metrics but got %v", test.count, metrics)
+			}
+
+			if test.cpu == 0 {
+				if metrics[0].Type != autoscalingv2beta2.PodsMetricSourceType {
+					t.Errorf("Exp. no CPU utilization metric but got %v", metrics)
+				}
+				return
+			}
+			if metrics[0].Resource == nil || metrics[0].Resource.Name != corev1.ResourceCPU || *metrics[0].Resource.Target.AverageUtilization != test.cpu {
+				t.Errorf("Exp. a CPU utilization of %d but got %v", test.cpu, metrics[0])
+			}
+		})
+	}
+}
+
+func TestCreateOrUpdateAutoscalers(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+
+	uuid := "abcd1234"
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch",
+			Namespace: "openshift-logging",
+		},
+		Spec: loggingv1.ElasticsearchSpec{
+			Nodes: []loggingv1.ElasticsearchNode{
+				{
+					Roles:     []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleClient},
+					NodeCount: 2,
+					GenUUID:   &uuid,
+					Autoscale: &loggingv1.ElasticsearchNodeAutoscaleSpec{MaxReplicas: 5},
+				},
+			},
+		},
+	}
+
+	k8sClient := fake.NewFakeClient(cluster)
+	er := &ElasticsearchRequest{
+		client:  k8sClient,
+		cluster: cluster,
+		ll:      log.Log.WithValues("cluster", "test-elasticsearch", "namespace", "test"),
+	}
+
+	if err := er.CreateOrUpdateAutoscalers(); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+
+	nodeName := getGeneratedNodeNames(cluster.Name, uuid, cluster.Spec.Nodes[0])[0]
+	key := client.ObjectKey{Name: nodeName, Namespace: cluster.Namespace}
+	got := &autoscalingv2beta2.HorizontalPodAutoscaler{}
+	if err := k8sClient.Get(context.TODO(), key, got); err != nil {
+		t.Fatalf("Exp. an autoscaler for the coordinating only nodes but got: %s", err)
+	}
+	if got.Spec.ScaleTargetRef.Kind != "StatefulSet" || got.Spec.ScaleTargetRef.Name != nodeName {
+		t.Errorf("Exp. the autoscaler to target the statefulset %q but got %v", nodeName, got.Spec.ScaleTargetRef)
+	}
+	if *got.Spec.MinReplicas != 2 || got.Spec.MaxReplicas != 5 {
+		t.Errorf("Exp. the autoscaler to scale between the node count and 5 but got %d and %d", *got.Spec.MinReplicas, got.Spec.MaxReplicas)
+	}
+	if len(got.OwnerReferences) != 1 {
+		t.Errorf("Exp. the autoscaler to be owned by the cluster but got %v", got.OwnerReferences)
+	}
+
+	cluster.Spec.Nodes[0].Autoscale = nil
+	if err := er.CreateOrUpdateAutoscalers(); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+	if err := k8sClient.Get(context.TODO(), key, &autoscalingv2beta2.HorizontalPodAutoscaler{}); err == nil {
+		t.Errorf("Exp. the autoscaler of the nodes no longer autoscaled to be removed")
+	}
+}
+
+func TestNewStatefulSetNodeAutoscaled(t *testing.T) {
+	minReplicas := int32(3)
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch", Namespace: "openshift-logging"},
+	}
+	node := loggingv1.ElasticsearchNode{
+		Roles:     []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleClient},
+		NodeCount: 1,
+		Autoscale: &loggingv1.ElasticsearchNodeAutoscaleSpec{MinReplicas: &minReplicas, MaxReplicas: 5},
+	}
+
+	n := newStatefulSetNode("elasticsearch-c-abcd1234", node, cluster, getNodeRoles(node), fake.NewFakeClient(), nil, nil, log.Log).(*statefulSetNode)
+	if !n.autoscaled {
+		t.Errorf("Exp. the node to be autoscaled")
+	}
+	if *n.self.Spec.Replicas != minReplicas {
+		t.Errorf("Exp. the statefulset to be created with the %d min replicas but got %d", minReplicas, *n.self.Spec.Replicas)
+	}
+}
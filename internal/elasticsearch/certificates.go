@@ -122,6 +122,8 @@ type CertificateRequest struct {
 	Namespace   string
 	OwnerRef    metav1.OwnerReference
 	K8sClient   client.Client
+	// Annotations are propagated to the secrets of the certificates
+	Annotations map[string]string
 
 	Extensions map[string]x509v3Ext
 }
@@ -198,7 +200,7 @@ func (cr *CertificateRequest) GenerateComponentCerts(secretName, cn string) {
 		componentCAName:   ca,
 	}
 
-	if err := CreateOrUpdateSecretWithOwnerRef(secretName, cr.Namespace, componentSecretData, cr.K8sClient, cr.OwnerRef); err != nil {
+	if err := CreateOrUpdateSecretWithOwnerRef(secretName, cr.Namespace, componentSecretData, cr.Annotations, cr.K8sClient, cr.OwnerRef); err != nil {
 		log.Error(err, "Unable to create secret for component")
 		return
 	}
@@ -235,7 +237,7 @@ func (cr *CertificateRequest) GenerateKibanaCerts(componentName string) {
 		kibanaComponentCAName:   ca,
 	}
 
-	if err = CreateOrUpdateSecretWithOwnerRef(kibanaSecretName, cr.Namespace, kibanaSecretData, cr.K8sClient, cr.OwnerRef); err != nil {
+	if err = CreateOrUpdateSecretWithOwnerRef(kibanaSecretName, cr.Namespace, kibanaSecretData, cr.Annotations, cr.K8sClient, cr.OwnerRef); err != nil {
 		log.Error(err, "Unable to create secret for kibana component")
 		return
 	}
@@ -274,7 +276,7 @@ func (cr *CertificateRequest) GenerateKibanaCerts(componentName string) {
 		kibanaInternalKeyName:           kibanaProxyCert.key,
 	}
 
-	if err = CreateOrUpdateSecretWithOwnerRef(getKibanaProxySecretName(kibanaSecretName), cr.Namespace, secretData, cr.K8sClient, cr.OwnerRef); err != nil {
+	if err = CreateOrUpdateSecretWithOwnerRef(getKibanaProxySecretName(kibanaSecretName), cr.Namespace, secretData, cr.Annotations, cr.K8sClient, cr.OwnerRef); err != nil {
 		log.Error(err, "Unable to create secret for kibana-proxy")
 		return
 	}
@@ -339,7 +341,7 @@ func (cr *CertificateRequest) GenerateElasticsearchCerts(clusterName string) {
 		esAdminCAName:       ca,
 	}
 
-	if err := CreateOrUpdateSecretWithOwnerRef(clusterName, cr.Namespace, secretData, cr.K8sClient, cr.OwnerRef); err != nil {
+	if err := CreateOrUpdateSecretWithOwnerRef(clusterName, cr.Namespace, secretData, cr.Annotations, cr.K8sClient, cr.OwnerRef); err != nil {
 		log.Error(err, "Unable to create secret for elasticsearch component")
 		return
 	}
@@ -354,7 +356,7 @@ func (cr *CertificateRequest) persistCA(caCert *certCA) error {
 		esCASerialName: []byte(caCert.serial.Text(10)),
 	}
 
-	return CreateOrUpdateSecretWithOwnerRef(secretName, cr.Namespace, secretData, cr.K8sClient, cr.OwnerRef)
+	return CreateOrUpdateSecretWithOwnerRef(secretName, cr.Namespace, secretData, cr.Annotations, cr.K8sClient, cr.OwnerRef)
 }
 
 func (cr *CertificateRequest) ensureCA(caCert *certCA) error {
@@ -3,12 +3,14 @@ package elasticsearch
 import (
 	"context"
 	"fmt"
+	"time"
+
+	"github.com/ViaQ/logerr/kverrors"
 
 	"github.com/openshift/elasticsearch-operator/internal/constants"
 
 	"github.com/openshift/elasticsearch-operator/internal/metrics"
 
-	"github.com/ViaQ/logerr/log"
 	"github.com/openshift/elasticsearch-operator/internal/utils"
 	"github.com/openshift/elasticsearch-operator/internal/utils/comparators"
 
@@ -19,6 +21,14 @@ import (
 	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
 )
 
+const (
+	// recoveryInProgressReason is the reason of the RecoveryInProgress condition
+	recoveryInProgressReason = "Shards Unassigned"
+	// nodeDrainingReason is the reason of the NodeDraining condition, reporting the shards
+	// remaining on a node which is relocating them before it is removed
+	nodeDrainingReason = "Relocating Shards"
+)
+
 const expectedMinVersion = "6.0"
 
 var (
@@ -38,7 +48,7 @@ func nodeMapKey(clusterName, namespace string) string {
 
 // CreateOrUpdateElasticsearchCluster creates an Elasticsearch deployment
 func (er *ElasticsearchRequest) CreateOrUpdateElasticsearchCluster() error {
-	ll := log.WithValues("cluster", er.cluster.Name, "namespace", er.cluster.Namespace)
+	ll := er.L()
 	esClient := er.esClient
 
 	// Verify that we didn't scale up too many masters
@@ -69,12 +79,43 @@ func (er *ElasticsearchRequest) CreateOrUpdateElasticsearchCluster() error {
 	if err := er.UpdateClusterStatus(); err != nil {
 		return err
 	}
+
+	// report pods the rollouts wait on but which fail to be scheduled
+	er.updatePodsUnschedulable()
+
+	// report pods rejected by their node for the requested sysctls
+	er.updateSysctlsForbidden()
+
+	// report other clusters claiming the same cluster name whose nodes may join this one
+	er.updateClusterNameConflict()
+
+	// report node deployments kept unpaused for debugging
+	er.updateDebugging()
+
+	// report the master set growing or shrinking towards the desired count
+	er.updateMasterTransition()
+
+	// restarting or removing nodes while no master is elected may worsen a split-brain,
+	// thus only missing nodes are created until a master is elected
+	if er.updateNoMasterElected() {
+		if err := er.createMissingNodes(); err != nil {
+			return err
+		}
+		return er.UpdateClusterStatus()
+	}
+
 	if err := er.progressUnschedulableNodes(); err != nil {
 		ll.Error(err, "unable to progress unschedulable nodes")
 		return er.UpdateClusterStatus()
 	}
 
+	// clusters with a maintenance window start no cert redeploy or node rollout outside of it
+	inWindow := er.isRolloutInMaintenanceWindow(append(er.getScheduledCertRedeployNodes(), er.getScheduledUpgradeNodes()...), time.Now())
+
 	certRestartNodes := er.getScheduledCertRedeployNodes()
+	if !inWindow {
+		certRestartNodes = nil
+	}
 	stillRecovering := containsClusterCondition(api.Recovering, v1.ConditionTrue, &er.cluster.Status)
 	if len(certRestartNodes) > 0 || stillRecovering {
 		if err := er.PerformFullClusterCertRestart(certRestartNodes); err != nil {
@@ -112,8 +153,30 @@ func (er *ElasticsearchRequest) CreateOrUpdateElasticsearchCluster() error {
 		_ = er.UpdateClusterStatus()
 	}
 
+	// change-controlled clusters start no rollout until it is approved
+	approved, err := er.isRolloutApproved(scheduledNodes)
+	if err != nil {
+		ll.Error(err, "unable to record pending node rollout")
+	}
+	if !approved {
+		scheduledNodes = nil
+	}
+
+	if !inWindow {
+		scheduledNodes = nil
+	}
+
+	// starting another rollout while the cluster recovers shards worsens the recovery
+	if er.updateRecoveryInProgress(len(scheduledNodes) > 0) {
+		_ = er.UpdateClusterStatus()
+		return newRequeueError(RequeueWaitingForHealth, "waiting on shard recovery before starting a node rollout",
+			"cluster", er.cluster.Name,
+		)
+	}
+
 	// We didn't have any in progress, but we have ones scheduled to be updated
 	if len(scheduledNodes) > 0 {
+		er.startWindowRollout()
 
 		// get the current ES version
 		version, err := esClient.GetLowestClusterVersion()
@@ -129,12 +192,12 @@ func (er *ElasticsearchRequest) CreateOrUpdateElasticsearchCluster() error {
 		if comparison > 0 {
 			// perform a full cluster update
 			if err := er.PerformFullClusterUpdate(scheduledNodes); err != nil {
-				log.Error(err, "failed to perform full cluster update")
+				er.L().Error(err, "failed to perform full cluster update")
 				return er.UpdateClusterStatus()
 			}
 		} else {
 			if err := er.PerformRollingUpdate(scheduledNodes); err != nil {
-				log.Error(err, "failed to perform rolling update")
+				er.L().Error(err, "failed to perform rolling update")
 				return er.UpdateClusterStatus()
 			}
 			metrics.IncrementRestartCounterRolling()
@@ -145,12 +208,70 @@ func (er *ElasticsearchRequest) CreateOrUpdateElasticsearchCluster() error {
 
 	if er.getNodeUpgradeInProgress() == nil {
 		// We have no updates or restarts in progress
+		// no node deployment may roll outside of an update or restart
+		if err := er.repauseNodes(); err != nil {
+			return err
+		}
+
 		// create any nodes we are missing and perform any required operations to ensure state
-		for _, node := range nodes[nodeMapKey(er.cluster.Name, er.cluster.Namespace)] {
+		var requeueErr error
+		clusterNodes := mastersFirst(nodes[nodeMapKey(er.cluster.Name, er.cluster.Namespace)])
+		waitForMasters := isBootstrapping(clusterNodes)
+		growingMasters, addedMaster := isGrowingMasters(clusterNodes), false
+		for _, node := range clusterNodes {
+			// the master set of a formed cluster grows one node at a time
+			if growingMasters && node.isMaster() && node.isMissing() {
+				if err := er.waitToAddMasterNode(node, clusterNodes, addedMaster); err != nil {
+					ll.Info("Waiting to add master node", "node", node.name(), "reason", err.Error())
+					requeueErr = err
+					continue
+				}
+				addedMaster = true
+				er.resetExistingMasterCount()
+
+				// a master statefulset added to a formed cluster starts with a single node
+				if sts, ok := node.(*statefulSetNode); ok {
+					one := int32(1)
+					sts.self.Spec.Replicas = &one
+				}
+			} else if sts, ok := node.(*statefulSetNode); ok && sts.isMaster() && !waitForMasters {
+				// the replicas of master statefulsets change one at a time as well
+				stepped, err := er.stepMasterReplicas(sts, clusterNodes, addedMaster)
+				if err != nil {
+					ll.Info("Waiting to change master nodes", "node", node.name(), "reason", err.Error())
+					requeueErr = err
+					continue
+				}
+				if stepped {
+					addedMaster = true
+					er.resetExistingMasterCount()
+				}
+			}
+
+			if waitForMasters && !node.isMaster() {
+				// the master eligible nodes are created at this point, let them form the
+				// cluster before the other nodes try to discover it
+				if err := er.waitForMasterNodes(clusterNodes); err != nil {
+					ll.Info("Waiting on master nodes before creating the other nodes", "reason", err.Error())
+					requeueErr = err
+					break
+				}
+				waitForMasters = false
+			}
+
 			clusterStatus := er.cluster.Status.DeepCopy()
 			_, nodeStatus := getNodeStatus(node.name(), clusterStatus)
 
 			if err := node.create(); err != nil {
+				if IsRequeue(err) {
+					// the node is created but its rollout is checked again on the next reconcile
+					ll.Info("Waiting on node rollout", "node", node.name(), "reason", err.Error())
+					requeueErr = err
+					continue
+				}
+				if message, ok := quotaRejectionMessage("node", node.name(), err); ok {
+					er.updateInsufficientQuota(message)
+				}
 				return err
 			}
 
@@ -162,10 +283,22 @@ func (er *ElasticsearchRequest) CreateOrUpdateElasticsearchCluster() error {
 			}
 
 			if err := er.setNodeStatus(node, nodeStatus, clusterStatus); err != nil {
-				log.Error(err, "unable to set status for node", "node", node.name())
+				er.L().Error(err, "unable to set status for node", "node", node.name())
 			}
 		}
 
+		// surface any node or storage creation rejected by a resource quota
+		er.updateInsufficientQuota()
+
+		if requeueErr != nil {
+			return requeueErr
+		}
+
+		// decommission node groups once their replacements joined the cluster
+		if err := er.progressNodeGroupReplacements(); err != nil {
+			ll.Error(err, "unable to progress node group replacements")
+		}
+
 		// ensure that MinMasters is (n / 2 + 1)
 		er.updateMinMasters()
 
@@ -197,8 +330,40 @@ func (er *ElasticsearchRequest) CreateOrUpdateElasticsearchCluster() error {
 		}
 	}
 
+	// delete the node deployments left behind by a topology change, a step per reconcile
+	pruneErr := er.pruneStaleNodeDeployments()
+	if pruneErr != nil && !IsRequeue(pruneErr) {
+		ll.Error(pruneErr, "unable to prune stale node deployments")
+	}
+
 	// Scrape cluster health from elasticsearch every time
-	return er.UpdateClusterStatus()
+	if err := er.UpdateClusterStatus(); err != nil {
+		return err
+	}
+
+	if IsRequeue(pruneErr) {
+		return pruneErr
+	}
+	return nil
+}
+
+// repauseNodes pauses any node deployment left unpaused while no update or restart is in
+// progress, so that it does not roll on unrelated changes
+func (er *ElasticsearchRequest) repauseNodes() error {
+	for _, node := range nodes[nodeMapKey(er.cluster.Name, er.cluster.Namespace)] {
+		dplNode, ok := node.(*deploymentNode)
+		if !ok {
+			continue
+		}
+
+		if _, err := dplNode.repause(); err != nil {
+			return kverrors.Wrap(err, "failed to pause node left unpaused",
+				"node", node.name(),
+			)
+		}
+	}
+
+	return nil
 }
 
 func (er *ElasticsearchRequest) getNodeUpgradeInProgress() NodeTypeInterface {
@@ -229,12 +394,12 @@ func (er *ElasticsearchRequest) progressUnschedulableNodes() error {
 			for _, node := range clusterNodes {
 				if nodeStatus.DeploymentName == node.name() || nodeStatus.StatefulSetName == node.name() {
 					if node.isMissing() {
-						log.Info("Unschedulable node does not have k8s resource, skipping", "node", node.name())
+						er.L().Info("Unschedulable node does not have k8s resource, skipping", "node", node.name())
 						continue
 					}
 
 					if err := node.progressNodeChanges(); err != nil {
-						log.Error(err, "Failed to progress update of unschedulable node", "node", node.name())
+						er.L().Error(err, "Failed to progress update of unschedulable node", "node", node.name())
 						return err
 					}
 				}
@@ -261,7 +426,7 @@ func (er *ElasticsearchRequest) setUUIDs() {
 }
 
 func (er *ElasticsearchRequest) setUUID(index int, uuid string) {
-	ll := log.WithValues("cluster", er.cluster.Name, "namespace", er.cluster.Namespace)
+	ll := er.L()
 
 	nretries := -1
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
@@ -308,6 +473,11 @@ func (er *ElasticsearchRequest) populateNodes() error {
 
 	// get list of client only nodes, and collapse node info into the node (self field) if needed
 	for _, node := range cluster.Spec.Nodes {
+		// skip node groups decommissioned in favor of their replacement
+		if isNodeGroupReplaced(cluster, node) {
+			continue
+		}
+
 		// build the NodeTypeInterface list
 		for _, nodeTypeInterface := range er.GetNodeTypeInterface(*node.GenUUID, node) {
 
@@ -322,36 +492,112 @@ func (er *ElasticsearchRequest) populateNodes() error {
 	}
 
 	minMasterUpdated := false
+	removedMaster := false
 
-	// we want to only keep nodes that were generated and purge/delete any other ones...
-	// make sure cluster is green/yellow before we delete nodes
+	removedNodes := []NodeTypeInterface{}
 	for _, node := range nodes[nodeMapKey(cluster.Name, cluster.Namespace)] {
 		if _, ok := containsNodeTypeInterface(node, currentNodes); !ok {
+			removedNodes = append(removedNodes, node)
+		}
+	}
+
+	// unless forced, removals must neither lose the only copies of shards nor the master quorum
+	forced := isForcedNodeRemoval(cluster)
+	refused := false
+
+	// we want to only keep nodes that were generated and purge/delete any other ones...
+	// make sure cluster is green/yellow before we delete nodes
+	for index, node := range removedNodes {
+		// the master set shrinks one node at a time so that the quorum follows each step
+		if node.isMaster() && removedMaster {
+			er.L().Info("Master nodes are removed one at a time, keeping node until the next reconcile", "node", node.name())
+			currentNodes = append(currentNodes, node)
+			continue
+		}
+
+		if forced {
+			er.L().Info("Forcing removal of Elasticsearch node", "node", node.name())
+		} else {
 			if status, _ := er.esClient.GetClusterHealthStatus(); !utils.Contains(desiredClusterStates, status) {
-				log.Info("Unable to delete/scale down any Elasticsearch nodes because of current cluster health", "currentHealth", status, "desiredHealth", desiredClusterStates)
+				er.L().Info("Unable to delete/scale down any Elasticsearch nodes because of current cluster health", "currentHealth", status, "desiredHealth", desiredClusterStates)
+				// keep track of the remaining nodes to remove them on a later reconcile
+				currentNodes = append(currentNodes, removedNodes[index:]...)
 				break
 			}
 
-			if !minMasterUpdated {
-				// if we're removing a node make sure we set a lower min masters to keep cluster functional
-				if er.AnyNodeReady() {
-					er.updateMinMasters()
-					minMasterUpdated = true
+			if node.isMaster() {
+				if risk := er.getMasterQuorumRisk(removedNodes[index:]); risk != "" {
+					er.refuseNodeRemoval(node, risk)
+					refused = true
+					currentNodes = append(currentNodes, removedNodes[index:]...)
+					break
 				}
 			}
 
-			if err := node.delete(); err != nil {
-				log.Error(err, "unable to delete node")
+			// relocate the shards off the node before removing it
+			drained, err := er.drainNode(node.name())
+			if err != nil {
+				er.L().Error(err, "unable to exclude node from shard allocation", "node", node.name())
+			}
+			if !drained {
+				er.L().Info("Unable to delete Elasticsearch node until its shards are relocated", "node", node.name())
+				currentNodes = append(currentNodes, removedNodes[index:]...)
+				break
+			}
+		}
+
+		// withdraw the vote of a removed master so that the remaining ones keep the quorum
+		votingExcluded := false
+		if node.isMaster() && er.usesVotingConfigExclusions() {
+			if err := er.esClient.AddVotingConfigExclusion(node.name()); err != nil {
+				er.L().Error(err, "unable to exclude master node from voting", "node", node.name())
+				currentNodes = append(currentNodes, removedNodes[index:]...)
+				break
+			}
+			votingExcluded = true
+		}
+
+		if node.isMaster() {
+			// lower min masters to the quorum of the masters remaining after this one
+			er.setMinMasters(getExistingMasterCount(currentNodes) + getExistingMasterCount(removedNodes[index+1:]))
+			minMasterUpdated = true
+			removedMaster = true
+		} else if !minMasterUpdated {
+			// if we're removing a node make sure we set a lower min masters to keep cluster functional
+			if er.AnyNodeReady() {
+				er.updateMinMasters()
+				minMasterUpdated = true
 			}
+		}
+
+		if err := node.delete(); err != nil {
+			er.L().Error(err, "unable to delete node")
+		}
+
+		if _, err := er.esClient.ClearAllocationExclusions(node.name()); err != nil {
+			er.L().Error(err, "unable to clear shard allocation exclusions", "node", node.name())
+		}
 
-			// remove from status.Nodes
-			if index, _ := getNodeStatus(node.name(), &cluster.Status); index != NotFoundIndex {
-				cluster.Status.Nodes = append(cluster.Status.Nodes[:index], cluster.Status.Nodes[index+1:]...)
+		if votingExcluded {
+			if err := er.esClient.ClearVotingConfigExclusions(); err != nil {
+				er.L().Error(err, "unable to clear voting config exclusions", "node", node.name())
 			}
 		}
+
+		// remove from status.Nodes
+		if index, _ := getNodeStatus(node.name(), &cluster.Status); index != NotFoundIndex {
+			cluster.Status.Nodes = append(cluster.Status.Nodes[:index], cluster.Status.Nodes[index+1:]...)
+		}
+	}
+
+	if !refused && containsClusterCondition(api.UnsafeNodeRemoval, v1.ConditionTrue, &cluster.Status) {
+		if err := updateCondition(cluster, api.UnsafeNodeRemoval, unsafeNodeRemovalReason, v1.ConditionFalse, "", er.client); err != nil {
+			er.L().Error(err, "unable to update unsafe node removal condition")
+		}
 	}
 
 	nodes[nodeMapKey(cluster.Name, cluster.Namespace)] = currentNodes
+	er.resetExistingMasterCount()
 
 	return nil
 }
@@ -405,6 +651,13 @@ func addNodeState(node NodeTypeInterface, nodeStatus *api.ElasticsearchNodeStatu
 	nodeStatus.UpgradeStatus.ScheduledForCertRedeploy = nodeState.UpgradeStatus.ScheduledForCertRedeploy
 	nodeStatus.DeploymentName = nodeState.DeploymentName
 	nodeStatus.StatefulSetName = nodeState.StatefulSetName
+	nodeStatus.Revision = nodeState.Revision
+	nodeStatus.DefaultedResources = nodeState.DefaultedResources
+	nodeStatus.AppliedTemplateHash = nodeState.AppliedTemplateHash
+	// the configuration hash is only known after the node restarted since the operator started
+	if nodeState.LastAppliedConfigHash != "" {
+		nodeStatus.LastAppliedConfigHash = nodeState.LastAppliedConfigHash
+	}
 }
 
 func (er *ElasticsearchRequest) checkWatermarkAndUnblockIndices() {
@@ -412,7 +665,7 @@ func (er *ElasticsearchRequest) checkWatermarkAndUnblockIndices() {
 	if er.isDiskUtilizationBelowFloodWatermark() {
 		indices, err := er.esClient.GetAllIndices("")
 		if err != nil {
-			log.Error(err, "failed to fetch all indices")
+			er.L().Error(err, "failed to fetch all indices")
 		}
 		for _, index := range indices {
 			if index.Index == constants.SecurityIndex {
@@ -420,7 +673,7 @@ func (er *ElasticsearchRequest) checkWatermarkAndUnblockIndices() {
 			}
 			if er.isIndexBlocked(index.Index) {
 				if err := er.unblockIndex(index.Index); err != nil {
-					log.Error(err, "Couldn't update the index setting")
+					er.L().Error(err, "Couldn't update the index setting")
 				}
 			}
 		}
@@ -431,7 +684,7 @@ func (er *ElasticsearchRequest) isDiskUtilizationBelowFloodWatermark() bool {
 	for _, nodeTypeInterface := range nodes[nodeMapKey(er.cluster.Name, er.cluster.Namespace)] {
 		usage, percent, err := er.esClient.GetNodeDiskUsage(nodeTypeInterface.name())
 		if err != nil {
-			log.Info("Unable to get disk usage", "error", err)
+			er.L().Info("Unable to get disk usage", "error", err)
 			continue
 		}
 		if exceedsFloodWatermark(usage, percent) {
@@ -440,3 +693,128 @@ func (er *ElasticsearchRequest) isDiskUtilizationBelowFloodWatermark() bool {
 	}
 	return true
 }
+
+// drainNode excludes the node from shard allocation and reports the shards remaining on the
+// node. Returns true once the node holds no more shards. The relocation is not waited on, the
+// NodeDraining condition requeues the reconcile to check on the node again.
+func (er *ElasticsearchRequest) drainNode(nodeName string) (bool, error) {
+	shards, err := er.esClient.GetShardsOnNode(nodeName)
+	if err != nil {
+		return false, err
+	}
+
+	if len(shards) == 0 {
+		if containsClusterCondition(api.NodeDraining, v1.ConditionTrue, &er.cluster.Status) {
+			message := fmt.Sprintf("Relocated all shards off node %s", nodeName)
+			er.L().Info(message)
+			recordEvent(er.recorder, er.cluster, v1.EventTypeNormal, eventReasonNodeDrained, message)
+			if err := updateCondition(er.cluster, api.NodeDraining, nodeDrainingReason, v1.ConditionFalse, "", er.client); err != nil {
+				er.L().Error(err, "unable to update node draining condition", "node", nodeName)
+			}
+		}
+		return true, nil
+	}
+
+	if ok, err := er.esClient.ExcludeNodeFromAllocation(nodeName); !ok {
+		return false, kverrors.Wrap(err, "failed to exclude node from shard allocation",
+			"node", nodeName)
+	}
+
+	er.reportDrainProgress(nodeName, len(shards))
+	return false, nil
+}
+
+// reportDrainProgress reports the shards remaining on a draining node in the status and events
+func (er *ElasticsearchRequest) reportDrainProgress(nodeName string, remaining int) {
+	message := fmt.Sprintf("%d shards remaining on node %s", remaining, nodeName)
+	if relocating, err := er.esClient.GetRelocatingShardCount(); err == nil {
+		message = fmt.Sprintf("%s, %d relocating in the cluster", message, relocating)
+	}
+
+	er.L().Info(message)
+	if _, condition := getESNodeCondition(er.cluster.Status.Conditions, api.NodeDraining); condition == nil || condition.Message != message {
+		recordEvent(er.recorder, er.cluster, v1.EventTypeNormal, eventReasonNodeDraining, message)
+	}
+	if err := updateCondition(er.cluster, api.NodeDraining, nodeDrainingReason, v1.ConditionTrue, message, er.client); err != nil {
+		er.L().Error(err, "unable to update node draining condition", "node", nodeName)
+	}
+}
+
+// mastersFirst returns the nodes with the master eligible ones ordered first
+func mastersFirst(clusterNodes []NodeTypeInterface) []NodeTypeInterface {
+	ordered := make([]NodeTypeInterface, 0, len(clusterNodes))
+	for _, node := range clusterNodes {
+		if node.isMaster() {
+			ordered = append(ordered, node)
+		}
+	}
+	for _, node := range clusterNodes {
+		if !node.isMaster() {
+			ordered = append(ordered, node)
+		}
+	}
+	return ordered
+}
+
+// isBootstrapping returns true if any of the nodes which are not master eligible is missing,
+// e.g. on the initial creation of the cluster. Steady state clusters whose nodes all exist
+// are not ordered.
+func isBootstrapping(clusterNodes []NodeTypeInterface) bool {
+	hasMasters := false
+	othersMissing := false
+	for _, node := range clusterNodes {
+		if node.isMaster() {
+			hasMasters = true
+			continue
+		}
+		if node.isMissing() {
+			othersMissing = true
+		}
+	}
+	return hasMasters && othersMissing
+}
+
+// waitForMasterNodes waits for the master eligible nodes to join the cluster and signals to
+// requeue the reconcile if any has not joined yet
+func (er *ElasticsearchRequest) waitForMasterNodes(clusterNodes []NodeTypeInterface) error {
+	for _, node := range clusterNodes {
+		if !node.isMaster() {
+			continue
+		}
+		if joined, _ := node.waitForNodeRejoinCluster(); !joined {
+			return newRequeueError(RequeueWaitingForNodes, "master node has not yet joined the cluster",
+				"node", node.name(),
+			)
+		}
+	}
+	return nil
+}
+
+// updateRecoveryInProgress sets the RecoveryInProgress condition if a node rollout is about to
+// start while more shards than allowed are unassigned. Returns true if the rollout must wait.
+func (er *ElasticsearchRequest) updateRecoveryInProgress(rolloutScheduled bool) bool {
+	var health api.ClusterHealth
+	recovering := false
+	if rolloutScheduled && maxUnassignedShards >= 0 {
+		var err error
+		health, err = er.esClient.GetClusterHealth()
+		if err != nil {
+			er.L().Info("Unable to get unassigned shards before starting node rollout", "error", err)
+		}
+		recovering = err == nil && health.UnassignedShards > maxUnassignedShards
+	}
+
+	if !recovering {
+		if err := updateCondition(er.cluster, api.RecoveryInProgress, recoveryInProgressReason, v1.ConditionFalse, "", er.client); err != nil {
+			er.L().Error(err, "Unable to clear recovery in progress condition")
+		}
+		return false
+	}
+
+	message := fmt.Sprintf("Node rollout waits on %d unassigned shards to recover, at most %d are allowed", health.UnassignedShards, maxUnassignedShards)
+	er.L().Info(message)
+	if err := updateCondition(er.cluster, api.RecoveryInProgress, recoveryInProgressReason, v1.ConditionTrue, message, er.client); err != nil {
+		er.L().Error(err, "Unable to set recovery in progress condition")
+	}
+	return true
+}
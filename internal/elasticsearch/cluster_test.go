@@ -1,6 +1,9 @@
 package elasticsearch
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"testing"
 
 	elasticsearchv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
@@ -9,6 +12,9 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -76,3 +82,352 @@ func populateSingleNode(clusterName string) []NodeTypeInterface {
 	}
 	return nodes
 }
+
+func TestDrainNodeWaitsForShardsToRelocate(t *testing.T) {
+	const (
+		esCluster   = "elasticsearch"
+		esNamespace = "openshift-logging"
+		nodeName    = "elasticsearch-cdm-1-deadbeef"
+	)
+
+	// the shards remaining on the node are started or being relocated off it
+	shardsOnNode := func(shards int) helpers.FakeElasticsearchResponse {
+		entries := []string{`{"index": "app-000001", "shard": "0", "prirep": "p", "state": "STARTED", "node": "elasticsearch-cdm-2-deadbeef"}`}
+		for i := 0; i < shards; i++ {
+			node, state := nodeName, "STARTED"
+			if i%2 == 0 {
+				node, state = nodeName+" -> 10.128.2.11 Pe8dA1RbStK0 elasticsearch-cdm-2-deadbeef", "RELOCATING"
+			}
+			entries = append(entries, fmt.Sprintf(`{"index": "infra-000001", "shard": "%d", "prirep": "r", "state": %q, "node": %q}`, i, state, node))
+		}
+		return helpers.FakeElasticsearchResponse{
+			StatusCode: 200,
+			Body:       "[" + strings.Join(entries, ",") + "]",
+		}
+	}
+	health := func(relocating int) helpers.FakeElasticsearchResponse {
+		return helpers.FakeElasticsearchResponse{
+			StatusCode: 200,
+			Body:       fmt.Sprintf(`{"status": "green", "relocating_shards": %d}`, relocating),
+		}
+	}
+	excluded := helpers.FakeElasticsearchResponse{
+		StatusCode: 200,
+		Body:       fmt.Sprintf(`{"persistent": {"cluster.routing.allocation.exclude._name": %q}}`, nodeName),
+	}
+
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"_cat/shards?format=json&h=index,shard,prirep,state,node": {
+			shardsOnNode(5),
+			shardsOnNode(3),
+			shardsOnNode(3),
+			shardsOnNode(1),
+			shardsOnNode(0),
+		},
+		"_cluster/health": {
+			health(3),
+			health(2),
+			health(2),
+			health(1),
+		},
+		"_cluster/settings?flat_settings=true": {
+			{StatusCode: 200, Body: `{"persistent": {}}`},
+			excluded,
+			excluded,
+			excluded,
+		},
+		"_cluster/settings": {
+			{
+				StatusCode: 200,
+				Body:       `{"acknowledged": true}`,
+			},
+		},
+	})
+
+	cluster := &elasticsearchv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      esCluster,
+			Namespace: esNamespace,
+		},
+	}
+	_ = elasticsearchv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	k8sClient := fake.NewFakeClient(cluster)
+	recorder := record.NewFakeRecorder(10)
+	er := ElasticsearchRequest{
+		cluster:  cluster,
+		client:   k8sClient,
+		esClient: helpers.NewFakeElasticsearchClient(esCluster, esNamespace, k8sClient, chatter),
+		recorder: recorder,
+	}
+
+	// each reconcile checks on the relocation once and is requeued until the node is drained
+	for i := 0; i < 4; i++ {
+		drained, err := er.drainNode(nodeName)
+		if err != nil {
+			t.Errorf("failed with error: %s", err)
+		}
+		if drained {
+			t.Fatal("Expected the node not to be drained while it holds shards")
+		}
+		if outcome := OutcomeOf(cluster, nil); outcome.Reason != RequeueWaitingForDrain {
+			t.Errorf("Expected the reconcile to be requeued while draining but got %v", outcome)
+		}
+		if i == 0 {
+			_, condition := getESNodeCondition(cluster.Status.Conditions, elasticsearchv1.NodeDraining)
+			if condition == nil || condition.Message != "5 shards remaining on node elasticsearch-cdm-1-deadbeef, 3 relocating in the cluster" {
+				t.Errorf("Expected the node draining condition to report the remaining shards but got %v", condition)
+			}
+		}
+	}
+	drained, err := er.drainNode(nodeName)
+	if err != nil {
+		t.Errorf("failed with error: %s", err)
+	}
+	if !drained {
+		t.Error("Expected the node to be drained once it holds no shards")
+	}
+
+	req, ok := chatter.GetRequest("_cluster/settings")
+	if !ok {
+		t.Fatal("Expected the node to be excluded from shard allocation")
+	}
+	if want := fmt.Sprintf(`{"persistent":{"cluster.routing.allocation.exclude._name":%q}}`, nodeName); req.Body != want {
+		t.Errorf("Expected exclusion request %s but got %s", want, req.Body)
+	}
+
+	close(recorder.Events)
+	events := []string{}
+	for event := range recorder.Events {
+		events = append(events, event)
+	}
+	want := []string{
+		"Normal NodeDraining 5 shards remaining on node elasticsearch-cdm-1-deadbeef, 3 relocating in the cluster",
+		"Normal NodeDraining 3 shards remaining on node elasticsearch-cdm-1-deadbeef, 2 relocating in the cluster",
+		"Normal NodeDraining 1 shards remaining on node elasticsearch-cdm-1-deadbeef, 1 relocating in the cluster",
+		"Normal NodeDrained Relocated all shards off node elasticsearch-cdm-1-deadbeef",
+	}
+	if strings.Join(events, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected events %q but got %q", want, events)
+	}
+
+	current := &elasticsearchv1.Elasticsearch{}
+	if err := k8sClient.Get(context.TODO(), client.ObjectKey{Name: esCluster, Namespace: esNamespace}, current); err != nil {
+		t.Fatalf("failed to get cluster: %s", err)
+	}
+	if _, condition := getESNodeCondition(current.Status.Conditions, elasticsearchv1.NodeDraining); condition != nil {
+		t.Errorf("Expected the node draining condition to be removed but got %v", condition)
+	}
+}
+
+func TestRepauseNodesAfterCrashMidRollout(t *testing.T) {
+	nodes = map[string][]NodeTypeInterface{}
+
+	const (
+		esCluster   = "elasticsearch"
+		esNamespace = "openshift-logging"
+	)
+
+	newDeployment := func(name string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: esNamespace,
+			},
+			Spec: appsv1.DeploymentSpec{
+				Paused: true,
+			},
+		}
+	}
+
+	k8sClient := fake.NewFakeClient(
+		newDeployment("elasticsearch-cdm-1-deadbeef"),
+		newDeployment("elasticsearch-cdm-2-deadbeef"),
+	)
+
+	// the operator unpaused the first node for its rollout and stopped before pausing it again
+	rolling := &deploymentNode{
+		clusterName: esCluster,
+		self:        *newDeployment("elasticsearch-cdm-1-deadbeef"),
+		client:      k8sClient,
+	}
+	if err := rolling.unpause(); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+
+	recorder := record.NewFakeRecorder(2)
+	key := nodeMapKey(esCluster, esNamespace)
+	for _, name := range []string{"elasticsearch-cdm-1-deadbeef", "elasticsearch-cdm-2-deadbeef"} {
+		nodes[key] = append(nodes[key], &deploymentNode{
+			clusterName: esCluster,
+			self:        *newDeployment(name),
+			client:      k8sClient,
+			recorder:    recorder,
+		})
+	}
+
+	er := ElasticsearchRequest{
+		cluster: &elasticsearchv1.Elasticsearch{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      esCluster,
+				Namespace: esNamespace,
+			},
+		},
+		client: k8sClient,
+	}
+
+	if err := er.repauseNodes(); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+
+	for _, name := range []string{"elasticsearch-cdm-1-deadbeef", "elasticsearch-cdm-2-deadbeef"} {
+		dpl := &appsv1.Deployment{}
+		if err := k8sClient.Get(context.TODO(), client.ObjectKey{Name: name, Namespace: esNamespace}, dpl); err != nil {
+			t.Fatalf("failed to get deployment %s: %s", name, err)
+		}
+		if !dpl.Spec.Paused {
+			t.Errorf("Expected deployment %s to be paused", name)
+		}
+	}
+
+	if len(recorder.Events) != 1 {
+		t.Fatalf("Expected a single event for the re-paused node but got %d", len(recorder.Events))
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "elasticsearch-cdm-1-deadbeef") {
+		t.Errorf("Expected the event to name the re-paused node but got %q", event)
+	}
+}
+
+func TestCreateMasterNodesFirstOnBootstrap(t *testing.T) {
+	const (
+		esCluster   = "elasticsearch"
+		esNamespace = "openshift-logging"
+	)
+
+	newDeployment := func(name string, master bool) appsv1.Deployment {
+		return appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: esNamespace,
+				Labels: map[string]string{
+					"es-node-master": fmt.Sprintf("%t", master),
+				},
+			},
+		}
+	}
+
+	k8sClient := fake.NewFakeClient()
+	newNode := func(name string, master bool, esClient esclient.Client) *deploymentNode {
+		return &deploymentNode{
+			clusterName: esCluster,
+			self:        newDeployment(name, master),
+			client:      k8sClient,
+			esClient:    esClient,
+		}
+	}
+
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"_cluster/state/nodes": {
+			{
+				StatusCode: 503,
+				Body:       `{"error": "master_not_discovered_exception"}`,
+			},
+			{
+				StatusCode: 200,
+				Body:       `{"nodes": {"7EN-Wa_EQC6LoANvWcoyHQ": {"name": "elasticsearch-cdm-1-deadbeef"}}}`,
+			},
+		},
+	})
+	esClient := helpers.NewFakeElasticsearchClient(esCluster, esNamespace, k8sClient, chatter)
+
+	data := newNode("elasticsearch-cd-1-deadbeef", false, esClient)
+	master := newNode("elasticsearch-cdm-1-deadbeef", true, esClient)
+
+	ordered := mastersFirst([]NodeTypeInterface{data, master})
+	if ordered[0].name() != master.name() || ordered[1].name() != data.name() {
+		t.Errorf("Expected the master node to be ordered first but got %s, %s", ordered[0].name(), ordered[1].name())
+	}
+
+	if !isBootstrapping(ordered) {
+		t.Error("Expected a cluster without data nodes to be bootstrapping")
+	}
+
+	er := ElasticsearchRequest{
+		cluster: &elasticsearchv1.Elasticsearch{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      esCluster,
+				Namespace: esNamespace,
+			},
+		},
+		client:   k8sClient,
+		esClient: esClient,
+	}
+
+	if err := er.waitForMasterNodes(ordered); !IsRequeue(err) {
+		t.Errorf("Expected a requeue while the master has not joined but got %v", err)
+	}
+
+	if err := er.waitForMasterNodes(ordered); err != nil {
+		t.Errorf("Expected no error once the master joined but got %v", err)
+	}
+
+	dpl := newDeployment(data.name(), false)
+	if err := k8sClient.Create(context.TODO(), &dpl); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+
+	if isBootstrapping(ordered) {
+		t.Error("Expected a cluster whose nodes all exist not to be bootstrapping")
+	}
+}
+
+func TestRolloutWaitsOnUnassignedShards(t *testing.T) {
+	_ = elasticsearchv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	SetMaxUnassignedShards(5)
+	defer SetMaxUnassignedShards(-1)
+
+	const (
+		esCluster   = "elasticsearch"
+		esNamespace = "openshift-logging"
+	)
+
+	health := func(unassigned int) helpers.FakeElasticsearchResponse {
+		return helpers.FakeElasticsearchResponse{
+			StatusCode: 200,
+			Body:       fmt.Sprintf(`{"cluster_name": "elasticsearch", "status": "yellow", "unassigned_shards": %d}`, unassigned),
+		}
+	}
+
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"_cluster/health": {
+			health(20),
+			health(0),
+		},
+	})
+
+	cluster := &elasticsearchv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      esCluster,
+			Namespace: esNamespace,
+		},
+	}
+	k8sClient := fake.NewFakeClient(cluster.DeepCopy())
+	er := ElasticsearchRequest{
+		cluster:  cluster,
+		client:   k8sClient,
+		esClient: helpers.NewFakeElasticsearchClient(esCluster, esNamespace, k8sClient, chatter),
+	}
+
+	if !er.updateRecoveryInProgress(true) {
+		t.Fatal("Expected the rollout to wait while too many shards are unassigned")
+	}
+	if _, condition := getESNodeCondition(cluster.Status.Conditions, elasticsearchv1.RecoveryInProgress); condition == nil || !strings.Contains(condition.Message, "20 unassigned shards") {
+		t.Errorf("Expected the RecoveryInProgress condition to be set but got %v", cluster.Status.Conditions)
+	}
+
+	if er.updateRecoveryInProgress(true) {
+		t.Error("Expected the rollout to proceed once the shards are assigned")
+	}
+	if _, condition := getESNodeCondition(cluster.Status.Conditions, elasticsearchv1.RecoveryInProgress); condition != nil {
+		t.Errorf("Expected the RecoveryInProgress condition to be removed but got %v", condition)
+	}
+}
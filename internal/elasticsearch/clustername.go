@@ -0,0 +1,87 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// clusterNameConflictReason is the reason of the ClusterNameConflict condition
+const clusterNameConflictReason = "Duplicate Cluster Name"
+
+// discoveryHosts returns the seed hosts the nodes of the cluster discover each other by,
+// without ports
+func discoveryHosts(dpl *api.Elasticsearch) sets.String {
+	hosts := sets.NewString()
+	for _, host := range strings.Split(esDiscoverySeedHosts(dpl), ",") {
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		hosts.Insert(strings.ToLower(strings.TrimSuffix(host, ".")))
+	}
+	return hosts
+}
+
+// getClusterNameConflicts returns the other clusters using the same Elasticsearch cluster
+// name in the same namespace or with overlapping discovery hosts, whose nodes may thus join
+// one cluster. Clusters being deleted are skipped.
+func getClusterNameConflicts(dpl *api.Elasticsearch, clusters []api.Elasticsearch) []string {
+	name := getESClusterName(dpl)
+	hosts := discoveryHosts(dpl)
+
+	conflicts := []string{}
+	for i := range clusters {
+		other := &clusters[i]
+		if other.Name == dpl.Name && other.Namespace == dpl.Namespace {
+			continue
+		}
+		if other.GetDeletionTimestamp() != nil || getESClusterName(other) != name {
+			continue
+		}
+		if other.Namespace == dpl.Namespace || hosts.HasAny(discoveryHosts(other).UnsortedList()...) {
+			conflicts = append(conflicts, fmt.Sprintf("%s/%s", other.Namespace, other.Name))
+		}
+	}
+
+	sort.Strings(conflicts)
+	return conflicts
+}
+
+// updateClusterNameConflict sets the ClusterNameConflict condition listing the other
+// clusters claiming the same Elasticsearch cluster name, and emits a warning event whenever
+// they change, or clears the condition if none does
+func (er *ElasticsearchRequest) updateClusterNameConflict() {
+	cluster := er.cluster
+
+	clusters := &api.ElasticsearchList{}
+	if err := er.client.List(context.TODO(), clusters); err != nil {
+		er.L().Error(err, "Unable to list clusters to check their cluster names")
+		return
+	}
+
+	conflicts := getClusterNameConflicts(cluster, clusters.Items)
+	if len(conflicts) == 0 {
+		if err := updateCondition(cluster, api.ClusterNameConflict, clusterNameConflictReason, v1.ConditionFalse, "", er.client); err != nil {
+			er.L().Error(err, "Unable to clear cluster name conflict condition")
+		}
+		return
+	}
+
+	message := fmt.Sprintf("Elasticsearch cluster name %q is also claimed by %s. Their nodes may form one cluster, please set a unique clusterName", getESClusterName(cluster), strings.Join(conflicts, ", "))
+	if _, condition := getESNodeCondition(cluster.Status.Conditions, api.ClusterNameConflict); condition != nil &&
+		condition.Status == v1.ConditionTrue && condition.Message == message {
+		return
+	}
+
+	er.L().Info(message)
+	recordEvent(er.recorder, cluster, v1.EventTypeWarning, eventReasonClusterNameConflict, message)
+	if err := updateCondition(cluster, api.ClusterNameConflict, clusterNameConflictReason, v1.ConditionTrue, message, er.client); err != nil {
+		er.L().Error(err, "Unable to set cluster name conflict condition")
+	}
+}
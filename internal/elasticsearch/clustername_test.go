@@ -0,0 +1,134 @@
+package elasticsearch
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetClusterNameConflicts(t *testing.T) {
+	newCluster := func(namespace, name, clusterName string, seedHosts ...string) loggingv1.Elasticsearch {
+		cluster := loggingv1.Elasticsearch{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       loggingv1.ElasticsearchSpec{ClusterName: clusterName},
+		}
+		if len(seedHosts) > 0 {
+			cluster.Spec.Discovery = &loggingv1.ElasticsearchDiscoverySpec{SeedHosts: seedHosts}
+		}
+		return cluster
+	}
+	deleted := newCluster("openshift-logging", "deleted", "logs")
+	now := metav1.Now()
+	deleted.DeletionTimestamp = &now
+
+	tests := []struct {
+		desc      string
+		cluster   loggingv1.Elasticsearch
+		others    []loggingv1.Elasticsearch
+		conflicts []string
+	}{
+		{
+			desc:    "unique cluster names",
+			cluster: newCluster("openshift-logging", "elasticsearch", ""),
+			others:  []loggingv1.Elasticsearch{newCluster("openshift-logging", "audit", "")},
+		},
+		{
+			desc:      "same namespace",
+			cluster:   newCluster("openshift-logging", "elasticsearch", "logs"),
+			others:    []loggingv1.Elasticsearch{newCluster("openshift-logging", "audit", "logs"), deleted},
+			conflicts: []string{"openshift-logging/audit"},
+		},
+		{
+			desc:    "other namespace without overlapping discovery",
+			cluster: newCluster("openshift-logging", "elasticsearch", "logs"),
+			others:  []loggingv1.Elasticsearch{newCluster("other", "elasticsearch", "logs")},
+		},
+		{
+			desc:      "other namespace seeding from the discovery service",
+			cluster:   newCluster("openshift-logging", "elasticsearch", "logs"),
+			others:    []loggingv1.Elasticsearch{newCluster("other", "elasticsearch", "logs", "elasticsearch-cluster.openshift-logging.svc:9300")},
+			conflicts: []string{"other/elasticsearch"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			clusters := append([]loggingv1.Elasticsearch{test.cluster}, test.others...)
+			conflicts := getClusterNameConflicts(&test.cluster, clusters)
+			if strings.Join(conflicts, ", ") != strings.Join(test.conflicts, ", ") {
+				t.Errorf("Exp. conflicts %v but got %v", test.conflicts, conflicts)
+			}
+		})
+	}
+}
+
+func TestUpdateClusterNameConflict(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch",
+			Namespace: "openshift-logging",
+		},
+		Spec: loggingv1.ElasticsearchSpec{ClusterName: "logs"},
+	}
+	other := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "audit",
+			Namespace: cluster.Namespace,
+		},
+		Spec: loggingv1.ElasticsearchSpec{ClusterName: "logs"},
+	}
+	k8sClient := fake.NewFakeClient(cluster, other)
+	recorder := record.NewFakeRecorder(2)
+
+	er := &ElasticsearchRequest{
+		cluster:  cluster,
+		client:   k8sClient,
+		recorder: recorder,
+	}
+
+	getCondition := func() *loggingv1.ClusterCondition {
+		current := &loggingv1.Elasticsearch{}
+		if err := k8sClient.Get(context.TODO(), client.ObjectKey{Name: cluster.Name, Namespace: cluster.Namespace}, current); err != nil {
+			t.Fatalf("failed to get cluster: %s", err)
+		}
+		_, condition := getESNodeCondition(current.Status.Conditions, loggingv1.ClusterNameConflict)
+		return condition
+	}
+
+	er.updateClusterNameConflict()
+	condition := getCondition()
+	if condition == nil || condition.Status != corev1.ConditionTrue || !strings.Contains(condition.Message, "openshift-logging/audit") {
+		t.Errorf("Exp. the cluster name conflict condition to list the other cluster but got %v", condition)
+	}
+	event := <-recorder.Events
+	if !strings.HasPrefix(event, "Warning "+eventReasonClusterNameConflict) {
+		t.Errorf("Exp. a warning event for the conflict but got %q", event)
+	}
+
+	// unchanged conflicts are not reported again
+	er.updateClusterNameConflict()
+	select {
+	case event := <-recorder.Events:
+		t.Errorf("Exp. no further event but got %q", event)
+	default:
+	}
+
+	if err := k8sClient.Delete(context.TODO(), other); err != nil {
+		t.Fatalf("failed to delete cluster: %s", err)
+	}
+	er.updateClusterNameConflict()
+	if condition := getCondition(); condition != nil {
+		t.Errorf("Exp. the cluster name conflict condition to be cleared but got %v", condition)
+	}
+}
@@ -1,6 +1,7 @@
 package elasticsearch
 
 import (
+	"context"
 	"errors"
 
 	"github.com/ViaQ/logerr/kverrors"
@@ -19,6 +20,7 @@ type ClusterRestart struct {
 	clusterName      string
 	clusterNamespace string
 	scheduledNodes   []NodeTypeInterface
+	maxPendingTasks  int
 }
 
 type Restarter struct {
@@ -48,11 +50,14 @@ type Restarter struct {
 }
 
 func (er *ElasticsearchRequest) PerformFullClusterUpdate(nodes []NodeTypeInterface) error {
+	defer er.startSpan("full cluster update", "nodes", len(nodes))()
+
 	r := ClusterRestart{
 		client:           er.esClient,
 		clusterName:      er.cluster.Name,
 		clusterNamespace: er.cluster.Namespace,
 		scheduledNodes:   nodes,
+		maxPendingTasks:  getMaxPendingTasks(er.cluster),
 	}
 
 	restarter := Restarter{
@@ -72,7 +77,7 @@ func (er *ElasticsearchRequest) PerformFullClusterUpdate(nodes []NodeTypeInterfa
 			nodeStatus.UpgradeStatus.ScheduledForCertRedeploy = v1.ConditionFalse
 
 			if err := er.setNodeStatus(node, nodeStatus, &er.cluster.Status); err != nil {
-				log.Error(err, "unable to update node status", "namespace", er.cluster.Namespace, "name", er.cluster.Name)
+				er.L().Error(err, "unable to update node status")
 			}
 		}
 	}
@@ -83,11 +88,14 @@ func (er *ElasticsearchRequest) PerformFullClusterUpdate(nodes []NodeTypeInterfa
 }
 
 func (er *ElasticsearchRequest) PerformFullClusterCertRestart(nodes []NodeTypeInterface) error {
+	defer er.startSpan("full cluster cert restart", "nodes", len(nodes))()
+
 	r := ClusterRestart{
 		client:           er.esClient,
 		clusterName:      er.cluster.Name,
 		clusterNamespace: er.cluster.Namespace,
 		scheduledNodes:   nodes,
+		maxPendingTasks:  getMaxPendingTasks(er.cluster),
 	}
 
 	restarter := Restarter{
@@ -107,7 +115,7 @@ func (er *ElasticsearchRequest) PerformFullClusterCertRestart(nodes []NodeTypeIn
 			nodeStatus.UpgradeStatus.ScheduledForCertRedeploy = v1.ConditionFalse
 
 			if err := er.setNodeStatus(node, nodeStatus, &er.cluster.Status); err != nil {
-				log.Error(err, "unable to update node status", "namespace", er.cluster.Namespace, "name", er.cluster.Name)
+				er.L().Error(err, "unable to update node status")
 			}
 		}
 	}
@@ -118,11 +126,14 @@ func (er *ElasticsearchRequest) PerformFullClusterCertRestart(nodes []NodeTypeIn
 }
 
 func (er *ElasticsearchRequest) PerformFullClusterRestart(nodes []NodeTypeInterface) error {
+	defer er.startSpan("full cluster restart", "nodes", len(nodes))()
+
 	r := ClusterRestart{
 		client:           er.esClient,
 		clusterName:      er.cluster.Name,
 		clusterNamespace: er.cluster.Namespace,
 		scheduledNodes:   nodes,
+		maxPendingTasks:  getMaxPendingTasks(er.cluster),
 	}
 
 	restarter := Restarter{
@@ -142,7 +153,7 @@ func (er *ElasticsearchRequest) PerformFullClusterRestart(nodes []NodeTypeInterf
 			nodeStatus.UpgradeStatus.ScheduledForCertRedeploy = v1.ConditionFalse
 
 			if err := er.setNodeStatus(node, nodeStatus, &er.cluster.Status); err != nil {
-				log.Error(err, "unable to update node status", "namespace", er.cluster.Namespace, "name", er.cluster.Name)
+				er.L().Error(err, "unable to update node status")
 			}
 		}
 	}
@@ -153,6 +164,8 @@ func (er *ElasticsearchRequest) PerformFullClusterRestart(nodes []NodeTypeInterf
 }
 
 func (er *ElasticsearchRequest) PerformNodeRestart(node NodeTypeInterface) error {
+	defer er.startSpan("node restart", "node", node.name())()
+
 	scheduledNode := []NodeTypeInterface{node}
 
 	r := ClusterRestart{
@@ -160,6 +173,7 @@ func (er *ElasticsearchRequest) PerformNodeRestart(node NodeTypeInterface) error
 		clusterName:      er.cluster.Name,
 		clusterNamespace: er.cluster.Namespace,
 		scheduledNodes:   scheduledNode,
+		maxPendingTasks:  getMaxPendingTasks(er.cluster),
 	}
 
 	restarter := Restarter{
@@ -175,7 +189,7 @@ func (er *ElasticsearchRequest) PerformNodeRestart(node NodeTypeInterface) error
 
 	updateStatus := func() {
 		if err := er.setNodeStatus(node, restarter.nodeStatus, &er.cluster.Status); err != nil {
-			log.Error(err, "unable to update node status", "namespace", er.cluster.Namespace, "name", er.cluster.Name)
+			er.L().Error(err, "unable to update node status")
 		}
 	}
 
@@ -186,6 +200,8 @@ func (er *ElasticsearchRequest) PerformNodeRestart(node NodeTypeInterface) error
 }
 
 func (er *ElasticsearchRequest) PerformNodeUpdate(node NodeTypeInterface) error {
+	defer er.startSpan("node update", "node", node.name())()
+
 	scheduledNode := []NodeTypeInterface{node}
 
 	r := ClusterRestart{
@@ -193,6 +209,7 @@ func (er *ElasticsearchRequest) PerformNodeUpdate(node NodeTypeInterface) error
 		clusterName:      er.cluster.Name,
 		clusterNamespace: er.cluster.Namespace,
 		scheduledNodes:   scheduledNode,
+		maxPendingTasks:  getMaxPendingTasks(er.cluster),
 	}
 
 	restarter := Restarter{
@@ -208,7 +225,7 @@ func (er *ElasticsearchRequest) PerformNodeUpdate(node NodeTypeInterface) error
 
 	updateStatus := func() {
 		if err := er.setNodeStatus(node, restarter.nodeStatus, &er.cluster.Status); err != nil {
-			log.Error(err, "unable to update node status", "namespace", er.cluster.Namespace, "name", er.cluster.Name)
+			er.L().Error(err, "unable to update node status")
 		}
 	}
 
@@ -264,15 +281,43 @@ func (cr ClusterRestart) restartNoop() error {
 	return nil
 }
 
+// ensureClusterHealthValid lets Elasticsearch wait for the cluster to be at least yellow before
+// restarting nodes, rather than failing the restart on a short lived unhealthy state. The
+// restart waits as well until the pending cluster tasks drained below the maximum.
 func (cr ClusterRestart) ensureClusterHealthValid() error {
-	if status, _ := cr.client.GetClusterHealthStatus(); !utils.Contains(desiredClusterStates, status) {
+	health, err := cr.client.WaitForClusterHealth(context.TODO(), yellowClusterState, clusterHealthWait)
+	if err != nil {
+		return kverrors.Wrap(err, "Waiting for cluster to be recovered",
+			"namespace", cr.clusterNamespace,
+			"cluster", cr.clusterName,
+			"status", health.Status,
+			"desired_status", desiredClusterStates)
+	}
+
+	if !utils.Contains(desiredClusterStates, health.Status) {
 		return kverrors.New("Waiting for cluster to be recovered",
 			"namespace", cr.clusterNamespace,
 			"cluster", cr.clusterName,
-			"status", status,
+			"status", health.Status,
 			"desired_status", desiredClusterStates)
 	}
 
+	count, oldest, err := cr.client.GetPendingTasks()
+	if err != nil {
+		return kverrors.Wrap(err, "Unable to get pending cluster tasks",
+			"namespace", cr.clusterNamespace,
+			"cluster", cr.clusterName)
+	}
+
+	if count > cr.maxPendingTasks {
+		return kverrors.New("Waiting for pending cluster tasks to drain",
+			"namespace", cr.clusterNamespace,
+			"cluster", cr.clusterName,
+			"pending_tasks", count,
+			"oldest_task_age", oldest.String(),
+			"max_pending_tasks", cr.maxPendingTasks)
+	}
+
 	return nil
 }
 
@@ -1,12 +1,17 @@
 package elasticsearch
 
 import (
+	"strings"
+	"testing"
+
 	"github.com/ViaQ/logerr/kverrors"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/test/helpers"
 	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 var (
@@ -429,3 +434,36 @@ var _ = Describe("clusterrestart", func() {
 func (cr ClusterRestart) restartFail() error {
 	return kverrors.New("we apologise for the fault in this function. Those responsible have been sacked.")
 }
+
+func TestEnsureClusterHealthValidWaitsForPendingTasks(t *testing.T) {
+	green := helpers.FakeElasticsearchResponse{StatusCode: 200, Body: `{"status": "green", "timed_out": false}`}
+	pendingTasks := func(count int) helpers.FakeElasticsearchResponse {
+		tasks := []string{}
+		for i := 0; i < count; i++ {
+			tasks = append(tasks, `{"source": "shard-started", "time_in_queue_millis": 100}`)
+		}
+		return helpers.FakeElasticsearchResponse{StatusCode: 200, Body: `{"tasks": [` + strings.Join(tasks, ",") + `]}`}
+	}
+
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"_cluster/health?wait_for_status=yellow&timeout=10s": {green, green, green},
+		"_cluster/pending_tasks":                             {pendingTasks(12), pendingTasks(6), pendingTasks(2)},
+	})
+
+	cr := ClusterRestart{
+		client:           helpers.NewFakeElasticsearchClient("elasticsearch", "openshift-logging", fake.NewFakeClient(), chatter),
+		clusterName:      "elasticsearch",
+		clusterNamespace: "openshift-logging",
+		maxPendingTasks:  defaultMaxPendingTasks,
+	}
+
+	for _, pending := range []int{12, 6} {
+		if err := cr.ensureClusterHealthValid(); err == nil {
+			t.Errorf("Exp. the restart to wait for %d pending tasks to drain", pending)
+		}
+	}
+
+	if err := cr.ensureClusterHealthValid(); err != nil {
+		t.Errorf("Exp. the restart to proceed once the pending tasks drained but got: %s", err)
+	}
+}
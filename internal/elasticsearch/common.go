@@ -3,8 +3,10 @@ package elasticsearch
 import (
 	"context"
 	"fmt"
+	"path"
 	"reflect"
 	"strconv"
+	"strings"
 
 	"github.com/ViaQ/logerr/kverrors"
 	v1 "k8s.io/api/core/v1"
@@ -14,6 +16,7 @@ import (
 
 	"github.com/ViaQ/logerr/log"
 	"github.com/openshift/elasticsearch-operator/internal/constants"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/image"
 	"github.com/openshift/elasticsearch-operator/internal/manifests/persistentvolume"
 	"github.com/openshift/elasticsearch-operator/internal/manifests/pod"
 	"github.com/openshift/elasticsearch-operator/internal/utils"
@@ -46,78 +49,99 @@ var defaultResources = map[string]v1.ResourceRequirements{
 			v1.ResourceMemory: resource.MustParse(defaultESMemoryRequest),
 		},
 	},
+	"elasticsearch-master": {
+		Limits: v1.ResourceList{
+			v1.ResourceMemory: resource.MustParse(defaultESMasterMemoryLimit),
+		},
+		Requests: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse(defaultESMasterCPURequest),
+			v1.ResourceMemory: resource.MustParse(defaultESMasterMemoryRequest),
+		},
+	},
 }
 
 func getESImage() string {
-	return utils.LookupEnvWithDefault("ELASTICSEARCH_IMAGE", constants.ElasticsearchDefaultImage)
+	return image.Resolve(utils.LookupEnvWithDefault("ELASTICSEARCH_IMAGE", constants.ElasticsearchDefaultImage))
 }
 
 func getESProxyImage() string {
-	return utils.LookupEnvWithDefault("ELASTICSEARCH_PROXY", constants.ProxyDefaultImage)
+	return image.Resolve(utils.LookupEnvWithDefault("ELASTICSEARCH_PROXY", constants.ProxyDefaultImage))
 }
 
-func getNodeRoleMap(node api.ElasticsearchNode) map[api.ElasticsearchNodeRole]bool {
-	isClient := false
-	isData := false
-	isMaster := false
+func isMasterNode(node api.ElasticsearchNode) bool {
+	return getNodeRoles(node).IsMaster()
+}
 
-	for _, role := range node.Roles {
-		if role == api.ElasticsearchRoleClient {
-			isClient = true
-		}
+func isDataNode(node api.ElasticsearchNode) bool {
+	return getNodeRoles(node).IsData()
+}
 
-		if role == api.ElasticsearchRoleData {
-			isData = true
-		}
+// ingestEnvVar is templated into the node.ingest setting of elasticsearch.yml
+const ingestEnvVar = "IS_INGEST"
 
-		if role == api.ElasticsearchRoleMaster {
-			isMaster = true
+// hasIngestNodes returns true if any node group of the cluster has the ingest role
+func hasIngestNodes(dpl *api.Elasticsearch) bool {
+	for _, node := range dpl.Spec.Nodes {
+		if getNodeRoles(node).IsIngest() {
+			return true
 		}
 	}
-	return map[api.ElasticsearchNodeRole]bool{
-		api.ElasticsearchRoleClient: isClient,
-		api.ElasticsearchRoleData:   isData,
-		api.ElasticsearchRoleMaster: isMaster,
+
+	return false
+}
+
+// getNodeIngest returns the node.ingest setting of the nodes of the group or an empty
+// string if the cluster has no dedicated ingest nodes, leaving the Elasticsearch default
+func getNodeIngest(dpl *api.Elasticsearch, node api.ElasticsearchNode) string {
+	if !hasIngestNodes(dpl) {
+		return ""
 	}
+	return strconv.FormatBool(getNodeRoles(node).IsIngest())
 }
 
-func isMasterNode(node api.ElasticsearchNode) bool {
-	for _, role := range node.Roles {
-		if role == api.ElasticsearchRoleMaster {
-			return true
-		}
+// setIngest sets the node.ingest setting of the Elasticsearch container, leaving the
+// template unchanged if the cluster has no dedicated ingest nodes
+func setIngest(template *v1.PodTemplateSpec, ingest string) {
+	if ingest == "" {
+		return
 	}
 
-	return false
-}
+	for i := range template.Spec.Containers {
+		container := &template.Spec.Containers[i]
+		if container.Name != "elasticsearch" {
+			continue
+		}
 
-func isDataNode(node api.ElasticsearchNode) bool {
-	for _, role := range node.Roles {
-		if role == api.ElasticsearchRoleData {
-			return true
+		found := false
+		for j := range container.Env {
+			if container.Env[j].Name == ingestEnvVar {
+				container.Env[j].Value = ingest
+				found = true
+			}
+		}
+		if !found {
+			container.Env = append(container.Env, v1.EnvVar{Name: ingestEnvVar, Value: ingest})
 		}
 	}
-
-	return false
 }
 
-func newAffinity(roleMap map[api.ElasticsearchNodeRole]bool) *v1.Affinity {
+func newAffinity(roles NodeRoles) *v1.Affinity {
 	labelSelectorReqs := []metav1.LabelSelectorRequirement{}
-	if roleMap[api.ElasticsearchRoleClient] {
+	if roles.IsClient() {
 		labelSelectorReqs = append(labelSelectorReqs, metav1.LabelSelectorRequirement{
 			Key:      "es-node-client",
 			Operator: metav1.LabelSelectorOpIn,
 			Values:   []string{"true"},
 		})
 	}
-	if roleMap[api.ElasticsearchRoleData] {
+	if roles.IsData() {
 		labelSelectorReqs = append(labelSelectorReqs, metav1.LabelSelectorRequirement{
 			Key:      "es-node-data",
 			Operator: metav1.LabelSelectorOpIn,
 			Values:   []string{"true"},
 		})
 	}
-	if roleMap[api.ElasticsearchRoleMaster] {
+	if roles.IsMaster() {
 		labelSelectorReqs = append(labelSelectorReqs, metav1.LabelSelectorRequirement{
 			Key:      "es-node-master",
 			Operator: metav1.LabelSelectorOpIn,
@@ -144,10 +168,11 @@ func newAffinity(roleMap map[api.ElasticsearchNodeRole]bool) *v1.Affinity {
 
 func newElasticsearchContainer(imageName string, envVars []v1.EnvVar, resourceRequirements v1.ResourceRequirements) v1.Container {
 	return v1.Container{
-		Name:            "elasticsearch",
-		Image:           imageName,
-		ImagePullPolicy: "IfNotPresent",
-		Env:             envVars,
+		Name:                     "elasticsearch",
+		Image:                    imageName,
+		ImagePullPolicy:          "IfNotPresent",
+		TerminationMessagePolicy: v1.TerminationMessageFallbackToLogsOnError,
+		Env:                      envVars,
 		Ports: []v1.ContainerPort{
 			{
 				Name:          "cluster",
@@ -159,22 +184,11 @@ func newElasticsearchContainer(imageName string, envVars []v1.EnvVar, resourceRe
 				Protocol:      v1.ProtocolTCP,
 			},
 		},
-		ReadinessProbe: &v1.Probe{
-			TimeoutSeconds:      30,
-			InitialDelaySeconds: 10,
-			PeriodSeconds:       5,
-			Handler: v1.Handler{
-				Exec: &v1.ExecAction{
-					Command: []string{
-						"/usr/share/elasticsearch/probe/readiness.sh",
-					},
-				},
-			},
-		},
+		ReadinessProbe: newReadinessProbe(nil, NodeRoles{data: true}),
 		VolumeMounts: []v1.VolumeMount{
 			{
 				Name:      "elasticsearch-storage",
-				MountPath: "/elasticsearch/persistent",
+				MountPath: elasticsearchPersistentPath,
 			},
 			{
 				Name:      "elasticsearch-config",
@@ -189,11 +203,79 @@ func newElasticsearchContainer(imageName string, envVars []v1.EnvVar, resourceRe
 	}
 }
 
+// newReadinessProbe returns the readiness probe of the Elasticsearch container of a node with
+// the given roles. Nodes holding shards recover them on start, thus are probed later than others.
+func newReadinessProbe(spec *api.ElasticsearchProbeSpec, roles NodeRoles) *v1.Probe {
+	probe := &v1.Probe{
+		TimeoutSeconds:      defaultReadinessProbeTimeoutSeconds,
+		InitialDelaySeconds: defaultReadinessProbeInitialDelaySeconds,
+		PeriodSeconds:       defaultReadinessProbePeriodSeconds,
+		Handler: v1.Handler{
+			Exec: &v1.ExecAction{
+				Command: []string{readinessProbeCommand},
+			},
+		},
+	}
+	if !roles.IsData() {
+		probe.InitialDelaySeconds = defaultNoDataReadinessProbeInitialDelaySeconds
+	}
+
+	if spec == nil {
+		return probe
+	}
+
+	if len(spec.Command) > 0 {
+		probe.Exec.Command = spec.Command
+	}
+	if spec.InitialDelaySeconds != nil {
+		probe.InitialDelaySeconds = *spec.InitialDelaySeconds
+	}
+	if spec.PeriodSeconds != nil {
+		probe.PeriodSeconds = *spec.PeriodSeconds
+	}
+	if spec.TimeoutSeconds != nil {
+		probe.TimeoutSeconds = *spec.TimeoutSeconds
+	}
+	if spec.FailureThreshold != nil {
+		probe.FailureThreshold = *spec.FailureThreshold
+	}
+
+	return probe
+}
+
+// setESClusterName sets the Elasticsearch cluster name of the pods, which also names their
+// data path. The pod templates are built with the custom resource name as cluster name.
+func setESClusterName(template *v1.PodTemplateSpec, name string) {
+	for i := range template.Spec.Containers {
+		container := &template.Spec.Containers[i]
+		if container.Name != "elasticsearch" {
+			continue
+		}
+
+		for j := range container.Env {
+			if container.Env[j].Name == "CLUSTER_NAME" {
+				container.Env[j].Value = name
+			}
+		}
+	}
+}
+
+// setReadinessProbeTimeout sets the timeout the readiness script bounds its requests by to
+// the one of the probe
+func setReadinessProbeTimeout(envVars []v1.EnvVar, probe *v1.Probe) {
+	for i := range envVars {
+		if envVars[i].Name == "READINESS_PROBE_TIMEOUT" {
+			envVars[i].Value = strconv.Itoa(int(probe.TimeoutSeconds))
+		}
+	}
+}
+
 func newProxyContainer(imageName, clusterName, namespace string, logConfig LogConfig, resourceRequirements v1.ResourceRequirements) v1.Container {
 	container := v1.Container{
-		Name:            "proxy",
-		Image:           imageName,
-		ImagePullPolicy: "IfNotPresent",
+		Name:                     "proxy",
+		Image:                    imageName,
+		ImagePullPolicy:          "IfNotPresent",
+		TerminationMessagePolicy: v1.TerminationMessageFallbackToLogsOnError,
 		Ports: []v1.ContainerPort{
 			{
 				Name:          "restapi",
@@ -249,7 +331,7 @@ func newProxyContainer(imageName, clusterName, namespace string, logConfig LogCo
 	return container
 }
 
-func newEnvVars(nodeName, clusterName, instanceRAM string, roleMap map[api.ElasticsearchNodeRole]bool) []v1.EnvVar {
+func newEnvVars(nodeName, clusterName, instanceRAM string, roles NodeRoles) []v1.EnvVar {
 	return []v1.EnvVar{
 		{
 			Name:  "DC_NAME",
@@ -295,10 +377,6 @@ func newEnvVars(nodeName, clusterName, instanceRAM string, roleMap map[api.Elast
 			Name:  "HEAP_DUMP_LOCATION",
 			Value: heapDumpLocation,
 		},
-		{
-			Name:  "RECOVER_AFTER_TIME",
-			Value: "5m",
-		},
 		{
 			Name:  "READINESS_PROBE_TIMEOUT",
 			Value: "30",
@@ -309,39 +387,55 @@ func newEnvVars(nodeName, clusterName, instanceRAM string, roleMap map[api.Elast
 		},
 		{
 			Name:  "IS_MASTER",
-			Value: strconv.FormatBool(roleMap[api.ElasticsearchRoleMaster]),
+			Value: strconv.FormatBool(roles.IsMaster()),
 		},
 		{
 			Name:  "HAS_DATA",
-			Value: strconv.FormatBool(roleMap[api.ElasticsearchRoleData]),
+			Value: strconv.FormatBool(roles.IsData()),
 		},
 	}
 }
 
-// TODO: add isChanged check for labels and label selector
-func newLabels(clusterName, nodeName string, roleMap map[api.ElasticsearchNodeRole]bool) map[string]string {
-	return map[string]string{
-		"es-node-client": strconv.FormatBool(roleMap[api.ElasticsearchRoleClient]),
-		"es-node-data":   strconv.FormatBool(roleMap[api.ElasticsearchRoleData]),
-		"es-node-master": strconv.FormatBool(roleMap[api.ElasticsearchRoleMaster]),
-		"cluster-name":   clusterName,
-		"component":      "elasticsearch",
-		"node-name":      nodeName,
+// mergeEnvVars appends the extra environment variables requested for the Elasticsearch
+// container. Variables already defined or reserved by the operator are skipped.
+func mergeEnvVars(envVars, extraEnv []v1.EnvVar) []v1.EnvVar {
+	defined := map[string]bool{}
+	for _, name := range reservedEnvVars {
+		defined[name] = true
+	}
+	for _, envVar := range envVars {
+		defined[envVar.Name] = true
 	}
-}
 
-func newLabelSelector(clusterName, nodeName string, roleMap map[api.ElasticsearchNodeRole]bool) map[string]string {
-	return map[string]string{
-		"es-node-client": strconv.FormatBool(roleMap[api.ElasticsearchRoleClient]),
-		"es-node-data":   strconv.FormatBool(roleMap[api.ElasticsearchRoleData]),
-		"es-node-master": strconv.FormatBool(roleMap[api.ElasticsearchRoleMaster]),
-		"cluster-name":   clusterName,
-		"node-name":      nodeName,
+	merged := envVars
+	for _, envVar := range extraEnv {
+		if defined[envVar.Name] {
+			log.Info("Ignoring extra environment variable managed by the operator", "name", envVar.Name)
+			continue
+		}
+		defined[envVar.Name] = true
+		merged = append(merged, envVar)
 	}
+
+	return merged
 }
 
-func newPodTemplateSpec(nodeName, clusterName, namespace string, node api.ElasticsearchNode, commonSpec api.ElasticsearchNodeSpec, labels map[string]string, roleMap map[api.ElasticsearchNodeRole]bool, client client.Client, logConfig LogConfig) v1.PodTemplateSpec {
-	resourceRequirements := newESResourceRequirements(node.Resources, commonSpec.Resources)
+// TODO: add isChanged check for labels and label selector
+func newLabels(clusterName, nodeName string, roles NodeRoles) map[string]string {
+	labels := newLabelSelector(clusterName, nodeName, roles)
+	labels["component"] = "elasticsearch"
+	return labels
+}
+
+func newLabelSelector(clusterName, nodeName string, roles NodeRoles) map[string]string {
+	selector := roles.labels()
+	selector["cluster-name"] = clusterName
+	selector["node-name"] = nodeName
+	return selector
+}
+
+func newPodTemplateSpec(nodeName, clusterName, namespace string, node api.ElasticsearchNode, commonSpec api.ElasticsearchNodeSpec, labels map[string]string, roles NodeRoles, client client.Client, logConfig LogConfig) v1.PodTemplateSpec {
+	resourceRequirements := newESNodeResourceRequirements(node.Resources, commonSpec.Resources, roles)
 	proxyResourceRequirements := newESProxyResourceRequirements(node.ProxyResources, commonSpec.ProxyResources)
 
 	selectors := mergeSelectors(node.NodeSelector, commonSpec.NodeSelector)
@@ -354,51 +448,346 @@ func newPodTemplateSpec(nodeName, clusterName, namespace string, node api.Elasti
 			Effect:   v1.TaintEffectNoSchedule,
 		},
 	})
+	tolerations = appendTolerations(
+		newNodeFailureTolerations(getNodeFailureTolerationSeconds(node, commonSpec), tolerations),
+		tolerations,
+	)
 
-	containers := []v1.Container{
-		newElasticsearchContainer(
-			getESImage(),
-			newEnvVars(nodeName, clusterName, resourceRequirements.Limits.Memory().String(), roleMap),
-			resourceRequirements,
-		),
-		newProxyContainer(
-			getESProxyImage(),
-			clusterName,
-			namespace,
-			logConfig,
-			proxyResourceRequirements,
+	volumes := newVolumes(clusterName, nodeName, namespace, node, client)
+
+	elasticsearchContainer := newElasticsearchContainer(
+		getESImage(),
+		mergeEnvVars(
+			newEnvVars(nodeName, clusterName, resourceRequirements.Limits.Memory().String(), roles),
+			commonSpec.ExtraEnv,
 		),
+		resourceRequirements,
+	)
+	elasticsearchContainer.ReadinessProbe = newReadinessProbe(node.ReadinessProbe, roles)
+	setReadinessProbeTimeout(elasticsearchContainer.Env, elasticsearchContainer.ReadinessProbe)
+
+	shutdownDelay := getShutdownDelaySeconds(node, commonSpec, roles)
+	elasticsearchContainer.Lifecycle = newShutdownDelayLifecycle(shutdownDelay)
+
+	if hasJvmOptions(node) {
+		// Elasticsearch 6 reads no jvm.options.d, the image passes ES_JAVA_OPTS to the JVM
+		elasticsearchContainer.Env = append(elasticsearchContainer.Env, v1.EnvVar{
+			Name:  "ES_JAVA_OPTS",
+			Value: strings.Join(node.JvmOptions.Options, " "),
+		})
 	}
 
-	volumes := newVolumes(clusterName, nodeName, namespace, node, client)
+	proxyContainer := newProxyContainer(
+		getESProxyImage(),
+		clusterName,
+		namespace,
+		logConfig,
+		proxyResourceRequirements,
+	)
+
+	// the proxy authorizes requests against the API server, thus needs a token in any case.
+	// The API server does not automount a token into containers mounting one already.
+	if !isAutomountServiceAccountToken(commonSpec) || commonSpec.ProxyServiceAccountToken != nil {
+		volumes = append(volumes, newServiceAccountTokenVolume(commonSpec.ProxyServiceAccountToken))
+		proxyContainer.VolumeMounts = append(proxyContainer.VolumeMounts, v1.VolumeMount{
+			Name:      serviceAccountTokenVolumeName,
+			MountPath: serviceAccountTokenPath,
+			ReadOnly:  true,
+		})
+	}
+
+	if hasPlugins(commonSpec) {
+		volumes = append(volumes, newPluginsVolume())
+		elasticsearchContainer.VolumeMounts = append(elasticsearchContainer.VolumeMounts, v1.VolumeMount{
+			Name:      pluginsVolumeName,
+			MountPath: elasticsearchPluginsPath,
+		})
+	}
+
+	if hasTrustedCA(commonSpec) {
+		volumes = append(volumes, newTrustedCAVolumes(commonSpec.TrustedCA)...)
+		setTrustedCA(&elasticsearchContainer, getTrustedCAHash(client, namespace, commonSpec.TrustedCA))
+	}
+
+	extraVolumes, extraVolumeMounts := newExtraVolumes(volumes, elasticsearchContainer.VolumeMounts, commonSpec)
+	volumes = append(volumes, extraVolumes...)
+	elasticsearchContainer.VolumeMounts = append(elasticsearchContainer.VolumeMounts, extraVolumeMounts...)
+
+	containers := []v1.Container{
+		elasticsearchContainer,
+		proxyContainer,
+	}
+
+	initContainers := []v1.Container{}
+	if commonSpec.ChownDataVolume && !isEphemeralStorage(volumes) {
+		initContainers = append(initContainers, newChownDataVolumeContainer(getESImage(), commonSpec.SecurityContext))
+	}
+	if hasTrustedCA(commonSpec) {
+		initContainers = append(initContainers, newImportTrustedCAContainer(getESImage()))
+	}
+	if hasPlugins(commonSpec) {
+		initContainers = append(initContainers, newInstallPluginsContainer(getESImage(), commonSpec.Plugins, commonSpec.PluginMirror))
+	}
 
 	podSpec := pod.NewSpec(clusterName, containers, volumes).
-		WithAffinity(newAffinity(roleMap)).
+		WithInitContainers(initContainers...).
+		WithAffinity(newAffinity(roles)).
 		WithNodeSelectors(selectors).
 		WithTolerations(tolerations...).
+		WithSecurityContext(newPodSecurityContext(commonSpec.SecurityContext, commonSpec.Sysctls)).
+		WithAutomountServiceAccountToken(commonSpec.AutomountServiceAccountToken).
+		WithPriorityClassName(getPriorityClassName(node, commonSpec)).
+		WithDNSPolicy(newDNSPolicy(commonSpec.DNSPolicy)).
+		WithDNSConfig(commonSpec.DNSConfig).
+		WithRuntimeClassName(commonSpec.RuntimeClassName).
+		WithTerminationGracePeriodSeconds(newTerminationGracePeriod(shutdownDelay)).
 		Build()
 
 	return v1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
-			Labels: labels,
+			Labels:      labels,
+			Annotations: newPodAnnotations(node.PodAnnotations),
 		},
 		Spec: *podSpec,
 	}
 }
 
+// newChownDataVolumeContainer returns the init container changing the ownership of the data
+// volume to the user and group Elasticsearch runs as
+func newChownDataVolumeContainer(imageName string, sc *api.ElasticsearchSecurityContext) v1.Container {
+	runAsUser := defaultESRunAsUser
+	fsGroup := defaultESFSGroup
+	if sc != nil && sc.RunAsUser != nil {
+		runAsUser = *sc.RunAsUser
+	}
+	if sc != nil && sc.FSGroup != nil {
+		fsGroup = *sc.FSGroup
+	}
+
+	root := int64(0)
+	return v1.Container{
+		Name:                     chownDataVolumeContainerName,
+		Image:                    imageName,
+		ImagePullPolicy:          "IfNotPresent",
+		TerminationMessagePolicy: v1.TerminationMessageFallbackToLogsOnError,
+		Command:                  []string{"chown", "-R", fmt.Sprintf("%d:%d", runAsUser, fsGroup), elasticsearchPersistentPath},
+		Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("10m"),
+				v1.ResourceMemory: resource.MustParse("16Mi"),
+			},
+		},
+		SecurityContext: &v1.SecurityContext{
+			RunAsUser: &root,
+		},
+		VolumeMounts: []v1.VolumeMount{
+			{
+				Name:      "elasticsearch-storage",
+				MountPath: elasticsearchPersistentPath,
+			},
+		},
+	}
+}
+
+// isEphemeralStorage returns true if the data volume is an emptyDir, which is already
+// owned by the fsGroup and need not be chowned
+func isEphemeralStorage(volumes []v1.Volume) bool {
+	for _, volume := range volumes {
+		if volume.Name == "elasticsearch-storage" {
+			return volume.EmptyDir != nil
+		}
+	}
+	return true
+}
+
+// newPodAnnotations returns a copy of the annotations requested for the pods of a node group
+func newPodAnnotations(annotations map[string]string) map[string]string {
+	if len(annotations) == 0 {
+		return nil
+	}
+
+	podAnnotations := make(map[string]string, len(annotations))
+	for key, value := range annotations {
+		podAnnotations[key] = value
+	}
+	return podAnnotations
+}
+
+// newDNSPolicy returns the requested DNS policy or ClusterFirst if none is requested
+func newDNSPolicy(policy v1.DNSPolicy) v1.DNSPolicy {
+	if policy == "" {
+		return v1.DNSClusterFirst
+	}
+	return policy
+}
+
+// newPodSecurityContext returns the pod security context for the requested user, group and
+// sysctls. If no user and group is requested we leave it to the platform (e.g. SCCs) to
+// assign them.
+func newPodSecurityContext(sc *api.ElasticsearchSecurityContext, sysctls []v1.Sysctl) *v1.PodSecurityContext {
+	if sc == nil {
+		if len(sysctls) == 0 {
+			return nil
+		}
+		return &v1.PodSecurityContext{Sysctls: append([]v1.Sysctl{}, sysctls...)}
+	}
+
+	runAsUser := defaultESRunAsUser
+	if sc.RunAsUser != nil {
+		runAsUser = *sc.RunAsUser
+	}
+
+	runAsGroup := defaultESRunAsGroup
+	if sc.RunAsGroup != nil {
+		runAsGroup = *sc.RunAsGroup
+	}
+
+	fsGroup := defaultESFSGroup
+	if sc.FSGroup != nil {
+		fsGroup = *sc.FSGroup
+	}
+
+	podSC := &v1.PodSecurityContext{
+		RunAsUser:  &runAsUser,
+		RunAsGroup: &runAsGroup,
+		FSGroup:    &fsGroup,
+	}
+	if sc.RunAsNonRoot != nil {
+		runAsNonRoot := *sc.RunAsNonRoot
+		podSC.RunAsNonRoot = &runAsNonRoot
+	}
+	if len(sysctls) > 0 {
+		podSC.Sysctls = append([]v1.Sysctl{}, sysctls...)
+	}
+	return podSC
+}
+
+// newExtraVolumes returns the additional volumes and volume mounts requested for the
+// Elasticsearch container. Entries colliding with the operator managed volumes or
+// mount paths are skipped so they cannot replace the configuration, certificates or data.
+func newExtraVolumes(volumes []v1.Volume, volumeMounts []v1.VolumeMount, commonSpec api.ElasticsearchNodeSpec) ([]v1.Volume, []v1.VolumeMount) {
+	reservedNames := map[string]bool{}
+	for _, volume := range volumes {
+		reservedNames[volume.Name] = true
+	}
+
+	extraVolumes := []v1.Volume{}
+	for _, volume := range commonSpec.ExtraVolumes {
+		if reservedNames[volume.Name] {
+			log.Info("Ignoring extra volume colliding with an operator managed volume", "volume", volume.Name)
+			continue
+		}
+		extraVolumes = append(extraVolumes, volume)
+	}
+
+	extraVolumeMounts := []v1.VolumeMount{}
+	for _, volumeMount := range commonSpec.ExtraVolumeMounts {
+		if reservedNames[volumeMount.Name] || isReservedMountPath(volumeMount.MountPath, volumeMounts) {
+			log.Info("Ignoring extra volume mount colliding with an operator managed volume mount",
+				"volume", volumeMount.Name,
+				"mountPath", volumeMount.MountPath)
+			continue
+		}
+		extraVolumeMounts = append(extraVolumeMounts, volumeMount)
+	}
+
+	return extraVolumes, extraVolumeMounts
+}
+
+// isReservedMountPath returns true if the path is or is nested within any of the given volume mounts
+func isReservedMountPath(mountPath string, volumeMounts []v1.VolumeMount) bool {
+	mountPath = path.Clean(mountPath)
+
+	for _, volumeMount := range volumeMounts {
+		reserved := path.Clean(volumeMount.MountPath)
+		if mountPath == reserved || strings.HasPrefix(mountPath, reserved+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
 // createUpdatablePodTemplateSpec creates a pod template from a copy of the update with
-// some aspects of the current
+// some aspects of the current. The operator managed volumes are kept as is, while
+// extra volumes are taken from the update so they can be added and removed.
 func createUpdatablePodTemplateSpec(current, desired v1.PodTemplateSpec) v1.PodTemplateSpec {
 	desiredCopy := desired
-	desiredCopy.Spec.Volumes = current.Spec.Volumes
+	desiredCopy.Spec.Volumes = []v1.Volume{}
+
+	for _, volume := range desired.Spec.Volumes {
+		if isOperatorVolume(volume.Name) {
+			if currentVolume, ok := getVolume(volume.Name, current.Spec.Volumes); ok {
+				volume = currentVolume
+			}
+		}
+		desiredCopy.Spec.Volumes = append(desiredCopy.Spec.Volumes, volume)
+	}
 
 	return desiredCopy
 }
 
+func isOperatorVolume(name string) bool {
+	switch name {
+	case "elasticsearch-config", "elasticsearch-storage", "certificates":
+		return true
+	}
+
+	return false
+}
+
+func getVolume(name string, volumes []v1.Volume) (v1.Volume, bool) {
+	for _, volume := range volumes {
+		if volume.Name == name {
+			return volume, true
+		}
+	}
+
+	return v1.Volume{}, false
+}
+
 func newESResourceRequirements(nodeResRequirements, commonResRequirements v1.ResourceRequirements) v1.ResourceRequirements {
 	return newResourceRequirements(nodeResRequirements, commonResRequirements, defaultResources["elasticsearch"])
 }
 
+// masterOnlyResourceDefaults enables the lower default resources of master only nodes.
+// It is disabled by default, since it rolls the masters of existing clusters with less heap.
+var masterOnlyResourceDefaults = false
+
+// SetMasterOnlyResourceDefaults sets whether master only nodes requesting no resources
+// default to less memory than the other nodes
+func SetMasterOnlyResourceDefaults(enabled bool) {
+	masterOnlyResourceDefaults = enabled
+}
+
+// newESNodeResourceRequirements returns the resource requirements for the Elasticsearch
+// container of a node with the given roles. Master only nodes default to less memory if
+// enabled.
+func newESNodeResourceRequirements(nodeResRequirements, commonResRequirements v1.ResourceRequirements, roles NodeRoles) v1.ResourceRequirements {
+	return newResourceRequirements(nodeResRequirements, commonResRequirements, defaultESResources(roles))
+}
+
+// defaultESResources returns the default resource requirements for the Elasticsearch container of a node with the given roles
+func defaultESResources(roles NodeRoles) v1.ResourceRequirements {
+	if masterOnlyResourceDefaults && roles.IsMasterOnly() {
+		return defaultResources["elasticsearch-master"]
+	}
+
+	return defaultResources["elasticsearch"]
+}
+
+// newDefaultedESResources returns the resource requirements applied to the Elasticsearch
+// container if neither the node nor the common spec request any, else nil
+func newDefaultedESResources(nodeResRequirements, commonResRequirements v1.ResourceRequirements, roles NodeRoles) *v1.ResourceRequirements {
+	if len(nodeResRequirements.Limits) > 0 || len(nodeResRequirements.Requests) > 0 ||
+		len(commonResRequirements.Limits) > 0 || len(commonResRequirements.Requests) > 0 {
+		return nil
+	}
+
+	defaulted := newESNodeResourceRequirements(nodeResRequirements, commonResRequirements, roles)
+	return &defaulted
+}
+
 func newESProxyResourceRequirements(nodeResRequirements, commonResRequirements v1.ResourceRequirements) v1.ResourceRequirements {
 	return newResourceRequirements(nodeResRequirements, commonResRequirements, defaultResources["proxy"])
 }
@@ -527,21 +916,8 @@ func newResourceRequirements(nodeResRequirements, commonResRequirements, default
 		}
 	}
 
-	if limitCPU == nil {
-		return v1.ResourceRequirements{
-			Limits: v1.ResourceList{
-				"memory": *limitMem,
-			},
-			Requests: v1.ResourceList{
-				"cpu":    *requestCPU,
-				"memory": *requestMem,
-			},
-		}
-	}
-
-	return v1.ResourceRequirements{
+	requirements := v1.ResourceRequirements{
 		Limits: v1.ResourceList{
-			"cpu":    *limitCPU,
 			"memory": *limitMem,
 		},
 		Requests: v1.ResourceList{
@@ -549,6 +925,95 @@ func newResourceRequirements(nodeResRequirements, commonResRequirements, default
 			"memory": *requestMem,
 		},
 	}
+	if limitCPU != nil {
+		requirements.Limits["cpu"] = *limitCPU
+	}
+
+	setEphemeralStorageRequirements(&requirements, nodeResRequirements, commonResRequirements, defaultRequirements)
+	return requirements
+}
+
+// setEphemeralStorageRequirements sets the ephemeral storage requested or limited by the node,
+// else by the common spec, else by the defaults. Like memory, a limit alone is requested as well.
+// None is requested by default, since changing the requests rolls the nodes of existing clusters.
+func setEphemeralStorageRequirements(requirements *v1.ResourceRequirements, nodeResRequirements, commonResRequirements, defaultRequirements v1.ResourceRequirements) {
+	for _, source := range []v1.ResourceRequirements{nodeResRequirements, commonResRequirements, defaultRequirements} {
+		request := source.Requests[v1.ResourceEphemeralStorage]
+		limit := source.Limits[v1.ResourceEphemeralStorage]
+		if request.IsZero() && limit.IsZero() {
+			continue
+		}
+
+		if request.IsZero() {
+			request = limit
+		}
+		requirements.Requests[v1.ResourceEphemeralStorage] = request
+		if !limit.IsZero() {
+			requirements.Limits[v1.ResourceEphemeralStorage] = limit
+		}
+		return
+	}
+}
+
+func isAutomountServiceAccountToken(commonSpec api.ElasticsearchNodeSpec) bool {
+	return commonSpec.AutomountServiceAccountToken == nil || *commonSpec.AutomountServiceAccountToken
+}
+
+// newServiceAccountTokenVolume projects a bound service account token with the CA and the
+// namespace, i.e. the same files the kubelet mounts when the token is automounted. The
+// audience and expiration of the spec, if any, bind the token.
+func newServiceAccountTokenVolume(spec *api.ElasticsearchProxyTokenSpec) v1.Volume {
+	audience := ""
+	expirationSeconds := serviceAccountTokenExpirationSeconds
+	if spec != nil {
+		audience = spec.Audience
+		if spec.ExpirationSeconds != nil {
+			expirationSeconds = *spec.ExpirationSeconds
+		}
+	}
+
+	return v1.Volume{
+		Name: serviceAccountTokenVolumeName,
+		VolumeSource: v1.VolumeSource{
+			Projected: &v1.ProjectedVolumeSource{
+				Sources: []v1.VolumeProjection{
+					{
+						ServiceAccountToken: &v1.ServiceAccountTokenProjection{
+							Audience:          audience,
+							Path:              "token",
+							ExpirationSeconds: &expirationSeconds,
+						},
+					},
+					{
+						ConfigMap: &v1.ConfigMapProjection{
+							LocalObjectReference: v1.LocalObjectReference{
+								Name: "kube-root-ca.crt",
+							},
+							Items: []v1.KeyToPath{
+								{
+									Key:  "ca.crt",
+									Path: "ca.crt",
+								},
+							},
+						},
+					},
+					{
+						DownwardAPI: &v1.DownwardAPIProjection{
+							Items: []v1.DownwardAPIVolumeFile{
+								{
+									Path: "namespace",
+									FieldRef: &v1.ObjectFieldSelector{
+										APIVersion: "v1",
+										FieldPath:  "metadata.namespace",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
 }
 
 func newVolumes(clusterName, nodeName, namespace string, node api.ElasticsearchNode, client client.Client) []v1.Volume {
@@ -606,33 +1071,37 @@ func newVolumeSource(clusterName, nodeName, namespace string, node api.Elasticse
 	}
 
 	// Persistent storage
-	claimName := fmt.Sprintf("%s-%s", clusterName, nodeName)
+	pvc := newPersistentVolumeClaim(clusterName, nodeName, namespace, specVol)
 	volSource.PersistentVolumeClaim = &v1.PersistentVolumeClaimVolumeSource{
-		ClaimName: claimName,
+		ClaimName: pvc.Name,
 	}
 
+	err := persistentvolume.CreateOrUpdatePVC(context.TODO(), client, pvc, persistentvolume.LabelsEqual, persistentvolume.MutateLabelsOnly)
+	if err != nil {
+		log.Error(err, "Unable to create PersistentVolumeClaim")
+	}
+
+	return volSource
+}
+
+// newPersistentVolumeClaim returns the claim of the persistent storage of the node
+func newPersistentVolumeClaim(clusterName, nodeName, namespace string, specVol api.ElasticsearchStorageSpec) *v1.PersistentVolumeClaim {
+	claimName := fmt.Sprintf("%s-%s", clusterName, nodeName)
 	pvcLabels := map[string]string{
 		"logging-cluster": clusterName,
 	}
 	pvc := persistentvolume.NewPVC(claimName, namespace, pvcLabels)
 	pvc.Spec = v1.PersistentVolumeClaimSpec{
-		AccessModes: []v1.PersistentVolumeAccessMode{
-			v1.ReadWriteOnce,
-		},
+		AccessModes: getPVCAccessModes(specVol),
 		Resources: v1.ResourceRequirements{
 			Requests: v1.ResourceList{
 				v1.ResourceStorage: *specVol.Size,
 			},
 		},
 		StorageClassName: specVol.StorageClassName,
+		VolumeMode:       specVol.VolumeMode,
 	}
-
-	err := persistentvolume.CreateOrUpdatePVC(context.TODO(), client, pvc, persistentvolume.LabelsEqual, persistentvolume.MutateLabelsOnly)
-	if err != nil {
-		log.Error(err, "Unable to create PersistentVolumeClaim")
-	}
-
-	return volSource
+	return pvc
 }
 
 /*
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -17,6 +18,8 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 
 	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/image"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/pod"
 	"github.com/openshift/elasticsearch-operator/internal/utils"
 	"github.com/openshift/elasticsearch-operator/internal/utils/comparators"
 	"github.com/openshift/elasticsearch-operator/test/helpers"
@@ -155,6 +158,83 @@ func TestResourcesNoCommonNoNodeDefined(t *testing.T) {
 	}
 }
 
+func TestResourcesNoCommonNoNodeDefinedPerRole(t *testing.T) {
+	masterOnly := newNodeRoles(api.ElasticsearchRoleMaster)
+	data := newNodeRoles(api.ElasticsearchRoleMaster, api.ElasticsearchRoleData)
+
+	defaults := buildNoCPULimitResource(
+		defaultTestCPURequest,
+		defaultTestMemLimit,
+		defaultTestMemRequest,
+	)
+	actual := newESNodeResourceRequirements(v1.ResourceRequirements{}, v1.ResourceRequirements{}, masterOnly)
+	if !areResourcesSame(actual, defaults) {
+		t.Errorf("Expected the common defaults for masters unless enabled %v but got %v", printResource(defaults), printResource(actual))
+	}
+
+	SetMasterOnlyResourceDefaults(true)
+	defer SetMasterOnlyResourceDefaults(false)
+
+	expected := buildNoCPULimitResource(
+		resource.MustParse(defaultESMasterCPURequest),
+		resource.MustParse(defaultESMasterMemoryLimit),
+		resource.MustParse(defaultESMasterMemoryRequest),
+	)
+	actual = newESNodeResourceRequirements(v1.ResourceRequirements{}, v1.ResourceRequirements{}, masterOnly)
+	if !areResourcesSame(actual, expected) {
+		t.Errorf("Expected %v but got %v", printResource(expected), printResource(actual))
+	}
+
+	actual = newESNodeResourceRequirements(v1.ResourceRequirements{}, v1.ResourceRequirements{}, data)
+	if !areResourcesSame(actual, defaults) {
+		t.Errorf("Expected %v but got %v", printResource(defaults), printResource(actual))
+	}
+}
+
+func TestResourcesEphemeralStorage(t *testing.T) {
+	data := newNodeRoles(api.ElasticsearchRoleData)
+	client := newNodeRoles(api.ElasticsearchRoleClient)
+
+	for _, roles := range []NodeRoles{data, client} {
+		if _, ok := newESNodeResourceRequirements(v1.ResourceRequirements{}, v1.ResourceRequirements{}, roles).Requests[v1.ResourceEphemeralStorage]; ok {
+			t.Error("Expected no ephemeral storage request unless requested")
+		}
+	}
+
+	common := v1.ResourceRequirements{
+		Requests: v1.ResourceList{v1.ResourceEphemeralStorage: resource.MustParse("2Gi")},
+	}
+	node := v1.ResourceRequirements{
+		Limits: v1.ResourceList{v1.ResourceEphemeralStorage: resource.MustParse("10Gi")},
+	}
+
+	actual := newESNodeResourceRequirements(v1.ResourceRequirements{}, common, client)
+	if request := actual.Requests[v1.ResourceEphemeralStorage]; request.Cmp(resource.MustParse("2Gi")) != 0 {
+		t.Errorf("Expected the common ephemeral storage request but got %v", printResource(actual))
+	}
+
+	actual = newESNodeResourceRequirements(node, common, data)
+	request := actual.Requests[v1.ResourceEphemeralStorage]
+	limit := actual.Limits[v1.ResourceEphemeralStorage]
+	if request.Cmp(resource.MustParse("10Gi")) != 0 || limit.Cmp(resource.MustParse("10Gi")) != 0 {
+		t.Errorf("Expected the node ephemeral storage limit to be requested as well but got %v", printResource(actual))
+	}
+}
+
+func TestDefaultedResources(t *testing.T) {
+	masterOnly := newNodeRoles(api.ElasticsearchRoleMaster)
+
+	defaulted := newDefaultedESResources(v1.ResourceRequirements{}, v1.ResourceRequirements{}, masterOnly)
+	if defaulted == nil || !areResourcesSame(*defaulted, defaultESResources(masterOnly)) {
+		t.Errorf("Expected the master defaults to be reported but got %v", defaulted)
+	}
+
+	common := buildResourceOnlyRequests(defaultTestCPURequest, defaultTestMemRequest)
+	if defaulted := newDefaultedESResources(v1.ResourceRequirements{}, common, masterOnly); defaulted != nil {
+		t.Errorf("Expected no defaults to be reported for requested resources but got %v", printResource(*defaulted))
+	}
+}
+
 // 4
 func TestResourcesCommonAndNodeRequestDefined(t *testing.T) {
 	commonRequirements := buildResource(
@@ -556,7 +636,7 @@ func TestPodSpecHasTaintTolerations(t *testing.T) {
 		},
 	}
 
-	podTemplateSpec := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, api.ElasticsearchNodeSpec{}, map[string]string{}, map[api.ElasticsearchNodeRole]bool{}, nil, LogConfig{})
+	podTemplateSpec := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, api.ElasticsearchNodeSpec{}, map[string]string{}, NodeRoles{}, nil, LogConfig{})
 
 	if !reflect.DeepEqual(podTemplateSpec.Spec.Tolerations, expectedTolerations) {
 		t.Errorf("Exp. the tolerations to be %v but was %v", expectedTolerations, podTemplateSpec.Spec.Tolerations)
@@ -635,6 +715,167 @@ func TestPodDiskToleration(t *testing.T) {
 	}
 }
 
+func TestPodNodeFailureTolerations(t *testing.T) {
+	commonSeconds := int64(600)
+	nodeSeconds := int64(1800)
+	newFailureTolerations := func(seconds int64) []v1.Toleration {
+		return []v1.Toleration{
+			{
+				Key:               "node.kubernetes.io/not-ready",
+				Operator:          v1.TolerationOpExists,
+				Effect:            v1.TaintEffectNoExecute,
+				TolerationSeconds: &seconds,
+			},
+			{
+				Key:               "node.kubernetes.io/unreachable",
+				Operator:          v1.TolerationOpExists,
+				Effect:            v1.TaintEffectNoExecute,
+				TolerationSeconds: &seconds,
+			},
+		}
+	}
+
+	commonSpec := api.ElasticsearchNodeSpec{NodeFailureTolerationSeconds: &commonSeconds}
+	podSpec := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, commonSpec, map[string]string{}, NodeRoles{}, nil, LogConfig{}).Spec
+	if !comparators.ContainsSameTolerations(podSpec.Tolerations, newFailureTolerations(commonSeconds)) {
+		t.Errorf("Exp. the node failure tolerations of the common spec but got %v", podSpec.Tolerations)
+	}
+
+	node := api.ElasticsearchNode{NodeFailureTolerationSeconds: &nodeSeconds}
+	nodeSpec := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", node, commonSpec, map[string]string{}, NodeRoles{}, nil, LogConfig{}).Spec
+	if !comparators.ContainsSameTolerations(nodeSpec.Tolerations, newFailureTolerations(nodeSeconds)) {
+		t.Errorf("Exp. the node failure tolerations of the node to override the common spec but got %v", nodeSpec.Tolerations)
+	}
+
+	// a change of the seconds alone must roll the node
+	if pod.ArePodSpecEqual(podSpec, nodeSpec, true) {
+		t.Error("Exp. the pod specs to differ by the toleration seconds")
+	}
+
+	// tolerations of the same taints defined by the user are kept as is
+	userSeconds := int64(60)
+	commonSpec.Tolerations = []v1.Toleration{
+		{
+			Key:               "node.kubernetes.io/unreachable",
+			Operator:          v1.TolerationOpExists,
+			Effect:            v1.TaintEffectNoExecute,
+			TolerationSeconds: &userSeconds,
+		},
+	}
+	podSpec = newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, commonSpec, map[string]string{}, NodeRoles{}, nil, LogConfig{}).Spec
+	if len(podSpec.Tolerations) != 3 {
+		t.Errorf("Exp. the disk, user and not-ready tolerations but got %v", podSpec.Tolerations)
+	}
+	if !comparators.ContainsSameTolerations(podSpec.Tolerations, commonSpec.Tolerations) {
+		t.Errorf("Exp. the user toleration to be kept but got %v", podSpec.Tolerations)
+	}
+}
+
+func TestPodExtraVolumes(t *testing.T) {
+	commonSpec := api.ElasticsearchNodeSpec{
+		ExtraVolumes: []v1.Volume{
+			{
+				Name: "trust-store",
+				VolumeSource: v1.VolumeSource{
+					ConfigMap: &v1.ConfigMapVolumeSource{
+						LocalObjectReference: v1.LocalObjectReference{Name: "trust-store"},
+					},
+				},
+			},
+			{
+				Name: "certificates",
+			},
+		},
+		ExtraVolumeMounts: []v1.VolumeMount{
+			{Name: "trust-store", MountPath: "/etc/pki/custom"},
+			{Name: "trust-store", MountPath: elasticsearchConfigPath},
+			{Name: "trust-store", MountPath: elasticsearchPersistentPath + "/nested"},
+			{Name: "certificates", MountPath: "/etc/certificates"},
+		},
+	}
+
+	podSpec := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, commonSpec, map[string]string{}, NodeRoles{}, nil, LogConfig{}).Spec
+
+	if len(podSpec.Volumes) != 5 {
+		t.Errorf("Exp. only the trust-store volume to be added but found %d volumes", len(podSpec.Volumes))
+	}
+	if _, ok := getVolume("trust-store", podSpec.Volumes); !ok {
+		t.Errorf("Exp. the trust-store volume to be added to %v", podSpec.Volumes)
+	}
+	if volume, _ := getVolume("certificates", podSpec.Volumes); volume.Secret == nil {
+		t.Errorf("Exp. the certificates volume not to be replaced but was %v", volume)
+	}
+
+	expectedMount := v1.VolumeMount{Name: "trust-store", MountPath: "/etc/pki/custom"}
+	mounts := podSpec.Containers[0].VolumeMounts
+	if len(mounts) != 4 || !reflect.DeepEqual(mounts[3], expectedMount) {
+		t.Errorf("Exp. only the %v mount to be added but was %v", expectedMount, mounts)
+	}
+}
+
+func TestUpdatablePodTemplateSpecExtraVolumes(t *testing.T) {
+	current := v1.PodTemplateSpec{
+		Spec: v1.PodSpec{
+			Volumes: []v1.Volume{
+				{Name: "elasticsearch-storage", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}},
+				{Name: "removed"},
+			},
+		},
+	}
+	desired := v1.PodTemplateSpec{
+		Spec: v1.PodSpec{
+			Volumes: []v1.Volume{
+				{Name: "elasticsearch-storage"},
+				{Name: "added"},
+			},
+		},
+	}
+
+	volumes := createUpdatablePodTemplateSpec(current, desired).Spec.Volumes
+
+	if len(volumes) != 2 {
+		t.Errorf("Exp. 2 volumes but found %v", volumes)
+	}
+	if volume, _ := getVolume("elasticsearch-storage", volumes); volume.EmptyDir == nil {
+		t.Errorf("Exp. the current storage volume to be kept but was %v", volume)
+	}
+	if _, ok := getVolume("added", volumes); !ok {
+		t.Errorf("Exp. the added volume to be part of %v", volumes)
+	}
+}
+
+func TestPodExtraEnv(t *testing.T) {
+	commonSpec := api.ElasticsearchNodeSpec{
+		ExtraEnv: []v1.EnvVar{
+			{Name: "HTTP_PROXY", Value: "http://proxy:3128"},
+			{
+				Name: "FEATURE_FLAG",
+				ValueFrom: &v1.EnvVarSource{
+					ConfigMapKeyRef: &v1.ConfigMapKeySelector{
+						LocalObjectReference: v1.LocalObjectReference{Name: "flags"},
+						Key:                  "feature",
+					},
+				},
+			},
+			{Name: "CLUSTER_NAME", Value: "other"},
+			{Name: "ES_JAVA_OPTS", Value: "-Xmx1g"},
+			{Name: "HTTP_PROXY", Value: "http://duplicate:3128"},
+		},
+	}
+
+	podSpec := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, commonSpec, map[string]string{}, NodeRoles{}, nil, LogConfig{}).Spec
+
+	expected := append(
+		newEnvVars("test-node-name", "test-cluster-name", podSpec.Containers[0].Resources.Limits.Memory().String(), NodeRoles{}),
+		commonSpec.ExtraEnv[0],
+		commonSpec.ExtraEnv[1],
+	)
+
+	if !comparators.EnvValueEqual(podSpec.Containers[0].Env, expected) {
+		t.Errorf("Exp. the env to be %v but was %v", expected, podSpec.Containers[0].Env)
+	}
+}
+
 func TestNewVolumeSource(t *testing.T) {
 	const (
 		clusterName = "elastisearch"
@@ -784,7 +1025,7 @@ func preparePodTemplateSpecProvidingNodeSelectors(selectors map[string]string) v
 		api.ElasticsearchNode{NodeSelector: selectors},
 		api.ElasticsearchNodeSpec{},
 		map[string]string{},
-		map[api.ElasticsearchNodeRole]bool{},
+		NodeRoles{},
 		nil,
 		LogConfig{})
 }
@@ -850,7 +1091,7 @@ var _ = Describe("common.go", func() {
 	Describe("#newEnvVars", func() {
 		var envVars []v1.EnvVar
 		BeforeEach(func() {
-			envVars = newEnvVars("theNodeName", "theClusterName", "theInstanceRam", map[api.ElasticsearchNodeRole]bool{})
+			envVars = newEnvVars("theNodeName", "theClusterName", "theInstanceRam", NodeRoles{})
 		})
 
 		It("should define POD_IP so IPV4 or IPV6 deployments are possible", func() {
@@ -858,3 +1099,395 @@ var _ = Describe("common.go", func() {
 		})
 	})
 })
+
+func TestPodJvmOptions(t *testing.T) {
+	node := api.ElasticsearchNode{
+		JvmOptions: &api.ElasticsearchJvmOptionsSpec{
+			Options: []string{"-XX:+UseG1GC", "-XX:G1ReservePercent=25"},
+		},
+	}
+
+	podSpec := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", node, api.ElasticsearchNodeSpec{}, map[string]string{}, NodeRoles{}, nil, LogConfig{}).Spec
+
+	if !hasEnvVar("ES_JAVA_OPTS", "-XX:+UseG1GC -XX:G1ReservePercent=25", podSpec.Containers[0].Env) {
+		t.Errorf("Exp. the jvm options to be passed through ES_JAVA_OPTS but was %v", podSpec.Containers[0].Env)
+	}
+	if _, ok := getVolume("elasticsearch-jvm-options", podSpec.Volumes); ok {
+		t.Errorf("Exp. no jvm options volume since Elasticsearch 6 reads no jvm.options.d but was %v", podSpec.Volumes)
+	}
+
+	changed := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{
+		JvmOptions: &api.ElasticsearchJvmOptionsSpec{
+			Options: []string{"-XX:+UseConcMarkSweepGC"},
+		},
+	}, api.ElasticsearchNodeSpec{}, map[string]string{}, NodeRoles{}, nil, LogConfig{}).Spec
+
+	if comparators.EnvValueEqual(podSpec.Containers[0].Env, changed.Containers[0].Env) {
+		t.Error("Exp. changed jvm options to change the container env to roll the node")
+	}
+}
+
+func TestPodAutomountServiceAccountTokenDisabled(t *testing.T) {
+	disabled := false
+	commonSpec := api.ElasticsearchNodeSpec{
+		AutomountServiceAccountToken: &disabled,
+	}
+
+	podSpec := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, commonSpec, map[string]string{}, NodeRoles{}, nil, LogConfig{}).Spec
+
+	if podSpec.AutomountServiceAccountToken == nil || *podSpec.AutomountServiceAccountToken {
+		t.Errorf("Exp. automountServiceAccountToken to be disabled but was %v", podSpec.AutomountServiceAccountToken)
+	}
+
+	if _, ok := getVolume(serviceAccountTokenVolumeName, podSpec.Volumes); !ok {
+		t.Errorf("Exp. the pod to have the projected token volume but was %v", podSpec.Volumes)
+	}
+
+	tokenMount := v1.VolumeMount{Name: serviceAccountTokenVolumeName, MountPath: serviceAccountTokenPath, ReadOnly: true}
+	for _, container := range podSpec.Containers {
+		mounted := comparators.ContainsSameVolumeMounts(container.VolumeMounts, []v1.VolumeMount{tokenMount})
+		if container.Name == "proxy" && !mounted {
+			t.Errorf("Exp. the proxy container to mount the token but was %v", container.VolumeMounts)
+		}
+		if container.Name == "elasticsearch" && mounted {
+			t.Errorf("Exp. the elasticsearch container to not mount the token but was %v", container.VolumeMounts)
+		}
+	}
+}
+
+func TestPodPriorityClassName(t *testing.T) {
+	commonSpec := api.ElasticsearchNodeSpec{
+		PriorityClassName: "logging-default",
+	}
+
+	podSpec := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, commonSpec, map[string]string{}, NodeRoles{}, nil, LogConfig{}).Spec
+	if podSpec.PriorityClassName != "logging-default" {
+		t.Errorf("Exp. the priority class of the common spec but was %q", podSpec.PriorityClassName)
+	}
+
+	node := api.ElasticsearchNode{PriorityClassName: "logging-data"}
+	podSpec = newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", node, commonSpec, map[string]string{}, NodeRoles{}, nil, LogConfig{}).Spec
+	if podSpec.PriorityClassName != "logging-data" {
+		t.Errorf("Exp. the priority class of the node to override the common spec but was %q", podSpec.PriorityClassName)
+	}
+}
+
+func TestPodRuntimeClassName(t *testing.T) {
+	kata := "kata"
+	commonSpec := api.ElasticsearchNodeSpec{
+		RuntimeClassName: &kata,
+	}
+
+	podSpec := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, commonSpec, map[string]string{}, NodeRoles{}, nil, LogConfig{}).Spec
+	if podSpec.RuntimeClassName == nil || *podSpec.RuntimeClassName != "kata" {
+		t.Errorf("Exp. the runtime class of the common spec but was %v", podSpec.RuntimeClassName)
+	}
+}
+
+func TestPodImageRegistryOverride(t *testing.T) {
+	image.SetRegistry("mirror.example.com")
+	defer image.SetRegistry("")
+
+	podSpec := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, api.ElasticsearchNodeSpec{}, map[string]string{}, NodeRoles{}, nil, LogConfig{}).Spec
+	for _, container := range podSpec.Containers {
+		if !strings.HasPrefix(container.Image, "mirror.example.com/") {
+			t.Errorf("Exp. the image of container %s to be moved to the registry but was %q", container.Name, container.Image)
+		}
+	}
+
+	// the desired spec is built with the rewritten images, thus rolled out nodes compare equal
+	desired := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, api.ElasticsearchNodeSpec{}, map[string]string{}, NodeRoles{}, nil, LogConfig{}).Spec
+	if !pod.ArePodSpecEqual(podSpec, desired, true) {
+		t.Errorf("Exp. no difference but got %v", pod.DiffPodSpec(podSpec, desired, true))
+	}
+}
+
+func TestPodDNSPolicy(t *testing.T) {
+	podSpec := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, api.ElasticsearchNodeSpec{}, map[string]string{}, NodeRoles{}, nil, LogConfig{}).Spec
+	if podSpec.DNSPolicy != v1.DNSClusterFirst {
+		t.Errorf("Exp. the default dnsPolicy %q but was %q", v1.DNSClusterFirst, podSpec.DNSPolicy)
+	}
+
+	dnsConfig := &v1.PodDNSConfig{Searches: []string{"remote.svc.cluster.local"}}
+	commonSpec := api.ElasticsearchNodeSpec{
+		DNSPolicy: v1.DNSClusterFirstWithHostNet,
+		DNSConfig: dnsConfig,
+	}
+	podSpec = newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, commonSpec, map[string]string{}, NodeRoles{}, nil, LogConfig{}).Spec
+	if podSpec.DNSPolicy != v1.DNSClusterFirstWithHostNet {
+		t.Errorf("Exp. the requested dnsPolicy but was %q", podSpec.DNSPolicy)
+	}
+	if !reflect.DeepEqual(podSpec.DNSConfig, dnsConfig) {
+		t.Errorf("Exp. the requested dnsConfig %v but was %v", dnsConfig, podSpec.DNSConfig)
+	}
+}
+
+func TestPodReadinessProbe(t *testing.T) {
+	dataRoles := newNodeRoles(api.ElasticsearchRoleData)
+	podSpec := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, api.ElasticsearchNodeSpec{}, map[string]string{}, dataRoles, nil, LogConfig{}).Spec
+	if probe := podSpec.Containers[0].ReadinessProbe; probe.InitialDelaySeconds != defaultReadinessProbeInitialDelaySeconds {
+		t.Errorf("Exp. data nodes to default to an initial delay of %d but was %d", defaultReadinessProbeInitialDelaySeconds, probe.InitialDelaySeconds)
+	}
+
+	masterRoles := newNodeRoles(api.ElasticsearchRoleMaster)
+	podSpec = newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, api.ElasticsearchNodeSpec{}, map[string]string{}, masterRoles, nil, LogConfig{}).Spec
+	if probe := podSpec.Containers[0].ReadinessProbe; probe.InitialDelaySeconds != defaultNoDataReadinessProbeInitialDelaySeconds {
+		t.Errorf("Exp. master only nodes to default to an initial delay of %d but was %d", defaultNoDataReadinessProbeInitialDelaySeconds, probe.InitialDelaySeconds)
+	}
+
+	timeout := int32(60)
+	node := api.ElasticsearchNode{
+		ReadinessProbe: &api.ElasticsearchProbeSpec{
+			Command:        []string{"/usr/share/elasticsearch/probe/data-readiness.sh"},
+			TimeoutSeconds: &timeout,
+		},
+	}
+	desired := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", node, api.ElasticsearchNodeSpec{}, map[string]string{}, dataRoles, nil, LogConfig{}).Spec
+	probe := desired.Containers[0].ReadinessProbe
+	if !reflect.DeepEqual(probe.Exec.Command, node.ReadinessProbe.Command) || probe.TimeoutSeconds != timeout {
+		t.Errorf("Exp. the requested command and timeout but got %v", probe)
+	}
+	for _, envVar := range desired.Containers[0].Env {
+		if envVar.Name == "READINESS_PROBE_TIMEOUT" && envVar.Value != "60" {
+			t.Errorf("Exp. the readiness script timeout to follow the probe timeout but was %q", envVar.Value)
+		}
+	}
+
+	current := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, api.ElasticsearchNodeSpec{}, map[string]string{}, dataRoles, nil, LogConfig{}).Spec
+	if got := pod.DiffPodSpec(current, desired, true); !reflect.DeepEqual(got, []string{"containers[elasticsearch].env", "containers[elasticsearch].readinessProbe"}) {
+		t.Errorf("Exp. a changed probe to roll the node but got %v", got)
+	}
+}
+
+func TestPodShutdownDelay(t *testing.T) {
+	dataRoles := newNodeRoles(api.ElasticsearchRoleData)
+	podSpec := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, api.ElasticsearchNodeSpec{}, map[string]string{}, dataRoles, nil, LogConfig{}).Spec
+	if lifecycle := podSpec.Containers[0].Lifecycle; lifecycle != nil {
+		t.Errorf("Exp. nodes without the client role not to delay their shutdown but got %v", lifecycle)
+	}
+	if grace := podSpec.TerminationGracePeriodSeconds; grace == nil || *grace != elasticsearchShutdownSeconds {
+		t.Errorf("Exp. the default termination grace period but was %v", grace)
+	}
+
+	clientRoles := newNodeRoles(api.ElasticsearchRoleClient)
+	podSpec = newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, api.ElasticsearchNodeSpec{}, map[string]string{}, clientRoles, nil, LogConfig{}).Spec
+	lifecycle := podSpec.Containers[0].Lifecycle
+	if lifecycle == nil || lifecycle.PreStop == nil || !reflect.DeepEqual(lifecycle.PreStop.Exec.Command, []string{"sleep", "20"}) {
+		t.Errorf("Exp. client nodes to sleep for the default delay before stopping but got %v", lifecycle)
+	}
+	if grace := podSpec.TerminationGracePeriodSeconds; grace == nil || *grace != 50 {
+		t.Errorf("Exp. the termination grace period to cover the delay but was %v", grace)
+	}
+
+	clientDataRoles := newNodeRoles(api.ElasticsearchRoleClient, api.ElasticsearchRoleData)
+	clientDataSpec := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, api.ElasticsearchNodeSpec{}, map[string]string{}, clientDataRoles, nil, LogConfig{}).Spec
+	if lifecycle := clientDataSpec.Containers[0].Lifecycle; lifecycle != nil {
+		t.Errorf("Exp. client nodes with other roles not to delay their shutdown by default but got %v", lifecycle)
+	}
+	explicitDelay := int64(10)
+	clientDataSpec = newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{ShutdownDelaySeconds: &explicitDelay}, api.ElasticsearchNodeSpec{}, map[string]string{}, clientDataRoles, nil, LogConfig{}).Spec
+	if grace := clientDataSpec.TerminationGracePeriodSeconds; grace == nil || *grace != 40 {
+		t.Errorf("Exp. client nodes with other roles to apply a requested delay but was %v", grace)
+	}
+
+	nodeDelay := int64(45)
+	commonDelay := int64(0)
+	node := api.ElasticsearchNode{ShutdownDelaySeconds: &nodeDelay}
+	commonSpec := api.ElasticsearchNodeSpec{ShutdownDelaySeconds: &commonDelay}
+	desired := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", node, commonSpec, map[string]string{}, clientRoles, nil, LogConfig{}).Spec
+	if grace := desired.TerminationGracePeriodSeconds; grace == nil || *grace != 75 {
+		t.Errorf("Exp. the delay of the node to override the common spec but was %v", grace)
+	}
+	if got := pod.DiffPodSpec(podSpec, desired, true); !reflect.DeepEqual(got, []string{"terminationGracePeriodSeconds", "containers[elasticsearch].lifecycle"}) {
+		t.Errorf("Exp. a changed delay to roll the node but got %v", got)
+	}
+
+	podSpec = newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, commonSpec, map[string]string{}, clientRoles, nil, LogConfig{}).Spec
+	if lifecycle := podSpec.Containers[0].Lifecycle; lifecycle != nil {
+		t.Errorf("Exp. a zero delay to disable it but got %v", lifecycle)
+	}
+}
+
+func TestPodAnnotations(t *testing.T) {
+	node := api.ElasticsearchNode{
+		PodAnnotations: map[string]string{
+			"prometheus.io/scrape": "true",
+			"prometheus.io/port":   "60001",
+		},
+	}
+
+	template := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", node, api.ElasticsearchNodeSpec{}, map[string]string{}, NodeRoles{}, nil, LogConfig{})
+	if !reflect.DeepEqual(template.Annotations, node.PodAnnotations) {
+		t.Errorf("Exp. the pod annotations %v but got %v", node.PodAnnotations, template.Annotations)
+	}
+
+	template.Annotations["prometheus.io/port"] = "9200"
+	if node.PodAnnotations["prometheus.io/port"] != "60001" {
+		t.Error("Exp. the pod annotations to be copied from the node spec")
+	}
+}
+
+func TestChownDataVolumeInitContainer(t *testing.T) {
+	size := resource.MustParse("10G")
+	persistent := api.ElasticsearchNode{
+		Storage: api.ElasticsearchStorageSpec{Size: &size},
+	}
+	uid := int64(1001)
+	commonSpec := api.ElasticsearchNodeSpec{
+		ChownDataVolume: true,
+		SecurityContext: &api.ElasticsearchSecurityContext{RunAsUser: &uid},
+	}
+
+	template := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", persistent, commonSpec, map[string]string{}, NodeRoles{}, fake.NewFakeClient(), LogConfig{})
+	if len(template.Spec.InitContainers) != 1 {
+		t.Fatalf("Exp. a single init container but got %v", template.Spec.InitContainers)
+	}
+	initContainer := template.Spec.InitContainers[0]
+	if want := []string{"chown", "-R", "1001:1000", elasticsearchPersistentPath}; !reflect.DeepEqual(initContainer.Command, want) {
+		t.Errorf("Exp. the command %v but got %v", want, initContainer.Command)
+	}
+	if initContainer.SecurityContext == nil || *initContainer.SecurityContext.RunAsUser != 0 {
+		t.Errorf("Exp. the init container to run as root but got %v", initContainer.SecurityContext)
+	}
+
+	template = newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, commonSpec, map[string]string{}, NodeRoles{}, nil, LogConfig{})
+	if len(template.Spec.InitContainers) != 0 {
+		t.Errorf("Exp. no init container for ephemeral storage but got %v", template.Spec.InitContainers)
+	}
+
+	commonSpec.ChownDataVolume = false
+	template = newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", persistent, commonSpec, map[string]string{}, NodeRoles{}, fake.NewFakeClient(), LogConfig{})
+	if len(template.Spec.InitContainers) != 0 {
+		t.Errorf("Exp. no init container unless requested but got %v", template.Spec.InitContainers)
+	}
+}
+
+func TestPodDataVolumeMode(t *testing.T) {
+	size := resource.MustParse("10G")
+	node := api.ElasticsearchNode{
+		Storage: api.ElasticsearchStorageSpec{Size: &size},
+	}
+
+	k8sClient := fake.NewFakeClient()
+	template := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", node, api.ElasticsearchNodeSpec{}, map[string]string{}, NodeRoles{}, k8sClient, LogConfig{})
+
+	container := template.Spec.Containers[0]
+	mounted := false
+	for _, mount := range container.VolumeMounts {
+		if mount.Name == "elasticsearch-storage" {
+			mounted = mount.MountPath == elasticsearchPersistentPath
+		}
+	}
+	if !mounted {
+		t.Errorf("Exp. the data volume to be mounted at %s but got %v", elasticsearchPersistentPath, container.VolumeMounts)
+	}
+	if len(container.VolumeDevices) != 0 {
+		t.Errorf("Exp. no volume devices but got %v", container.VolumeDevices)
+	}
+
+	pvc := &v1.PersistentVolumeClaim{}
+	key := types.NamespacedName{Name: "test-cluster-name-test-node-name", Namespace: "test-namespace-name"}
+	if err := k8sClient.Get(context.TODO(), key, pvc); err != nil {
+		t.Fatalf("failed to get pvc: %s", err)
+	}
+	if getPVCVolumeMode(pvc.Spec.VolumeMode) != v1.PersistentVolumeFilesystem {
+		t.Errorf("Exp. the pvc to request the filesystem volume mode but got %v", pvc.Spec.VolumeMode)
+	}
+	if want := []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce}; !reflect.DeepEqual(pvc.Spec.AccessModes, want) {
+		t.Errorf("Exp. the pvc to default to the access modes %v but got %v", want, pvc.Spec.AccessModes)
+	}
+}
+
+func TestESClusterNameOverride(t *testing.T) {
+	cluster := &api.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch",
+			Namespace: "openshift-logging",
+		},
+		Spec: api.ElasticsearchSpec{
+			ClusterName: "logging-prod",
+		},
+	}
+
+	node := &deploymentNode{}
+	node.populateReference("elasticsearch-cdm-1-deadbeef", api.ElasticsearchNode{}, cluster, newNodeRoles(api.ElasticsearchRoleMaster), 1, fake.NewFakeClient(), nil)
+
+	if node.self.Labels["cluster-name"] != cluster.Name {
+		t.Errorf("Exp. the resources to be labeled with the custom resource name but got %v", node.self.Labels)
+	}
+
+	env := map[string]string{}
+	for _, envVar := range node.self.Spec.Template.Spec.Containers[0].Env {
+		env[envVar.Name] = envVar.Value
+	}
+	if env["CLUSTER_NAME"] != "logging-prod" {
+		t.Errorf("Exp. the Elasticsearch cluster name to be overridden but was %q", env["CLUSTER_NAME"])
+	}
+	if env["SERVICE_DNS"] != "elasticsearch-cluster" {
+		t.Errorf("Exp. the services to be named after the custom resource but got %q", env["SERVICE_DNS"])
+	}
+
+	for name, valid := range map[string]bool{
+		"logging-prod": true,
+		"es_6.8":       true,
+		"":             false,
+		"-logging":     false,
+		"logging:prod": false,
+		"logging/prod": false,
+	} {
+		if got := isValidESClusterName(name); got != valid {
+			t.Errorf("Exp. cluster name %q to be valid %t but got %t", name, valid, got)
+		}
+	}
+}
+
+func TestPodProxyServiceAccountToken(t *testing.T) {
+	expirationSeconds := int64(7200)
+	commonSpec := api.ElasticsearchNodeSpec{
+		ProxyServiceAccountToken: &api.ElasticsearchProxyTokenSpec{
+			Audience:          "https://kubernetes.default.svc",
+			ExpirationSeconds: &expirationSeconds,
+		},
+	}
+
+	podSpec := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, commonSpec, map[string]string{}, NodeRoles{}, nil, LogConfig{}).Spec
+
+	if podSpec.AutomountServiceAccountToken != nil && !*podSpec.AutomountServiceAccountToken {
+		t.Errorf("Exp. automountServiceAccountToken to remain enabled but was %v", *podSpec.AutomountServiceAccountToken)
+	}
+
+	volume, ok := getVolume(serviceAccountTokenVolumeName, podSpec.Volumes)
+	if !ok {
+		t.Fatalf("Exp. the pod to have the projected token volume but was %v", podSpec.Volumes)
+	}
+	token := volume.Projected.Sources[0].ServiceAccountToken
+	if token == nil || token.Audience != "https://kubernetes.default.svc" || *token.ExpirationSeconds != expirationSeconds {
+		t.Errorf("Exp. a token of the configured audience and expiration but was %v", token)
+	}
+
+	tokenMount := v1.VolumeMount{Name: serviceAccountTokenVolumeName, MountPath: serviceAccountTokenPath, ReadOnly: true}
+	for _, container := range podSpec.Containers {
+		mounted := comparators.ContainsSameVolumeMounts(container.VolumeMounts, []v1.VolumeMount{tokenMount})
+		if container.Name == "proxy" && !mounted {
+			t.Errorf("Exp. the proxy container to mount the token but was %v", container.VolumeMounts)
+		}
+		if container.Name == "elasticsearch" && mounted {
+			t.Errorf("Exp. the elasticsearch container to not mount the token but was %v", container.VolumeMounts)
+		}
+	}
+}
+
+func TestPodContainersTerminationMessagePolicy(t *testing.T) {
+	commonSpec := api.ElasticsearchNodeSpec{
+		ChownDataVolume: true,
+		Plugins:         []string{"analysis-icu"},
+	}
+	template := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, commonSpec, map[string]string{}, NodeRoles{}, fake.NewFakeClient(), LogConfig{})
+
+	containers := append(template.Spec.InitContainers, template.Spec.Containers...)
+	for _, container := range containers {
+		if container.TerminationMessagePolicy != v1.TerminationMessageFallbackToLogsOnError {
+			t.Errorf("Exp. container %s to fall back to its logs for the termination message but got %q", container.Name, container.TerminationMessagePolicy)
+		}
+	}
+}
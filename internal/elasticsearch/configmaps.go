@@ -4,14 +4,21 @@ import (
 	"bytes"
 	"context"
 	"crypto/sha256"
+	"fmt"
 	"html/template"
 	"io"
+	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/ViaQ/logerr/kverrors"
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
 	"github.com/openshift/elasticsearch-operator/internal/manifests/configmap"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
 	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
@@ -26,9 +33,16 @@ type esYmlStruct struct {
 	EsUnicastHost        string
 	NodeQuorum           string
 	RecoverExpectedNodes string
+	RecoverAfterTime     string
 	SystemCallFilter     string
+	DataTiers            bool
+	IngestNodes          bool
 }
 
+// rootLoggerRegex matches the rootLogger definition of a log4j2.properties, e.g.
+// rootLogger.level = info or the short form rootLogger = info, console
+var rootLoggerRegex = regexp.MustCompile(`(?m)^\s*rootLogger(\.[A-Za-z]+)?\s*=`)
+
 type log4j2PropertiesStruct struct {
 	RootLogger       string
 	LogLevel         string
@@ -49,27 +63,58 @@ func (er *ElasticsearchRequest) CreateOrUpdateConfigMaps() error {
 		return err
 	}
 	dataNodeCount := int(GetDataCount(dpl))
-	masterNodeCount := int(getMasterCount(dpl))
+	masterNodeCount := int(er.getRenderedMasterCount())
 
 	logConfig := getLogConfig(dpl.GetAnnotations())
 
+	if invalid := getInvalidDiscoverySeedHosts(dpl); len(invalid) > 0 {
+		if err := updateCondition(dpl, api.InvalidDiscoveryHosts, invalidSpecReason, v1.ConditionTrue, invalidDiscoverySeedHostsMessage(invalid), er.client); err != nil {
+			return kverrors.Wrap(err, "failed to set discovery hosts status")
+		}
+		return kverrors.New("invalid discovery seed hosts",
+			"hosts", invalid)
+	}
+
+	if err := updateCondition(dpl, api.InvalidDiscoveryHosts, invalidSpecReason, v1.ConditionFalse, "", er.client); err != nil {
+		return kverrors.Wrap(err, "failed to set discovery hosts status")
+	}
+
 	cm := newConfigMap(
 		dpl.Name,
 		dpl.Namespace,
 		dpl.Labels,
 		kibanaIndexMode,
-		esUnicastHost(dpl.Name, dpl.Namespace),
+		esDiscoverySeedHosts(dpl),
 		strconv.Itoa(masterNodeCount/2+1),
 		strconv.Itoa(dataNodeCount),
+		recoverAfterTime(dpl),
 		strconv.Itoa(CalculatePrimaryCount(dpl)),
 		strconv.Itoa(CalculateReplicaCount(dpl)),
 		strconv.FormatBool(runtime.GOARCH == "amd64"),
+		usesDataTiers(dpl),
+		hasIngestNodes(dpl),
 		logConfig,
 	)
 
+	if dpl.Spec.Log4j2Properties != nil {
+		cm.Data[log4jConfig] = *dpl.Spec.Log4j2Properties
+	}
+
+	if err := er.checkConfigVersion(cm.Data[esConfig]); err != nil {
+		return err
+	}
+
+	cm.Annotations = utils.WithPropagatedAnnotations(cm.Annotations, propagatedAnnotations(dpl))
 	dpl.AddOwnerRefTo(cm)
 
-	updated, err := configmap.CreateOrUpdate(context.TODO(), er.client, cm, configMapContentEqual, configmap.MutateDataOnly)
+	var previousData map[string]string
+	contentChanged := true
+	if current, err := configmap.Get(context.TODO(), er.client, client.ObjectKey{Name: cm.Name, Namespace: cm.Namespace}); err == nil {
+		previousData = current.Data
+		contentChanged = !configMapContentEqual(current, cm)
+	}
+
+	updated, err := configmap.CreateOrUpdate(context.TODO(), er.client, cm, configMapContentAndAnnotationsEqual, mutateDataAndAnnotations)
 	if err != nil {
 		return kverrors.Wrap(err, "failed to create or update elasticsearch configmap",
 			"cluster", er.cluster.Name,
@@ -77,7 +122,25 @@ func (er *ElasticsearchRequest) CreateOrUpdateConfigMaps() error {
 		)
 	}
 
-	if updated {
+	if changed := changedConfigFiles(previousData, cm.Data); updated && len(changed) > 0 {
+		message := fmt.Sprintf("Changed the Elasticsearch configuration files: %s", strings.Join(changed, ", "))
+		er.L().Info(message)
+		recordEvent(er.recorder, dpl, v1.EventTypeNormal, eventReasonConfigChanged, message)
+	}
+
+	hash := configHash(configmap.DataSHA256(cm.Data, excludeConfigMapKeys))
+	if err := updateConditionWithRetry(dpl, v1.ConditionTrue, func(status *api.ElasticsearchStatus, _ v1.ConditionStatus) bool {
+		if status.ConfigHash == hash {
+			return false
+		}
+		status.ConfigHash = hash
+		return true
+	}, er.client); err != nil {
+		return err
+	}
+
+	// Updates of the propagated annotations only leave the settings unchanged
+	if updated && contentChanged {
 		// Cluster settings has changed, make sure it doesnt go unnoticed
 		if err := updateConditionWithRetry(dpl, v1.ConditionTrue, updateUpdatingSettingsCondition, er.client); err != nil {
 			return err
@@ -91,10 +154,10 @@ func (er *ElasticsearchRequest) CreateOrUpdateConfigMaps() error {
 	return nil
 }
 
-func renderData(kibanaIndexMode, esUnicastHost, nodeQuorum, recoverExpectedNodes, primaryShardsCount, replicaShardsCount, systemCallFilter string, logConfig LogConfig) (map[string]string, error) {
+func renderData(kibanaIndexMode, esUnicastHost, nodeQuorum, recoverExpectedNodes, recoverAfterTime, primaryShardsCount, replicaShardsCount, systemCallFilter string, dataTiers, ingestNodes bool, logConfig LogConfig) (map[string]string, error) {
 	data := map[string]string{}
 	buf := &bytes.Buffer{}
-	if err := renderEsYml(buf, kibanaIndexMode, esUnicastHost, nodeQuorum, recoverExpectedNodes, systemCallFilter); err != nil {
+	if err := renderEsYml(buf, kibanaIndexMode, esUnicastHost, nodeQuorum, recoverExpectedNodes, recoverAfterTime, systemCallFilter, dataTiers, ingestNodes); err != nil {
 		return data, err
 	}
 	data[esConfig] = buf.String()
@@ -116,8 +179,8 @@ func renderData(kibanaIndexMode, esUnicastHost, nodeQuorum, recoverExpectedNodes
 
 // newConfigMap returns a v1.ConfigMap object
 func newConfigMap(configMapName, namespace string, labels map[string]string,
-	kibanaIndexMode, esUnicastHost, nodeQuorum, recoverExpectedNodes, primaryShardsCount, replicaShardsCount, systemCallFilter string, logConfig LogConfig) *v1.ConfigMap {
-	data, err := renderData(kibanaIndexMode, esUnicastHost, nodeQuorum, recoverExpectedNodes, primaryShardsCount, replicaShardsCount, systemCallFilter, logConfig)
+	kibanaIndexMode, esUnicastHost, nodeQuorum, recoverExpectedNodes, recoverAfterTime, primaryShardsCount, replicaShardsCount, systemCallFilter string, dataTiers, ingestNodes bool, logConfig LogConfig) *v1.ConfigMap {
+	data, err := renderData(kibanaIndexMode, esUnicastHost, nodeQuorum, recoverExpectedNodes, recoverAfterTime, primaryShardsCount, replicaShardsCount, systemCallFilter, dataTiers, ingestNodes, logConfig)
 	if err != nil {
 		return nil
 	}
@@ -150,7 +213,20 @@ func configMapContentEqual(old, new *v1.ConfigMap) bool {
 	return true
 }
 
-func renderEsYml(w io.Writer, kibanaIndexMode, esUnicastHost, nodeQuorum, recoverExpectedNodes, systemCallFilter string) error {
+// configMapContentAndAnnotationsEqual compares the content of the configmaps and their
+// propagated annotations
+func configMapContentAndAnnotationsEqual(current, desired *v1.ConfigMap) bool {
+	return configMapContentEqual(current, desired) && utils.ContainsPropagatedAnnotations(current.Annotations, desired.Annotations)
+}
+
+// mutateDataAndAnnotations copies the data and the propagated annotations of the desired
+// configmap to the current one
+func mutateDataAndAnnotations(current, desired *v1.ConfigMap) {
+	configmap.MutateDataOnly(current, desired)
+	current.Annotations = utils.MergePropagatedAnnotations(current.Annotations, desired.Annotations)
+}
+
+func renderEsYml(w io.Writer, kibanaIndexMode, esUnicastHost, nodeQuorum, recoverExpectedNodes, recoverAfterTime, systemCallFilter string, dataTiers, ingestNodes bool) error {
 	t := template.New("elasticsearch.yml")
 	config := esYmlTmpl
 	t, err := t.Parse(config)
@@ -162,7 +238,10 @@ func renderEsYml(w io.Writer, kibanaIndexMode, esUnicastHost, nodeQuorum, recove
 		EsUnicastHost:        esUnicastHost,
 		NodeQuorum:           nodeQuorum,
 		RecoverExpectedNodes: recoverExpectedNodes,
+		RecoverAfterTime:     recoverAfterTime,
 		SystemCallFilter:     systemCallFilter,
+		DataTiers:            dataTiers,
+		IngestNodes:          ingestNodes,
 	}
 
 	return t.Execute(w, esy)
@@ -184,6 +263,12 @@ func renderLog4j2Properties(w io.Writer, logConfig LogConfig) error {
 	return t.Execute(w, log4jProp)
 }
 
+// isValidLog4j2Properties returns whether the custom log4j2.properties are non-empty
+// and define the rootLogger, without which Elasticsearch does not log at all
+func isValidLog4j2Properties(properties string) bool {
+	return strings.TrimSpace(properties) != "" && rootLoggerRegex.MatchString(properties)
+}
+
 func renderIndexSettings(w io.Writer, primaryShardsCount, replicaShardsCount string) error {
 	t := template.New("index_settings")
 	t, err := t.Parse(indexSettingsTmpl)
@@ -198,3 +283,34 @@ func renderIndexSettings(w io.Writer, primaryShardsCount, replicaShardsCount str
 
 	return t.Execute(w, indexSettings)
 }
+
+func hasJvmOptions(node api.ElasticsearchNode) bool {
+	return node.JvmOptions != nil && len(node.JvmOptions.Options) > 0
+}
+
+// configHash returns a short printable form of the configmap data hash driving the node restarts
+func configHash(dataHash string) string {
+	if dataHash == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(dataHash))
+	return fmt.Sprintf("%x", sum[:8])
+}
+
+// changedConfigFiles returns the sorted keys of the configuration files which were added,
+// changed or removed
+func changedConfigFiles(previous, current map[string]string) []string {
+	changed := []string{}
+	for key, value := range current {
+		if old, ok := previous[key]; !ok || old != value {
+			changed = append(changed, key)
+		}
+	}
+	for key := range previous {
+		if _, ok := current[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
@@ -2,11 +2,20 @@ package elasticsearch
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 
+	"github.com/ViaQ/logerr/log"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
 	"github.com/openshift/elasticsearch-operator/test/helpers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 var _ = Describe("configmaps.go", func() {
@@ -111,7 +120,7 @@ var _ = Describe("configmaps", func() {
 	Describe("#renderEsYml", func() {
 		It("should produce an elasticsearch.yml for our managed elasticsearch instance", func() {
 			result := &bytes.Buffer{}
-			Expect(renderEsYml(result, "", "my.unicast.host", "7", "4", "false")).To(BeNil(), "Exp. no errors when rendering the configuration")
+			Expect(renderEsYml(result, "", "my.unicast.host", "7", "4", "10m", "false", false, false)).To(BeNil(), "Exp. no errors when rendering the configuration")
 			helpers.ExpectYaml(result.String()).ToEqual(`
 cluster:
   name: ${CLUSTER_NAME}
@@ -138,7 +147,7 @@ discovery.zen:
 gateway:
   recover_after_nodes: 7
   expected_nodes: 4
-  recover_after_time: ${RECOVER_AFTER_TIME}
+  recover_after_time: 10m
 
 path:
   data: /elasticsearch/persistent/${CLUSTER_NAME}/data
@@ -177,5 +186,108 @@ opendistro_security:
       truststore_filepath: /etc/elasticsearch/secret/truststore.p12
       truststore_password: tspass`)
 		})
+
+		It("should template the data attribute of the nodes if the cluster uses data tiers", func() {
+			result := &bytes.Buffer{}
+			Expect(renderEsYml(result, "", "my.unicast.host", "7", "4", "10m", "false", true, false)).To(BeNil(), "Exp. no errors when rendering the configuration")
+			Expect(result.String()).To(ContainSubstring("  max_local_storage_nodes: 1\n  attr.data: ${NODE_ATTR_DATA}\n"))
+		})
+
+		It("should template the ingest role of the nodes only if the cluster has dedicated ingest nodes", func() {
+			result := &bytes.Buffer{}
+			Expect(renderEsYml(result, "", "my.unicast.host", "7", "4", "10m", "false", false, true)).To(BeNil(), "Exp. no errors when rendering the configuration")
+			Expect(result.String()).To(ContainSubstring("  max_local_storage_nodes: 1\n  ingest: ${IS_INGEST}\n"))
+
+			result = &bytes.Buffer{}
+			Expect(renderEsYml(result, "", "my.unicast.host", "7", "4", "10m", "false", false, false)).To(BeNil(), "Exp. no errors when rendering the configuration")
+			Expect(result.String()).NotTo(ContainSubstring("ingest"))
+		})
+	})
+
+	Describe("#renderIndexSettings", func() {
+		It("should render the primary and replica shards", func() {
+			result := &bytes.Buffer{}
+			Expect(renderIndexSettings(result, "3", "1")).To(BeNil(), "Exp. no errors when rendering the index settings")
+			Expect(result.String()).To(Equal(`
+PRIMARY_SHARDS=3
+REPLICA_SHARDS=1
+`))
+		})
+	})
+
+	Describe("#isValidLog4j2Properties", func() {
+		It("should accept properties defining the rootLogger", func() {
+			Expect(isValidLog4j2Properties("status = error\nrootLogger.level = info\nrootLogger.appenderRef.console.ref = console\n")).To(BeTrue())
+			Expect(isValidLog4j2Properties("rootLogger = info, console")).To(BeTrue())
+		})
+		It("should reject empty properties", func() {
+			Expect(isValidLog4j2Properties(" \n")).To(BeFalse())
+		})
+		It("should reject properties without rootLogger", func() {
+			Expect(isValidLog4j2Properties("status = error\nlogger.action.level = debug\n")).To(BeFalse())
+		})
+	})
+
+	Describe("#changedConfigFiles", func() {
+		It("should list the added, changed and removed files sorted", func() {
+			previous := map[string]string{esConfig: "a", log4jConfig: "b", "removed": "c"}
+			current := map[string]string{esConfig: "a", log4jConfig: "changed", indexSettingsConfig: "d"}
+			Expect(changedConfigFiles(previous, current)).To(Equal([]string{indexSettingsConfig, log4jConfig, "removed"}))
+		})
+		It("should list nothing for unchanged files", func() {
+			files := map[string]string{esConfig: "a"}
+			Expect(changedConfigFiles(files, files)).To(BeEmpty())
+		})
+	})
+
+	Describe("#CreateOrUpdateConfigMaps", func() {
+		It("should report the config hash and the changed files", func() {
+			_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+			cluster := &loggingv1.Elasticsearch{
+				ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch", Namespace: "openshift-logging"},
+			}
+			recorder := record.NewFakeRecorder(10)
+			er := &ElasticsearchRequest{
+				cluster:  cluster,
+				client:   fake.NewFakeClient(cluster.DeepCopy()),
+				recorder: recorder,
+				ll:       log.WithValues(),
+			}
+
+			Expect(er.CreateOrUpdateConfigMaps()).To(Succeed())
+			hash := cluster.Status.ConfigHash
+			Expect(hash).ToNot(BeEmpty())
+			Expect(recorder.Events).To(BeEmpty(), "Exp. no change to be reported when creating the configmap")
+
+			log4j2Properties := "status = error\nrootLogger.level = info\n"
+			cluster.Spec.Log4j2Properties = &log4j2Properties
+			Expect(er.CreateOrUpdateConfigMaps()).To(Succeed())
+			Expect(cluster.Status.ConfigHash).ToNot(Equal(hash))
+			Expect(recorder.Events).To(HaveLen(1))
+			Expect(<-recorder.Events).To(And(ContainSubstring(eventReasonConfigChanged), ContainSubstring(log4jConfig)))
+		})
+		It("should propagate annotations of the cluster without changing the settings", func() {
+			_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+			cluster := &loggingv1.Elasticsearch{
+				ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch", Namespace: "openshift-logging"},
+			}
+			k8sClient := fake.NewFakeClient(cluster.DeepCopy())
+			er := &ElasticsearchRequest{
+				cluster:  cluster,
+				client:   k8sClient,
+				recorder: record.NewFakeRecorder(10),
+				ll:       log.WithValues(),
+			}
+			Expect(er.CreateOrUpdateConfigMaps()).To(Succeed())
+
+			cluster.Annotations = map[string]string{"cost-center": "1234"}
+			cluster.Spec.PropagatedAnnotations = []string{"cost-center"}
+			Expect(er.CreateOrUpdateConfigMaps()).To(Succeed())
+			Expect(containsClusterCondition(loggingv1.UpdatingSettings, corev1.ConditionTrue, &cluster.Status)).To(BeFalse())
+
+			cm := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Name: cluster.Name, Namespace: cluster.Namespace}, cm)).To(Succeed())
+			Expect(cm.Annotations).To(HaveKeyWithValue("cost-center", "1234"))
+		})
 	})
 })
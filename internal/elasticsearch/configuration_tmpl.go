@@ -12,6 +12,12 @@ node:
   master: ${IS_MASTER}
   data: ${HAS_DATA}
   max_local_storage_nodes: 1
+{{- if .IngestNodes}}
+  ingest: ${IS_INGEST}
+{{- end}}
+{{- if .DataTiers}}
+  attr.data: ${NODE_ATTR_DATA}
+{{- end}}
 
 action.auto_create_index: "-*-write,+*"
 
@@ -26,7 +32,7 @@ discovery.zen:
 gateway:
   recover_after_nodes: {{.NodeQuorum}}
   expected_nodes: {{.RecoverExpectedNodes}}
-  recover_after_time: ${RECOVER_AFTER_TIME}
+  recover_after_time: {{.RecoverAfterTime}}
 
 path:
   data: /elasticsearch/persistent/${CLUSTER_NAME}/data
@@ -0,0 +1,179 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// noDataTier is the data attribute of the nodes without a tier in a cluster using data
+// tiers, e.g. master only nodes, which matches no index allocation filter
+const noDataTier = "none"
+
+// dataTierEnvVar is templated into the node.attr.data setting of elasticsearch.yml
+const dataTierEnvVar = "NODE_ATTR_DATA"
+
+var dataTiers = []api.ElasticsearchDataTier{
+	api.ElasticsearchDataTierHot,
+	api.ElasticsearchDataTierWarm,
+	api.ElasticsearchDataTierCold,
+}
+
+func isValidDataTier(tier api.ElasticsearchDataTier) bool {
+	for _, t := range dataTiers {
+		if t == tier {
+			return true
+		}
+	}
+	return false
+}
+
+// usesDataTiers returns true if any node group of the cluster declares a data tier
+func usesDataTiers(dpl *api.Elasticsearch) bool {
+	for _, node := range dpl.Spec.Nodes {
+		if node.DataTier != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// getNodeDataTier returns the data attribute of the nodes of the group or an empty
+// string if the cluster does not use data tiers
+func getNodeDataTier(dpl *api.Elasticsearch, node api.ElasticsearchNode) string {
+	if node.DataTier != "" {
+		return string(node.DataTier)
+	}
+	if usesDataTiers(dpl) {
+		return noDataTier
+	}
+	return ""
+}
+
+// setDataTier sets the data attribute of the Elasticsearch container, leaving the
+// template unchanged if the cluster does not use data tiers
+func setDataTier(template *v1.PodTemplateSpec, tier string) {
+	if tier == "" {
+		return
+	}
+
+	for i := range template.Spec.Containers {
+		container := &template.Spec.Containers[i]
+		if container.Name != "elasticsearch" {
+			continue
+		}
+
+		found := false
+		for j := range container.Env {
+			if container.Env[j].Name == dataTierEnvVar {
+				container.Env[j].Value = tier
+				found = true
+			}
+		}
+		if !found {
+			container.Env = append(container.Env, v1.EnvVar{Name: dataTierEnvVar, Value: tier})
+		}
+	}
+}
+
+// getInvalidDataTiers returns the node groups and index tiers breaking the data tiering
+// of the cluster, i.e. unknown tiers, tiers of non data nodes, data nodes lacking a tier,
+// index tiers without data nodes and index patterns overlapping those of previous tiers
+func getInvalidDataTiers(dpl *api.Elasticsearch) []string {
+	invalid := []string{}
+	tiered := usesDataTiers(dpl)
+	declared := map[api.ElasticsearchDataTier]bool{}
+
+	for i, node := range dpl.Spec.Nodes {
+		isData := getNodeRoles(node).IsData()
+		switch {
+		case node.DataTier != "" && !isValidDataTier(node.DataTier):
+			invalid = append(invalid, fmt.Sprintf("nodes[%d] (unknown tier %q)", i, node.DataTier))
+		case node.DataTier != "" && !isData:
+			invalid = append(invalid, fmt.Sprintf("nodes[%d] (tier of non data nodes)", i))
+		case node.DataTier == "" && isData && tiered:
+			invalid = append(invalid, fmt.Sprintf("nodes[%d] (data nodes without tier)", i))
+		case node.DataTier != "" && node.NodeCount > 0:
+			declared[node.DataTier] = true
+		}
+	}
+
+	for i, index := range dpl.Spec.IndexTiers {
+		switch {
+		case index.IndexPattern == "":
+			invalid = append(invalid, fmt.Sprintf("indexTiers[%d] (empty index pattern)", i))
+		case !isValidDataTier(index.Tier):
+			invalid = append(invalid, fmt.Sprintf("indexTiers[%d] (unknown tier %q)", i, index.Tier))
+		case !declared[index.Tier]:
+			invalid = append(invalid, fmt.Sprintf("indexTiers[%d] (no data nodes of tier %s)", i, index.Tier))
+		default:
+			// indices matched by two tiers would be moved back and forth every reconcile
+			for j, previous := range dpl.Spec.IndexTiers[:i] {
+				if previous.IndexPattern != "" && indexPatternsOverlap(previous.IndexPattern, index.IndexPattern) {
+					invalid = append(invalid, fmt.Sprintf("indexTiers[%d] (index pattern overlapping indexTiers[%d])", i, j))
+					break
+				}
+			}
+		}
+	}
+
+	return invalid
+}
+
+// indexPatternsOverlap returns true if an index name can match both patterns, * matching
+// any sequence of characters
+func indexPatternsOverlap(a, b string) bool {
+	switch {
+	case a == "" && b == "":
+		return true
+	case strings.HasPrefix(a, "*"):
+		return indexPatternsOverlap(a[1:], b) || (b != "" && indexPatternsOverlap(a, b[1:]))
+	case strings.HasPrefix(b, "*"):
+		return indexPatternsOverlap(a, b[1:]) || (a != "" && indexPatternsOverlap(a[1:], b))
+	case a == "" || b == "":
+		return false
+	default:
+		return a[0] == b[0] && indexPatternsOverlap(a[1:], b[1:])
+	}
+}
+
+// CreateOrUpdateIndexTiers allocates the shards of the indices matching the index tiers
+// of the spec to the nodes of their tier, updating those pinned to a different one
+func (er *ElasticsearchRequest) CreateOrUpdateIndexTiers() error {
+	dpl := er.cluster
+
+	if len(dpl.Spec.IndexTiers) == 0 || !er.AnyNodeReady() {
+		return nil
+	}
+
+	for _, index := range dpl.Spec.IndexTiers {
+		current, err := er.esClient.GetIndexDataTiers(index.IndexPattern)
+		if err != nil {
+			return err
+		}
+
+		drifted := []string{}
+		for name, tier := range current {
+			if tier != string(index.Tier) {
+				drifted = append(drifted, name)
+			}
+		}
+		if len(drifted) == 0 {
+			continue
+		}
+		sort.Strings(drifted)
+
+		if err := er.esClient.SetIndexDataTier(strings.Join(drifted, ","), string(index.Tier)); err != nil {
+			return err
+		}
+		er.L().Info("Allocated indices to data tier",
+			"pattern", index.IndexPattern,
+			"tier", index.Tier,
+			"indices", drifted)
+	}
+
+	return nil
+}
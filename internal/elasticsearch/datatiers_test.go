@@ -0,0 +1,258 @@
+package elasticsearch
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/test/helpers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetInvalidDataTiers(t *testing.T) {
+	data := []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleData}
+	master := []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleMaster}
+
+	tests := []struct {
+		desc    string
+		nodes   []loggingv1.ElasticsearchNode
+		indices []loggingv1.ElasticsearchIndexTier
+		invalid []string
+	}{
+		{
+			desc: "no tiers",
+			nodes: []loggingv1.ElasticsearchNode{
+				{Roles: data, NodeCount: 1},
+			},
+		},
+		{
+			desc: "tiered data nodes",
+			nodes: []loggingv1.ElasticsearchNode{
+				{Roles: master, NodeCount: 3},
+				{Roles: data, NodeCount: 2, DataTier: loggingv1.ElasticsearchDataTierHot},
+				{Roles: data, NodeCount: 1, DataTier: loggingv1.ElasticsearchDataTierWarm},
+			},
+			indices: []loggingv1.ElasticsearchIndexTier{
+				{IndexPattern: "app-*", Tier: loggingv1.ElasticsearchDataTierWarm},
+			},
+		},
+		{
+			desc: "unknown tier",
+			nodes: []loggingv1.ElasticsearchNode{
+				{Roles: data, NodeCount: 1, DataTier: "frozen"},
+			},
+			invalid: []string{"nodes[0]"},
+		},
+		{
+			desc: "tier of master nodes",
+			nodes: []loggingv1.ElasticsearchNode{
+				{Roles: master, NodeCount: 3, DataTier: loggingv1.ElasticsearchDataTierHot},
+				{Roles: data, NodeCount: 1, DataTier: loggingv1.ElasticsearchDataTierHot},
+			},
+			invalid: []string{"nodes[0]"},
+		},
+		{
+			desc: "data nodes without tier",
+			nodes: []loggingv1.ElasticsearchNode{
+				{Roles: data, NodeCount: 1, DataTier: loggingv1.ElasticsearchDataTierHot},
+				{Roles: data, NodeCount: 1},
+			},
+			invalid: []string{"nodes[1]"},
+		},
+		{
+			desc: "index tier without data nodes",
+			nodes: []loggingv1.ElasticsearchNode{
+				{Roles: data, NodeCount: 1, DataTier: loggingv1.ElasticsearchDataTierHot},
+			},
+			indices: []loggingv1.ElasticsearchIndexTier{
+				{IndexPattern: "app-*", Tier: loggingv1.ElasticsearchDataTierCold},
+				{Tier: loggingv1.ElasticsearchDataTierHot},
+			},
+			invalid: []string{"indexTiers[0]", "indexTiers[1]"},
+		},
+		{
+			desc: "overlapping index patterns",
+			nodes: []loggingv1.ElasticsearchNode{
+				{Roles: data, NodeCount: 1, DataTier: loggingv1.ElasticsearchDataTierHot},
+				{Roles: data, NodeCount: 1, DataTier: loggingv1.ElasticsearchDataTierWarm},
+			},
+			indices: []loggingv1.ElasticsearchIndexTier{
+				{IndexPattern: "app-*", Tier: loggingv1.ElasticsearchDataTierHot},
+				{IndexPattern: "infra-*", Tier: loggingv1.ElasticsearchDataTierWarm},
+				{IndexPattern: "*-2021.*", Tier: loggingv1.ElasticsearchDataTierWarm},
+				{IndexPattern: "audit", Tier: loggingv1.ElasticsearchDataTierWarm},
+			},
+			invalid: []string{"indexTiers[2]"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			dpl := &loggingv1.Elasticsearch{
+				Spec: loggingv1.ElasticsearchSpec{
+					Nodes:      test.nodes,
+					IndexTiers: test.indices,
+				},
+			}
+
+			invalid := getInvalidDataTiers(dpl)
+			if len(invalid) != len(test.invalid) {
+				t.Fatalf("Exp. %v to be invalid but got %v", test.invalid, invalid)
+			}
+			for i, prefix := range test.invalid {
+				if !strings.HasPrefix(invalid[i], prefix) {
+					t.Errorf("Exp. %s to be invalid but got %s", prefix, invalid[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIndexPatternsOverlap(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		overlap bool
+	}{
+		{a: "app-*", b: "app-*", overlap: true},
+		{a: "app-*", b: "app-2021*", overlap: true},
+		{a: "app-*", b: "*-2021", overlap: true},
+		{a: "app-*", b: "infra-*", overlap: false},
+		{a: "*-write", b: "*-read", overlap: false},
+		{a: "*", b: "audit", overlap: true},
+		{a: "audit", b: "audit-000001", overlap: false},
+	}
+
+	for _, test := range tests {
+		if got := indexPatternsOverlap(test.a, test.b); got != test.overlap {
+			t.Errorf("Exp. %q and %q to overlap: %t but got %t", test.a, test.b, test.overlap, got)
+		}
+		if got := indexPatternsOverlap(test.b, test.a); got != test.overlap {
+			t.Errorf("Exp. %q and %q to overlap: %t but got %t", test.b, test.a, test.overlap, got)
+		}
+	}
+}
+
+func TestSetDataTier(t *testing.T) {
+	hot := loggingv1.ElasticsearchNode{
+		Roles:    []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleData},
+		DataTier: loggingv1.ElasticsearchDataTierHot,
+	}
+	master := loggingv1.ElasticsearchNode{
+		Roles: []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleMaster},
+	}
+
+	untiered := &loggingv1.Elasticsearch{
+		Spec: loggingv1.ElasticsearchSpec{Nodes: []loggingv1.ElasticsearchNode{master}},
+	}
+	tiered := &loggingv1.Elasticsearch{
+		Spec: loggingv1.ElasticsearchSpec{Nodes: []loggingv1.ElasticsearchNode{master, hot}},
+	}
+
+	for _, test := range []struct {
+		desc    string
+		cluster *loggingv1.Elasticsearch
+		node    loggingv1.ElasticsearchNode
+		want    string
+	}{
+		{desc: "untiered cluster", cluster: untiered, node: master, want: ""},
+		{desc: "tiered node", cluster: tiered, node: hot, want: "hot"},
+		{desc: "untiered node of tiered cluster", cluster: tiered, node: master, want: noDataTier},
+	} {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			template := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", test.node, loggingv1.ElasticsearchNodeSpec{}, map[string]string{}, NodeRoles{}, nil, LogConfig{})
+			setDataTier(&template, getNodeDataTier(test.cluster, test.node))
+
+			got, found := "", false
+			for _, envVar := range template.Spec.Containers[0].Env {
+				if envVar.Name == dataTierEnvVar {
+					got, found = envVar.Value, true
+				}
+			}
+			if found != (test.want != "") || got != test.want {
+				t.Errorf("Exp. data attribute %q but got %q (set %t)", test.want, got, found)
+			}
+		})
+	}
+}
+
+func TestCreateOrUpdateIndexTiers(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+
+	const (
+		esCluster   = "elasticsearch"
+		esNamespace = "openshift-logging"
+	)
+
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      esCluster,
+			Namespace: esNamespace,
+		},
+		Spec: loggingv1.ElasticsearchSpec{
+			IndexTiers: []loggingv1.ElasticsearchIndexTier{
+				{IndexPattern: "app-*", Tier: loggingv1.ElasticsearchDataTierWarm},
+				{IndexPattern: "infra-*", Tier: loggingv1.ElasticsearchDataTierHot},
+			},
+		},
+	}
+
+	readyPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch-cdm-1",
+			Namespace: esNamespace,
+			Labels: map[string]string{
+				"component":      "elasticsearch",
+				"cluster-name":   esCluster,
+				"es-node-client": "true",
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{Ready: true}},
+		},
+	}
+
+	k8sClient := fake.NewFakeClient(cluster, readyPod)
+
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"app-*/_settings/index.routing.allocation.require.data?flat_settings=true": {
+			{StatusCode: http.StatusOK, Body: `{"app-000001":{"settings":{}},"app-000002":{"settings":{"index.routing.allocation.require.data":"hot"}},"app-000003":{"settings":{"index.routing.allocation.require.data":"warm"}}}`},
+		},
+		"app-000001,app-000002/_settings": {
+			{StatusCode: http.StatusOK, Body: `{"acknowledged": true}`},
+		},
+		"infra-*/_settings/index.routing.allocation.require.data?flat_settings=true": {
+			{StatusCode: http.StatusOK, Body: `{"infra-000001":{"settings":{"index.routing.allocation.require.data":"hot"}}}`},
+		},
+	})
+
+	er := &ElasticsearchRequest{
+		cluster:  cluster,
+		client:   k8sClient,
+		esClient: helpers.NewFakeElasticsearchClient(esCluster, esNamespace, k8sClient, chatter),
+	}
+
+	if err := er.CreateOrUpdateIndexTiers(); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+
+	req, ok := chatter.GetRequest("app-000001,app-000002/_settings")
+	if !ok {
+		t.Fatal("Exp. the drifted app indices to be allocated to the warm tier")
+	}
+	if req.Method != http.MethodPut || req.Body != `{"index.routing.allocation.require.data":"warm"}` {
+		t.Errorf("Exp. a PUT of the warm tier but got %s %s", req.Method, req.Body)
+	}
+
+	for uri := range chatter.Requests {
+		if strings.HasPrefix(uri, "infra-") && strings.HasSuffix(uri, "/_settings") {
+			t.Errorf("Exp. the infra indices not to be updated but got %s", uri)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"strings"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// debugUnpauseAnnotation keeps the deployments of the comma separated nodes it is set to
+// unpaused, so that manual edits take effect immediately while debugging them. The drift
+// of their pod template from the spec is neither detected nor reverted meanwhile.
+const debugUnpauseAnnotation = "elasticsearch.openshift.io/debug-unpause"
+
+// debuggingReason is the reason of the Debugging condition
+const debuggingReason = "Debug Unpause"
+
+// getDebugUnpausedNodes returns the names of the nodes the cluster keeps unpaused for debugging
+func getDebugUnpausedNodes(cluster *api.Elasticsearch) sets.String {
+	names := sets.NewString()
+	for _, name := range strings.Split(cluster.Annotations[debugUnpauseAnnotation], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names.Insert(name)
+		}
+	}
+	return names
+}
+
+// updateDebugging sets the Debugging condition listing the node deployments kept unpaused
+// for debugging, or clears the condition if none is
+func (er *ElasticsearchRequest) updateDebugging() {
+	cluster := er.cluster
+
+	debugged := []string{}
+	for _, node := range nodes[nodeMapKey(cluster.Name, cluster.Namespace)] {
+		if dplNode, ok := node.(*deploymentNode); ok && dplNode.debugging {
+			debugged = append(debugged, node.name())
+		}
+	}
+	if unknown := getDebugUnpausedNodes(cluster).Difference(sets.NewString(debugged...)); unknown.Len() > 0 {
+		er.L().Info("Ignoring debug unpause of unknown or statefulset nodes", "nodes", unknown.List())
+	}
+
+	if len(debugged) == 0 {
+		if err := updateCondition(cluster, api.Debugging, debuggingReason, v1.ConditionFalse, "", er.client); err != nil {
+			er.L().Error(err, "Unable to clear debugging condition")
+		}
+		return
+	}
+
+	message := fmt.Sprintf("Nodes are kept unpaused for debugging by %s, their drift from the spec is neither detected nor reverted until it is removed: %s", debugUnpauseAnnotation, strings.Join(sets.NewString(debugged...).List(), ", "))
+	if err := updateCondition(cluster, api.Debugging, debuggingReason, v1.ConditionTrue, message, er.client); err != nil {
+		er.L().Error(err, "Unable to set debugging condition")
+	}
+}
@@ -0,0 +1,132 @@
+package elasticsearch
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetDebugUnpausedNodes(t *testing.T) {
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{debugUnpauseAnnotation: "elasticsearch-cdm-1-deadbeef, elasticsearch-cdm-2-deadbeef,"},
+		},
+	}
+
+	got := getDebugUnpausedNodes(cluster).List()
+	if strings.Join(got, ",") != "elasticsearch-cdm-1-deadbeef,elasticsearch-cdm-2-deadbeef" {
+		t.Errorf("Exp. both nodes to be debugged but got %v", got)
+	}
+
+	cluster.Annotations = nil
+	if got := getDebugUnpausedNodes(cluster); got.Len() != 0 {
+		t.Errorf("Exp. no nodes debugged without the annotation but got %v", got.List())
+	}
+}
+
+func TestDebugUnpausedNodeIsNeitherPausedNorUpdated(t *testing.T) {
+	template := corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "elasticsearch", Image: "newImage"}}},
+	}
+	edited := template.DeepCopy()
+	edited.Spec.Containers[0].Image = "debugImage"
+
+	key := client.ObjectKey{Name: "elasticsearch-cdm-1-deadbeef", Namespace: "openshift-logging"}
+	existing := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		Spec:       appsv1.DeploymentSpec{Template: *edited},
+	}
+	c := &countingUpdateClient{Client: fake.NewFakeClient(existing)}
+
+	node := &deploymentNode{
+		client:      c,
+		recorder:    record.NewFakeRecorder(2),
+		desiredHash: podTemplateHash(template),
+		appliedHash: "previous",
+		debugging:   true,
+		self: appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Spec:       appsv1.DeploymentSpec{Template: template},
+		},
+	}
+
+	if repaused, err := node.repause(); err != nil || repaused {
+		t.Errorf("Exp. the debugged node not to be paused again but got %t, %v", repaused, err)
+	}
+	if err := node.pause(); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+	if err := node.progressNodeChanges(); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+	if node.state().UpgradeStatus.ScheduledForUpgrade == corev1.ConditionTrue {
+		t.Errorf("Exp. the drift of the debugged node not to schedule an upgrade")
+	}
+	if c.updates != 0 {
+		t.Errorf("Exp. no updates of the debugged node but got %d", c.updates)
+	}
+
+	// clearing the annotation resumes the paused management
+	node.debugging = false
+	if repaused, err := node.repause(); err != nil || !repaused {
+		t.Errorf("Exp. the node to be paused again but got %t, %v", repaused, err)
+	}
+	if node.state().UpgradeStatus.ScheduledForUpgrade != corev1.ConditionTrue {
+		t.Errorf("Exp. the drift of the node to schedule an upgrade")
+	}
+}
+
+func TestUpdateDebugging(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "debugged",
+			Namespace: "openshift-logging",
+		},
+	}
+	k8sClient := fake.NewFakeClient(cluster)
+
+	key := nodeMapKey(cluster.Name, cluster.Namespace)
+	node := &deploymentNode{
+		self:      appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "debugged-cdm-1-deadbeef"}},
+		debugging: true,
+	}
+	nodes[key] = []NodeTypeInterface{node}
+	defer delete(nodes, key)
+
+	er := &ElasticsearchRequest{
+		cluster: cluster,
+		client:  k8sClient,
+	}
+
+	getCondition := func() *loggingv1.ClusterCondition {
+		current := &loggingv1.Elasticsearch{}
+		if err := k8sClient.Get(context.TODO(), client.ObjectKey{Name: cluster.Name, Namespace: cluster.Namespace}, current); err != nil {
+			t.Fatalf("failed to get cluster: %s", err)
+		}
+		_, condition := getESNodeCondition(current.Status.Conditions, loggingv1.Debugging)
+		return condition
+	}
+
+	er.updateDebugging()
+	condition := getCondition()
+	if condition == nil || condition.Status != corev1.ConditionTrue || !strings.Contains(condition.Message, "debugged-cdm-1-deadbeef") {
+		t.Errorf("Exp. the debugging condition to list the debugged node but got %v", condition)
+	}
+
+	node.debugging = false
+	er.updateDebugging()
+	if condition := getCondition(); condition != nil {
+		t.Errorf("Exp. the debugging condition to be cleared but got %v", condition)
+	}
+}
@@ -2,6 +2,9 @@ package elasticsearch
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/ViaQ/logerr/kverrors"
 	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
@@ -16,22 +19,115 @@ const (
 	defaultESCpuRequest    = "100m"
 	defaultESMemoryLimit   = "4Gi"
 	defaultESMemoryRequest = "1Gi"
+	// ES master only nodes hold no shards and need less memory
+	defaultESMasterCPURequest    = "100m"
+	defaultESMasterMemoryLimit   = "2Gi"
+	defaultESMasterMemoryRequest = "1Gi"
 	// ESProxy
 	defaultESProxyCPURequest    = "100m"
 	defaultESProxyMemoryLimit   = "256Mi"
 	defaultESProxyMemoryRequest = "256Mi"
+	// Pod security context
+	defaultESRunAsUser  int64 = 1000
+	defaultESRunAsGroup int64 = 1000
+	defaultESFSGroup    int64 = 1000
 
 	maxMasterCount       = 3
 	maxPrimaryShardCount = 5
 
-	elasticsearchCertsPath  = "/etc/openshift/elasticsearch/secret"
-	elasticsearchConfigPath = "/usr/share/java/elasticsearch/config"
-	heapDumpLocation        = "/elasticsearch/persistent/heapdump.hprof"
+	// The ports of the services the operator and its components connect to
+	defaultClientServicePort    int32 = 9200
+	defaultDiscoveryServicePort int32 = 9300
+	// restAPIPortName is the name of the http port of the pods
+	restAPIPortName = "restapi"
+
+	elasticsearchCertsPath      = "/etc/openshift/elasticsearch/secret"
+	elasticsearchConfigPath     = "/usr/share/java/elasticsearch/config"
+	elasticsearchPersistentPath = "/elasticsearch/persistent"
+	elasticsearchPluginsPath    = "/usr/share/java/elasticsearch/plugins"
+	heapDumpLocation            = "/elasticsearch/persistent/heapdump.hprof"
+
+	chownDataVolumeContainerName = "chown-data-volume"
+	installPluginsContainerName  = "install-plugins"
+	pluginsVolumeName            = "elasticsearch-plugins"
+	importTrustedCAContainerName = "import-trusted-ca"
+	trustedCAVolumeName          = "elasticsearch-trusted-ca"
+	truststoreVolumeName         = "elasticsearch-truststore"
+
+	serviceAccountTokenVolumeName              = "service-account-token"
+	serviceAccountTokenPath                    = "/var/run/secrets/kubernetes.io/serviceaccount"
+	serviceAccountTokenExpirationSeconds int64 = 3607
+
+	readinessProbeCommand                    = "/usr/share/elasticsearch/probe/readiness.sh"
+	defaultReadinessProbeTimeoutSeconds      = 30
+	defaultReadinessProbeInitialDelaySeconds = 10
+	defaultReadinessProbePeriodSeconds       = 5
+	// nodes without the data role have no shards to recover before being ready
+	defaultNoDataReadinessProbeInitialDelaySeconds = 5
+
+	defaultRecoverAfterTime = "5m"
+	defaultMaxPendingTasks  = 5
 
 	yellowClusterState = "yellow"
 	greenClusterState  = "green"
 )
 
+// reservedEnvVars are computed by the Elasticsearch image from the operator
+// provided environment and must not be overridden
+var reservedEnvVars = []string{"ES_JAVA_OPTS"}
+
+// reservedJvmOptions are the prefixes of JVM flags computed by the operator,
+// i.e. the heap size derived from the memory limit
+var reservedJvmOptions = []string{"-Xms", "-Xmx", "-XX:InitialHeapSize", "-XX:MaxHeapSize"}
+
+// clusterHealthWait bounds the server side wait for the cluster health before restarting nodes
+var clusterHealthWait = 10 * time.Second
+
+// RolloutTimeouts bound the waits on the rollouts of the node deployments
+type RolloutTimeouts struct {
+	// InitialRollout bounds the wait for a created node deployment to be assigned its first
+	// revision. Nodes exceeding it are checked again on the next reconcile.
+	InitialRollout time.Duration
+
+	// NodeRollout bounds the wait for the pods of a node to match its updated template
+	NodeRollout time.Duration
+}
+
+// DefaultRolloutTimeouts are used for the rollout timeouts not configured
+var DefaultRolloutTimeouts = RolloutTimeouts{
+	InitialRollout: 30 * time.Second,
+	NodeRollout:    30 * time.Second,
+}
+
+var (
+	rolloutPollInterval = time.Second
+	rolloutTimeouts     = DefaultRolloutTimeouts
+)
+
+// SetRolloutTimeouts sets the rollout timeouts, keeping the defaults of the unset ones
+func SetRolloutTimeouts(timeouts RolloutTimeouts) {
+	if timeouts.InitialRollout <= 0 {
+		timeouts.InitialRollout = DefaultRolloutTimeouts.InitialRollout
+	}
+	if timeouts.NodeRollout <= 0 {
+		timeouts.NodeRollout = DefaultRolloutTimeouts.NodeRollout
+	}
+	rolloutTimeouts = timeouts
+}
+
+// maxUnassignedShards caps the unassigned shards of a cluster starting a node rollout,
+// a negative value disables the cap
+var maxUnassignedShards int32 = -1
+
+// SetMaxUnassignedShards sets the number of unassigned shards above which no new node
+// rollout is started, e.g. while the cluster still recovers from a previous one
+func SetMaxUnassignedShards(max int32) {
+	maxUnassignedShards = max
+}
+
+// timeValueRegex matches the Elasticsearch time values, e.g. 30s or 5m
+var timeValueRegex = regexp.MustCompile(`^[0-9]+(d|h|m|s|ms|micros|nanos)$`)
+
 var desiredClusterStates = []string{yellowClusterState, greenClusterState}
 
 func kibanaIndexMode(mode string) (string, error) {
@@ -49,6 +145,171 @@ func esUnicastHost(clusterName, namespace string) string {
 	return fmt.Sprintf("%v-cluster.%v.svc", clusterName, namespace)
 }
 
+func esDiscoveryServiceName(clusterName string) string {
+	return fmt.Sprintf("%v-discovery", clusterName)
+}
+
+// discoveryPublishNotReady returns whether the discovery services publish not ready
+// addresses, which allows nodes to find each other before they are ready
+func discoveryPublishNotReady(dpl *api.Elasticsearch) bool {
+	if dpl.Spec.Services != nil {
+		return publishNotReady(dpl.Spec.Services.Discovery, true)
+	}
+	return true
+}
+
+// clientPublishNotReady returns whether the client service publishes not ready addresses
+func clientPublishNotReady(dpl *api.Elasticsearch) bool {
+	if dpl.Spec.Services != nil {
+		return publishNotReady(dpl.Spec.Services.Client, false)
+	}
+	return false
+}
+
+// metricsPublishNotReady returns whether the metrics service publishes not ready addresses
+func metricsPublishNotReady(dpl *api.Elasticsearch) bool {
+	if dpl.Spec.Services != nil {
+		return publishNotReady(dpl.Spec.Services.Metrics, false)
+	}
+	return false
+}
+
+func publishNotReady(spec *api.ElasticsearchServiceSpec, defaultValue bool) bool {
+	if spec == nil || spec.PublishNotReadyAddresses == nil {
+		return defaultValue
+	}
+	return *spec.PublishNotReadyAddresses
+}
+
+// serviceAnnotations returns the annotations declared for a service merged with the
+// ones set by the operator, which take precedence
+func serviceAnnotations(spec *api.ElasticsearchServiceSpec, annotations map[string]string) map[string]string {
+	merged := map[string]string{}
+	if spec != nil {
+		for k, v := range spec.Annotations {
+			merged[k] = v
+		}
+	}
+	for k, v := range annotations {
+		merged[k] = v
+	}
+	return merged
+}
+
+func discoveryServiceSpec(dpl *api.Elasticsearch) *api.ElasticsearchServiceSpec {
+	if dpl.Spec.Services == nil {
+		return nil
+	}
+	return dpl.Spec.Services.Discovery
+}
+
+func clientServiceSpec(dpl *api.Elasticsearch) *api.ElasticsearchServiceSpec {
+	if dpl.Spec.Services == nil {
+		return nil
+	}
+	return dpl.Spec.Services.Client
+}
+
+// clientServicePort returns the port number of the client service
+func clientServicePort(dpl *api.Elasticsearch) int32 {
+	if spec := clientServiceSpec(dpl); spec != nil && spec.Port != 0 {
+		return spec.Port
+	}
+	return defaultClientServicePort
+}
+
+// clientServicePortName returns the name of the port of the client service
+func clientServicePortName(dpl *api.Elasticsearch) string {
+	if spec := clientServiceSpec(dpl); spec != nil && spec.PortName != "" {
+		return spec.PortName
+	}
+	return dpl.Name
+}
+
+func metricsServiceSpec(dpl *api.Elasticsearch) *api.ElasticsearchServiceSpec {
+	if dpl.Spec.Services == nil {
+		return nil
+	}
+	return dpl.Spec.Services.Metrics
+}
+
+func isHeadlessDiscovery(dpl *api.Elasticsearch) bool {
+	return dpl.Spec.Discovery != nil && dpl.Spec.Discovery.Provider == api.DiscoveryProviderHeadlessService
+}
+
+// esDiscoverySeedHosts returns the comma separated seed hosts of the cluster, starting
+// with the one of the configured provider followed by any additional seed hosts
+func esDiscoverySeedHosts(dpl *api.Elasticsearch) string {
+	hosts := []string{esUnicastHost(dpl.Name, dpl.Namespace)}
+	if isHeadlessDiscovery(dpl) {
+		hosts = []string{fmt.Sprintf("%v.%v.svc", esDiscoveryServiceName(dpl.Name), dpl.Namespace)}
+	}
+
+	if dpl.Spec.Discovery != nil {
+		hosts = append(hosts, dpl.Spec.Discovery.SeedHosts...)
+	}
+
+	return strings.Join(hosts, ",")
+}
+
+// recoverAfterTime returns the time to wait for the expected nodes after a full cluster restart
+func recoverAfterTime(dpl *api.Elasticsearch) string {
+	if dpl.Spec.RecoverAfterTime == "" {
+		return defaultRecoverAfterTime
+	}
+	return dpl.Spec.RecoverAfterTime
+}
+
+// getRoutingShards returns the number of routing shards of new indices or zero to
+// leave it to Elasticsearch
+func getRoutingShards(dpl *api.Elasticsearch) int {
+	if dpl.Spec.RoutingShards == nil {
+		return 0
+	}
+	return int(*dpl.Spec.RoutingShards)
+}
+
+// isValidRoutingShards returns whether the routing shards are a multiple of the primary
+// shards, without which Elasticsearch refuses to create the indices
+func isValidRoutingShards(dpl *api.Elasticsearch) bool {
+	routingShards := getRoutingShards(dpl)
+	if routingShards == 0 {
+		return true
+	}
+
+	primaryShards := CalculatePrimaryCount(dpl)
+	return primaryShards > 0 && routingShards >= primaryShards && routingShards%primaryShards == 0
+}
+
+// esClusterNameRegex matches the cluster names Elasticsearch accepts and which are safe to
+// be part of the data path
+var esClusterNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]{0,254}$`)
+
+// getESClusterName returns the name of the Elasticsearch cluster, which defaults to the
+// name of the custom resource
+func getESClusterName(dpl *api.Elasticsearch) string {
+	if dpl.Spec.ClusterName != "" {
+		return dpl.Spec.ClusterName
+	}
+	return dpl.Name
+}
+
+func isValidESClusterName(name string) bool {
+	return esClusterNameRegex.MatchString(name)
+}
+
+// getMaxPendingTasks returns the maximum number of pending cluster tasks to restart the next node
+func getMaxPendingTasks(dpl *api.Elasticsearch) int {
+	if dpl.Spec.MaxPendingTasks == nil {
+		return defaultMaxPendingTasks
+	}
+	return int(*dpl.Spec.MaxPendingTasks)
+}
+
+func isValidTimeValue(value string) bool {
+	return timeValueRegex.MatchString(value)
+}
+
 func CalculatePrimaryCount(dpl *api.Elasticsearch) int {
 	dataNodeCount := int(GetDataCount(dpl))
 	if dataNodeCount > maxPrimaryShardCount {
@@ -60,6 +321,15 @@ func CalculatePrimaryCount(dpl *api.Elasticsearch) int {
 	return dataNodeCount
 }
 
+// CalculateRoutingShardsCount returns the routing shards of new indices or zero to leave them
+// to Elasticsearch, which is also the case for routing shards not fitting the primary shards
+func CalculateRoutingShardsCount(dpl *api.Elasticsearch) int {
+	if !isValidRoutingShards(dpl) {
+		return 0
+	}
+	return getRoutingShards(dpl)
+}
+
 func CalculateReplicaCount(dpl *api.Elasticsearch) int {
 	dataNodeCount := int(GetDataCount(dpl))
 	repType := dpl.Spec.RedundancyPolicy
@@ -59,4 +59,58 @@ var _ = Describe("defaults", func() {
 			Expect(CalculatePrimaryCount(dpl)).To(Equal(dataNodeCount))
 		})
 	})
+
+	Describe("#recoverAfterTime", func() {
+		It("should default to 5m", func() {
+			Expect(recoverAfterTime(&api.Elasticsearch{})).To(Equal(defaultRecoverAfterTime))
+		})
+		It("should return the configured time", func() {
+			dpl = &api.Elasticsearch{Spec: api.ElasticsearchSpec{RecoverAfterTime: "10m"}}
+			Expect(recoverAfterTime(dpl)).To(Equal("10m"))
+		})
+	})
+
+	Describe("#isValidTimeValue", func() {
+		It("should accept Elasticsearch time values", func() {
+			for _, value := range []string{"30s", "5m", "1h", "500ms", "2d"} {
+				Expect(isValidTimeValue(value)).To(BeTrue(), value)
+			}
+		})
+		It("should reject malformed time values", func() {
+			for _, value := range []string{"", "5", "m", "5 m", "-5m", "5y"} {
+				Expect(isValidTimeValue(value)).To(BeFalse(), value)
+			}
+		})
+	})
+
+	Describe("#isValidRoutingShards", func() {
+		JustBeforeEach(func() {
+			dataNode.NodeCount = 3
+			dpl = &api.Elasticsearch{
+				Spec: api.ElasticsearchSpec{
+					Nodes: []api.ElasticsearchNode{
+						dataNode,
+					},
+				},
+			}
+		})
+		It("should accept unset routing shards", func() {
+			Expect(isValidRoutingShards(dpl)).To(BeTrue())
+		})
+		It("should accept a multiple of the primary shards", func() {
+			routingShards := int32(48)
+			dpl.Spec.RoutingShards = &routingShards
+			Expect(isValidRoutingShards(dpl)).To(BeTrue())
+		})
+		It("should reject fewer routing than primary shards", func() {
+			routingShards := int32(2)
+			dpl.Spec.RoutingShards = &routingShards
+			Expect(isValidRoutingShards(dpl)).To(BeFalse())
+		})
+		It("should reject values not divisible by the primary shards", func() {
+			routingShards := int32(16)
+			dpl.Spec.RoutingShards = &routingShards
+			Expect(isValidRoutingShards(dpl)).To(BeFalse())
+		})
+	})
 })
@@ -2,6 +2,8 @@ package elasticsearch
 
 import (
 	"context"
+	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/ViaQ/logerr/kverrors"
@@ -10,16 +12,21 @@ import (
 	"github.com/openshift/elasticsearch-operator/internal/manifests/deployment"
 	"github.com/openshift/elasticsearch-operator/internal/manifests/pod"
 	"github.com/openshift/elasticsearch-operator/internal/manifests/secret"
+	"github.com/openshift/elasticsearch-operator/internal/metrics"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
+	"github.com/openshift/elasticsearch-operator/internal/utils/comparators"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/ViaQ/logerr/log"
+	"github.com/go-logr/logr"
 	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 )
 
 type deploymentNode struct {
@@ -33,21 +40,55 @@ type deploymentNode struct {
 
 	replicas int32
 
+	// resources applied by default to the elasticsearch container
+	defaultedResources *v1.ResourceRequirements
+
 	client client.Client
 
 	esClient esclient.Client
+
+	recorder record.EventRecorder
+
+	// allow deleting and recreating the deployment when it cannot be updated
+	allowRecreate bool
+
+	// hash of the desired pod template
+	desiredHash string
+	// hash of the desired pod template last rolled out on the node
+	appliedHash string
+
+	// the deployment was created but not yet assigned its first revision
+	initialRolloutPending bool
+
+	// the deployment is kept unpaused for debugging and its drift is ignored
+	debugging bool
+
+	l logr.Logger
 }
 
-func (node *deploymentNode) populateReference(nodeName string, n api.ElasticsearchNode, cluster *api.Elasticsearch, roleMap map[api.ElasticsearchNodeRole]bool, replicas int32, client client.Client, esClient esclient.Client) {
-	labels := newLabels(cluster.Name, nodeName, roleMap)
+// L is the logger relative to this node
+//
+// TODO remove this construct when context.Context is passed and it should contain any relevant contextual values
+func (node *deploymentNode) L() logr.Logger {
+	if node.l == nil {
+		node.l = log.WithValues("node", node.name())
+	}
+	return node.l
+}
+
+func (node *deploymentNode) populateReference(nodeName string, n api.ElasticsearchNode, cluster *api.Elasticsearch, roles NodeRoles, replicas int32, client client.Client, esClient esclient.Client) {
+	labels := newLabels(cluster.Name, nodeName, roles)
 
 	progressDeadlineSeconds := int32(1800)
 	logConfig := getLogConfig(cluster.GetAnnotations())
-	template := newPodTemplateSpec(nodeName, cluster.Name, cluster.Namespace, n, cluster.Spec.Spec, labels, roleMap, client, logConfig)
+	template := newPodTemplateSpec(nodeName, cluster.Name, cluster.Namespace, n, cluster.Spec.Spec, labels, roles, client, logConfig)
+	setESClusterName(&template, getESClusterName(cluster))
+	setDataTier(&template, getNodeDataTier(cluster, n))
+	setIngest(&template, getNodeIngest(cluster, n))
 
 	dpl := deployment.New(nodeName, cluster.Namespace, labels, replicas).
 		WithSelector(metav1.LabelSelector{
-			MatchLabels: newLabelSelector(cluster.Name, nodeName, roleMap),
+			MatchLabels: newLabelSelector(cluster.Name, nodeName, roles),
 		}).
 		WithStrategy(apps.RecreateDeploymentStrategyType).
 		WithProgressDeadlineSeconds(progressDeadlineSeconds).
@@ -55,11 +96,18 @@ func (node *deploymentNode) populateReference(nodeName string, n api.Elasticsear
 		WithPaused(false).
 		Build()
 
+	dpl.Annotations = utils.WithPropagatedAnnotations(setDesiredTemplateHash(dpl.Annotations, template), propagatedAnnotations(cluster))
 	cluster.AddOwnerRefTo(dpl)
 
 	node.self = *dpl
 	node.clusterName = cluster.Name
 	node.replicas = replicas
+	node.defaultedResources = newDefaultedESResources(n.Resources, cluster.Spec.Spec.Resources, roles)
+	node.allowRecreate = cluster.Spec.AllowRecreateOnImmutableError
+	node.debugging = getDebugUnpausedNodes(cluster).Has(nodeName)
+	node.desiredHash = podTemplateHash(template)
+	_, nodeStatus := getNodeStatus(nodeName, &cluster.Status)
+	node.appliedHash = nodeStatus.AppliedTemplateHash
 
 	node.client = client
 	node.esClient = esClient
@@ -67,6 +115,11 @@ func (node *deploymentNode) populateReference(nodeName string, n api.Elasticsear
 
 func (node *deploymentNode) updateReference(n NodeTypeInterface) {
 	node.self = n.(*deploymentNode).self
+	node.defaultedResources = n.(*deploymentNode).defaultedResources
+	node.allowRecreate = n.(*deploymentNode).allowRecreate
+	node.debugging = n.(*deploymentNode).debugging
+	node.desiredHash = n.(*deploymentNode).desiredHash
+	node.l = n.(*deploymentNode).l
 }
 
 func (node *deploymentNode) scaleDown() error {
@@ -90,9 +143,15 @@ func (node *deploymentNode) state() api.ElasticsearchNodeStatus {
 	var rolloutForUpdate v1.ConditionStatus
 	var rolloutForCertReload v1.ConditionStatus
 
-	// see if we need to update the deployment object
-	if node.isChanged() {
+	// see if we need to update the deployment object, skipping the comparison
+	// with the pods if the live deployment is the one the template was rolled out with
+	switch {
+	case node.debugging:
+	case node.isApplied():
+	case node.isChanged():
 		rolloutForUpdate = v1.ConditionTrue
+	case node.podSpecMatches():
+		node.recordApplied()
 	}
 
 	// check for a case where our hash is missing -- operator restarted?
@@ -111,7 +170,11 @@ func (node *deploymentNode) state() api.ElasticsearchNodeStatus {
 	}
 
 	return api.ElasticsearchNodeStatus{
-		DeploymentName: node.self.Name,
+		DeploymentName:        node.self.Name,
+		Revision:              node.nodeRevision(),
+		DefaultedResources:    node.defaultedResources,
+		AppliedTemplateHash:   node.appliedHash,
+		LastAppliedConfigHash: configHash(node.configmapHash),
 		UpgradeStatus: api.ElasticsearchNodeUpgradeStatus{
 			ScheduledForUpgrade:      rolloutForUpdate,
 			ScheduledForCertRedeploy: rolloutForCertReload,
@@ -135,26 +198,78 @@ func (node *deploymentNode) create() error {
 					"cluster", node.clusterName,
 					"namespace", node.self.Namespace,
 				)
-			} else {
+			} else if node.isDeleting() {
+				// the deployment recreated on an immutable change is created once it is gone
+				return newRequeueError(RequeueWaitingForRollout, "waiting for node deployment to be deleted before recreating it",
+					"node", node.self.Name,
+				)
+			} else if !node.initialRolloutPending {
+				if err := node.updateInPlace(); err != nil {
+					return err
+				}
 				return node.pause()
 			}
+		} else {
+			node.initialRolloutPending = true
 		}
+	}
 
+	if node.initialRolloutPending {
 		// created unpaused, pause after deployment...
 		// wait until we have a revision annotation...
-		if err = node.waitForInitialRollout(); err != nil {
+		if err := node.waitForInitialRollout(); err != nil {
+			if err == wait.ErrWaitTimeout {
+				return newRequeueError(RequeueWaitingForRollout, "node deployment not yet assigned its first revision",
+					"node", node.self.Name,
+					"timeout", rolloutTimeouts.InitialRollout.String(),
+				)
+			}
 			return err
 		}
+		node.initialRolloutPending = false
 
 		// update the hashmaps
 		node.refreshHashes()
+		node.recordApplied()
+	}
+
+	if err := node.updateInPlace(); err != nil {
+		return err
 	}
 
 	return node.pause()
 }
 
+// updateInPlace updates the deployment strategy and the propagated annotations of the
+// deployment and its PVC in place, since changing them does not replace the pods and thus
+// needs no rollout of the node
+func (node *deploymentNode) updateInPlace() error {
+	strategy := node.self.Spec.Strategy
+	annotations := node.self.Annotations
+	equalFunc := func(current, _ *apps.Deployment) bool {
+		return reflect.DeepEqual(current.Spec.Strategy, strategy) &&
+			utils.ContainsPropagatedAnnotations(current.Annotations, annotations)
+	}
+	mutateFunc := func(current, _ *apps.Deployment) {
+		current.Spec.Strategy = strategy
+		current.Annotations = utils.MergePropagatedAnnotations(current.Annotations, annotations)
+	}
+
+	err := deployment.Update(context.TODO(), node.client, node.self.DeepCopy(), equalFunc, mutateFunc)
+	if err != nil {
+		return kverrors.Wrap(err, "failed to update elasticsearch node deployment in place",
+			"cluster", node.clusterName,
+			"namespace", node.self.Namespace,
+		)
+	}
+
+	return updatePropagatedPVCAnnotations(node.client, node.self.Namespace, node.self.Spec.Template, utils.PropagatedAnnotations(annotations))
+}
+
 func (node *deploymentNode) waitForInitialRollout() error {
-	err := wait.Poll(time.Second*1, time.Second*30, func() (done bool, err error) {
+	defer metrics.ObserveWait(time.Now())
+
+	err := wait.Poll(rolloutPollInterval, rolloutTimeouts.InitialRollout, func() (done bool, err error) {
 		key := client.ObjectKey{Name: node.self.Name, Namespace: node.self.Namespace}
 		dpl, err := deployment.Get(context.TODO(), node.client, key)
 		if err != nil {
@@ -172,8 +287,15 @@ func (node *deploymentNode) waitForInitialRollout() error {
 	return err
 }
 
+// nodeRevision returns the revision of the deployment as observed on the cluster
 func (node *deploymentNode) nodeRevision() string {
-	val, ok := node.self.ObjectMeta.Annotations["deployment.kubernetes.io/revision"]
+	key := client.ObjectKey{Name: node.self.Name, Namespace: node.self.Namespace}
+	current, err := deployment.Get(context.TODO(), node.client, key)
+	if err != nil {
+		return ""
+	}
+
+	val, ok := current.ObjectMeta.Annotations["deployment.kubernetes.io/revision"]
 
 	if ok {
 		return val
@@ -183,7 +305,9 @@ func (node *deploymentNode) nodeRevision() string {
 }
 
 func (node *deploymentNode) waitForNodeRollout() error {
-	err := wait.Poll(time.Second*1, time.Second*30, func() (done bool, err error) {
+	defer metrics.ObserveWait(time.Now())
+
+	err := wait.Poll(rolloutPollInterval, rolloutTimeouts.NodeRollout, func() (done bool, err error) {
 		return node.podSpecMatches(), nil
 	})
 	return err
@@ -200,7 +324,7 @@ func (node *deploymentNode) podSpecMatches() bool {
 func (node *deploymentNode) checkPodSpecMatches(labels map[string]string) bool {
 	podList, err := pod.List(context.TODO(), node.client, node.self.Namespace, labels)
 	if err != nil {
-		log.Error(err, "Could not get node pods", "node", node.name())
+		node.L().Error(err, "Could not get node pods")
 		return false
 	}
 
@@ -213,7 +337,11 @@ func (node *deploymentNode) checkPodSpecMatches(labels map[string]string) bool {
 	return true
 }
 
+// pause pauses the deployment unless it is kept unpaused for debugging
 func (node *deploymentNode) pause() error {
+	if node.debugging {
+		return nil
+	}
 	return node.setPaused(true)
 }
 
@@ -244,6 +372,38 @@ func (node *deploymentNode) setPaused(paused bool) error {
 	return nil
 }
 
+// repause pauses the deployment if it was left unpaused on the cluster, e.g. by an operator
+// stopped in the middle of a rollout, unless it is kept unpaused for debugging. Returns true
+// if the deployment was paused again.
+func (node *deploymentNode) repause() (bool, error) {
+	if node.debugging {
+		return false, nil
+	}
+
+	key := client.ObjectKey{Name: node.self.Name, Namespace: node.self.Namespace}
+	current, err := deployment.Get(context.TODO(), node.client, key)
+	if err != nil {
+		if apierrors.IsNotFound(kverrors.Root(err)) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if current.Spec.Paused {
+		return false, nil
+	}
+
+	message := fmt.Sprintf("Pausing deployment %q left unpaused without a rollout in progress", node.name())
+	node.L().Info(message)
+	recordEvent(node.recorder, current, v1.EventTypeWarning, eventReasonNodeRepaused, message)
+
+	if err := node.pause(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
 func (node *deploymentNode) setReplicaCount(replicas int32) error {
 	equalFunc := func(current, _ *apps.Deployment) bool {
 		if current.Spec.Replicas == nil {
@@ -272,7 +432,7 @@ func (node *deploymentNode) replicaCount() (int32, error) {
 	key := client.ObjectKey{Name: node.self.Name, Namespace: node.self.Namespace}
 	dpl, err := deployment.Get(context.TODO(), node.client, key)
 	if err != nil {
-		log.Error(err, "Could not get Elasticsearch node resource")
+		node.L().Error(err, "Could not get Elasticsearch node resource")
 		return -1, err
 	}
 
@@ -280,6 +440,8 @@ func (node *deploymentNode) replicaCount() (int32, error) {
 }
 
 func (node *deploymentNode) waitForNodeRejoinCluster() (bool, error) {
+	defer metrics.ObserveWait(time.Now())
+
 	err := wait.Poll(time.Second*1, time.Second*60, func() (done bool, err error) {
 		return node.esClient.IsNodeInCluster(node.name())
 	})
@@ -288,6 +450,8 @@ func (node *deploymentNode) waitForNodeRejoinCluster() (bool, error) {
 }
 
 func (node *deploymentNode) waitForNodeLeaveCluster() (bool, error) {
+	defer metrics.ObserveWait(time.Now())
+
 	err := wait.Poll(time.Second*1, time.Second*60, func() (done bool, err error) {
 		inCluster, checkErr := node.esClient.IsNodeInCluster(node.name())
 
@@ -299,38 +463,156 @@ func (node *deploymentNode) waitForNodeLeaveCluster() (bool, error) {
 
 func (node *deploymentNode) isMissing() bool {
 	key := client.ObjectKey{Name: node.name(), Namespace: node.self.Namespace}
-	_, err := deployment.Get(context.TODO(), node.client, key)
-	if err != nil {
-		if apierrors.IsNotFound(kverrors.Root(err)) {
-			return true
-		}
-	}
+	return isObjectMissing(func() error {
+		_, err := deployment.Get(context.TODO(), node.client, key)
+		return err
+	})
+}
 
-	return false
+func (node *deploymentNode) isMaster() bool {
+	return isMasterDeployment(node.self)
 }
 
 func (node *deploymentNode) executeUpdate() error {
+	key := client.ObjectKey{Name: node.self.Name, Namespace: node.self.Namespace}
+	current, err := deployment.Get(context.TODO(), node.client, key)
+	if err != nil {
+		return kverrors.Wrap(err, "failed to get elasticsearch node deployment",
+			"node", node.name(),
+		)
+	}
+
+	// the selector is immutable, an update changing it is rejected on every retry
+	if !comparators.AreSelectorsSame(selectorLabels(current), selectorLabels(&node.self)) {
+		cause := kverrors.New("node deployment selector changed",
+			"current", selectorLabels(current),
+			"desired", selectorLabels(&node.self))
+		if node.allowRecreate {
+			return node.recreate(cause)
+		}
+		node.L().Info("Unable to change node deployment selector, set allowRecreateOnImmutableError to recreate it",
+			"current", selectorLabels(current),
+			"desired", selectorLabels(&node.self))
+		return kverrors.Wrap(cause, "failed to update elasticsearch node deployment",
+			"cluster", node.clusterName,
+			"namespace", node.self.Namespace,
+		)
+	}
+
 	equalFunc := func(current, desired *apps.Deployment) bool {
-		return pod.ArePodTemplateSpecEqual(current.Spec.Template, desired.Spec.Template)
+		return pod.ArePodTemplateSpecEqual(current.Spec.Template, desired.Spec.Template) &&
+			comparators.AreStringMapsSame(current.Spec.Template.Labels, desired.Spec.Template.Labels) &&
+			containsLabels(current.Labels, desired.Labels) &&
+			reflect.DeepEqual(current.Spec.Strategy, desired.Spec.Strategy)
 	}
 
+	var reverted []string
 	mutateFunc := func(current, desired *apps.Deployment) {
+		reverted = nil
+		if isManuallyModified(current.Annotations, desired.Spec.Template) {
+			reverted = pod.DiffPodTemplateSpec(current.Spec.Template, desired.Spec.Template)
+		}
+
 		current.Spec.Template = createUpdatablePodTemplateSpec(current.Spec.Template, desired.Spec.Template)
+		current.Spec.Strategy = desired.Spec.Strategy
+		current.Labels = mergeLabels(current.Labels, desired.Labels)
+		current.Annotations = setDesiredTemplateHash(utils.MergePropagatedAnnotations(current.Annotations, desired.Annotations), desired.Spec.Template)
 	}
 
-	err := deployment.Update(context.TODO(), node.client, &node.self, equalFunc, mutateFunc)
+	err = deployment.Update(context.TODO(), node.client, &node.self, equalFunc, mutateFunc)
 	if err != nil {
+		// invalid updates, e.g. of immutable fields, fail the same way on every retry
+		if apierrors.IsInvalid(kverrors.Root(err)) {
+			if node.allowRecreate {
+				return node.recreate(err)
+			}
+			node.L().Info("Unable to update node deployment, set allowRecreateOnImmutableError to recreate it",
+				"cause", kverrors.Root(err).Error())
+		}
 		return kverrors.Wrap(err, "failed to update elasticsearch node deployment",
 			"cluster", node.clusterName,
 			"namespace", node.self.Namespace,
 		)
 	}
 
+	recordResourceReverted(node.recorder, &node.self, "Deployment", node.name(), reverted)
+
 	return nil
 }
 
+// selectorLabels returns the labels the deployment selects its pods by
+func selectorLabels(dpl *apps.Deployment) map[string]string {
+	if dpl.Spec.Selector == nil {
+		return nil
+	}
+	return dpl.Spec.Selector.MatchLabels
+}
+
+// containsLabels returns true if the labels hold all the desired ones, leaving the labels
+// added by others untouched
+func containsLabels(labels, desired map[string]string) bool {
+	for key, value := range desired {
+		if current, ok := labels[key]; !ok || current != value {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeLabels returns the labels updated by the desired ones
+func mergeLabels(labels, desired map[string]string) map[string]string {
+	if len(desired) == 0 {
+		return labels
+	}
+	merged := make(map[string]string, len(labels)+len(desired))
+	for key, value := range labels {
+		merged[key] = value
+	}
+	for key, value := range desired {
+		merged[key] = value
+	}
+	return merged
+}
+
+// recreate deletes the deployment that cannot be updated. The deletion waits for the pods
+// of the node to be gone in the background, thus the reconcile is requeued and the missing
+// deployment is created anew from the desired spec on a later reconcile, restarting the
+// node like any other node under update.
+func (node *deploymentNode) recreate(cause error) error {
+	key := client.ObjectKey{Name: node.self.Name, Namespace: node.self.Namespace}
+
+	message := fmt.Sprintf("Recreating deployment %q since it cannot be updated: %s", node.name(), kverrors.Root(cause).Error())
+	node.L().Info(message)
+	recordEvent(node.recorder, &node.self, v1.EventTypeWarning, eventReasonNodeRecreated, message)
+
+	if err := deployment.DeleteForeground(context.TODO(), node.client, key); err != nil && !apierrors.IsNotFound(kverrors.Root(err)) {
+		return kverrors.Wrap(err, "failed to delete elasticsearch node deployment for recreation",
+			"node", node.name(),
+		)
+	}
+
+	return newRequeueError(RequeueWaitingForRollout, "waiting for node deployment to be deleted before recreating it",
+		"node", node.name(),
+	)
+}
+
+// isDeleting returns true if the deployment of the node is deleted but not yet gone
+func (node *deploymentNode) isDeleting() bool {
+	key := client.ObjectKey{Name: node.self.Name, Namespace: node.self.Namespace}
+	current, err := deployment.Get(context.TODO(), node.client, key)
+	return err == nil && current.DeletionTimestamp != nil
+}
+
 func (node *deploymentNode) progressNodeChanges() error {
+	if node.isApplied() {
+		return nil
+	}
 	if !node.isChanged() && node.podSpecMatches() {
+		node.recordApplied()
+		return nil
+	}
+	if node.debugging {
+		node.L().Info("Skipping update of node kept unpaused for debugging", "annotation", debugUnpauseAnnotation)
 		return nil
 	}
 
@@ -357,9 +639,38 @@ func (node *deploymentNode) progressNodeChanges() error {
 	}
 
 	node.refreshHashes()
+	node.recordApplied()
 	return nil
 }
 
+// isApplied returns true if the desired pod template was already rolled out on the node
+// and the live deployment did not change since, i.e. the node needs no update
+func (node *deploymentNode) isApplied() bool {
+	if node.appliedHash == "" {
+		return false
+	}
+
+	key := client.ObjectKey{Name: node.self.Name, Namespace: node.self.Namespace}
+	current, err := deployment.Get(context.TODO(), node.client, key)
+	if err != nil {
+		return false
+	}
+
+	return appliedTemplateHash(node.desiredHash, current.Spec.Template) == node.appliedHash
+}
+
+// recordApplied records the desired pod template as rolled out on the live deployment
+func (node *deploymentNode) recordApplied() {
+	key := client.ObjectKey{Name: node.self.Name, Namespace: node.self.Namespace}
+	current, err := deployment.Get(context.TODO(), node.client, key)
+	if err != nil {
+		node.appliedHash = ""
+		return
+	}
+
+	node.appliedHash = appliedTemplateHash(node.desiredHash, current.Spec.Template)
+}
+
 func (node *deploymentNode) refreshHashes() {
 	key := client.ObjectKey{Name: node.clusterName, Namespace: node.self.Namespace}
 
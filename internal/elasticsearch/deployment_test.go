@@ -1,16 +1,88 @@
 package elasticsearch
 
 import (
+	"context"
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
-	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	"github.com/openshift/elasticsearch-operator/internal/utils"
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
 )
 
+// immutableUpdateClient rejects all updates like the API server does for changed immutable fields
+type immutableUpdateClient struct {
+	client.Client
+}
+
+func (c *immutableUpdateClient) Update(_ context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+	return apierrors.NewInvalid(schema.GroupKind{Group: "apps", Kind: "Deployment"}, "aName", field.ErrorList{
+		field.Invalid(field.NewPath("spec", "selector"), nil, "field is immutable"),
+	})
+}
+
+// countingUpdateClient records the number of updates issued against the API server
+type countingUpdateClient struct {
+	client.Client
+	updates int
+}
+
+func (c *countingUpdateClient) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	c.updates++
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+// slowRevisionClient assigns the first revision to the deployments only after a number of
+// gets, like a heavily loaded API server
+type slowRevisionClient struct {
+	client.Client
+	gets          int
+	revisionAfter int
+}
+
+func (c *slowRevisionClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	if err := c.Client.Get(ctx, key, obj); err != nil {
+		return err
+	}
+
+	if dpl, ok := obj.(*apps.Deployment); ok {
+		c.gets++
+		if c.gets > c.revisionAfter {
+			if dpl.Annotations == nil {
+				dpl.Annotations = map[string]string{}
+			}
+			dpl.Annotations["deployment.kubernetes.io/revision"] = "1"
+		}
+	}
+	return nil
+}
+
+// unreadableClient fails the first gets like an API server momentarily unavailable
+type unreadableClient struct {
+	client.Client
+	gets          int
+	failFirstGets int
+}
+
+func (c *unreadableClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	c.gets++
+	if c.gets <= c.failFirstGets {
+		return apierrors.NewServiceUnavailable("etcdserver: leader changed")
+	}
+	return c.Client.Get(ctx, key, obj)
+}
+
 var _ = Describe("deployment", func() {
 	defer GinkgoRecover()
 
@@ -35,7 +107,7 @@ var _ = Describe("deployment", func() {
 		client = fake.NewFakeClient(&current.self)
 
 		elasticsearch = newElasticsearchContainer("someImage",
-			newEnvVars("mynodename", "clustername", "", map[loggingv1.ElasticsearchNodeRole]bool{}),
+			newEnvVars("mynodename", "clustername", "", NodeRoles{}),
 			v1.ResourceRequirements{
 				Limits: v1.ResourceList{},
 			})
@@ -92,4 +164,487 @@ var _ = Describe("deployment", func() {
 			Expect(desired.self.Spec.Template.Spec.Containers[0].Ports).To(Equal(elasticsearch.Ports))
 		})
 	})
+
+	Context("executeUpdate()", func() {
+		var (
+			template = v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: "elasticsearch", Image: "someImage"},
+					},
+				},
+			}
+			newNode = func(image string, annotations map[string]string) *deploymentNode {
+				manual := template.DeepCopy()
+				manual.Spec.Containers[0].Image = image
+
+				existing := &apps.Deployment{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "reverted",
+						Namespace:   "aNamespace",
+						Annotations: annotations,
+					},
+					Spec: apps.DeploymentSpec{Template: *manual},
+				}
+
+				return &deploymentNode{
+					client:   fake.NewFakeClient(existing),
+					recorder: record.NewFakeRecorder(1),
+					self: apps.Deployment{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:        existing.Name,
+							Namespace:   existing.Namespace,
+							Annotations: setDesiredTemplateHash(nil, template),
+						},
+						Spec: apps.DeploymentSpec{Template: template},
+					},
+				}
+			}
+		)
+
+		It("should emit a warning event when reverting manual changes", func() {
+			node := newNode("manualImage", setDesiredTemplateHash(nil, template))
+			Expect(node.executeUpdate()).To(Succeed())
+
+			recorder := node.recorder.(*record.FakeRecorder)
+			Expect(recorder.Events).To(Receive(And(
+				ContainSubstring(eventReasonResourceReverted),
+				ContainSubstring("containers[elasticsearch].image"),
+			)))
+		})
+
+		It("should not emit an event when the desired spec changed", func() {
+			node := newNode("oldImage", map[string]string{desiredTemplateHashAnnotation: "previous"})
+			Expect(node.executeUpdate()).To(Succeed())
+
+			recorder := node.recorder.(*record.FakeRecorder)
+			Expect(recorder.Events).NotTo(Receive())
+		})
+
+		It("should fail on immutable field errors unless recreation is allowed", func() {
+			node := newNode("oldImage", nil)
+			node.client = &immutableUpdateClient{Client: node.client}
+			Expect(node.executeUpdate()).NotTo(Succeed())
+
+			recorder := node.recorder.(*record.FakeRecorder)
+			Expect(recorder.Events).NotTo(Receive())
+		})
+
+		It("should recreate the deployment on immutable field errors when allowed", func() {
+			node := newNode("oldImage", nil)
+			node.client = &immutableUpdateClient{Client: node.client}
+			node.allowRecreate = true
+			err := node.executeUpdate()
+			Expect(IsRequeue(err)).To(BeTrue())
+
+			key := types.NamespacedName{Name: node.self.Name, Namespace: node.self.Namespace}
+			Expect(apierrors.IsNotFound(node.client.Get(context.TODO(), key, &apps.Deployment{}))).To(BeTrue())
+
+			// the next reconcile creates the deployment anew
+			node.client = node.client.(*immutableUpdateClient).Client
+			node.self.ResourceVersion = ""
+			node.self.Annotations = map[string]string{"deployment.kubernetes.io/revision": "1"}
+			Expect(node.create()).To(Succeed())
+
+			recreated := &apps.Deployment{}
+			Expect(node.client.Get(context.TODO(), key, recreated)).To(Succeed())
+			Expect(recreated.Spec.Template.Spec.Containers[0].Image).To(Equal("someImage"))
+
+			recorder := node.recorder.(*record.FakeRecorder)
+			Expect(recorder.Events).To(Receive(ContainSubstring(eventReasonNodeRecreated)))
+		})
+	})
+
+	Context("executeUpdate() of changed labels", func() {
+		var (
+			oldLabels = map[string]string{"cluster-name": "elasticsearch", "node-name": "labeled", "es-node-data": "true"}
+
+			newNode = func(labels map[string]string) (*deploymentNode, *countingUpdateClient) {
+				existing := &apps.Deployment{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "labeled",
+						Namespace: "aNamespace",
+						Labels:    map[string]string{"component": "elasticsearch", "team": "logging"},
+					},
+					Spec: apps.DeploymentSpec{
+						Selector: &metav1.LabelSelector{MatchLabels: oldLabels},
+						Template: v1.PodTemplateSpec{
+							ObjectMeta: metav1.ObjectMeta{Labels: oldLabels},
+						},
+					},
+				}
+				c := &countingUpdateClient{Client: fake.NewFakeClient(existing)}
+
+				selector := map[string]string{}
+				for key, value := range labels {
+					if key != "component" {
+						selector[key] = value
+					}
+				}
+				return &deploymentNode{
+					client:   c,
+					recorder: record.NewFakeRecorder(1),
+					self: apps.Deployment{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      existing.Name,
+							Namespace: existing.Namespace,
+							Labels:    labels,
+						},
+						Spec: apps.DeploymentSpec{
+							Selector: &metav1.LabelSelector{MatchLabels: selector},
+							Template: v1.PodTemplateSpec{
+								ObjectMeta: metav1.ObjectMeta{Labels: labels},
+							},
+						},
+					},
+				}, c
+			}
+
+			get = func(node *deploymentNode) *apps.Deployment {
+				current := &apps.Deployment{}
+				key := types.NamespacedName{Name: node.self.Name, Namespace: node.self.Namespace}
+				Expect(node.client.Get(context.TODO(), key, current)).To(Succeed())
+				return current
+			}
+		)
+
+		It("should update non selector labels in place", func() {
+			labels := map[string]string{"component": "elasticsearch", "cluster-name": "elasticsearch", "node-name": "labeled", "es-node-data": "true"}
+			node, c := newNode(labels)
+			node.self.Labels = map[string]string{"component": "elasticsearch-node"}
+			Expect(node.executeUpdate()).To(Succeed())
+			Expect(c.updates).To(Equal(1))
+
+			current := get(node)
+			Expect(current.Labels).To(Equal(map[string]string{"component": "elasticsearch-node", "team": "logging"}))
+			Expect(current.Spec.Template.Labels).To(Equal(labels))
+
+			recorder := node.recorder.(*record.FakeRecorder)
+			Expect(recorder.Events).NotTo(Receive())
+		})
+
+		It("should not attempt to update a changed selector unless recreation is allowed", func() {
+			node, c := newNode(map[string]string{"component": "elasticsearch", "cluster-name": "elasticsearch", "node-name": "labeled", "es-node-master": "true"})
+			Expect(node.executeUpdate()).NotTo(Succeed())
+			Expect(c.updates).To(BeZero())
+			Expect(get(node).Spec.Selector.MatchLabels).To(Equal(oldLabels))
+		})
+
+		It("should recreate the deployment on a changed selector when allowed", func() {
+			node, c := newNode(map[string]string{"component": "elasticsearch", "cluster-name": "elasticsearch", "node-name": "labeled", "es-node-master": "true"})
+			node.allowRecreate = true
+			Expect(IsRequeue(node.executeUpdate())).To(BeTrue())
+			Expect(c.updates).To(BeZero())
+
+			node.self.ResourceVersion = ""
+			node.self.Annotations = map[string]string{"deployment.kubernetes.io/revision": "1"}
+			Expect(node.create()).To(Succeed())
+			Expect(get(node).Spec.Selector.MatchLabels).To(Equal(map[string]string{"cluster-name": "elasticsearch", "node-name": "labeled", "es-node-master": "true"}))
+
+			recorder := node.recorder.(*record.FakeRecorder)
+			Expect(recorder.Events).To(Receive(ContainSubstring("node deployment selector changed")))
+		})
+	})
+
+	Context("progressNodeChanges()", func() {
+		var (
+			template = v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: "elasticsearch", Image: "newImage"},
+					},
+				},
+			}
+
+			outdated = func() v1.PodTemplateSpec {
+				outdated := template.DeepCopy()
+				outdated.Spec.Containers[0].Image = "oldImage"
+				return *outdated
+			}()
+
+			newNode = func(name string, live v1.PodTemplateSpec, appliedHash string) (*deploymentNode, *countingUpdateClient) {
+				existing := &apps.Deployment{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      name,
+						Namespace: "aNamespace",
+					},
+					Spec: apps.DeploymentSpec{Template: live},
+				}
+				c := &countingUpdateClient{Client: fake.NewFakeClient(existing)}
+
+				return &deploymentNode{
+					client:      c,
+					recorder:    record.NewFakeRecorder(1),
+					desiredHash: podTemplateHash(template),
+					appliedHash: appliedHash,
+					self: apps.Deployment{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      existing.Name,
+							Namespace: existing.Namespace,
+						},
+						Spec: apps.DeploymentSpec{Template: template},
+					},
+				}, c
+			}
+		)
+
+		It("should not update node groups whose desired template was already applied", func() {
+			node, c := newNode("untouched", template, appliedTemplateHash(podTemplateHash(template), template))
+			Expect(node.progressNodeChanges()).To(Succeed())
+			Expect(c.updates).To(BeZero())
+		})
+
+		It("should revert manual changes of node groups whose desired template was already applied", func() {
+			node, c := newNode("drifted", outdated, appliedTemplateHash(podTemplateHash(template), template))
+			Expect(node.progressNodeChanges()).To(Succeed())
+			Expect(c.updates).NotTo(BeZero())
+
+			dpl := &apps.Deployment{}
+			Expect(c.Get(context.TODO(), types.NamespacedName{Name: "drifted", Namespace: "aNamespace"}, dpl)).To(Succeed())
+			Expect(dpl.Spec.Template.Spec.Containers[0].Image).To(Equal("newImage"))
+		})
+
+		It("should update node groups whose desired template changed and record it as applied", func() {
+			node, c := newNode("changed", outdated, "previous")
+			Expect(node.progressNodeChanges()).To(Succeed())
+			Expect(c.updates).NotTo(BeZero())
+
+			dpl := &apps.Deployment{}
+			Expect(c.Get(context.TODO(), types.NamespacedName{Name: "changed", Namespace: "aNamespace"}, dpl)).To(Succeed())
+			Expect(node.appliedHash).To(Equal(appliedTemplateHash(node.desiredHash, dpl.Spec.Template)))
+			Expect(node.state().AppliedTemplateHash).To(Equal(node.appliedHash))
+		})
+
+		It("should schedule an upgrade for node groups whose desired or live template changed", func() {
+			untouched, _ := newNode("untouched", template, appliedTemplateHash(podTemplateHash(template), template))
+			Expect(untouched.state().UpgradeStatus.ScheduledForUpgrade).To(BeEmpty())
+
+			drifted, _ := newNode("drifted", outdated, appliedTemplateHash(podTemplateHash(template), template))
+			Expect(drifted.state().UpgradeStatus.ScheduledForUpgrade).To(Equal(v1.ConditionTrue))
+
+			changed, _ := newNode("changed", outdated, "previous")
+			Expect(changed.state().UpgradeStatus.ScheduledForUpgrade).To(Equal(v1.ConditionTrue))
+		})
+	})
+
+	Context("state()", func() {
+		It("should report the current revision of the deployment", func() {
+			existing := &apps.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "revisioned",
+					Namespace:   "aNamespace",
+					Annotations: map[string]string{"deployment.kubernetes.io/revision": "3"},
+				},
+			}
+			node := &deploymentNode{
+				client: fake.NewFakeClient(existing),
+				self: apps.Deployment{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      existing.Name,
+						Namespace: existing.Namespace,
+					},
+				},
+			}
+
+			Expect(node.state().Revision).To(Equal("3"))
+		})
+	})
+	Context("create()", func() {
+		var (
+			defaultTimeouts = rolloutTimeouts
+			defaultInterval = rolloutPollInterval
+			newNode         = func(c *slowRevisionClient) *deploymentNode {
+				return &deploymentNode{
+					client:      c,
+					desiredHash: "desired",
+					self: apps.Deployment{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "slowNode",
+							Namespace: "aNamespace",
+						},
+					},
+				}
+			}
+		)
+
+		BeforeEach(func() {
+			rolloutPollInterval = 10 * time.Millisecond
+			SetRolloutTimeouts(RolloutTimeouts{InitialRollout: 50 * time.Millisecond})
+		})
+
+		AfterEach(func() {
+			rolloutTimeouts = defaultTimeouts
+			rolloutPollInterval = defaultInterval
+		})
+
+		It("should requeue instead of failing if the first revision is late", func() {
+			slowClient := &slowRevisionClient{Client: fake.NewFakeClient(), revisionAfter: 1000}
+			node := newNode(slowClient)
+
+			err := node.create()
+			Expect(IsRequeue(err)).To(BeTrue(), "Exp. the timeout to signal a requeue: %v", err)
+			Expect(node.initialRolloutPending).To(BeTrue())
+			Expect(node.appliedHash).To(BeEmpty())
+
+			// the revision is assigned before the next reconcile rebuilds the node
+			slowClient.revisionAfter = 0
+			node.self.ResourceVersion = ""
+			Expect(node.create()).To(Succeed())
+			Expect(node.initialRolloutPending).To(BeFalse())
+
+			dpl := &apps.Deployment{}
+			Expect(slowClient.Client.Get(context.TODO(), types.NamespacedName{Name: "slowNode", Namespace: "aNamespace"}, dpl)).To(Succeed())
+			Expect(dpl.Spec.Paused).To(BeTrue())
+			Expect(node.appliedHash).To(Equal(appliedTemplateHash("desired", dpl.Spec.Template)))
+		})
+
+		It("should wait up to the configured initial rollout timeout", func() {
+			SetRolloutTimeouts(RolloutTimeouts{InitialRollout: time.Second})
+			node := newNode(&slowRevisionClient{Client: fake.NewFakeClient(), revisionAfter: 3})
+
+			Expect(node.create()).To(Succeed())
+			Expect(node.initialRolloutPending).To(BeFalse())
+			Expect(node.appliedHash).NotTo(BeEmpty())
+		})
+	})
+
+	Context("setPaused() and setReplicaCount()", func() {
+		var (
+			key     = types.NamespacedName{Name: "pausedNode", Namespace: "aNamespace"}
+			newNode = func() (*deploymentNode, *apps.Deployment) {
+				replicas := int32(1)
+				dpl := &apps.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+					Spec:       apps.DeploymentSpec{Replicas: &replicas},
+				}
+				return &deploymentNode{self: *dpl.DeepCopy(), client: fake.NewFakeClient(dpl)}, dpl
+			}
+			getServer = func(node *deploymentNode) *apps.Deployment {
+				dpl := &apps.Deployment{}
+				Expect(node.client.Get(context.TODO(), key, dpl)).To(Succeed())
+				return dpl
+			}
+		)
+
+		It("should pause and unpause the deployment on the server", func() {
+			node, _ := newNode()
+
+			Expect(node.setPaused(true)).To(Succeed())
+			Expect(getServer(node).Spec.Paused).To(BeTrue())
+			Expect(node.self.Spec.Paused).To(BeTrue())
+
+			Expect(node.setPaused(false)).To(Succeed())
+			Expect(getServer(node).Spec.Paused).To(BeFalse())
+			Expect(node.self.Spec.Paused).To(BeFalse())
+		})
+
+		It("should pause the deployment on the server even if the node is already considered paused", func() {
+			node, _ := newNode()
+			node.self.Spec.Paused = true
+
+			Expect(node.setPaused(true)).To(Succeed())
+			Expect(getServer(node).Spec.Paused).To(BeTrue())
+		})
+
+		It("should scale the deployment on the server", func() {
+			node, _ := newNode()
+
+			Expect(node.setReplicaCount(0)).To(Succeed())
+			Expect(*getServer(node).Spec.Replicas).To(BeEquivalentTo(0))
+			Expect(*node.self.Spec.Replicas).To(BeEquivalentTo(0))
+
+			Expect(node.setReplicaCount(1)).To(Succeed())
+			Expect(*getServer(node).Spec.Replicas).To(BeEquivalentTo(1))
+		})
+
+		It("should scale the deployment on the server even if the node already has the replica count", func() {
+			node, dpl := newNode()
+			dpl.Spec.Replicas = nil
+			Expect(node.client.Update(context.TODO(), dpl)).To(Succeed())
+
+			Expect(node.setReplicaCount(1)).To(Succeed())
+			Expect(getServer(node).Spec.Replicas).ToNot(BeNil())
+			Expect(*getServer(node).Spec.Replicas).To(BeEquivalentTo(1))
+		})
+	})
+
+	Context("isMissing()", func() {
+		newNode := func(c *unreadableClient) *deploymentNode {
+			return &deploymentNode{
+				self: apps.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "aName", Namespace: "aNamespace"},
+				},
+				client: c,
+			}
+		}
+
+		It("should retry transient errors until the deployment is confirmed gone", func() {
+			c := &unreadableClient{Client: fake.NewFakeClient(), failFirstGets: 1}
+			Expect(newNode(c).isMissing()).To(BeTrue())
+			Expect(c.gets).To(Equal(2))
+		})
+
+		It("should not consider the deployment missing while it stays unreadable", func() {
+			c := &unreadableClient{Client: fake.NewFakeClient(), failFirstGets: 1000}
+			Expect(newNode(c).isMissing()).To(BeFalse())
+		})
+
+		It("should not consider a deployment pending deletion missing", func() {
+			now := metav1.Now()
+			dpl := &apps.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "aName", Namespace: "aNamespace", DeletionTimestamp: &now},
+			}
+			c := &unreadableClient{Client: fake.NewFakeClient(dpl), failFirstGets: 1}
+			Expect(newNode(c).isMissing()).To(BeFalse())
+		})
+	})
+
+	Describe("#updateInPlace", func() {
+		It("should update the propagated annotations of the deployment and its PVC without a rollout", func() {
+			key := types.NamespacedName{Name: "aNode", Namespace: "aNamespace"}
+			template := v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Volumes: []v1.Volume{
+						{
+							Name: "elasticsearch-storage",
+							VolumeSource: v1.VolumeSource{
+								PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: "aClaim"},
+							},
+						},
+					},
+				},
+			}
+			current := &apps.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      key.Name,
+					Namespace: key.Namespace,
+					Annotations: utils.WithPropagatedAnnotations(
+						map[string]string{"deployment.kubernetes.io/revision": "2"},
+						map[string]string{"removed": "old"},
+					),
+				},
+				Spec: apps.DeploymentSpec{Template: template},
+			}
+			pvc := &v1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "aClaim", Namespace: key.Namespace},
+			}
+			c := fake.NewFakeClient(current, pvc)
+
+			desired := current.DeepCopy()
+			desired.Annotations = utils.WithPropagatedAnnotations(nil, map[string]string{"cost-center": "1234"})
+			node := &deploymentNode{self: *desired, client: c}
+			Expect(node.updateInPlace()).To(Succeed())
+
+			got := &apps.Deployment{}
+			Expect(c.Get(context.TODO(), key, got)).To(Succeed())
+			Expect(got.Annotations).To(HaveKeyWithValue("cost-center", "1234"))
+			Expect(got.Annotations).To(HaveKeyWithValue("deployment.kubernetes.io/revision", "2"))
+			Expect(got.Annotations).NotTo(HaveKey("removed"))
+			Expect(got.Spec.Template).To(Equal(template))
+
+			gotPVC := &v1.PersistentVolumeClaim{}
+			Expect(c.Get(context.TODO(), types.NamespacedName{Name: "aClaim", Namespace: key.Namespace}, gotPVC)).To(Succeed())
+			Expect(gotPVC.Annotations).To(HaveKeyWithValue("cost-center", "1234"))
+		})
+	})
 })
@@ -41,6 +41,11 @@ func (er *ElasticsearchRequest) AnyNodeReady() bool {
 }
 
 func (er *ElasticsearchRequest) updateMinMasters() {
+	er.setMinMasters(er.getMasterQuorumCount())
+}
+
+// setMinMasters sets the minimum master nodes to the quorum of the given number of masters
+func (er *ElasticsearchRequest) setMinMasters(masterCount int32) {
 	// do as best effort -- whenever we create a node update min masters (if required)
 	if !er.AnyNodeReady() {
 		return
@@ -51,7 +56,7 @@ func (er *ElasticsearchRequest) updateMinMasters() {
 		er.L().Info("Unable to get current min master count")
 	}
 
-	desiredMasterCount := getMasterCount(er.cluster)/2 + 1
+	desiredMasterCount := masterCount/2 + 1
 	currentNodeCount, err := er.esClient.GetClusterNodeCount()
 	if err != nil {
 		er.L().Error(err, "Unable to get cluster node count")
@@ -20,6 +20,7 @@ import (
 	"github.com/ViaQ/logerr/log"
 	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
 	"github.com/openshift/elasticsearch-operator/internal/manifests/secret"
+	"github.com/openshift/elasticsearch-operator/internal/tracing"
 	estypes "github.com/openshift/elasticsearch-operator/internal/types/elasticsearch"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -40,15 +41,23 @@ type Client interface {
 	GetDiskWatermarks() (interface{}, interface{}, interface{}, error)
 	GetMinMasterNodes() (int32, error)
 	SetMinMasterNodes(numberMasters int32) (bool, error)
+	GetClusterSetting(name string) (interface{}, error)
+	UpdateClusterSettings(settings map[string]interface{}) error
 	DoSynchronizedFlush() (bool, error)
 
 	// Cluster State API
 	GetLowestClusterVersion() (string, error)
 	IsNodeInCluster(nodeName string) (bool, error)
+	GetPendingTasks() (int, time.Duration, error)
+	AddVotingConfigExclusion(nodeName string) error
+	ClearVotingConfigExclusions() error
+	GetLicense() (*estypes.License, error)
 
 	// Health API
 	GetClusterHealth() (api.ClusterHealth, error)
 	GetClusterHealthStatus() (string, error)
+	WaitForClusterHealth(ctx context.Context, minStatus string, wait time.Duration) (api.ClusterHealth, error)
+	HasElectedMaster() (bool, error)
 	GetClusterNodeCount() (int32, error)
 
 	// Index API
@@ -65,6 +74,10 @@ type Client interface {
 	// Index Settings API
 	GetIndexSettings(name string) (*estypes.Index, error)
 	UpdateIndexSettings(name string, settings *estypes.IndexSettings) error
+	SetIndexBlock(name string, block estypes.IndexBlock, enabled bool) error
+	GetIndexBlocks(name string) (map[estypes.IndexBlock]bool, error)
+	GetIndexDataTiers(pattern string) (map[string]string, error)
+	SetIndexDataTier(name, tier string) error
 
 	// Nodes API
 	GetNodeDiskUsage(nodeName string) (string, float64, error)
@@ -78,6 +91,12 @@ type Client interface {
 	ClearTransientShardAllocation() (bool, error)
 	GetShardAllocation() (string, error)
 	SetShardAllocation(state api.ShardAllocationState) (bool, error)
+	ExcludeNodeFromAllocation(nodeNames ...string) (bool, error)
+	ClearAllocationExclusions(nodeNames ...string) (bool, error)
+	GetNodeShardCount(nodeName string) (int32, error)
+	GetShardsOnNode(nodeName string) ([]estypes.CatShardsResponse, error)
+	GetRelocatingShardCount() (int32, error)
+	AllocationExplain() (*estypes.AllocationExplainResponse, error)
 
 	// Index Templates API
 	CreateIndexTemplate(name string, template *estypes.IndexTemplate) error
@@ -86,6 +105,13 @@ type Client interface {
 	GetIndexTemplates() (map[string]estypes.GetIndexTemplate, error)
 	UpdateTemplatePrimaryShards(shardCount int32) error
 
+	// Ingest API
+	PutPipeline(name, body string) error
+	GetPipeline(name string) (map[string]interface{}, error)
+
+	// Snapshot API
+	CreateSnapshot(repository, name string) error
+
 	SetSendRequestFn(fn FnEsSendRequest)
 }
 
@@ -121,6 +147,15 @@ func (ec *esClient) SetSendRequestFn(fn FnEsSendRequest) {
 	ec.fnSendEsRequest = fn
 }
 
+// TracedSendRequest returns the function sending the requests to the cluster within a child
+// span of the one of the reconcile, looked up per request as it changes with the steps
+func TracedSendRequest(span func() *tracing.Span) FnEsSendRequest {
+	return func(cluster, namespace string, payload *EsRequest, client k8sclient.Client) {
+		defer span().Child("esclient", "method", payload.Method, "uri", payload.URI).End()
+		sendEsRequest(cluster, namespace, payload, client)
+	}
+}
+
 func (ec *esClient) ClusterName() string {
 	return ec.cluster
 }
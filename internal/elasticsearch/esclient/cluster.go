@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ViaQ/logerr/kverrors"
 	estypes "github.com/openshift/elasticsearch-operator/internal/types/elasticsearch"
@@ -204,6 +206,59 @@ func (ec *esClient) GetMinMasterNodes() (int32, error) {
 	return masterCount, payload.Error
 }
 
+// GetClusterSetting returns the persistent value of the cluster setting, the one
+// UpdateClusterSettings sets, or nil if it is unset. Transient values are left to the
+// users overriding it temporarily.
+func (ec *esClient) GetClusterSetting(name string) (interface{}, error) {
+	payload := &EsRequest{
+		Method: http.MethodGet,
+		URI:    "_cluster/settings?flat_settings=true",
+	}
+
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error != nil {
+		return nil, payload.Error
+	}
+	if payload.StatusCode != http.StatusOK {
+		return nil, ec.errorCtx().New("failed to get cluster settings",
+			"setting", name,
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+
+	if settings, ok := payload.ResponseBody["persistent"].(map[string]interface{}); ok {
+		return settings[name], nil
+	}
+	return nil, nil
+}
+
+// UpdateClusterSettings sets the persistent cluster settings, nil values resetting them to
+// their defaults
+func (ec *esClient) UpdateClusterSettings(settings map[string]interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"persistent": settings})
+	if err != nil {
+		return kverrors.Wrap(err, "failed to encode cluster settings")
+	}
+
+	payload := &EsRequest{
+		Method:      http.MethodPut,
+		URI:         "_cluster/settings",
+		RequestBody: string(body),
+	}
+
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error != nil {
+		return payload.Error
+	}
+	if payload.StatusCode != http.StatusOK || !parseBool("acknowledged", payload.ResponseBody) {
+		return ec.errorCtx().New("failed to update cluster settings",
+			"settings", settings,
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+	return nil
+}
+
 // TODO: also check that the number of shards in the response > 0?
 func (ec *esClient) DoSynchronizedFlush() (bool, error) {
 	payload := &EsRequest{
@@ -301,3 +356,112 @@ func (ec *esClient) IsNodeInCluster(nodeName string) (bool, error) {
 
 	return false, nil
 }
+
+// GetPendingTasks returns the number of cluster tasks queued on the master and the
+// time the oldest of them is waiting in the queue
+func (ec *esClient) GetPendingTasks() (int, time.Duration, error) {
+	payload := &EsRequest{
+		Method: http.MethodGet,
+		URI:    "_cluster/pending_tasks",
+	}
+
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error != nil {
+		return 0, 0, payload.Error
+	}
+	if payload.StatusCode != http.StatusOK {
+		return 0, 0, ec.errorCtx().New("failed to get pending cluster tasks",
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody,
+		)
+	}
+
+	res := &estypes.PendingTasksResponse{}
+	err := json.Unmarshal([]byte(payload.RawResponseBody), res)
+	if err != nil {
+		return 0, 0, ec.errorCtx().Wrap(err, "failed to decode raw response body into `estypes.PendingTasksResponse`")
+	}
+
+	var oldest int64
+	for _, task := range res.Tasks {
+		if task.TimeInQueueMillis > oldest {
+			oldest = task.TimeInQueueMillis
+		}
+	}
+
+	return len(res.Tasks), time.Duration(oldest) * time.Millisecond, nil
+}
+
+// AddVotingConfigExclusion withdraws the vote of the master eligible node on the elected
+// master, so that removing it does not lose the quorum of the remaining masters
+func (ec *esClient) AddVotingConfigExclusion(nodeName string) error {
+	payload := &EsRequest{
+		Method: http.MethodPost,
+		URI:    fmt.Sprintf("_cluster/voting_config_exclusions?node_names=%s", url.QueryEscape(nodeName)),
+	}
+
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error != nil {
+		return payload.Error
+	}
+	if payload.StatusCode != http.StatusOK {
+		return ec.errorCtx().New("failed to add voting config exclusion",
+			"node", nodeName,
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+	return nil
+}
+
+// ClearVotingConfigExclusions returns the votes to all master eligible nodes once the
+// excluded ones left the cluster
+func (ec *esClient) ClearVotingConfigExclusions() error {
+	payload := &EsRequest{
+		Method: http.MethodDelete,
+		URI:    "_cluster/voting_config_exclusions",
+	}
+
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error != nil {
+		return payload.Error
+	}
+	if payload.StatusCode != http.StatusOK {
+		return ec.errorCtx().New("failed to clear voting config exclusions",
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+	return nil
+}
+
+// GetLicense returns the license of the cluster, or nil if the distribution does not
+// provide the license API, e.g. the OSS distribution
+func (ec *esClient) GetLicense() (*estypes.License, error) {
+	payload := &EsRequest{
+		Method: http.MethodGet,
+		URI:    "_license",
+	}
+
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error != nil {
+		return nil, payload.Error
+	}
+
+	switch payload.StatusCode {
+	case http.StatusOK:
+	case http.StatusBadRequest, http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, ec.errorCtx().New("failed to get license",
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody,
+		)
+	}
+
+	res := &estypes.LicenseResponse{}
+	err := json.Unmarshal([]byte(payload.RawResponseBody), res)
+	if err != nil {
+		return nil, ec.errorCtx().Wrap(err, "failed to decode raw response body into `estypes.LicenseResponse`")
+	}
+
+	return &res.License, nil
+}
@@ -1,8 +1,10 @@
 package esclient_test
 
 import (
+	"net/http"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/openshift/elasticsearch-operator/test/helpers"
 )
@@ -135,3 +137,172 @@ func TestIsNodeInCluster(t *testing.T) {
 		})
 	}
 }
+
+func TestVotingConfigExclusions(t *testing.T) {
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"_cluster/voting_config_exclusions?node_names=elasticsearch-cm-1": {
+			{StatusCode: 200, Body: `{}`},
+		},
+		"_cluster/voting_config_exclusions": {
+			{StatusCode: 200, Body: `{}`},
+			{StatusCode: 500, Body: `{"error": "timed out waiting for removal"}`},
+		},
+	})
+	esClient := helpers.NewFakeElasticsearchClient("elasticsearch", "test-namespace", fakeClient, chatter)
+
+	if err := esClient.AddVotingConfigExclusion("elasticsearch-cm-1"); err != nil {
+		t.Errorf("got err: %s", err)
+	}
+	req, ok := chatter.GetRequest("_cluster/voting_config_exclusions?node_names=elasticsearch-cm-1")
+	if !ok || req.Method != http.MethodPost {
+		t.Errorf("Exp. a POST of the voting config exclusion but got %v", req)
+	}
+
+	if err := esClient.ClearVotingConfigExclusions(); err != nil {
+		t.Errorf("got err: %s", err)
+	}
+	req, ok = chatter.GetRequest("_cluster/voting_config_exclusions")
+	if !ok || req.Method != http.MethodDelete {
+		t.Errorf("Exp. a DELETE of the voting config exclusions but got %v", req)
+	}
+
+	if err := esClient.ClearVotingConfigExclusions(); err == nil {
+		t.Error("Exp. an error if the exclusions are not cleared")
+	}
+}
+
+func TestGetPendingTasks(t *testing.T) {
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"_cluster/pending_tasks": {
+			{
+				StatusCode: 200,
+				Body:       `{"tasks": []}`,
+			},
+			{
+				StatusCode: 200,
+				Body:       `{"tasks": [{"insert_order": 101, "priority": "URGENT", "source": "create-index", "time_in_queue_millis": 86}, {"insert_order": 46, "priority": "HIGH", "source": "shard-started", "time_in_queue_millis": 842}]}`,
+			},
+		},
+	})
+	esClient := helpers.NewFakeElasticsearchClient("elasticsearch", "test-namespace", fakeClient, chatter)
+
+	tests := []struct {
+		desc       string
+		wantCount  int
+		wantOldest time.Duration
+	}{
+		{
+			desc: "no pending tasks",
+		},
+		{
+			desc:       "pending tasks",
+			wantCount:  2,
+			wantOldest: 842 * time.Millisecond,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			count, oldest, err := esClient.GetPendingTasks()
+			if err != nil {
+				t.Errorf("got err: %s", err)
+			}
+			if count != test.wantCount {
+				t.Errorf("got count %d, want %d", count, test.wantCount)
+			}
+			if oldest != test.wantOldest {
+				t.Errorf("got oldest %s, want %s", oldest, test.wantOldest)
+			}
+		})
+	}
+}
+
+func TestGetLicense(t *testing.T) {
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"_license": {
+			{
+				StatusCode: 200,
+				Body:       `{"license": {"status": "active", "uid": "893361dc-9749-4997-93cb-802e3d7fa4xx", "type": "platinum", "expiry_date_in_millis": 1914278399999}}`,
+			},
+			{
+				StatusCode: 400,
+				Body:       `{"error": "no handler found for uri [/_license] and method [GET]"}`,
+			},
+		},
+	})
+	esClient := helpers.NewFakeElasticsearchClient("elasticsearch", "test-namespace", fakeClient, chatter)
+
+	license, err := esClient.GetLicense()
+	if err != nil {
+		t.Errorf("got err: %s", err)
+	}
+	if license == nil || license.Type != "platinum" || license.Status != "active" || license.ExpiryDateInMillis != 1914278399999 {
+		t.Errorf("got license %v, want the active platinum license", license)
+	}
+
+	license, err = esClient.GetLicense()
+	if err != nil {
+		t.Errorf("got err: %s", err)
+	}
+	if license != nil {
+		t.Errorf("got license %v, want none for distributions without licensing", license)
+	}
+}
+
+func TestGetClusterSetting(t *testing.T) {
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"_cluster/settings?flat_settings=true": {
+			{StatusCode: 200, Body: `{"persistent": {"action.auto_create_index": "+app-*,-*"}, "transient": {}}`},
+			{StatusCode: 200, Body: `{"persistent": {"action.auto_create_index": "+app-*,-*"}, "transient": {"action.auto_create_index": "false"}}`},
+			{StatusCode: 200, Body: `{"persistent": {}, "transient": {}}`},
+			{StatusCode: 500, Body: `{"error": "internal server error"}`},
+		},
+	})
+	esClient := helpers.NewFakeElasticsearchClient("elasticsearch", "test-namespace", fakeClient, chatter)
+
+	tests := []struct {
+		desc  string
+		want  interface{}
+		isErr bool
+	}{
+		{desc: "persistent setting", want: "+app-*,-*"},
+		{desc: "transient setting ignored", want: "+app-*,-*"},
+		{desc: "unset setting", want: nil},
+		{desc: "failed request", isErr: true},
+	}
+	for _, test := range tests {
+		got, err := esClient.GetClusterSetting("action.auto_create_index")
+		if (err != nil) != test.isErr {
+			t.Errorf("%s: got err: %v, want error %t", test.desc, err, test.isErr)
+		}
+		if got != test.want {
+			t.Errorf("%s: got %v, want %v", test.desc, got, test.want)
+		}
+	}
+}
+
+func TestUpdateClusterSettings(t *testing.T) {
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"_cluster/settings": {
+			{StatusCode: 200, Body: `{"acknowledged": true, "persistent": {"action": {"auto_create_index": "+app-*,-*"}}}`},
+			{StatusCode: 200, Body: `{"acknowledged": false}`},
+		},
+	})
+	esClient := helpers.NewFakeElasticsearchClient("elasticsearch", "test-namespace", fakeClient, chatter)
+
+	if err := esClient.UpdateClusterSettings(map[string]interface{}{"action.auto_create_index": "+app-*,-*"}); err != nil {
+		t.Errorf("got err: %s", err)
+	}
+	req, ok := chatter.GetRequest("_cluster/settings")
+	if !ok || req.Method != http.MethodPut {
+		t.Errorf("Exp. a PUT of the cluster settings but got %v", req)
+	}
+	if req.Body != `{"persistent":{"action.auto_create_index":"+app-*,-*"}}` {
+		t.Errorf("got body %s, want the persistent setting", req.Body)
+	}
+
+	if err := esClient.UpdateClusterSettings(map[string]interface{}{"action.auto_create_index": "false"}); err == nil {
+		t.Error("Exp. an error if the settings are not acknowledged")
+	}
+}
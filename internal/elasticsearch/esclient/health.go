@@ -1,9 +1,13 @@
 package esclient
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"time"
 
 	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/metrics"
 )
 
 func (ec *esClient) GetClusterHealth() (api.ClusterHealth, error) {
@@ -20,19 +24,99 @@ func (ec *esClient) GetClusterHealth() (api.ClusterHealth, error) {
 		return clusterHealth, payload.Error
 	}
 
-	clusterHealth.Status = parseString("status", payload.ResponseBody)
-	clusterHealth.NumNodes = parseInt32("number_of_nodes", payload.ResponseBody)
-	clusterHealth.NumDataNodes = parseInt32("number_of_data_nodes", payload.ResponseBody)
-	clusterHealth.ActivePrimaryShards = parseInt32("active_primary_shards", payload.ResponseBody)
-	clusterHealth.ActiveShards = parseInt32("active_shards", payload.ResponseBody)
-	clusterHealth.RelocatingShards = parseInt32("relocating_shards", payload.ResponseBody)
-	clusterHealth.InitializingShards = parseInt32("initializing_shards", payload.ResponseBody)
-	clusterHealth.UnassignedShards = parseInt32("unassigned_shards", payload.ResponseBody)
-	clusterHealth.PendingTasks = parseInt32("number_of_pending_tasks", payload.ResponseBody)
+	return parseClusterHealth(payload.ResponseBody), nil
+}
+
+// WaitForClusterHealth lets Elasticsearch wait until the cluster health is at least minStatus
+// or the wait elapsed and returns the health at that time. The wait is capped by the deadline
+// of the context. An error is returned if the cluster did not reach the status in time.
+func (ec *esClient) WaitForClusterHealth(ctx context.Context, minStatus string, wait time.Duration) (api.ClusterHealth, error) {
+	defer metrics.ObserveWait(time.Now())
+
+	if err := ctx.Err(); err != nil {
+		return api.ClusterHealth{}, ec.errorCtx().Wrap(err, "failed to wait for cluster health")
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); remaining < wait {
+			wait = remaining
+		}
+	}
+
+	payload := &EsRequest{
+		Method: http.MethodGet,
+		URI:    fmt.Sprintf("_cluster/health?wait_for_status=%s&timeout=%ds", minStatus, int(wait.Seconds())),
+	}
+
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+
+	if payload.Error != nil {
+		return api.ClusterHealth{}, payload.Error
+	}
+
+	// Elasticsearch responds with request timeout and the current health if the wait elapsed
+	if payload.StatusCode != http.StatusOK && payload.StatusCode != http.StatusRequestTimeout {
+		return api.ClusterHealth{}, ec.errorCtx().New("failed to get cluster health",
+			"response_code", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+
+	clusterHealth := parseClusterHealth(payload.ResponseBody)
+
+	if timedOut, ok := payload.ResponseBody["timed_out"].(bool); ok && timedOut {
+		return clusterHealth, ec.errorCtx().New("timed out waiting for cluster health",
+			"status", clusterHealth.Status,
+			"desired_status", minStatus,
+			"wait", wait.String())
+	}
 
 	return clusterHealth, nil
 }
 
+// masterTimeout bounds the wait of Elasticsearch for an elected master when checking for one
+const masterTimeout = "5s"
+
+// HasElectedMaster returns whether the cluster has an elected master. Without one Elasticsearch
+// responds with service unavailable once the master timeout elapsed. An error is returned if
+// the cluster could not be asked, i.e. whether a master is elected is unknown.
+func (ec *esClient) HasElectedMaster() (bool, error) {
+	payload := &EsRequest{
+		Method: http.MethodGet,
+		URI:    fmt.Sprintf("_cluster/health?master_timeout=%s", masterTimeout),
+	}
+
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+
+	if payload.Error != nil {
+		return false, payload.Error
+	}
+
+	switch payload.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusServiceUnavailable:
+		return false, nil
+	default:
+		return false, ec.errorCtx().New("failed to check for an elected master",
+			"response_code", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+}
+
+func parseClusterHealth(body map[string]interface{}) api.ClusterHealth {
+	return api.ClusterHealth{
+		Status:              parseString("status", body),
+		NumNodes:            parseInt32("number_of_nodes", body),
+		NumDataNodes:        parseInt32("number_of_data_nodes", body),
+		ActivePrimaryShards: parseInt32("active_primary_shards", body),
+		ActiveShards:        parseInt32("active_shards", body),
+		RelocatingShards:    parseInt32("relocating_shards", body),
+		InitializingShards:  parseInt32("initializing_shards", body),
+		UnassignedShards:    parseInt32("unassigned_shards", body),
+		PendingTasks:        parseInt32("number_of_pending_tasks", body),
+	}
+}
+
 func (ec *esClient) GetClusterHealthStatus() (string, error) {
 	payload := &EsRequest{
 		Method: http.MethodGet,
@@ -0,0 +1,108 @@
+package esclient_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/openshift/elasticsearch-operator/test/helpers"
+)
+
+func TestWaitForClusterHealth(t *testing.T) {
+	tests := []struct {
+		desc       string
+		statusCode int
+		body       string
+		wantStatus string
+		wantErr    bool
+	}{
+		{
+			desc:       "status reached",
+			statusCode: 200,
+			body:       `{"status": "yellow", "timed_out": false, "number_of_nodes": 3}`,
+			wantStatus: "yellow",
+		},
+		{
+			desc:       "wait timed out",
+			statusCode: 408,
+			body:       `{"status": "red", "timed_out": true, "number_of_nodes": 2}`,
+			wantStatus: "red",
+			wantErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+				"_cluster/health?wait_for_status=yellow&timeout=10s": {
+					{
+						StatusCode: test.statusCode,
+						Body:       test.body,
+					},
+				},
+			})
+			esClient := helpers.NewFakeElasticsearchClient("elasticsearch", "test-namespace", k8sClient, chatter)
+
+			got, err := esClient.WaitForClusterHealth(context.TODO(), "yellow", 10*time.Second)
+			if (err != nil) != test.wantErr {
+				t.Errorf("got err: %v, want err: %t", err, test.wantErr)
+			}
+			if got.Status != test.wantStatus {
+				t.Errorf("got status %q, want %q", got.Status, test.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHasElectedMaster(t *testing.T) {
+	tests := []struct {
+		desc       string
+		statusCode int
+		body       string
+		want       bool
+		wantErr    bool
+	}{
+		{
+			desc:       "master elected",
+			statusCode: 200,
+			body:       `{"status": "green", "number_of_nodes": 3}`,
+			want:       true,
+		},
+		{
+			desc:       "no master elected",
+			statusCode: 503,
+			body:       `{"error": {"type": "master_not_discovered_exception"}, "status": 503}`,
+			want:       false,
+		},
+		{
+			desc:       "unexpected response",
+			statusCode: 401,
+			body:       `{"error": "unauthorized"}`,
+			wantErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+				"_cluster/health?master_timeout=5s": {
+					{
+						StatusCode: test.statusCode,
+						Body:       test.body,
+					},
+				},
+			})
+			esClient := helpers.NewFakeElasticsearchClient("elasticsearch", "test-namespace", k8sClient, chatter)
+
+			got, err := esClient.HasElectedMaster()
+			if (err != nil) != test.wantErr {
+				t.Errorf("got err: %v, want err: %t", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("got elected master %t, want %t", got, test.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/ViaQ/logerr/kverrors"
@@ -12,6 +13,10 @@ import (
 	"github.com/openshift/elasticsearch-operator/internal/utils"
 )
 
+// indexDataTierSetting is the setting allocating the shards of an index to the nodes
+// with the matching data attribute
+const indexDataTierSetting = "index.routing.allocation.require.data"
+
 func (ec *esClient) GetIndex(name string) (*estypes.Index, error) {
 	payload := &EsRequest{
 		Method: http.MethodGet,
@@ -148,6 +153,151 @@ func (ec *esClient) UpdateIndexSettings(name string, settings *estypes.IndexSett
 	return nil
 }
 
+// SetIndexBlock enables or disables the given block on an index, e.g. to block writes
+// on the source index of a reindex
+func (ec *esClient) SetIndexBlock(name string, block estypes.IndexBlock, enabled bool) error {
+	if !isIndexBlock(block) {
+		return kverrors.New("unknown index block",
+			"index", name,
+			"block", block)
+	}
+
+	payload := &EsRequest{
+		Method:      http.MethodPut,
+		URI:         fmt.Sprintf("%s/_settings", name),
+		RequestBody: fmt.Sprintf("{%q:%t}", indexBlockSetting(block), enabled),
+	}
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error != nil {
+		return payload.Error
+	}
+	if payload.StatusCode != http.StatusOK || !parseBool("acknowledged", payload.ResponseBody) {
+		return ec.errorCtx().New("failed to set index block",
+			"index", name,
+			"block", block,
+			"enabled", enabled,
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+	return nil
+}
+
+// GetIndexBlocks returns whether each of the known blocks is enabled on an index
+func (ec *esClient) GetIndexBlocks(name string) (map[estypes.IndexBlock]bool, error) {
+	payload := &EsRequest{
+		Method: http.MethodGet,
+		URI:    fmt.Sprintf("%s/_settings/index.blocks.*?flat_settings=true", name),
+	}
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error != nil {
+		return nil, payload.Error
+	}
+	if payload.StatusCode != http.StatusOK {
+		return nil, ec.errorCtx().New("failed to get index blocks",
+			"index", name,
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+
+	// flat settings are returned as strings, e.g. {"<index>":{"settings":{"index.blocks.write":"true"}}}
+	response := map[string]struct {
+		Settings map[string]string `json:"settings"`
+	}{}
+	if err := json.Unmarshal([]byte(payload.RawResponseBody), &response); err != nil {
+		return nil, kverrors.Wrap(err, "failed to decode response body",
+			"destination_type", "index blocks",
+			"index", name)
+	}
+
+	blocks := map[estypes.IndexBlock]bool{}
+	settings := response[name].Settings
+	for _, block := range estypes.IndexBlocks {
+		value, ok := settings[indexBlockSetting(block)]
+		if !ok {
+			blocks[block] = false
+			continue
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, kverrors.Wrap(err, "failed to parse index block setting",
+				"index", name,
+				"block", block,
+				"value", value)
+		}
+		blocks[block] = enabled
+	}
+	return blocks, nil
+}
+
+// GetIndexDataTiers returns the data tier required for the shards of each index matching
+// the pattern, i.e. index.routing.allocation.require.data, empty for indices without one
+func (ec *esClient) GetIndexDataTiers(pattern string) (map[string]string, error) {
+	payload := &EsRequest{
+		Method: http.MethodGet,
+		URI:    fmt.Sprintf("%s/_settings/%s?flat_settings=true", pattern, indexDataTierSetting),
+	}
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error != nil {
+		return nil, payload.Error
+	}
+	if payload.StatusCode != http.StatusOK {
+		return nil, ec.errorCtx().New("failed to get index data tiers",
+			"pattern", pattern,
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+
+	response := map[string]struct {
+		Settings map[string]string `json:"settings"`
+	}{}
+	if err := json.Unmarshal([]byte(payload.RawResponseBody), &response); err != nil {
+		return nil, kverrors.Wrap(err, "failed to decode response body",
+			"destination_type", "index data tiers",
+			"pattern", pattern)
+	}
+
+	tiers := map[string]string{}
+	for index, settings := range response {
+		tiers[index] = settings.Settings[indexDataTierSetting]
+	}
+	return tiers, nil
+}
+
+// SetIndexDataTier requires the shards of the given indices to be allocated to nodes
+// of the data tier
+func (ec *esClient) SetIndexDataTier(name, tier string) error {
+	payload := &EsRequest{
+		Method:      http.MethodPut,
+		URI:         fmt.Sprintf("%s/_settings", name),
+		RequestBody: fmt.Sprintf("{%q:%q}", indexDataTierSetting, tier),
+	}
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error != nil {
+		return payload.Error
+	}
+	if payload.StatusCode != http.StatusOK || !parseBool("acknowledged", payload.ResponseBody) {
+		return ec.errorCtx().New("failed to set index data tier",
+			"index", name,
+			"tier", tier,
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+	return nil
+}
+
+func indexBlockSetting(block estypes.IndexBlock) string {
+	return fmt.Sprintf("index.blocks.%s", block)
+}
+
+func isIndexBlock(block estypes.IndexBlock) bool {
+	for _, b := range estypes.IndexBlocks {
+		if b == block {
+			return true
+		}
+	}
+	return false
+}
+
 func (ec *esClient) ReIndex(src, dst, script, lang string) error {
 	reIndex := estypes.ReIndex{
 		Source: estypes.IndexRef{Index: src},
@@ -1,8 +1,11 @@
 package esclient_test
 
 import (
+	"net/http"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	estypes "github.com/openshift/elasticsearch-operator/internal/types/elasticsearch"
 	testhelpers "github.com/openshift/elasticsearch-operator/test/helpers"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -349,3 +352,143 @@ func TestAliasOperationsNotNeeded(t *testing.T) {
 		t.Errorf("Expected creation of aliases to succeed")
 	}
 }
+
+func TestSetIndexBlock(t *testing.T) {
+	chatter := testhelpers.NewFakeElasticsearchChatter(
+		map[string]testhelpers.FakeElasticsearchResponses{
+			"app-000001/_settings": {
+				{
+					Error:      nil,
+					StatusCode: 200,
+					Body:       `{"acknowledged": true}`,
+				},
+			},
+		},
+	)
+	esClient := testhelpers.NewFakeElasticsearchClient("elasticsearch", "openshift-logging", fakeClient, chatter)
+
+	if err := esClient.SetIndexBlock("app-000001", estypes.IndexBlockWrite, true); err != nil {
+		t.Errorf("got err: %s, want nil", err)
+	}
+
+	req, _ := chatter.GetRequest("app-000001/_settings")
+	if req.Method != http.MethodPut {
+		t.Errorf("got method %q, want %q", req.Method, http.MethodPut)
+	}
+	if req.Body != `{"index.blocks.write":true}` {
+		t.Errorf("got body %s, want the write block enabled", req.Body)
+	}
+}
+
+func TestSetIndexBlockUnknownBlock(t *testing.T) {
+	chatter := testhelpers.NewFakeElasticsearchChatter(map[string]testhelpers.FakeElasticsearchResponses{})
+	esClient := testhelpers.NewFakeElasticsearchClient("elasticsearch", "openshift-logging", fakeClient, chatter)
+
+	if err := esClient.SetIndexBlock("app-000001", estypes.IndexBlock("read_only_allow_delete"), true); err == nil {
+		t.Error("got nil, want err for unknown block")
+	}
+	if len(chatter.Requests) != 0 {
+		t.Errorf("got %d requests, want none", len(chatter.Requests))
+	}
+}
+
+func TestGetIndexBlocks(t *testing.T) {
+	chatter := testhelpers.NewFakeElasticsearchChatter(
+		map[string]testhelpers.FakeElasticsearchResponses{
+			"app-000001/_settings/index.blocks.*?flat_settings=true": {
+				{
+					Error:      nil,
+					StatusCode: 200,
+					Body: `{
+                      "app-000001": {
+                          "settings": {
+                              "index.blocks.write": "true",
+                              "index.blocks.read_only": "false"
+                          }
+                      }
+                    }`,
+				},
+			},
+		},
+	)
+	esClient := testhelpers.NewFakeElasticsearchClient("elasticsearch", "openshift-logging", fakeClient, chatter)
+
+	blocks, err := esClient.GetIndexBlocks("app-000001")
+	if err != nil {
+		t.Fatalf("got err: %s, want nil", err)
+	}
+
+	want := map[estypes.IndexBlock]bool{
+		estypes.IndexBlockWrite:    true,
+		estypes.IndexBlockRead:     false,
+		estypes.IndexBlockMetadata: false,
+		estypes.IndexBlockReadOnly: false,
+	}
+	if diff := cmp.Diff(want, blocks); diff != "" {
+		t.Errorf("index blocks mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGetIndexDataTiers(t *testing.T) {
+	chatter := testhelpers.NewFakeElasticsearchChatter(
+		map[string]testhelpers.FakeElasticsearchResponses{
+			"app-*/_settings/index.routing.allocation.require.data?flat_settings=true": {
+				{
+					Error:      nil,
+					StatusCode: 200,
+					Body: `{
+                      "app-000001": {
+                          "settings": {
+                              "index.routing.allocation.require.data": "hot"
+                          }
+                      },
+                      "app-000002": {
+                          "settings": {}
+                      }
+                    }`,
+				},
+			},
+		},
+	)
+	esClient := testhelpers.NewFakeElasticsearchClient("elasticsearch", "openshift-logging", fakeClient, chatter)
+
+	tiers, err := esClient.GetIndexDataTiers("app-*")
+	if err != nil {
+		t.Fatalf("got err: %s, want nil", err)
+	}
+
+	want := map[string]string{
+		"app-000001": "hot",
+		"app-000002": "",
+	}
+	if diff := cmp.Diff(want, tiers); diff != "" {
+		t.Errorf("index data tiers mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSetIndexDataTier(t *testing.T) {
+	chatter := testhelpers.NewFakeElasticsearchChatter(
+		map[string]testhelpers.FakeElasticsearchResponses{
+			"app-000001/_settings": {
+				{
+					Error:      nil,
+					StatusCode: 200,
+					Body:       `{"acknowledged": true}`,
+				},
+			},
+		},
+	)
+	esClient := testhelpers.NewFakeElasticsearchClient("elasticsearch", "openshift-logging", fakeClient, chatter)
+
+	if err := esClient.SetIndexDataTier("app-000001", "warm"); err != nil {
+		t.Errorf("got err: %s, want nil", err)
+	}
+
+	req, _ := chatter.GetRequest("app-000001/_settings")
+	if req.Method != http.MethodPut {
+		t.Errorf("got method %q, want %q", req.Method, http.MethodPut)
+	}
+	if req.Body != `{"index.routing.allocation.require.data":"warm"}` {
+		t.Errorf("got body %s, want the warm tier required", req.Body)
+	}
+}
@@ -0,0 +1,54 @@
+package esclient
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// PutPipeline creates or replaces the ingest pipeline with the given JSON definition
+func (ec *esClient) PutPipeline(name, body string) error {
+	payload := &EsRequest{
+		Method:      http.MethodPut,
+		URI:         fmt.Sprintf("_ingest/pipeline/%s", name),
+		RequestBody: body,
+	}
+
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error != nil || (payload.StatusCode != 200 && payload.StatusCode != 201) {
+		return ec.errorCtx().New("failed to put ingest pipeline",
+			"pipeline", name,
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody,
+			"response_error", payload.Error,
+		)
+	}
+	return nil
+}
+
+// GetPipeline returns the definition of the ingest pipeline or nil if it does not exist
+func (ec *esClient) GetPipeline(name string) (map[string]interface{}, error) {
+	payload := &EsRequest{
+		Method: http.MethodGet,
+		URI:    fmt.Sprintf("_ingest/pipeline/%s", name),
+	}
+
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error == nil && payload.StatusCode == 404 {
+		return nil, nil
+	}
+
+	if payload.Error != nil || payload.StatusCode != 200 {
+		return nil, ec.errorCtx().New("failed to get ingest pipeline",
+			"pipeline", name,
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody,
+			"response_error", payload.Error,
+		)
+	}
+
+	definition, ok := payload.ResponseBody[name].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	return definition, nil
+}
@@ -0,0 +1,96 @@
+package esclient_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	testhelpers "github.com/openshift/elasticsearch-operator/test/helpers"
+)
+
+func TestPutPipeline(t *testing.T) {
+	body := `{"description":"test","processors":[{"set":{"field":"foo","value":"bar"}}]}`
+	chatter := testhelpers.NewFakeElasticsearchChatter(
+		map[string]testhelpers.FakeElasticsearchResponses{
+			"_ingest/pipeline/foo": {
+				{
+					StatusCode: http.StatusOK,
+					Body:       `{"acknowledged":true}`,
+				},
+			},
+		})
+	esClient := testhelpers.NewFakeElasticsearchClient(cluster, namespace, k8sClient, chatter)
+
+	if err := esClient.PutPipeline("foo", body); err != nil {
+		t.Errorf("Exp. no error but got %v", err)
+	}
+
+	req, ok := chatter.GetRequest("_ingest/pipeline/foo")
+	if !ok {
+		t.Fatal("Exp. the pipeline to be put")
+	}
+	if req.Method != http.MethodPut || req.Body != body {
+		t.Errorf("Exp. a PUT with the pipeline definition but got %s %s", req.Method, req.Body)
+	}
+}
+
+func TestPutPipelineWhenResponseNot200(t *testing.T) {
+	chatter := testhelpers.NewFakeElasticsearchChatter(
+		map[string]testhelpers.FakeElasticsearchResponses{
+			"_ingest/pipeline/foo": {
+				{
+					StatusCode: http.StatusBadRequest,
+					Body:       `{"error":{"type":"parse_exception"}}`,
+				},
+			},
+		})
+	esClient := testhelpers.NewFakeElasticsearchClient(cluster, namespace, k8sClient, chatter)
+
+	if esClient.PutPipeline("foo", "{}") == nil {
+		t.Error("Exp. to return an error but did not")
+	}
+}
+
+func TestGetPipeline(t *testing.T) {
+	chatter := testhelpers.NewFakeElasticsearchChatter(
+		map[string]testhelpers.FakeElasticsearchResponses{
+			"_ingest/pipeline/foo": {
+				{
+					StatusCode: http.StatusOK,
+					Body:       `{"foo":{"description":"test","processors":[]}}`,
+				},
+			},
+		})
+	esClient := testhelpers.NewFakeElasticsearchClient(cluster, namespace, k8sClient, chatter)
+
+	definition, err := esClient.GetPipeline("foo")
+	if err != nil {
+		t.Fatalf("Exp. no error but got %v", err)
+	}
+
+	want := map[string]interface{}{"description": "test", "processors": []interface{}{}}
+	if !reflect.DeepEqual(definition, want) {
+		t.Errorf("Exp. definition %v but got %v", want, definition)
+	}
+}
+
+func TestGetPipelineWhenMissing(t *testing.T) {
+	chatter := testhelpers.NewFakeElasticsearchChatter(
+		map[string]testhelpers.FakeElasticsearchResponses{
+			"_ingest/pipeline/foo": {
+				{
+					StatusCode: http.StatusNotFound,
+					Body:       `{}`,
+				},
+			},
+		})
+	esClient := testhelpers.NewFakeElasticsearchClient(cluster, namespace, k8sClient, chatter)
+
+	definition, err := esClient.GetPipeline("foo")
+	if err != nil {
+		t.Errorf("Exp. no error but got %v", err)
+	}
+	if definition != nil {
+		t.Errorf("Exp. no definition but got %v", definition)
+	}
+}
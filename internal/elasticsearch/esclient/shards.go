@@ -1,12 +1,18 @@
 package esclient
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	estypes "github.com/openshift/elasticsearch-operator/internal/types/elasticsearch"
 )
 
+const allocationExcludeNameSetting = "cluster.routing.allocation.exclude._name"
+
 func (ec *esClient) ClearTransientShardAllocation() (bool, error) {
 	payload := &EsRequest{
 		Method:      http.MethodPut,
@@ -75,3 +81,219 @@ func (ec *esClient) GetShardAllocation() (string, error) {
 
 	return allocationString, payload.Error
 }
+
+// ExcludeNodeFromAllocation relocates all shards off the nodes and prevents new ones
+// from being allocated to them, e.g. before the nodes are removed permanently. Nodes
+// excluded by others remain excluded.
+func (ec *esClient) ExcludeNodeFromAllocation(nodeNames ...string) (bool, error) {
+	excluded, err := ec.getAllocationExclusions()
+	if err != nil {
+		return false, ec.errorCtx().Wrap(err, "failed to exclude node from shard allocation",
+			"node", nodeNames)
+	}
+
+	changed := false
+	for _, name := range nodeNames {
+		if !containsString(excluded, name) {
+			excluded = append(excluded, name)
+			changed = true
+		}
+	}
+	if !changed {
+		return true, nil
+	}
+
+	if err := ec.updateAllocationExclusions(excluded); err != nil {
+		return false, ec.errorCtx().Wrap(err, "failed to exclude node from shard allocation",
+			"node", nodeNames)
+	}
+	return true, nil
+}
+
+// ClearAllocationExclusions allows shards to be allocated to the nodes again. Nodes
+// excluded by others remain excluded.
+func (ec *esClient) ClearAllocationExclusions(nodeNames ...string) (bool, error) {
+	excluded, err := ec.getAllocationExclusions()
+	if err != nil {
+		return false, ec.errorCtx().Wrap(err, "failed to clear shard allocation exclusions",
+			"node", nodeNames)
+	}
+
+	remaining := []string{}
+	for _, name := range excluded {
+		if !containsString(nodeNames, name) {
+			remaining = append(remaining, name)
+		}
+	}
+	if len(remaining) == len(excluded) {
+		return true, nil
+	}
+
+	if err := ec.updateAllocationExclusions(remaining); err != nil {
+		return false, ec.errorCtx().Wrap(err, "failed to clear shard allocation exclusions",
+			"node", nodeNames)
+	}
+	return true, nil
+}
+
+// getAllocationExclusions returns the names of the nodes excluded from shard allocation
+func (ec *esClient) getAllocationExclusions() ([]string, error) {
+	value, err := ec.GetClusterSetting(allocationExcludeNameSetting)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	if excluded, ok := value.(string); ok {
+		for _, name := range strings.Split(excluded, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names, nil
+}
+
+// updateAllocationExclusions sets the names of the nodes excluded from shard allocation,
+// resetting the setting if none are left
+func (ec *esClient) updateAllocationExclusions(names []string) error {
+	var value interface{}
+	if len(names) > 0 {
+		value = strings.Join(names, ",")
+	}
+	return ec.UpdateClusterSettings(map[string]interface{}{allocationExcludeNameSetting: value})
+}
+
+func containsString(values []string, value string) bool {
+	for _, current := range values {
+		if current == value {
+			return true
+		}
+	}
+	return false
+}
+
+// GetNodeShardCount returns the number of shards allocated to the node. A node
+// unknown to the cluster holds no shards.
+func (ec *esClient) GetNodeShardCount(nodeName string) (int32, error) {
+	payload := &EsRequest{
+		Method: http.MethodGet,
+		URI:    "_cat/allocation?format=json&h=node,shards",
+	}
+
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error != nil {
+		return -1, payload.Error
+	}
+	if payload.StatusCode != http.StatusOK {
+		return -1, ec.errorCtx().New("failed to get shard allocation",
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+
+	res := estypes.CatAllocationResponses{}
+	if err := json.Unmarshal([]byte(payload.RawResponseBody), &res); err != nil {
+		return -1, ec.errorCtx().Wrap(err, "failed to parse _cat/allocation response body")
+	}
+
+	for _, allocation := range res {
+		if allocation.Node != nodeName {
+			continue
+		}
+
+		count, err := strconv.ParseInt(allocation.Shards, 10, 32)
+		if err != nil {
+			return -1, ec.errorCtx().Wrap(err, "failed to parse shard count",
+				"node", nodeName,
+				"shards", allocation.Shards)
+		}
+		return int32(count), nil
+	}
+
+	return 0, nil
+}
+
+// GetShardsOnNode returns the shards allocated to the node, including those being
+// relocated off it. A node holding no shards has drained.
+func (ec *esClient) GetShardsOnNode(nodeName string) ([]estypes.CatShardsResponse, error) {
+	payload := &EsRequest{
+		Method: http.MethodGet,
+		URI:    "_cat/shards?format=json&h=index,shard,prirep,state,node",
+	}
+
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error != nil {
+		return nil, payload.Error
+	}
+	if payload.StatusCode != http.StatusOK {
+		return nil, ec.errorCtx().New("failed to get shards",
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+
+	res := estypes.CatShardsResponses{}
+	if err := json.Unmarshal([]byte(payload.RawResponseBody), &res); err != nil {
+		return nil, ec.errorCtx().Wrap(err, "failed to parse _cat/shards response body")
+	}
+
+	// shards being relocated are listed as "<source> -> <target ip> <target id> <target>"
+	relocating := nodeName + " -> "
+	shards := []estypes.CatShardsResponse{}
+	for _, shard := range res {
+		if shard.Node == nodeName || strings.HasPrefix(shard.Node, relocating) {
+			shards = append(shards, shard)
+		}
+	}
+
+	return shards, nil
+}
+
+// GetRelocatingShardCount returns the number of shards being relocated within the cluster
+func (ec *esClient) GetRelocatingShardCount() (int32, error) {
+	payload := &EsRequest{
+		Method: http.MethodGet,
+		URI:    "_cluster/health",
+	}
+
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error != nil {
+		return -1, payload.Error
+	}
+	if payload.StatusCode != http.StatusOK {
+		return -1, ec.errorCtx().New("failed to get cluster health",
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+
+	return parseInt32("relocating_shards", payload.ResponseBody), nil
+}
+
+// AllocationExplain explains why the first unassigned shard is not allocated. Returns nil
+// if no shard is unassigned.
+func (ec *esClient) AllocationExplain() (*estypes.AllocationExplainResponse, error) {
+	payload := &EsRequest{
+		Method: http.MethodGet,
+		URI:    "_cluster/allocation/explain",
+	}
+
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error != nil {
+		return nil, payload.Error
+	}
+	// Elasticsearch rejects the request if there is no unassigned shard to explain
+	if payload.StatusCode == http.StatusBadRequest {
+		return nil, nil
+	}
+	if payload.StatusCode != http.StatusOK {
+		return nil, ec.errorCtx().New("failed to explain shard allocation",
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+
+	res := &estypes.AllocationExplainResponse{}
+	if err := json.Unmarshal([]byte(payload.RawResponseBody), res); err != nil {
+		return nil, ec.errorCtx().Wrap(err, "failed to parse _cluster/allocation/explain response body")
+	}
+
+	return res, nil
+}
@@ -0,0 +1,246 @@
+package esclient_test
+
+import (
+	"testing"
+
+	"github.com/openshift/elasticsearch-operator/test/helpers"
+)
+
+func TestGetNodeShardCount(t *testing.T) {
+	tests := []struct {
+		desc string
+		body string
+		want int32
+	}{
+		{
+			desc: "node with shards",
+			body: `[{"node": "elasticsearch-cdm-1-deadbeef", "shards": "12"}, {"node": "elasticsearch-cdm-2-deadbeef", "shards": "3"}]`,
+			want: 3,
+		},
+		{
+			desc: "node unknown to the cluster",
+			body: `[{"node": "elasticsearch-cdm-1-deadbeef", "shards": "12"}]`,
+			want: 0,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+				"_cat/allocation?format=json&h=node,shards": {
+					{
+						StatusCode: 200,
+						Body:       test.body,
+					},
+				},
+			})
+			esClient := helpers.NewFakeElasticsearchClient("elasticsearch", "test-namespace", k8sClient, chatter)
+
+			got, err := esClient.GetNodeShardCount("elasticsearch-cdm-2-deadbeef")
+			if err != nil {
+				t.Errorf("got err: %s", err)
+			}
+			if got != test.want {
+				t.Errorf("got %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestAllocationExclusions(t *testing.T) {
+	tests := []struct {
+		desc     string
+		exclude  bool
+		excluded string
+		want     string
+	}{
+		{
+			desc:     "exclude next to the nodes excluded by others",
+			exclude:  true,
+			excluded: `"elasticsearch-cdm-1-deadbeef"`,
+			want:     `{"persistent":{"cluster.routing.allocation.exclude._name":"elasticsearch-cdm-1-deadbeef,elasticsearch-cdm-2-deadbeef"}}`,
+		},
+		{
+			desc:     "exclude without other exclusions",
+			exclude:  true,
+			excluded: "null",
+			want:     `{"persistent":{"cluster.routing.allocation.exclude._name":"elasticsearch-cdm-2-deadbeef"}}`,
+		},
+		{
+			desc:     "exclude an excluded node",
+			exclude:  true,
+			excluded: `"elasticsearch-cdm-2-deadbeef"`,
+		},
+		{
+			desc:     "clear keeping the nodes excluded by others",
+			excluded: `"elasticsearch-cdm-1-deadbeef,elasticsearch-cdm-2-deadbeef"`,
+			want:     `{"persistent":{"cluster.routing.allocation.exclude._name":"elasticsearch-cdm-1-deadbeef"}}`,
+		},
+		{
+			desc:     "clear the last exclusion",
+			excluded: `"elasticsearch-cdm-2-deadbeef"`,
+			want:     `{"persistent":{"cluster.routing.allocation.exclude._name":null}}`,
+		},
+		{
+			desc:     "clear a node not excluded",
+			excluded: `"elasticsearch-cdm-1-deadbeef"`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+				"_cluster/settings?flat_settings=true": {
+					{
+						StatusCode: 200,
+						Body:       `{"persistent": {"cluster.routing.allocation.exclude._name": ` + test.excluded + `}}`,
+					},
+				},
+				"_cluster/settings": {
+					{
+						StatusCode: 200,
+						Body:       `{"acknowledged": true}`,
+					},
+				},
+			})
+			esClient := helpers.NewFakeElasticsearchClient("elasticsearch", "test-namespace", k8sClient, chatter)
+
+			var ok bool
+			var err error
+			if test.exclude {
+				ok, err = esClient.ExcludeNodeFromAllocation("elasticsearch-cdm-2-deadbeef")
+			} else {
+				ok, err = esClient.ClearAllocationExclusions("elasticsearch-cdm-2-deadbeef")
+			}
+			if err != nil {
+				t.Errorf("got err: %s", err)
+			}
+			if !ok {
+				t.Error("expected the exclusions to be updated")
+			}
+
+			req, found := chatter.GetRequest("_cluster/settings")
+			if test.want == "" {
+				if found {
+					t.Errorf("expected no update but got %s", req.Body)
+				}
+				return
+			}
+			if !found || req.Body != test.want {
+				t.Errorf("got request %v, want body %s", req, test.want)
+			}
+		})
+	}
+}
+
+func TestGetShardsOnNode(t *testing.T) {
+	const uri = "_cat/shards?format=json&h=index,shard,prirep,state,node"
+
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		uri: {
+			{
+				StatusCode: 200,
+				Body: `[
+					{"index": "app-000001", "shard": "0", "prirep": "p", "state": "STARTED", "node": "elasticsearch-cdm-2-deadbeef"},
+					{"index": "app-000001", "shard": "0", "prirep": "r", "state": "RELOCATING", "node": "elasticsearch-cdm-1-deadbeef -> 10.128.2.11 Pe8dA1RbStK0 elasticsearch-cdm-3-deadbeef"},
+					{"index": "infra-000001", "shard": "0", "prirep": "p", "state": "STARTED", "node": "elasticsearch-cdm-1-deadbeef"},
+					{"index": "infra-000001", "shard": "0", "prirep": "r", "state": "UNASSIGNED"},
+					{"index": "infra-000001", "shard": "1", "prirep": "p", "state": "STARTED", "node": "elasticsearch-cdm-10-deadbeef"}
+				]`,
+			},
+			{
+				StatusCode: 200,
+				Body:       `[{"index": "app-000001", "shard": "0", "prirep": "p", "state": "STARTED", "node": "elasticsearch-cdm-2-deadbeef"}]`,
+			},
+		},
+	})
+	esClient := helpers.NewFakeElasticsearchClient("elasticsearch", "test-namespace", k8sClient, chatter)
+
+	shards, err := esClient.GetShardsOnNode("elasticsearch-cdm-1-deadbeef")
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if len(shards) != 2 || shards[0].State != "RELOCATING" || shards[1].Index != "infra-000001" {
+		t.Errorf("got %v, want the relocating and the started shard of the node", shards)
+	}
+
+	shards, err = esClient.GetShardsOnNode("elasticsearch-cdm-1-deadbeef")
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if len(shards) != 0 {
+		t.Errorf("got %v, want no shards on the drained node", shards)
+	}
+}
+
+func TestGetRelocatingShardCount(t *testing.T) {
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"_cluster/health": {
+			{
+				StatusCode: 200,
+				Body:       `{"cluster_name": "elasticsearch", "status": "green", "relocating_shards": 4}`,
+			},
+		},
+	})
+	esClient := helpers.NewFakeElasticsearchClient("elasticsearch", "test-namespace", k8sClient, chatter)
+
+	got, err := esClient.GetRelocatingShardCount()
+	if err != nil {
+		t.Errorf("got err: %s", err)
+	}
+	if got != 4 {
+		t.Errorf("got %d, want 4", got)
+	}
+}
+
+func TestAllocationExplain(t *testing.T) {
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"_cluster/allocation/explain": {
+			{
+				StatusCode: 200,
+				Body: `{
+					"index": "app-000001",
+					"shard": 0,
+					"primary": false,
+					"current_state": "unassigned",
+					"unassigned_info": {"reason": "NODE_LEFT", "last_allocation_status": "no_attempt"},
+					"can_allocate": "no",
+					"allocate_explanation": "cannot allocate because allocation is not permitted to any of the nodes",
+					"node_allocation_decisions": [
+						{
+							"node_name": "elasticsearch-cdm-1-deadbeef",
+							"node_decision": "no",
+							"deciders": [{"decider": "disk_threshold", "decision": "NO", "explanation": "the node is above the low watermark"}]
+						}
+					]
+				}`,
+			},
+			{
+				StatusCode: 400,
+				Body:       `{"error": {"type": "illegal_argument_exception", "reason": "unable to find any unassigned shards to explain"}, "status": 400}`,
+			},
+		},
+	})
+	esClient := helpers.NewFakeElasticsearchClient("elasticsearch", "test-namespace", k8sClient, chatter)
+
+	explanation, err := esClient.AllocationExplain()
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if explanation == nil || explanation.Index != "app-000001" || explanation.UnassignedInfo.Reason != "NODE_LEFT" {
+		t.Fatalf("got %v, want the explanation of the unassigned replica", explanation)
+	}
+	if len(explanation.NodeAllocationDecisions) != 1 || explanation.NodeAllocationDecisions[0].Deciders[0].Decider != "disk_threshold" {
+		t.Errorf("got %v, want the disk threshold decision", explanation.NodeAllocationDecisions)
+	}
+
+	explanation, err = esClient.AllocationExplain()
+	if err != nil {
+		t.Errorf("got err: %s", err)
+	}
+	if explanation != nil {
+		t.Errorf("got %v, want no explanation without unassigned shards", explanation)
+	}
+}
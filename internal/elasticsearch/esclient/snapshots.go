@@ -0,0 +1,39 @@
+package esclient
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/openshift/elasticsearch-operator/internal/metrics"
+)
+
+// CreateSnapshot takes a snapshot of all indices into the given repository and waits
+// for it to complete. A snapshot that already exists with the same name is considered
+// as successfully taken.
+func (ec *esClient) CreateSnapshot(repository, name string) error {
+	defer metrics.ObserveWait(time.Now())
+
+	payload := &EsRequest{
+		Method: http.MethodPut,
+		URI:    fmt.Sprintf("_snapshot/%s/%s?wait_for_completion=true", repository, name),
+	}
+
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+
+	if payload.Error == nil && payload.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	if payload.StatusCode == http.StatusBadRequest &&
+		parseString("error.type", payload.ResponseBody) == "invalid_snapshot_name_exception" {
+		return nil
+	}
+
+	return ec.errorCtx().New("failed to create snapshot",
+		"repository", repository,
+		"snapshot", name,
+		"response_status", payload.StatusCode,
+		"response_body", payload.ResponseBody,
+		"response_error", payload.Error)
+}
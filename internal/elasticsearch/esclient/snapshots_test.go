@@ -0,0 +1,59 @@
+package esclient_test
+
+import (
+	"net/http"
+	"testing"
+
+	testhelpers "github.com/openshift/elasticsearch-operator/test/helpers"
+)
+
+func TestCreateSnapshotWhenResponse200(t *testing.T) {
+	chatter := testhelpers.NewFakeElasticsearchChatter(
+		map[string]testhelpers.FakeElasticsearchResponses{
+			"_snapshot/backup/elasticsearch-final?wait_for_completion=true": {
+				{
+					StatusCode: http.StatusOK,
+					Body:       `{"snapshot": {"snapshot": "elasticsearch-final", "state": "SUCCESS"}}`,
+				},
+			},
+		})
+	esClient := testhelpers.NewFakeElasticsearchClient(cluster, namespace, k8sClient, chatter)
+
+	if err := esClient.CreateSnapshot("backup", "elasticsearch-final"); err != nil {
+		t.Errorf("Exp. to not return an error but got: %v", err)
+	}
+}
+
+func TestCreateSnapshotWhenAlreadyExists(t *testing.T) {
+	chatter := testhelpers.NewFakeElasticsearchChatter(
+		map[string]testhelpers.FakeElasticsearchResponses{
+			"_snapshot/backup/elasticsearch-final?wait_for_completion=true": {
+				{
+					StatusCode: http.StatusBadRequest,
+					Body:       `{"error": {"type": "invalid_snapshot_name_exception"}, "status": 400}`,
+				},
+			},
+		})
+	esClient := testhelpers.NewFakeElasticsearchClient(cluster, namespace, k8sClient, chatter)
+
+	if err := esClient.CreateSnapshot("backup", "elasticsearch-final"); err != nil {
+		t.Errorf("Exp. to not return an error but got: %v", err)
+	}
+}
+
+func TestCreateSnapshotWhenMissingRepository(t *testing.T) {
+	chatter := testhelpers.NewFakeElasticsearchChatter(
+		map[string]testhelpers.FakeElasticsearchResponses{
+			"_snapshot/backup/elasticsearch-final?wait_for_completion=true": {
+				{
+					StatusCode: http.StatusNotFound,
+					Body:       `{"error": {"type": "repository_missing_exception"}, "status": 404}`,
+				},
+			},
+		})
+	esClient := testhelpers.NewFakeElasticsearchClient(cluster, namespace, k8sClient, chatter)
+
+	if esClient.CreateSnapshot("backup", "elasticsearch-final") == nil {
+		t.Error("Exp. to return an error but did not")
+	}
+}
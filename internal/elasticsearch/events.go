@@ -0,0 +1,112 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ViaQ/logerr/log"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// desiredTemplateHashAnnotation holds the hash of the pod template last applied by the operator
+	desiredTemplateHashAnnotation = "elasticsearch.openshift.io/desired-template-hash"
+
+	eventReasonResourceReverted      = "ResourceReverted"
+	eventReasonServiceRecreated      = "ServiceRecreated"
+	eventReasonNodeRecreated         = "NodeRecreated"
+	eventReasonInsufficientQuota     = "InsufficientQuota"
+	eventReasonNodePruned            = "NodePruned"
+	eventReasonNodeRepaused          = "NodeRepaused"
+	eventReasonConfigChanged         = "ConfigChanged"
+	eventReasonNodeDraining          = "NodeDraining"
+	eventReasonNodeDrained           = "NodeDrained"
+	eventReasonNodeRemovalRefused    = "NodeRemovalRefused"
+	eventReasonShardsUnassigned      = "ShardsUnassigned"
+	eventReasonConfigVersionMismatch = "ConfigVersionMismatch"
+	eventReasonPodsUnschedulable     = "PodsUnschedulable"
+	eventReasonReconcileStepFailed   = "ReconcileStepFailed"
+	eventReasonMasterTransition      = "MasterTransition"
+	eventReasonSysctlsForbidden      = "SysctlsForbidden"
+	eventReasonLicenseExpiring       = "LicenseExpiring"
+	eventReasonClusterNameConflict   = "ClusterNameConflict"
+	eventReasonServiceReadiness      = "ServiceReadinessOverridden"
+	eventReasonRestrictedPodSecurity = "RestrictedPodSecurity"
+)
+
+// recordEvent emits an event for the object if a recorder is available
+func recordEvent(recorder record.EventRecorder, object runtime.Object, eventType, reason, message string) {
+	if recorder == nil {
+		return
+	}
+
+	recorder.Event(object, eventType, reason, message)
+}
+
+// recordResourceReverted emits a warning event listing the pod template fields
+// of a manually modified node resource the operator overwrote
+func recordResourceReverted(recorder record.EventRecorder, object runtime.Object, kind, name string, fields []string) {
+	if len(fields) == 0 {
+		return
+	}
+
+	message := fmt.Sprintf("Reverted manual changes to %s %q, overwritten fields: %s", kind, name, strings.Join(fields, ", "))
+	log.Info(message)
+	recordEvent(recorder, object, v1.EventTypeWarning, eventReasonResourceReverted, message)
+}
+
+// podTemplateHash returns the hash of the pod template as desired by the operator
+func podTemplateHash(template v1.PodTemplateSpec) string {
+	text, err := utils.ToJSON(template)
+	if err != nil {
+		return ""
+	}
+
+	hash, err := utils.CalculateMD5Hash(text)
+	if err != nil {
+		return ""
+	}
+
+	return hash
+}
+
+// appliedTemplateHash returns the hash recorded once the desired pod template was rolled
+// out. It covers the live pod template too, so that manual changes of the node still
+// compare as changed.
+func appliedTemplateHash(desiredHash string, live v1.PodTemplateSpec) string {
+	if desiredHash == "" {
+		return ""
+	}
+
+	hash, err := utils.CalculateMD5Hash(desiredHash + podTemplateHash(live))
+	if err != nil {
+		return ""
+	}
+
+	return hash
+}
+
+// setDesiredTemplateHash stores the hash of the desired pod template within the annotations
+func setDesiredTemplateHash(annotations map[string]string, template v1.PodTemplateSpec) map[string]string {
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	annotations[desiredTemplateHashAnnotation] = podTemplateHash(template)
+	return annotations
+}
+
+// isManuallyModified returns true if the desired pod template did not change since the
+// operator last applied it. Any differences to the current pod template were thus not
+// introduced by the operator.
+func isManuallyModified(currentAnnotations map[string]string, desired v1.PodTemplateSpec) bool {
+	hash, ok := currentAnnotations[desiredTemplateHashAnnotation]
+	if !ok || hash == "" {
+		return false
+	}
+
+	return hash == podTemplateHash(desired)
+}
@@ -0,0 +1,126 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ViaQ/logerr/kverrors"
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	estypes "github.com/openshift/elasticsearch-operator/internal/types/elasticsearch"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// licenseExpiringReason is the reason of the LicenseExpiring condition, reporting the license
+// of the cluster expiring within the lead time or having expired
+const licenseExpiringReason = "License Expiring"
+
+// DefaultLicenseExpiryWarning is the time before the license of a cluster expires it is
+// reported with the LicenseExpiring condition
+const DefaultLicenseExpiryWarning = 14 * 24 * time.Hour
+
+const licenseStatusExpired = "expired"
+
+var licenseExpiryWarning = DefaultLicenseExpiryWarning
+
+// SetLicenseExpiryWarning sets the time before the license of a cluster expires it is
+// reported, e.g. to leave enough time to renew it
+func SetLicenseExpiryWarning(lead time.Duration) {
+	licenseExpiryWarning = lead
+}
+
+// newLicenseStatus returns the status of the license, or nil for distributions without
+// licensing
+func newLicenseStatus(license *estypes.License) *api.ElasticsearchLicenseStatus {
+	if license == nil {
+		return nil
+	}
+
+	status := &api.ElasticsearchLicenseStatus{
+		Type:   license.Type,
+		Status: license.Status,
+	}
+	if license.ExpiryDateInMillis > 0 {
+		expiry := metav1.NewTime(time.Unix(license.ExpiryDateInMillis/1000, 0).UTC())
+		status.ExpiryDate = &expiry
+	}
+	return status
+}
+
+// isLicenseStatusSame compares two license statuses, the expiry dates by their instant
+func isLicenseStatusSame(lhs, rhs *api.ElasticsearchLicenseStatus) bool {
+	if lhs == nil || rhs == nil {
+		return lhs == rhs
+	}
+	if lhs.Type != rhs.Type || lhs.Status != rhs.Status {
+		return false
+	}
+	if lhs.ExpiryDate == nil || rhs.ExpiryDate == nil {
+		return lhs.ExpiryDate == rhs.ExpiryDate
+	}
+	return lhs.ExpiryDate.Equal(rhs.ExpiryDate)
+}
+
+// getLicenseExpiringMessage returns why the license needs attention, i.e. it expired or
+// expires within the lead time, or an empty string if it does not
+func getLicenseExpiringMessage(license *api.ElasticsearchLicenseStatus, now time.Time, lead time.Duration) string {
+	if license == nil {
+		return ""
+	}
+
+	if license.ExpiryDate == nil {
+		if license.Status == licenseStatusExpired {
+			return fmt.Sprintf("The %s license expired", license.Type)
+		}
+		return ""
+	}
+
+	expiry := license.ExpiryDate.Time
+	if license.Status == licenseStatusExpired || !now.Before(expiry) {
+		return fmt.Sprintf("The %s license expired at %s", license.Type, expiry.UTC().Format(time.RFC3339))
+	}
+	if expiry.Sub(now) <= lead {
+		return fmt.Sprintf("The %s license expires at %s. Please renew it to keep its features available", license.Type, expiry.UTC().Format(time.RFC3339))
+	}
+	return ""
+}
+
+// UpdateLicenseStatus reports the license of the cluster in the status and sets the
+// LicenseExpiring condition, with a warning event whenever its message changes, if it
+// expires within the lead time. Distributions without licensing are reported without one.
+func (er *ElasticsearchRequest) UpdateLicenseStatus() error {
+	cluster := er.cluster
+	if !er.AnyNodeReady() {
+		return nil
+	}
+
+	license, err := er.esClient.GetLicense()
+	if err != nil {
+		return kverrors.Wrap(err, "failed to get license")
+	}
+
+	status := newLicenseStatus(license)
+	if err := updateConditionWithRetry(cluster, v1.ConditionTrue, func(clusterStatus *api.ElasticsearchStatus, _ v1.ConditionStatus) bool {
+		if isLicenseStatusSame(clusterStatus.License, status) {
+			return false
+		}
+		clusterStatus.License = status
+		return true
+	}, er.client); err != nil {
+		return kverrors.Wrap(err, "failed to update license status")
+	}
+
+	message := getLicenseExpiringMessage(status, time.Now(), licenseExpiryWarning)
+	if message == "" {
+		return updateCondition(cluster, api.LicenseExpiring, licenseExpiringReason, v1.ConditionFalse, "", er.client)
+	}
+
+	if _, condition := getESNodeCondition(cluster.Status.Conditions, api.LicenseExpiring); condition != nil &&
+		condition.Status == v1.ConditionTrue && condition.Message == message {
+		return nil
+	}
+
+	er.L().Info(message)
+	recordEvent(er.recorder, cluster, v1.EventTypeWarning, eventReasonLicenseExpiring, message)
+	return updateCondition(cluster, api.LicenseExpiring, licenseExpiringReason, v1.ConditionTrue, message, er.client)
+}
@@ -0,0 +1,130 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/test/helpers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetLicenseExpiringMessage(t *testing.T) {
+	now := time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)
+	expiresIn := func(d time.Duration) *metav1.Time {
+		expiry := metav1.NewTime(now.Add(d))
+		return &expiry
+	}
+
+	tests := []struct {
+		desc    string
+		license *loggingv1.ElasticsearchLicenseStatus
+		message string
+	}{
+		{
+			desc: "no licensing",
+		},
+		{
+			desc:    "license not expiring",
+			license: &loggingv1.ElasticsearchLicenseStatus{Type: "basic", Status: "active"},
+		},
+		{
+			desc:    "license expiring after the lead time",
+			license: &loggingv1.ElasticsearchLicenseStatus{Type: "platinum", Status: "active", ExpiryDate: expiresIn(30 * 24 * time.Hour)},
+		},
+		{
+			desc:    "license expiring within the lead time",
+			license: &loggingv1.ElasticsearchLicenseStatus{Type: "platinum", Status: "active", ExpiryDate: expiresIn(24 * time.Hour)},
+			message: "The platinum license expires at 2021-03-02T12:00:00Z",
+		},
+		{
+			desc:    "license expired",
+			license: &loggingv1.ElasticsearchLicenseStatus{Type: "trial", Status: "expired", ExpiryDate: expiresIn(-time.Hour)},
+			message: "The trial license expired at 2021-03-01T11:00:00Z",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			message := getLicenseExpiringMessage(test.license, now, DefaultLicenseExpiryWarning)
+			if !strings.HasPrefix(message, test.message) || (test.message == "") != (message == "") {
+				t.Errorf("Exp. message %q but got %q", test.message, message)
+			}
+		})
+	}
+}
+
+func TestUpdateLicenseStatus(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch",
+			Namespace: "openshift-logging",
+		},
+	}
+	ready := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch-cdm-1",
+			Namespace: cluster.Namespace,
+			Labels: map[string]string{
+				"component":    "elasticsearch",
+				"cluster-name": cluster.Name,
+				"es-node-data": "true",
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{Ready: true}},
+		},
+	}
+	k8sClient := fake.NewFakeClient(cluster, ready)
+	recorder := record.NewFakeRecorder(2)
+
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"_license": {
+			{StatusCode: http.StatusOK, Body: fmt.Sprintf(`{"license": {"status": "active", "type": "trial", "expiry_date_in_millis": %d}}`, time.Now().Add(time.Hour).Unix()*1000)},
+			{StatusCode: http.StatusBadRequest, Body: `{"error": "no handler found for uri [/_license] and method [GET]"}`},
+		},
+	})
+
+	er := &ElasticsearchRequest{
+		cluster:  cluster,
+		client:   k8sClient,
+		esClient: helpers.NewFakeElasticsearchClient(cluster.Name, cluster.Namespace, k8sClient, chatter),
+		recorder: recorder,
+	}
+
+	if err := er.UpdateLicenseStatus(); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+	if license := cluster.Status.License; license == nil || license.Type != "trial" || license.ExpiryDate == nil {
+		t.Errorf("Exp. the trial license to be reported but got %v", license)
+	}
+	_, condition := getESNodeCondition(cluster.Status.Conditions, loggingv1.LicenseExpiring)
+	if condition == nil || condition.Status != corev1.ConditionTrue || !strings.HasPrefix(condition.Message, "The trial license expires at") {
+		t.Errorf("Exp. the license expiring condition but got %v", condition)
+	}
+	if event := <-recorder.Events; !strings.HasPrefix(event, "Warning "+eventReasonLicenseExpiring) {
+		t.Errorf("Exp. a warning event for the expiring license but got %q", event)
+	}
+
+	// distributions without the license API are not applicable
+	if err := er.UpdateLicenseStatus(); err != nil {
+		t.Fatalf("Exp. no error without the license API but got %s", err)
+	}
+	if cluster.Status.License != nil {
+		t.Errorf("Exp. no license to be reported but got %v", cluster.Status.License)
+	}
+	if _, condition := getESNodeCondition(cluster.Status.Conditions, loggingv1.LicenseExpiring); condition != nil {
+		t.Errorf("Exp. the license expiring condition to be cleared but got %v", condition)
+	}
+}
@@ -0,0 +1,154 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"time"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// deferredOutsideWindowReason is the reason of the DeferredOutsideWindow condition, reporting
+// a node rollout waiting on the maintenance window to open
+const deferredOutsideWindowReason = "Outside Maintenance Window"
+
+// maintenanceWindowStartLayout is the layout of the time of the day a maintenance window opens at
+const maintenanceWindowStartLayout = "15:04"
+
+var weekdays = map[api.ElasticsearchWeekday]time.Weekday{
+	"Sunday":    time.Sunday,
+	"Monday":    time.Monday,
+	"Tuesday":   time.Tuesday,
+	"Wednesday": time.Wednesday,
+	"Thursday":  time.Thursday,
+	"Friday":    time.Friday,
+	"Saturday":  time.Saturday,
+}
+
+// getInvalidMaintenanceWindow returns the fields of the maintenance window which do not
+// describe a recurring window
+func getInvalidMaintenanceWindow(dpl *api.Elasticsearch) []string {
+	window := dpl.Spec.MaintenanceWindow
+	invalid := []string{}
+	if window == nil {
+		return invalid
+	}
+
+	if _, err := time.Parse(maintenanceWindowStartLayout, window.Start); err != nil {
+		invalid = append(invalid, fmt.Sprintf("start %q", window.Start))
+	}
+	if window.Duration.Duration <= 0 {
+		invalid = append(invalid, fmt.Sprintf("duration %s", window.Duration.Duration))
+	}
+	for _, day := range window.Days {
+		if _, ok := weekdays[day]; !ok {
+			invalid = append(invalid, fmt.Sprintf("day %q", day))
+		}
+	}
+	return invalid
+}
+
+// getMaintenanceWindowOpening returns the time the window opens at on the day the given
+// number of days after the one of the time, and whether it opens on that day at all
+func getMaintenanceWindowOpening(window *api.ElasticsearchMaintenanceWindow, now time.Time, days int) (time.Time, bool) {
+	start, err := time.Parse(maintenanceWindowStartLayout, window.Start)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	year, month, day := now.UTC().Date()
+	opening := time.Date(year, month, day+days, start.Hour(), start.Minute(), 0, 0, time.UTC)
+	if len(window.Days) == 0 {
+		return opening, true
+	}
+	for _, d := range window.Days {
+		if weekdays[d] == opening.Weekday() {
+			return opening, true
+		}
+	}
+	return opening, false
+}
+
+// isMaintenanceWindowOpen returns true if the window is open at the time. Since it opens at
+// least once a week, only the openings of the last week can still be open.
+func isMaintenanceWindowOpen(window *api.ElasticsearchMaintenanceWindow, now time.Time) bool {
+	for days := -7; days <= 0; days++ {
+		opening, ok := getMaintenanceWindowOpening(window, now, days)
+		if ok && !now.Before(opening) && now.Before(opening.Add(window.Duration.Duration)) {
+			return true
+		}
+	}
+	return false
+}
+
+// nextMaintenanceWindowOpening returns the time the window opens at next after the time
+func nextMaintenanceWindowOpening(window *api.ElasticsearchMaintenanceWindow, now time.Time) time.Time {
+	for days := 0; days <= 7; days++ {
+		if opening, ok := getMaintenanceWindowOpening(window, now, days); ok && opening.After(now) {
+			return opening
+		}
+	}
+	return now.Add(DefaultRequeueAfter)
+}
+
+// isRolloutInMaintenanceWindow returns true if the rollout or cert redeploy of the scheduled
+// nodes may start or continue at the time. Clusters with a maintenance window defer it until
+// the window opens and report it with the DeferredOutsideWindow condition. A rollout started
+// in the window completes after it closed, as long as the desired state of the nodes is the
+// same, see startWindowRollout.
+func (er *ElasticsearchRequest) isRolloutInMaintenanceWindow(scheduledNodes []NodeTypeInterface, now time.Time) bool {
+	dpl := er.cluster
+	window := dpl.Spec.MaintenanceWindow
+
+	if window == nil || len(scheduledNodes) == 0 {
+		er.clearDeferredOutsideWindow()
+		er.setWindowRolloutID("")
+		return true
+	}
+
+	id := er.desiredRolloutID()
+	started := isRolloutStarted(&dpl.Status) || (id != "" && dpl.Status.WindowRolloutID == id)
+	if started || isMaintenanceWindowOpen(window, now) {
+		er.clearDeferredOutsideWindow()
+		return true
+	}
+
+	opening := nextMaintenanceWindowOpening(window, now)
+	message := fmt.Sprintf("Rollout of %d nodes deferred until the maintenance window opens at %s", len(scheduledNodes), opening.Format(time.RFC3339))
+	er.L().Info("Node rollout waits on maintenance window", "opening", opening.Format(time.RFC3339))
+	if err := updateCondition(dpl, api.DeferredOutsideWindow, deferredOutsideWindowReason, v1.ConditionTrue, message, er.client); err != nil {
+		er.L().Error(err, "Unable to set deferred outside window condition")
+	}
+	return false
+}
+
+// startWindowRollout records the rollout starting in the maintenance window to let it
+// continue once the window closed
+func (er *ElasticsearchRequest) startWindowRollout() {
+	if er.cluster.Spec.MaintenanceWindow == nil {
+		return
+	}
+	er.setWindowRolloutID(er.desiredRolloutID())
+}
+
+func (er *ElasticsearchRequest) clearDeferredOutsideWindow() {
+	if err := updateCondition(er.cluster, api.DeferredOutsideWindow, deferredOutsideWindowReason, v1.ConditionFalse, "", er.client); err != nil {
+		er.L().Error(err, "Unable to clear deferred outside window condition")
+	}
+}
+
+func (er *ElasticsearchRequest) setWindowRolloutID(id string) {
+	if er.cluster.Status.WindowRolloutID == id {
+		return
+	}
+	err := updateConditionWithRetry(er.cluster, v1.ConditionTrue, func(status *api.ElasticsearchStatus, _ v1.ConditionStatus) bool {
+		if status.WindowRolloutID == id {
+			return false
+		}
+		status.WindowRolloutID = id
+		return true
+	}, er.client)
+	if err != nil {
+		er.L().Error(err, "Unable to record the rollout started in the maintenance window")
+	}
+}
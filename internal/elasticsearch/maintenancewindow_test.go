@@ -0,0 +1,179 @@
+package elasticsearch
+
+import (
+	"testing"
+	"time"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMaintenanceWindow(t *testing.T) {
+	// Saturday 22:00 for 4h, i.e. until Sunday 02:00
+	window := &loggingv1.ElasticsearchMaintenanceWindow{
+		Days:     []loggingv1.ElasticsearchWeekday{"Saturday"},
+		Start:    "22:00",
+		Duration: metav1.Duration{Duration: 4 * time.Hour},
+	}
+	nextOpening := time.Date(2021, time.June, 12, 22, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		desc string
+		now  time.Time
+		open bool
+		next time.Time
+	}{
+		{
+			desc: "before the window opens",
+			now:  time.Date(2021, time.June, 5, 21, 59, 0, 0, time.UTC),
+			next: time.Date(2021, time.June, 5, 22, 0, 0, 0, time.UTC),
+		},
+		{
+			desc: "when the window opens",
+			now:  time.Date(2021, time.June, 5, 22, 0, 0, 0, time.UTC),
+			open: true,
+			next: nextOpening,
+		},
+		{
+			desc: "past midnight in the window",
+			now:  time.Date(2021, time.June, 6, 1, 30, 0, 0, time.UTC),
+			open: true,
+			next: nextOpening,
+		},
+		{
+			desc: "when the window closes",
+			now:  time.Date(2021, time.June, 6, 2, 0, 0, 0, time.UTC),
+			next: nextOpening,
+		},
+		{
+			desc: "in another time zone",
+			now:  time.Date(2021, time.June, 6, 0, 30, 0, 0, time.FixedZone("CEST", 2*60*60)),
+			open: true,
+			next: nextOpening,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			if open := isMaintenanceWindowOpen(window, test.now); open != test.open {
+				t.Errorf("Exp. the window to be open %t but got %t", test.open, open)
+			}
+			if next := nextMaintenanceWindowOpening(window, test.now); !next.Equal(test.next) {
+				t.Errorf("Exp. the next opening %s but got %s", test.next, next)
+			}
+		})
+	}
+
+	daily := &loggingv1.ElasticsearchMaintenanceWindow{
+		Start:    "03:00",
+		Duration: metav1.Duration{Duration: time.Hour},
+	}
+	now := time.Date(2021, time.June, 8, 12, 0, 0, 0, time.UTC)
+	if next := nextMaintenanceWindowOpening(daily, now); !next.Equal(time.Date(2021, time.June, 9, 3, 0, 0, 0, time.UTC)) {
+		t.Errorf("Exp. a daily window to open the next day but got %s", next)
+	}
+}
+
+func TestGetInvalidMaintenanceWindow(t *testing.T) {
+	dpl := &loggingv1.Elasticsearch{
+		Spec: loggingv1.ElasticsearchSpec{
+			MaintenanceWindow: &loggingv1.ElasticsearchMaintenanceWindow{
+				Days:  []loggingv1.ElasticsearchWeekday{"Sunday", "Caturday"},
+				Start: "24:00",
+			},
+		},
+	}
+
+	invalid := getInvalidMaintenanceWindow(dpl)
+	want := []string{`start "24:00"`, "duration 0s", `day "Caturday"`}
+	assertMessages(t, "invalid fields", want, invalid)
+
+	dpl.Spec.MaintenanceWindow = &loggingv1.ElasticsearchMaintenanceWindow{
+		Start:    "02:30",
+		Duration: metav1.Duration{Duration: 2 * time.Hour},
+	}
+	if invalid := getInvalidMaintenanceWindow(dpl); len(invalid) > 0 {
+		t.Errorf("Exp. a valid window but got %v", invalid)
+	}
+}
+
+func TestIsRolloutInMaintenanceWindow(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch", Namespace: "openshift-logging"},
+		Spec: loggingv1.ElasticsearchSpec{
+			MaintenanceWindow: &loggingv1.ElasticsearchMaintenanceWindow{
+				Start:    "02:00",
+				Duration: metav1.Duration{Duration: 2 * time.Hour},
+			},
+		},
+	}
+	k8sClient := fake.NewFakeClient(cluster)
+	er := &ElasticsearchRequest{
+		cluster: cluster,
+		client:  k8sClient,
+	}
+	scheduled := []NodeTypeInterface{
+		&deploymentNode{self: appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch-cdm-1", Namespace: "openshift-logging"}}},
+	}
+	outside := time.Date(2021, time.June, 8, 12, 0, 0, 0, time.UTC)
+	inside := time.Date(2021, time.June, 9, 2, 30, 0, 0, time.UTC)
+
+	if er.isRolloutInMaintenanceWindow(scheduled, outside) {
+		t.Error("Exp. the rollout to be deferred outside of the window")
+	}
+	_, condition := getESNodeCondition(cluster.Status.Conditions, loggingv1.DeferredOutsideWindow)
+	if condition == nil || condition.Status != corev1.ConditionTrue {
+		t.Fatalf("Exp. the deferred rollout to be reported but got %v", condition)
+	}
+	if want := "Rollout of 1 nodes deferred until the maintenance window opens at 2021-06-09T02:00:00Z"; condition.Message != want {
+		t.Errorf("Exp. message %q but got %q", want, condition.Message)
+	}
+
+	if outcome := OutcomeOf(cluster, nil); outcome.Reason != RequeueWaitingForMaintenanceWindow || outcome.RequeueAfter <= 0 {
+		t.Errorf("Exp. the reconcile to wait on the window but got %v", outcome)
+	}
+
+	if !er.isRolloutInMaintenanceWindow(nil, outside) {
+		t.Error("Exp. no rollout to be deferred without scheduled nodes")
+	}
+	if _, condition := getESNodeCondition(cluster.Status.Conditions, loggingv1.DeferredOutsideWindow); condition != nil {
+		t.Errorf("Exp. the condition to be cleared without scheduled nodes but got %v", condition)
+	}
+
+	if !er.isRolloutInMaintenanceWindow(scheduled, inside) {
+		t.Error("Exp. the rollout to start in the window")
+	}
+	er.startWindowRollout()
+	if !er.isRolloutInMaintenanceWindow(scheduled, outside) {
+		t.Error("Exp. the rollout started in the window to complete after it closed")
+	}
+
+	cluster.Status.ConfigHash = "changed"
+	if er.isRolloutInMaintenanceWindow(scheduled, outside) {
+		t.Error("Exp. a rollout of another desired state to be deferred")
+	}
+	cluster.Status.Nodes = []loggingv1.ElasticsearchNodeStatus{
+		{
+			DeploymentName: "elasticsearch-cdm-1",
+			UpgradeStatus:  loggingv1.ElasticsearchNodeUpgradeStatus{ScheduledForUpgrade: corev1.ConditionTrue},
+		},
+	}
+	if outcome := OutcomeOf(cluster, nil); outcome.Reason != RequeueWaitingForMaintenanceWindow {
+		t.Errorf("Exp. the scheduled rollout to wait on the window but got %v", outcome)
+	}
+
+	cluster.Status.Nodes[0].UpgradeStatus.UnderUpgrade = corev1.ConditionTrue
+	if !er.isRolloutInMaintenanceWindow(scheduled, outside) {
+		t.Error("Exp. a node update under way to complete outside of the window")
+	}
+	if outcome := OutcomeOf(cluster, nil); outcome.Reason != RequeueRolloutInProgress {
+		t.Errorf("Exp. the started rollout to be tracked but got %v", outcome)
+	}
+}
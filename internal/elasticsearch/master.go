@@ -0,0 +1,56 @@
+package elasticsearch
+
+import (
+	"github.com/ViaQ/logerr/kverrors"
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// noMasterElectedReason is the reason of the NoMasterElected condition
+const noMasterElectedReason = "Master Not Discovered"
+
+// updateNoMasterElected checks whether the cluster has an elected master and sets the
+// NoMasterElected condition accordingly. Returns true if no master is elected. Clusters
+// without ready nodes, e.g. on bootstrap, or which cannot be asked are not reported.
+func (er *ElasticsearchRequest) updateNoMasterElected() bool {
+	noMaster := false
+	if er.AnyNodeReady() {
+		elected, err := er.esClient.HasElectedMaster()
+		if err != nil {
+			er.L().Info("Unable to check for an elected master", "error", err)
+		}
+		noMaster = err == nil && !elected
+	}
+
+	if !noMaster {
+		if err := updateCondition(er.cluster, api.NoMasterElected, noMasterElectedReason, v1.ConditionFalse, "", er.client); err != nil {
+			er.L().Error(err, "Unable to clear no master elected condition")
+		}
+		return false
+	}
+
+	message := "The cluster has no elected master. Node rollouts and removals are paused until a master is elected"
+	er.L().Info(message)
+	if err := updateCondition(er.cluster, api.NoMasterElected, noMasterElectedReason, v1.ConditionTrue, message, er.client); err != nil {
+		er.L().Error(err, "Unable to set no master elected condition")
+	}
+	return true
+}
+
+// createMissingNodes creates the nodes whose resources are missing without progressing any
+// other change, e.g. to recover master nodes while no master is elected
+func (er *ElasticsearchRequest) createMissingNodes() error {
+	for _, node := range nodes[nodeMapKey(er.cluster.Name, er.cluster.Namespace)] {
+		if !node.isMissing() {
+			continue
+		}
+
+		if err := node.create(); err != nil && !IsRequeue(err) {
+			return kverrors.Wrap(err, "failed to create missing node",
+				"node", node.name(),
+			)
+		}
+	}
+
+	return nil
+}
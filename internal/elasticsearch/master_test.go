@@ -0,0 +1,82 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/test/helpers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestUpdateNoMasterElected(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+
+	const (
+		esCluster   = "elasticsearch"
+		esNamespace = "openshift-logging"
+	)
+
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      esCluster,
+			Namespace: esNamespace,
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch-cdm-1-deadbeef-1",
+			Namespace: esNamespace,
+			Labels: map[string]string{
+				"component":      "elasticsearch",
+				"cluster-name":   esCluster,
+				"es-node-master": "true",
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "elasticsearch", Ready: true},
+			},
+		},
+	}
+
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"_cluster/health?master_timeout=5s": {
+			{
+				StatusCode: 503,
+				Body:       `{"error": {"type": "master_not_discovered_exception"}, "status": 503}`,
+			},
+			{
+				StatusCode: 200,
+				Body:       `{"cluster_name": "elasticsearch", "status": "green"}`,
+			},
+		},
+	})
+
+	k8sClient := fake.NewFakeClient(cluster.DeepCopy(), pod)
+	er := &ElasticsearchRequest{
+		cluster:  cluster,
+		client:   k8sClient,
+		esClient: helpers.NewFakeElasticsearchClient(esCluster, esNamespace, k8sClient, chatter),
+	}
+
+	if !er.updateNoMasterElected() {
+		t.Fatal("Exp. rollouts to be paused while no master is elected")
+	}
+
+	_, condition := getESNodeCondition(cluster.Status.Conditions, loggingv1.NoMasterElected)
+	if condition == nil || condition.Status != corev1.ConditionTrue {
+		t.Fatalf("Exp. the NoMasterElected condition to be true but got %v", cluster.Status.Conditions)
+	}
+
+	if er.updateNoMasterElected() {
+		t.Error("Exp. rollouts to resume once a master is elected")
+	}
+
+	if _, condition := getESNodeCondition(cluster.Status.Conditions, loggingv1.NoMasterElected); condition != nil {
+		t.Errorf("Exp. the NoMasterElected condition to be removed but got %v", condition)
+	}
+}
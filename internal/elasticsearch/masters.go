@@ -0,0 +1,231 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/statefulset"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// getExistingMasterCount returns the number of master nodes run by the existing nodes
+func getExistingMasterCount(clusterNodes []NodeTypeInterface) int32 {
+	count := int32(0)
+	for _, node := range clusterNodes {
+		if !node.isMaster() || node.isMissing() {
+			continue
+		}
+
+		n, ok := node.(*statefulSetNode)
+		if !ok {
+			count++
+			continue
+		}
+		key := client.ObjectKey{Name: n.name(), Namespace: n.self.Namespace}
+		if sts, err := statefulset.Get(context.TODO(), n.client, key); err == nil && sts.Spec.Replicas != nil {
+			count += *sts.Spec.Replicas
+		}
+	}
+	return count
+}
+
+// existingMasterCount returns the number of master nodes run by the tracked nodes of the
+// cluster. It is read once per reconcile and read again only after master nodes were
+// created or removed.
+func (er *ElasticsearchRequest) existingMasterCount() int32 {
+	if er.existingMasters == nil {
+		count := getExistingMasterCount(nodes[nodeMapKey(er.cluster.Name, er.cluster.Namespace)])
+		er.existingMasters = &count
+	}
+	return *er.existingMasters
+}
+
+// resetExistingMasterCount makes the next existingMasterCount read the master nodes again
+func (er *ElasticsearchRequest) resetExistingMasterCount() {
+	er.existingMasters = nil
+}
+
+// getMasterQuorumCount returns the number of master nodes the quorum is computed of. While
+// the master set grows or shrinks one node at a time, it is the number of existing master
+// nodes, so that the quorum follows each step. Only clusters without any fall back to the
+// desired count.
+func (er *ElasticsearchRequest) getMasterQuorumCount() int32 {
+	if existing := er.existingMasterCount(); existing > 0 {
+		return existing
+	}
+	return getMasterCount(er.cluster)
+}
+
+// getRenderedMasterCount returns the number of master nodes the quorum in the rendered
+// configuration is computed of. A change of the master count is rendered only once it
+// completed, keeping the count the cluster ran before, so that the nodes restarted
+// meanwhile do not wait for masters which do not exist yet and are not restarted at each
+// step.
+func (er *ElasticsearchRequest) getRenderedMasterCount() int32 {
+	if transition := er.cluster.Status.MasterTransition; transition != nil && transition.Initial > 0 {
+		return transition.Initial
+	}
+	if existing := er.existingMasterCount(); existing > 0 {
+		return existing
+	}
+	return getMasterCount(er.cluster)
+}
+
+// isGrowingMasters returns true if master nodes are missing while others exist, i.e. the
+// master set of a formed cluster grows. The nodes of a new cluster are created at once to
+// bootstrap it.
+func isGrowingMasters(clusterNodes []NodeTypeInterface) bool {
+	existing, missing := false, false
+	for _, node := range clusterNodes {
+		if !node.isMaster() {
+			continue
+		}
+		if node.isMissing() {
+			missing = true
+		} else {
+			existing = true
+		}
+	}
+	return existing && missing
+}
+
+// waitToAddMasterNode signals to requeue the reconcile if the missing master node of a
+// growing master set has to wait, since a master node was added by this reconcile already
+// or an existing one has not joined the cluster yet. Otherwise the quorum is raised to the
+// joined master nodes before the next one is added.
+func (er *ElasticsearchRequest) waitToAddMasterNode(node NodeTypeInterface, clusterNodes []NodeTypeInterface, added bool) error {
+	if added {
+		return newRequeueError(RequeueWaitingForNodes, "master nodes are added one at a time",
+			"node", node.name(),
+		)
+	}
+
+	existing := []NodeTypeInterface{}
+	for _, n := range clusterNodes {
+		if n.isMaster() && !n.isMissing() {
+			existing = append(existing, n)
+		}
+	}
+	if err := er.waitForMasterNodes(existing); err != nil {
+		return err
+	}
+
+	er.updateMinMasters()
+	er.L().Info("Adding master node", "node", node.name(), "masters", er.existingMasterCount()+1, "desired", getMasterCount(er.cluster))
+	return nil
+}
+
+// stepMasterReplicas changes the replicas of a master statefulset of a formed cluster by one
+// node towards the desired count, as the master deployments are added and removed one at a
+// time. Returns true if it stepped, or signals to requeue the reconcile while the step has to
+// wait since master nodes were changed by this reconcile already or the nodes of the last
+// step are not ready yet. The quorum is raised to the joined master nodes before a node is
+// added and lowered to the remaining ones before a node is removed.
+func (er *ElasticsearchRequest) stepMasterReplicas(node *statefulSetNode, clusterNodes []NodeTypeInterface, changed bool) (bool, error) {
+	if node.autoscaled {
+		return false, nil
+	}
+
+	key := client.ObjectKey{Name: node.name(), Namespace: node.self.Namespace}
+	sts, err := statefulset.Get(context.TODO(), node.client, key)
+	if err != nil || sts.Spec.Replicas == nil {
+		return false, nil
+	}
+
+	// scaled down statefulsets are scaled up again by the restart they are part of
+	current, desired := *sts.Spec.Replicas, node.replicas
+	if current == desired || current == 0 {
+		return false, nil
+	}
+
+	// keep the replicas the statefulset runs until the step is taken
+	next := current
+	node.self.Spec.Replicas = &next
+
+	if changed {
+		return false, newRequeueError(RequeueWaitingForNodes, "master nodes are changed one at a time",
+			"node", node.name(),
+		)
+	}
+	if sts.Status.ReadyReplicas < current {
+		return false, newRequeueError(RequeueWaitingForNodes, "master nodes of the statefulset are not ready yet",
+			"node", node.name(),
+		)
+	}
+
+	if desired > current {
+		others := []NodeTypeInterface{}
+		for _, n := range clusterNodes {
+			if n != NodeTypeInterface(node) && n.isMaster() && !n.isMissing() {
+				others = append(others, n)
+			}
+		}
+		if err := er.waitForMasterNodes(others); err != nil {
+			return false, err
+		}
+
+		er.updateMinMasters()
+		next = current + 1
+	} else {
+		er.setMinMasters(er.existingMasterCount() - 1)
+		next = current - 1
+	}
+
+	er.L().Info("Changing master nodes of statefulset", "node", node.name(), "replicas", next, "desired", desired)
+	if err := node.setReplicaCount(next); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// updateMasterTransition reports the master set growing or shrinking towards the desired
+// master count in the status, keeping the time and the count it started with, and clears
+// it once reached
+func (er *ElasticsearchRequest) updateMasterTransition() {
+	cluster := er.cluster
+	current := er.existingMasterCount()
+	desired := getMasterCount(cluster)
+
+	var transition *api.ElasticsearchMasterTransition
+	if current > 0 && current != desired {
+		transition = &api.ElasticsearchMasterTransition{
+			Current: current,
+			Desired: desired,
+			Initial: current,
+			Since:   metav1.Now(),
+		}
+		previous := cluster.Status.MasterTransition
+		if previous != nil && previous.Initial > 0 {
+			// the rendered configuration still holds the count the first change started with
+			transition.Initial = previous.Initial
+		}
+		if previous != nil && previous.Desired == desired {
+			transition.Since = previous.Since
+		} else {
+			message := fmt.Sprintf("Changing master nodes from %d to %d one node at a time", current, desired)
+			er.L().Info(message)
+			recordEvent(er.recorder, cluster, v1.EventTypeNormal, eventReasonMasterTransition, message)
+		}
+	}
+
+	if err := updateConditionWithRetry(cluster, v1.ConditionTrue, func(status *api.ElasticsearchStatus, _ v1.ConditionStatus) bool {
+		if transition == nil {
+			if status.MasterTransition == nil {
+				return false
+			}
+			status.MasterTransition = nil
+			return true
+		}
+
+		if previous := status.MasterTransition; previous != nil && previous.Current == transition.Current && previous.Desired == transition.Desired && previous.Initial == transition.Initial {
+			return false
+		}
+		status.MasterTransition = transition
+		return true
+	}, er.client); err != nil {
+		er.L().Error(err, "Unable to update master transition status")
+	}
+}
@@ -0,0 +1,337 @@
+package elasticsearch
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/deployment"
+	"github.com/openshift/elasticsearch-operator/test/helpers"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	mastersCluster   = "elasticsearch"
+	mastersNamespace = "openshift-logging"
+)
+
+func newMasterDeployment(name string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: mastersNamespace,
+			Labels: map[string]string{
+				"cluster-name":   mastersCluster,
+				"es-node-master": "true",
+			},
+		},
+	}
+}
+
+func newReadyMasterPod(name string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: mastersNamespace,
+			Labels: map[string]string{
+				"component":      "elasticsearch",
+				"cluster-name":   mastersCluster,
+				"es-node-master": "true",
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{Ready: true}},
+		},
+	}
+}
+
+func TestPopulateNodesRemovesMasterNodesOneAtATime(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        mastersCluster,
+			Namespace:   mastersNamespace,
+			Annotations: map[string]string{forceNodeRemovalAnnotation: "true"},
+		},
+	}
+	first := newMasterDeployment("elasticsearch-cdm-old-1")
+	second := newMasterDeployment("elasticsearch-cdm-old-2")
+	k8sClient := fake.NewFakeClient(cluster, first, second, newReadyMasterPod("elasticsearch-cdm-old-2-abc"))
+
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"_cluster/stats/nodes/_all": {
+			{StatusCode: http.StatusOK, Body: `{"nodes": {"versions": ["6.8.1"]}}`},
+			{StatusCode: http.StatusOK, Body: `{"nodes": {"versions": ["6.8.1"]}}`},
+		},
+		"_cluster/health": {
+			{StatusCode: http.StatusOK, Body: `{"number_of_nodes": 2}`},
+			{StatusCode: http.StatusOK, Body: `{"number_of_nodes": 1}`},
+		},
+		"_cluster/settings?flat_settings=true": {
+			{StatusCode: http.StatusOK, Body: `{"persistent": {"cluster.routing.allocation.exclude._name": "elasticsearch-cdm-old-1"}}`},
+			{StatusCode: http.StatusOK, Body: `{"persistent": {"cluster.routing.allocation.exclude._name": "elasticsearch-cdm-old-2"}}`},
+		},
+		"_cluster/settings": {
+			{StatusCode: http.StatusOK, Body: `{"persistent": {"discovery.zen.minimum_master_nodes": 2}}`},
+			{StatusCode: http.StatusOK, Body: `{"acknowledged": true}`},
+			{StatusCode: http.StatusOK, Body: `{"acknowledged": true}`},
+			{StatusCode: http.StatusOK, Body: `{"persistent": {"discovery.zen.minimum_master_nodes": 1}}`},
+			{StatusCode: http.StatusOK, Body: `{"acknowledged": true}`},
+		},
+	})
+
+	er := &ElasticsearchRequest{
+		cluster:  cluster,
+		client:   k8sClient,
+		esClient: helpers.NewFakeElasticsearchClient(mastersCluster, mastersNamespace, k8sClient, chatter),
+	}
+	key := nodeMapKey(mastersCluster, mastersNamespace)
+	nodes = map[string][]NodeTypeInterface{
+		key: {
+			&deploymentNode{self: *first, client: k8sClient},
+			&deploymentNode{self: *second, client: k8sClient},
+		},
+	}
+
+	if err := er.populateNodes(); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+	if len(nodes[key]) != 1 || nodes[key][0].name() != second.Name {
+		t.Fatalf("Exp. only %s to be kept until the next reconcile but got %v", second.Name, nodes[key])
+	}
+	if _, err := deployment.Get(context.TODO(), k8sClient, client.ObjectKey{Name: first.Name, Namespace: mastersNamespace}); err == nil {
+		t.Errorf("Exp. %s to be deleted", first.Name)
+	}
+
+	found := false
+	for _, request := range chatter.Requests["_cluster/settings"] {
+		if request.Method == http.MethodPut && strings.Contains(request.Body, `"discovery.zen.minimum_master_nodes":1`) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Exp. min masters to be lowered to the quorum of the remaining master but got %v", chatter.Requests["_cluster/settings"])
+	}
+
+	if err := er.populateNodes(); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+	if len(nodes[key]) != 0 {
+		t.Errorf("Exp. the last master node to be removed but got %v", nodes[key])
+	}
+}
+
+func TestWaitToAddMasterNode(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mastersCluster,
+			Namespace: mastersNamespace,
+		},
+	}
+	existing := newMasterDeployment("elasticsearch-cdm-1")
+	k8sClient := fake.NewFakeClient(cluster, existing)
+
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"_cluster/state/nodes": {
+			{StatusCode: http.StatusOK, Body: `{"nodes": {"a": {"name": "elasticsearch-cdm-1"}}}`},
+		},
+	})
+	esClient := helpers.NewFakeElasticsearchClient(mastersCluster, mastersNamespace, k8sClient, chatter)
+
+	er := &ElasticsearchRequest{
+		cluster:  cluster,
+		client:   k8sClient,
+		esClient: esClient,
+	}
+	added := &deploymentNode{self: *newMasterDeployment("elasticsearch-cdm-2"), client: k8sClient, esClient: esClient}
+	clusterNodes := []NodeTypeInterface{
+		&deploymentNode{self: *existing, client: k8sClient, esClient: esClient},
+		added,
+	}
+	nodes = map[string][]NodeTypeInterface{
+		nodeMapKey(mastersCluster, mastersNamespace): clusterNodes,
+	}
+
+	if !isGrowingMasters(clusterNodes) {
+		t.Fatalf("Exp. the master set to grow")
+	}
+	if err := er.waitToAddMasterNode(added, clusterNodes, true); !IsRequeue(err) {
+		t.Errorf("Exp. a requeue once a master node was added but got %v", err)
+	}
+	if err := er.waitToAddMasterNode(added, clusterNodes, false); err != nil {
+		t.Errorf("Exp. the master node to be added once the existing one joined but got %s", err)
+	}
+}
+
+func newMasterStatefulSet(name string, replicas, ready int32) *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: mastersNamespace,
+			Labels: map[string]string{
+				"cluster-name":   mastersCluster,
+				"es-node-master": "true",
+			},
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{},
+			},
+		},
+		Status: appsv1.StatefulSetStatus{ReadyReplicas: ready},
+	}
+}
+
+func TestStepMasterReplicas(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+
+	tests := []struct {
+		desc    string
+		live    int32
+		ready   int32
+		desired int32
+		changed bool
+		stepped bool
+		requeue bool
+		want    int32
+	}{
+		{desc: "grow", live: 3, ready: 3, desired: 5, stepped: true, want: 4},
+		{desc: "shrink", live: 5, ready: 5, desired: 3, stepped: true, want: 4},
+		{desc: "grow after another change", live: 3, ready: 3, desired: 5, changed: true, requeue: true, want: 3},
+		{desc: "shrink after another change", live: 5, ready: 5, desired: 3, changed: true, requeue: true, want: 5},
+		{desc: "previous step not ready", live: 4, ready: 3, desired: 5, requeue: true, want: 4},
+		{desc: "converged", live: 3, ready: 3, desired: 3, want: 3},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			cluster := &loggingv1.Elasticsearch{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      mastersCluster,
+					Namespace: mastersNamespace,
+				},
+			}
+			live := newMasterStatefulSet("elasticsearch-m-1", test.live, test.ready)
+			k8sClient := fake.NewFakeClient(cluster, live)
+			esClient := helpers.NewFakeElasticsearchClient(mastersCluster, mastersNamespace, k8sClient,
+				helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{}))
+
+			er := &ElasticsearchRequest{
+				cluster:  cluster,
+				client:   k8sClient,
+				esClient: esClient,
+			}
+			node := &statefulSetNode{
+				self:     *newMasterStatefulSet(live.Name, test.desired, 0),
+				replicas: test.desired,
+				client:   k8sClient,
+				esClient: esClient,
+			}
+			clusterNodes := []NodeTypeInterface{node}
+			nodes = map[string][]NodeTypeInterface{
+				nodeMapKey(mastersCluster, mastersNamespace): clusterNodes,
+			}
+
+			stepped, err := er.stepMasterReplicas(node, clusterNodes, test.changed)
+			if IsRequeue(err) != test.requeue {
+				t.Errorf("Exp. a requeue: %t but got %v", test.requeue, err)
+			}
+			if stepped != test.stepped {
+				t.Errorf("Exp. stepped: %t but got %t", test.stepped, stepped)
+			}
+
+			got := &appsv1.StatefulSet{}
+			if err := k8sClient.Get(context.TODO(), client.ObjectKey{Name: live.Name, Namespace: mastersNamespace}, got); err != nil {
+				t.Fatalf("failed with error: %s", err)
+			}
+			if *got.Spec.Replicas != test.want {
+				t.Errorf("Exp. the statefulset to run %d replicas but got %d", test.want, *got.Spec.Replicas)
+			}
+			if *node.self.Spec.Replicas != test.want {
+				t.Errorf("Exp. the node to keep %d replicas but got %d", test.want, *node.self.Spec.Replicas)
+			}
+		})
+	}
+}
+
+func TestUpdateMasterTransition(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mastersCluster,
+			Namespace: mastersNamespace,
+		},
+		Spec: loggingv1.ElasticsearchSpec{
+			Nodes: []loggingv1.ElasticsearchNode{
+				{Roles: []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleMaster, loggingv1.ElasticsearchRoleData}, NodeCount: 3},
+			},
+		},
+	}
+	existing := newMasterDeployment("elasticsearch-cdm-1")
+	k8sClient := fake.NewFakeClient(cluster, existing)
+	recorder := record.NewFakeRecorder(1)
+
+	er := &ElasticsearchRequest{
+		cluster:  cluster,
+		client:   k8sClient,
+		recorder: recorder,
+	}
+	nodes = map[string][]NodeTypeInterface{
+		nodeMapKey(mastersCluster, mastersNamespace): {
+			&deploymentNode{self: *existing, client: k8sClient},
+			&deploymentNode{self: *newMasterDeployment("elasticsearch-cdm-2"), client: k8sClient},
+		},
+	}
+
+	er.updateMasterTransition()
+
+	transition := cluster.Status.MasterTransition
+	if transition == nil || transition.Current != 1 || transition.Desired != 3 || transition.Initial != 1 {
+		t.Fatalf("Exp. the transition from 1 to 3 master nodes to be reported but got %v", transition)
+	}
+	if event := <-recorder.Events; !strings.HasPrefix(event, "Normal "+eventReasonMasterTransition) {
+		t.Errorf("Exp. an event for the master transition but got %q", event)
+	}
+
+	joined := newMasterDeployment("elasticsearch-cdm-2")
+	if err := k8sClient.Create(context.TODO(), joined); err != nil {
+		t.Fatalf("failed to create deployment: %s", err)
+	}
+	nodes[nodeMapKey(mastersCluster, mastersNamespace)][1] = &deploymentNode{self: *joined, client: k8sClient}
+	er.resetExistingMasterCount()
+	er.updateMasterTransition()
+
+	transition = cluster.Status.MasterTransition
+	if transition == nil || transition.Current != 2 || transition.Initial != 1 {
+		t.Fatalf("Exp. the transition to keep the initial master count but got %v", transition)
+	}
+	if got := er.getRenderedMasterCount(); got != 1 {
+		t.Errorf("Exp. the rendered quorum to keep 1 master node until the transition completes but got %d", got)
+	}
+
+	cluster.Spec.Nodes[0].NodeCount = 2
+	er.updateMasterTransition()
+
+	if cluster.Status.MasterTransition != nil {
+		t.Errorf("Exp. the transition to be cleared once reached but got %v", cluster.Status.MasterTransition)
+	}
+	if got := er.getRenderedMasterCount(); got != 2 {
+		t.Errorf("Exp. the rendered quorum to follow the master count once reached but got %d", got)
+	}
+}
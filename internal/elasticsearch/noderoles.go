@@ -0,0 +1,94 @@
+package elasticsearch
+
+import (
+	"strconv"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+)
+
+// NodeRoles are the roles of the nodes of a node group
+type NodeRoles struct {
+	client bool
+	data   bool
+	master bool
+	ingest bool
+}
+
+// newNodeRoles returns the node roles for the given list of roles, ignoring unknown ones
+func newNodeRoles(roles ...api.ElasticsearchNodeRole) NodeRoles {
+	nr := NodeRoles{}
+	for _, role := range roles {
+		switch role {
+		case api.ElasticsearchRoleClient:
+			nr.client = true
+		case api.ElasticsearchRoleData:
+			nr.data = true
+		case api.ElasticsearchRoleMaster:
+			nr.master = true
+		case api.ElasticsearchRoleIngest:
+			nr.ingest = true
+		}
+	}
+	return nr
+}
+
+// getNodeRoles returns the node roles of the given node group
+func getNodeRoles(node api.ElasticsearchNode) NodeRoles {
+	return newNodeRoles(node.Roles...)
+}
+
+// IsClient returns true if the nodes serve client requests
+func (nr NodeRoles) IsClient() bool {
+	return nr.client
+}
+
+// IsData returns true if the nodes hold shards
+func (nr NodeRoles) IsData() bool {
+	return nr.data
+}
+
+// IsMaster returns true if the nodes are master eligible
+func (nr NodeRoles) IsMaster() bool {
+	return nr.master
+}
+
+// IsIngest returns true if the nodes run the ingest pipelines
+func (nr NodeRoles) IsIngest() bool {
+	return nr.ingest
+}
+
+// IsMasterOnly returns true if the nodes are master eligible only
+func (nr NodeRoles) IsMasterOnly() bool {
+	return nr.master && !nr.data && !nr.client && !nr.ingest
+}
+
+// IsCoordinatingOnly returns true if the nodes only serve client requests
+func (nr NodeRoles) IsCoordinatingOnly() bool {
+	return nr.client && !nr.data && !nr.master && !nr.ingest
+}
+
+// suffix returns the node name suffix abbreviating the roles, e.g. cdm. The ingest role
+// is not part of it to keep the names of existing nodes stable.
+func (nr NodeRoles) suffix() string {
+	suffix := ""
+	if nr.client {
+		suffix += "c"
+	}
+	if nr.data {
+		suffix += "d"
+	}
+	if nr.master {
+		suffix += "m"
+	}
+	return suffix
+}
+
+// labels returns the role labels used to select the nodes. The ingest role is not part of
+// them since label selectors of existing nodes are immutable.
+func (nr NodeRoles) labels() map[string]string {
+	return map[string]string{
+		"es-node-client": strconv.FormatBool(nr.client),
+		"es-node-data":   strconv.FormatBool(nr.data),
+		"es-node-master": strconv.FormatBool(nr.master),
+	}
+}
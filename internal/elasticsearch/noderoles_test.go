@@ -0,0 +1,157 @@
+package elasticsearch
+
+import (
+	"reflect"
+	"testing"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+)
+
+func TestNodeRoles(t *testing.T) {
+	tests := []struct {
+		desc             string
+		roles            []loggingv1.ElasticsearchNodeRole
+		master           bool
+		data             bool
+		client           bool
+		ingest           bool
+		masterOnly       bool
+		coordinatingOnly bool
+		suffix           string
+	}{
+		{
+			desc:   "all roles",
+			roles:  []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleClient, loggingv1.ElasticsearchRoleData, loggingv1.ElasticsearchRoleMaster},
+			master: true,
+			data:   true,
+			client: true,
+			suffix: "cdm",
+		},
+		{
+			desc:       "master only",
+			roles:      []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleMaster},
+			master:     true,
+			masterOnly: true,
+			suffix:     "m",
+		},
+		{
+			desc:   "data and client",
+			roles:  []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleData, loggingv1.ElasticsearchRoleClient},
+			data:   true,
+			client: true,
+			suffix: "cd",
+		},
+		{
+			desc:             "coordinating only",
+			roles:            []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleClient},
+			client:           true,
+			coordinatingOnly: true,
+			suffix:           "c",
+		},
+		{
+			desc:   "ingest and client",
+			roles:  []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleIngest, loggingv1.ElasticsearchRoleClient},
+			client: true,
+			ingest: true,
+			suffix: "c",
+		},
+		{
+			desc:   "duplicated and unknown roles",
+			roles:  []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleData, loggingv1.ElasticsearchRoleData, "unknown"},
+			data:   true,
+			suffix: "d",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			roles := getNodeRoles(loggingv1.ElasticsearchNode{Roles: test.roles})
+
+			if roles.IsMaster() != test.master {
+				t.Errorf("expected IsMaster to be %t", test.master)
+			}
+			if roles.IsData() != test.data {
+				t.Errorf("expected IsData to be %t", test.data)
+			}
+			if roles.IsClient() != test.client {
+				t.Errorf("expected IsClient to be %t", test.client)
+			}
+			if roles.IsIngest() != test.ingest {
+				t.Errorf("expected IsIngest to be %t", test.ingest)
+			}
+			if roles.IsMasterOnly() != test.masterOnly {
+				t.Errorf("expected IsMasterOnly to be %t", test.masterOnly)
+			}
+			if roles.IsCoordinatingOnly() != test.coordinatingOnly {
+				t.Errorf("expected IsCoordinatingOnly to be %t", test.coordinatingOnly)
+			}
+			if got := roles.suffix(); got != test.suffix {
+				t.Errorf("expected suffix %q, got %q", test.suffix, got)
+			}
+		})
+	}
+}
+
+func TestNewLabelsFromNodeRoles(t *testing.T) {
+	roles := newNodeRoles(loggingv1.ElasticsearchRoleClient)
+
+	want := map[string]string{
+		"es-node-client": "true",
+		"es-node-data":   "false",
+		"es-node-master": "false",
+		"cluster-name":   "elasticsearch",
+		"node-name":      "elasticsearch-c-abc",
+	}
+	if got := newLabelSelector("elasticsearch", "elasticsearch-c-abc", roles); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected selector %v, got %v", want, got)
+	}
+
+	want["component"] = "elasticsearch"
+	if got := newLabels("elasticsearch", "elasticsearch-c-abc", roles); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected labels %v, got %v", want, got)
+	}
+}
+
+func TestSetIngest(t *testing.T) {
+	data := loggingv1.ElasticsearchNode{
+		Roles: []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleMaster, loggingv1.ElasticsearchRoleData},
+	}
+	ingest := loggingv1.ElasticsearchNode{
+		Roles: []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleIngest},
+	}
+
+	withoutIngest := &loggingv1.Elasticsearch{
+		Spec: loggingv1.ElasticsearchSpec{Nodes: []loggingv1.ElasticsearchNode{data}},
+	}
+	withIngest := &loggingv1.Elasticsearch{
+		Spec: loggingv1.ElasticsearchSpec{Nodes: []loggingv1.ElasticsearchNode{data, ingest}},
+	}
+
+	for _, test := range []struct {
+		desc    string
+		cluster *loggingv1.Elasticsearch
+		node    loggingv1.ElasticsearchNode
+		want    string
+	}{
+		{desc: "no dedicated ingest nodes", cluster: withoutIngest, node: data, want: ""},
+		{desc: "dedicated ingest node", cluster: withIngest, node: ingest, want: "true"},
+		{desc: "other node of a cluster with dedicated ingest nodes", cluster: withIngest, node: data, want: "false"},
+	} {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			template := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", test.node, loggingv1.ElasticsearchNodeSpec{}, map[string]string{}, getNodeRoles(test.node), nil, LogConfig{})
+			setIngest(&template, getNodeIngest(test.cluster, test.node))
+
+			got, found := "", false
+			for _, envVar := range template.Spec.Containers[0].Env {
+				if envVar.Name == ingestEnvVar {
+					got, found = envVar.Value, true
+				}
+			}
+			if found != (test.want != "") || got != test.want {
+				t.Errorf("Exp. ingest setting %q but got %q (set %t)", test.want, got, found)
+			}
+		})
+	}
+}
@@ -2,10 +2,17 @@ package elasticsearch
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/ViaQ/logerr/kverrors"
+	"github.com/go-logr/logr"
 	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
 	"github.com/openshift/elasticsearch-operator/internal/elasticsearch/esclient"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -13,10 +20,11 @@ import (
 type NodeTypeInterface interface {
 	state() api.ElasticsearchNodeStatus // this will get the current -- used for status
 	updateReference(node NodeTypeInterface)
-	populateReference(nodeName string, node api.ElasticsearchNode, cluster *api.Elasticsearch, roleMap map[api.ElasticsearchNodeRole]bool, replicas int32, client client.Client, esClient esclient.Client)
+	populateReference(nodeName string, node api.ElasticsearchNode, cluster *api.Elasticsearch, roles NodeRoles, replicas int32, client client.Client, esClient esclient.Client)
 
 	create() error // this will create the node in the case where it is new
 	isMissing() bool
+	isMaster() bool // this function is used to determine if the node is master eligible
 	name() string
 	delete() error
 	getSecretHash() string
@@ -36,10 +44,10 @@ type NodeTypeFactory func(name, namespace string) NodeTypeInterface
 func (er *ElasticsearchRequest) GetNodeTypeInterface(uuid string, node api.ElasticsearchNode) []NodeTypeInterface {
 	nodes := []NodeTypeInterface{}
 
-	roleMap := getNodeRoleMap(node)
+	roles := getNodeRoles(node)
 
 	// common spec => cluster.Spec.Spec
-	nodeName := fmt.Sprintf("%s-%s", er.cluster.Name, getNodeSuffix(uuid, roleMap))
+	nodeName := fmt.Sprintf("%s-%s", er.cluster.Name, getNodeSuffix(uuid, roles))
 
 	// if we have a data node then we need to create one deployment per replica
 	if isDataNode(node) {
@@ -47,32 +55,36 @@ func (er *ElasticsearchRequest) GetNodeTypeInterface(uuid string, node api.Elast
 		//   it is 1 instead of 0 because of legacy code
 		for replicaIndex := int32(1); replicaIndex <= node.NodeCount; replicaIndex++ {
 			dataNodeName := addDataNodeSuffix(nodeName, replicaIndex)
-			node := newDeploymentNode(dataNodeName, node, er.cluster, roleMap, er.client, er.esClient)
+			node := newDeploymentNode(dataNodeName, node, er.cluster, roles, er.client, er.esClient, er.recorder, er.L())
 			nodes = append(nodes, node)
 		}
 	} else {
-		node := newStatefulSetNode(nodeName, node, er.cluster, roleMap, er.client, er.esClient)
+		node := newStatefulSetNode(nodeName, node, er.cluster, roles, er.client, er.esClient, er.recorder, er.L())
 		nodes = append(nodes, node)
 	}
 
 	return nodes
 }
 
-func getNodeSuffix(uuid string, roleMap map[api.ElasticsearchNodeRole]bool) string {
-	suffix := ""
-	if roleMap[api.ElasticsearchRoleClient] {
-		suffix = fmt.Sprintf("%s%s", suffix, "c")
-	}
+// getGeneratedNodeNames returns the names of the deployments or statefulset
+// that GetNodeTypeInterface creates for the given node group
+func getGeneratedNodeNames(clusterName, uuid string, node api.ElasticsearchNode) []string {
+	nodeName := fmt.Sprintf("%s-%s", clusterName, getNodeSuffix(uuid, getNodeRoles(node)))
 
-	if roleMap[api.ElasticsearchRoleData] {
-		suffix = fmt.Sprintf("%s%s", suffix, "d")
+	if !isDataNode(node) {
+		return []string{nodeName}
 	}
 
-	if roleMap[api.ElasticsearchRoleMaster] {
-		suffix = fmt.Sprintf("%s%s", suffix, "m")
+	names := []string{}
+	for replicaIndex := int32(1); replicaIndex <= node.NodeCount; replicaIndex++ {
+		names = append(names, addDataNodeSuffix(nodeName, replicaIndex))
 	}
 
-	return fmt.Sprintf("%s-%s", suffix, uuid)
+	return names
+}
+
+func getNodeSuffix(uuid string, roles NodeRoles) string {
+	return fmt.Sprintf("%s-%s", roles.suffix(), uuid)
 }
 
 func addDataNodeSuffix(nodeName string, replicaNumber int32) string {
@@ -80,19 +92,24 @@ func addDataNodeSuffix(nodeName string, replicaNumber int32) string {
 }
 
 // newDeploymentNode constructs deploymentNode struct for data nodes
-func newDeploymentNode(nodeName string, node api.ElasticsearchNode, cluster *api.Elasticsearch, roleMap map[api.ElasticsearchNodeRole]bool, client client.Client, esClient esclient.Client) NodeTypeInterface {
-	deploymentNode := deploymentNode{}
+func newDeploymentNode(nodeName string, node api.ElasticsearchNode, cluster *api.Elasticsearch, roles NodeRoles, client client.Client, esClient esclient.Client, recorder record.EventRecorder, ll logr.Logger) NodeTypeInterface {
+	deploymentNode := deploymentNode{recorder: recorder, l: ll.WithValues("node", nodeName)}
 
-	deploymentNode.populateReference(nodeName, node, cluster, roleMap, int32(1), client, esClient)
+	deploymentNode.populateReference(nodeName, node, cluster, roles, int32(1), client, esClient)
 
 	return &deploymentNode
 }
 
 // newStatefulSetNode constructs statefulSetNode struct for non-data nodes
-func newStatefulSetNode(nodeName string, node api.ElasticsearchNode, cluster *api.Elasticsearch, roleMap map[api.ElasticsearchNodeRole]bool, client client.Client, esClient esclient.Client) NodeTypeInterface {
-	statefulSetNode := statefulSetNode{}
+func newStatefulSetNode(nodeName string, node api.ElasticsearchNode, cluster *api.Elasticsearch, roles NodeRoles, client client.Client, esClient esclient.Client, recorder record.EventRecorder, ll logr.Logger) NodeTypeInterface {
+	statefulSetNode := statefulSetNode{recorder: recorder, autoscaled: isAutoscaled(node), l: ll.WithValues("node", nodeName)}
 
-	statefulSetNode.populateReference(nodeName, node, cluster, roleMap, node.NodeCount, client, esClient)
+	// autoscaled nodes are created with the lower limit and then left to the autoscaler
+	replicas := node.NodeCount
+	if statefulSetNode.autoscaled {
+		replicas = autoscaleMinReplicas(node)
+	}
+	statefulSetNode.populateReference(nodeName, node, cluster, roles, replicas, client, esClient)
 
 	return &statefulSetNode
 }
@@ -117,3 +134,22 @@ func (er *ElasticsearchRequest) getNodeState(node NodeTypeInterface) *api.Elasti
 
 	return status
 }
+
+// missingObjectBackoff is the backoff retrying to get a node object before it is considered missing
+var missingObjectBackoff = wait.Backoff{
+	Steps:    4,
+	Duration: 100 * time.Millisecond,
+	Factor:   2.0,
+}
+
+// isObjectMissing returns true only if getting the node object fails with NotFound. Other
+// errors are retried briefly as the API server may be momentarily unreadable, e.g. while
+// many nodes restart at once, to avoid recreating objects which are still there. Objects
+// pending deletion are found and thus not missing either.
+func isObjectMissing(get func() error) bool {
+	err := retry.OnError(missingObjectBackoff, func(err error) bool {
+		return !apierrors.IsNotFound(kverrors.Root(err))
+	}, get)
+
+	return err != nil && apierrors.IsNotFound(kverrors.Root(err))
+}
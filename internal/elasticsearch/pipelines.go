@@ -0,0 +1,89 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ViaQ/logerr/kverrors"
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// CreateOrUpdateIngestPipelines provisions the ingest pipelines of the spec, putting those
+// missing or drifted from their definition. Invalid definitions are reported via the
+// InvalidIngestPipelines condition and skipped.
+func (er *ElasticsearchRequest) CreateOrUpdateIngestPipelines() error {
+	dpl := er.cluster
+
+	pipelines := map[string]map[string]interface{}{}
+	invalid := []string{}
+	for _, pipeline := range dpl.Spec.IngestPipelines {
+		if _, ok := pipelines[pipeline.Name]; ok {
+			invalid = append(invalid, fmt.Sprintf("%s (duplicate name)", pipeline.Name))
+			continue
+		}
+
+		definition, err := parsePipelineDefinition(pipeline.Definition)
+		if err != nil || pipeline.Name == "" {
+			invalid = append(invalid, fmt.Sprintf("%q", pipeline.Name))
+			continue
+		}
+		pipelines[pipeline.Name] = definition
+	}
+
+	if len(invalid) > 0 {
+		message := fmt.Sprintf("Invalid ingest pipelines: %s. Please ensure each pipeline has a unique name and a JSON definition with processors", strings.Join(invalid, ", "))
+		if err := updateCondition(dpl, api.InvalidIngestPipelines, invalidSpecReason, v1.ConditionTrue, message, er.client); err != nil {
+			return kverrors.Wrap(err, "failed to set ingest pipelines status")
+		}
+	} else {
+		if err := updateCondition(dpl, api.InvalidIngestPipelines, invalidSpecReason, v1.ConditionFalse, "", er.client); err != nil {
+			return kverrors.Wrap(err, "failed to set ingest pipelines status")
+		}
+	}
+
+	if len(pipelines) == 0 || !er.AnyNodeReady() {
+		return nil
+	}
+
+	for _, pipeline := range dpl.Spec.IngestPipelines {
+		desired, ok := pipelines[pipeline.Name]
+		if !ok {
+			continue
+		}
+
+		current, err := er.esClient.GetPipeline(pipeline.Name)
+		if err != nil {
+			return err
+		}
+
+		if reflect.DeepEqual(current, desired) {
+			continue
+		}
+
+		if err := er.esClient.PutPipeline(pipeline.Name, pipeline.Definition); err != nil {
+			return err
+		}
+		er.L().Info("Provisioned ingest pipeline", "pipeline", pipeline.Name)
+	}
+
+	return nil
+}
+
+// parsePipelineDefinition returns the decoded JSON definition of an ingest pipeline
+// or an error if it is no object listing processors
+func parsePipelineDefinition(definition string) (map[string]interface{}, error) {
+	decoded := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(definition), &decoded); err != nil {
+		return nil, kverrors.Wrap(err, "failed to decode ingest pipeline definition")
+	}
+
+	processors, ok := decoded["processors"].([]interface{})
+	if !ok || len(processors) == 0 {
+		return nil, kverrors.New("ingest pipeline definition has no processors")
+	}
+
+	return decoded, nil
+}
@@ -0,0 +1,139 @@
+package elasticsearch
+
+import (
+	"net/http"
+	"testing"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/test/helpers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParsePipelineDefinition(t *testing.T) {
+	tests := []struct {
+		desc       string
+		definition string
+		valid      bool
+	}{
+		{
+			desc:       "processors",
+			definition: `{"description":"test","processors":[{"set":{"field":"foo","value":"bar"}}]}`,
+			valid:      true,
+		},
+		{
+			desc:       "no processors",
+			definition: `{"description":"test","processors":[]}`,
+		},
+		{
+			desc:       "no object",
+			definition: `["set"]`,
+		},
+		{
+			desc:       "malformed",
+			definition: `{"processors":[`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			_, err := parsePipelineDefinition(test.definition)
+			if valid := err == nil; valid != test.valid {
+				t.Errorf("Exp. definition to be valid %t but got error %v", test.valid, err)
+			}
+		})
+	}
+}
+
+func TestCreateOrUpdateIngestPipelines(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+
+	const (
+		esCluster   = "elasticsearch"
+		esNamespace = "openshift-logging"
+	)
+
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      esCluster,
+			Namespace: esNamespace,
+		},
+		Spec: loggingv1.ElasticsearchSpec{
+			IngestPipelines: []loggingv1.ElasticsearchIngestPipeline{
+				{Name: "unchanged", Definition: `{"processors":[{"set":{"field":"foo","value":"bar"}}]}`},
+				{Name: "drifted", Definition: `{"processors":[{"set":{"field":"foo","value":"bar"}}]}`},
+				{Name: "missing", Definition: `{"processors":[{"lowercase":{"field":"foo"}}]}`},
+				{Name: "invalid", Definition: `{"processors":`},
+			},
+		},
+	}
+
+	readyPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch-cdm-1",
+			Namespace: esNamespace,
+			Labels: map[string]string{
+				"component":      "elasticsearch",
+				"cluster-name":   esCluster,
+				"es-node-client": "true",
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{Ready: true}},
+		},
+	}
+
+	k8sClient := fake.NewFakeClient(cluster, readyPod)
+
+	acknowledged := helpers.FakeElasticsearchResponse{StatusCode: http.StatusOK, Body: `{"acknowledged": true}`}
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"_ingest/pipeline/unchanged": {
+			{StatusCode: http.StatusOK, Body: `{"unchanged":{"processors":[{"set":{"field":"foo","value":"bar"}}]}}`},
+		},
+		"_ingest/pipeline/drifted": {
+			{StatusCode: http.StatusOK, Body: `{"drifted":{"processors":[{"set":{"field":"foo","value":"baz"}}]}}`},
+			acknowledged,
+		},
+		"_ingest/pipeline/missing": {
+			{StatusCode: http.StatusNotFound, Body: `{}`},
+			acknowledged,
+		},
+	})
+
+	er := &ElasticsearchRequest{
+		cluster:  cluster,
+		client:   k8sClient,
+		esClient: helpers.NewFakeElasticsearchClient(esCluster, esNamespace, k8sClient, chatter),
+	}
+
+	if err := er.CreateOrUpdateIngestPipelines(); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+
+	for name, wantPut := range map[string]bool{
+		"unchanged": false,
+		"drifted":   true,
+		"missing":   true,
+	} {
+		requests := chatter.Requests["_ingest/pipeline/"+name]
+		if len(requests) == 0 || requests[0].Method != http.MethodGet {
+			t.Fatalf("Exp. pipeline %s to be fetched", name)
+		}
+		if gotPut := len(requests) == 2 && requests[1].Method == http.MethodPut; gotPut != wantPut {
+			t.Errorf("Exp. pipeline %s to be put %t", name, wantPut)
+		}
+	}
+
+	if _, ok := chatter.Requests["_ingest/pipeline/invalid"]; ok {
+		t.Error("Exp. the invalid pipeline not to be applied")
+	}
+
+	_, condition := getESNodeCondition(cluster.Status.Conditions, loggingv1.InvalidIngestPipelines)
+	if condition == nil || condition.Status != corev1.ConditionTrue {
+		t.Errorf("Exp. the InvalidIngestPipelines condition to be true but got %v", cluster.Status.Conditions)
+	}
+}
@@ -0,0 +1,112 @@
+package elasticsearch
+
+import (
+	"net/url"
+	"regexp"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// pluginsStagingPath is where the init container copies the plugins directory to, so
+// that the Elasticsearch container mounts the bundled and the installed plugins
+const pluginsStagingPath = "/elasticsearch/plugins"
+
+// installPluginsScript installs the plugins given as arguments into the plugins directory
+// of the image and copies it to the staging volume. Names of official plugins are
+// resolved against the mirror if any, using the version of the Elasticsearch jar.
+const installPluginsScript = `set -e
+version=$(ls /usr/share/java/elasticsearch/lib | sed -n 's/^elasticsearch-\([0-9][0-9.]*\)\.jar$/\1/p')
+for plugin in "$@"; do
+  location="$plugin"
+  if [ -n "$PLUGIN_MIRROR" ] && ! echo "$plugin" | grep -q "://"; then
+    location="$PLUGIN_MIRROR/$plugin/$plugin-$version.zip"
+  fi
+  /usr/share/java/elasticsearch/bin/elasticsearch-plugin install --batch "$location"
+done
+cp -a /usr/share/java/elasticsearch/plugins/. ` + pluginsStagingPath + `/
+`
+
+// pluginNameRegex matches the names of official plugins, e.g. repository-s3
+var pluginNameRegex = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+func hasPlugins(commonSpec api.ElasticsearchNodeSpec) bool {
+	return len(commonSpec.Plugins) > 0
+}
+
+// newInstallPluginsContainer returns the init container installing the plugins. The
+// plugins are passed as arguments, thus changing them changes the pod spec and rolls the nodes.
+func newInstallPluginsContainer(imageName string, plugins []string, mirror string) v1.Container {
+	env := []v1.EnvVar{
+		{
+			Name:  "ES_JAVA_OPTS",
+			Value: "-Xms64m -Xmx64m",
+		},
+	}
+	if mirror != "" {
+		env = append(env, v1.EnvVar{Name: "PLUGIN_MIRROR", Value: mirror})
+	}
+
+	return v1.Container{
+		Name:                     installPluginsContainerName,
+		Image:                    imageName,
+		ImagePullPolicy:          "IfNotPresent",
+		TerminationMessagePolicy: v1.TerminationMessageFallbackToLogsOnError,
+		Command:                  []string{"sh", "-c", installPluginsScript, installPluginsContainerName},
+		Args:                     append([]string{}, plugins...),
+		Env:                      env,
+		Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("100m"),
+				v1.ResourceMemory: resource.MustParse("256Mi"),
+			},
+		},
+		VolumeMounts: []v1.VolumeMount{
+			{
+				Name:      pluginsVolumeName,
+				MountPath: pluginsStagingPath,
+			},
+		},
+	}
+}
+
+func newPluginsVolume() v1.Volume {
+	return v1.Volume{
+		Name: pluginsVolumeName,
+		VolumeSource: v1.VolumeSource{
+			EmptyDir: &v1.EmptyDirVolumeSource{},
+		},
+	}
+}
+
+// getInvalidPlugins returns the plugins which are neither names of official plugins nor
+// URLs of plugin archives and the mirror if it is no http(s) URL
+func getInvalidPlugins(commonSpec api.ElasticsearchNodeSpec) []string {
+	invalid := []string{}
+	for _, plugin := range commonSpec.Plugins {
+		if !pluginNameRegex.MatchString(plugin) && !isPluginURL(plugin, "http", "https", "file") {
+			invalid = append(invalid, plugin)
+		}
+	}
+
+	if mirror := commonSpec.PluginMirror; mirror != "" && !isPluginURL(mirror, "http", "https") {
+		invalid = append(invalid, mirror)
+	}
+
+	return invalid
+}
+
+func isPluginURL(location string, schemes ...string) bool {
+	u, err := url.Parse(location)
+	if err != nil || (u.Host == "" && u.Path == "") {
+		return false
+	}
+
+	for _, scheme := range schemes {
+		if u.Scheme == scheme {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package elasticsearch
+
+import (
+	"reflect"
+	"testing"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/pod"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestInstallPluginsInitContainer(t *testing.T) {
+	commonSpec := api.ElasticsearchNodeSpec{
+		Plugins:      []string{"repository-s3"},
+		PluginMirror: "https://mirror.example.com/plugins",
+	}
+
+	template := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, commonSpec, map[string]string{}, NodeRoles{}, nil, LogConfig{})
+	if len(template.Spec.InitContainers) != 1 {
+		t.Fatalf("Exp. a single init container but got %v", template.Spec.InitContainers)
+	}
+	initContainer := template.Spec.InitContainers[0]
+	if initContainer.Name != installPluginsContainerName || !reflect.DeepEqual(initContainer.Args, []string{"repository-s3"}) {
+		t.Errorf("Exp. the plugins to be passed to the install container but got %v", initContainer.Args)
+	}
+	if !hasEnvVar("PLUGIN_MIRROR", "https://mirror.example.com/plugins", initContainer.Env) {
+		t.Errorf("Exp. the plugin mirror to be passed to the install container but got %v", initContainer.Env)
+	}
+
+	if _, ok := getVolume(pluginsVolumeName, template.Spec.Volumes); !ok {
+		t.Errorf("Exp. the plugins volume but got %v", template.Spec.Volumes)
+	}
+	mounted := false
+	for _, volumeMount := range template.Spec.Containers[0].VolumeMounts {
+		if volumeMount.Name == pluginsVolumeName && volumeMount.MountPath == elasticsearchPluginsPath {
+			mounted = true
+		}
+	}
+	if !mounted {
+		t.Errorf("Exp. the plugins volume to be mounted to the plugins directory but got %v", template.Spec.Containers[0].VolumeMounts)
+	}
+
+	commonSpec.Plugins = append(commonSpec.Plugins, "analysis-icu")
+	changed := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, commonSpec, map[string]string{}, NodeRoles{}, nil, LogConfig{})
+	if pod.ArePodSpecEqual(template.Spec, changed.Spec, true) {
+		t.Error("Exp. an added plugin to roll the nodes")
+	}
+
+	template = newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, api.ElasticsearchNodeSpec{}, map[string]string{}, NodeRoles{}, nil, LogConfig{})
+	if len(template.Spec.InitContainers) != 0 {
+		t.Errorf("Exp. no init container without plugins but got %v", template.Spec.InitContainers)
+	}
+	if _, ok := getVolume(pluginsVolumeName, template.Spec.Volumes); ok {
+		t.Error("Exp. no plugins volume without plugins")
+	}
+}
+
+func TestGetInvalidPlugins(t *testing.T) {
+	commonSpec := api.ElasticsearchNodeSpec{
+		Plugins: []string{
+			"repository-s3",
+			"https://plugins.example.com/custom-plugin-6.8.1.zip",
+			"file:///tmp/custom-plugin.zip",
+			"Repository_S3",
+			"repository-s3; rm -rf /",
+		},
+		PluginMirror: "ftp://mirror.example.com",
+	}
+
+	want := []string{"Repository_S3", "repository-s3; rm -rf /", "ftp://mirror.example.com"}
+	if got := getInvalidPlugins(commonSpec); !reflect.DeepEqual(got, want) {
+		t.Errorf("Exp. invalid plugins %v but got %v", want, got)
+	}
+}
+
+func hasEnvVar(name, value string, envVars []corev1.EnvVar) bool {
+	for _, envVar := range envVars {
+		if envVar.Name == name && envVar.Value == value {
+			return true
+		}
+	}
+	return false
+}
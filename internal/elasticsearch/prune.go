@@ -0,0 +1,127 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ViaQ/logerr/kverrors"
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/deployment"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// pruneStaleNodeDeployments deletes the node deployments of the cluster which are neither
+// generated by its node groups nor tracked as nodes to remove, e.g. left behind by a topology
+// change while the operator was restarted. Nodes without the master role are removed first
+// and only one master eligible node is removed per reconcile to keep the quorum. Signals to
+// requeue the reconcile while the cluster is unhealthy or a node is drained of its shards.
+func (er *ElasticsearchRequest) pruneStaleNodeDeployments() error {
+	cluster := er.cluster
+
+	expected := sets.NewString()
+	for _, node := range cluster.Spec.Nodes {
+		if node.GenUUID == nil || isNodeGroupReplaced(cluster, node) {
+			continue
+		}
+		expected.Insert(getGeneratedNodeNames(cluster.Name, *node.GenUUID, node)...)
+	}
+	for _, node := range nodes[nodeMapKey(cluster.Name, cluster.Namespace)] {
+		expected.Insert(node.name())
+	}
+
+	selector := map[string]string{
+		"cluster-name": cluster.Name,
+	}
+	deployments, err := deployment.List(context.TODO(), er.client, cluster.Namespace, selector)
+	if err != nil {
+		return kverrors.Wrap(err, "failed to list node deployments",
+			"cluster", cluster.Name,
+			"namespace", cluster.Namespace,
+		)
+	}
+
+	stale := []appsv1.Deployment{}
+	for _, dpl := range deployments {
+		if expected.Has(dpl.Name) || !metav1.IsControlledBy(&dpl, cluster) {
+			continue
+		}
+		stale = append(stale, dpl)
+	}
+
+	sort.SliceStable(stale, func(i, j int) bool {
+		return !isMasterDeployment(stale[i]) && isMasterDeployment(stale[j])
+	})
+
+	for _, dpl := range stale {
+		if status, _ := er.esClient.GetClusterHealthStatus(); !utils.Contains(desiredClusterStates, status) {
+			return newRequeueError(RequeueWaitingForHealth, "stale node deployments are pruned once the cluster is healthy",
+				"currentHealth", status,
+				"desiredHealth", desiredClusterStates,
+			)
+		}
+
+		// relocate the shards off the node before removing it
+		drained, err := er.drainNode(dpl.Name)
+		if err != nil {
+			er.L().Error(err, "unable to exclude node from shard allocation", "node", dpl.Name)
+		}
+		if !drained {
+			return newRequeueError(RequeueWaitingForDrain, "stale node deployment is pruned once its shards are relocated",
+				"node", dpl.Name,
+			)
+		}
+
+		master := isMasterDeployment(dpl)
+		if master {
+			// lower the min masters to the desired topology before the master leaves
+			er.updateMinMasters()
+		}
+
+		key := client.ObjectKey{Name: dpl.Name, Namespace: dpl.Namespace}
+		if err := deployment.Delete(context.TODO(), er.client, key); err != nil {
+			return kverrors.Wrap(err, "failed to prune stale node deployment",
+				"node", dpl.Name,
+			)
+		}
+
+		if master {
+			er.resetExistingMasterCount()
+		}
+
+		if _, err := er.esClient.ClearAllocationExclusions(dpl.Name); err != nil {
+			er.L().Error(err, "unable to clear shard allocation exclusions", "node", dpl.Name)
+		}
+
+		name := dpl.Name
+		if err := updateConditionWithRetry(cluster, v1.ConditionTrue, func(status *api.ElasticsearchStatus, _ v1.ConditionStatus) bool {
+			index, _ := getNodeStatus(name, status)
+			if index == NotFoundIndex {
+				return false
+			}
+			status.Nodes = append(status.Nodes[:index], status.Nodes[index+1:]...)
+			return true
+		}, er.client); err != nil {
+			er.L().Error(err, "unable to remove pruned node from status", "node", name)
+		}
+
+		message := fmt.Sprintf("Deleted node deployment %s no longer generated by any node group", dpl.Name)
+		er.L().Info(message)
+		recordEvent(er.recorder, cluster, v1.EventTypeNormal, eventReasonNodePruned, message)
+
+		if master {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func isMasterDeployment(dpl appsv1.Deployment) bool {
+	return dpl.Labels["es-node-master"] == "true"
+}
@@ -0,0 +1,209 @@
+package elasticsearch
+
+import (
+	"context"
+	"testing"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/test/helpers"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPruneStaleNodeDeployments(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	nodes = map[string][]NodeTypeInterface{}
+
+	const (
+		esCluster   = "elasticsearch"
+		esNamespace = "openshift-logging"
+	)
+
+	uuid := "abc"
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      esCluster,
+			Namespace: esNamespace,
+			UID:       "cluster-uid",
+		},
+		Spec: loggingv1.ElasticsearchSpec{
+			Nodes: []loggingv1.ElasticsearchNode{
+				{
+					Roles:     []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleData},
+					NodeCount: 1,
+					GenUUID:   &uuid,
+				},
+			},
+		},
+		Status: loggingv1.ElasticsearchStatus{
+			Nodes: []loggingv1.ElasticsearchNodeStatus{
+				{DeploymentName: "elasticsearch-d-abc-1"},
+				{DeploymentName: "elasticsearch-d-abc-2"},
+				{DeploymentName: "elasticsearch-cdm-old-1"},
+				{DeploymentName: "elasticsearch-cdm-old-2"},
+			},
+		},
+	}
+
+	newDeployment := func(name string, master, owned bool) *appsv1.Deployment {
+		dpl := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: esNamespace,
+				Labels: map[string]string{
+					"cluster-name":   esCluster,
+					"es-node-master": "false",
+				},
+			},
+		}
+		if master {
+			dpl.Labels["es-node-master"] = "true"
+		}
+		if owned {
+			cluster.AddOwnerRefTo(dpl)
+		}
+		return dpl
+	}
+
+	k8sClient := fake.NewFakeClient(
+		cluster.DeepCopy(),
+		newDeployment("elasticsearch-d-abc-1", false, true),
+		newDeployment("elasticsearch-cdm-old-1", true, true),
+		newDeployment("elasticsearch-cdm-old-2", true, true),
+		newDeployment("elasticsearch-d-abc-2", false, true),
+		newDeployment("elasticsearch-d-foreign-1", false, false),
+	)
+
+	green := helpers.FakeElasticsearchResponse{StatusCode: 200, Body: `{"status": "green"}`}
+	noShards := helpers.FakeElasticsearchResponse{StatusCode: 200, Body: `[]`}
+	acknowledged := helpers.FakeElasticsearchResponse{StatusCode: 200, Body: `{"acknowledged": true}`}
+	noExclusions := helpers.FakeElasticsearchResponse{StatusCode: 200, Body: `{"persistent": {}}`}
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"_cluster/health": {green, green, green},
+		"_cat/shards?format=json&h=index,shard,prirep,state,node": {noShards, noShards, noShards},
+		"_cluster/settings?flat_settings=true":                    {noExclusions, noExclusions, noExclusions},
+		"_cluster/settings":                                       {acknowledged, acknowledged, acknowledged},
+	})
+
+	recorder := record.NewFakeRecorder(3)
+	er := &ElasticsearchRequest{
+		cluster:  cluster,
+		client:   k8sClient,
+		esClient: helpers.NewFakeElasticsearchClient(esCluster, esNamespace, k8sClient, chatter),
+		recorder: recorder,
+	}
+
+	exists := func(name string) bool {
+		key := client.ObjectKey{Name: name, Namespace: esNamespace}
+		err := k8sClient.Get(context.TODO(), key, &appsv1.Deployment{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			t.Fatalf("failed to get deployment %s: %s", name, err)
+		}
+		return err == nil
+	}
+
+	if err := er.pruneStaleNodeDeployments(); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+
+	// the scaled in data node and a single master are pruned first
+	for name, want := range map[string]bool{
+		"elasticsearch-d-abc-1":     true,
+		"elasticsearch-d-abc-2":     false,
+		"elasticsearch-cdm-old-1":   false,
+		"elasticsearch-cdm-old-2":   true,
+		"elasticsearch-d-foreign-1": true,
+	} {
+		if got := exists(name); got != want {
+			t.Errorf("Exp. deployment %s to exist %t after the first prune but was %t", name, want, got)
+		}
+	}
+
+	if err := er.pruneStaleNodeDeployments(); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+
+	if exists("elasticsearch-cdm-old-2") {
+		t.Error("Exp. the remaining stale master to be pruned on the next reconcile")
+	}
+	if !exists("elasticsearch-d-abc-1") || !exists("elasticsearch-d-foreign-1") {
+		t.Error("Exp. generated and unowned deployments to be kept")
+	}
+
+	if len(cluster.Status.Nodes) != 1 || cluster.Status.Nodes[0].DeploymentName != "elasticsearch-d-abc-1" {
+		t.Errorf("Exp. the pruned nodes to be removed from the status but was %v", cluster.Status.Nodes)
+	}
+
+	stored := &loggingv1.Elasticsearch{}
+	if err := k8sClient.Get(context.TODO(), client.ObjectKey{Name: esCluster, Namespace: esNamespace}, stored); err != nil {
+		t.Fatalf("failed to get cluster: %s", err)
+	}
+	if len(stored.Status.Nodes) != 1 {
+		t.Errorf("Exp. the removal of the pruned nodes from the status to be persisted but was %v", stored.Status.Nodes)
+	}
+
+	if len(recorder.Events) != 3 {
+		t.Errorf("Exp. an event per pruned node but got %d", len(recorder.Events))
+	}
+}
+
+func TestPruneStaleNodeDeploymentsRequeuesWhileDraining(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	nodes = map[string][]NodeTypeInterface{}
+
+	const (
+		esCluster   = "elasticsearch"
+		esNamespace = "openshift-logging"
+	)
+
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      esCluster,
+			Namespace: esNamespace,
+			UID:       "cluster-uid",
+		},
+	}
+	dpl := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch-d-old-1",
+			Namespace: esNamespace,
+			Labels: map[string]string{
+				"cluster-name": esCluster,
+			},
+		},
+	}
+	cluster.AddOwnerRefTo(dpl)
+	k8sClient := fake.NewFakeClient(cluster.DeepCopy(), dpl)
+
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"_cluster/health": {
+			{StatusCode: 200, Body: `{"status": "green"}`},
+			{StatusCode: 200, Body: `{"status": "green", "relocating_shards": 1}`},
+		},
+		"_cat/shards?format=json&h=index,shard,prirep,state,node": {
+			{StatusCode: 200, Body: `[{"index": "app-000001", "shard": "0", "prirep": "p", "state": "STARTED", "node": "elasticsearch-d-old-1"}]`},
+		},
+		"_cluster/settings?flat_settings=true": {{StatusCode: 200, Body: `{"persistent": {}}`}},
+		"_cluster/settings":                    {{StatusCode: 200, Body: `{"acknowledged": true}`}},
+	})
+
+	er := &ElasticsearchRequest{
+		cluster:  cluster,
+		client:   k8sClient,
+		esClient: helpers.NewFakeElasticsearchClient(esCluster, esNamespace, k8sClient, chatter),
+		recorder: record.NewFakeRecorder(2),
+	}
+
+	err := er.pruneStaleNodeDeployments()
+	if !IsRequeue(err) {
+		t.Fatalf("Exp. a requeue while the stale node is drained but got %v", err)
+	}
+	if err := k8sClient.Get(context.TODO(), client.ObjectKey{Name: dpl.Name, Namespace: esNamespace}, &appsv1.Deployment{}); err != nil {
+		t.Errorf("Exp. the stale node to be kept until drained but got %s", err)
+	}
+}
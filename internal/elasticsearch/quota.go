@@ -0,0 +1,159 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ViaQ/logerr/kverrors"
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/deployment"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/statefulset"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// insufficientQuotaReason is the reason of the InsufficientQuota condition
+const insufficientQuotaReason = "Exceeded Quota"
+
+// quotaRejectionMessage returns the message reporting the creation of the object as rejected
+// if the error is caused by an exceeded resource quota
+func quotaRejectionMessage(kind, name string, err error) (string, bool) {
+	resources, ok := getExceededQuotaResources(err)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("Creating %s %s exceeds the resource quota for: %s", kind, name, strings.Join(resources, ", ")), true
+}
+
+// updateInsufficientQuota sets the InsufficientQuota condition and emits a warning event
+// for each creation rejected by a resource quota, or clears the condition if none is.
+// Next to the given rejections of this reconcile, the pod creations rejected for the nodes
+// and the storage claims of the nodes which cannot be created are read from the cluster.
+func (er *ElasticsearchRequest) updateInsufficientQuota(rejections ...string) {
+	messages := append([]string{}, rejections...)
+	for _, node := range nodes[nodeMapKey(er.cluster.Name, er.cluster.Namespace)] {
+		if message, ok := er.getPodQuotaRejection(node); ok {
+			messages = append(messages, message)
+		}
+	}
+	messages = append(messages, er.getStorageQuotaRejections()...)
+
+	if len(messages) == 0 {
+		if err := updateCondition(er.cluster, api.InsufficientQuota, insufficientQuotaReason, v1.ConditionFalse, "", er.client); err != nil {
+			er.L().Error(err, "Unable to clear insufficient quota condition")
+		}
+		return
+	}
+
+	sort.Strings(messages)
+	message := strings.Join(messages, "; ")
+
+	// report the rejections once, as long as they do not change
+	if _, condition := getESNodeCondition(er.cluster.Status.Conditions, api.InsufficientQuota); condition == nil || condition.Message != message {
+		for _, rejection := range messages {
+			er.L().Info(rejection)
+			recordEvent(er.recorder, er.cluster, v1.EventTypeWarning, eventReasonInsufficientQuota, rejection)
+		}
+	}
+
+	if err := updateCondition(er.cluster, api.InsufficientQuota, insufficientQuotaReason, v1.ConditionTrue, message, er.client); err != nil {
+		er.L().Error(err, "Unable to set insufficient quota condition")
+	}
+}
+
+// getPodQuotaRejection returns the rejection of the pods of the node by a resource quota.
+// Pods are created by the controller of the node, which reports the rejection in its
+// ReplicaFailure condition. The deployment controller reports the one of its replica sets.
+func (er *ElasticsearchRequest) getPodQuotaRejection(node NodeTypeInterface) (string, bool) {
+	var conditionMessage string
+	switch n := node.(type) {
+	case *deploymentNode:
+		dpl, err := deployment.Get(context.TODO(), er.client, client.ObjectKey{Name: n.name(), Namespace: n.self.Namespace})
+		if err != nil {
+			return "", false
+		}
+		for _, condition := range dpl.Status.Conditions {
+			if condition.Type == apps.DeploymentReplicaFailure && condition.Status == v1.ConditionTrue {
+				conditionMessage = condition.Message
+			}
+		}
+	case *statefulSetNode:
+		sts, err := statefulset.Get(context.TODO(), er.client, client.ObjectKey{Name: n.name(), Namespace: n.self.Namespace})
+		if err != nil {
+			return "", false
+		}
+		for _, condition := range sts.Status.Conditions {
+			if string(condition.Type) == string(apps.ReplicaSetReplicaFailure) && condition.Status == v1.ConditionTrue {
+				conditionMessage = condition.Message
+			}
+		}
+	}
+
+	resources, ok := parseExceededQuotaResources(conditionMessage)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("Creating pods of node %s exceeds the resource quota for: %s", node.name(), strings.Join(resources, ", ")), true
+}
+
+// getStorageQuotaRejections returns the rejections by a resource quota of the storage claims
+// of the nodes which do not exist. Their creation is checked by a dry run.
+func (er *ElasticsearchRequest) getStorageQuotaRejections() []string {
+	cluster := er.cluster
+	messages := []string{}
+	for _, node := range cluster.Spec.Nodes {
+		if node.GenUUID == nil || node.Storage.Size == nil {
+			continue
+		}
+		for _, nodeName := range getGeneratedNodeNames(cluster.Name, *node.GenUUID, node) {
+			pvc := newPersistentVolumeClaim(cluster.Name, nodeName, cluster.Namespace, node.Storage)
+
+			current := &v1.PersistentVolumeClaim{}
+			err := er.client.Get(context.TODO(), client.ObjectKey{Name: pvc.Name, Namespace: pvc.Namespace}, current)
+			if !apierrors.IsNotFound(kverrors.Root(err)) {
+				continue
+			}
+
+			err = er.client.Create(context.TODO(), pvc, client.DryRunAll)
+			if message, ok := quotaRejectionMessage("PersistentVolumeClaim", pvc.Name, err); ok {
+				messages = append(messages, message)
+			}
+		}
+	}
+	return messages
+}
+
+// getExceededQuotaResources returns the requested resources if the error is a rejection
+// by a resource quota, e.g. `exceeded quota: storage, requested: requests.storage=10Gi, ...`
+func getExceededQuotaResources(err error) ([]string, bool) {
+	root := kverrors.Root(err)
+	if !apierrors.IsForbidden(root) {
+		return nil, false
+	}
+	return parseExceededQuotaResources(root.Error())
+}
+
+// parseExceededQuotaResources returns the requested resources if the message reports a
+// rejection by a resource quota
+func parseExceededQuotaResources(message string) ([]string, bool) {
+	if !strings.Contains(message, "exceeded quota") {
+		return nil, false
+	}
+
+	resources := []string{}
+	if index := strings.Index(message, "requested: "); index >= 0 {
+		requested := message[index+len("requested: "):]
+		if end := strings.Index(requested, ", used:"); end >= 0 {
+			requested = requested[:end]
+		}
+		for _, request := range strings.Split(requested, ",") {
+			resources = append(resources, strings.SplitN(strings.TrimSpace(request), "=", 2)[0])
+		}
+	}
+
+	return resources, true
+}
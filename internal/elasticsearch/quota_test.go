@@ -0,0 +1,165 @@
+package elasticsearch
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ViaQ/logerr/kverrors"
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newQuotaError(message string) error {
+	err := apierrors.NewForbidden(schema.GroupResource{Resource: "persistentvolumeclaims"}, "elasticsearch-elasticsearch-cdm-1", kverrors.New(message))
+	return kverrors.Wrap(err, "failed to create PVC")
+}
+
+func TestGetExceededQuotaResources(t *testing.T) {
+	tests := []struct {
+		desc      string
+		err       error
+		resources []string
+		ok        bool
+	}{
+		{
+			desc:      "exceeded quota",
+			err:       newQuotaError("exceeded quota: storage, requested: requests.storage=10Gi,persistentvolumeclaims=1, used: requests.storage=0, limited: requests.storage=5Gi"),
+			resources: []string{"requests.storage", "persistentvolumeclaims"},
+			ok:        true,
+		},
+		{
+			desc: "forbidden without quota",
+			err:  apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "elasticsearch", kverrors.New("not allowed")),
+		},
+		{
+			desc: "not forbidden",
+			err:  apierrors.NewAlreadyExists(schema.GroupResource{Resource: "pods"}, "elasticsearch"),
+		},
+	}
+
+	for _, test := range tests {
+		resources, ok := getExceededQuotaResources(test.err)
+		if ok != test.ok {
+			t.Errorf("%s: exp. %t got %t", test.desc, test.ok, ok)
+		}
+		if ok && !reflect.DeepEqual(resources, test.resources) {
+			t.Errorf("%s: exp. resources %v got %v", test.desc, test.resources, resources)
+		}
+	}
+}
+
+// quotaClient rejects the creation of persistent volume claims as exceeding a quota
+type quotaClient struct {
+	client.Client
+}
+
+func (c *quotaClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	if pvc, ok := obj.(*corev1.PersistentVolumeClaim); ok {
+		return apierrors.NewForbidden(schema.GroupResource{Resource: "persistentvolumeclaims"}, pvc.Name,
+			kverrors.New("exceeded quota: storage, requested: requests.storage=10Gi, used: requests.storage=0, limited: requests.storage=5Gi"))
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func TestUpdateInsufficientQuota(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+
+	size := resource.MustParse("10Gi")
+	uuid := "1234"
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch",
+			Namespace: "openshift-logging",
+		},
+		Spec: loggingv1.ElasticsearchSpec{
+			Nodes: []loggingv1.ElasticsearchNode{
+				{
+					Roles:     []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleData},
+					NodeCount: 1,
+					GenUUID:   &uuid,
+					Storage:   loggingv1.ElasticsearchStorageSpec{Size: &size},
+				},
+			},
+		},
+	}
+	dpl := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch-cdm-1",
+			Namespace: cluster.Namespace,
+		},
+		Status: appsv1.DeploymentStatus{
+			Conditions: []appsv1.DeploymentCondition{
+				{
+					Type:    appsv1.DeploymentReplicaFailure,
+					Status:  corev1.ConditionTrue,
+					Reason:  "FailedCreate",
+					Message: `pods "elasticsearch-cdm-1-abc" is forbidden: exceeded quota: compute, requested: limits.memory=16Gi, used: limits.memory=0, limited: limits.memory=8Gi`,
+				},
+			},
+		},
+	}
+
+	recorder := record.NewFakeRecorder(3)
+	k8sClient := &quotaClient{Client: fake.NewFakeClient(cluster.DeepCopy(), dpl)}
+	er := &ElasticsearchRequest{
+		cluster:  cluster,
+		client:   k8sClient,
+		recorder: recorder,
+	}
+	nodes = map[string][]NodeTypeInterface{
+		nodeMapKey(cluster.Name, cluster.Namespace): {
+			&deploymentNode{self: *dpl, client: k8sClient},
+		},
+	}
+
+	er.updateInsufficientQuota()
+
+	_, condition := getESNodeCondition(cluster.Status.Conditions, loggingv1.InsufficientQuota)
+	if condition == nil || condition.Status != corev1.ConditionTrue {
+		t.Fatalf("Exp. the InsufficientQuota condition to be true but got %v", cluster.Status.Conditions)
+	}
+	for _, exceeded := range []string{"limits.memory", "requests.storage"} {
+		if !strings.Contains(condition.Message, exceeded) {
+			t.Errorf("Exp. the condition message to name the exceeded resource %s but got %q", exceeded, condition.Message)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case event := <-recorder.Events:
+			if !strings.HasPrefix(event, "Warning InsufficientQuota") {
+				t.Errorf("Exp. a warning event but got %q", event)
+			}
+		default:
+			t.Error("Exp. an InsufficientQuota event to be recorded")
+		}
+	}
+
+	er.updateInsufficientQuota()
+	select {
+	case event := <-recorder.Events:
+		t.Errorf("Exp. no event for unchanged rejections but got %q", event)
+	default:
+	}
+
+	dpl.Status.Conditions = nil
+	er.client = fake.NewFakeClient(cluster.DeepCopy(), dpl)
+	nodes[nodeMapKey(cluster.Name, cluster.Namespace)][0] = &deploymentNode{self: *dpl, client: er.client}
+	er.updateInsufficientQuota()
+
+	if _, condition = getESNodeCondition(cluster.Status.Conditions, loggingv1.InsufficientQuota); condition != nil {
+		t.Errorf("Exp. the InsufficientQuota condition to be cleared but got %v", cluster.Status.Conditions)
+	}
+}
@@ -100,6 +100,11 @@ func (er *ElasticsearchRequest) CreateOrUpdateRBAC() error {
 
 	subjects := []rbacv1.Subject{}
 	for _, es := range esList.Items {
+		// clusters being torn down are removed from the subjects on teardown
+		if es.GetDeletionTimestamp() != nil {
+			continue
+		}
+
 		subject = rbac.NewSubject(
 			"ServiceAccount",
 			es.Name,
@@ -110,7 +115,7 @@ func (er *ElasticsearchRequest) CreateOrUpdateRBAC() error {
 	}
 
 	proxyRoleBinding := rbac.NewClusterRoleBinding(
-		"elasticsearch-proxy",
+		proxyClusterRoleBindingName,
 		"elasticsearch-proxy",
 		subjects,
 	)
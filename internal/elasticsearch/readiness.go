@@ -0,0 +1,165 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"strings"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// degradedRestartCount is the number of restarts after which a container failing to
+// become ready degrades the node instead of being considered starting
+const degradedRestartCount = 3
+
+// failedWaitingReasons are the reasons of waiting containers which will not start
+// without intervention
+var failedWaitingReasons = []string{
+	"CrashLoopBackOff",
+	"ImagePullBackOff",
+	"ErrImagePull",
+	"InvalidImageName",
+	"CreateContainerConfigError",
+	"CreateContainerError",
+}
+
+// updateNodeReadinessConditions sets the Degraded condition of the node if any of its pods
+// fails to start, else the Progressing condition if any of them is still starting
+func updateNodeReadinessConditions(node *api.ElasticsearchNodeStatus, pods []v1.Pod) {
+	var degradedReason, degradedMessage, progressingReason, progressingMessage string
+
+	for _, p := range pods {
+		if reason, message := podFailure(p); reason != "" {
+			degradedReason, degradedMessage = reason, message
+			break
+		}
+
+		if progressingReason == "" {
+			progressingReason, progressingMessage = podStarting(p)
+		}
+	}
+
+	if degradedReason != "" {
+		progressingReason, progressingMessage = "", ""
+	}
+
+	updateNodeReadinessCondition(node, api.DegradedState, degradedReason, degradedMessage)
+	updateNodeReadinessCondition(node, api.ProgressingState, progressingReason, progressingMessage)
+}
+
+func updateNodeReadinessCondition(node *api.ElasticsearchNodeStatus, conditionType api.ClusterConditionType, reason, message string) bool {
+	status := v1.ConditionTrue
+	if reason == "" {
+		status = v1.ConditionFalse
+	}
+
+	transitionTime := metav1.Now()
+	if _, old := getPodCondition(node, conditionType); old != nil && old.Status == status {
+		transitionTime = old.LastTransitionTime
+	}
+
+	return updatePodCondition(node, &api.ClusterCondition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: transitionTime,
+	})
+}
+
+// podFailure returns the reason and message the pod fails to start for, if any
+func podFailure(p v1.Pod) (string, string) {
+	if p.Status.Phase == v1.PodFailed {
+		return "PodFailed", p.Status.Message
+	}
+
+	for _, cs := range p.Status.ContainerStatuses {
+		if cs.State.Waiting != nil && isFailedWaitingReason(cs.State.Waiting.Reason) {
+			return cs.State.Waiting.Reason, fmt.Sprintf("Container %s of pod %s is waiting: %s", cs.Name, p.Name, cs.State.Waiting.Message)
+		}
+
+		if !cs.Ready && cs.RestartCount >= degradedRestartCount && cs.LastTerminationState.Terminated != nil {
+			terminated := cs.LastTerminationState.Terminated
+			return "RepeatedRestarts", fmt.Sprintf("Container %s of pod %s restarted %d times, last terminated with %s (exit code %d)",
+				cs.Name, p.Name, cs.RestartCount, terminated.Reason, terminated.ExitCode)
+		}
+	}
+
+	return "", ""
+}
+
+// podStarting returns the reason and message the pod is not ready yet for, if any.
+// Unschedulable pods are reported by the Unschedulable condition instead.
+func podStarting(p v1.Pod) (string, string) {
+	for _, condition := range p.Status.Conditions {
+		if condition.Type == v1.PodScheduled && condition.Status == v1.ConditionFalse {
+			return "", ""
+		}
+	}
+
+	if p.Status.Phase == v1.PodPending && len(p.Status.ContainerStatuses) == 0 {
+		return "PodPending", fmt.Sprintf("Pod %s is pending", p.Name)
+	}
+
+	for _, cs := range p.Status.ContainerStatuses {
+		if !cs.Ready {
+			return "ContainerStarting", fmt.Sprintf("Container %s of pod %s is not ready yet", cs.Name, p.Name)
+		}
+	}
+
+	return "", ""
+}
+
+func isFailedWaitingReason(reason string) bool {
+	for _, r := range failedWaitingReasons {
+		if r == reason {
+			return true
+		}
+	}
+
+	return false
+}
+
+// updateNodesReadinessConditions aggregates the readiness conditions of the nodes into the
+// NodesDegraded and NodesProgressing conditions of the cluster
+func updateNodesReadinessConditions(status *api.ElasticsearchStatus) {
+	degraded := []string{}
+	progressing := []string{}
+
+	for i := range status.Nodes {
+		node := &status.Nodes[i]
+		name := node.DeploymentName
+		if name == "" {
+			name = node.StatefulSetName
+		}
+
+		if _, condition := getPodCondition(node, api.DegradedState); condition != nil && condition.Status == v1.ConditionTrue {
+			degraded = append(degraded, fmt.Sprintf("%s (%s)", name, condition.Reason))
+			continue
+		}
+
+		if _, condition := getPodCondition(node, api.ProgressingState); condition != nil && condition.Status == v1.ConditionTrue {
+			progressing = append(progressing, name)
+		}
+	}
+
+	updateNodesReadinessCondition(status, api.NodesDegraded, "Nodes Failing", "Nodes failing to start: %s", degraded)
+	updateNodesReadinessCondition(status, api.NodesProgressing, "Nodes Starting", "Nodes still starting: %s", progressing)
+}
+
+func updateNodesReadinessCondition(status *api.ElasticsearchStatus, conditionType api.ClusterConditionType, reason, format string, nodeNames []string) bool {
+	if len(nodeNames) == 0 {
+		return updateESNodeCondition(status, &api.ClusterCondition{
+			Type:   conditionType,
+			Status: v1.ConditionFalse,
+		})
+	}
+
+	return updateESNodeCondition(status, &api.ClusterCondition{
+		Type:    conditionType,
+		Status:  v1.ConditionTrue,
+		Reason:  reason,
+		Message: fmt.Sprintf(format, strings.Join(nodeNames, ", ")),
+	})
+}
@@ -0,0 +1,201 @@
+package elasticsearch
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+)
+
+func TestNodeReadinessConditions(t *testing.T) {
+	tests := []struct {
+		desc        string
+		pod         corev1.Pod
+		degraded    string
+		progressing string
+	}{
+		{
+			desc: "pending pod",
+			pod: corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod"},
+				Status:     corev1.PodStatus{Phase: corev1.PodPending},
+			},
+			progressing: "PodPending",
+		},
+		{
+			desc: "freshly created container",
+			pod: corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod"},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					ContainerStatuses: []corev1.ContainerStatus{
+						{Name: "elasticsearch", Ready: false},
+					},
+				},
+			},
+			progressing: "ContainerStarting",
+		},
+		{
+			desc: "container in CrashLoopBackOff",
+			pod: corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod"},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					ContainerStatuses: []corev1.ContainerStatus{
+						{
+							Name:         "elasticsearch",
+							RestartCount: 1,
+							State: corev1.ContainerState{
+								Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+							},
+						},
+					},
+				},
+			},
+			degraded: "CrashLoopBackOff",
+		},
+		{
+			desc: "container restarting repeatedly",
+			pod: corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod"},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					ContainerStatuses: []corev1.ContainerStatus{
+						{
+							Name:         "elasticsearch",
+							RestartCount: degradedRestartCount,
+							LastTerminationState: corev1.ContainerState{
+								Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137},
+							},
+						},
+					},
+				},
+			},
+			degraded: "RepeatedRestarts",
+		},
+		{
+			desc: "ready container restarted before",
+			pod: corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod"},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					ContainerStatuses: []corev1.ContainerStatus{
+						{
+							Name:         "elasticsearch",
+							Ready:        true,
+							RestartCount: degradedRestartCount,
+							LastTerminationState: corev1.ContainerState{
+								Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "unschedulable pod",
+			pod: corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod"},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodPending,
+					Conditions: []corev1.PodCondition{
+						{Type: corev1.PodScheduled, Status: corev1.ConditionFalse},
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			node := &loggingv1.ElasticsearchNodeStatus{DeploymentName: "elasticsearch-cdm-1"}
+			updateNodeReadinessConditions(node, []corev1.Pod{test.pod})
+
+			if _, got := getPodCondition(node, loggingv1.DegradedState); !conditionHasReason(got, test.degraded) {
+				t.Errorf("expected degraded reason %q, got %v", test.degraded, got)
+			}
+			if _, got := getPodCondition(node, loggingv1.ProgressingState); !conditionHasReason(got, test.progressing) {
+				t.Errorf("expected progressing reason %q, got %v", test.progressing, got)
+			}
+		})
+	}
+}
+
+func TestNodeReadinessConditionsKeepTransitionTime(t *testing.T) {
+	transitionTime := metav1.NewTime(metav1.Now().Add(-time.Hour))
+	node := &loggingv1.ElasticsearchNodeStatus{
+		Conditions: []loggingv1.ClusterCondition{
+			{
+				Type:               loggingv1.ProgressingState,
+				Status:             corev1.ConditionTrue,
+				Reason:             "PodPending",
+				LastTransitionTime: transitionTime,
+			},
+		},
+	}
+
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod"},
+		Status:     corev1.PodStatus{Phase: corev1.PodPending},
+	}
+	updateNodeReadinessConditions(node, []corev1.Pod{pod})
+
+	_, condition := getPodCondition(node, loggingv1.ProgressingState)
+	if condition == nil || !condition.LastTransitionTime.Equal(&transitionTime) {
+		t.Errorf("expected transition time %v to be kept, got %v", transitionTime, condition)
+	}
+}
+
+func TestNodesReadinessConditions(t *testing.T) {
+	status := &loggingv1.ElasticsearchStatus{
+		Nodes: []loggingv1.ElasticsearchNodeStatus{
+			{
+				DeploymentName: "elasticsearch-cdm-1",
+				Conditions: []loggingv1.ClusterCondition{
+					{Type: loggingv1.DegradedState, Status: corev1.ConditionTrue, Reason: "CrashLoopBackOff"},
+				},
+			},
+			{
+				DeploymentName: "elasticsearch-cdm-2",
+				Conditions: []loggingv1.ClusterCondition{
+					{Type: loggingv1.ProgressingState, Status: corev1.ConditionTrue, Reason: "ContainerStarting"},
+				},
+			},
+			{
+				DeploymentName: "elasticsearch-cdm-3",
+			},
+		},
+	}
+
+	updateNodesReadinessConditions(status)
+
+	_, degraded := getESNodeCondition(status.Conditions, loggingv1.NodesDegraded)
+	if degraded == nil || !strings.Contains(degraded.Message, "elasticsearch-cdm-1 (CrashLoopBackOff)") || strings.Contains(degraded.Message, "elasticsearch-cdm-2") {
+		t.Errorf("expected only elasticsearch-cdm-1 to be degraded, got %v", degraded)
+	}
+
+	_, progressing := getESNodeCondition(status.Conditions, loggingv1.NodesProgressing)
+	if progressing == nil || !strings.Contains(progressing.Message, "elasticsearch-cdm-2") || strings.Contains(progressing.Message, "elasticsearch-cdm-1") {
+		t.Errorf("expected only elasticsearch-cdm-2 to be progressing, got %v", progressing)
+	}
+
+	status.Nodes[0].Conditions = nil
+	status.Nodes[1].Conditions = nil
+	updateNodesReadinessConditions(status)
+
+	if len(status.Conditions) != 0 {
+		t.Errorf("expected readiness conditions to be removed once all nodes are ready, got %v", status.Conditions)
+	}
+}
+
+func conditionHasReason(condition *loggingv1.ClusterCondition, reason string) bool {
+	if reason == "" {
+		return condition == nil
+	}
+	return condition != nil && condition.Status == corev1.ConditionTrue && condition.Reason == reason
+}
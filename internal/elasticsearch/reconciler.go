@@ -2,7 +2,6 @@ package elasticsearch
 
 import (
 	"context"
-	"strconv"
 	"strings"
 
 	"github.com/ViaQ/logerr/kverrors"
@@ -11,18 +10,30 @@ import (
 	elasticsearchv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
 	"github.com/openshift/elasticsearch-operator/internal/constants"
 	"github.com/openshift/elasticsearch-operator/internal/elasticsearch/esclient"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/apply"
 	"github.com/openshift/elasticsearch-operator/internal/manifests/secret"
+	"github.com/openshift/elasticsearch-operator/internal/tracing"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type ElasticsearchRequest struct {
-	client   client.Client
-	cluster  *elasticsearchv1.Elasticsearch
-	esClient esclient.Client
-	ll       logr.Logger
+	client client.Client
+	// reads the objects not cached by the manager, e.g. namespaces
+	apiReader client.Reader
+	cluster   *elasticsearchv1.Elasticsearch
+	esClient  esclient.Client
+	recorder  record.EventRecorder
+	ll        logr.Logger
+	// the span of the reconcile or of its current step, nil unless tracing is enabled
+	span *tracing.Span
+
+	// the number of existing master nodes, read once per reconcile
+	existingMasters *int32
 }
 
 // L is the logger used for this request.
@@ -34,6 +45,17 @@ func (er *ElasticsearchRequest) L() logr.Logger {
 	return er.ll
 }
 
+// startSpan starts a child span of the current one, which it replaces until the returned
+// function ends it
+func (er *ElasticsearchRequest) startSpan(name string, keysAndValues ...interface{}) func() {
+	parent := er.span
+	er.span = parent.Child(name, keysAndValues...)
+	return func() {
+		er.span.End()
+		er.span = parent
+	}
+}
+
 // SecretReconcile returns false if the event needs to be requeued
 func SecretReconcile(requestCluster *elasticsearchv1.Elasticsearch, requestClient client.Client) (bool, error) {
 	var secretChanged bool
@@ -41,12 +63,12 @@ func SecretReconcile(requestCluster *elasticsearchv1.Elasticsearch, requestClien
 	elasticsearchRequest := ElasticsearchRequest{
 		client:  requestClient,
 		cluster: requestCluster,
-		ll:      log.WithValues("cluster", requestCluster.Name, "namespace", requestCluster.Namespace),
+		ll:      utils.NewReconcileLogger(requestCluster.Name, requestCluster.Namespace),
 	}
 
 	// evaluate if we are missing the required secret/certs
 	if ok, missing := elasticsearchRequest.hasRequiredSecrets(); !ok {
-		if err := elasticsearchRequest.UpdateDegradedCondition(true, "Missing Required Secrets", missing); err != nil {
+		if err := elasticsearchRequest.UpdateSummaryConditions("Missing Required Secrets", missing); err != nil {
 			elasticsearchRequest.ll.Error(err, "Unable to set Degraded condition")
 		}
 	}
@@ -119,103 +141,74 @@ func SecretReconcile(requestCluster *elasticsearchv1.Elasticsearch, requestClien
 	return true, nil
 }
 
-func Reconcile(requestCluster *elasticsearchv1.Elasticsearch, requestClient client.Client) error {
-	esClient := esclient.NewClient(requestCluster.Name, requestCluster.Namespace, requestClient)
+// Reconcile ensures the Elasticsearch cluster is up to spec. Status condition changes made
+// along the way are persisted with a single status update at the end.
+func Reconcile(requestCluster *elasticsearchv1.Elasticsearch, requestClient client.Client, apiReader client.Reader, recorder record.EventRecorder) (ReconcileOutcome, error) {
+	ll := utils.NewReconcileLogger(requestCluster.Name, requestCluster.Namespace)
+	b := newStatusBuilder(requestCluster, requestClient)
 
-	elasticsearchRequest := ElasticsearchRequest{
-		client:   requestClient,
-		cluster:  requestCluster,
-		esClient: esClient,
-		ll:       log.WithValues("cluster", requestCluster.Name, "namespace", requestCluster.Namespace),
-	}
+	span := tracing.Start("reconcile", "cluster", requestCluster.Name, "namespace", requestCluster.Namespace)
+	defer span.End()
 
-	// check if we are doing ES cert management looking for annotation:
-	// logging.openshift.io/elasticsearch-cert-management: true
-	value, ok := requestCluster.Annotations[constants.EOCertManagementLabel]
-	if ok {
-		manageBool, _ := strconv.ParseBool(value)
-		if manageBool {
-			cr := NewCertificateRequest(requestCluster.Name, requestCluster.Namespace, requestCluster.GetOwnerRef(), requestClient)
-			cr.GenerateElasticsearchCerts(requestCluster.Name)
-
-			// for any components specified like:
-			// logging.openshift.io/elasticsearch-cert.{secret_name}: {component_name}
-			for annotationKey, componentName := range requestCluster.Annotations {
-				if strings.HasPrefix(annotationKey, constants.EOComponentCertPrefix) {
-					secretName := strings.TrimPrefix(annotationKey, constants.EOComponentCertPrefix)
-
-					cr.GenerateComponentCerts(secretName, componentName)
-				}
-			}
-		}
-	}
-
-	degradedCondition := false
-
-	// Ensure existence of servicesaccount
-	if err := elasticsearchRequest.CreateOrUpdateServiceAccount(); err != nil {
-		return kverrors.Wrap(err, "Failed to reconcile ServiceAccount for Elasticsearch cluster")
-	}
-
-	// Ensure existence of clusterroles and clusterrolebindings
-	if err := elasticsearchRequest.CreateOrUpdateRBAC(); err != nil {
-		return kverrors.Wrap(err, "Failed to reconcile Roles and RoleBindings for Elasticsearch cluster")
-	}
-
-	// Ensure existence of config maps
-	if err := elasticsearchRequest.CreateOrUpdateConfigMaps(); err != nil {
-		return kverrors.Wrap(err, "Failed to reconcile ConfigMaps for Elasticsearch cluster")
-	}
+	err := reconcile(requestCluster, requestClient, apiReader, recorder, ll, span)
 
-	if err := elasticsearchRequest.CreateOrUpdateServices(); err != nil {
-		return kverrors.Wrap(err, "Failed to reconcile Services for Elasticsearch cluster")
+	var outcome ReconcileOutcome
+	if err == nil || IsRequeue(err) {
+		outcome = OutcomeOf(requestCluster, err)
+		err = nil
+		_ = updateReconcileWaitingCondition(requestCluster, outcome, requestClient)
 	}
 
-	if err := elasticsearchRequest.CreateOrUpdateDashboards(); err != nil {
-		return kverrors.Wrap(err, "Failed to reconcile Dashboards for Elasticsearch cluster")
-	}
-
-	// Ensure Elasticsearch cluster itself is up to spec
-	if err := elasticsearchRequest.CreateOrUpdateElasticsearchCluster(); err != nil {
-		return kverrors.Wrap(err, "Failed to reconcile Elasticsearch deployment spec")
-	}
-
-	// Ensure existence of service monitors
-	if err := elasticsearchRequest.CreateOrUpdateServiceMonitors(); err != nil {
-		return kverrors.Wrap(err, "Failed to reconcile Service Monitors for Elasticsearch cluster")
+	if flushErr := b.flush(); flushErr != nil {
+		if err != nil {
+			ll.Error(flushErr, "Unable to update status conditions")
+			return outcome, err
+		}
+		return outcome, flushErr
 	}
 
-	/* Priority for evaluating degraded state
-	   To properly denote priority of degraded states, we check them in the reverse
-	   order of what this list shows (so that the higher priority message can replace
-	   lower priorty ones).
+	return outcome, err
+}
 
-	1. missing certs
-	2. missing prom rules/alerts
-	*/
+func reconcile(requestCluster *elasticsearchv1.Elasticsearch, requestClient client.Client, apiReader client.Reader, recorder record.EventRecorder, ll logr.Logger, span *tracing.Span) error {
+	esClient := esclient.NewClient(requestCluster.Name, requestCluster.Namespace, requestClient)
 
-	// Ensure existence of prometheus rules
-	if err := elasticsearchRequest.CreateOrUpdatePrometheusRules(); err != nil {
-		// no need to error out here, we can just mark ourselves as degraded and report why
-		if err := elasticsearchRequest.UpdateDegradedCondition(true, "Missing Prometheus Rules", err.Error()); err != nil {
-			elasticsearchRequest.ll.Error(err, "Unable to set Degraded condition")
-		}
-		degradedCondition = true
-	}
+	// the objects of the cluster are removed with it by their owner reference
+	requestClient = apply.GuardOwnerReferences(requestClient, requestCluster.Namespace)
 
-	// evaluate if we are missing the required secret/certs
-	if ok, missing := elasticsearchRequest.hasRequiredSecrets(); !ok {
-		if err := elasticsearchRequest.UpdateDegradedCondition(true, "Missing Required Secrets", missing); err != nil {
-			elasticsearchRequest.ll.Error(err, "Unable to set Degraded condition")
+	elasticsearchRequest := ElasticsearchRequest{
+		client:    requestClient,
+		apiReader: apiReader,
+		cluster:   requestCluster,
+		esClient:  esClient,
+		recorder:  recorder,
+		ll:        ll,
+		span:      span,
+	}
+	if span != nil {
+		esClient.SetSendRequestFn(esclient.TracedSendRequest(func() *tracing.Span { return elasticsearchRequest.span }))
+	}
+
+	// check if we are doing ES cert management
+	if isCertManagementEnabled(requestCluster) {
+		cr := NewCertificateRequest(requestCluster.Name, requestCluster.Namespace, requestCluster.GetOwnerRef(), requestClient)
+		cr.Annotations = propagatedAnnotations(requestCluster)
+		cr.GenerateElasticsearchCerts(requestCluster.Name)
+
+		// for any components specified like:
+		// logging.openshift.io/elasticsearch-cert.{secret_name}: {component_name}
+		for annotationKey, componentName := range requestCluster.Annotations {
+			if strings.HasPrefix(annotationKey, constants.EOComponentCertPrefix) {
+				secretName := strings.TrimPrefix(annotationKey, constants.EOComponentCertPrefix)
+
+				cr.GenerateComponentCerts(secretName, componentName)
+			}
 		}
-		degradedCondition = true
 	}
 
-	if !degradedCondition {
-		if err := elasticsearchRequest.UpdateDegradedCondition(false, "", ""); err != nil {
-			elasticsearchRequest.ll.Error(err, "Unable to remove Degraded condition")
-		}
+	if err := elasticsearchRequest.ensureRequiredSecrets(); err != nil {
+		return err
 	}
 
-	return nil
+	return elasticsearchRequest.runReconcileSteps(elasticsearchRequest.reconcileSteps())
 }
@@ -4,7 +4,6 @@ import (
 	"context"
 	"strings"
 
-	"github.com/ViaQ/logerr/log"
 	"github.com/openshift/elasticsearch-operator/internal/manifests/deployment"
 	"github.com/openshift/elasticsearch-operator/internal/manifests/persistentvolume"
 	"github.com/openshift/elasticsearch-operator/internal/manifests/statefulset"
@@ -41,7 +40,7 @@ func (er *ElasticsearchRequest) recoverOrphanedCluster() error {
 		selector := map[string]string{}
 		pvcList, err := persistentvolume.ListPVC(context.TODO(), er.client, er.cluster.Namespace, selector)
 		if err != nil {
-			log.Error(err, "Unable to retrieve PVC list while recovering", "cluster", er.cluster.Name, "namespace", er.cluster.Namespace)
+			er.L().Error(err, "Unable to retrieve PVC list while recovering")
 			return err
 		}
 
@@ -87,7 +86,7 @@ func (er *ElasticsearchRequest) podSpecMatchNode(node loggingv1.ElasticsearchNod
 		return false
 	}
 
-	nodeResources := newESResourceRequirements(node.Resources, er.cluster.Spec.Spec.Resources)
+	nodeResources := newESNodeResourceRequirements(node.Resources, er.cluster.Spec.Spec.Resources, getNodeRoles(node))
 	proxyResources := newESProxyResourceRequirements(node.ProxyResources, er.cluster.Spec.Spec.ProxyResources)
 
 	var deploymentNodeResources corev1.ResourceRequirements
@@ -148,7 +147,7 @@ func (er *ElasticsearchRequest) recoverFromDeployments(knownUUIDs []string, node
 
 		deploymentList, err := deployment.List(context.TODO(), er.client, er.cluster.Namespace, selector)
 		if err != nil {
-			log.Error(err, "Unable to retrieve Deployment list while recovering", "cluster", er.cluster.Name, "namespace", er.cluster.Namespace)
+			er.L().Error(err, "Unable to retrieve Deployment list while recovering")
 			return err
 		}
 
@@ -194,7 +193,7 @@ func (er *ElasticsearchRequest) recoverFromDeployments(knownUUIDs []string, node
 			var deploymentList []appsv1.Deployment
 			deploymentList, err := deployment.List(context.TODO(), er.client, er.cluster.Namespace, selector)
 			if err != nil {
-				log.Error(err, "Unable to retrieve Deployment list while recovering", "cluster", er.cluster.Name, "namespace", er.cluster.Namespace)
+				er.L().Error(err, "Unable to retrieve Deployment list while recovering")
 				return err
 			}
 
@@ -206,7 +205,7 @@ func (er *ElasticsearchRequest) recoverFromDeployments(knownUUIDs []string, node
 				}
 
 				if sliceContainsString(knownUUIDs, uuid) {
-					log.Info("already found while adopting", "knownUUIDs", knownUUIDs, "uuid", uuid)
+					er.L().Info("already found while adopting", "knownUUIDs", knownUUIDs, "uuid", uuid)
 					continue
 				}
 
@@ -224,7 +223,7 @@ func (er *ElasticsearchRequest) recoverFromDeployments(knownUUIDs []string, node
 			var statefulsetList []appsv1.StatefulSet
 			statefulsetList, err := statefulset.List(context.TODO(), er.client, er.cluster.Namespace, selector)
 			if err != nil {
-				log.Error(err, "Unable to retrieve Statefulset list while recovering", "cluster", er.cluster.Name, "namespace", er.cluster.Namespace)
+				er.L().Error(err, "Unable to retrieve Statefulset list while recovering")
 				return err
 			}
 
@@ -236,7 +235,7 @@ func (er *ElasticsearchRequest) recoverFromDeployments(knownUUIDs []string, node
 				}
 
 				if sliceContainsString(knownUUIDs, uuid) {
-					log.Info("already found while adopting", "knownUUIDs", knownUUIDs, "uuid", uuid)
+					er.L().Info("already found while adopting", "knownUUIDs", knownUUIDs, "uuid", uuid)
 					continue
 				}
 
@@ -264,7 +263,7 @@ func (er *ElasticsearchRequest) recoverFromPVCs(knownUUIDs []string, nodesToMatc
 
 	pvcList, err := persistentvolume.ListPVC(context.TODO(), er.client, er.cluster.Namespace, selector)
 	if err != nil {
-		log.Error(err, "Unable to retrieve PVC list while recovering", "cluster", er.cluster.Name, "namespace", er.cluster.Namespace)
+		er.L().Error(err, "Unable to retrieve PVC list while recovering")
 		return err
 	}
 
@@ -318,7 +317,7 @@ func (er *ElasticsearchRequest) recoverFromPVCs(knownUUIDs []string, nodesToMatc
 			}
 
 			if sliceContainsString(knownUUIDs, uuid) {
-				log.Info("already found while adopting", "knownUUIDs", knownUUIDs, "uuid", uuid)
+				er.L().Info("already found while adopting", "knownUUIDs", knownUUIDs, "uuid", uuid)
 				continue
 			}
 
@@ -0,0 +1,76 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"strings"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// unsafeNodeRemovalReason is the reason of the UnsafeNodeRemoval condition, reporting the
+// removal of nodes refused since it would break the cluster
+const unsafeNodeRemovalReason = "Master Quorum At Risk"
+
+// forceNodeRemovalAnnotation removes the nodes of node groups deleted from the spec without
+// draining their shards and verifying the master quorum, e.g. to drop a broken node group
+const forceNodeRemovalAnnotation = "elasticsearch.openshift.io/force-node-removal"
+
+func isForcedNodeRemoval(dpl *api.Elasticsearch) bool {
+	return dpl.Annotations[forceNodeRemovalAnnotation] == "true"
+}
+
+// getMasterQuorumRisk returns why removing the master nodes would break the cluster or
+// an empty string if the ready masters remaining afterwards elect a master of the desired
+// topology by themselves, or of all remaining masters while the master set shrinks
+func (er *ElasticsearchRequest) getMasterQuorumRisk(removed []NodeTypeInterface) string {
+	desired := getMasterCount(er.cluster)
+	if desired == 0 {
+		return "no master nodes would remain"
+	}
+
+	masterPods := er.GetCurrentPodStateMap()[api.ElasticsearchRoleMaster]
+	remaining, total := int32(0), int32(0)
+	for state, podNames := range masterPods {
+		for _, podName := range podNames {
+			if isPodOfNodes(podName, removed) {
+				continue
+			}
+			total++
+			if state == api.PodStateTypeReady {
+				remaining++
+			}
+		}
+	}
+
+	// the remaining masters form the quorum until the master set shrank to the desired count
+	if total > desired {
+		desired = total
+	}
+	if quorum := desired/2 + 1; remaining < quorum {
+		return fmt.Sprintf("%d ready master nodes would remain, %d are required for quorum", remaining, quorum)
+	}
+	return ""
+}
+
+// isPodOfNodes returns true if the pod belongs to the deployment or statefulset of any of the nodes
+func isPodOfNodes(podName string, nodes []NodeTypeInterface) bool {
+	for _, node := range nodes {
+		if strings.HasPrefix(podName, node.name()+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// refuseNodeRemoval reports that the node is kept since removing it would break the cluster
+func (er *ElasticsearchRequest) refuseNodeRemoval(node NodeTypeInterface, risk string) {
+	message := fmt.Sprintf("Refusing to remove node %s: %s. Annotate the cluster with %s=true to remove it anyway",
+		node.name(), risk, forceNodeRemovalAnnotation)
+
+	er.L().Info(message)
+	recordEvent(er.recorder, er.cluster, v1.EventTypeWarning, eventReasonNodeRemovalRefused, message)
+	if err := updateCondition(er.cluster, api.UnsafeNodeRemoval, unsafeNodeRemovalReason, v1.ConditionTrue, message, er.client); err != nil {
+		er.L().Error(err, "unable to update unsafe node removal condition", "node", node.name())
+	}
+}
@@ -0,0 +1,228 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/test/helpers"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetMasterQuorumRisk(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+
+	const (
+		esCluster   = "elasticsearch"
+		esNamespace = "openshift-logging"
+	)
+
+	masterPod := func(name string, ready bool) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: esNamespace,
+				Labels: map[string]string{
+					"component":      "elasticsearch",
+					"cluster-name":   esCluster,
+					"es-node-master": "true",
+				},
+			},
+			Status: corev1.PodStatus{
+				Phase:             corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{Ready: ready}},
+			},
+		}
+	}
+
+	removed := []NodeTypeInterface{
+		&statefulSetNode{self: appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch-cm-old"}}},
+	}
+	oldMasters := []runtime.Object{
+		masterPod("elasticsearch-cm-old-0", true),
+		masterPod("elasticsearch-cm-old-1", true),
+		masterPod("elasticsearch-cm-old-2", true),
+	}
+
+	tests := []struct {
+		desc    string
+		masters int32
+		pods    []runtime.Object
+		risk    string
+	}{
+		{
+			desc:    "remaining masters form a quorum",
+			masters: 3,
+			pods:    []runtime.Object{masterPod("elasticsearch-cm-new-0", true), masterPod("elasticsearch-cm-new-1", true), masterPod("elasticsearch-cm-new-2", false)},
+		},
+		{
+			desc:    "remaining masters not ready",
+			masters: 3,
+			pods:    []runtime.Object{masterPod("elasticsearch-cm-new-0", true), masterPod("elasticsearch-cm-new-1", false)},
+			risk:    "1 ready master nodes would remain, 2 are required for quorum",
+		},
+		{
+			desc:    "shrinking masters keep the quorum of the remaining ones",
+			masters: 1,
+			pods:    []runtime.Object{masterPod("elasticsearch-cm-new-0", true), masterPod("elasticsearch-cm-new-1", false)},
+			risk:    "1 ready master nodes would remain, 2 are required for quorum",
+		},
+		{
+			desc:    "no masters remaining",
+			masters: 0,
+			risk:    "no master nodes would remain",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			cluster := &loggingv1.Elasticsearch{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      esCluster,
+					Namespace: esNamespace,
+				},
+				Spec: loggingv1.ElasticsearchSpec{
+					Nodes: []loggingv1.ElasticsearchNode{
+						{Roles: []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleMaster}, NodeCount: test.masters},
+					},
+				},
+			}
+
+			er := &ElasticsearchRequest{
+				cluster: cluster,
+				client:  fake.NewFakeClient(append(append([]runtime.Object{}, oldMasters...), test.pods...)...),
+			}
+
+			if risk := er.getMasterQuorumRisk(removed); risk != test.risk {
+				t.Errorf("Exp. risk %q but got %q", test.risk, risk)
+			}
+		})
+	}
+}
+
+func TestRefuseNodeRemoval(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch",
+			Namespace: "openshift-logging",
+		},
+	}
+	k8sClient := fake.NewFakeClient(cluster)
+	recorder := record.NewFakeRecorder(1)
+
+	er := &ElasticsearchRequest{
+		cluster:  cluster,
+		client:   k8sClient,
+		recorder: recorder,
+	}
+	node := &statefulSetNode{self: appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch-cm-old"}}}
+
+	er.refuseNodeRemoval(node, "no master nodes would remain")
+
+	event := <-recorder.Events
+	if !strings.HasPrefix(event, fmt.Sprintf("Warning %s Refusing to remove node elasticsearch-cm-old", eventReasonNodeRemovalRefused)) {
+		t.Errorf("Exp. a warning event for the refused removal but got %q", event)
+	}
+
+	current := &loggingv1.Elasticsearch{}
+	if err := k8sClient.Get(context.TODO(), client.ObjectKey{Name: cluster.Name, Namespace: cluster.Namespace}, current); err != nil {
+		t.Fatalf("failed to get cluster: %s", err)
+	}
+	_, condition := getESNodeCondition(current.Status.Conditions, loggingv1.UnsafeNodeRemoval)
+	if condition == nil || condition.Status != corev1.ConditionTrue || !strings.Contains(condition.Message, forceNodeRemovalAnnotation) {
+		t.Errorf("Exp. the unsafe node removal condition to name the force annotation but got %v", condition)
+	}
+}
+
+func TestPopulateNodesExcludesRemovedMasterFromVoting(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+
+	const (
+		esCluster   = "elasticsearch"
+		esNamespace = "openshift-logging"
+	)
+
+	for _, test := range []struct {
+		version  string
+		excluded bool
+	}{
+		{version: "7.10.2", excluded: true},
+		{version: "6.8.1", excluded: false},
+	} {
+		test := test
+		t.Run(test.version, func(t *testing.T) {
+			cluster := &loggingv1.Elasticsearch{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        esCluster,
+					Namespace:   esNamespace,
+					Annotations: map[string]string{forceNodeRemovalAnnotation: "true"},
+				},
+			}
+			master := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "elasticsearch-cdm-old-1",
+					Namespace: esNamespace,
+					Labels: map[string]string{
+						"cluster-name":   esCluster,
+						"es-node-master": "true",
+					},
+				},
+			}
+			k8sClient := fake.NewFakeClient(cluster, master)
+
+			chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+				"_cluster/stats/nodes/_all": {
+					{StatusCode: http.StatusOK, Body: fmt.Sprintf(`{"nodes": {"versions": [%q]}}`, test.version)},
+				},
+				"_cluster/voting_config_exclusions?node_names=elasticsearch-cdm-old-1": {
+					{StatusCode: http.StatusOK, Body: `{}`},
+				},
+				"_cluster/voting_config_exclusions": {
+					{StatusCode: http.StatusOK, Body: `{}`},
+				},
+				"_cluster/settings?flat_settings=true": {
+					{StatusCode: http.StatusOK, Body: `{"persistent": {"cluster.routing.allocation.exclude._name": "elasticsearch-cdm-old-1"}}`},
+				},
+				"_cluster/settings": {
+					{StatusCode: http.StatusOK, Body: `{"acknowledged": true}`},
+				},
+			})
+
+			er := &ElasticsearchRequest{
+				cluster:  cluster,
+				client:   k8sClient,
+				esClient: helpers.NewFakeElasticsearchClient(esCluster, esNamespace, k8sClient, chatter),
+			}
+			key := nodeMapKey(esCluster, esNamespace)
+			nodes = map[string][]NodeTypeInterface{
+				key: {&deploymentNode{self: *master, client: k8sClient}},
+			}
+
+			if err := er.populateNodes(); err != nil {
+				t.Fatalf("failed with error: %s", err)
+			}
+			if len(nodes[key]) != 0 {
+				t.Errorf("Exp. the master node to be removed but got %v", nodes[key])
+			}
+
+			_, added := chatter.GetRequest("_cluster/voting_config_exclusions?node_names=elasticsearch-cdm-old-1")
+			_, cleared := chatter.GetRequest("_cluster/voting_config_exclusions")
+			if added != test.excluded || cleared != test.excluded {
+				t.Errorf("Exp. the voting config exclusion to be added and cleared to be %t but got %t and %t", test.excluded, added, cleared)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ViaQ/logerr/kverrors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/persistentvolume"
+)
+
+// progressNodeGroupReplacements moves each requested node group replacement one step
+// forward. The nodes of the new group are created like any other node, thus a replacement
+// waits for them to join the cluster, relocates the shards off the replaced nodes and
+// finally deletes the replaced nodes. Each step is re-evaluated on the next reconcile.
+func (er *ElasticsearchRequest) progressNodeGroupReplacements() error {
+	cluster := er.cluster
+
+	if err := er.pruneNodeReplacementStatus(); err != nil {
+		return err
+	}
+
+	for _, node := range cluster.Spec.Nodes {
+		if node.Replaces == nil || node.GenUUID == nil {
+			continue
+		}
+
+		replaced, ok := getNodeGroup(cluster, node.Replaces.GenUUID)
+		if !ok || replaced.GenUUID == nil || *replaced.GenUUID == *node.GenUUID {
+			er.L().Info("Skipping replacement of unknown node group", "genUUID", node.Replaces.GenUUID)
+			continue
+		}
+
+		if isNodeGroupReplaced(cluster, replaced) {
+			continue
+		}
+
+		if err := er.replaceNodeGroup(replaced, node); err != nil {
+			return kverrors.Wrap(err, "failed to replace node group",
+				"genUUID", node.Replaces.GenUUID,
+				"replacedBy", *node.GenUUID,
+			)
+		}
+	}
+
+	return nil
+}
+
+func (er *ElasticsearchRequest) replaceNodeGroup(replaced, replacement api.ElasticsearchNode) error {
+	status := api.ElasticsearchNodeReplacementStatus{
+		GenUUID:    *replaced.GenUUID,
+		ReplacedBy: *replacement.GenUUID,
+	}
+
+	for _, node := range er.GetNodeTypeInterface(*replacement.GenUUID, replacement) {
+		joined, err := er.esClient.IsNodeInCluster(node.name())
+		if err != nil || !joined {
+			status.Phase = api.NodeReplacementWaitingForNodes
+			status.Message = fmt.Sprintf("Waiting for node %s to join the cluster", node.name())
+			return er.setNodeReplacementStatus(status, nil)
+		}
+	}
+
+	replacedNodes := er.GetNodeTypeInterface(*replaced.GenUUID, replaced)
+	names := []string{}
+	for _, node := range replacedNodes {
+		names = append(names, node.name())
+	}
+
+	if ok, err := er.esClient.ExcludeNodeFromAllocation(names...); !ok {
+		return kverrors.Wrap(err, "failed to exclude replaced nodes from shard allocation",
+			"nodes", names)
+	}
+
+	for _, name := range names {
+		count, err := er.esClient.GetNodeShardCount(name)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			status.Phase = api.NodeReplacementDraining
+			status.Message = fmt.Sprintf("Waiting for %d shards to relocate off node %s", count, name)
+			return er.setNodeReplacementStatus(status, nil)
+		}
+	}
+
+	if err := er.decommissionNodes(replacedNodes, replacement.Replaces.DeletePersistentVolumeClaims); err != nil {
+		return err
+	}
+
+	if _, err := er.esClient.ClearAllocationExclusions(names...); err != nil {
+		er.L().Error(err, "unable to clear shard allocation exclusions", "nodes", names)
+	}
+
+	status.Phase = api.NodeReplacementCompleted
+	status.Message = fmt.Sprintf("Decommissioned nodes %s", strings.Join(names, ", "))
+	return er.setNodeReplacementStatus(status, names)
+}
+
+// decommissionNodes deletes the nodes and optionally their storage and stops tracking them
+func (er *ElasticsearchRequest) decommissionNodes(decommissioned []NodeTypeInterface, deleteStorage bool) error {
+	cluster := er.cluster
+	key := nodeMapKey(cluster.Name, cluster.Namespace)
+
+	for _, node := range decommissioned {
+		if err := node.delete(); err != nil && !apierrors.IsNotFound(kverrors.Root(err)) {
+			return err
+		}
+
+		if deleteStorage {
+			claim := client.ObjectKey{Name: fmt.Sprintf("%s-%s", cluster.Name, node.name()), Namespace: cluster.Namespace}
+			if err := persistentvolume.DeletePVC(context.TODO(), er.client, claim); err != nil && !apierrors.IsNotFound(kverrors.Root(err)) {
+				return err
+			}
+		}
+
+		if index, ok := containsNodeTypeInterface(node, nodes[key]); ok {
+			nodes[key] = append(nodes[key][:index], nodes[key][index+1:]...)
+		}
+	}
+
+	return nil
+}
+
+// setNodeReplacementStatus records the progress of the node group replacement and
+// removes the status of the decommissioned nodes
+func (er *ElasticsearchRequest) setNodeReplacementStatus(replacement api.ElasticsearchNodeReplacementStatus, decommissioned []string) error {
+	status := er.cluster.Status.DeepCopy()
+
+	for _, name := range decommissioned {
+		if index, _ := getNodeStatus(name, status); index != NotFoundIndex {
+			status.Nodes = append(status.Nodes[:index], status.Nodes[index+1:]...)
+		}
+	}
+
+	found := false
+	for index, current := range status.Replacements {
+		if current.GenUUID == replacement.GenUUID {
+			status.Replacements[index] = replacement
+			found = true
+		}
+	}
+	if !found {
+		status.Replacements = append(status.Replacements, replacement)
+	}
+
+	return er.updateNodeStatus(*status)
+}
+
+// pruneNodeReplacementStatus removes the progress of replacements whose replaced
+// node group was removed from the spec
+func (er *ElasticsearchRequest) pruneNodeReplacementStatus() error {
+	status := er.cluster.Status.DeepCopy()
+
+	replacements := []api.ElasticsearchNodeReplacementStatus{}
+	for _, replacement := range status.Replacements {
+		if _, ok := getNodeGroup(er.cluster, replacement.GenUUID); ok {
+			replacements = append(replacements, replacement)
+		}
+	}
+	if len(replacements) == len(status.Replacements) {
+		return nil
+	}
+
+	status.Replacements = replacements
+	return er.updateNodeStatus(*status)
+}
+
+func getNodeGroup(cluster *api.Elasticsearch, genUUID string) (api.ElasticsearchNode, bool) {
+	for _, node := range cluster.Spec.Nodes {
+		if node.GenUUID != nil && *node.GenUUID == genUUID {
+			return node, true
+		}
+	}
+
+	return api.ElasticsearchNode{}, false
+}
+
+// isNodeGroupReplaced returns true if the node group was decommissioned in favor of another one
+func isNodeGroupReplaced(cluster *api.Elasticsearch, node api.ElasticsearchNode) bool {
+	if node.GenUUID == nil {
+		return false
+	}
+
+	for _, replacement := range cluster.Status.Replacements {
+		if replacement.GenUUID == *node.GenUUID && replacement.Phase == api.NodeReplacementCompleted {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,140 @@
+package elasticsearch
+
+import (
+	"context"
+	"testing"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/test/helpers"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestProgressNodeGroupReplacements(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	nodes = map[string][]NodeTypeInterface{}
+
+	const (
+		esCluster   = "elasticsearch"
+		esNamespace = "openshift-logging"
+		oldNode     = "elasticsearch-d-old-1"
+		newNode     = "elasticsearch-d-new-1"
+	)
+
+	oldUUID, newUUID := "old", "new"
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      esCluster,
+			Namespace: esNamespace,
+		},
+		Spec: loggingv1.ElasticsearchSpec{
+			Nodes: []loggingv1.ElasticsearchNode{
+				{
+					Roles:     []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleData},
+					NodeCount: 1,
+					GenUUID:   &oldUUID,
+				},
+				{
+					Roles:     []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleData},
+					NodeCount: 1,
+					GenUUID:   &newUUID,
+					Replaces: &loggingv1.ElasticsearchNodeReplacementSpec{
+						GenUUID:                      oldUUID,
+						DeletePersistentVolumeClaims: true,
+					},
+				},
+			},
+		},
+		Status: loggingv1.ElasticsearchStatus{
+			Nodes: []loggingv1.ElasticsearchNodeStatus{
+				{DeploymentName: oldNode},
+				{DeploymentName: newNode},
+			},
+		},
+	}
+
+	k8sClient := fake.NewFakeClient(
+		cluster.DeepCopy(),
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: oldNode, Namespace: esNamespace}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: esCluster + "-" + oldNode, Namespace: esNamespace}},
+	)
+
+	acknowledged := helpers.FakeElasticsearchResponse{StatusCode: 200, Body: `{"acknowledged": true}`}
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"_cluster/state/nodes": {
+			{StatusCode: 200, Body: `{"nodes": {"a": {"name": "elasticsearch-d-old-1"}}}`},
+			{StatusCode: 200, Body: `{"nodes": {"a": {"name": "elasticsearch-d-old-1"}, "b": {"name": "elasticsearch-d-new-1"}}}`},
+			{StatusCode: 200, Body: `{"nodes": {"a": {"name": "elasticsearch-d-old-1"}, "b": {"name": "elasticsearch-d-new-1"}}}`},
+		},
+		"_cat/allocation?format=json&h=node,shards": {
+			{StatusCode: 200, Body: `[{"node": "elasticsearch-d-old-1", "shards": "3"}]`},
+			{StatusCode: 200, Body: `[{"node": "elasticsearch-d-old-1", "shards": "0"}]`},
+		},
+		// a node excluded by the user remains excluded
+		"_cluster/settings?flat_settings=true": {
+			{StatusCode: 200, Body: `{"persistent": {"cluster.routing.allocation.exclude._name": "user-node"}}`},
+			{StatusCode: 200, Body: `{"persistent": {"cluster.routing.allocation.exclude._name": "user-node,elasticsearch-d-old-1"}}`},
+			{StatusCode: 200, Body: `{"persistent": {"cluster.routing.allocation.exclude._name": "user-node,elasticsearch-d-old-1"}}`},
+		},
+		"_cluster/settings": {acknowledged, acknowledged},
+	})
+
+	er := &ElasticsearchRequest{
+		cluster:  cluster,
+		client:   k8sClient,
+		esClient: helpers.NewFakeElasticsearchClient(esCluster, esNamespace, k8sClient, chatter),
+	}
+
+	for _, want := range []loggingv1.ElasticsearchNodeReplacementPhase{
+		loggingv1.NodeReplacementWaitingForNodes,
+		loggingv1.NodeReplacementDraining,
+		loggingv1.NodeReplacementCompleted,
+	} {
+		if err := er.progressNodeGroupReplacements(); err != nil {
+			t.Fatalf("failed with error: %s", err)
+		}
+
+		if len(cluster.Status.Replacements) != 1 || cluster.Status.Replacements[0].Phase != want {
+			t.Fatalf("got replacements %v, want phase %q", cluster.Status.Replacements, want)
+		}
+	}
+
+	if !isNodeGroupReplaced(cluster, cluster.Spec.Nodes[0]) {
+		t.Error("Exp. the old node group to be replaced")
+	}
+	if isNodeGroupReplaced(cluster, cluster.Spec.Nodes[1]) {
+		t.Error("Exp. the new node group to not be replaced")
+	}
+	if count := GetDataCount(cluster); count != 1 {
+		t.Errorf("Exp. the replaced node group not to count as data nodes but got %d", count)
+	}
+
+	for _, want := range []string{
+		`{"persistent":{"cluster.routing.allocation.exclude._name":"user-node,elasticsearch-d-old-1"}}`,
+		`{"persistent":{"cluster.routing.allocation.exclude._name":"user-node"}}`,
+	} {
+		req, ok := chatter.GetRequest("_cluster/settings")
+		if !ok || req.Body != want {
+			t.Errorf("Exp. the allocation exclusion request %s but got %v", want, req)
+		}
+	}
+
+	if _, status := getNodeStatus(oldNode, &cluster.Status); status.DeploymentName != "" {
+		t.Errorf("Exp. the status of the decommissioned node to be removed but was %v", cluster.Status.Nodes)
+	}
+
+	key := client.ObjectKey{Name: oldNode, Namespace: esNamespace}
+	if err := k8sClient.Get(context.TODO(), key, &appsv1.Deployment{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Exp. the replaced node deployment to be deleted but got: %v", err)
+	}
+
+	key = client.ObjectKey{Name: esCluster + "-" + oldNode, Namespace: esNamespace}
+	if err := k8sClient.Get(context.TODO(), key, &corev1.PersistentVolumeClaim{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Exp. the replaced node claim to be deleted but got: %v", err)
+	}
+}
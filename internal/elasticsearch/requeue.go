@@ -0,0 +1,140 @@
+package elasticsearch
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ViaQ/logerr/kverrors"
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// RequeueReason is the reason the operator intentionally reconciles a cluster again later
+type RequeueReason string
+
+const (
+	// RequeueWaitingForHealth waits on the cluster to recover its shards
+	RequeueWaitingForHealth RequeueReason = "WaitingForHealth"
+	// RequeueWaitingForNodes waits on nodes to join the cluster
+	RequeueWaitingForNodes RequeueReason = "WaitingForNodes"
+	// RequeueWaitingForSecret waits on a secret required by the cluster
+	RequeueWaitingForSecret RequeueReason = "WaitingForSecret"
+	// RequeueWaitingForRollout waits on the API server to roll out a node
+	RequeueWaitingForRollout RequeueReason = "WaitingForRollout"
+	// RequeueRolloutInProgress keeps track of a node rollout or cert redeploy in progress
+	RequeueRolloutInProgress RequeueReason = "RolloutInProgress"
+	// RequeueWaitingForMaintenanceWindow waits on the maintenance window to start a node rollout
+	RequeueWaitingForMaintenanceWindow RequeueReason = "WaitingForMaintenanceWindow"
+	// RequeueWaitingForDrain waits on the shards to relocate off a node before it is removed
+	RequeueWaitingForDrain RequeueReason = "WaitingForDrain"
+)
+
+// DefaultRequeueAfter is the interval after which a waiting reconcile is retried
+const DefaultRequeueAfter = 30 * time.Second
+
+// minSecretRequeueAfter and maxSecretRequeueAfter bound the interval after which a reconcile
+// waiting on a secret is retried, growing with the time waited so far
+const (
+	minSecretRequeueAfter = 5 * time.Second
+	maxSecretRequeueAfter = 5 * time.Minute
+)
+
+// ReconcileOutcome is the outcome of a reconcile without failures. A reconcile with a
+// reason waits on the cluster, otherwise it converged.
+type ReconcileOutcome struct {
+	Reason       RequeueReason
+	Message      string
+	RequeueAfter time.Duration
+}
+
+// IsWaiting returns true if the reconcile waits on the cluster to be retried later
+func (o ReconcileOutcome) IsWaiting() bool {
+	return o.Reason != ""
+}
+
+// requeueError signals that the reconcile has to be retried later since it waits on the
+// API server or the cluster, as opposed to having failed
+type requeueError struct {
+	error
+	reason RequeueReason
+}
+
+func newRequeueError(reason RequeueReason, msg string, keysAndValues ...interface{}) error {
+	return &requeueError{error: kverrors.New(msg, keysAndValues...), reason: reason}
+}
+
+func (e *requeueError) Unwrap() error {
+	return e.error
+}
+
+// IsRequeue returns true if the error only signals to retry the reconcile later
+func IsRequeue(err error) bool {
+	var re *requeueError
+	return errors.As(err, &re)
+}
+
+// OutcomeOf returns the outcome of a reconcile of the cluster returning the error, which
+// must be nil or signal a requeue
+func OutcomeOf(cluster *api.Elasticsearch, err error) ReconcileOutcome {
+	var re *requeueError
+	if errors.As(err, &re) {
+		return ReconcileOutcome{
+			Reason:       re.reason,
+			Message:      re.Error(),
+			RequeueAfter: requeueAfter(cluster, re.reason),
+		}
+	}
+
+	// a rollout only scheduled but deferred waits on the window, a started one is tracked
+	window := cluster.Spec.MaintenanceWindow
+	deferred := window != nil && containsClusterCondition(api.DeferredOutsideWindow, v1.ConditionTrue, &cluster.Status)
+	if IsRolloutInProgress(cluster) && (!deferred || isRolloutStarted(&cluster.Status)) {
+		return ReconcileOutcome{
+			Reason:       RequeueRolloutInProgress,
+			Message:      "A node rollout or cert redeploy is in progress",
+			RequeueAfter: DefaultRequeueAfter,
+		}
+	}
+
+	if deferred {
+		now := time.Now()
+		return ReconcileOutcome{
+			Reason:       RequeueWaitingForMaintenanceWindow,
+			Message:      "A node rollout waits on the maintenance window",
+			RequeueAfter: nextMaintenanceWindowOpening(window, now).Sub(now),
+		}
+	}
+
+	if _, condition := getESNodeCondition(cluster.Status.Conditions, api.NodeDraining); condition != nil && condition.Status == v1.ConditionTrue {
+		return ReconcileOutcome{
+			Reason:       RequeueWaitingForDrain,
+			Message:      condition.Message,
+			RequeueAfter: DefaultRequeueAfter,
+		}
+	}
+
+	return ReconcileOutcome{}
+}
+
+// requeueAfter returns the interval after which a reconcile waiting for the reason is
+// retried. Waiting on a secret backs off, since it is provided by an external party
+// which may take long.
+func requeueAfter(cluster *api.Elasticsearch, reason RequeueReason) time.Duration {
+	if reason != RequeueWaitingForSecret {
+		return DefaultRequeueAfter
+	}
+
+	_, condition := getESNodeCondition(cluster.Status.Conditions, api.ReconcileWaiting)
+	if condition == nil || condition.Status != v1.ConditionTrue || condition.Reason != string(reason) {
+		return minSecretRequeueAfter
+	}
+
+	waited := time.Since(condition.LastTransitionTime.Time)
+	switch {
+	case waited < minSecretRequeueAfter:
+		return minSecretRequeueAfter
+	case waited > maxSecretRequeueAfter:
+		return maxSecretRequeueAfter
+	}
+	return waited
+}
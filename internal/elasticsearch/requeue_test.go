@@ -0,0 +1,87 @@
+package elasticsearch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ViaQ/logerr/kverrors"
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestOutcomeOf(t *testing.T) {
+	cluster := &loggingv1.Elasticsearch{}
+
+	if outcome := OutcomeOf(cluster, nil); outcome.IsWaiting() {
+		t.Errorf("Exp. a converged cluster not to be waited on but got %v", outcome)
+	}
+
+	err := kverrors.Wrap(newRequeueError(RequeueWaitingForNodes, "master node has not yet joined the cluster"), "Failed to reconcile")
+	outcome := OutcomeOf(cluster, err)
+	if outcome.Reason != RequeueWaitingForNodes || outcome.RequeueAfter != DefaultRequeueAfter {
+		t.Errorf("Exp. the wrapped requeue reason %q but got %v", RequeueWaitingForNodes, outcome)
+	}
+
+	cluster.Status.Conditions = []loggingv1.ClusterCondition{
+		{Type: loggingv1.Restarting, Status: corev1.ConditionTrue},
+	}
+	if outcome := OutcomeOf(cluster, nil); outcome.Reason != RequeueRolloutInProgress {
+		t.Errorf("Exp. the reason %q for a restarting cluster but got %v", RequeueRolloutInProgress, outcome)
+	}
+}
+
+func TestUpdateReconcileWaitingCondition(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch", Namespace: "openshift-logging"},
+	}
+	k8sClient := fake.NewFakeClient(cluster.DeepCopy())
+
+	outcome := ReconcileOutcome{Reason: RequeueWaitingForHealth, Message: "waiting on shard recovery"}
+	if err := updateReconcileWaitingCondition(cluster, outcome, k8sClient); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, condition := getESNodeCondition(cluster.Status.Conditions, loggingv1.ReconcileWaiting)
+	if condition == nil || condition.Reason != string(RequeueWaitingForHealth) || condition.Message != outcome.Message {
+		t.Errorf("Exp. the waiting reason to be reported but got %v", condition)
+	}
+
+	if err := updateReconcileWaitingCondition(cluster, ReconcileOutcome{}, k8sClient); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, condition := getESNodeCondition(cluster.Status.Conditions, loggingv1.ReconcileWaiting); condition != nil {
+		t.Errorf("Exp. the condition to be removed once converged but got %v", condition)
+	}
+}
+
+func TestRequeueAfterWaitingForSecret(t *testing.T) {
+	cluster := &loggingv1.Elasticsearch{}
+	if got := requeueAfter(cluster, RequeueWaitingForSecret); got != minSecretRequeueAfter {
+		t.Errorf("Exp. the first wait on a secret to requeue after %s but got %s", minSecretRequeueAfter, got)
+	}
+
+	cluster.Status.Conditions = []loggingv1.ClusterCondition{
+		{
+			Type:               loggingv1.ReconcileWaiting,
+			Status:             corev1.ConditionTrue,
+			Reason:             string(RequeueWaitingForSecret),
+			LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Minute)),
+		},
+	}
+	if got := requeueAfter(cluster, RequeueWaitingForSecret); got < time.Minute || got >= maxSecretRequeueAfter {
+		t.Errorf("Exp. the requeue to back off with the time waited but got %s", got)
+	}
+
+	cluster.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Hour))
+	if got := requeueAfter(cluster, RequeueWaitingForSecret); got != maxSecretRequeueAfter {
+		t.Errorf("Exp. the backoff to be capped at %s but got %s", maxSecretRequeueAfter, got)
+	}
+
+	if got := requeueAfter(cluster, RequeueWaitingForNodes); got != DefaultRequeueAfter {
+		t.Errorf("Exp. other reasons to requeue after %s but got %s", DefaultRequeueAfter, got)
+	}
+}
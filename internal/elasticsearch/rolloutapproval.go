@@ -0,0 +1,140 @@
+package elasticsearch
+
+import (
+	"context"
+	"strings"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/deployment"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/pod"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/statefulset"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// approveRolloutAnnotation approves the pending rollout whose ID it is set to
+const approveRolloutAnnotation = "elasticsearch.openshift.io/approve-rollout"
+
+// isRolloutApproved returns true if the rollout of the scheduled nodes may start. Clusters
+// requiring approval record the rollout as pending in the status until they are annotated
+// with its ID, any other rollout is approved. The ID derives from the desired state of the
+// nodes, thus the approval holds for every node of the rollout until it completes.
+func (er *ElasticsearchRequest) isRolloutApproved(scheduledNodes []NodeTypeInterface) (bool, error) {
+	dpl := er.cluster
+
+	if !dpl.Spec.RequireRolloutApproval || len(scheduledNodes) == 0 {
+		return true, er.setPendingRollout(nil)
+	}
+
+	pending := er.newPendingRollout(scheduledNodes)
+	if pending.ID != "" && dpl.Annotations[approveRolloutAnnotation] == pending.ID {
+		er.L().Info("Rolling out approved node changes", "id", pending.ID, "nodes", len(scheduledNodes))
+		return true, er.setPendingRollout(nil)
+	}
+
+	// keep the time the same rollout first became pending
+	if current := dpl.Status.PendingRollout; current != nil && current.ID == pending.ID {
+		pending.Since = current.Since
+	}
+
+	er.L().Info("Node rollout waits on approval", "id", pending.ID, "annotation", approveRolloutAnnotation)
+	return false, er.setPendingRollout(pending)
+}
+
+// newPendingRollout describes the changes the rollout of the scheduled nodes applies
+func (er *ElasticsearchRequest) newPendingRollout(scheduledNodes []NodeTypeInterface) *api.ElasticsearchPendingRollout {
+	pending := &api.ElasticsearchPendingRollout{
+		Since: metav1.Now(),
+		Nodes: []api.ElasticsearchPendingNodeRollout{},
+	}
+
+	for _, node := range scheduledNodes {
+		pending.Nodes = append(pending.Nodes, api.ElasticsearchPendingNodeRollout{
+			Name:    node.name(),
+			Changes: er.getPendingNodeChanges(node),
+		})
+	}
+
+	pending.ID = er.desiredRolloutID()
+
+	return pending
+}
+
+// desiredRolloutID hashes the desired pod templates of all nodes of the cluster and its
+// desired configuration. Unlike the pending changes it stays the same while the nodes
+// of the rollout are updated one after the other
+func (er *ElasticsearchRequest) desiredRolloutID() string {
+	desired := []string{er.cluster.Status.ConfigHash}
+
+	for _, node := range nodes[nodeMapKey(er.cluster.Name, er.cluster.Namespace)] {
+		switch n := node.(type) {
+		case *deploymentNode:
+			desired = append(desired, n.name(), podTemplateHash(n.self.Spec.Template))
+		case *statefulSetNode:
+			desired = append(desired, n.name(), podTemplateHash(n.self.Spec.Template))
+		}
+	}
+
+	id, err := utils.CalculateMD5Hash(strings.Join(desired, ","))
+	if err != nil {
+		return ""
+	}
+
+	return id
+}
+
+// getPendingNodeChanges returns the fields of the pod template of the node that differ
+// from the one rolled out and whether its configuration changed since it restarted
+func (er *ElasticsearchRequest) getPendingNodeChanges(node NodeTypeInterface) []string {
+	changes := []string{}
+
+	var current, desired *v1.PodTemplateSpec
+	switch n := node.(type) {
+	case *deploymentNode:
+		key := client.ObjectKey{Name: n.name(), Namespace: n.self.Namespace}
+		if dpl, err := deployment.Get(context.TODO(), er.client, key); err == nil {
+			current, desired = &dpl.Spec.Template, &n.self.Spec.Template
+		}
+	case *statefulSetNode:
+		key := client.ObjectKey{Name: n.name(), Namespace: n.self.Namespace}
+		if sts, err := statefulset.Get(context.TODO(), er.client, key); err == nil {
+			current, desired = &sts.Spec.Template, &n.self.Spec.Template
+		}
+	}
+	if current != nil {
+		changes = append(changes, pod.DiffPodTemplateSpec(*current, *desired)...)
+	}
+
+	status := er.cluster.Status
+	if _, nodeStatus := getNodeStatus(node.name(), &status); nodeStatus != nil &&
+		status.ConfigHash != "" && nodeStatus.LastAppliedConfigHash != status.ConfigHash {
+		changes = append(changes, "configuration")
+	}
+
+	return changes
+}
+
+// setPendingRollout records the rollout awaiting approval in the status, clearing it if nil
+func (er *ElasticsearchRequest) setPendingRollout(pending *api.ElasticsearchPendingRollout) error {
+	if pending == nil && er.cluster.Status.PendingRollout == nil {
+		return nil
+	}
+
+	return updateConditionWithRetry(er.cluster, v1.ConditionTrue, func(status *api.ElasticsearchStatus, _ v1.ConditionStatus) bool {
+		if pending == nil {
+			if status.PendingRollout == nil {
+				return false
+			}
+			status.PendingRollout = nil
+			return true
+		}
+
+		if current := status.PendingRollout; current != nil && current.ID == pending.ID {
+			return false
+		}
+		status.PendingRollout = pending
+		return true
+	}, er.client)
+}
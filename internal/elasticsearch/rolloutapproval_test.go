@@ -0,0 +1,139 @@
+package elasticsearch
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestIsRolloutApproved(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+
+	const (
+		esCluster   = "elasticsearch"
+		esNamespace = "openshift-logging"
+		nodeName    = "elasticsearch-cdm-1-deadbeef"
+	)
+
+	newDeployment := func(image string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      nodeName,
+				Namespace: esNamespace,
+			},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "elasticsearch", Image: image}},
+					},
+				},
+			},
+		}
+	}
+
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      esCluster,
+			Namespace: esNamespace,
+		},
+		Spec: loggingv1.ElasticsearchSpec{
+			RequireRolloutApproval: true,
+		},
+		Status: loggingv1.ElasticsearchStatus{
+			ConfigHash: "new",
+			Nodes: []loggingv1.ElasticsearchNodeStatus{
+				{DeploymentName: nodeName, LastAppliedConfigHash: "old"},
+			},
+		},
+	}
+
+	k8sClient := fake.NewFakeClient(cluster, newDeployment("elasticsearch:6.8.1"))
+	er := &ElasticsearchRequest{
+		cluster: cluster,
+		client:  k8sClient,
+	}
+	scheduled := []NodeTypeInterface{
+		&deploymentNode{self: *newDeployment("elasticsearch:6.8.2"), client: k8sClient},
+	}
+	nodes[nodeMapKey(esCluster, esNamespace)] = scheduled
+	defer delete(nodes, nodeMapKey(esCluster, esNamespace))
+
+	getPending := func() *loggingv1.ElasticsearchPendingRollout {
+		current := &loggingv1.Elasticsearch{}
+		if err := k8sClient.Get(context.TODO(), client.ObjectKey{Name: esCluster, Namespace: esNamespace}, current); err != nil {
+			t.Fatalf("failed to get cluster: %s", err)
+		}
+		return current.Status.PendingRollout
+	}
+
+	approved, err := er.isRolloutApproved(scheduled)
+	if err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+	if approved {
+		t.Error("Exp. the rollout to wait on approval")
+	}
+
+	pending := getPending()
+	if pending == nil || pending.ID == "" {
+		t.Fatalf("Exp. the pending rollout to be recorded but got %v", pending)
+	}
+	want := []loggingv1.ElasticsearchPendingNodeRollout{
+		{Name: nodeName, Changes: []string{"containers[elasticsearch].image", "configuration"}},
+	}
+	if !reflect.DeepEqual(pending.Nodes, want) {
+		t.Errorf("Exp. the pending changes %v but got %v", want, pending.Nodes)
+	}
+
+	// approving a different rollout does not start this one
+	er.cluster.Annotations = map[string]string{approveRolloutAnnotation: "stale"}
+	if approved, _ := er.isRolloutApproved(scheduled); approved {
+		t.Error("Exp. a stale approval not to start the rollout")
+	}
+
+	er.cluster.Annotations = map[string]string{approveRolloutAnnotation: pending.ID}
+	if approved, _ := er.isRolloutApproved(scheduled); !approved {
+		t.Error("Exp. the approved rollout to start")
+	}
+	if pending := getPending(); pending != nil {
+		t.Errorf("Exp. the pending rollout to be cleared once approved but got %v", pending)
+	}
+
+	// the approval holds for the nodes left once the first ones are updated
+	nodes[nodeMapKey(esCluster, esNamespace)] = append(scheduled,
+		&deploymentNode{self: *newDeployment("elasticsearch:6.8.2"), client: k8sClient},
+	)
+	nodes[nodeMapKey(esCluster, esNamespace)][1].(*deploymentNode).self.Name = nodeName + "-2"
+	approvedID := er.desiredRolloutID()
+	er.cluster.Annotations = map[string]string{approveRolloutAnnotation: approvedID}
+	if approved, _ := er.isRolloutApproved(nodes[nodeMapKey(esCluster, esNamespace)]); !approved {
+		t.Error("Exp. the approved rollout of both nodes to start")
+	}
+	if approved, _ := er.isRolloutApproved(nodes[nodeMapKey(esCluster, esNamespace)][1:]); !approved {
+		t.Error("Exp. the approval to hold until the rollout completes")
+	}
+
+	// changing the desired state again requires another approval
+	nodes[nodeMapKey(esCluster, esNamespace)][1].(*deploymentNode).self.Spec.Template.Spec.Containers[0].Image = "elasticsearch:6.8.3"
+	if approved, _ := er.isRolloutApproved(nodes[nodeMapKey(esCluster, esNamespace)][1:]); approved {
+		t.Error("Exp. a changed rollout to wait on approval again")
+	}
+	if pending := getPending(); pending == nil || pending.ID == approvedID {
+		t.Errorf("Exp. the changed rollout to be pending with a new ID but got %v", pending)
+	}
+
+	// rollouts of clusters not requiring approval start right away
+	er.cluster.Annotations = nil
+	er.cluster.Spec.RequireRolloutApproval = false
+	if approved, _ := er.isRolloutApproved(scheduled); !approved {
+		t.Error("Exp. the rollout to start without requiring approval")
+	}
+}
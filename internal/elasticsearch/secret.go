@@ -3,24 +3,37 @@ package elasticsearch
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/ViaQ/logerr/kverrors"
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
 	"github.com/openshift/elasticsearch-operator/internal/constants"
 	"github.com/openshift/elasticsearch-operator/internal/manifests/secret"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func CreateOrUpdateSecretWithOwnerRef(secretName, namespace string, data map[string][]byte, client client.Client, ownerRef metav1.OwnerReference) error {
+func CreateOrUpdateSecretWithOwnerRef(secretName, namespace string, data map[string][]byte, propagated map[string]string, client client.Client, ownerRef metav1.OwnerReference) error {
 	s := secret.New(secretName, namespace, data)
+	s.Annotations = utils.WithPropagatedAnnotations(s.Annotations, propagated)
 
 	// add owner ref to secret
 	s.OwnerReferences = append(s.OwnerReferences, ownerRef)
 
-	err := secret.CreateOrUpdate(context.TODO(), client, s, secret.DataEqual, secret.MutateDataOnly)
+	equalFunc := func(current, desired *corev1.Secret) bool {
+		return secret.DataEqual(current, desired) && utils.ContainsPropagatedAnnotations(current.Annotations, desired.Annotations)
+	}
+	mutateFunc := func(current, desired *corev1.Secret) {
+		secret.MutateDataOnly(current, desired)
+		current.Annotations = utils.MergePropagatedAnnotations(current.Annotations, desired.Annotations)
+	}
+
+	err := secret.CreateOrUpdate(context.TODO(), client, s, equalFunc, mutateFunc)
 	if err != nil {
 		return kverrors.Wrap(err, "failed to create or update elasticsearch secret",
 			"owner_ref_name", ownerRef.Name,
@@ -41,6 +54,47 @@ func CreateOrUpdateSecret(secretName, namespace string, data map[string][]byte,
 	return nil
 }
 
+// generateMissingSecrets enables generating self-signed certificates into the secret of
+// clusters missing it, if they opt into the operator cert management
+var generateMissingSecrets = false
+
+// SetGenerateMissingSecrets sets whether the operator generates self-signed certificates
+// into missing secrets instead of waiting on them to be provided
+func SetGenerateMissingSecrets(enabled bool) {
+	generateMissingSecrets = enabled
+}
+
+// ensureRequiredSecrets returns an error requeueing the reconcile unless the required
+// secrets exist, since the nodes cannot start and the operator cannot communicate with
+// the cluster without them. Missing secrets are generated first if enabled and the cluster
+// opts into the operator cert management.
+func (er *ElasticsearchRequest) ensureRequiredSecrets() error {
+	ok, missing := er.hasRequiredSecrets()
+	if !ok && generateMissingSecrets && isCertManagementEnabled(er.cluster) {
+		er.L().Info("Generating self-signed certificates into the missing secret", "reason", missing)
+		cr := NewCertificateRequest(er.cluster.Name, er.cluster.Namespace, er.cluster.GetOwnerRef(), er.client)
+		cr.Annotations = propagatedAnnotations(er.cluster)
+		cr.GenerateElasticsearchCerts(er.cluster.Name)
+		ok, missing = er.hasRequiredSecrets()
+	}
+
+	if ok {
+		return nil
+	}
+
+	if err := er.UpdateSummaryConditions("Missing Required Secrets", missing); err != nil {
+		er.L().Error(err, "Unable to set Degraded condition")
+	}
+	return newRequeueError(RequeueWaitingForSecret, missing)
+}
+
+// isCertManagementEnabled returns true if the cluster opts into the operator cert management
+// with the annotation logging.openshift.io/elasticsearch-cert-management: true
+func isCertManagementEnabled(cluster *api.Elasticsearch) bool {
+	enabled, _ := strconv.ParseBool(cluster.Annotations[constants.EOCertManagementLabel])
+	return enabled
+}
+
 // hasRequiredSecrets will check that all secrets that we expect for EO to be able to communicate
 // with the ES cluster it manages exist.
 // It will return true if all required secrets/keys exist.
@@ -0,0 +1,91 @@
+package elasticsearch
+
+import (
+	"context"
+	"testing"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/constants"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestEnsureRequiredSecretsWaitsForExternalSecret(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch", Namespace: "openshift-logging"},
+	}
+	er := &ElasticsearchRequest{
+		cluster: cluster,
+		client:  fake.NewFakeClient(cluster.DeepCopy()),
+	}
+
+	err := er.ensureRequiredSecrets()
+	if !IsRequeue(err) {
+		t.Fatalf("Exp. a missing secret to requeue instead of failing but got %v", err)
+	}
+	if outcome := OutcomeOf(cluster, err); outcome.Reason != RequeueWaitingForSecret {
+		t.Errorf("Exp. the reason %q but got %v", RequeueWaitingForSecret, outcome)
+	}
+	if !containsClusterCondition(loggingv1.DegradedState, corev1.ConditionTrue, &cluster.Status) {
+		t.Errorf("Exp. the cluster to be degraded but got %v", cluster.Status.Conditions)
+	}
+}
+
+func TestEnsureRequiredSecretsGeneratesMissingSecret(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	SetGenerateMissingSecrets(true)
+	defer SetGenerateMissingSecrets(false)
+
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch", Namespace: "openshift-logging"},
+	}
+	k8sClient := fake.NewFakeClient(cluster.DeepCopy())
+	er := &ElasticsearchRequest{
+		cluster: cluster,
+		client:  k8sClient,
+	}
+
+	if err := er.ensureRequiredSecrets(); !IsRequeue(err) {
+		t.Fatalf("Exp. no secret to be generated for a cluster not opting into the cert management but got %v", err)
+	}
+
+	cluster.Annotations = map[string]string{constants.EOCertManagementLabel: "true"}
+	if err := er.ensureRequiredSecrets(); err != nil {
+		t.Fatalf("Exp. the missing secret to be generated but got %v", err)
+	}
+
+	sec := &corev1.Secret{}
+	if err := k8sClient.Get(context.TODO(), types.NamespacedName{Name: "elasticsearch", Namespace: "openshift-logging"}, sec); err != nil {
+		t.Fatalf("Exp. the secret to be created but got %v", err)
+	}
+	for _, key := range constants.ExpectedSecretKeys {
+		if len(sec.Data[key]) == 0 {
+			t.Errorf("Exp. the generated secret to hold %s", key)
+		}
+	}
+}
+
+func TestCreateOrUpdateSecretWithOwnerRefPropagatesAnnotations(t *testing.T) {
+	k8sClient := fake.NewFakeClient()
+	data := map[string][]byte{"key": []byte("value")}
+	key := types.NamespacedName{Name: "elasticsearch", Namespace: "openshift-logging"}
+
+	if err := CreateOrUpdateSecretWithOwnerRef(key.Name, key.Namespace, data, map[string]string{"cost-center": "1234"}, k8sClient, metav1.OwnerReference{}); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+	if err := CreateOrUpdateSecretWithOwnerRef(key.Name, key.Namespace, data, map[string]string{"cost-center": "5678"}, k8sClient, metav1.OwnerReference{}); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+
+	sec := &corev1.Secret{}
+	if err := k8sClient.Get(context.TODO(), key, sec); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+	if got := sec.Annotations["cost-center"]; got != "5678" {
+		t.Errorf("Exp. a changed propagated annotation to update the secret but got %q", got)
+	}
+}
@@ -3,18 +3,28 @@ package elasticsearch
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/ViaQ/logerr/kverrors"
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
 	"github.com/openshift/elasticsearch-operator/internal/manifests/service"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// serviceReadinessReason is the reason of the ServiceReadinessOverridden condition, reporting
+// the services overriding the membership of not ready nodes in a way hindering the rollouts
+const serviceReadinessReason = "Service Readiness Overridden"
+
 // CreateOrUpdateServices ensures the existence of the services for Elasticsearch cluster
 func (er *ElasticsearchRequest) CreateOrUpdateServices() error {
 	dpl := er.cluster
 
-	annotations := make(map[string]string)
 	serviceName := fmt.Sprintf("%s-%s", dpl.Name, "cluster")
 
 	errCtx := kverrors.NewContext("service_name", serviceName,
@@ -26,26 +36,26 @@ func (er *ElasticsearchRequest) CreateOrUpdateServices() error {
 		serviceName,
 		dpl.Namespace,
 		dpl.Name,
-		"cluster",
-		9300,
+		[]v1.ServicePort{newServicePort(dpl.Name, "cluster", defaultDiscoveryServicePort)},
 		selectorForES("es-node-master", dpl.Name),
-		annotations,
-		true,
+		serviceAnnotations(discoveryServiceSpec(dpl), nil),
+		discoveryPublishNotReady(dpl),
 		map[string]string{},
 	)
 	if err != nil {
 		return errCtx.Wrap(err, "failed to create service")
 	}
 
+	// the client service publishes ready addresses only, thus rollouts rely on it to stop
+	// routing queries to restarting nodes while they still discover each other
 	err = er.createOrUpdateService(
 		dpl.Name,
 		dpl.Namespace,
 		dpl.Name,
-		"restapi",
-		9200,
+		clientServicePorts(dpl),
 		selectorForES("es-node-client", dpl.Name),
-		annotations,
-		false,
+		serviceAnnotations(clientServiceSpec(dpl), nil),
+		clientPublishNotReady(dpl),
 		map[string]string{},
 	)
 	if err != nil {
@@ -53,16 +63,17 @@ func (er *ElasticsearchRequest) CreateOrUpdateServices() error {
 	}
 
 	// legacy metrics service that likely can be rolled into the single service that goes through the proxy
-	annotations["service.beta.openshift.io/serving-cert-secret-name"] = fmt.Sprintf("%s-%s", dpl.Name, "metrics")
+	annotations := serviceAnnotations(metricsServiceSpec(dpl), map[string]string{
+		"service.beta.openshift.io/serving-cert-secret-name": fmt.Sprintf("%s-%s", dpl.Name, "metrics"),
+	})
 	err = er.createOrUpdateService(
 		fmt.Sprintf("%s-%s", dpl.Name, "metrics"),
 		dpl.Namespace,
 		dpl.Name,
-		"metrics",
-		60001,
+		[]v1.ServicePort{newServicePort(dpl.Name, "metrics", 60001)},
 		selectorForES("es-node-client", dpl.Name),
 		annotations,
-		false,
+		metricsPublishNotReady(dpl),
 		map[string]string{
 			"scrape-metrics": "enabled",
 		},
@@ -70,30 +81,173 @@ func (er *ElasticsearchRequest) CreateOrUpdateServices() error {
 	if err != nil {
 		return errCtx.Wrap(err, "failed to create service")
 	}
+
+	if err := er.createOrDeleteDiscoveryService(); err != nil {
+		return errCtx.Wrap(err, "failed to reconcile discovery service")
+	}
+
+	if err := er.reportServiceReadiness(); err != nil {
+		er.L().Error(err, "Unable to update the service readiness status")
+	}
+
+	if err := updateConditionWithRetry(dpl, v1.ConditionTrue, func(status *api.ElasticsearchStatus, _ v1.ConditionStatus) bool {
+		endpoints := getEndpoints(dpl)
+		if status.Endpoints != nil && *status.Endpoints == *endpoints {
+			return false
+		}
+		status.Endpoints = endpoints
+		return true
+	}, er.client); err != nil {
+		er.L().Error(err, "Unable to update the endpoints status")
+	}
 	return nil
 }
 
-func (er *ElasticsearchRequest) createOrUpdateService(serviceName, namespace, clusterName, targetPortName string, port int32, selector, annotations map[string]string, publishNotReady bool, labels map[string]string) error {
-	client := er.client
-	cluster := er.cluster
+// reportServiceReadiness reports the service readiness overrides hindering the rollouts with
+// the ServiceReadinessOverridden condition and a warning event whenever they change
+func (er *ElasticsearchRequest) reportServiceReadiness() error {
+	dpl := er.cluster
+
+	warnings := getServiceReadinessWarnings(dpl)
+	if len(warnings) == 0 {
+		return updateCondition(dpl, api.ServiceReadiness, serviceReadinessReason, v1.ConditionFalse, "", er.client)
+	}
+
+	message := strings.Join(warnings, "; ")
+	if _, condition := getESNodeCondition(dpl.Status.Conditions, api.ServiceReadiness); condition != nil &&
+		condition.Status == v1.ConditionTrue && condition.Message == message {
+		return nil
+	}
+
+	er.L().Info(message)
+	recordEvent(er.recorder, dpl, v1.EventTypeWarning, eventReasonServiceReadiness, message)
+	return updateCondition(dpl, api.ServiceReadiness, serviceReadinessReason, v1.ConditionTrue, message, er.client)
+}
+
+// getEndpoints returns the addresses of the services of the cluster, with the discovery
+// service being the headless one if it is the configured discovery provider
+func getEndpoints(dpl *api.Elasticsearch) *api.ElasticsearchEndpointsStatus {
+	discoveryServiceName := fmt.Sprintf("%s-%s", dpl.Name, "cluster")
+	if isHeadlessDiscovery(dpl) {
+		discoveryServiceName = esDiscoveryServiceName(dpl.Name)
+	}
+
+	clientHost := fmt.Sprintf("%s.%s.svc", dpl.Name, dpl.Namespace)
+	return &api.ElasticsearchEndpointsStatus{
+		DiscoveryService:   fmt.Sprintf("%s.%s.svc:%d", discoveryServiceName, dpl.Namespace, defaultDiscoveryServicePort),
+		ClientService:      fmt.Sprintf("%s:%d", clientHost, clientServicePort(dpl)),
+		ClientServiceHTTPS: fmt.Sprintf("https://%s:%d", clientHost, clientServicePort(dpl)),
+	}
+}
+
+// newServicePort returns a TCP port of a service forwarding to the named port of the pods
+func newServicePort(name, targetPortName string, port int32) v1.ServicePort {
+	return v1.ServicePort{
+		Port:       port,
+		Protocol:   v1.ProtocolTCP,
+		TargetPort: intstr.FromString(targetPortName),
+		Name:       name,
+	}
+}
+
+// clientServicePorts returns the ports of the client service. A custom port is published
+// next to the default one, since the operator, the index management and Kibana connect to
+// the latter.
+func clientServicePorts(dpl *api.Elasticsearch) []v1.ServicePort {
+	port := clientServicePort(dpl)
+	ports := []v1.ServicePort{newServicePort(clientServicePortName(dpl), restAPIPortName, port)}
+	if port != defaultClientServicePort {
+		ports = append(ports, newServicePort(restAPIPortName, restAPIPortName, defaultClientServicePort))
+	}
+	return ports
+}
+
+// getInvalidServicePorts returns the custom ports of the services which are out of range,
+// not DNS labels, collide with the default client port or are set for a service other than
+// the client one
+func getInvalidServicePorts(dpl *api.Elasticsearch) []string {
+	invalid := []string{}
+	for name, spec := range map[string]*api.ElasticsearchServiceSpec{
+		"discovery": discoveryServiceSpec(dpl),
+		"metrics":   metricsServiceSpec(dpl),
+	} {
+		if spec != nil && (spec.Port != 0 || spec.PortName != "") {
+			invalid = append(invalid, fmt.Sprintf("%s (not configurable)", name))
+		}
+	}
+	sort.Strings(invalid)
+
+	spec := clientServiceSpec(dpl)
+	if spec == nil {
+		return invalid
+	}
+	if spec.Port < 0 || spec.Port > 65535 {
+		invalid = append(invalid, fmt.Sprintf("client port %d (out of range)", spec.Port))
+	}
+	if spec.PortName != "" {
+		if errs := validation.IsDNS1123Label(spec.PortName); len(errs) > 0 {
+			invalid = append(invalid, fmt.Sprintf("client port name %q (not a DNS label)", spec.PortName))
+		} else if spec.PortName == restAPIPortName && clientServicePort(dpl) != defaultClientServicePort {
+			invalid = append(invalid, fmt.Sprintf("client port name %q (reserved for port %d)", spec.PortName, defaultClientServicePort))
+		}
+	}
+	return invalid
+}
 
+// createOrDeleteDiscoveryService ensures the headless service resolving to all master
+// pods exists when it is the configured discovery provider and is removed otherwise
+func (er *ElasticsearchRequest) createOrDeleteDiscoveryService() error {
+	dpl := er.cluster
+	serviceName := esDiscoveryServiceName(dpl.Name)
+
+	if !isHeadlessDiscovery(dpl) {
+		key := client.ObjectKey{Name: serviceName, Namespace: dpl.Namespace}
+		if err := service.Delete(context.TODO(), er.client, key); err != nil && !apierrors.IsNotFound(kverrors.Root(err)) {
+			return kverrors.Wrap(err, "failed to delete elasticsearch discovery service",
+				"cluster", dpl.Name,
+				"namespace", dpl.Namespace,
+			)
+		}
+		return nil
+	}
+
+	svc := service.New(serviceName, dpl.Namespace, appendDefaultLabel(dpl.Name, map[string]string{})).
+		WithManagedAnnotations(utils.WithPropagatedAnnotations(serviceAnnotations(discoveryServiceSpec(dpl), nil), propagatedAnnotations(dpl))).
+		WithSelector(selectorForES("es-node-master", dpl.Name)).
+		WithServicePorts(newServicePort(dpl.Name, "cluster", defaultDiscoveryServicePort)).
+		WithClusterIP(v1.ClusterIPNone).
+		WithPublishNotReady(discoveryPublishNotReady(dpl)).
+		Build()
+
+	return er.applyService(svc)
+}
+
+func (er *ElasticsearchRequest) createOrUpdateService(serviceName, namespace, clusterName string, ports []v1.ServicePort, selector, annotations map[string]string, publishNotReady bool, labels map[string]string) error {
 	labels = appendDefaultLabel(clusterName, labels)
 
 	svc := service.New(serviceName, namespace, labels).
-		WithAnnotations(annotations).
+		WithManagedAnnotations(utils.WithPropagatedAnnotations(annotations, propagatedAnnotations(er.cluster))).
 		WithSelector(selector).
-		WithServicePorts(v1.ServicePort{
-			Port:       port,
-			Protocol:   v1.ProtocolTCP,
-			TargetPort: intstr.FromString(targetPortName),
-			Name:       clusterName,
-		}).
+		WithServicePorts(ports...).
 		WithPublishNotReady(publishNotReady).
 		Build()
 
+	return er.applyService(svc)
+}
+
+// applyService creates the desired service or updates the existing one,
+// recreating it first if its immutable fields differ
+func (er *ElasticsearchRequest) applyService(svc *v1.Service) error {
+	client := er.client
+	cluster := er.cluster
+
 	cluster.AddOwnerRefTo(svc)
 
-	err := service.CreateOrUpdate(context.TODO(), client, svc, service.Equal, service.Mutate)
+	if err := er.recreateOnImmutableChange(svc); err != nil {
+		return err
+	}
+
+	err := service.CreateOrUpdate(context.TODO(), client, svc, serviceEqual, serviceMutate)
 	if err != nil {
 		return kverrors.Wrap(err, "failed to create or update elasticsearch service",
 			"cluster", cluster.Name,
@@ -103,3 +257,64 @@ func (er *ElasticsearchRequest) createOrUpdateService(serviceName, namespace, cl
 
 	return nil
 }
+
+// legacyServiceAnnotations are annotations set by former operator versions which were
+// replaced and thus are removed even though they are not listed as managed
+var legacyServiceAnnotations = []string{"service.alpha.openshift.io/serving-cert-secret-name"}
+
+// serviceEqual compares the operator managed fields of the services, so that foreign
+// annotations, e.g. added by a load balancer controller, do not cause updates
+func serviceEqual(current, desired *v1.Service) bool {
+	for _, key := range legacyServiceAnnotations {
+		if _, ok := current.Annotations[key]; ok {
+			return false
+		}
+	}
+	return service.CompareManagedAnnotations(current, desired)
+}
+
+// serviceMutate updates the operator managed fields of the services preserving
+// foreign annotations
+func serviceMutate(current, desired *v1.Service) {
+	service.MutateManagedAnnotations(current, desired)
+	for _, key := range legacyServiceAnnotations {
+		if _, ok := desired.Annotations[key]; !ok {
+			delete(current.Annotations, key)
+		}
+	}
+}
+
+// recreateOnImmutableChange deletes the existing service if it cannot be updated
+// to the desired one, e.g. when its type or cluster IP were changed manually.
+// The service is created anew by the following CreateOrUpdate.
+func (er *ElasticsearchRequest) recreateOnImmutableChange(desired *v1.Service) error {
+	key := client.ObjectKey{Name: desired.Name, Namespace: desired.Namespace}
+
+	current, err := service.Get(context.TODO(), er.client, key)
+	if err != nil {
+		if apierrors.IsNotFound(kverrors.Root(err)) {
+			return nil
+		}
+		return kverrors.Wrap(err, "failed to get elasticsearch service",
+			"cluster", er.cluster.Name,
+			"namespace", er.cluster.Namespace,
+		)
+	}
+
+	if !service.RequiresRecreate(current, desired) {
+		return nil
+	}
+
+	message := fmt.Sprintf("Recreating service %q since its immutable fields were changed: type %q, clusterIP %q", current.Name, current.Spec.Type, current.Spec.ClusterIP)
+	er.L().Info(message)
+	recordEvent(er.recorder, er.cluster, v1.EventTypeWarning, eventReasonServiceRecreated, message)
+
+	if err := service.Delete(context.TODO(), er.client, key); err != nil && !apierrors.IsNotFound(kverrors.Root(err)) {
+		return kverrors.Wrap(err, "failed to delete elasticsearch service for recreation",
+			"cluster", er.cluster.Name,
+			"namespace", er.cluster.Namespace,
+		)
+	}
+
+	return nil
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 
 	"github.com/ViaQ/logerr/kverrors"
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
 	"github.com/openshift/elasticsearch-operator/internal/manifests/servicemonitor"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
 
 	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
 
@@ -46,7 +48,12 @@ func (er *ElasticsearchRequest) CreateOrUpdateServiceMonitors() error {
 		},
 	}
 
-	monitor := servicemonitor.New(serviceMonitorName, dpl.Namespace, labelsWithDefault).
+	monitoring := dpl.Spec.Monitoring
+	if monitoring == nil {
+		monitoring = &api.ElasticsearchMonitoringSpec{}
+	}
+
+	monitor := servicemonitor.New(serviceMonitorName, dpl.Namespace, newServiceMonitorLabels(labelsWithDefault, monitoring.Labels)).
 		WithJobLabel("monitor-elasticsearch").
 		WithSelector(metav1.LabelSelector{
 			MatchLabels: labelsWithDefault,
@@ -54,12 +61,22 @@ func (er *ElasticsearchRequest) CreateOrUpdateServiceMonitors() error {
 		WithNamespaceSelector(monitoringv1.NamespaceSelector{
 			MatchNames: []string{dpl.Namespace},
 		}).
+		WithTargetLabels(monitoring.TargetLabels...).
+		WithPodTargetLabels(monitoring.PodTargetLabels...).
 		WithEndpoints(endpoints...).
 		Build()
 
+	monitor.Annotations = utils.WithPropagatedAnnotations(monitor.Annotations, propagatedAnnotations(dpl))
 	dpl.AddOwnerRefTo(monitor)
 
-	err := servicemonitor.CreateOrUpdate(context.TODO(), er.client, monitor, servicemonitor.Equal, servicemonitor.Mutate)
+	equalFunc := func(current, desired *monitoringv1.ServiceMonitor) bool {
+		return servicemonitor.SpecEqual(current, desired) && utils.ContainsPropagatedAnnotations(current.Annotations, desired.Annotations)
+	}
+	mutateFunc := func(current, desired *monitoringv1.ServiceMonitor) {
+		servicemonitor.Mutate(current, desired)
+		current.Annotations = utils.MergePropagatedAnnotations(current.Annotations, desired.Annotations)
+	}
+	err := servicemonitor.CreateOrUpdate(context.TODO(), er.client, monitor, equalFunc, mutateFunc)
 	if err != nil {
 		return kverrors.Wrap(err, "failed to create or update elasticsearch servicemonitor",
 			"cluster", er.cluster.Name,
@@ -69,3 +86,16 @@ func (er *ElasticsearchRequest) CreateOrUpdateServiceMonitors() error {
 
 	return nil
 }
+
+// newServiceMonitorLabels returns the additional labels requested for the ServiceMonitor
+// merged with the labels set by the operator, which take precedence
+func newServiceMonitorLabels(labels, extraLabels map[string]string) map[string]string {
+	monitorLabels := map[string]string{}
+	for k, v := range extraLabels {
+		monitorLabels[k] = v
+	}
+	for k, v := range labels {
+		monitorLabels[k] = v
+	}
+	return monitorLabels
+}
@@ -0,0 +1,75 @@
+package elasticsearch
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCreateOrUpdateServiceMonitors(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	_ = monitoringv1.AddToScheme(scheme.Scheme)
+
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch", Namespace: "openshift-logging"},
+		Spec: loggingv1.ElasticsearchSpec{
+			Monitoring: &loggingv1.ElasticsearchMonitoringSpec{
+				Labels:          map[string]string{"prometheus": "user-workload", "cluster-name": "other"},
+				TargetLabels:    []string{"cluster-name"},
+				PodTargetLabels: []string{"es-node-role"},
+			},
+		},
+	}
+	k8sClient := fake.NewFakeClient()
+	er := ElasticsearchRequest{cluster: cluster, client: k8sClient}
+
+	if err := er.CreateOrUpdateServiceMonitors(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	key := client.ObjectKey{Name: "monitor-elasticsearch-cluster", Namespace: cluster.Namespace}
+	monitor := &monitoringv1.ServiceMonitor{}
+	if err := k8sClient.Get(context.TODO(), key, monitor); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if monitor.Labels["prometheus"] != "user-workload" || monitor.Labels["cluster-name"] != cluster.Name {
+		t.Errorf("Exp. the additional labels merged with the operator ones but got %v", monitor.Labels)
+	}
+	if _, ok := monitor.Spec.Selector.MatchLabels["prometheus"]; ok {
+		t.Errorf("Exp. the selector to match the service labels only but got %v", monitor.Spec.Selector.MatchLabels)
+	}
+
+	// manual changes to the discovery are reverted
+	monitor.Spec.NamespaceSelector = monitoringv1.NamespaceSelector{Any: true}
+	monitor.Spec.TargetLabels = nil
+	if err := k8sClient.Update(context.TODO(), monitor); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := er.CreateOrUpdateServiceMonitors(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	monitor = &monitoringv1.ServiceMonitor{}
+	if err := k8sClient.Get(context.TODO(), key, monitor); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := monitoringv1.NamespaceSelector{MatchNames: []string{cluster.Namespace}}
+	if !reflect.DeepEqual(monitor.Spec.NamespaceSelector, want) {
+		t.Errorf("Exp. the namespace selector %v but got %v", want, monitor.Spec.NamespaceSelector)
+	}
+	if !reflect.DeepEqual(monitor.Spec.TargetLabels, []string{"cluster-name"}) {
+		t.Errorf("Exp. the target labels to be reverted but got %v", monitor.Spec.TargetLabels)
+	}
+	if !reflect.DeepEqual(monitor.Spec.PodTargetLabels, []string{"es-node-role"}) {
+		t.Errorf("Exp. the pod target labels %v", monitor.Spec.PodTargetLabels)
+	}
+}
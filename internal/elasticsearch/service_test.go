@@ -2,6 +2,7 @@ package elasticsearch
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -12,6 +13,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -114,6 +116,7 @@ func TestCreateOrUpdateServices(t *testing.T) {
 						Namespace:       "openshift-logging",
 						ResourceVersion: "1",
 						Annotations: map[string]string{
+							"logging.openshift.io/managed-annotations":           "service.beta.openshift.io/serving-cert-secret-name",
 							"service.beta.openshift.io/serving-cert-secret-name": "elasticsearch-metrics",
 						},
 						Labels: map[string]string{
@@ -276,6 +279,7 @@ func TestCreateOrUpdateServices(t *testing.T) {
 						Namespace:       "openshift-logging",
 						ResourceVersion: "2",
 						Annotations: map[string]string{
+							"logging.openshift.io/managed-annotations":           "service.beta.openshift.io/serving-cert-secret-name",
 							"service.beta.openshift.io/serving-cert-secret-name": "elasticsearch-metrics",
 						},
 						Labels: map[string]string{
@@ -379,3 +383,580 @@ func TestCreateOrUpdateServices(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateOrUpdateServicesRecreatesOnImmutableChange(t *testing.T) {
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch",
+			Namespace: "openshift-logging",
+		},
+	}
+	current := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch",
+			Namespace: "openshift-logging",
+		},
+		Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeNodePort,
+			ClusterIP: "172.30.0.10",
+		},
+	}
+
+	client := fake.NewFakeClient(current)
+	recorder := record.NewFakeRecorder(1)
+
+	req := &ElasticsearchRequest{
+		client:   client,
+		cluster:  cluster,
+		recorder: recorder,
+		ll:       log.Log.WithValues("cluster", "test-elasticsearch", "namespace", "test"),
+	}
+
+	if err := req.CreateOrUpdateServices(); err != nil {
+		t.Errorf("failed with error: %s", err)
+	}
+
+	got := &corev1.Service{}
+	key := types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}
+	if err := client.Get(context.TODO(), key, got); err != nil {
+		t.Errorf("failed with error: %s", err)
+	}
+
+	if got.Spec.Type != "" || got.Spec.ClusterIP != "" {
+		t.Errorf("Exp. the service to be recreated but got type %q and clusterIP %q", got.Spec.Type, got.Spec.ClusterIP)
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, eventReasonServiceRecreated) {
+			t.Errorf("Exp. a %s event but got %q", eventReasonServiceRecreated, event)
+		}
+	default:
+		t.Error("Exp. a warning event for the recreated service")
+	}
+}
+
+func TestCreateOrUpdateServicesHeadlessDiscovery(t *testing.T) {
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch",
+			Namespace: "openshift-logging",
+		},
+		Spec: loggingv1.ElasticsearchSpec{
+			Discovery: &loggingv1.ElasticsearchDiscoverySpec{
+				Provider: loggingv1.DiscoveryProviderHeadlessService,
+			},
+		},
+	}
+
+	client := fake.NewFakeClient()
+	req := &ElasticsearchRequest{
+		client:  client,
+		cluster: cluster,
+		ll:      log.Log.WithValues("cluster", "test-elasticsearch", "namespace", "test"),
+	}
+
+	if err := req.CreateOrUpdateServices(); err != nil {
+		t.Errorf("failed with error: %s", err)
+	}
+
+	got := &corev1.Service{}
+	key := types.NamespacedName{Name: "elasticsearch-discovery", Namespace: cluster.Namespace}
+	if err := client.Get(context.TODO(), key, got); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+
+	if got.Spec.ClusterIP != corev1.ClusterIPNone || !got.Spec.PublishNotReadyAddresses {
+		t.Errorf("Exp. a headless service publishing not ready addresses but got clusterIP %q and publishNotReady %t", got.Spec.ClusterIP, got.Spec.PublishNotReadyAddresses)
+	}
+
+	cluster.Spec.Discovery = nil
+	if err := req.CreateOrUpdateServices(); err != nil {
+		t.Errorf("failed with error: %s", err)
+	}
+
+	if err := client.Get(context.TODO(), key, got); err == nil {
+		t.Error("Exp. the discovery service to be deleted when not configured")
+	}
+}
+
+func TestCreateOrUpdateServicesPublishNotReady(t *testing.T) {
+	enabled := true
+	disabled := false
+
+	tests := []struct {
+		desc     string
+		services *loggingv1.ElasticsearchServicesSpec
+		want     map[string]bool
+	}{
+		{
+			desc: "defaults",
+			want: map[string]bool{
+				"elasticsearch-cluster": true,
+				"elasticsearch":         false,
+				"elasticsearch-metrics": false,
+			},
+		},
+		{
+			desc: "overridden per role",
+			services: &loggingv1.ElasticsearchServicesSpec{
+				Discovery: &loggingv1.ElasticsearchServiceSpec{PublishNotReadyAddresses: &disabled},
+				Client:    &loggingv1.ElasticsearchServiceSpec{PublishNotReadyAddresses: &enabled},
+			},
+			want: map[string]bool{
+				"elasticsearch-cluster": false,
+				"elasticsearch":         true,
+				"elasticsearch-metrics": false,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			cluster := &loggingv1.Elasticsearch{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "elasticsearch",
+					Namespace: "openshift-logging",
+				},
+				Spec: loggingv1.ElasticsearchSpec{
+					Services: test.services,
+				},
+			}
+
+			client := fake.NewFakeClient()
+			req := &ElasticsearchRequest{
+				client:  client,
+				cluster: cluster,
+				ll:      log.Log.WithValues("cluster", "test-elasticsearch", "namespace", "test"),
+			}
+
+			if err := req.CreateOrUpdateServices(); err != nil {
+				t.Errorf("failed with error: %s", err)
+			}
+
+			for name, want := range test.want {
+				got := &corev1.Service{}
+				key := types.NamespacedName{Name: name, Namespace: cluster.Namespace}
+				if err := client.Get(context.TODO(), key, got); err != nil {
+					t.Fatalf("failed with error: %s", err)
+				}
+
+				if got.Spec.PublishNotReadyAddresses != want {
+					t.Errorf("Exp. service %q to have publishNotReadyAddresses %t but was %t", name, want, got.Spec.PublishNotReadyAddresses)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateOrUpdateServicesReportsReadinessOverrides(t *testing.T) {
+	disabled := false
+
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch",
+			Namespace: "openshift-logging",
+		},
+		Spec: loggingv1.ElasticsearchSpec{
+			Services: &loggingv1.ElasticsearchServicesSpec{
+				Discovery: &loggingv1.ElasticsearchServiceSpec{PublishNotReadyAddresses: &disabled},
+			},
+		},
+	}
+	recorder := record.NewFakeRecorder(2)
+	req := &ElasticsearchRequest{
+		client:   fake.NewFakeClient(cluster),
+		cluster:  cluster,
+		recorder: recorder,
+		ll:       log.Log.WithValues("cluster", "test-elasticsearch", "namespace", "test"),
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := req.CreateOrUpdateServices(); err != nil {
+			t.Fatalf("failed with error: %s", err)
+		}
+	}
+
+	_, condition := getESNodeCondition(cluster.Status.Conditions, loggingv1.ServiceReadiness)
+	if condition == nil || condition.Status != corev1.ConditionTrue || !strings.HasPrefix(condition.Message, "Discovery services publish ready addresses only") {
+		t.Errorf("Exp. the service readiness condition but got %v", condition)
+	}
+	if len(recorder.Events) != 1 {
+		t.Fatalf("Exp. a single warning event for the unchanged overrides but got %d", len(recorder.Events))
+	}
+	if event := <-recorder.Events; !strings.HasPrefix(event, "Warning "+eventReasonServiceReadiness) {
+		t.Errorf("Exp. a warning event for the overridden service readiness but got %q", event)
+	}
+
+	cluster.Spec.Services = nil
+	if err := req.CreateOrUpdateServices(); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+	if _, condition := getESNodeCondition(cluster.Status.Conditions, loggingv1.ServiceReadiness); condition != nil {
+		t.Errorf("Exp. the service readiness condition to be cleared but got %v", condition)
+	}
+}
+
+func TestCreateOrUpdateServicesReadinessMembership(t *testing.T) {
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch",
+			Namespace: "openshift-logging",
+		},
+		Spec: loggingv1.ElasticsearchSpec{
+			Discovery: &loggingv1.ElasticsearchDiscoverySpec{
+				Provider: loggingv1.DiscoveryProviderHeadlessService,
+			},
+		},
+	}
+
+	client := fake.NewFakeClient()
+	req := &ElasticsearchRequest{
+		client:  client,
+		cluster: cluster,
+		ll:      log.Log.WithValues("cluster", "test-elasticsearch", "namespace", "test"),
+	}
+
+	if err := req.CreateOrUpdateServices(); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+
+	// the nodes discover each other while restarting, clients reach ready nodes only
+	tests := map[string]struct {
+		publishNotReady bool
+		selector        map[string]string
+	}{
+		"elasticsearch-cluster":   {publishNotReady: true, selector: map[string]string{"es-node-master": "true", "cluster-name": "elasticsearch"}},
+		"elasticsearch-discovery": {publishNotReady: true, selector: map[string]string{"es-node-master": "true", "cluster-name": "elasticsearch"}},
+		"elasticsearch":           {publishNotReady: false, selector: map[string]string{"es-node-client": "true", "cluster-name": "elasticsearch"}},
+	}
+	for name, want := range tests {
+		got := &corev1.Service{}
+		key := types.NamespacedName{Name: name, Namespace: cluster.Namespace}
+		if err := client.Get(context.TODO(), key, got); err != nil {
+			t.Fatalf("failed with error: %s", err)
+		}
+
+		if got.Spec.PublishNotReadyAddresses != want.publishNotReady {
+			t.Errorf("Exp. service %q to have publishNotReadyAddresses %t but was %t", name, want.publishNotReady, got.Spec.PublishNotReadyAddresses)
+		}
+		if diff := cmp.Diff(want.selector, got.Spec.Selector); diff != "" {
+			t.Errorf("Exp. service %q to select %v:\n%s", name, want.selector, diff)
+		}
+	}
+}
+
+func TestCreateOrUpdateServicesPreservesForeignAnnotations(t *testing.T) {
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch",
+			Namespace: "openshift-logging",
+		},
+		Spec: loggingv1.ElasticsearchSpec{
+			Services: &loggingv1.ElasticsearchServicesSpec{
+				Client: &loggingv1.ElasticsearchServiceSpec{
+					Annotations: map[string]string{
+						"example.com/owner":  "logging",
+						"example.com/team":   "observability",
+						"example.com/ignore": "old",
+					},
+				},
+				Metrics: &loggingv1.ElasticsearchServiceSpec{
+					Annotations: map[string]string{
+						"prometheus.io/scrape": "true",
+						// the operator managed annotation takes precedence
+						"service.beta.openshift.io/serving-cert-secret-name": "other",
+					},
+				},
+			},
+		},
+	}
+
+	client := fake.NewFakeClient()
+	req := &ElasticsearchRequest{
+		client:  client,
+		cluster: cluster,
+		ll:      log.Log.WithValues("cluster", "test-elasticsearch", "namespace", "test"),
+	}
+
+	if err := req.CreateOrUpdateServices(); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+
+	// a load balancer controller annotates the client service
+	key := types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}
+	got := &corev1.Service{}
+	if err := client.Get(context.TODO(), key, got); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+	got.Annotations["service.beta.kubernetes.io/aws-load-balancer-type"] = "nlb"
+	if err := client.Update(context.TODO(), got); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+
+	// the user drops one of the declared annotations and changes another
+	delete(cluster.Spec.Services.Client.Annotations, "example.com/ignore")
+	cluster.Spec.Services.Client.Annotations["example.com/team"] = "logging"
+
+	if err := req.CreateOrUpdateServices(); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+
+	got = &corev1.Service{}
+	if err := client.Get(context.TODO(), key, got); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+
+	want := map[string]string{
+		"example.com/owner": "logging",
+		"example.com/team":  "logging",
+		"service.beta.kubernetes.io/aws-load-balancer-type": "nlb",
+		"logging.openshift.io/managed-annotations":          "example.com/owner,example.com/team",
+	}
+	if diff := cmp.Diff(want, got.Annotations); diff != "" {
+		t.Errorf("Exp. foreign annotations to survive the reconciliation. Diff: %s", diff)
+	}
+
+	metrics := &corev1.Service{}
+	key = types.NamespacedName{Name: "elasticsearch-metrics", Namespace: cluster.Namespace}
+	if err := client.Get(context.TODO(), key, metrics); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+
+	if metrics.Annotations["prometheus.io/scrape"] != "true" {
+		t.Errorf("Exp. the declared metrics annotation but got %v", metrics.Annotations)
+	}
+	if got := metrics.Annotations["service.beta.openshift.io/serving-cert-secret-name"]; got != "elasticsearch-metrics" {
+		t.Errorf("Exp. the operator serving cert annotation to take precedence but got %q", got)
+	}
+}
+
+func TestCreateOrUpdateServicesPropagatesAnnotations(t *testing.T) {
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch",
+			Namespace: "openshift-logging",
+			Annotations: map[string]string{
+				"cost-center":       "1234",
+				"example.com/owner": "finance",
+				"not-propagated":    "value",
+			},
+		},
+		Spec: loggingv1.ElasticsearchSpec{
+			PropagatedAnnotations: []string{"cost-center", "example.com/"},
+			Services: &loggingv1.ElasticsearchServicesSpec{
+				Client: &loggingv1.ElasticsearchServiceSpec{
+					Annotations: map[string]string{"example.com/owner": "logging"},
+				},
+			},
+		},
+	}
+
+	client := fake.NewFakeClient()
+	req := &ElasticsearchRequest{
+		client:  client,
+		cluster: cluster,
+		ll:      log.Log.WithValues("cluster", "test-elasticsearch", "namespace", "test"),
+	}
+
+	if err := req.CreateOrUpdateServices(); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+
+	for _, name := range []string{"elasticsearch", "elasticsearch-cluster", "elasticsearch-metrics"} {
+		got := &corev1.Service{}
+		if err := client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: cluster.Namespace}, got); err != nil {
+			t.Fatalf("failed with error: %s", err)
+		}
+		if got.Annotations["cost-center"] != "1234" {
+			t.Errorf("Exp. service %s to carry the propagated annotations but got %v", name, got.Annotations)
+		}
+		if _, ok := got.Annotations["not-propagated"]; ok {
+			t.Errorf("Exp. service %s to carry only the propagated annotations but got %v", name, got.Annotations)
+		}
+	}
+
+	got := &corev1.Service{}
+	key := types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}
+	if err := client.Get(context.TODO(), key, got); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+	if owner := got.Annotations["example.com/owner"]; owner != "logging" {
+		t.Errorf("Exp. the annotation of the service to take precedence but got %q", owner)
+	}
+
+	cluster.Spec.PropagatedAnnotations = nil
+	if err := req.CreateOrUpdateServices(); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+
+	got = &corev1.Service{}
+	if err := client.Get(context.TODO(), key, got); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+	if _, ok := got.Annotations["cost-center"]; ok {
+		t.Errorf("Exp. annotations no longer propagated to be removed but got %v", got.Annotations)
+	}
+}
+
+func TestCreateOrUpdateServicesUpdatesEndpoints(t *testing.T) {
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch",
+			Namespace: "openshift-logging",
+		},
+	}
+
+	client := fake.NewFakeClient(cluster)
+	req := &ElasticsearchRequest{
+		client:  client,
+		cluster: cluster,
+		ll:      log.Log.WithValues("cluster", "test-elasticsearch", "namespace", "test"),
+	}
+
+	if err := req.CreateOrUpdateServices(); err != nil {
+		t.Errorf("failed with error: %s", err)
+	}
+
+	want := loggingv1.ElasticsearchEndpointsStatus{
+		DiscoveryService:   "elasticsearch-cluster.openshift-logging.svc:9300",
+		ClientService:      "elasticsearch.openshift-logging.svc:9200",
+		ClientServiceHTTPS: "https://elasticsearch.openshift-logging.svc:9200",
+	}
+	if cluster.Status.Endpoints == nil || *cluster.Status.Endpoints != want {
+		t.Errorf("Exp. endpoints %+v but got %+v", want, cluster.Status.Endpoints)
+	}
+
+	cluster.Spec.Discovery = &loggingv1.ElasticsearchDiscoverySpec{
+		Provider: loggingv1.DiscoveryProviderHeadlessService,
+	}
+	if err := client.Update(context.TODO(), cluster); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+	if err := req.CreateOrUpdateServices(); err != nil {
+		t.Errorf("failed with error: %s", err)
+	}
+
+	want.DiscoveryService = "elasticsearch-discovery.openshift-logging.svc:9300"
+	if cluster.Status.Endpoints == nil || *cluster.Status.Endpoints != want {
+		t.Errorf("Exp. endpoints %+v after switching to headless discovery but got %+v", want, cluster.Status.Endpoints)
+	}
+}
+
+func TestCreateOrUpdateServicesClientPort(t *testing.T) {
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch",
+			Namespace: "openshift-logging",
+		},
+	}
+
+	client := fake.NewFakeClient(cluster)
+	req := &ElasticsearchRequest{
+		client:  client,
+		cluster: cluster,
+		ll:      log.Log.WithValues("cluster", "test-elasticsearch", "namespace", "test"),
+	}
+
+	if err := req.CreateOrUpdateServices(); err != nil {
+		t.Errorf("failed with error: %s", err)
+	}
+
+	key := types.NamespacedName{Name: "elasticsearch", Namespace: cluster.Namespace}
+	got := &corev1.Service{}
+	if err := client.Get(context.TODO(), key, got); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+	want := []corev1.ServicePort{
+		{Name: "elasticsearch", Port: 9200, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromString("restapi")},
+	}
+	if diff := cmp.Diff(want, got.Spec.Ports); diff != "" {
+		t.Errorf("Exp. the default client port, diff: %s", diff)
+	}
+
+	cluster.Spec.Services = &loggingv1.ElasticsearchServicesSpec{
+		Client: &loggingv1.ElasticsearchServiceSpec{Port: 443, PortName: "https"},
+	}
+	if err := req.CreateOrUpdateServices(); err != nil {
+		t.Errorf("failed with error: %s", err)
+	}
+
+	got = &corev1.Service{}
+	if err := client.Get(context.TODO(), key, got); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+	want = []corev1.ServicePort{
+		{Name: "https", Port: 443, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromString("restapi")},
+		{Name: "restapi", Port: 9200, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromString("restapi")},
+	}
+	if diff := cmp.Diff(want, got.Spec.Ports); diff != "" {
+		t.Errorf("Exp. the custom client port next to the default one, diff: %s", diff)
+	}
+
+	wantClient := "elasticsearch.openshift-logging.svc:443"
+	if cluster.Status.Endpoints == nil || cluster.Status.Endpoints.ClientService != wantClient {
+		t.Errorf("Exp. client endpoint %q but got %+v", wantClient, cluster.Status.Endpoints)
+	}
+}
+
+func TestGetInvalidServicePorts(t *testing.T) {
+	tests := []struct {
+		desc     string
+		services *loggingv1.ElasticsearchServicesSpec
+		invalid  []string
+	}{
+		{
+			desc: "defaults",
+		},
+		{
+			desc: "custom client port",
+			services: &loggingv1.ElasticsearchServicesSpec{
+				Client: &loggingv1.ElasticsearchServiceSpec{Port: 8443, PortName: "es-https"},
+			},
+		},
+		{
+			desc: "default client port named restapi",
+			services: &loggingv1.ElasticsearchServicesSpec{
+				Client: &loggingv1.ElasticsearchServiceSpec{PortName: "restapi"},
+			},
+		},
+		{
+			desc: "out of range port and malformed name",
+			services: &loggingv1.ElasticsearchServicesSpec{
+				Client: &loggingv1.ElasticsearchServiceSpec{Port: 70000, PortName: "ES_HTTP"},
+			},
+			invalid: []string{"client port 70000 (out of range)", `client port name "ES_HTTP" (not a DNS label)`},
+		},
+		{
+			desc: "custom port named restapi",
+			services: &loggingv1.ElasticsearchServicesSpec{
+				Client: &loggingv1.ElasticsearchServiceSpec{Port: 443, PortName: "restapi"},
+			},
+			invalid: []string{`client port name "restapi" (reserved for port 9200)`},
+		},
+		{
+			desc: "ports of the other services",
+			services: &loggingv1.ElasticsearchServicesSpec{
+				Discovery: &loggingv1.ElasticsearchServiceSpec{Port: 9301},
+				Metrics:   &loggingv1.ElasticsearchServiceSpec{PortName: "prometheus"},
+			},
+			invalid: []string{"discovery (not configurable)", "metrics (not configurable)"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			cluster := &loggingv1.Elasticsearch{
+				ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch"},
+				Spec:       loggingv1.ElasticsearchSpec{Services: test.services},
+			}
+
+			invalid := getInvalidServicePorts(cluster)
+			if strings.Join(invalid, ", ") != strings.Join(test.invalid, ", ") {
+				t.Errorf("Exp. invalid service ports %v but got %v", test.invalid, invalid)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package elasticsearch
+
+import (
+	"strconv"
+	"time"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// defaultClientShutdownDelaySeconds leaves the clients of a terminating coordinating
+	// only node the time to complete their requests and to reconnect to another node
+	defaultClientShutdownDelaySeconds int64 = 20
+
+	// elasticsearchShutdownSeconds is the time Elasticsearch gets to shut down after the
+	// delay, i.e. the default termination grace period of the platform
+	elasticsearchShutdownSeconds int64 = 30
+)
+
+// getShutdownDelaySeconds returns the seconds the pods of the node delay the shutdown of
+// Elasticsearch to drain the connections, or 0 for nodes without the client role. Only
+// coordinating only nodes delay it by default, since the pod spec of the master and data
+// nodes of existing clusters, and thus their rollout, must not change with the default
+func getShutdownDelaySeconds(node api.ElasticsearchNode, commonSpec api.ElasticsearchNodeSpec, roles NodeRoles) int64 {
+	if !roles.IsClient() {
+		return 0
+	}
+	if node.ShutdownDelaySeconds != nil {
+		return *node.ShutdownDelaySeconds
+	}
+	if commonSpec.ShutdownDelaySeconds != nil {
+		return *commonSpec.ShutdownDelaySeconds
+	}
+	if !roles.IsCoordinatingOnly() {
+		return 0
+	}
+	return defaultClientShutdownDelaySeconds
+}
+
+// newShutdownDelayLifecycle returns the lifecycle of the Elasticsearch container sleeping
+// for the delay before it is stopped, or nil without a delay
+func newShutdownDelayLifecycle(delaySeconds int64) *v1.Lifecycle {
+	if delaySeconds <= 0 {
+		return nil
+	}
+
+	return &v1.Lifecycle{
+		PreStop: &v1.Handler{
+			Exec: &v1.ExecAction{
+				Command: []string{"sleep", strconv.FormatInt(delaySeconds, 10)},
+			},
+		},
+	}
+}
+
+// newTerminationGracePeriod returns the termination grace period leaving Elasticsearch the
+// time to shut down after the delay
+func newTerminationGracePeriod(delaySeconds int64) time.Duration {
+	return time.Duration(delaySeconds+elasticsearchShutdownSeconds) * time.Second
+}
@@ -2,6 +2,7 @@ package elasticsearch
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	"github.com/ViaQ/logerr/kverrors"
@@ -11,6 +12,8 @@ import (
 	"github.com/openshift/elasticsearch-operator/internal/manifests/pod"
 	"github.com/openshift/elasticsearch-operator/internal/manifests/secret"
 	"github.com/openshift/elasticsearch-operator/internal/manifests/statefulset"
+	"github.com/openshift/elasticsearch-operator/internal/metrics"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 
 	"github.com/ViaQ/logerr/log"
@@ -21,8 +24,13 @@ import (
 	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
 	apps "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 )
 
+// autoscaledReplicasAnnotation keeps the replicas an autoscaled node ran when it was scaled
+// down for a full cluster restart, so that it is scaled back up to them
+const autoscaledReplicasAnnotation = "elasticsearch.openshift.io/autoscaled-replicas"
+
 type statefulSetNode struct {
 	self apps.StatefulSet
 	// prior hash for configmap content
@@ -34,10 +42,21 @@ type statefulSetNode struct {
 
 	replicas int32
 
+	// the replicas are managed by a HorizontalPodAutoscaler
+	autoscaled bool
+
+	// resources applied by default to the elasticsearch container
+	defaultedResources *v1.ResourceRequirements
+
+	// hash of the desired pod template last rolled out together with the live one
+	appliedHash string
+
 	client client.Client
 
 	esClient esclient.Client
 
+	recorder record.EventRecorder
+
 	l logr.Logger
 }
 
@@ -51,19 +70,22 @@ func (n *statefulSetNode) L() logr.Logger {
 	return n.l
 }
 
-func (n *statefulSetNode) populateReference(nodeName string, node api.ElasticsearchNode, cluster *api.Elasticsearch, roleMap map[api.ElasticsearchNodeRole]bool, replicas int32, client client.Client, esClient esclient.Client) {
-	labels := newLabels(cluster.Name, nodeName, roleMap)
+func (n *statefulSetNode) populateReference(nodeName string, node api.ElasticsearchNode, cluster *api.Elasticsearch, roles NodeRoles, replicas int32, client client.Client, esClient esclient.Client) {
+	labels := newLabels(cluster.Name, nodeName, roles)
 	partition := int32(0)
 	logConfig := getLogConfig(cluster.GetAnnotations())
 
 	template := newPodTemplateSpec(
 		nodeName, cluster.Name, cluster.Namespace, node,
-		cluster.Spec.Spec, labels, roleMap, client, logConfig,
+		cluster.Spec.Spec, labels, roles, client, logConfig,
 	)
+	setESClusterName(&template, getESClusterName(cluster))
+	setDataTier(&template, getNodeDataTier(cluster, node))
+	setIngest(&template, getNodeIngest(cluster, node))
 
 	sts := statefulset.New(nodeName, cluster.Namespace, labels, replicas).
 		WithSelector(metav1.LabelSelector{
-			MatchLabels: newLabelSelector(cluster.Name, nodeName, roleMap),
+			MatchLabels: newLabelSelector(cluster.Name, nodeName, roles),
 		}).
 		WithTemplate(template).
 		WithUpdateStrategy(apps.StatefulSetUpdateStrategy{
@@ -74,13 +96,20 @@ func (n *statefulSetNode) populateReference(nodeName string, node api.Elasticsea
 		}).
 		Build()
 
-	sts.Spec.Template.Spec.Containers[0].ReadinessProbe = nil
+	// the nodes of statefulsets are only probed if requested
+	if node.ReadinessProbe == nil {
+		sts.Spec.Template.Spec.Containers[0].ReadinessProbe = nil
+	}
 
+	sts.Annotations = utils.WithPropagatedAnnotations(setDesiredTemplateHash(sts.Annotations, sts.Spec.Template), propagatedAnnotations(cluster))
 	cluster.AddOwnerRefTo(sts)
 
 	n.self = *sts
 	n.clusterName = cluster.Name
 	n.replicas = replicas
+	n.defaultedResources = newDefaultedESResources(node.Resources, cluster.Spec.Spec.Resources, roles)
+	_, nodeStatus := getNodeStatus(nodeName, &cluster.Status)
+	n.appliedHash = nodeStatus.AppliedTemplateHash
 
 	n.client = client
 	n.esClient = esClient
@@ -88,14 +117,92 @@ func (n *statefulSetNode) populateReference(nodeName string, node api.Elasticsea
 
 func (n *statefulSetNode) updateReference(desired NodeTypeInterface) {
 	n.self = desired.(*statefulSetNode).self
+	n.replicas = desired.(*statefulSetNode).replicas
+	n.defaultedResources = desired.(*statefulSetNode).defaultedResources
+	n.autoscaled = desired.(*statefulSetNode).autoscaled
+	n.l = desired.(*statefulSetNode).l
 }
 
 func (n *statefulSetNode) scaleDown() error {
+	if n.autoscaled {
+		if err := n.recordAutoscaledReplicas(); err != nil {
+			return err
+		}
+	}
 	return n.setReplicaCount(0)
 }
 
 func (n *statefulSetNode) scaleUp() error {
-	return n.setReplicaCount(n.replicas)
+	if err := n.setReplicaCount(n.desiredReplicas()); err != nil {
+		return err
+	}
+	if n.autoscaled {
+		return n.clearAutoscaledReplicas()
+	}
+	return nil
+}
+
+// desiredReplicas returns the number of replicas the node runs when scaled up. Autoscaled
+// nodes run the replicas set by the autoscaler, or the ones recorded when they were scaled
+// down, and the lower limit only if neither is known.
+func (n *statefulSetNode) desiredReplicas() int32 {
+	if !n.autoscaled {
+		return n.replicas
+	}
+
+	key := client.ObjectKey{Name: n.name(), Namespace: n.self.Namespace}
+	sts, err := statefulset.Get(context.TODO(), n.client, key)
+	if err != nil {
+		return n.replicas
+	}
+	if sts.Spec.Replicas != nil && *sts.Spec.Replicas > 0 {
+		return *sts.Spec.Replicas
+	}
+	if recorded, err := strconv.ParseInt(sts.Annotations[autoscaledReplicasAnnotation], 10, 32); err == nil && recorded > 0 {
+		return int32(recorded)
+	}
+	return n.replicas
+}
+
+// recordAutoscaledReplicas keeps the replicas set by the autoscaler on the statefulset
+// before it is scaled down, unless it is scaled down already
+func (n *statefulSetNode) recordAutoscaledReplicas() error {
+	replicas := n.desiredReplicas()
+	value := strconv.Itoa(int(replicas))
+	equalFunc := func(current, _ *apps.StatefulSet) bool {
+		return current.Annotations[autoscaledReplicasAnnotation] == value
+	}
+	mutateFunc := func(current, _ *apps.StatefulSet) {
+		if current.Annotations == nil {
+			current.Annotations = map[string]string{}
+		}
+		current.Annotations[autoscaledReplicasAnnotation] = value
+	}
+
+	if err := statefulset.Update(context.TODO(), n.client, n.self.DeepCopy(), equalFunc, mutateFunc); err != nil {
+		return kverrors.Wrap(err, "failed to record autoscaled replicas of elasticsearch node statefulset",
+			"node_statefulset_name", n.self.Name,
+		)
+	}
+	return nil
+}
+
+// clearAutoscaledReplicas removes the replicas recorded before the node was scaled down
+func (n *statefulSetNode) clearAutoscaledReplicas() error {
+	equalFunc := func(current, _ *apps.StatefulSet) bool {
+		_, ok := current.Annotations[autoscaledReplicasAnnotation]
+		return !ok
+	}
+	mutateFunc := func(current, _ *apps.StatefulSet) {
+		delete(current.Annotations, autoscaledReplicasAnnotation)
+	}
+
+	if err := statefulset.Update(context.TODO(), n.client, n.self.DeepCopy(), equalFunc, mutateFunc); err != nil {
+		return kverrors.Wrap(err, "failed to clear autoscaled replicas of elasticsearch node statefulset",
+			"node_statefulset_name", n.self.Name,
+		)
+	}
+	return nil
 }
 
 func (n *statefulSetNode) getSecretHash() string {
@@ -106,9 +213,14 @@ func (n *statefulSetNode) state() api.ElasticsearchNodeStatus {
 	var rolloutForUpdate v1.ConditionStatus
 	var rolloutForCertReload v1.ConditionStatus
 
-	// see if we need to update the deployment object
-	if n.isChanged() {
+	// see if we need to update the statefulset object, skipping the comparison if the
+	// live statefulset is the one the template was rolled out with
+	switch {
+	case n.isApplied():
+	case n.isChanged():
 		rolloutForUpdate = v1.ConditionTrue
+	default:
+		n.recordApplied()
 	}
 
 	// check for a case where our hash is missing -- operator restarted?
@@ -127,7 +239,11 @@ func (n *statefulSetNode) state() api.ElasticsearchNodeStatus {
 	}
 
 	return api.ElasticsearchNodeStatus{
-		StatefulSetName: n.self.Name,
+		StatefulSetName:       n.self.Name,
+		Revision:              n.nodeRevision(),
+		DefaultedResources:    n.defaultedResources,
+		AppliedTemplateHash:   n.appliedHash,
+		LastAppliedConfigHash: configHash(n.configmapHash),
 		UpgradeStatus: api.ElasticsearchNodeUpgradeStatus{
 			ScheduledForUpgrade:      rolloutForUpdate,
 			ScheduledForCertRedeploy: rolloutForCertReload,
@@ -135,11 +251,24 @@ func (n *statefulSetNode) state() api.ElasticsearchNodeStatus {
 	}
 }
 
+// nodeRevision returns the revision of the statefulset the pods currently run as observed on the cluster
+func (n *statefulSetNode) nodeRevision() string {
+	key := client.ObjectKey{Name: n.name(), Namespace: n.self.Namespace}
+	sts, err := statefulset.Get(context.TODO(), n.client, key)
+	if err != nil {
+		return ""
+	}
+
+	return sts.Status.CurrentRevision
+}
+
 func (n *statefulSetNode) name() string {
 	return n.self.Name
 }
 
 func (n *statefulSetNode) waitForNodeRejoinCluster() (bool, error) {
+	defer metrics.ObserveWait(time.Now())
+
 	err := wait.Poll(time.Second*1, time.Second*60, func() (done bool, err error) {
 		clusterSize, err := n.esClient.GetClusterNodeCount()
 		if err != nil {
@@ -147,13 +276,15 @@ func (n *statefulSetNode) waitForNodeRejoinCluster() (bool, error) {
 			return false, err
 		}
 
-		return n.replicas <= clusterSize, nil
+		return n.desiredReplicas() <= clusterSize, nil
 	})
 
 	return err == nil, err
 }
 
 func (n *statefulSetNode) waitForNodeLeaveCluster() (bool, error) {
+	defer metrics.ObserveWait(time.Now())
+
 	err := wait.Poll(time.Second*1, time.Second*60, func() (done bool, err error) {
 		clusterSize, err := n.esClient.GetClusterNodeCount()
 		if err != nil {
@@ -161,7 +292,7 @@ func (n *statefulSetNode) waitForNodeLeaveCluster() (bool, error) {
 			return false, err
 		}
 
-		return n.replicas > clusterSize, nil
+		return n.desiredReplicas() > clusterSize, nil
 	})
 
 	return err == nil, err
@@ -236,14 +367,14 @@ func (n *statefulSetNode) replicaCount() (int32, error) {
 
 func (n *statefulSetNode) isMissing() bool {
 	key := client.ObjectKey{Name: n.name(), Namespace: n.self.Namespace}
-	_, err := statefulset.Get(context.TODO(), n.client, key)
-	if err != nil {
-		if apierrors.IsNotFound(kverrors.Root(err)) {
-			return true
-		}
-	}
+	return isObjectMissing(func() error {
+		_, err := statefulset.Get(context.TODO(), n.client, key)
+		return err
+	})
+}
 
-	return false
+func (n *statefulSetNode) isMaster() bool {
+	return n.self.Labels["es-node-master"] == "true"
 }
 
 func (n *statefulSetNode) delete() error {
@@ -261,7 +392,7 @@ func (n *statefulSetNode) create() error {
 				)
 			} else {
 				n.scale()
-				return nil
+				return n.updateAnnotations()
 			}
 		}
 
@@ -269,18 +400,47 @@ func (n *statefulSetNode) create() error {
 		n.refreshHashes()
 	} else {
 		n.scale()
+		return n.updateAnnotations()
 	}
 
 	return nil
 }
 
+// updateAnnotations updates the propagated annotations of the statefulset and its PVCs in
+// place, since changing them does not replace the pods and thus needs no rollout of the node
+func (n *statefulSetNode) updateAnnotations() error {
+	annotations := n.self.Annotations
+	equalFunc := func(current, _ *apps.StatefulSet) bool {
+		return utils.ContainsPropagatedAnnotations(current.Annotations, annotations)
+	}
+	mutateFunc := func(current, _ *apps.StatefulSet) {
+		current.Annotations = utils.MergePropagatedAnnotations(current.Annotations, annotations)
+	}
+
+	err := statefulset.Update(context.TODO(), n.client, n.self.DeepCopy(), equalFunc, mutateFunc)
+	if err != nil {
+		return kverrors.Wrap(err, "failed to update annotations of elasticsearch node statefulset",
+			"node_statefulset_name", n.self.Name,
+		)
+	}
+
+	return updatePropagatedPVCAnnotations(n.client, n.self.Namespace, n.self.Spec.Template, utils.PropagatedAnnotations(annotations))
+}
+
 func (n *statefulSetNode) executeUpdate() error {
 	equalFunc := func(current, desired *apps.StatefulSet) bool {
 		return pod.ArePodTemplateSpecEqual(current.Spec.Template, desired.Spec.Template)
 	}
 
+	var reverted []string
 	mutateFunc := func(current, desired *apps.StatefulSet) {
+		reverted = nil
+		if isManuallyModified(current.Annotations, desired.Spec.Template) {
+			reverted = pod.DiffPodTemplateSpec(current.Spec.Template, desired.Spec.Template)
+		}
+
 		current.Spec.Template = createUpdatablePodTemplateSpec(current.Spec.Template, desired.Spec.Template)
+		current.Annotations = setDesiredTemplateHash(utils.MergePropagatedAnnotations(current.Annotations, desired.Annotations), desired.Spec.Template)
 	}
 
 	err := statefulset.Update(context.TODO(), n.client, &n.self, equalFunc, mutateFunc)
@@ -290,6 +450,8 @@ func (n *statefulSetNode) executeUpdate() error {
 		)
 	}
 
+	recordResourceReverted(n.recorder, &n.self, "StatefulSet", n.name(), reverted)
+
 	return nil
 }
 
@@ -308,6 +470,11 @@ func (n *statefulSetNode) refreshHashes() {
 }
 
 func (n *statefulSetNode) scale() {
+	// the replicas of autoscaled nodes are left to the autoscaler
+	if n.autoscaled {
+		return
+	}
+
 	key := client.ObjectKey{Name: n.name(), Namespace: n.self.Namespace}
 	sts, err := statefulset.Get(context.TODO(), n.client, key)
 	if err != nil {
@@ -315,8 +482,9 @@ func (n *statefulSetNode) scale() {
 	}
 
 	if *sts.Spec.Replicas != *n.self.Spec.Replicas {
-		n.self.Spec.Replicas = sts.Spec.Replicas
-		n.L().Info("Resource has different container replicas than desired")
+		n.L().Info("Resource has different container replicas than desired",
+			"replicas", *sts.Spec.Replicas,
+			"desired", *n.self.Spec.Replicas)
 
 		if err := n.setReplicaCount(*n.self.Spec.Replicas); err != nil {
 			n.L().Error(err, "unable to set replicate count")
@@ -335,7 +503,11 @@ func (n *statefulSetNode) isChanged() bool {
 }
 
 func (n *statefulSetNode) progressNodeChanges() error {
+	if n.isApplied() {
+		return nil
+	}
 	if !n.isChanged() {
+		n.recordApplied()
 		return nil
 	}
 	replicas, err := n.replicaCount()
@@ -391,5 +563,34 @@ func (n *statefulSetNode) progressNodeChanges() error {
 	}
 
 	n.refreshHashes()
+	n.recordApplied()
 	return nil
 }
+
+// isApplied returns true if the desired pod template was already rolled out on the node
+// and the live statefulset did not change since, i.e. the node needs no update
+func (n *statefulSetNode) isApplied() bool {
+	if n.appliedHash == "" {
+		return false
+	}
+
+	key := client.ObjectKey{Name: n.name(), Namespace: n.self.Namespace}
+	sts, err := statefulset.Get(context.TODO(), n.client, key)
+	if err != nil {
+		return false
+	}
+
+	return appliedTemplateHash(podTemplateHash(n.self.Spec.Template), sts.Spec.Template) == n.appliedHash
+}
+
+// recordApplied records the desired pod template as rolled out on the live statefulset
+func (n *statefulSetNode) recordApplied() {
+	key := client.ObjectKey{Name: n.name(), Namespace: n.self.Namespace}
+	sts, err := statefulset.Get(context.TODO(), n.client, key)
+	if err != nil {
+		n.appliedHash = ""
+		return
+	}
+
+	n.appliedHash = appliedTemplateHash(podTemplateHash(n.self.Spec.Template), sts.Spec.Template)
+}
@@ -0,0 +1,137 @@
+package elasticsearch
+
+import (
+	"context"
+	"testing"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestStatefulSetNodeAppliedTemplate(t *testing.T) {
+	template := v1.PodTemplateSpec{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: "elasticsearch", Image: "newImage"}},
+		},
+	}
+	outdated := template.DeepCopy()
+	outdated.Spec.Containers[0].Image = "oldImage"
+	applied := appliedTemplateHash(podTemplateHash(template), template)
+
+	tests := []struct {
+		desc        string
+		live        v1.PodTemplateSpec
+		appliedHash string
+		want        v1.ConditionStatus
+	}{
+		{desc: "untouched", live: template, appliedHash: applied},
+		{desc: "manually changed", live: *outdated, appliedHash: applied, want: v1.ConditionTrue},
+		{desc: "desired changed", live: *outdated, appliedHash: "previous", want: v1.ConditionTrue},
+		{desc: "not recorded yet", live: template},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			existing := &apps.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch-cm-1", Namespace: "openshift-logging"},
+				Spec:       apps.StatefulSetSpec{Template: test.live},
+			}
+			c := &countingUpdateClient{Client: fake.NewFakeClient(existing)}
+			node := &statefulSetNode{
+				client:      c,
+				appliedHash: test.appliedHash,
+				self: apps.StatefulSet{
+					ObjectMeta: metav1.ObjectMeta{Name: existing.Name, Namespace: existing.Namespace},
+					Spec:       apps.StatefulSetSpec{Template: template},
+				},
+			}
+
+			state := node.state()
+			if got := state.UpgradeStatus.ScheduledForUpgrade; got != test.want {
+				t.Errorf("Exp. scheduled for upgrade %q but got %q", test.want, got)
+			}
+			if test.want == "" && state.AppliedTemplateHash != applied {
+				t.Errorf("Exp. the template to be recorded as applied but got %q", state.AppliedTemplateHash)
+			}
+			if c.updates != 0 {
+				t.Errorf("Exp. no updates but got %d", c.updates)
+			}
+		})
+	}
+}
+
+func TestStatefulSetNodeScaleUpKeepsAutoscaledReplicas(t *testing.T) {
+	scaled := int32(5)
+	existing := &apps.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch-c-1", Namespace: "openshift-logging"},
+		Spec:       apps.StatefulSetSpec{Replicas: &scaled},
+	}
+	k8sClient := fake.NewFakeClient(existing)
+	node := &statefulSetNode{
+		client:     k8sClient,
+		replicas:   2,
+		autoscaled: true,
+		self: apps.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: existing.Name, Namespace: existing.Namespace},
+		},
+	}
+
+	replicas := func() int32 {
+		sts := &apps.StatefulSet{}
+		if err := k8sClient.Get(context.TODO(), client.ObjectKey{Name: existing.Name, Namespace: existing.Namespace}, sts); err != nil {
+			t.Fatalf("failed to get statefulset: %s", err)
+		}
+		return *sts.Spec.Replicas
+	}
+
+	if err := node.scaleDown(); err != nil {
+		t.Fatalf("failed to scale down: %s", err)
+	}
+	if got := replicas(); got != 0 {
+		t.Errorf("Exp. the node to be scaled down but got %d replicas", got)
+	}
+	if got := node.desiredReplicas(); got != scaled {
+		t.Errorf("Exp. the scaled down node to wait on the %d autoscaled replicas but got %d", scaled, got)
+	}
+
+	if err := node.scaleUp(); err != nil {
+		t.Fatalf("failed to scale up: %s", err)
+	}
+	if got := replicas(); got != scaled {
+		t.Errorf("Exp. the node to be scaled up to the %d autoscaled replicas but got %d", scaled, got)
+	}
+}
+
+func TestStatefulSetNodeReadinessProbe(t *testing.T) {
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch", Namespace: "openshift-logging"},
+	}
+	node := loggingv1.ElasticsearchNode{
+		Roles:     []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleMaster},
+		NodeCount: 3,
+	}
+
+	n := newStatefulSetNode("elasticsearch-m-abcd1234", node, cluster, getNodeRoles(node), fake.NewFakeClient(), nil, nil, log.Log).(*statefulSetNode)
+	if probe := n.self.Spec.Template.Spec.Containers[0].ReadinessProbe; probe != nil {
+		t.Errorf("Exp. no readiness probe unless requested but got %v", probe)
+	}
+
+	timeout := int32(60)
+	node.ReadinessProbe = &loggingv1.ElasticsearchProbeSpec{TimeoutSeconds: &timeout}
+	n = newStatefulSetNode("elasticsearch-m-abcd1234", node, cluster, getNodeRoles(node), fake.NewFakeClient(), nil, nil, log.Log).(*statefulSetNode)
+	probe := n.self.Spec.Template.Spec.Containers[0].ReadinessProbe
+	if probe == nil {
+		t.Fatal("Exp. the requested readiness probe")
+	}
+	if probe.TimeoutSeconds != timeout {
+		t.Errorf("Exp. the requested timeout %d but got %d", timeout, probe.TimeoutSeconds)
+	}
+	if probe.InitialDelaySeconds != defaultNoDataReadinessProbeInitialDelaySeconds {
+		t.Errorf("Exp. the initial delay of non data nodes %d but got %d", defaultNoDataReadinessProbeInitialDelaySeconds, probe.InitialDelaySeconds)
+	}
+}
@@ -69,9 +69,11 @@ func (er *ElasticsearchRequest) UpdateClusterStatus() error {
 
 	clusterStatus.Pods = rolePodStateMap(cluster.Namespace, cluster.Name, er.client)
 	updateStatusConditions(clusterStatus)
+	er.updateUnassignedShardsCondition(clusterStatus)
 	if err := er.updateNodeConditions(clusterStatus); err != nil {
 		return err
 	}
+	updateNodesReadinessConditions(clusterStatus)
 
 	if err := er.updateStorageConditions(clusterStatus); err != nil {
 		return err
@@ -90,6 +92,7 @@ func (er *ElasticsearchRequest) UpdateClusterStatus() error {
 			cluster.Status.Pods = clusterStatus.Pods
 			cluster.Status.ShardAllocationEnabled = clusterStatus.ShardAllocationEnabled
 			cluster.Status.Nodes = clusterStatus.Nodes
+			cluster.Status.UnassignedShardsSince = clusterStatus.UnassignedShardsSince
 
 			if err := er.client.Status().Update(context.TODO(), cluster); err != nil {
 				return err
@@ -155,6 +158,44 @@ func (er *ElasticsearchRequest) updateNodeStatus(status api.ElasticsearchStatus)
 	return nil
 }
 
+// IsRolloutInProgress returns true if any node of the cluster is scheduled for or under
+// an upgrade or cert redeploy, or if the cluster is restarting or recovering
+func IsRolloutInProgress(cluster *api.Elasticsearch) bool {
+	return isRolloutInProgress(&cluster.Status)
+}
+
+func isRolloutInProgress(status *api.ElasticsearchStatus) bool {
+	if isRolloutStarted(status) {
+		return true
+	}
+
+	for _, node := range status.Nodes {
+		if node.UpgradeStatus.ScheduledForUpgrade == v1.ConditionTrue ||
+			node.UpgradeStatus.ScheduledForCertRedeploy == v1.ConditionTrue {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isRolloutStarted returns true if a full cluster restart or the update of a node is under way,
+// unlike rollouts or cert redeploys only scheduled
+func isRolloutStarted(status *api.ElasticsearchStatus) bool {
+	if containsClusterCondition(api.Restarting, v1.ConditionTrue, status) ||
+		containsClusterCondition(api.Recovering, v1.ConditionTrue, status) {
+		return true
+	}
+
+	for _, node := range status.Nodes {
+		if node.UpgradeStatus.UnderUpgrade == v1.ConditionTrue {
+			return true
+		}
+	}
+
+	return false
+}
+
 func containsClusterCondition(condition api.ClusterConditionType, status v1.ConditionStatus, elasticsearchStatus *api.ElasticsearchStatus) bool {
 	// if we're looking for a status of v1.ConditionTrue then we want to see if the
 	// condition is present and the status is the same
@@ -292,7 +333,7 @@ func (er *ElasticsearchRequest) updateStorageConditions(status *api.Elasticsearc
 	ll := er.L()
 
 	emptySpecVol := api.ElasticsearchStorageSpec{}
-	structureStatus, nameStatus, sizeStatus := v1.ConditionFalse, v1.ConditionFalse, v1.ConditionFalse
+	structureStatus, nameStatus, sizeStatus, modeStatus := v1.ConditionFalse, v1.ConditionFalse, v1.ConditionFalse, v1.ConditionFalse
 
 	nodeNames := []string{}
 	clusterNodes := nodes[nodeMapKey(er.cluster.GetName(), er.cluster.GetNamespace())]
@@ -357,6 +398,10 @@ func (er *ElasticsearchRequest) updateStorageConditions(status *api.Elasticsearc
 				sizeStatus = v1.ConditionTrue
 			}
 
+			if isStorageModeChanged(specVol, current) {
+				modeStatus = v1.ConditionTrue
+			}
+
 			return nil
 		})
 
@@ -389,6 +434,14 @@ func (er *ElasticsearchRequest) updateStorageConditions(status *api.Elasticsearc
 		Message:            "Resizing the storage for a custom resource is not supported",
 	})
 
+	updateESNodeCondition(status, &api.ClusterCondition{
+		Type:               api.StorageMode,
+		Status:             modeStatus,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "StorageModeChangeIgnored",
+		Message:            "Changing the access modes or volume mode of the storage for a custom resource is not supported",
+	})
+
 	return nil
 }
 
@@ -505,6 +558,8 @@ func (er *ElasticsearchRequest) updatePodNodeConditions(status *api.Elasticsearc
 			return err
 		}
 
+		updateNodeReadinessConditions(nodeStatus, nodePodList)
+
 		for _, nodePod := range nodePodList {
 
 			isUnschedulable := false
@@ -918,6 +973,14 @@ func updateESNodeCondition(status *api.ElasticsearchStatus, condition *api.Clust
 
 func updateConditionWithRetry(dpl *api.Elasticsearch, value v1.ConditionStatus,
 	executeUpdateCondition func(*api.ElasticsearchStatus, v1.ConditionStatus) bool, client client.Client) error {
+	// defer the write to the end of the reconcile if changes are batched
+	if b := getStatusBuilder(dpl); b != nil {
+		b.add(dpl, func(status *api.ElasticsearchStatus) bool {
+			return executeUpdateCondition(status, value)
+		})
+		return nil
+	}
+
 	retryErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		if err := client.Get(context.TODO(), types.NamespacedName{Name: dpl.Name, Namespace: dpl.Namespace}, dpl); err != nil {
 			log.Info("Could not get Elasticsearch", "cluster", dpl.Name, "error", err)
@@ -941,7 +1004,7 @@ func updateInvalidMasterCountCondition(status *api.ElasticsearchStatus, value v1
 	var message string
 	var reason string
 	if value == v1.ConditionTrue {
-		message = fmt.Sprintf("Invalid master nodes count. Please ensure there are no more than %v total nodes with master roles", maxMasterCount)
+		message = invalidMasterCountMessage
 		reason = "Invalid Settings"
 	} else {
 		message = ""
@@ -959,7 +1022,7 @@ func updateInvalidDataCountCondition(status *api.ElasticsearchStatus, value v1.C
 	var message string
 	var reason string
 	if value == v1.ConditionTrue {
-		message = "No data nodes requested. Please ensure there is at least 1 node with data roles"
+		message = invalidDataCountMessage
 		reason = "Invalid Settings"
 	} else {
 		message = ""
@@ -973,11 +1036,13 @@ func updateInvalidDataCountCondition(status *api.ElasticsearchStatus, value v1.C
 	})
 }
 
-func updateInvalidUUIDChangeCondition(cluster *api.Elasticsearch, value v1.ConditionStatus, message string, client client.Client) error {
-	var reason string
-	if value == v1.ConditionTrue {
-		reason = "Invalid Spec"
-	} else {
+// invalidSpecReason is the reason of the conditions reporting an invalid spec
+const invalidSpecReason = "Invalid Spec"
+
+// updateCondition sets the condition of the given type of the cluster, with the reason
+// applied while it is true, and persists the status
+func updateCondition(cluster *api.Elasticsearch, conditionType api.ClusterConditionType, reason string, value v1.ConditionStatus, message string, client client.Client) error {
+	if value != v1.ConditionTrue {
 		reason = ""
 	}
 
@@ -985,8 +1050,8 @@ func updateInvalidUUIDChangeCondition(cluster *api.Elasticsearch, value v1.Condi
 		cluster,
 		value,
 		func(status *api.ElasticsearchStatus, value v1.ConditionStatus) bool {
-			return updateESNodeCondition(&cluster.Status, &api.ClusterCondition{
-				Type:    api.InvalidUUID,
+			return updateESNodeCondition(status, &api.ClusterCondition{
+				Type:    conditionType,
 				Status:  value,
 				Reason:  reason,
 				Message: message,
@@ -996,6 +1061,23 @@ func updateInvalidUUIDChangeCondition(cluster *api.Elasticsearch, value v1.Condi
 	)
 }
 
+// updateReconcileWaitingCondition reports the reason the operator waits on the cluster, if any
+func updateReconcileWaitingCondition(cluster *api.Elasticsearch, outcome ReconcileOutcome, client client.Client) error {
+	value := v1.ConditionFalse
+	if outcome.IsWaiting() {
+		value = v1.ConditionTrue
+	}
+
+	return updateConditionWithRetry(cluster, value, func(status *api.ElasticsearchStatus, value v1.ConditionStatus) bool {
+		return updateESNodeCondition(status, &api.ClusterCondition{
+			Type:    api.ReconcileWaiting,
+			Status:  value,
+			Reason:  string(outcome.Reason),
+			Message: outcome.Message,
+		})
+	}, client)
+}
+
 func updateInvalidReplicationCondition(status *api.ElasticsearchStatus, value v1.ConditionStatus) bool {
 	var message string
 	var reason string
@@ -1043,7 +1125,7 @@ func (er *ElasticsearchRequest) UpdateDegradedCondition(value bool, reason, mess
 		cluster,
 		statusValue,
 		func(status *api.ElasticsearchStatus, statusValue v1.ConditionStatus) bool {
-			return updateESNodeCondition(&cluster.Status, &api.ClusterCondition{
+			return updateESNodeCondition(status, &api.ClusterCondition{
 				Type:    api.DegradedState,
 				Status:  statusValue,
 				Reason:  reason,
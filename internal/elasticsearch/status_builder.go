@@ -0,0 +1,104 @@
+package elasticsearch
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ViaQ/logerr/kverrors"
+	"github.com/ViaQ/logerr/log"
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// statusUpdateFunc applies a change to the status and returns true if it changed anything
+type statusUpdateFunc func(*api.ElasticsearchStatus) bool
+
+// statusBuilder accumulates the status condition changes made during a single
+// reconcile of a cluster, to persist them with one status update when flushed.
+type statusBuilder struct {
+	client  client.Client
+	key     types.NamespacedName
+	updates []statusUpdateFunc
+}
+
+var (
+	statusBuilders   = map[string]*statusBuilder{}
+	statusBuildersMu sync.Mutex
+)
+
+// newStatusBuilder starts batching the status condition changes of the cluster
+// until the returned builder is flushed
+func newStatusBuilder(cluster *api.Elasticsearch, client client.Client) *statusBuilder {
+	b := &statusBuilder{
+		client: client,
+		key:    types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace},
+	}
+
+	statusBuildersMu.Lock()
+	defer statusBuildersMu.Unlock()
+	statusBuilders[nodeMapKey(cluster.Name, cluster.Namespace)] = b
+
+	return b
+}
+
+// getStatusBuilder returns the builder currently batching the status changes
+// of the cluster or nil if changes need to be written immediately
+func getStatusBuilder(cluster *api.Elasticsearch) *statusBuilder {
+	statusBuildersMu.Lock()
+	defer statusBuildersMu.Unlock()
+
+	return statusBuilders[nodeMapKey(cluster.Name, cluster.Namespace)]
+}
+
+// add applies the update to the in-memory status of the cluster and records
+// it to be applied to the observed status once flushed
+func (b *statusBuilder) add(cluster *api.Elasticsearch, update statusUpdateFunc) {
+	update(&cluster.Status)
+	b.updates = append(b.updates, update)
+}
+
+// flush stops batching and writes all recorded updates with a single status
+// update. The write is skipped if they do not change the observed status.
+func (b *statusBuilder) flush() error {
+	statusBuildersMu.Lock()
+	delete(statusBuilders, nodeMapKey(b.key.Name, b.key.Namespace))
+	statusBuildersMu.Unlock()
+
+	if len(b.updates) == 0 {
+		return nil
+	}
+
+	nretries := -1
+	retryErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		nretries++
+
+		cluster := &api.Elasticsearch{}
+		if err := b.client.Get(context.TODO(), b.key, cluster); err != nil {
+			log.Info("Could not get Elasticsearch", "cluster", b.key.Name, "error", err)
+			return err
+		}
+
+		changed := false
+		for _, update := range b.updates {
+			if update(&cluster.Status) {
+				changed = true
+			}
+		}
+
+		if !changed {
+			return nil
+		}
+
+		return b.client.Status().Update(context.TODO(), cluster)
+	})
+
+	if retryErr != nil {
+		return kverrors.Wrap(retryErr, "failed to flush status updates for cluster",
+			"cluster", b.key.Name,
+			"retries", nretries)
+	}
+
+	return nil
+}
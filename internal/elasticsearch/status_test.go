@@ -1,6 +1,7 @@
 package elasticsearch
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -8,6 +9,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
@@ -445,3 +447,117 @@ func TestUnschedulableNodesConditions(t *testing.T) {
 		t.Errorf("Expected cluster node statuses to be same. Diff is %s", diff)
 	}
 }
+
+func TestIsRolloutInProgress(t *testing.T) {
+	tests := []struct {
+		desc   string
+		status loggingv1.ElasticsearchStatus
+		want   bool
+	}{
+		{
+			desc: "converged cluster",
+			status: loggingv1.ElasticsearchStatus{
+				Nodes: []loggingv1.ElasticsearchNodeStatus{
+					{DeploymentName: "elasticsearch-cdm-1"},
+				},
+			},
+			want: false,
+		},
+		{
+			desc: "node scheduled for upgrade",
+			status: loggingv1.ElasticsearchStatus{
+				Nodes: []loggingv1.ElasticsearchNodeStatus{
+					{
+						DeploymentName: "elasticsearch-cdm-1",
+						UpgradeStatus: loggingv1.ElasticsearchNodeUpgradeStatus{
+							ScheduledForUpgrade: corev1.ConditionTrue,
+						},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			desc: "node scheduled for cert redeploy",
+			status: loggingv1.ElasticsearchStatus{
+				Nodes: []loggingv1.ElasticsearchNodeStatus{
+					{
+						DeploymentName: "elasticsearch-cdm-1",
+						UpgradeStatus: loggingv1.ElasticsearchNodeUpgradeStatus{
+							ScheduledForCertRedeploy: corev1.ConditionTrue,
+						},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			desc: "cluster recovering",
+			status: loggingv1.ElasticsearchStatus{
+				Conditions: []loggingv1.ClusterCondition{
+					{Type: loggingv1.Recovering, Status: corev1.ConditionTrue},
+				},
+			},
+			want: true,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			cluster := &loggingv1.Elasticsearch{Status: test.status}
+			if got := IsRolloutInProgress(cluster); got != test.want {
+				t.Errorf("got: %t, want: %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestStatusBuilderBatchesConditionUpdates(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch",
+			Namespace: "openshift-logging",
+		},
+	}
+	k8sClient := fake.NewFakeClient(cluster.DeepCopy())
+	key := client.ObjectKey{Name: cluster.Name, Namespace: cluster.Namespace}
+
+	b := newStatusBuilder(cluster, k8sClient)
+
+	if err := updateConditionWithRetry(cluster, corev1.ConditionTrue, updateInvalidMasterCountCondition, k8sClient); err != nil {
+		t.Errorf("failed with error: %s", err)
+	}
+	if err := updateConditionWithRetry(cluster, corev1.ConditionTrue, updateInvalidDataCountCondition, k8sClient); err != nil {
+		t.Errorf("failed with error: %s", err)
+	}
+
+	if !containsClusterCondition(loggingv1.InvalidMasters, corev1.ConditionTrue, &cluster.Status) {
+		t.Error("Exp. the in-memory status to contain the batched condition")
+	}
+
+	observed := &loggingv1.Elasticsearch{}
+	if err := k8sClient.Get(context.TODO(), key, observed); err != nil {
+		t.Errorf("failed with error: %s", err)
+	}
+	if len(observed.Status.Conditions) != 0 {
+		t.Errorf("Exp. no status write before flush, got conditions: %v", observed.Status.Conditions)
+	}
+
+	if err := b.flush(); err != nil {
+		t.Errorf("failed with error: %s", err)
+	}
+
+	if err := k8sClient.Get(context.TODO(), key, observed); err != nil {
+		t.Errorf("failed with error: %s", err)
+	}
+	if !containsClusterCondition(loggingv1.InvalidMasters, corev1.ConditionTrue, &observed.Status) ||
+		!containsClusterCondition(loggingv1.InvalidData, corev1.ConditionTrue, &observed.Status) {
+		t.Errorf("Exp. both conditions to be written on flush, got: %v", observed.Status.Conditions)
+	}
+
+	if getStatusBuilder(cluster) != nil {
+		t.Error("Exp. batching to stop after flush")
+	}
+}
@@ -0,0 +1,126 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ViaQ/logerr/kverrors"
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// ReconcileMode decides whether failing steps not critical to running the cluster abort
+// the reconcile
+type ReconcileMode string
+
+const (
+	// ReconcileBestEffort reports failing non-critical steps with the Degraded condition and
+	// a warning event, and continues the reconcile
+	ReconcileBestEffort ReconcileMode = "best-effort"
+	// ReconcileFailFast aborts the reconcile on the first failing step
+	ReconcileFailFast ReconcileMode = "fail-fast"
+)
+
+var reconcileMode = ReconcileBestEffort
+
+// SetReconcileMode sets whether failing non-critical steps, e.g. creating the ServiceMonitor
+// while the monitoring CRDs are unavailable, abort the reconcile
+func SetReconcileMode(mode string) error {
+	switch ReconcileMode(mode) {
+	case ReconcileBestEffort, ReconcileFailFast:
+		reconcileMode = ReconcileMode(mode)
+		return nil
+	}
+	return fmt.Errorf("invalid reconcile mode %q, expected %s or %s", mode, ReconcileBestEffort, ReconcileFailFast)
+}
+
+// reconcileStep reconciles a part of the cluster. Critical steps provision what the nodes
+// need to run, e.g. the config maps and deployments, and abort the reconcile when failing.
+// The others only serve monitoring, the console and the status.
+type reconcileStep struct {
+	// the name of the span tracing the step
+	name string
+	// the message the error of the step is wrapped with
+	failure string
+	// the reason of the Degraded condition reporting the non-critical step failed
+	degradedReason string
+	critical       bool
+	// the failure of the step is only reported, in fail-fast mode as well
+	bestEffort bool
+	run        func() error
+}
+
+// reconcileSteps returns the steps of the reconcile following the required secrets in order
+func (er *ElasticsearchRequest) reconcileSteps() []reconcileStep {
+	return []reconcileStep{
+		{name: "serviceaccount", failure: "Failed to reconcile ServiceAccount for Elasticsearch cluster", critical: true, run: er.CreateOrUpdateServiceAccount},
+		{name: "rbac", failure: "Failed to reconcile Roles and RoleBindings for Elasticsearch cluster", critical: true, run: er.CreateOrUpdateRBAC},
+		{name: "configmaps", failure: "Failed to reconcile ConfigMaps for Elasticsearch cluster", critical: true, run: er.CreateOrUpdateConfigMaps},
+		{name: "services", failure: "Failed to reconcile Services for Elasticsearch cluster", critical: true, run: er.CreateOrUpdateServices},
+		{name: "dashboards", failure: "Failed to reconcile Dashboards for Elasticsearch cluster", degradedReason: "Missing Dashboards", run: er.CreateOrUpdateDashboards},
+		{name: "nodes", failure: "Failed to reconcile Elasticsearch deployment spec", critical: true, run: er.CreateOrUpdateElasticsearchCluster},
+		{name: "autoscalers", failure: "Failed to reconcile node autoscalers for Elasticsearch cluster", degradedReason: "Missing Autoscalers", run: er.CreateOrUpdateAutoscalers},
+		{name: "ingestpipelines", failure: "Failed to reconcile ingest pipelines for Elasticsearch cluster", critical: true, run: er.CreateOrUpdateIngestPipelines},
+		{name: "indextiers", failure: "Failed to reconcile index data tiers for Elasticsearch cluster", critical: true, run: er.CreateOrUpdateIndexTiers},
+		{name: "autocreateindex", failure: "Failed to reconcile auto create index patterns for Elasticsearch cluster", degradedReason: "Drifted Auto Create Index", run: er.UpdateAutoCreateIndex},
+		{name: "license", failure: "Failed to reconcile license status for Elasticsearch cluster", degradedReason: "Unknown License", run: er.UpdateLicenseStatus},
+		{name: "servicemonitors", failure: "Failed to reconcile Service Monitors for Elasticsearch cluster", degradedReason: "Missing Service Monitors", run: er.CreateOrUpdateServiceMonitors},
+		{name: "prometheusrules", failure: "Failed to reconcile Prometheus Rules for Elasticsearch cluster", degradedReason: "Missing Prometheus Rules", bestEffort: true, run: er.CreateOrUpdatePrometheusRules},
+	}
+}
+
+// runReconcileSteps runs the steps in order. The first failing critical step aborts the
+// reconcile, as does any failing step not best-effort in fail-fast mode. Otherwise the failing steps are
+// reported with the Degraded condition, the reason being the one of the last failing step,
+// and a warning event whenever they change. The summary conditions are updated however the
+// steps end, the error aborting them degrading the cluster as well, and the Degraded
+// condition is cleared once they succeed and the cluster is healthy.
+func (er *ElasticsearchRequest) runReconcileSteps(steps []reconcileStep) (err error) {
+	var reason string
+	failures := []string{}
+
+	defer func() {
+		// waiting on the cluster does not degrade it
+		if err != nil && !IsRequeue(err) {
+			reason = "Reconcile Failed"
+			failures = append(failures, err.Error())
+		}
+
+		message := strings.Join(failures, "; ")
+		if len(failures) > 0 && !isDegradedByStepFailures(&er.cluster.Status, reason, message) {
+			recordEvent(er.recorder, er.cluster, v1.EventTypeWarning, eventReasonReconcileStepFailed, message)
+		}
+		if updateErr := er.UpdateSummaryConditions(reason, message); updateErr != nil {
+			er.L().Error(updateErr, "Unable to update summary conditions")
+		}
+	}()
+
+	for _, step := range steps {
+		endSpan := er.startSpan("step", "step", step.name)
+		err := step.run()
+		endSpan()
+		if err == nil {
+			continue
+		}
+		if step.critical || (reconcileMode == ReconcileFailFast && !step.bestEffort) {
+			return kverrors.Wrap(err, step.failure)
+		}
+
+		er.L().Error(err, step.failure)
+		reason = step.degradedReason
+		failures = append(failures, fmt.Sprintf("%s: %s", step.failure, err))
+	}
+
+	return nil
+}
+
+// isDegradedByStepFailures returns true if the Degraded condition already reports the
+// failing steps. Their cause comes first, thus other causes changing the message of the
+// condition, e.g. the cluster health, are ignored.
+func isDegradedByStepFailures(status *api.ElasticsearchStatus, reason, message string) bool {
+	_, condition := getESNodeCondition(status.Conditions, api.DegradedState)
+	if condition == nil || condition.Status != v1.ConditionTrue || condition.Reason != reason {
+		return false
+	}
+	return condition.Message == message || strings.HasPrefix(condition.Message, message+"; ")
+}
@@ -0,0 +1,197 @@
+package elasticsearch
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/apply"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	serviceMonitorsFailure = "Failed to reconcile Service Monitors for Elasticsearch cluster"
+	deploymentsFailure     = "Failed to reconcile Elasticsearch deployment spec"
+)
+
+func TestReconcileStepsCriticality(t *testing.T) {
+	critical := map[string]bool{}
+	for _, step := range (&ElasticsearchRequest{}).reconcileSteps() {
+		critical[step.failure] = step.critical
+		if !step.critical && step.degradedReason == "" {
+			t.Errorf("Exp. the non-critical step %q to have a Degraded reason", step.failure)
+		}
+		if want := step.failure == "Failed to reconcile Prometheus Rules for Elasticsearch cluster"; step.bestEffort != want {
+			t.Errorf("Exp. step %q to be best-effort %t but got %t", step.failure, want, step.bestEffort)
+		}
+	}
+
+	tests := map[string]bool{
+		"Failed to reconcile ConfigMaps for Elasticsearch cluster": true,
+		"Failed to reconcile Services for Elasticsearch cluster":   true,
+		deploymentsFailure: true,
+		"Failed to reconcile Dashboards for Elasticsearch cluster": false,
+		serviceMonitorsFailure: false,
+		"Failed to reconcile Prometheus Rules for Elasticsearch cluster":           false,
+		"Failed to reconcile license status for Elasticsearch cluster":             false,
+		"Failed to reconcile node autoscalers for Elasticsearch cluster":           false,
+		"Failed to reconcile auto create index patterns for Elasticsearch cluster": false,
+	}
+	for failure, want := range tests {
+		got, ok := critical[failure]
+		if !ok {
+			t.Errorf("Exp. a step failing with %q", failure)
+			continue
+		}
+		if got != want {
+			t.Errorf("Exp. step %q to be critical %t but got %t", failure, want, got)
+		}
+	}
+}
+
+func TestRunReconcileSteps(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	defer func() { reconcileMode = ReconcileBestEffort }()
+
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch", Namespace: "openshift-logging"},
+	}
+	recorder := record.NewFakeRecorder(10)
+	er := &ElasticsearchRequest{
+		cluster:  cluster,
+		client:   fake.NewFakeClient(cluster),
+		recorder: recorder,
+		ll:       log.Log.WithValues("cluster", "test-elasticsearch", "namespace", "test"),
+	}
+
+	// the service monitor fails while the deployments reconcile, all other steps succeed
+	var serviceMonitorErr error
+	var reconciledDeployments bool
+	newSteps := func() []reconcileStep {
+		reconciledDeployments = false
+		// run the failing step first to show it does not block the deployments
+		steps := []reconcileStep{}
+		for _, step := range er.reconcileSteps() {
+			switch step.failure {
+			case serviceMonitorsFailure:
+				step.run = func() error { return serviceMonitorErr }
+				steps = append([]reconcileStep{step}, steps...)
+				continue
+			case deploymentsFailure:
+				step.run = func() error {
+					reconciledDeployments = true
+					return nil
+				}
+			default:
+				step.run = func() error { return nil }
+			}
+			steps = append(steps, step)
+		}
+		return steps
+	}
+
+	serviceMonitorErr = errors.New("no matches for kind ServiceMonitor")
+	if err := er.runReconcileSteps(newSteps()); err != nil {
+		t.Errorf("Exp. a failing service monitor not to abort the reconcile but got %s", err)
+	}
+	if !reconciledDeployments {
+		t.Error("Exp. the deployments to reconcile despite the failing service monitor")
+	}
+
+	_, condition := getESNodeCondition(cluster.Status.Conditions, loggingv1.DegradedState)
+	if condition == nil || condition.Status != corev1.ConditionTrue || condition.Reason != "Missing Service Monitors" ||
+		!strings.Contains(condition.Message, serviceMonitorErr.Error()) {
+		t.Errorf("Exp. the failing service monitor to degrade the cluster but got %v", condition)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, eventReasonReconcileStepFailed) {
+			t.Errorf("Exp. a %s event but got %q", eventReasonReconcileStepFailed, event)
+		}
+	default:
+		t.Error("Exp. an event reporting the failing step")
+	}
+
+	// the same failure reports no other event while the cluster health changes the condition
+	cluster.Status.Cluster.Status = redClusterState
+	if err := er.runReconcileSteps(newSteps()); err != nil {
+		t.Errorf("failed with error: %s", err)
+	}
+	select {
+	case event := <-recorder.Events:
+		t.Errorf("Exp. no event for the failure already reported but got %q", event)
+	default:
+	}
+	cluster.Status.Cluster.Status = ""
+
+	if err := SetReconcileMode(string(ReconcileFailFast)); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+	if err := er.runReconcileSteps(newSteps()); err == nil || !strings.Contains(err.Error(), serviceMonitorsFailure) {
+		t.Errorf("Exp. the failing service monitor to abort the reconcile in fail-fast mode but got %v", err)
+	}
+	if reconciledDeployments {
+		t.Error("Exp. no step to run after the failing one in fail-fast mode")
+	}
+	_, condition = getESNodeCondition(cluster.Status.Conditions, loggingv1.DegradedState)
+	if condition == nil || condition.Status != corev1.ConditionTrue || condition.Reason != "Reconcile Failed" ||
+		!strings.Contains(condition.Message, serviceMonitorsFailure) {
+		t.Errorf("Exp. the aborted reconcile to degrade the cluster but got %v", condition)
+	}
+
+	// a failing best-effort step is only reported in fail-fast mode as well
+	steps := newSteps()
+	steps[0].bestEffort = true
+	if err := er.runReconcileSteps(steps); err != nil {
+		t.Errorf("Exp. the failing best-effort step not to abort the reconcile in fail-fast mode but got %v", err)
+	}
+	if !reconciledDeployments {
+		t.Error("Exp. the deployments to reconcile despite the failing best-effort step")
+	}
+
+	serviceMonitorErr = nil
+	if err := er.runReconcileSteps(newSteps()); err != nil {
+		t.Errorf("failed with error: %s", err)
+	}
+	if _, condition := getESNodeCondition(cluster.Status.Conditions, loggingv1.DegradedState); condition != nil {
+		t.Errorf("Exp. the Degraded condition to be cleared once all steps succeed but got %v", condition)
+	}
+
+	if err := SetReconcileMode("eventually"); err == nil {
+		t.Error("Exp. an unknown reconcile mode to be rejected")
+	}
+}
+
+func TestReconcileStepsSetOwnerReferences(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	_ = monitoringv1.AddToScheme(scheme.Scheme)
+	apply.SetStrictOwnerReferences(true)
+	defer apply.SetStrictOwnerReferences(false)
+
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch", Namespace: "openshift-logging"},
+	}
+	er := &ElasticsearchRequest{
+		cluster: cluster,
+		client:  apply.GuardOwnerReferences(fake.NewFakeClient(cluster), cluster.Namespace),
+		ll:      log.Log.WithValues("cluster", cluster.Name, "namespace", cluster.Namespace),
+	}
+
+	steps := map[string]func() error{
+		"service account":  er.CreateOrUpdateServiceAccount,
+		"services":         er.CreateOrUpdateServices,
+		"service monitors": er.CreateOrUpdateServiceMonitors,
+	}
+	for name, run := range steps {
+		if err := run(); err != nil {
+			t.Errorf("Exp. the %s to be created with a controller reference but got %v", name, err)
+		}
+	}
+}
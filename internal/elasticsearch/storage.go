@@ -0,0 +1,115 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ViaQ/logerr/kverrors"
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/persistentvolume"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// supportedAccessModes are the access modes the nodes can write their data with. The
+// ReadWriteOncePod access mode is left out, the API servers before 1.22 reject it.
+var supportedAccessModes = sets.NewString(
+	string(v1.ReadWriteOnce),
+	string(v1.ReadWriteMany),
+)
+
+// getPVCAccessModes returns the requested access modes of the PVC of a node group or
+// ReadWriteOnce if none are requested
+func getPVCAccessModes(storage api.ElasticsearchStorageSpec) []v1.PersistentVolumeAccessMode {
+	if len(storage.AccessModes) == 0 {
+		return []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce}
+	}
+	return append([]v1.PersistentVolumeAccessMode{}, storage.AccessModes...)
+}
+
+// getPVCVolumeMode returns the requested volume mode of the PVC of a node group or
+// Filesystem if none is requested
+func getPVCVolumeMode(mode *v1.PersistentVolumeMode) v1.PersistentVolumeMode {
+	if mode == nil || *mode == "" {
+		return v1.PersistentVolumeFilesystem
+	}
+	return *mode
+}
+
+// getInvalidStorage returns the node groups requesting access modes the nodes cannot
+// write their data with, the Block volume mode Elasticsearch cannot keep its data on,
+// and access modes without a size, which falls back to an emptyDir they do not apply to
+func getInvalidStorage(dpl *api.Elasticsearch) []string {
+	invalid := []string{}
+	for i, node := range dpl.Spec.Nodes {
+		storage := node.Storage
+
+		for _, mode := range storage.AccessModes {
+			if !supportedAccessModes.Has(string(mode)) {
+				invalid = append(invalid, fmt.Sprintf("nodes[%d] (unsupported access mode %q)", i, mode))
+			}
+		}
+		if getPVCVolumeMode(storage.VolumeMode) != v1.PersistentVolumeFilesystem {
+			invalid = append(invalid, fmt.Sprintf("nodes[%d] (unsupported volume mode %q)", i, *storage.VolumeMode))
+		}
+
+		if storage.Size != nil {
+			continue
+		}
+		if len(storage.AccessModes) > 0 {
+			invalid = append(invalid, fmt.Sprintf("nodes[%d] (access modes without size)", i))
+		}
+	}
+	return invalid
+}
+
+// isStorageModeChanged returns true if the requested access modes or volume mode differ
+// from those of the existing PVC, which are immutable and thus not updated
+func isStorageModeChanged(storage api.ElasticsearchStorageSpec, current *v1.PersistentVolumeClaim) bool {
+	requested := sets.NewString(accessModeStrings(getPVCAccessModes(storage))...)
+	if !requested.Equal(sets.NewString(accessModeStrings(current.Spec.AccessModes)...)) {
+		return true
+	}
+	return getPVCVolumeMode(storage.VolumeMode) != getPVCVolumeMode(current.Spec.VolumeMode)
+}
+
+func accessModeStrings(modes []v1.PersistentVolumeAccessMode) []string {
+	values := make([]string, 0, len(modes))
+	for _, mode := range modes {
+		values = append(values, string(mode))
+	}
+	return values
+}
+
+// updatePropagatedPVCAnnotations applies the propagated annotations to the PVCs mounted by
+// the pod template of a node. PVCs not created yet are skipped, they are updated once they
+// exist.
+func updatePropagatedPVCAnnotations(c client.Client, namespace string, template v1.PodTemplateSpec, propagated map[string]string) error {
+	desired := utils.WithPropagatedAnnotations(nil, propagated)
+	equalFunc := func(current, desired *v1.PersistentVolumeClaim) bool {
+		return utils.ContainsPropagatedAnnotations(current.Annotations, desired.Annotations)
+	}
+	mutateFunc := func(current, desired *v1.PersistentVolumeClaim) {
+		current.Annotations = utils.MergePropagatedAnnotations(current.Annotations, desired.Annotations)
+	}
+
+	for _, volume := range template.Spec.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+
+		pvc := persistentvolume.NewPVC(volume.PersistentVolumeClaim.ClaimName, namespace, nil)
+		pvc.Annotations = desired
+		err := persistentvolume.UpdatePVC(context.TODO(), c, pvc, equalFunc, mutateFunc)
+		if err != nil && !apierrors.IsNotFound(kverrors.Root(err)) {
+			return kverrors.Wrap(err, "failed to update propagated annotations of persistentvolumeclaim",
+				"name", pvc.Name,
+			)
+		}
+	}
+
+	return nil
+}
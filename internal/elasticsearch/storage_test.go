@@ -0,0 +1,134 @@
+package elasticsearch
+
+import (
+	"strings"
+	"testing"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestGetInvalidStorage(t *testing.T) {
+	size := resource.MustParse("10G")
+	filesystem := corev1.PersistentVolumeFilesystem
+	block := corev1.PersistentVolumeBlock
+
+	tests := []struct {
+		desc    string
+		storage loggingv1.ElasticsearchStorageSpec
+		invalid []string
+	}{
+		{
+			desc: "ephemeral storage",
+		},
+		{
+			desc:    "default access modes",
+			storage: loggingv1.ElasticsearchStorageSpec{Size: &size, VolumeMode: &filesystem},
+		},
+		{
+			desc:    "block volume mode",
+			storage: loggingv1.ElasticsearchStorageSpec{Size: &size, VolumeMode: &block},
+			invalid: []string{`nodes[0] (unsupported volume mode "Block")`},
+		},
+		{
+			desc: "supported access modes",
+			storage: loggingv1.ElasticsearchStorageSpec{
+				Size:        &size,
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce, corev1.ReadWriteMany},
+			},
+		},
+		{
+			desc: "read only access mode",
+			storage: loggingv1.ElasticsearchStorageSpec{
+				Size:        &size,
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadOnlyMany},
+			},
+			invalid: []string{"nodes[0] (unsupported access mode"},
+		},
+		{
+			desc: "ReadWriteOncePod access mode",
+			storage: loggingv1.ElasticsearchStorageSpec{
+				Size:        &size,
+				AccessModes: []corev1.PersistentVolumeAccessMode{"ReadWriteOncePod"},
+			},
+			invalid: []string{`nodes[0] (unsupported access mode "ReadWriteOncePod")`},
+		},
+		{
+			desc: "modes without size",
+			storage: loggingv1.ElasticsearchStorageSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+			},
+			invalid: []string{"nodes[0] (access modes without size)"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			dpl := &loggingv1.Elasticsearch{
+				Spec: loggingv1.ElasticsearchSpec{
+					Nodes: []loggingv1.ElasticsearchNode{{NodeCount: 1, Storage: test.storage}},
+				},
+			}
+
+			invalid := getInvalidStorage(dpl)
+			if len(invalid) != len(test.invalid) {
+				t.Fatalf("Exp. %v to be invalid but got %v", test.invalid, invalid)
+			}
+			for i, prefix := range test.invalid {
+				if !strings.HasPrefix(invalid[i], prefix) {
+					t.Errorf("Exp. %s to be invalid but got %s", prefix, invalid[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIsStorageModeChanged(t *testing.T) {
+	block := corev1.PersistentVolumeBlock
+	filesystem := corev1.PersistentVolumeFilesystem
+	pvc := func(mode *corev1.PersistentVolumeMode, accessModes ...corev1.PersistentVolumeAccessMode) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			Spec: corev1.PersistentVolumeClaimSpec{AccessModes: accessModes, VolumeMode: mode},
+		}
+	}
+
+	tests := []struct {
+		desc    string
+		storage loggingv1.ElasticsearchStorageSpec
+		current *corev1.PersistentVolumeClaim
+		changed bool
+	}{
+		{
+			desc:    "defaults",
+			current: pvc(&filesystem, corev1.ReadWriteOnce),
+		},
+		{
+			desc:    "reordered access modes",
+			storage: loggingv1.ElasticsearchStorageSpec{AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany, corev1.ReadWriteOnce}},
+			current: pvc(nil, corev1.ReadWriteOnce, corev1.ReadWriteMany),
+		},
+		{
+			desc:    "changed access modes",
+			storage: loggingv1.ElasticsearchStorageSpec{AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}},
+			current: pvc(nil, corev1.ReadWriteOnce),
+			changed: true,
+		},
+		{
+			desc:    "changed volume mode",
+			storage: loggingv1.ElasticsearchStorageSpec{VolumeMode: &block},
+			current: pvc(&filesystem, corev1.ReadWriteOnce),
+			changed: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			if got := isStorageModeChanged(test.storage, test.current); got != test.changed {
+				t.Errorf("Exp. changed to be %t but got %t", test.changed, got)
+			}
+		})
+	}
+}
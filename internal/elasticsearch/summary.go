@@ -0,0 +1,123 @@
+package elasticsearch
+
+import (
+	"strings"
+
+	"github.com/ViaQ/logerr/kverrors"
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+const redClusterState = "red"
+
+// summaryCause is a reason a summary condition is true, with the message telling why
+type summaryCause struct {
+	reason  string
+	message string
+}
+
+// newSummaryCondition returns the condition true for the causes, the reason being the one
+// of the first cause and the message joining all of them, or a false one without causes
+func newSummaryCondition(conditionType api.ClusterConditionType, causes []summaryCause) *api.ClusterCondition {
+	if len(causes) == 0 {
+		return &api.ClusterCondition{Type: conditionType, Status: v1.ConditionFalse}
+	}
+
+	messages := []string{}
+	for _, cause := range causes {
+		messages = append(messages, cause.message)
+	}
+	return &api.ClusterCondition{
+		Type:    conditionType,
+		Status:  v1.ConditionTrue,
+		Reason:  causes[0].reason,
+		Message: strings.Join(messages, "; "),
+	}
+}
+
+// newDegradedCondition returns the Degraded condition, true if non-critical reconcile steps
+// failed, the cluster health is red, no master is elected or nodes fail to start or rejoin
+func newDegradedCondition(status *api.ElasticsearchStatus, stepReason, stepMessage string) *api.ClusterCondition {
+	causes := []summaryCause{}
+	if stepMessage != "" {
+		causes = append(causes, summaryCause{stepReason, stepMessage})
+	}
+	if status.Cluster.Status == redClusterState {
+		causes = append(causes, summaryCause{"Cluster Red", "The cluster health is red, primary shards are unassigned"})
+	}
+	if _, condition := getESNodeCondition(status.Conditions, api.NoMasterElected); condition != nil && condition.Status == v1.ConditionTrue {
+		causes = append(causes, summaryCause{"No Master Elected", condition.Message})
+	}
+	if _, condition := getESNodeCondition(status.Conditions, api.NodesDegraded); condition != nil && condition.Status == v1.ConditionTrue {
+		causes = append(causes, summaryCause{"Nodes Failing", condition.Message})
+	}
+	return newSummaryCondition(api.DegradedState, causes)
+}
+
+// newProgressingCondition returns the Progressing condition, true while nodes are rolled
+// out, restarted or recovered, the cluster scales or nodes are still starting
+func newProgressingCondition(status *api.ElasticsearchStatus) *api.ClusterCondition {
+	causes := []summaryCause{}
+	if isRolloutInProgress(status) {
+		causes = append(causes, summaryCause{"Rollout In Progress", "Nodes are rolled out, restarted or recovered"})
+	}
+	if containsClusterCondition(api.ScalingUp, v1.ConditionTrue, status) ||
+		containsClusterCondition(api.ScalingDown, v1.ConditionTrue, status) {
+		causes = append(causes, summaryCause{"Scaling", "Nodes are added or removed"})
+	}
+	if _, condition := getESNodeCondition(status.Conditions, api.NodesProgressing); condition != nil && condition.Status == v1.ConditionTrue {
+		causes = append(causes, summaryCause{"Nodes Starting", condition.Message})
+	}
+	return newSummaryCondition(api.ProgressingState, causes)
+}
+
+// newAvailableCondition returns the Available condition, true if the cluster serves
+// requests, i.e. a master is elected and its health is green or yellow
+func newAvailableCondition(status *api.ElasticsearchStatus) *api.ClusterCondition {
+	health := status.Cluster.Status
+	if (health != greenClusterState && health != yellowClusterState) ||
+		containsClusterCondition(api.NoMasterElected, v1.ConditionTrue, status) {
+		return newSummaryCondition(api.AvailableState, nil)
+	}
+	return newSummaryCondition(api.AvailableState, []summaryCause{
+		{"Cluster Available", "The cluster serves requests with all primary shards assigned"},
+	})
+}
+
+// newReadyCondition returns the Ready condition, true if the cluster is available and
+// green without being degraded or progressing
+func newReadyCondition(status *api.ElasticsearchStatus, available, degraded, progressing *api.ClusterCondition) *api.ClusterCondition {
+	if available.Status != v1.ConditionTrue || status.Cluster.Status != greenClusterState ||
+		degraded.Status == v1.ConditionTrue || progressing.Status == v1.ConditionTrue {
+		return newSummaryCondition(api.ReadyState, nil)
+	}
+	return newSummaryCondition(api.ReadyState, []summaryCause{
+		{"Cluster Ready", "The cluster is green with all nodes started and up to date"},
+	})
+}
+
+// updateSummaryConditions derives the Ready, Available, Progressing and Degraded conditions
+// summarizing the state of the cluster from its health and the conditions of the nodes.
+// The failures of the non-critical reconcile steps degrade the cluster as well.
+func updateSummaryConditions(status *api.ElasticsearchStatus, stepReason, stepMessage string) bool {
+	degraded := newDegradedCondition(status, stepReason, stepMessage)
+	progressing := newProgressingCondition(status)
+	available := newAvailableCondition(status)
+	ready := newReadyCondition(status, available, degraded, progressing)
+
+	changed := false
+	for _, condition := range []*api.ClusterCondition{ready, available, progressing, degraded} {
+		if updateESNodeCondition(status, condition) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// UpdateSummaryConditions sets the conditions summarizing the state of the cluster
+func (er *ElasticsearchRequest) UpdateSummaryConditions(stepReason, stepMessage string) error {
+	err := updateConditionWithRetry(er.cluster, v1.ConditionTrue, func(status *api.ElasticsearchStatus, _ v1.ConditionStatus) bool {
+		return updateSummaryConditions(status, stepReason, stepMessage)
+	}, er.client)
+	return kverrors.Wrap(err, "failed to update summary conditions")
+}
@@ -0,0 +1,135 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestUpdateSummaryConditions(t *testing.T) {
+	tests := []struct {
+		desc        string
+		status      loggingv1.ElasticsearchStatus
+		stepReason  string
+		stepMessage string
+		// the reasons of the true summary conditions, absent ones being false
+		want map[loggingv1.ClusterConditionType]string
+	}{
+		{
+			desc: "all green",
+			status: loggingv1.ElasticsearchStatus{
+				Cluster: loggingv1.ClusterHealth{Status: greenClusterState},
+			},
+			want: map[loggingv1.ClusterConditionType]string{
+				loggingv1.ReadyState:     "Cluster Ready",
+				loggingv1.AvailableState: "Cluster Available",
+			},
+		},
+		{
+			desc: "mid rollout",
+			status: loggingv1.ElasticsearchStatus{
+				Cluster: loggingv1.ClusterHealth{Status: yellowClusterState},
+				Nodes: []loggingv1.ElasticsearchNodeStatus{
+					{
+						DeploymentName: "elasticsearch-cdm-1",
+						UpgradeStatus:  loggingv1.ElasticsearchNodeUpgradeStatus{UnderUpgrade: corev1.ConditionTrue},
+					},
+				},
+			},
+			want: map[loggingv1.ClusterConditionType]string{
+				loggingv1.AvailableState:   "Cluster Available",
+				loggingv1.ProgressingState: "Rollout In Progress",
+			},
+		},
+		{
+			desc: "node crashlooping",
+			status: loggingv1.ElasticsearchStatus{
+				Cluster: loggingv1.ClusterHealth{Status: yellowClusterState},
+				Conditions: []loggingv1.ClusterCondition{
+					{Type: loggingv1.NodesDegraded, Status: corev1.ConditionTrue, Reason: "Nodes Failing", Message: "Nodes failing to start: elasticsearch-cdm-1 (CrashLoopBackOff)"},
+				},
+			},
+			want: map[loggingv1.ClusterConditionType]string{
+				loggingv1.NodesDegraded:  "Nodes Failing",
+				loggingv1.AvailableState: "Cluster Available",
+				loggingv1.DegradedState:  "Nodes Failing",
+			},
+		},
+		{
+			desc: "no master",
+			status: loggingv1.ElasticsearchStatus{
+				Cluster: loggingv1.ClusterHealth{Status: healthUnknown},
+				Conditions: []loggingv1.ClusterCondition{
+					{Type: loggingv1.NoMasterElected, Status: corev1.ConditionTrue, Reason: "No Master Elected", Message: "The cluster has no elected master"},
+				},
+			},
+			want: map[loggingv1.ClusterConditionType]string{
+				loggingv1.NoMasterElected: "No Master Elected",
+				loggingv1.DegradedState:   "No Master Elected",
+			},
+		},
+		{
+			desc: "red with failing steps",
+			status: loggingv1.ElasticsearchStatus{
+				Cluster: loggingv1.ClusterHealth{Status: redClusterState},
+			},
+			stepReason:  "Missing Service Monitors",
+			stepMessage: "Failed to reconcile Service Monitors for Elasticsearch cluster: no matches for kind ServiceMonitor",
+			want: map[loggingv1.ClusterConditionType]string{
+				loggingv1.DegradedState: "Missing Service Monitors",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			status := test.status.DeepCopy()
+			if !updateSummaryConditions(status, test.stepReason, test.stepMessage) {
+				t.Error("Exp. the summary conditions to change")
+			}
+
+			got := map[loggingv1.ClusterConditionType]string{}
+			for _, condition := range status.Conditions {
+				if condition.Status == corev1.ConditionTrue {
+					got[condition.Type] = condition.Reason
+				}
+			}
+			if len(got) != len(test.want) {
+				t.Errorf("Exp. conditions %v but got %v", test.want, got)
+			}
+			for conditionType, reason := range test.want {
+				if got[conditionType] != reason {
+					t.Errorf("Exp. condition %s with reason %q but got %q", conditionType, reason, got[conditionType])
+				}
+			}
+
+			if updateSummaryConditions(status, test.stepReason, test.stepMessage) {
+				t.Error("Exp. no change without a change of the cluster")
+			}
+		})
+	}
+}
+
+func TestUpdateSummaryConditionsDegradedMessage(t *testing.T) {
+	status := &loggingv1.ElasticsearchStatus{
+		Cluster: loggingv1.ClusterHealth{Status: redClusterState},
+	}
+	updateSummaryConditions(status, "Missing Dashboards", "Failed to reconcile Dashboards for Elasticsearch cluster: timeout")
+
+	_, condition := getESNodeCondition(status.Conditions, loggingv1.DegradedState)
+	want := "Failed to reconcile Dashboards for Elasticsearch cluster: timeout; The cluster health is red, primary shards are unassigned"
+	if condition == nil || condition.Message != want {
+		t.Errorf("Exp. the Degraded condition to report all causes but got %v", condition)
+	}
+
+	status.Cluster.Status = greenClusterState
+	updateSummaryConditions(status, "", "")
+	if _, condition := getESNodeCondition(status.Conditions, loggingv1.DegradedState); condition != nil {
+		t.Errorf("Exp. the Degraded condition to be cleared once the cluster recovered but got %v", condition)
+	}
+	if _, condition := getESNodeCondition(status.Conditions, loggingv1.ReadyState); condition == nil {
+		t.Error("Exp. the recovered cluster to be ready")
+	}
+}
@@ -0,0 +1,128 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/pod"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// sysctlForbiddenReason is the reason of pods the kubelet rejects for sysctls it does not allow
+const sysctlForbiddenReason = "SysctlForbidden"
+
+var (
+	sysctlNamePattern = regexp.MustCompile(`^([a-z0-9]([-_a-z0-9]*[a-z0-9])?[\./])*[a-z0-9]([-_a-z0-9]*[a-z0-9])?$`)
+
+	// safeSysctls are the sysctls the kubelets allow by default as of 1.18. The later safe
+	// ones, e.g. net.ipv4.ip_unprivileged_port_start, need allowUnsafeSysctls.
+	safeSysctls = sets.NewString(
+		"kernel.shm_rmid_forced",
+		"net.ipv4.ip_local_port_range",
+		"net.ipv4.tcp_syncookies",
+		"net.ipv4.ping_group_range",
+	)
+
+	// namespacedSysctlPrefixes are the prefixes of the sysctls isolated per pod, only
+	// which can be set for the pods
+	namespacedSysctlPrefixes = []string{"kernel.shm", "kernel.msg", "kernel.sem", "fs.mqueue.", "net."}
+)
+
+// isSafeSysctl returns true if the sysctl is allowed by the kubelets by default
+func isSafeSysctl(name string) bool {
+	return safeSysctls.Has(name)
+}
+
+// isNamespacedSysctl returns true if the sysctl is isolated per pod
+func isNamespacedSysctl(name string) bool {
+	for _, prefix := range namespacedSysctlPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// getInvalidSysctls returns the requested sysctls which are malformed, requested more than
+// once, not isolated per pod or unsafe without unsafe sysctls being allowed
+func getInvalidSysctls(commonSpec api.ElasticsearchNodeSpec) []string {
+	invalid := []string{}
+	seen := sets.NewString()
+	for _, sysctl := range commonSpec.Sysctls {
+		name := strings.ReplaceAll(sysctl.Name, "/", ".")
+		switch {
+		case !sysctlNamePattern.MatchString(sysctl.Name):
+			invalid = append(invalid, fmt.Sprintf("%s (malformed name)", sysctl.Name))
+		case seen.Has(name):
+			invalid = append(invalid, fmt.Sprintf("%s (duplicate)", sysctl.Name))
+		case !isNamespacedSysctl(name):
+			invalid = append(invalid, fmt.Sprintf("%s (not namespaced)", sysctl.Name))
+		case !isSafeSysctl(name) && !commonSpec.AllowUnsafeSysctls:
+			invalid = append(invalid, fmt.Sprintf("%s (unsafe)", sysctl.Name))
+		}
+		seen.Insert(name)
+	}
+	return invalid
+}
+
+// getForbiddenSysctlPods returns the pods rejected by their node for sysctls its kubelet
+// does not allow, with the rejection message
+func getForbiddenSysctlPods(pods []v1.Pod) []string {
+	forbidden := []string{}
+	for _, p := range pods {
+		if p.Status.Phase == v1.PodFailed && p.Status.Reason == sysctlForbiddenReason {
+			forbidden = append(forbidden, fmt.Sprintf("%s: %s", p.Name, p.Status.Message))
+		}
+	}
+
+	sort.Strings(forbidden)
+	return forbidden
+}
+
+// updateSysctlsForbidden sets the SysctlsForbidden condition listing the pods rejected by
+// their node for the requested sysctls, and emits a warning event whenever they change, or
+// clears the condition if none is
+func (er *ElasticsearchRequest) updateSysctlsForbidden() {
+	cluster := er.cluster
+	if len(cluster.Spec.Spec.Sysctls) == 0 {
+		if err := updateCondition(cluster, api.SysctlsForbidden, sysctlForbiddenReason, v1.ConditionFalse, "", er.client); err != nil {
+			er.L().Error(err, "Unable to clear sysctls forbidden condition")
+		}
+		return
+	}
+
+	selector := map[string]string{
+		"component":    "elasticsearch",
+		"cluster-name": cluster.Name,
+	}
+	pods, err := pod.List(context.TODO(), er.client, cluster.Namespace, selector)
+	if err != nil {
+		er.L().Error(err, "Unable to list pods to check their sysctls")
+		return
+	}
+
+	forbidden := getForbiddenSysctlPods(pods)
+	if len(forbidden) == 0 {
+		if err := updateCondition(cluster, api.SysctlsForbidden, sysctlForbiddenReason, v1.ConditionFalse, "", er.client); err != nil {
+			er.L().Error(err, "Unable to clear sysctls forbidden condition")
+		}
+		return
+	}
+
+	message := fmt.Sprintf("Pods were rejected by their node for unsafe sysctls not allowed by the kubelet, please allow them with --allowed-unsafe-sysctls or remove them: %s", strings.Join(forbidden, "; "))
+	if _, condition := getESNodeCondition(cluster.Status.Conditions, api.SysctlsForbidden); condition != nil &&
+		condition.Status == v1.ConditionTrue && condition.Message == message {
+		return
+	}
+
+	er.L().Info(message)
+	recordEvent(er.recorder, cluster, v1.EventTypeWarning, eventReasonSysctlsForbidden, message)
+	if err := updateCondition(cluster, api.SysctlsForbidden, sysctlForbiddenReason, v1.ConditionTrue, message, er.client); err != nil {
+		er.L().Error(err, "Unable to set sysctls forbidden condition")
+	}
+}
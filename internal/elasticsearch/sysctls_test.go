@@ -0,0 +1,163 @@
+package elasticsearch
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetInvalidSysctls(t *testing.T) {
+	tests := []struct {
+		desc    string
+		spec    loggingv1.ElasticsearchNodeSpec
+		invalid []string
+	}{
+		{
+			desc: "safe sysctls",
+			spec: loggingv1.ElasticsearchNodeSpec{
+				Sysctls: []corev1.Sysctl{{Name: "net.ipv4.tcp_syncookies", Value: "1"}, {Name: "net/ipv4/ip_local_port_range", Value: "1024 65535"}},
+			},
+		},
+		{
+			desc: "unsafe sysctl without allowing unsafe ones",
+			spec: loggingv1.ElasticsearchNodeSpec{
+				Sysctls: []corev1.Sysctl{{Name: "net.core.somaxconn", Value: "1024"}},
+			},
+			invalid: []string{"net.core.somaxconn (unsafe)"},
+		},
+		{
+			desc: "sysctl safe only on later kubelets",
+			spec: loggingv1.ElasticsearchNodeSpec{
+				Sysctls: []corev1.Sysctl{{Name: "net.ipv4.ip_unprivileged_port_start", Value: "0"}},
+			},
+			invalid: []string{"net.ipv4.ip_unprivileged_port_start (unsafe)"},
+		},
+		{
+			desc: "unsafe sysctl allowed",
+			spec: loggingv1.ElasticsearchNodeSpec{
+				Sysctls:            []corev1.Sysctl{{Name: "net.core.somaxconn", Value: "1024"}},
+				AllowUnsafeSysctls: true,
+			},
+		},
+		{
+			desc: "node level, duplicate and malformed sysctls",
+			spec: loggingv1.ElasticsearchNodeSpec{
+				Sysctls: []corev1.Sysctl{
+					{Name: "vm.max_map_count", Value: "262144"},
+					{Name: "net.ipv4.tcp_syncookies", Value: "1"},
+					{Name: "net/ipv4/tcp_syncookies", Value: "0"},
+					{Name: "Net.Core", Value: "1"},
+				},
+				AllowUnsafeSysctls: true,
+			},
+			invalid: []string{"vm.max_map_count (not namespaced)", "net/ipv4/tcp_syncookies (duplicate)", "Net.Core (malformed name)"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			invalid := getInvalidSysctls(test.spec)
+			if strings.Join(invalid, ", ") != strings.Join(test.invalid, ", ") {
+				t.Errorf("Exp. invalid sysctls %v but got %v", test.invalid, invalid)
+			}
+		})
+	}
+}
+
+func TestPodSecurityContextSysctls(t *testing.T) {
+	sysctls := []corev1.Sysctl{{Name: "net.core.somaxconn", Value: "1024"}}
+
+	if sc := newPodSecurityContext(nil, nil); sc != nil {
+		t.Errorf("Exp. no pod security context but got %v", sc)
+	}
+	if sc := newPodSecurityContext(nil, sysctls); sc == nil || sc.RunAsUser != nil || len(sc.Sysctls) != 1 {
+		t.Errorf("Exp. the pod security context to only set the sysctls but got %v", sc)
+	}
+	if sc := newPodSecurityContext(&loggingv1.ElasticsearchSecurityContext{}, sysctls); sc == nil || sc.RunAsUser == nil || len(sc.Sysctls) != 1 {
+		t.Errorf("Exp. the pod security context to set the user and the sysctls but got %v", sc)
+	}
+}
+
+func TestUpdateSysctlsForbidden(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch",
+			Namespace: "openshift-logging",
+		},
+		Spec: loggingv1.ElasticsearchSpec{
+			Spec: loggingv1.ElasticsearchNodeSpec{
+				Sysctls:            []corev1.Sysctl{{Name: "net.core.somaxconn", Value: "1024"}},
+				AllowUnsafeSysctls: true,
+			},
+		},
+	}
+	rejected := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch-cdm-1",
+			Namespace: cluster.Namespace,
+			Labels: map[string]string{
+				"component":    "elasticsearch",
+				"cluster-name": cluster.Name,
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase:   corev1.PodFailed,
+			Reason:  sysctlForbiddenReason,
+			Message: "Pod forbidden sysctl: \"net.core.somaxconn\" not allowlisted",
+		},
+	}
+	k8sClient := fake.NewFakeClient(cluster, rejected)
+	recorder := record.NewFakeRecorder(2)
+
+	er := &ElasticsearchRequest{
+		cluster:  cluster,
+		client:   k8sClient,
+		recorder: recorder,
+	}
+
+	getCondition := func() *loggingv1.ClusterCondition {
+		current := &loggingv1.Elasticsearch{}
+		if err := k8sClient.Get(context.TODO(), client.ObjectKey{Name: cluster.Name, Namespace: cluster.Namespace}, current); err != nil {
+			t.Fatalf("failed to get cluster: %s", err)
+		}
+		_, condition := getESNodeCondition(current.Status.Conditions, loggingv1.SysctlsForbidden)
+		return condition
+	}
+
+	er.updateSysctlsForbidden()
+	condition := getCondition()
+	if condition == nil || condition.Status != corev1.ConditionTrue || !strings.Contains(condition.Message, "elasticsearch-cdm-1: Pod forbidden sysctl") {
+		t.Errorf("Exp. the sysctls forbidden condition to list the rejected pod but got %v", condition)
+	}
+	event := <-recorder.Events
+	if !strings.HasPrefix(event, "Warning "+eventReasonSysctlsForbidden) || !strings.Contains(event, "--allowed-unsafe-sysctls") {
+		t.Errorf("Exp. a warning event for the rejected pod but got %q", event)
+	}
+
+	// unchanged rejections are not reported again
+	er.updateSysctlsForbidden()
+	select {
+	case event := <-recorder.Events:
+		t.Errorf("Exp. no further event but got %q", event)
+	default:
+	}
+
+	if err := k8sClient.Delete(context.TODO(), rejected); err != nil {
+		t.Fatalf("failed to delete pod: %s", err)
+	}
+	er.updateSysctlsForbidden()
+	if condition := getCondition(); condition != nil {
+		t.Errorf("Exp. the sysctls forbidden condition to be cleared but got %v", condition)
+	}
+}
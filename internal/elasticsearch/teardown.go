@@ -0,0 +1,251 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ViaQ/logerr/kverrors"
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/constants"
+	"github.com/openshift/elasticsearch-operator/internal/elasticsearch/esclient"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/console"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/deployment"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/rbac"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
+	apps "k8s.io/api/apps/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// finalSnapshotRepositoryAnnotation names the snapshot repository used to take a
+	// final snapshot of all indices before the cluster is torn down
+	finalSnapshotRepositoryAnnotation = "elasticsearch.openshift.io/final-snapshot-repository"
+
+	proxyClusterRoleBindingName = "elasticsearch-proxy"
+)
+
+// Teardown cleans up the resources of a deleted Elasticsearch cluster that are not
+// garbage collected by owner references and removes the finalizer afterwards.
+func Teardown(ctx context.Context, requestCluster *api.Elasticsearch, requestClient client.Client) error {
+	esClient := esclient.NewClient(requestCluster.Name, requestCluster.Namespace, requestClient)
+
+	elasticsearchRequest := ElasticsearchRequest{
+		client:   requestClient,
+		cluster:  requestCluster,
+		esClient: esClient,
+		ll:       utils.NewReconcileLogger(requestCluster.Name, requestCluster.Namespace),
+	}
+
+	return elasticsearchRequest.Teardown(ctx)
+}
+
+// Teardown runs every cleanup step and removes the finalizer once all of them succeeded.
+// Each step is idempotent, so that a failed teardown can be re-run until it completes.
+func (er *ElasticsearchRequest) Teardown(ctx context.Context) error {
+	if !sliceContainsString(er.cluster.GetFinalizers(), constants.ElasticsearchFinalizer) {
+		return nil
+	}
+
+	if err := er.settleRollout(ctx); err != nil {
+		return kverrors.Wrap(err, "failed to settle node rollout of Elasticsearch cluster")
+	}
+
+	if err := er.takeFinalSnapshot(); err != nil {
+		return kverrors.Wrap(err, "failed to take final snapshot of Elasticsearch cluster")
+	}
+
+	if err := er.deleteConsoleLinks(ctx); err != nil {
+		return kverrors.Wrap(err, "failed to delete console links for Elasticsearch cluster")
+	}
+
+	if err := er.removeProxyRoleBindingSubject(ctx); err != nil {
+		return kverrors.Wrap(err, "failed to remove Elasticsearch cluster from proxy clusterrolebinding")
+	}
+
+	if err := er.removeFinalizer(ctx); err != nil {
+		return kverrors.Wrap(err, "failed to remove finalizer from Elasticsearch cluster")
+	}
+
+	er.L().Info("Completed teardown of Elasticsearch cluster")
+
+	return nil
+}
+
+// EnsureFinalizer adds the teardown finalizer to the Elasticsearch cluster if missing
+func EnsureFinalizer(ctx context.Context, requestCluster *api.Elasticsearch, requestClient client.Client) error {
+	if sliceContainsString(requestCluster.GetFinalizers(), constants.ElasticsearchFinalizer) {
+		return nil
+	}
+
+	return updateFinalizers(ctx, requestCluster, requestClient, func(finalizers []string) []string {
+		if sliceContainsString(finalizers, constants.ElasticsearchFinalizer) {
+			return finalizers
+		}
+		return append(finalizers, constants.ElasticsearchFinalizer)
+	})
+}
+
+func (er *ElasticsearchRequest) removeFinalizer(ctx context.Context) error {
+	return updateFinalizers(ctx, er.cluster, er.client, func(finalizers []string) []string {
+		remaining := []string{}
+		for _, f := range finalizers {
+			if f != constants.ElasticsearchFinalizer {
+				remaining = append(remaining, f)
+			}
+		}
+		return remaining
+	})
+}
+
+func updateFinalizers(ctx context.Context, cluster *api.Elasticsearch, c client.Client, mutate func([]string) []string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current := &api.Elasticsearch{}
+		if err := c.Get(ctx, types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, current); err != nil {
+			return err
+		}
+
+		current.SetFinalizers(mutate(current.GetFinalizers()))
+		if err := c.Update(ctx, current); err != nil {
+			return err
+		}
+
+		cluster.SetFinalizers(current.GetFinalizers())
+		return nil
+	})
+}
+
+// settleRollout restores the state a node rollout interrupted by the deletion leaves
+// behind, since the cluster settings outlive the nodes it is torn down with. The shard
+// allocation is re-enabled, unpaused node deployments are paused again and the nodes of
+// the cluster are forgotten, so that a cluster recreated under the same name starts anew.
+func (er *ElasticsearchRequest) settleRollout(ctx context.Context) error {
+	if !IsRolloutInProgress(er.cluster) {
+		return nil
+	}
+
+	er.L().Info("Elasticsearch cluster deleted during a node rollout, settling it before teardown")
+
+	if er.AnyNodeReady() {
+		if ok, err := er.esClient.SetShardAllocation(api.ShardAllocationAll); !ok {
+			if err == nil {
+				err = kverrors.New("shard allocation not acknowledged")
+			}
+			return kverrors.Wrap(err, "failed to re-enable shard allocation")
+		}
+	}
+
+	selector := map[string]string{
+		"cluster-name": er.cluster.Name,
+	}
+	deployments, err := deployment.List(ctx, er.client, er.cluster.Namespace, selector)
+	if err != nil {
+		return err
+	}
+
+	equalFunc := func(current, _ *apps.Deployment) bool {
+		return current.Spec.Paused
+	}
+	mutateFunc := func(current, _ *apps.Deployment) {
+		current.Spec.Paused = true
+	}
+	for i := range deployments {
+		dpl := &deployments[i]
+		if dpl.Spec.Paused || !dpl.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if err := deployment.Update(ctx, er.client, dpl, equalFunc, mutateFunc); err != nil {
+			if apierrors.IsNotFound(kverrors.Root(err)) {
+				continue
+			}
+			return err
+		}
+	}
+
+	delete(nodes, nodeMapKey(er.cluster.Name, er.cluster.Namespace))
+
+	return nil
+}
+
+// takeFinalSnapshot takes a snapshot of all indices if a repository is configured.
+// If no nodes are left running, e.g. during a foreground deletion, there is nothing to
+// take a snapshot from anymore and the step is skipped.
+func (er *ElasticsearchRequest) takeFinalSnapshot() error {
+	repository, ok := er.cluster.GetAnnotations()[finalSnapshotRepositoryAnnotation]
+	if !ok || repository == "" {
+		return nil
+	}
+
+	if !er.AnyNodeReady() {
+		er.L().Info("Skipping final snapshot, no Elasticsearch nodes are ready", "repository", repository)
+		return nil
+	}
+
+	snapshot := fmt.Sprintf("%s-final", er.cluster.Name)
+	if err := er.esClient.CreateSnapshot(repository, snapshot); err != nil {
+		return err
+	}
+
+	er.L().Info("Took final snapshot of Elasticsearch cluster", "repository", repository, "snapshot", snapshot)
+	return nil
+}
+
+// deleteConsoleLinks deletes the cluster scoped console links created for
+// the Kibana instance of the cluster logging use case
+func (er *ElasticsearchRequest) deleteConsoleLinks(ctx context.Context) error {
+	if er.cluster.Namespace != constants.OpenshiftLoggingNamespace {
+		return nil
+	}
+
+	key := client.ObjectKey{Name: constants.KibanaConsoleLinkName}
+	if err := console.DeleteConsoleLink(ctx, er.client, key); err != nil {
+		if !apierrors.IsNotFound(kverrors.Root(err)) {
+			return err
+		}
+	}
+
+	key = client.ObjectKey{Name: constants.KibanaConsoleExternalLogLinkName}
+	if err := console.DeleteConsoleExternalLogLink(ctx, er.client, key); err != nil {
+		if !apierrors.IsNotFound(kverrors.Root(err)) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeProxyRoleBindingSubject removes the service account of the cluster from
+// the subjects of the elasticsearch-proxy clusterrolebinding
+func (er *ElasticsearchRequest) removeProxyRoleBindingSubject(ctx context.Context) error {
+	key := client.ObjectKey{Name: proxyClusterRoleBindingName}
+	current, err := rbac.GetClusterRoleBinding(ctx, er.client, key)
+	if err != nil {
+		if apierrors.IsNotFound(kverrors.Root(err)) {
+			return nil
+		}
+		return err
+	}
+
+	subjects := []rbacv1.Subject{}
+	for _, subject := range current.Subjects {
+		if subject.Kind == "ServiceAccount" && subject.Name == er.cluster.Name && subject.Namespace == er.cluster.Namespace {
+			continue
+		}
+		subjects = append(subjects, subject)
+	}
+
+	if len(subjects) == len(current.Subjects) {
+		return nil
+	}
+
+	proxyRoleBinding := rbac.NewClusterRoleBinding(
+		proxyClusterRoleBindingName,
+		proxyClusterRoleBindingName,
+		subjects,
+	)
+
+	return rbac.CreateOrUpdateClusterRoleBinding(ctx, er.client, proxyRoleBinding)
+}
@@ -0,0 +1,190 @@
+package elasticsearch
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	consolev1 "github.com/openshift/api/console/v1"
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/constants"
+	"github.com/openshift/elasticsearch-operator/test/helpers"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTeardownCluster() *api.Elasticsearch {
+	return &api.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "elasticsearch",
+			Namespace:  constants.OpenshiftLoggingNamespace,
+			Finalizers: []string{constants.ElasticsearchFinalizer},
+		},
+	}
+}
+
+func TestTeardownRemovesClusterScopedResources(t *testing.T) {
+	_ = api.SchemeBuilder.AddToScheme(scheme.Scheme)
+	_ = consolev1.AddToScheme(scheme.Scheme)
+
+	cluster := newTeardownCluster()
+
+	binding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: proxyClusterRoleBindingName,
+		},
+		Subjects: []rbacv1.Subject{
+			{Kind: "ServiceAccount", Name: "elasticsearch", Namespace: constants.OpenshiftLoggingNamespace},
+			{Kind: "ServiceAccount", Name: "other", Namespace: "other-namespace"},
+		},
+	}
+
+	consoleLink := &consolev1.ConsoleLink{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.KibanaConsoleLinkName},
+	}
+
+	consoleExternalLogLink := &consolev1.ConsoleExternalLogLink{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.KibanaConsoleExternalLogLinkName},
+	}
+
+	k8sClient := fake.NewFakeClient(cluster, binding, consoleLink, consoleExternalLogLink)
+
+	if err := Teardown(context.TODO(), cluster, k8sClient); err != nil {
+		t.Fatalf("Expected teardown to succeed, got: %v", err)
+	}
+
+	// re-running the teardown must not fail
+	if err := Teardown(context.TODO(), cluster, k8sClient); err != nil {
+		t.Fatalf("Expected repeated teardown to succeed, got: %v", err)
+	}
+
+	current := &rbacv1.ClusterRoleBinding{}
+	if err := k8sClient.Get(context.TODO(), client.ObjectKey{Name: proxyClusterRoleBindingName}, current); err != nil {
+		t.Fatalf("Expected to get proxy clusterrolebinding, got: %v", err)
+	}
+
+	if len(current.Subjects) != 1 || current.Subjects[0].Name != "other" {
+		t.Errorf("Expected only the subject of the other cluster to remain, got: %v", current.Subjects)
+	}
+
+	err := k8sClient.Get(context.TODO(), client.ObjectKey{Name: constants.KibanaConsoleLinkName}, &consolev1.ConsoleLink{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("Expected console link to be deleted, got: %v", err)
+	}
+
+	err = k8sClient.Get(context.TODO(), client.ObjectKey{Name: constants.KibanaConsoleExternalLogLinkName}, &consolev1.ConsoleExternalLogLink{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("Expected console external log link to be deleted, got: %v", err)
+	}
+
+	es := &api.Elasticsearch{}
+	if err := k8sClient.Get(context.TODO(), types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, es); err != nil {
+		t.Fatalf("Expected to get elasticsearch, got: %v", err)
+	}
+
+	if sliceContainsString(es.GetFinalizers(), constants.ElasticsearchFinalizer) {
+		t.Errorf("Expected finalizer to be removed, got: %v", es.GetFinalizers())
+	}
+}
+
+func TestTeardownPartiallyDeletedState(t *testing.T) {
+	_ = api.SchemeBuilder.AddToScheme(scheme.Scheme)
+	_ = consolev1.AddToScheme(scheme.Scheme)
+
+	cluster := newTeardownCluster()
+	k8sClient := fake.NewFakeClient(cluster)
+
+	if err := Teardown(context.TODO(), cluster, k8sClient); err != nil {
+		t.Fatalf("Expected teardown to succeed, got: %v", err)
+	}
+
+	if sliceContainsString(cluster.GetFinalizers(), constants.ElasticsearchFinalizer) {
+		t.Errorf("Expected finalizer to be removed, got: %v", cluster.GetFinalizers())
+	}
+}
+
+func TestTeardownSettlesRolloutInProgress(t *testing.T) {
+	_ = api.SchemeBuilder.AddToScheme(scheme.Scheme)
+	_ = consolev1.AddToScheme(scheme.Scheme)
+
+	cluster := newTeardownCluster()
+	cluster.Status.Nodes = []api.ElasticsearchNodeStatus{
+		{
+			DeploymentName: "elasticsearch-cdm-1",
+			UpgradeStatus:  api.ElasticsearchNodeUpgradeStatus{UnderUpgrade: corev1.ConditionTrue},
+		},
+	}
+
+	readyPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch-cdm-1-pod",
+			Namespace: cluster.Namespace,
+			Labels: map[string]string{
+				"component":      "elasticsearch",
+				"cluster-name":   cluster.Name,
+				"es-node-master": "true",
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{Ready: true}},
+		},
+	}
+
+	unpaused := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch-cdm-1",
+			Namespace: cluster.Namespace,
+			Labels:    map[string]string{"cluster-name": cluster.Name},
+		},
+	}
+
+	k8sClient := fake.NewFakeClient(cluster, readyPod, unpaused)
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"_cluster/settings": {
+			{StatusCode: http.StatusOK, Body: `{"acknowledged": true}`},
+		},
+	})
+
+	key := nodeMapKey(cluster.Name, cluster.Namespace)
+	nodes = map[string][]NodeTypeInterface{key: {&deploymentNode{}}}
+
+	er := &ElasticsearchRequest{
+		cluster:  cluster,
+		client:   k8sClient,
+		esClient: helpers.NewFakeElasticsearchClient(cluster.Name, cluster.Namespace, k8sClient, chatter),
+	}
+
+	if err := er.Teardown(context.TODO()); err != nil {
+		t.Fatalf("Expected teardown to succeed, got: %v", err)
+	}
+
+	req, ok := chatter.GetRequest("_cluster/settings")
+	if !ok || req.Method != http.MethodPut || !strings.Contains(req.Body, `"cluster.routing.allocation.enable":"all"`) {
+		t.Errorf("Expected shard allocation to be re-enabled, got: %v", req)
+	}
+
+	current := &appsv1.Deployment{}
+	if err := k8sClient.Get(context.TODO(), client.ObjectKey{Name: unpaused.Name, Namespace: unpaused.Namespace}, current); err != nil {
+		t.Fatalf("Expected to get node deployment, got: %v", err)
+	}
+	if !current.Spec.Paused {
+		t.Errorf("Expected the node deployment to be paused again")
+	}
+
+	if _, ok := nodes[key]; ok {
+		t.Errorf("Expected the nodes of the cluster to be forgotten, got: %v", nodes[key])
+	}
+
+	if sliceContainsString(cluster.GetFinalizers(), constants.ElasticsearchFinalizer) {
+		t.Errorf("Expected finalizer to be removed, got: %v", cluster.GetFinalizers())
+	}
+}
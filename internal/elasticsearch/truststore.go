@@ -0,0 +1,202 @@
+package elasticsearch
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strings"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/configmap"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/secret"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// trustedCAPath is where the init container reads the additional CA certificates from
+	trustedCAPath = "/etc/elasticsearch/trusted-ca"
+	// truststorePath is where the init container writes the truststore the JVM uses
+	truststorePath = "/etc/elasticsearch/truststore"
+	// truststorePassword is the well known default password of the JVM cacerts
+	truststorePassword = "changeit"
+)
+
+// importTrustedCAScript copies the cacerts of the JVM of the image to the truststore volume
+// and imports every certificate of the mounted files, splitting files holding several
+const importTrustedCAScript = `set -e
+java_home=$(dirname "$(dirname "$(readlink -f "$(command -v java)")")")
+cacerts=$(find "$java_home" -path "*/security/cacerts" | head -n 1)
+cp "$cacerts" ` + truststorePath + `/cacerts
+chmod u+w ` + truststorePath + `/cacerts
+mkdir -p ` + truststorePath + `/certs
+for file in ` + trustedCAPath + `/*; do
+  name=$(basename "$file")
+  awk -v prefix="` + truststorePath + `/certs/$name-" '/-----BEGIN CERTIFICATE-----/ {n++} n {print > (prefix n ".pem")}' "$file"
+done
+for cert in ` + truststorePath + `/certs/*.pem; do
+  "$java_home/bin/keytool" -importcert -noprompt -keystore ` + truststorePath + `/cacerts -storepass ` + truststorePassword + ` -alias "$(basename "$cert" .pem)" -file "$cert"
+done
+rm -rf ` + truststorePath + `/certs
+`
+
+func hasTrustedCA(commonSpec api.ElasticsearchNodeSpec) bool {
+	return commonSpec.TrustedCA != nil
+}
+
+// newImportTrustedCAContainer returns the init container building the truststore of the
+// JVM from its default CAs and the additional ones
+func newImportTrustedCAContainer(imageName string) v1.Container {
+	return v1.Container{
+		Name:                     importTrustedCAContainerName,
+		Image:                    imageName,
+		ImagePullPolicy:          "IfNotPresent",
+		TerminationMessagePolicy: v1.TerminationMessageFallbackToLogsOnError,
+		Command:                  []string{"sh", "-c", importTrustedCAScript},
+		Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("10m"),
+				v1.ResourceMemory: resource.MustParse("64Mi"),
+			},
+		},
+		VolumeMounts: []v1.VolumeMount{
+			{
+				Name:      trustedCAVolumeName,
+				MountPath: trustedCAPath,
+				ReadOnly:  true,
+			},
+			{
+				Name:      truststoreVolumeName,
+				MountPath: truststorePath,
+			},
+		},
+	}
+}
+
+// newTrustedCAVolumes returns the volume of the additional CA certificates and the one
+// of the truststore built from them
+func newTrustedCAVolumes(trustedCA *api.ElasticsearchTrustedCASpec) []v1.Volume {
+	source := v1.VolumeSource{}
+	if trustedCA.SecretName != "" {
+		source.Secret = &v1.SecretVolumeSource{SecretName: trustedCA.SecretName}
+	} else {
+		source.ConfigMap = &v1.ConfigMapVolumeSource{
+			LocalObjectReference: v1.LocalObjectReference{Name: trustedCA.ConfigMapName},
+		}
+	}
+
+	return []v1.Volume{
+		{
+			Name:         trustedCAVolumeName,
+			VolumeSource: source,
+		},
+		{
+			Name: truststoreVolumeName,
+			VolumeSource: v1.VolumeSource{
+				EmptyDir: &v1.EmptyDirVolumeSource{},
+			},
+		},
+	}
+}
+
+// setTrustedCA mounts the truststore into the Elasticsearch container and points the JVM
+// to it. The hash of the certificates rolls the nodes when they change, since the
+// truststore is only built when the pods start.
+func setTrustedCA(container *v1.Container, hash string) {
+	container.VolumeMounts = append(container.VolumeMounts, v1.VolumeMount{
+		Name:      truststoreVolumeName,
+		MountPath: truststorePath,
+		ReadOnly:  true,
+	})
+	container.Env = append(container.Env,
+		v1.EnvVar{
+			Name:  "JAVA_TOOL_OPTIONS",
+			Value: fmt.Sprintf("-Djavax.net.ssl.trustStore=%s/cacerts -Djavax.net.ssl.trustStorePassword=%s", truststorePath, truststorePassword),
+		},
+		v1.EnvVar{
+			Name:  "TRUSTED_CA_HASH",
+			Value: hash,
+		},
+	)
+}
+
+// getTrustedCAHash returns the checksum of the additional CA certificates or an empty
+// hash if they are missing
+func getTrustedCAHash(c client.Client, namespace string, trustedCA *api.ElasticsearchTrustedCASpec) string {
+	if c == nil {
+		return ""
+	}
+	if trustedCA.SecretName != "" {
+		return secret.GetDataSHA256(context.TODO(), c, client.ObjectKey{Name: trustedCA.SecretName, Namespace: namespace})
+	}
+	return configmap.GetDataSHA256(context.TODO(), c, client.ObjectKey{Name: trustedCA.ConfigMapName, Namespace: namespace}, nil)
+}
+
+// getInvalidTrustedCAs returns the keys of the additional CA certificates holding
+// anything but PEM encoded certificates, or the reason the certificates cannot be read
+func getInvalidTrustedCAs(c client.Client, namespace string, trustedCA *api.ElasticsearchTrustedCASpec) []string {
+	if trustedCA == nil {
+		return nil
+	}
+
+	var data map[string]string
+	switch {
+	case (trustedCA.ConfigMapName == "") == (trustedCA.SecretName == ""):
+		return []string{"exactly one of configMapName and secretName must be set"}
+	case trustedCA.SecretName != "":
+		s, err := secret.Get(context.TODO(), c, client.ObjectKey{Name: trustedCA.SecretName, Namespace: namespace})
+		if err != nil {
+			return []string{fmt.Sprintf("secret %s (%s)", trustedCA.SecretName, err)}
+		}
+		data = map[string]string{}
+		for key, value := range s.Data {
+			data[key] = string(value)
+		}
+	default:
+		cm, err := configmap.Get(context.TODO(), c, client.ObjectKey{Name: trustedCA.ConfigMapName, Namespace: namespace})
+		if err != nil {
+			return []string{fmt.Sprintf("config map %s (%s)", trustedCA.ConfigMapName, err)}
+		}
+		data = cm.Data
+	}
+
+	if len(data) == 0 {
+		return []string{"no CA certificates"}
+	}
+
+	invalid := []string{}
+	for key, value := range data {
+		if !isPEMCertificates(value) {
+			invalid = append(invalid, key)
+		}
+	}
+	sort.Strings(invalid)
+
+	return invalid
+}
+
+// isPEMCertificates returns true if the value holds one or more PEM encoded certificates
+// and nothing else
+func isPEMCertificates(value string) bool {
+	rest := []byte(value)
+	found := false
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return false
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return false
+		}
+		found = true
+	}
+
+	return found && strings.TrimSpace(string(rest)) == ""
+}
@@ -0,0 +1,133 @@
+package elasticsearch
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestCACertificate(t *testing.T) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "private-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %s", err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestGetInvalidTrustedCAs(t *testing.T) {
+	ca := newTestCACertificate(t)
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "trusted-ca", Namespace: "openshift-logging"},
+		Data: map[string]string{
+			"ldap.crt":   ca,
+			"bundle.crt": ca + "\n" + ca,
+			"broken.crt": "-----BEGIN CERTIFICATE-----\nbm90IGEgY2VydGlmaWNhdGU=\n-----END CERTIFICATE-----\n",
+			"notes.txt":  ca + "trailing text",
+		},
+	}
+	s := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "trusted-ca", Namespace: "openshift-logging"},
+		Data:       map[string][]byte{"s3.crt": []byte(ca)},
+	}
+	k8sClient := fake.NewFakeClient(cm, s)
+
+	tests := []struct {
+		desc      string
+		trustedCA *api.ElasticsearchTrustedCASpec
+		invalid   []string
+	}{
+		{desc: "no trusted CA"},
+		{desc: "valid secret", trustedCA: &api.ElasticsearchTrustedCASpec{SecretName: "trusted-ca"}},
+		{desc: "invalid keys", trustedCA: &api.ElasticsearchTrustedCASpec{ConfigMapName: "trusted-ca"}, invalid: []string{"broken.crt", "notes.txt"}},
+		{desc: "missing config map", trustedCA: &api.ElasticsearchTrustedCASpec{ConfigMapName: "missing"}, invalid: []string{"config map missing"}},
+		{desc: "both sources", trustedCA: &api.ElasticsearchTrustedCASpec{ConfigMapName: "trusted-ca", SecretName: "trusted-ca"}, invalid: []string{"exactly one"}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			invalid := getInvalidTrustedCAs(k8sClient, "openshift-logging", test.trustedCA)
+			if len(invalid) != len(test.invalid) {
+				t.Fatalf("Exp. %v to be invalid but got %v", test.invalid, invalid)
+			}
+			for i, prefix := range test.invalid {
+				if !strings.HasPrefix(invalid[i], prefix) {
+					t.Errorf("Exp. %s to be invalid but got %s", prefix, invalid[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPodTrustedCA(t *testing.T) {
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "trusted-ca", Namespace: "test-namespace-name"},
+		Data:       map[string]string{"ldap.crt": newTestCACertificate(t)},
+	}
+	k8sClient := fake.NewFakeClient(cm)
+	commonSpec := api.ElasticsearchNodeSpec{
+		TrustedCA: &api.ElasticsearchTrustedCASpec{ConfigMapName: "trusted-ca"},
+	}
+
+	newPodSpec := func() v1.PodSpec {
+		return newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, commonSpec, map[string]string{}, NodeRoles{}, k8sClient, LogConfig{}).Spec
+	}
+	trustedCAHash := func(podSpec v1.PodSpec) string {
+		for _, envVar := range podSpec.Containers[0].Env {
+			if envVar.Name == "TRUSTED_CA_HASH" {
+				return envVar.Value
+			}
+		}
+		return ""
+	}
+
+	podSpec := newPodSpec()
+	if len(podSpec.InitContainers) != 1 || podSpec.InitContainers[0].Name != importTrustedCAContainerName {
+		t.Fatalf("Exp. the init container importing the trusted CA but got %v", podSpec.InitContainers)
+	}
+	if volume, ok := getVolume(trustedCAVolumeName, podSpec.Volumes); !ok || volume.ConfigMap == nil || volume.ConfigMap.Name != "trusted-ca" {
+		t.Errorf("Exp. the pod to mount the trusted CA config map but got %v", podSpec.Volumes)
+	}
+	if _, ok := getVolume(truststoreVolumeName, podSpec.Volumes); !ok {
+		t.Errorf("Exp. the pod to have the truststore volume but got %v", podSpec.Volumes)
+	}
+
+	hash := trustedCAHash(podSpec)
+	if hash == "" {
+		t.Fatalf("Exp. the elasticsearch container to have the hash of the trusted CA but got %v", podSpec.Containers[0].Env)
+	}
+
+	cm.Data["ldap.crt"] = newTestCACertificate(t)
+	if err := k8sClient.Update(context.TODO(), cm); err != nil {
+		t.Fatalf("failed to update config map: %s", err)
+	}
+	if trustedCAHash(newPodSpec()) == hash {
+		t.Errorf("Exp. the hash to change with the trusted CA to roll the nodes")
+	}
+}
@@ -0,0 +1,86 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/pod"
+	v1 "k8s.io/api/core/v1"
+)
+
+// DefaultUnschedulableTimeout is the time a pod of a node may fail to be scheduled before
+// the cluster reports it with the PodsUnschedulable condition
+const DefaultUnschedulableTimeout = 5 * time.Minute
+
+var unschedulableTimeout = DefaultUnschedulableTimeout
+
+// SetUnschedulableTimeout sets the time a pod of a node may fail to be scheduled, e.g. while
+// the cluster autoscaler adds nodes, before the cluster reports it
+func SetUnschedulableTimeout(timeout time.Duration) {
+	unschedulableTimeout = timeout
+}
+
+// getUnschedulablePods returns the scheduling failures of the pending pods the scheduler
+// failed to place for longer than the timeout
+func getUnschedulablePods(pods []v1.Pod, now time.Time, timeout time.Duration) []string {
+	failures := []string{}
+	for _, p := range pods {
+		if p.Status.Phase != v1.PodPending {
+			continue
+		}
+
+		for _, condition := range p.Status.Conditions {
+			if condition.Type != v1.PodScheduled || condition.Status != v1.ConditionFalse || condition.Reason != v1.PodReasonUnschedulable {
+				continue
+			}
+			if now.Sub(condition.LastTransitionTime.Time) < timeout {
+				continue
+			}
+			failures = append(failures, fmt.Sprintf("%s: %s", p.Name, condition.Message))
+		}
+	}
+
+	sort.Strings(failures)
+	return failures
+}
+
+// updatePodsUnschedulable sets the PodsUnschedulable condition listing the scheduling
+// failures of the pods of the cluster pending for longer than the timeout, and emits a
+// warning event whenever they change, or clears the condition if none is
+func (er *ElasticsearchRequest) updatePodsUnschedulable() {
+	cluster := er.cluster
+
+	selector := map[string]string{
+		"component":    "elasticsearch",
+		"cluster-name": cluster.Name,
+	}
+	pods, err := pod.List(context.TODO(), er.client, cluster.Namespace, selector)
+	if err != nil {
+		er.L().Error(err, "Unable to list pods to check their scheduling")
+		return
+	}
+
+	failures := getUnschedulablePods(pods, time.Now(), unschedulableTimeout)
+	if len(failures) == 0 {
+		if err := updateCondition(cluster, api.PodsUnschedulable, v1.PodReasonUnschedulable, v1.ConditionFalse, "", er.client); err != nil {
+			er.L().Error(err, "Unable to clear pods unschedulable condition")
+		}
+		return
+	}
+
+	message := fmt.Sprintf("Pods failed to be scheduled for more than %s: %s", unschedulableTimeout, strings.Join(failures, "; "))
+	if _, condition := getESNodeCondition(cluster.Status.Conditions, api.PodsUnschedulable); condition != nil &&
+		condition.Status == v1.ConditionTrue && condition.Message == message {
+		return
+	}
+
+	er.L().Info(message)
+	recordEvent(er.recorder, cluster, v1.EventTypeWarning, eventReasonPodsUnschedulable, message)
+	if err := updateCondition(cluster, api.PodsUnschedulable, v1.PodReasonUnschedulable, v1.ConditionTrue, message, er.client); err != nil {
+		er.L().Error(err, "Unable to set pods unschedulable condition")
+	}
+}
@@ -0,0 +1,119 @@
+package elasticsearch
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newUnschedulablePod(name string, since time.Time) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "openshift-logging",
+			Labels: map[string]string{
+				"component":    "elasticsearch",
+				"cluster-name": "elasticsearch",
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			Conditions: []corev1.PodCondition{
+				{
+					Type:               corev1.PodScheduled,
+					Status:             corev1.ConditionFalse,
+					Reason:             corev1.PodReasonUnschedulable,
+					Message:            "0/3 nodes are available: 3 Insufficient memory.",
+					LastTransitionTime: metav1.NewTime(since),
+				},
+			},
+		},
+	}
+}
+
+func TestGetUnschedulablePods(t *testing.T) {
+	now := time.Now()
+	scheduled := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch-cdm-1"},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodScheduled, Status: corev1.ConditionTrue}},
+		},
+	}
+
+	pods := []corev1.Pod{
+		scheduled,
+		*newUnschedulablePod("elasticsearch-cdm-3", now.Add(-10*time.Minute)),
+		*newUnschedulablePod("elasticsearch-cdm-2", now.Add(-time.Minute)),
+	}
+
+	got := getUnschedulablePods(pods, now, 5*time.Minute)
+	want := []string{"elasticsearch-cdm-3: 0/3 nodes are available: 3 Insufficient memory."}
+	if len(got) != len(want) || got[0] != want[0] {
+		t.Errorf("Exp. the scheduling failures %v but got %v", want, got)
+	}
+}
+
+func TestUpdatePodsUnschedulable(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch",
+			Namespace: "openshift-logging",
+		},
+	}
+	unschedulable := newUnschedulablePod("elasticsearch-cdm-1", time.Now().Add(-2*DefaultUnschedulableTimeout))
+	k8sClient := fake.NewFakeClient(cluster, unschedulable)
+	recorder := record.NewFakeRecorder(2)
+
+	er := &ElasticsearchRequest{
+		cluster:  cluster,
+		client:   k8sClient,
+		recorder: recorder,
+	}
+
+	getCondition := func() *loggingv1.ClusterCondition {
+		current := &loggingv1.Elasticsearch{}
+		if err := k8sClient.Get(context.TODO(), client.ObjectKey{Name: cluster.Name, Namespace: cluster.Namespace}, current); err != nil {
+			t.Fatalf("failed to get cluster: %s", err)
+		}
+		_, condition := getESNodeCondition(current.Status.Conditions, loggingv1.PodsUnschedulable)
+		return condition
+	}
+
+	er.updatePodsUnschedulable()
+	condition := getCondition()
+	if condition == nil || condition.Status != corev1.ConditionTrue || !strings.Contains(condition.Message, "3 Insufficient memory") {
+		t.Errorf("Exp. the pods unschedulable condition to list the scheduling failure but got %v", condition)
+	}
+	event := <-recorder.Events
+	if !strings.HasPrefix(event, "Warning "+eventReasonPodsUnschedulable) {
+		t.Errorf("Exp. a warning event for the unschedulable pod but got %q", event)
+	}
+
+	// unchanged failures are not reported again
+	er.updatePodsUnschedulable()
+	select {
+	case event := <-recorder.Events:
+		t.Errorf("Exp. no further event but got %q", event)
+	default:
+	}
+
+	if err := k8sClient.Delete(context.TODO(), unschedulable); err != nil {
+		t.Fatalf("failed to delete pod: %s", err)
+	}
+	er.updatePodsUnschedulable()
+	if condition := getCondition(); condition != nil {
+		t.Errorf("Exp. the pods unschedulable condition to be cleared but got %v", condition)
+	}
+}
@@ -0,0 +1,23 @@
+package elasticsearch
+
+import (
+	"fmt"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+)
+
+// getInvalidUpdateStrategies returns the node groups using the RollingUpdate strategy. Data
+// nodes are replaced one at a time by the operator, and the other groups run as statefulsets,
+// whose pods are replaced in order without surge or unavailability bounds.
+func getInvalidUpdateStrategies(dpl *api.Elasticsearch) []string {
+	invalid := []string{}
+
+	for i, node := range dpl.Spec.Nodes {
+		strategy := node.UpdateStrategy
+		if strategy != nil && strategy.Type == api.ElasticsearchNodeUpdateRollingUpdate {
+			invalid = append(invalid, fmt.Sprintf("nodes[%d] (RollingUpdate)", i))
+		}
+	}
+
+	return invalid
+}
@@ -0,0 +1,29 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+)
+
+func TestGetInvalidUpdateStrategies(t *testing.T) {
+	client := []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleClient}
+	data := []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleClient, loggingv1.ElasticsearchRoleData}
+
+	dpl := &loggingv1.Elasticsearch{
+		Spec: loggingv1.ElasticsearchSpec{
+			Nodes: []loggingv1.ElasticsearchNode{
+				{Roles: data, NodeCount: 3},
+				{Roles: data, NodeCount: 3, UpdateStrategy: &loggingv1.ElasticsearchNodeUpdateStrategy{Type: loggingv1.ElasticsearchNodeUpdateRecreate}},
+				{Roles: data, NodeCount: 3, UpdateStrategy: &loggingv1.ElasticsearchNodeUpdateStrategy{Type: loggingv1.ElasticsearchNodeUpdateRollingUpdate}},
+				{Roles: client, NodeCount: 2, UpdateStrategy: &loggingv1.ElasticsearchNodeUpdateStrategy{Type: loggingv1.ElasticsearchNodeUpdateRollingUpdate}},
+			},
+		},
+	}
+
+	want := []string{"nodes[2] (RollingUpdate)", "nodes[3] (RollingUpdate)"}
+	if diff := cmp.Diff(want, getInvalidUpdateStrategies(dpl)); diff != "" {
+		t.Errorf("Unexpected invalid update strategies (-want +got):\n%s", diff)
+	}
+}
@@ -1,20 +1,32 @@
 package elasticsearch
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"strconv"
 	"strings"
 
 	"github.com/ViaQ/logerr/kverrors"
-	"github.com/ViaQ/logerr/log"
 	v1 "k8s.io/api/core/v1"
+	nodev1beta1 "k8s.io/api/node/v1beta1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
 )
 
 const (
 	loglevelAnnotation          = "elasticsearch.openshift.io/loglevel"
 	serverLogAppenderAnnotation = "elasticsearch.openshift.io/develLogAppender"
 	serverLoglevelAnnotation    = "elasticsearch.openshift.io/esloglevel"
+
+	podSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+	podSecurityRestricted   = "restricted"
 )
 
 type LogConfig struct {
@@ -53,6 +65,12 @@ func selectorForES(nodeRole string, clusterName string) map[string]string {
 	}
 }
 
+// propagatedAnnotations returns the annotations of the cluster propagated to the objects
+// the operator creates for it
+func propagatedAnnotations(dpl *api.Elasticsearch) map[string]string {
+	return utils.SelectPropagatedAnnotations(dpl.Annotations, dpl.Spec.PropagatedAnnotations)
+}
+
 func appendDefaultLabel(clusterName string, labels map[string]string) map[string]string {
 	if _, ok := labels["cluster-name"]; ok {
 		return labels
@@ -76,6 +94,57 @@ func mergeSelectors(nodeSelectors, commonSelectors map[string]string) map[string
 	return commonSelectors
 }
 
+func getPriorityClassName(node api.ElasticsearchNode, commonSpec api.ElasticsearchNodeSpec) string {
+	if node.PriorityClassName != "" {
+		return node.PriorityClassName
+	}
+	return commonSpec.PriorityClassName
+}
+
+// getNodeFailureTolerationSeconds returns the seconds the pods of the node tolerate a not ready
+// or unreachable node, or nil for the platform default
+func getNodeFailureTolerationSeconds(node api.ElasticsearchNode, commonSpec api.ElasticsearchNodeSpec) *int64 {
+	if node.NodeFailureTolerationSeconds != nil {
+		return node.NodeFailureTolerationSeconds
+	}
+	return commonSpec.NodeFailureTolerationSeconds
+}
+
+// newNodeFailureTolerations returns the tolerations of the not ready and unreachable node taints
+// for the given seconds, skipping those already tolerated
+func newNodeFailureTolerations(seconds *int64, tolerations []v1.Toleration) []v1.Toleration {
+	if seconds == nil {
+		return nil
+	}
+
+	failureTolerations := []v1.Toleration{}
+	for _, key := range []string{"node.kubernetes.io/not-ready", "node.kubernetes.io/unreachable"} {
+		if isTaintKeyTolerated(key, tolerations) {
+			continue
+		}
+
+		tolerationSeconds := *seconds
+		failureTolerations = append(failureTolerations, v1.Toleration{
+			Key:               key,
+			Operator:          v1.TolerationOpExists,
+			Effect:            v1.TaintEffectNoExecute,
+			TolerationSeconds: &tolerationSeconds,
+		})
+	}
+
+	return failureTolerations
+}
+
+func isTaintKeyTolerated(key string, tolerations []v1.Toleration) bool {
+	for _, toleration := range tolerations {
+		if toleration.Key == key && (toleration.Effect == v1.TaintEffectNoExecute || toleration.Effect == "") {
+			return true
+		}
+	}
+
+	return false
+}
+
 func appendTolerations(nodeTolerations, commonTolerations []v1.Toleration) []v1.Toleration {
 	if commonTolerations == nil {
 		commonTolerations = []v1.Toleration{}
@@ -84,10 +153,12 @@ func appendTolerations(nodeTolerations, commonTolerations []v1.Toleration) []v1.
 	return append(commonTolerations, nodeTolerations...)
 }
 
+// getMasterCount returns the number of master nodes, excluding node groups
+// decommissioned in favor of another one
 func getMasterCount(dpl *api.Elasticsearch) int32 {
 	masterCount := int32(0)
 	for _, node := range dpl.Spec.Nodes {
-		if isMasterNode(node) {
+		if getNodeRoles(node).IsMaster() && !isNodeGroupReplaced(dpl, node) {
 			masterCount += node.NodeCount
 		}
 	}
@@ -95,10 +166,12 @@ func getMasterCount(dpl *api.Elasticsearch) int32 {
 	return masterCount
 }
 
+// GetDataCount returns the number of data nodes, excluding node groups
+// decommissioned in favor of another one
 func GetDataCount(dpl *api.Elasticsearch) int32 {
 	dataCount := int32(0)
 	for _, node := range dpl.Spec.Nodes {
-		if isDataNode(node) {
+		if getNodeRoles(node).IsData() && !isNodeGroupReplaced(dpl, node) {
 			dataCount = dataCount + node.NodeCount
 		}
 	}
@@ -166,7 +239,7 @@ func (er *ElasticsearchRequest) isValidScaleDownRate() (bool, error) {
 		// check the lowest replica value in the cluster
 		foundLowestReplica, err := er.esClient.GetLowestReplicaValue()
 		if err != nil {
-			log.Error(err, "Unable to determine lowest replica value for cluster")
+			er.L().Error(err, "Unable to determine lowest replica value for cluster")
 			return false, kverrors.Wrap(err, "Unable to determine lowest replica value for cluster")
 		}
 
@@ -178,41 +251,21 @@ func (er *ElasticsearchRequest) isValidScaleDownRate() (bool, error) {
 	return (rate <= lowestReplica), nil
 }
 
+// isValidConf reports the specRules and the rules depending on the cluster or referenced
+// objects with their conditions and returns an error for the first violated one
 func (er *ElasticsearchRequest) isValidConf() error {
 	dpl := er.cluster
 
-	if !isValidMasterCount(dpl) {
-		if err := updateConditionWithRetry(dpl, v1.ConditionTrue, updateInvalidMasterCountCondition, er.client); err != nil {
-			return err
-		}
-		return kverrors.New("invalid master nodes count. Please ensure the total nodes with master roles is less than the maximum",
-			"maximum", maxMasterCount)
-	} else {
-		if err := updateConditionWithRetry(dpl, v1.ConditionFalse, updateInvalidMasterCountCondition, er.client); err != nil {
-			return kverrors.Wrap(err, "failed to set master count status")
-		}
-	}
-
-	if !isValidDataCount(dpl) {
-		if err := updateConditionWithRetry(dpl, v1.ConditionTrue, updateInvalidDataCountCondition, er.client); err != nil {
-			return kverrors.Wrap(err, "failed to set data count status")
-		}
-		return kverrors.New("no data nodes requested. Please ensure there is at least 1 node with data roles")
-	} else {
-		if err := updateConditionWithRetry(dpl, v1.ConditionFalse, updateInvalidDataCountCondition, er.client); err != nil {
-			return kverrors.Wrap(err, "failed to set data count status")
-		}
-	}
-
-	if !isValidRedundancyPolicy(dpl) {
-		if err := updateConditionWithRetry(dpl, v1.ConditionTrue, updateInvalidReplicationCondition, er.client); err != nil {
-			return kverrors.Wrap(err, "failed to set replication status")
+	for _, rule := range specRules {
+		if message := rule.violation(dpl); message != "" {
+			if err := rule.updateCondition(dpl, v1.ConditionTrue, message, er.client); err != nil {
+				return kverrors.Wrap(err, fmt.Sprintf("failed to set %s status", rule.subject))
+			}
+			return kverrors.New(fmt.Sprintf("invalid %s", rule.subject),
+				"message", message)
 		}
-		return kverrors.New("wrong RedundancyPolicy selected. Choose different RedundancyPolicy or add more nodes with data roles",
-			"policy", dpl.Spec.RedundancyPolicy)
-	} else {
-		if err := updateConditionWithRetry(dpl, v1.ConditionFalse, updateInvalidReplicationCondition, er.client); err != nil {
-			return kverrors.Wrap(err, "failed to set replication status")
+		if err := rule.updateCondition(dpl, v1.ConditionFalse, "", er.client); err != nil {
+			return kverrors.Wrap(err, fmt.Sprintf("failed to set %s status", rule.subject))
 		}
 	}
 
@@ -232,21 +285,225 @@ func (er *ElasticsearchRequest) isValidConf() error {
 		return kverrors.New("Data node scale down rate is too high based on minimum number of replicas for all indices")
 	}
 
+	// invalid routing shards are left out of the index templates, thus only reported
+	if !isValidRoutingShards(dpl) {
+		if err := updateCondition(dpl, api.InvalidRoutingShards, invalidSpecReason, v1.ConditionTrue, invalidRoutingShardsMessage(dpl), er.client); err != nil {
+			return kverrors.Wrap(err, "failed to set routing shards status")
+		}
+	} else {
+		if err := updateCondition(dpl, api.InvalidRoutingShards, invalidSpecReason, v1.ConditionFalse, "", er.client); err != nil {
+			return kverrors.Wrap(err, "failed to set routing shards status")
+		}
+	}
+
+	if invalid := getInvalidTrustedCAs(er.client, dpl.Namespace, dpl.Spec.Spec.TrustedCA); len(invalid) > 0 {
+		message := fmt.Sprintf("Invalid trusted CA: %s. Please ensure the referenced config map or secret holds PEM encoded certificates only", strings.Join(invalid, ", "))
+		if err := updateCondition(dpl, api.InvalidTrustedCA, invalidSpecReason, v1.ConditionTrue, message, er.client); err != nil {
+			return kverrors.Wrap(err, "failed to set trusted CA status")
+		}
+		return kverrors.New("invalid trusted CA",
+			"invalid", invalid)
+	} else {
+		if err := updateCondition(dpl, api.InvalidTrustedCA, invalidSpecReason, v1.ConditionFalse, "", er.client); err != nil {
+			return kverrors.Wrap(err, "failed to set trusted CA status")
+		}
+	}
+
 	// TODO: replace this with a validating web hook to ensure field is immutable
 	if err := validateUUIDs(dpl); err != nil {
-		if err := updateInvalidUUIDChangeCondition(dpl, v1.ConditionTrue, err.Error(), er.client); err != nil {
+		if err := updateCondition(dpl, api.InvalidUUID, invalidSpecReason, v1.ConditionTrue, err.Error(), er.client); err != nil {
 			return kverrors.Wrap(err, "failed to set UUID change status")
 		}
 		return kverrors.Wrap(err, "unsupported change to UUIDs made")
 	} else {
-		if err := updateInvalidUUIDChangeCondition(dpl, v1.ConditionFalse, "", er.client); err != nil {
+		if err := updateCondition(dpl, api.InvalidUUID, invalidSpecReason, v1.ConditionFalse, "", er.client); err != nil {
 			return kverrors.Wrap(err, "failed to set UUID change status")
 		}
 	}
 
+	er.warnOnRestrictedPodSecurity()
+	er.warnOnMissingPriorityClasses()
+	er.warnOnMissingRuntimeClass()
+
 	return nil
 }
 
+// warnOnMissingPriorityClasses logs a warning for each requested priority class that does
+// not exist, since pods referencing it are rejected until it is created
+func (er *ElasticsearchRequest) warnOnMissingPriorityClasses() {
+	dpl := er.cluster
+
+	names := sets.NewString()
+	for _, node := range dpl.Spec.Nodes {
+		if name := getPriorityClassName(node, dpl.Spec.Spec); name != "" {
+			names.Insert(name)
+		}
+	}
+
+	for _, name := range names.List() {
+		pc := &schedulingv1.PriorityClass{}
+		err := er.client.Get(context.TODO(), types.NamespacedName{Name: name}, pc)
+		if err == nil {
+			continue
+		}
+
+		if apierrors.IsNotFound(err) {
+			er.L().Info("Warning: requested priority class does not exist, pods of the cluster will not be admitted until it is created",
+				"cluster", dpl.Name,
+				"namespace", dpl.Namespace,
+				"priorityClassName", name)
+			continue
+		}
+
+		er.L().Error(err, "Unable to get priority class", "priorityClassName", name)
+	}
+}
+
+// warnOnMissingRuntimeClass logs a warning if the requested runtime class does not exist,
+// since pods referencing it are rejected until it is created
+func (er *ElasticsearchRequest) warnOnMissingRuntimeClass() {
+	dpl := er.cluster
+
+	name := dpl.Spec.Spec.RuntimeClassName
+	if name == nil || *name == "" {
+		return
+	}
+
+	rc := &nodev1beta1.RuntimeClass{}
+	err := er.client.Get(context.TODO(), types.NamespacedName{Name: *name}, rc)
+	if err == nil {
+		return
+	}
+
+	if apierrors.IsNotFound(err) {
+		er.L().Info("Warning: requested runtime class does not exist, pods of the cluster will not be admitted until it is created",
+			"cluster", dpl.Name,
+			"namespace", dpl.Namespace,
+			"runtimeClassName", *name)
+		return
+	}
+
+	er.L().Error(err, "Unable to get runtime class", "runtimeClassName", *name)
+}
+
+// warnOnRestrictedPodSecurity logs a warning and emits a warning event if the namespace
+// enforces the restricted pod security standard and the requested security context would
+// be rejected by it. The namespace is read uncached since the operator does not watch
+// the namespaces.
+func (er *ElasticsearchRequest) warnOnRestrictedPodSecurity() {
+	dpl := er.cluster
+	if dpl.Spec.Spec.SecurityContext == nil {
+		return
+	}
+
+	violations := getRestrictedPodSecurityViolations(newPodSecurityContext(dpl.Spec.Spec.SecurityContext, nil))
+	if len(violations) == 0 {
+		return
+	}
+
+	ns := &v1.Namespace{}
+	if err := er.apiReader.Get(context.TODO(), types.NamespacedName{Name: dpl.Namespace}, ns); err != nil {
+		er.L().Error(err, "Unable to get namespace to check pod security standard", "namespace", dpl.Namespace)
+		return
+	}
+
+	if ns.Labels[podSecurityEnforceLabel] == podSecurityRestricted {
+		er.L().Info("Warning: requested security context will be rejected by the restricted pod security standard",
+			"cluster", dpl.Name,
+			"namespace", dpl.Namespace,
+			"violations", violations)
+		message := fmt.Sprintf("The pods of the cluster will be rejected by the restricted pod security standard of the namespace: %s. Please set runAsNonRoot and a non-root runAsUser in the security context", strings.Join(violations, ", "))
+		recordEvent(er.recorder, dpl, v1.EventTypeWarning, eventReasonRestrictedPodSecurity, message)
+	}
+}
+
+// getRestrictedPodSecurityViolations returns the fields of the pod security context the
+// restricted pod security standard rejects
+func getRestrictedPodSecurityViolations(sc *v1.PodSecurityContext) []string {
+	violations := []string{}
+	if sc != nil && sc.RunAsUser != nil && *sc.RunAsUser == 0 {
+		violations = append(violations, "runAsUser is 0")
+	}
+	if sc == nil || sc.RunAsNonRoot == nil || !*sc.RunAsNonRoot {
+		violations = append(violations, "runAsNonRoot is not true")
+	}
+	return violations
+}
+
+// getReservedJvmOptions returns the JVM options of all node groups that override
+// flags computed by the operator without being explicitly allowed to
+func getReservedJvmOptions(dpl *api.Elasticsearch) []string {
+	reserved := []string{}
+	for _, node := range dpl.Spec.Nodes {
+		if !hasJvmOptions(node) || node.JvmOptions.AllowReservedOptions {
+			continue
+		}
+
+		for _, option := range node.JvmOptions.Options {
+			for _, prefix := range reservedJvmOptions {
+				if strings.HasPrefix(strings.TrimSpace(option), prefix) {
+					reserved = append(reserved, option)
+					break
+				}
+			}
+		}
+	}
+
+	return reserved
+}
+
+// getInvalidDiscoverySeedHosts returns the additional seed hosts that are not
+// a DNS name with an optional port
+func getInvalidDiscoverySeedHosts(dpl *api.Elasticsearch) []string {
+	invalid := []string{}
+	if dpl.Spec.Discovery == nil {
+		return invalid
+	}
+
+	for _, seedHost := range dpl.Spec.Discovery.SeedHosts {
+		host := seedHost
+		if h, port, err := net.SplitHostPort(seedHost); err == nil {
+			if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+				invalid = append(invalid, seedHost)
+				continue
+			}
+			host = h
+		}
+
+		if errs := validation.IsDNS1123Subdomain(host); len(errs) > 0 {
+			invalid = append(invalid, seedHost)
+		}
+	}
+
+	return invalid
+}
+
+// getDuplicateNodeNames returns the generated node names that more than one node group
+// would resolve to. Node groups without a GenUUID are skipped since they are assigned a
+// new unique one before any nodes are created.
+func getDuplicateNodeNames(dpl *api.Elasticsearch) []string {
+	seen := map[string]bool{}
+	duplicates := []string{}
+
+	for _, node := range dpl.Spec.Nodes {
+		if node.GenUUID == nil {
+			continue
+		}
+
+		for _, nodeName := range getGeneratedNodeNames(dpl.Name, *node.GenUUID, node) {
+			if seen[nodeName] {
+				if !sliceContainsString(duplicates, nodeName) {
+					duplicates = append(duplicates, nodeName)
+				}
+				continue
+			}
+			seen[nodeName] = true
+		}
+	}
+
+	return duplicates
+}
+
 func validateUUIDs(dpl *api.Elasticsearch) error {
 	// TODO:
 	// check that someone didn't update a uuid
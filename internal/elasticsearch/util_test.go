@@ -1,8 +1,10 @@
 package elasticsearch
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -11,6 +13,7 @@ import (
 	"github.com/openshift/elasticsearch-operator/test/helpers"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -924,3 +927,212 @@ func TestFullRedundancyScaleDownInvalid(t *testing.T) {
 		t.Errorf("Expected to be invalid scale down case")
 	}
 }
+
+func TestDuplicateNodeNamesCollidingGroups(t *testing.T) {
+	uuid := "abcd1234"
+
+	esCR := &api.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "elasticsearch",
+		},
+		Spec: api.ElasticsearchSpec{
+			Nodes: []api.ElasticsearchNode{
+				{
+					Roles:     []api.ElasticsearchNodeRole{"master", "data"},
+					NodeCount: int32(2),
+					GenUUID:   &uuid,
+				},
+				{
+					Roles:     []api.ElasticsearchNodeRole{"data", "master"},
+					NodeCount: int32(1),
+					GenUUID:   &uuid,
+				},
+			},
+		},
+	}
+
+	expected := []string{"elasticsearch-dm-abcd1234-1"}
+	actual := getDuplicateNodeNames(esCR)
+
+	if len(actual) != len(expected) || actual[0] != expected[0] {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestDuplicateNodeNamesUniqueGroups(t *testing.T) {
+	uuid := "abcd1234"
+	otherUUID := "efgh5678"
+
+	esCR := &api.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "elasticsearch",
+		},
+		Spec: api.ElasticsearchSpec{
+			Nodes: []api.ElasticsearchNode{
+				{
+					Roles:     []api.ElasticsearchNodeRole{"master", "data"},
+					NodeCount: int32(2),
+					GenUUID:   &uuid,
+				},
+				{
+					Roles:     []api.ElasticsearchNodeRole{"master"},
+					NodeCount: int32(1),
+					GenUUID:   &uuid,
+				},
+				{
+					Roles:     []api.ElasticsearchNodeRole{"data"},
+					NodeCount: int32(1),
+					GenUUID:   &otherUUID,
+				},
+				{
+					Roles:     []api.ElasticsearchNodeRole{"data"},
+					NodeCount: int32(1),
+				},
+			},
+		},
+	}
+
+	if actual := getDuplicateNodeNames(esCR); len(actual) != 0 {
+		t.Errorf("Expected no duplicate node names but got %v", actual)
+	}
+}
+
+func TestDiscoverySeedHosts(t *testing.T) {
+	esCR := &api.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch",
+			Namespace: "openshift-logging",
+		},
+		Spec: api.ElasticsearchSpec{},
+	}
+
+	if got, want := esDiscoverySeedHosts(esCR), "elasticsearch-cluster.openshift-logging.svc"; got != want {
+		t.Errorf("Expected seed hosts %q without discovery spec, got %q", want, got)
+	}
+
+	esCR.Spec.Discovery = &api.ElasticsearchDiscoverySpec{
+		Provider:  api.DiscoveryProviderHeadlessService,
+		SeedHosts: []string{"es-remote.example.com:9300"},
+	}
+
+	want := "elasticsearch-discovery.openshift-logging.svc,es-remote.example.com:9300"
+	if got := esDiscoverySeedHosts(esCR); got != want {
+		t.Errorf("Expected seed hosts %q for headless discovery, got %q", want, got)
+	}
+}
+
+func TestInvalidDiscoverySeedHosts(t *testing.T) {
+	esCR := &api.Elasticsearch{
+		Spec: api.ElasticsearchSpec{
+			Discovery: &api.ElasticsearchDiscoverySpec{
+				SeedHosts: []string{
+					"es-remote.example.com",
+					"es-remote.example.com:9300",
+					"Not_A_Host",
+					"es-remote.example.com:port",
+				},
+			},
+		},
+	}
+
+	got := getInvalidDiscoverySeedHosts(esCR)
+	want := []string{"Not_A_Host", "es-remote.example.com:port"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected invalid seed hosts (-want +got):\n%s", diff)
+	}
+}
+
+func TestReservedJvmOptions(t *testing.T) {
+	esCR := &api.Elasticsearch{
+		Spec: api.ElasticsearchSpec{
+			Nodes: []api.ElasticsearchNode{
+				{
+					JvmOptions: &api.ElasticsearchJvmOptionsSpec{
+						Options: []string{"-XX:+UseG1GC", "-Xmx4g"},
+					},
+				},
+				{
+					JvmOptions: &api.ElasticsearchJvmOptionsSpec{
+						Options:              []string{"-Xms4g"},
+						AllowReservedOptions: true,
+					},
+				},
+			},
+		},
+	}
+
+	got := getReservedJvmOptions(esCR)
+	want := []string{"-Xmx4g"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected reserved jvm options (-want +got):\n%s", diff)
+	}
+}
+
+func TestWarnOnRestrictedPodSecurity(t *testing.T) {
+	root := int64(0)
+	user := int64(1000)
+	nonRoot := true
+
+	tests := []struct {
+		desc     string
+		sc       *api.ElasticsearchSecurityContext
+		wantWarn bool
+	}{
+		{
+			desc: "no security context requested",
+		},
+		{
+			desc:     "root user",
+			sc:       &api.ElasticsearchSecurityContext{RunAsUser: &root, RunAsNonRoot: &nonRoot},
+			wantWarn: true,
+		},
+		{
+			desc:     "non-root not required",
+			sc:       &api.ElasticsearchSecurityContext{RunAsUser: &user},
+			wantWarn: true,
+		},
+		{
+			desc: "non-root user required",
+			sc:   &api.ElasticsearchSecurityContext{RunAsUser: &user, RunAsNonRoot: &nonRoot},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			ns := &v1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "openshift-logging",
+					Labels: map[string]string{podSecurityEnforceLabel: podSecurityRestricted},
+				},
+			}
+			cluster := &api.Elasticsearch{
+				ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch", Namespace: ns.Name},
+				Spec: api.ElasticsearchSpec{
+					Spec: api.ElasticsearchNodeSpec{SecurityContext: test.sc},
+				},
+			}
+			recorder := record.NewFakeRecorder(1)
+			er := &ElasticsearchRequest{
+				apiReader: fake.NewFakeClient(ns),
+				cluster:   cluster,
+				recorder:  recorder,
+			}
+
+			er.warnOnRestrictedPodSecurity()
+
+			if !test.wantWarn {
+				if len(recorder.Events) != 0 {
+					t.Errorf("Exp. no warning event but got %q", <-recorder.Events)
+				}
+				return
+			}
+			if len(recorder.Events) != 1 {
+				t.Fatalf("Exp. a warning event but got %d events", len(recorder.Events))
+			}
+			if event := <-recorder.Events; !strings.HasPrefix(event, "Warning "+eventReasonRestrictedPodSecurity) {
+				t.Errorf("Exp. a restricted pod security warning but got %q", event)
+			}
+		})
+	}
+}
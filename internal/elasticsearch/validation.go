@@ -0,0 +1,364 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"strings"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SpecValidation is the outcome of validating the spec of a cluster without the API server
+// and the cluster. Errors block the reconcile of the cluster, warnings are only reported.
+type SpecValidation struct {
+	Errors   []string
+	Warnings []string
+}
+
+// IsValid returns true if the spec has no errors
+func (v SpecValidation) IsValid() bool {
+	return len(v.Errors) == 0
+}
+
+// ValidateSpec applies the rules of the spec the reconcile enforces which need neither the
+// API server nor the cluster, e.g. to lint a CR offline. The rules depending on referenced
+// objects or the indices of the cluster, like the trusted CA or the scale down rate, are
+// only checked by the reconcile.
+func ValidateSpec(dpl *api.Elasticsearch) SpecValidation {
+	v := SpecValidation{}
+	for _, rule := range specRules {
+		if message := rule.violation(dpl); message != "" {
+			v.Errors = append(v.Errors, message)
+		}
+	}
+	// checked by the reconcile when rendering the config maps
+	if hosts := getInvalidDiscoverySeedHosts(dpl); len(hosts) > 0 {
+		v.Errors = append(v.Errors, invalidDiscoverySeedHostsMessage(hosts))
+	}
+
+	v.Warnings = getSpecWarnings(dpl)
+	return v
+}
+
+// specRule is a rule of the spec which needs neither the API server nor the cluster. The
+// reconcile reports it with its condition, ValidateSpec with its message.
+type specRule struct {
+	// the subject in the errors of the reconcile, e.g. "data tiers"
+	subject string
+	// violation returns the message of the violated rule, empty if the spec follows it
+	violation       func(dpl *api.Elasticsearch) string
+	updateCondition func(cluster *api.Elasticsearch, value v1.ConditionStatus, message string, client client.Client) error
+}
+
+// specRules are the rules shared by the reconcile and ValidateSpec in the order they are checked
+var specRules = []specRule{
+	{
+		subject: "master nodes count",
+		violation: func(dpl *api.Elasticsearch) string {
+			if isValidMasterCount(dpl) {
+				return ""
+			}
+			return invalidMasterCountMessage
+		},
+		updateCondition: withStatusUpdate(updateInvalidMasterCountCondition),
+	},
+	{
+		subject: "data nodes count",
+		violation: func(dpl *api.Elasticsearch) string {
+			if isValidDataCount(dpl) {
+				return ""
+			}
+			return invalidDataCountMessage
+		},
+		updateCondition: withStatusUpdate(updateInvalidDataCountCondition),
+	},
+	{
+		subject: "redundancy policy",
+		violation: func(dpl *api.Elasticsearch) string {
+			if isValidRedundancyPolicy(dpl) {
+				return ""
+			}
+			return invalidRedundancyPolicyMessage(dpl)
+		},
+		updateCondition: withStatusUpdate(updateInvalidReplicationCondition),
+	},
+	{
+		subject: "node names",
+		violation: func(dpl *api.Elasticsearch) string {
+			if duplicates := getDuplicateNodeNames(dpl); len(duplicates) > 0 {
+				return invalidNodeNamesMessage(duplicates)
+			}
+			return ""
+		},
+		updateCondition: invalidSpecCondition(api.InvalidNodeNames),
+	},
+	{
+		subject: "jvm options",
+		violation: func(dpl *api.Elasticsearch) string {
+			if reserved := getReservedJvmOptions(dpl); len(reserved) > 0 {
+				return reservedJvmOptionsMessage(reserved)
+			}
+			return ""
+		},
+		updateCondition: invalidSpecCondition(api.InvalidJvmOptions),
+	},
+	{
+		subject: "recover after time",
+		violation: func(dpl *api.Elasticsearch) string {
+			if value := recoverAfterTime(dpl); !isValidTimeValue(value) {
+				return invalidRecoverAfterTimeMessage(value)
+			}
+			return ""
+		},
+		updateCondition: invalidSpecCondition(api.InvalidRecoverAfterTime),
+	},
+	{
+		subject: "cluster name",
+		violation: func(dpl *api.Elasticsearch) string {
+			if name := getESClusterName(dpl); !isValidESClusterName(name) {
+				return invalidClusterNameMessage(name)
+			}
+			return ""
+		},
+		updateCondition: invalidSpecCondition(api.InvalidClusterName),
+	},
+	{
+		subject: "data tiers",
+		violation: func(dpl *api.Elasticsearch) string {
+			if invalid := getInvalidDataTiers(dpl); len(invalid) > 0 {
+				return invalidDataTiersMessage(invalid)
+			}
+			return ""
+		},
+		updateCondition: invalidSpecCondition(api.InvalidDataTiers),
+	},
+	{
+		subject: "plugins",
+		violation: func(dpl *api.Elasticsearch) string {
+			if invalid := getInvalidPlugins(dpl.Spec.Spec); len(invalid) > 0 {
+				return invalidPluginsMessage(invalid)
+			}
+			return ""
+		},
+		updateCondition: invalidSpecCondition(api.InvalidPlugins),
+	},
+	{
+		subject: "storage",
+		violation: func(dpl *api.Elasticsearch) string {
+			if invalid := getInvalidStorage(dpl); len(invalid) > 0 {
+				return invalidStorageMessage(invalid)
+			}
+			return ""
+		},
+		updateCondition: invalidSpecCondition(api.InvalidStorage),
+	},
+	{
+		subject: "maintenance window",
+		violation: func(dpl *api.Elasticsearch) string {
+			if invalid := getInvalidMaintenanceWindow(dpl); len(invalid) > 0 {
+				return invalidMaintenanceWindowMessage(invalid)
+			}
+			return ""
+		},
+		updateCondition: invalidSpecCondition(api.InvalidMaintenanceWindow),
+	},
+	{
+		subject: "sysctls",
+		violation: func(dpl *api.Elasticsearch) string {
+			if invalid := getInvalidSysctls(dpl.Spec.Spec); len(invalid) > 0 {
+				return invalidSysctlsMessage(invalid)
+			}
+			return ""
+		},
+		updateCondition: invalidSpecCondition(api.InvalidSysctls),
+	},
+	{
+		subject: "service ports",
+		violation: func(dpl *api.Elasticsearch) string {
+			if invalid := getInvalidServicePorts(dpl); len(invalid) > 0 {
+				return invalidServicePortsMessage(invalid)
+			}
+			return ""
+		},
+		updateCondition: invalidSpecCondition(api.InvalidServicePorts),
+	},
+	{
+		subject: "autoscaling",
+		violation: func(dpl *api.Elasticsearch) string {
+			if invalid := getInvalidAutoscaling(dpl); len(invalid) > 0 {
+				return invalidAutoscalingMessage(invalid)
+			}
+			return ""
+		},
+		updateCondition: invalidSpecCondition(api.InvalidAutoscaling),
+	},
+	{
+		subject: "auto create index patterns",
+		violation: func(dpl *api.Elasticsearch) string {
+			if invalid := getInvalidAutoCreateIndexPatterns(dpl); len(invalid) > 0 {
+				return invalidAutoCreateIndexMessage(invalid)
+			}
+			return ""
+		},
+		updateCondition: invalidSpecCondition(api.InvalidAutoCreateIndex),
+	},
+	{
+		subject: "update strategies",
+		violation: func(dpl *api.Elasticsearch) string {
+			if invalid := getInvalidUpdateStrategies(dpl); len(invalid) > 0 {
+				return invalidUpdateStrategiesMessage(invalid)
+			}
+			return ""
+		},
+		updateCondition: invalidSpecCondition(api.InvalidUpdateStrategy),
+	},
+	{
+		subject: "log4j2 properties",
+		violation: func(dpl *api.Elasticsearch) string {
+			if properties := dpl.Spec.Log4j2Properties; properties != nil && !isValidLog4j2Properties(*properties) {
+				return invalidLog4j2PropertiesMessage
+			}
+			return ""
+		},
+		updateCondition: invalidSpecCondition(api.InvalidLog4j2Properties),
+	},
+}
+
+// invalidSpecCondition adapts the update of the invalid spec condition of the given type to
+// the rules
+func invalidSpecCondition(conditionType api.ClusterConditionType) func(*api.Elasticsearch, v1.ConditionStatus, string, client.Client) error {
+	return func(cluster *api.Elasticsearch, value v1.ConditionStatus, message string, client client.Client) error {
+		return updateCondition(cluster, conditionType, invalidSpecReason, value, message, client)
+	}
+}
+
+// withStatusUpdate adapts the updates of the conditions with a fixed message to the rules
+func withStatusUpdate(update func(status *api.ElasticsearchStatus, value v1.ConditionStatus) bool) func(*api.Elasticsearch, v1.ConditionStatus, string, client.Client) error {
+	return func(cluster *api.Elasticsearch, value v1.ConditionStatus, _ string, client client.Client) error {
+		return updateConditionWithRetry(cluster, value, update, client)
+	}
+}
+
+// getSpecWarnings returns the findings of the spec which do not block the reconcile but
+// likely are not intended
+func getSpecWarnings(dpl *api.Elasticsearch) []string {
+	warnings := []string{}
+
+	if masters := getMasterCount(dpl); masters > 0 && masters%2 == 0 {
+		warnings = append(warnings, fmt.Sprintf("Even master nodes count: %d. The cluster tolerates no more lost master nodes than with %d", masters, masters-1))
+	}
+
+	for i, node := range dpl.Spec.Nodes {
+		resources := newResourceRequirements(node.Resources, dpl.Spec.Spec.Resources, v1.ResourceRequirements{})
+		missing := []string{}
+		for _, name := range []v1.ResourceName{v1.ResourceMemory, v1.ResourceCPU} {
+			if request, ok := resources.Requests[name]; !ok || request.IsZero() {
+				missing = append(missing, string(name))
+			}
+		}
+		if len(missing) > 0 {
+			warnings = append(warnings, fmt.Sprintf("nodes[%d] requests no %s. The operator defaults apply", i, strings.Join(missing, " and ")))
+		}
+	}
+
+	warnings = append(warnings, getServiceReadinessWarnings(dpl)...)
+
+	if !isValidRoutingShards(dpl) {
+		warnings = append(warnings, invalidRoutingShardsMessage(dpl))
+	}
+
+	if dpl.Spec.Spec.Image != "" {
+		warnings = append(warnings, fmt.Sprintf("Custom image %s is ignored. The operator deploys its own image", dpl.Spec.Spec.Image))
+	}
+	if image := getESImage(); getImageMajorVersion(image) == 0 {
+		warnings = append(warnings, fmt.Sprintf("Image %s tells no Elasticsearch major version. The rendered configuration is not checked against it", image))
+	}
+
+	return warnings
+}
+
+// getServiceReadinessWarnings returns the overrides of the service membership of not ready
+// nodes which hinder the rollouts
+func getServiceReadinessWarnings(dpl *api.Elasticsearch) []string {
+	warnings := []string{}
+	if !discoveryPublishNotReady(dpl) {
+		warnings = append(warnings, "Discovery services publish ready addresses only. Restarting nodes may not discover the cluster before they are ready")
+	}
+	if clientPublishNotReady(dpl) {
+		warnings = append(warnings, "Client service publishes not ready addresses. Queries are routed to restarting nodes during rollouts")
+	}
+	return warnings
+}
+
+// The messages of the violated rules, shared by the conditions of the reconcile and ValidateSpec
+var invalidMasterCountMessage = fmt.Sprintf("Invalid master nodes count. Please ensure there are no more than %v total nodes with master roles", maxMasterCount)
+
+const (
+	invalidDataCountMessage        = "No data nodes requested. Please ensure there is at least 1 node with data roles"
+	invalidLog4j2PropertiesMessage = "Invalid log4j2.properties. Please ensure they are not empty and define the rootLogger"
+)
+
+func invalidRedundancyPolicyMessage(dpl *api.Elasticsearch) string {
+	return fmt.Sprintf("Wrong RedundancyPolicy selected: %q. Choose different RedundancyPolicy or add more nodes with data roles", dpl.Spec.RedundancyPolicy)
+}
+
+func invalidNodeNamesMessage(duplicates []string) string {
+	return fmt.Sprintf("Node groups generate colliding node names: %s. Please ensure each node group has a unique GenUUID or set of roles", strings.Join(duplicates, ", "))
+}
+
+func reservedJvmOptionsMessage(reserved []string) string {
+	return fmt.Sprintf("JVM options override flags computed by the operator: %s. Please remove them or set allowReservedOptions", strings.Join(reserved, ", "))
+}
+
+func invalidRecoverAfterTimeMessage(value string) string {
+	return fmt.Sprintf("Invalid recover after time: %s. Please ensure it is an Elasticsearch time value, e.g. 5m", value)
+}
+
+func invalidRoutingShardsMessage(dpl *api.Elasticsearch) string {
+	return fmt.Sprintf("Invalid routing shards: %d. Please ensure they are a multiple of the %d primary shards. They are not set on new indices until then", getRoutingShards(dpl), CalculatePrimaryCount(dpl))
+}
+
+func invalidClusterNameMessage(name string) string {
+	return fmt.Sprintf("Invalid cluster name: %q. Please ensure it starts with a letter or digit followed by letters, digits, '_', '-' or '.'", name)
+}
+
+func invalidDataTiersMessage(invalid []string) string {
+	return fmt.Sprintf("Invalid data tiers: %s. Please ensure each data node group declares one of the tiers hot, warm or cold and index tiers match data nodes", strings.Join(invalid, ", "))
+}
+
+func invalidPluginsMessage(invalid []string) string {
+	return fmt.Sprintf("Invalid plugins: %s. Please ensure plugins are names of official plugins or URLs of plugin archives and the mirror is an http(s) URL", strings.Join(invalid, ", "))
+}
+
+func invalidStorageMessage(invalid []string) string {
+	return fmt.Sprintf("Invalid storage: %s. Please ensure nodes write their data with ReadWriteOnce or ReadWriteMany only and access modes or the Block volume mode come with a size", strings.Join(invalid, ", "))
+}
+
+func invalidDiscoverySeedHostsMessage(invalid []string) string {
+	return fmt.Sprintf("Invalid discovery seed hosts: %s. Please ensure seed hosts are DNS names with an optional port", strings.Join(invalid, ", "))
+}
+
+func invalidUpdateStrategiesMessage(invalid []string) string {
+	return fmt.Sprintf("Invalid update strategies: %s. Please use the Recreate strategy, the operator replaces the pods of every node group itself", strings.Join(invalid, ", "))
+}
+
+func invalidMaintenanceWindowMessage(invalid []string) string {
+	return fmt.Sprintf("Invalid maintenance window: %s. Please ensure it starts at a HH:MM time of the day, lasts a positive duration and opens on days of the week", strings.Join(invalid, ", "))
+}
+
+func invalidSysctlsMessage(invalid []string) string {
+	return fmt.Sprintf("Invalid sysctls: %s. Please ensure sysctls are namespaced, requested once and unsafe ones are allowed by allowUnsafeSysctls", strings.Join(invalid, ", "))
+}
+
+func invalidServicePortsMessage(invalid []string) string {
+	return fmt.Sprintf("Invalid service ports: %s. Please ensure only the client service customizes its port, within 1-65535 and with a DNS label name", strings.Join(invalid, ", "))
+}
+
+func invalidAutoCreateIndexMessage(invalid []string) string {
+	return fmt.Sprintf("Invalid auto create index patterns: %s. Please ensure patterns are lowercase index names or wildcards with at most one + or - prefix", strings.Join(invalid, ", "))
+}
+
+func invalidAutoscalingMessage(invalid []string) string {
+	return fmt.Sprintf("Invalid autoscaling: %s. Please ensure only coordinating only node groups autoscale between at least 1 and maxReplicas nodes", strings.Join(invalid, ", "))
+}
@@ -0,0 +1,133 @@
+package elasticsearch
+
+import (
+	"strings"
+	"testing"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestValidateSpec(t *testing.T) {
+	masterData := []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleMaster, loggingv1.ElasticsearchRoleData}
+	master := []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleMaster}
+	requests := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("2Gi"),
+			corev1.ResourceCPU:    resource.MustParse("1"),
+		},
+	}
+	enabled := true
+	disabled := false
+
+	tests := []struct {
+		desc     string
+		spec     loggingv1.ElasticsearchSpec
+		errors   []string
+		warnings []string
+	}{
+		{
+			desc: "valid spec",
+			spec: loggingv1.ElasticsearchSpec{
+				Spec:             loggingv1.ElasticsearchNodeSpec{Resources: requests},
+				RedundancyPolicy: loggingv1.SingleRedundancy,
+				Nodes: []loggingv1.ElasticsearchNode{
+					{Roles: masterData, NodeCount: 3},
+				},
+			},
+		},
+		{
+			desc: "too many masters",
+			spec: loggingv1.ElasticsearchSpec{
+				Spec:             loggingv1.ElasticsearchNodeSpec{Resources: requests},
+				RedundancyPolicy: loggingv1.SingleRedundancy,
+				Nodes: []loggingv1.ElasticsearchNode{
+					{Roles: masterData, NodeCount: 5},
+				},
+			},
+			errors: []string{"Invalid master nodes count"},
+		},
+		{
+			desc: "replicas without data nodes to hold them",
+			spec: loggingv1.ElasticsearchSpec{
+				Spec:             loggingv1.ElasticsearchNodeSpec{Resources: requests},
+				RedundancyPolicy: loggingv1.MultipleRedundancy,
+				Nodes: []loggingv1.ElasticsearchNode{
+					{Roles: masterData, NodeCount: 1},
+				},
+			},
+			errors: []string{"Wrong RedundancyPolicy"},
+		},
+		{
+			desc: "no data nodes",
+			spec: loggingv1.ElasticsearchSpec{
+				Spec:             loggingv1.ElasticsearchNodeSpec{Resources: requests},
+				RedundancyPolicy: loggingv1.ZeroRedundancy,
+				Nodes: []loggingv1.ElasticsearchNode{
+					{Roles: master, NodeCount: 1},
+				},
+			},
+			errors: []string{"No data nodes requested"},
+		},
+		{
+			desc: "even masters and missing requests",
+			spec: loggingv1.ElasticsearchSpec{
+				RedundancyPolicy: loggingv1.SingleRedundancy,
+				Nodes: []loggingv1.ElasticsearchNode{
+					{Roles: masterData, NodeCount: 2},
+					{
+						Roles:     []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleClient},
+						NodeCount: 1,
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+						},
+					},
+				},
+			},
+			warnings: []string{"Even master nodes count: 2", "nodes[0] requests no memory and cpu", "nodes[1] requests no memory"},
+		},
+		{
+			desc: "services routing to restarting nodes",
+			spec: loggingv1.ElasticsearchSpec{
+				Spec:             loggingv1.ElasticsearchNodeSpec{Resources: requests},
+				RedundancyPolicy: loggingv1.SingleRedundancy,
+				Nodes: []loggingv1.ElasticsearchNode{
+					{Roles: masterData, NodeCount: 3},
+				},
+				Services: &loggingv1.ElasticsearchServicesSpec{
+					Discovery: &loggingv1.ElasticsearchServiceSpec{PublishNotReadyAddresses: &disabled},
+					Client:    &loggingv1.ElasticsearchServiceSpec{PublishNotReadyAddresses: &enabled},
+				},
+			},
+			warnings: []string{"Discovery services publish ready addresses only", "Client service publishes not ready addresses"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			dpl := &loggingv1.Elasticsearch{Spec: test.spec}
+			dpl.Name = "elasticsearch"
+
+			result := ValidateSpec(dpl)
+			if result.IsValid() != (len(test.errors) == 0) {
+				t.Errorf("Exp. valid to be %t but got errors %v", len(test.errors) == 0, result.Errors)
+			}
+			assertMessages(t, "errors", test.errors, result.Errors)
+			assertMessages(t, "warnings", test.warnings, result.Warnings)
+		})
+	}
+}
+
+func assertMessages(t *testing.T, kind string, want, got []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("Exp. %s %v but got %v", kind, want, got)
+	}
+	for i, prefix := range want {
+		if !strings.HasPrefix(got[i], prefix) {
+			t.Errorf("Exp. %s to start with %q but got %q", kind, prefix, got[i])
+		}
+	}
+}
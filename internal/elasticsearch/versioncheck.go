@@ -0,0 +1,183 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ViaQ/logerr/kverrors"
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
+	"github.com/openshift/elasticsearch-operator/internal/utils/comparators"
+	"gopkg.in/yaml.v2"
+	v1 "k8s.io/api/core/v1"
+)
+
+// configVersionMismatchReason is the reason of the ConfigVersionMismatch condition, reporting
+// settings of the desired configuration not supported by the Elasticsearch version of the image
+const configVersionMismatchReason = "Version Mismatch"
+
+// versionedSetting is a setting of elasticsearch.yml only supported by some major versions
+type versionedSetting struct {
+	// the first major version supporting the setting, zero if supported by all previous ones
+	since int
+	// the first major version no longer supporting the setting, zero if supported to date
+	removedIn int
+	// the setting to use instead, if any
+	replacement string
+}
+
+// versionedSettings are the settings of elasticsearch.yml failing the bootstrap checks of
+// the versions not supporting them
+var versionedSettings = map[string]versionedSetting{
+	"node.master":                        {removedIn: 8, replacement: "node.roles"},
+	"node.data":                          {removedIn: 8, replacement: "node.roles"},
+	"node.ingest":                        {removedIn: 8, replacement: "node.roles"},
+	"node.max_local_storage_nodes":       {removedIn: 8},
+	"discovery.zen.minimum_master_nodes": {removedIn: 8, replacement: "cluster.initial_master_nodes"},
+	"discovery.zen.ping.unicast.hosts":   {removedIn: 8, replacement: "discovery.seed_hosts"},
+	"node.roles":                         {since: 7},
+	"discovery.seed_hosts":               {since: 7},
+	"cluster.initial_master_nodes":       {since: 7},
+}
+
+// syncedFlushRemovedIn is the first major version without the synced flush the operator
+// runs before restarting the nodes
+const syncedFlushRemovedIn = 8
+
+// votingConfigExclusionsMinVersion is the first version excluding master eligible nodes
+// from voting by their names
+const votingConfigExclusionsMinVersion = "7.8"
+
+// majorVersionTagRegex matches the major version at the start of an image tag, e.g. 6 of 6.8.1-3
+var majorVersionTagRegex = regexp.MustCompile(`^v?([0-9]+)(\.|-|$)`)
+
+// majorVersionRepositoryRegex matches the major version at the end of an image repository,
+// e.g. 6 of quay.io/openshift-logging/elasticsearch6
+var majorVersionRepositoryRegex = regexp.MustCompile(`[a-z]([0-9]+)$`)
+
+// getImageMajorVersion returns the major Elasticsearch version of the image parsed from
+// its repository name or else from its tag, or zero if neither tells the version. The
+// repository name comes first, since tags may carry the version of the product shipping
+// the image instead, e.g. elasticsearch6:v5.2.0.
+func getImageMajorVersion(image string) int {
+	repository, reference := image, ""
+	if i := strings.Index(repository, "@"); i >= 0 {
+		repository = repository[:i]
+	} else if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository, reference = repository[:i], repository[i+1:]
+	}
+
+	if match := majorVersionRepositoryRegex.FindStringSubmatch(repository); match != nil {
+		major, _ := strconv.Atoi(match[1])
+		return major
+	}
+	if match := majorVersionTagRegex.FindStringSubmatch(reference); match != nil {
+		major, _ := strconv.Atoi(utils.GetMajorVersion(match[1]))
+		return major
+	}
+	return 0
+}
+
+// getVersionMismatches returns the settings of the rendered elasticsearch.yml and the
+// operations of the operator not supported by the major version
+func getVersionMismatches(major int, esYml string) ([]string, error) {
+	settings := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(esYml), &settings); err != nil {
+		return nil, kverrors.Wrap(err, "failed to parse the rendered elasticsearch.yml")
+	}
+
+	mismatches := []string{}
+	for _, key := range flattenSettings("", settings) {
+		setting, ok := versionedSettings[key]
+		if !ok {
+			continue
+		}
+
+		switch {
+		case setting.since > 0 && major < setting.since:
+			mismatches = append(mismatches, fmt.Sprintf("%s (supported since %d)", key, setting.since))
+		case setting.removedIn > 0 && major >= setting.removedIn && setting.replacement != "":
+			mismatches = append(mismatches, fmt.Sprintf("%s (removed in %d, replaced by %s)", key, setting.removedIn, setting.replacement))
+		case setting.removedIn > 0 && major >= setting.removedIn:
+			mismatches = append(mismatches, fmt.Sprintf("%s (removed in %d)", key, setting.removedIn))
+		}
+	}
+	sort.Strings(mismatches)
+
+	if major >= syncedFlushRemovedIn {
+		mismatches = append(mismatches, fmt.Sprintf("synced flush before restarts (removed in %d)", syncedFlushRemovedIn))
+	}
+
+	return mismatches, nil
+}
+
+// flattenSettings returns the dotted keys of the nested settings, e.g. discovery.zen.minimum_master_nodes
+func flattenSettings(prefix string, settings map[string]interface{}) []string {
+	keys := []string{}
+	for key, value := range settings {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		nested := map[string]interface{}{}
+		if m, ok := value.(map[interface{}]interface{}); ok {
+			for k, v := range m {
+				nested[fmt.Sprint(k)] = v
+			}
+			keys = append(keys, flattenSettings(key, nested)...)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// checkConfigVersion verifies that the version specific settings of the desired
+// elasticsearch.yml match the major version of the Elasticsearch image, so that an image
+// bump without matching configuration does not roll out nodes failing their bootstrap checks
+func (er *ElasticsearchRequest) checkConfigVersion(esYml string) error {
+	dpl := er.cluster
+	esImage := getESImage()
+
+	major := getImageMajorVersion(esImage)
+	if major == 0 {
+		er.L().Info("Unable to tell the Elasticsearch version of the image, skipping the configuration check", "image", esImage)
+		return updateCondition(dpl, api.ConfigVersionMismatch, configVersionMismatchReason, v1.ConditionFalse, "", er.client)
+	}
+
+	mismatches, err := getVersionMismatches(major, esYml)
+	if err != nil {
+		return err
+	}
+
+	if len(mismatches) == 0 {
+		return updateCondition(dpl, api.ConfigVersionMismatch, configVersionMismatchReason, v1.ConditionFalse, "", er.client)
+	}
+
+	message := fmt.Sprintf("The configuration does not match version %d of the image %s: %s", major, esImage, strings.Join(mismatches, ", "))
+	if _, condition := getESNodeCondition(dpl.Status.Conditions, api.ConfigVersionMismatch); condition == nil || condition.Message != message {
+		recordEvent(er.recorder, dpl, v1.EventTypeWarning, eventReasonConfigVersionMismatch, message)
+	}
+	if err := updateCondition(dpl, api.ConfigVersionMismatch, configVersionMismatchReason, v1.ConditionTrue, message, er.client); err != nil {
+		return kverrors.Wrap(err, "failed to set config version status")
+	}
+
+	return kverrors.New("configuration does not match the Elasticsearch version of the image",
+		"image", esImage,
+		"mismatches", mismatches)
+}
+
+// usesVotingConfigExclusions returns true if the master eligible nodes of the cluster vote
+// on the elected master and support excluding a master from voting before removing it.
+// Older clusters rely on the minimum master nodes setting instead.
+func (er *ElasticsearchRequest) usesVotingConfigExclusions() bool {
+	version, err := er.esClient.GetLowestClusterVersion()
+	if err != nil {
+		er.L().Info("Unable to get cluster version, not excluding master node from voting", "error", err)
+		return false
+	}
+	return comparators.CompareVersions(version, votingConfigExclusionsMinVersion) <= 0
+}
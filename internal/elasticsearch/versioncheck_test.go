@@ -0,0 +1,61 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGetImageMajorVersion(t *testing.T) {
+	for image, want := range map[string]int{
+		"quay.io/openshift-logging/elasticsearch6:6.8.1":             6,
+		"quay.io/openshift-logging/elasticsearch6:latest":            6,
+		"registry.redhat.io/openshift-logging/elasticsearch6:v5.2.0": 6,
+		"registry.example.com:5000/logging/elasticsearch:7.10.2-1":   7,
+		"registry.example.com/elasticsearch:v8.1":                    8,
+		"quay.io/openshift-logging/elasticsearch6@sha256:deadbeef":   6,
+		"registry.example.com/elasticsearch:latest":                  0,
+	} {
+		if got := getImageMajorVersion(image); got != want {
+			t.Errorf("Exp. major version %d of %s but got %d", want, image, got)
+		}
+	}
+}
+
+func TestGetVersionMismatches(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := renderEsYml(buf, "unique", "[elasticsearch-cluster]", "2", "3", "5m", "true", false, false); err != nil {
+		t.Fatalf("failed to render elasticsearch.yml: %s", err)
+	}
+	esYml := buf.String()
+
+	mismatches, err := getVersionMismatches(6, esYml)
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if len(mismatches) != 0 {
+		t.Errorf("Exp. the configuration to match version 6 but got %v", mismatches)
+	}
+
+	mismatches, err = getVersionMismatches(8, esYml)
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	for _, want := range []string{"node.master", "discovery.zen.minimum_master_nodes", "synced flush"} {
+		found := false
+		for _, mismatch := range mismatches {
+			found = found || strings.HasPrefix(mismatch, want)
+		}
+		if !found {
+			t.Errorf("Exp. %s to mismatch version 8 but got %v", want, mismatches)
+		}
+	}
+
+	mismatches, err = getVersionMismatches(6, "node:\n  roles: master,data\n")
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if len(mismatches) != 1 || !strings.HasPrefix(mismatches[0], "node.roles") {
+		t.Errorf("Exp. node.roles to mismatch version 6 but got %v", mismatches)
+	}
+}
@@ -222,6 +222,38 @@ var _ = Describe("Index Management", func() {
 					"template": "node.infra*"
 				}`)
 		})
+		It("should set the routing shards of the cluster on the template", func() {
+			routingShards := int32(48)
+			request.cluster.Spec.RoutingShards = &routingShards
+			defer func() { request.cluster.Spec.RoutingShards = nil }()
+
+			Expect(request.createOrUpdateIndexTemplate(mapping)).To(BeNil())
+			req, _ := chatter.GetRequest("_template/ocp-gen-node.infra")
+			helpers.ExpectJSON(req.Body).ToEqual(
+				`{
+					"aliases": {
+						"infra": {},
+						"node.infra" : {}
+					},
+					"settings": {
+						"index": {
+							"number_of_replicas": "1",
+							"number_of_routing_shards": "48",
+							"number_of_shards": "3"
+						}
+					},
+					"template": "node.infra*"
+				}`)
+		})
+		It("should leave out routing shards not fitting the primary shards", func() {
+			routingShards := int32(16)
+			request.cluster.Spec.RoutingShards = &routingShards
+			defer func() { request.cluster.Spec.RoutingShards = nil }()
+
+			Expect(request.createOrUpdateIndexTemplate(mapping)).To(BeNil())
+			req, _ := chatter.GetRequest("_template/ocp-gen-node.infra")
+			Expect(req.Body).NotTo(ContainSubstring("number_of_routing_shards"))
+		})
 	})
 	Describe("#initializeIndexIfNeeded", func() {
 		Context("when an index matching the pattern for rolling indices does not exist", func() {
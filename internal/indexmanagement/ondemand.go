@@ -0,0 +1,308 @@
+package indexmanagement
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ViaQ/logerr/kverrors"
+	"github.com/ViaQ/logerr/log"
+	apis "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batch "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// runIndexManagementNowAnnotation requests a run of the index management outside of the
+	// schedule, for all policy mappings or the comma separated ones it is set to
+	runIndexManagementNowAnnotation = "elasticsearch.openshift.io/run-index-management-now"
+
+	// onDemandRunLabel marks the jobs of the runs started on demand and onDemandMappingLabel
+	// tells the policy mapping they run for
+	onDemandRunLabel     = "logging.openshift.io/index-management-run"
+	onDemandRunValue     = "on-demand"
+	onDemandMappingLabel = "logging.openshift.io/index-management-mapping"
+
+	eventReasonIndexManagementRun = "IndexManagementRun"
+)
+
+// onDemandJobName returns the name of the job running the index management of a
+// cronjob on demand
+func onDemandJobName(cronJobName string) string {
+	return fmt.Sprintf("%s-now", cronJobName)
+}
+
+// requestedRuns returns the names of the policy mappings the cluster requests to run the
+// index management for now. Unknown mappings are ignored.
+func requestedRuns(cluster *apis.Elasticsearch, mappings []apis.IndexManagementPolicyMappingSpec) []string {
+	value, ok := cluster.Annotations[runIndexManagementNowAnnotation]
+	if !ok {
+		return nil
+	}
+
+	known := sets.NewString()
+	for _, mapping := range mappings {
+		known.Insert(mapping.Name)
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "" || value == "true" || value == "all" {
+		return known.List()
+	}
+
+	requested := sets.NewString()
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if !known.Has(name) {
+			log.Info("Ignoring on demand index management run of unknown policy mapping", "mapping", name)
+			continue
+		}
+		requested.Insert(name)
+	}
+	return requested.List()
+}
+
+// newOnDemandJob returns a job running the job template of the cronjob once
+func newOnDemandJob(cluster *apis.Elasticsearch, cj *batch.CronJob, mapping string) *batchv1.Job {
+	labels := map[string]string{}
+	for k, v := range cj.Spec.JobTemplate.Labels {
+		labels[k] = v
+	}
+	for k, v := range cj.Labels {
+		labels[k] = v
+	}
+	labels[onDemandRunLabel] = onDemandRunValue
+	labels[onDemandMappingLabel] = mapping
+
+	annotations := map[string]string{
+		// the same annotation kubectl create job --from sets
+		"cronjob.kubernetes.io/instantiate": "manual",
+	}
+	for k, v := range cj.Spec.JobTemplate.Annotations {
+		annotations[k] = v
+	}
+
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Job",
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        onDemandJobName(cj.Name),
+			Namespace:   cj.Namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: *cj.Spec.JobTemplate.Spec.DeepCopy(),
+	}
+	cluster.AddOwnerRefTo(job)
+	return job
+}
+
+// getJobFinished returns the state of a finished job and the time it finished, or false if
+// it still runs
+func getJobFinished(job batchv1.Job) (apis.IndexManagementRunState, *metav1.Time, bool) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+
+		finished := condition.LastTransitionTime.DeepCopy()
+		if job.Status.CompletionTime != nil {
+			finished = job.Status.CompletionTime.DeepCopy()
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return apis.IndexManagementRunStateSucceeded, finished, true
+		case batchv1.JobFailed:
+			return apis.IndexManagementRunStateFailed, finished, true
+		}
+	}
+	return "", nil, false
+}
+
+// completeOnDemandRuns records the outcome of the finished on demand runs and removes their
+// jobs. It returns the policy mappings whose on demand run still runs.
+func (imr *IndexManagementRequest) completeOnDemandRuns() (sets.String, error) {
+	running := sets.NewString()
+
+	jobs := &batchv1.JobList{}
+	opts := []client.ListOption{
+		client.InNamespace(imr.cluster.Namespace),
+		client.MatchingLabels{
+			"cluster-name":   imr.cluster.Name,
+			onDemandRunLabel: onDemandRunValue,
+		},
+	}
+	if err := imr.client.List(context.TODO(), jobs, opts...); err != nil {
+		return running, kverrors.Wrap(err, "failed to list on demand index management jobs",
+			"cluster", imr.cluster.Name,
+		)
+	}
+
+	for _, job := range jobs.Items {
+		mapping := job.Labels[onDemandMappingLabel]
+		state, finished, ok := getJobFinished(job)
+		if !ok {
+			running.Insert(mapping)
+			continue
+		}
+
+		run := apis.IndexManagementRunStatus{
+			Mapping:        mapping,
+			Job:            job.Name,
+			State:          state,
+			StartTime:      job.CreationTimestamp,
+			CompletionTime: finished,
+		}
+		if current := imr.getRunStatus(mapping); current != nil && current.Job == job.Name {
+			run.StartTime = current.StartTime
+		}
+		if err := imr.updateRunStatus(run); err != nil {
+			return running, err
+		}
+
+		imr.recordRun(fmt.Sprintf("On demand index management run of policy mapping %q %s", mapping, strings.ToLower(string(state))))
+
+		err := imr.client.Delete(context.TODO(), &job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !apierrors.IsNotFound(err) {
+			return running, kverrors.Wrap(err, "failed to delete on demand index management job",
+				"job", job.Name,
+			)
+		}
+	}
+
+	return running, nil
+}
+
+// startOnDemandRuns creates the jobs running the index management of the requested policy
+// mappings now and clears the request once all of them started. Mappings whose cronjob
+// forbids concurrent runs wait for the active scheduled run to finish first. Mappings
+// already running on demand are not started twice.
+func (imr *IndexManagementRequest) startOnDemandRuns(mappings []apis.IndexManagementPolicyMappingSpec, running sets.String) error {
+	if _, ok := imr.cluster.Annotations[runIndexManagementNowAnnotation]; !ok {
+		return nil
+	}
+
+	pending := false
+	for _, mapping := range requestedRuns(imr.cluster, mappings) {
+		ll := log.WithValues("mapping", mapping)
+		if running.Has(mapping) {
+			ll.Info("On demand index management run already in progress")
+			continue
+		}
+
+		cj := &batch.CronJob{}
+		key := client.ObjectKey{Name: fmt.Sprintf("%s-im-%s", imr.cluster.Name, mapping), Namespace: imr.cluster.Namespace}
+		if err := imr.client.Get(context.TODO(), key, cj); err != nil {
+			return kverrors.Wrap(err, "failed to get index management cronjob",
+				"cronjob", key.Name,
+			)
+		}
+
+		if cj.Spec.ConcurrencyPolicy != batch.AllowConcurrent && len(cj.Status.Active) > 0 {
+			ll.Info("On demand index management run waits on the scheduled run to finish", "concurrencyPolicy", cj.Spec.ConcurrencyPolicy)
+			pending = true
+			continue
+		}
+
+		job := newOnDemandJob(imr.cluster, cj, mapping)
+		if err := imr.client.Create(context.TODO(), job); err != nil && !apierrors.IsAlreadyExists(err) {
+			return kverrors.Wrap(err, "failed to create on demand index management job",
+				"job", job.Name,
+			)
+		}
+
+		run := apis.IndexManagementRunStatus{
+			Mapping:   mapping,
+			Job:       job.Name,
+			State:     apis.IndexManagementRunStateRunning,
+			StartTime: metav1.Now(),
+		}
+		if err := imr.updateRunStatus(run); err != nil {
+			return err
+		}
+		imr.recordRun(fmt.Sprintf("Started on demand index management run of policy mapping %q", mapping))
+	}
+
+	if pending {
+		return nil
+	}
+	return imr.clearRunRequest()
+}
+
+// getRunStatus returns the status of the last on demand run of the policy mapping
+func (imr *IndexManagementRequest) getRunStatus(mapping string) *apis.IndexManagementRunStatus {
+	for i, run := range imr.cluster.Status.IndexManagementRuns {
+		if run.Mapping == mapping {
+			return &imr.cluster.Status.IndexManagementRuns[i]
+		}
+	}
+	return nil
+}
+
+// updateRunStatus replaces the status of the last on demand run of the policy mapping
+func (imr *IndexManagementRequest) updateRunStatus(run apis.IndexManagementRunStatus) error {
+	key := client.ObjectKey{Name: imr.cluster.Name, Namespace: imr.cluster.Namespace}
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current := &apis.Elasticsearch{}
+		if err := imr.client.Get(context.TODO(), key, current); err != nil {
+			return err
+		}
+
+		runs := []apis.IndexManagementRunStatus{}
+		for _, r := range current.Status.IndexManagementRuns {
+			if r.Mapping != run.Mapping {
+				runs = append(runs, r)
+			}
+		}
+		current.Status.IndexManagementRuns = append(runs, run)
+
+		if err := imr.client.Status().Update(context.TODO(), current); err != nil {
+			return err
+		}
+		imr.cluster.Status.IndexManagementRuns = current.Status.IndexManagementRuns
+		return nil
+	})
+	return kverrors.Wrap(err, "failed to update on demand index management run status",
+		"mapping", run.Mapping,
+	)
+}
+
+// clearRunRequest removes the annotation requesting the on demand runs from the cluster
+func (imr *IndexManagementRequest) clearRunRequest() error {
+	key := client.ObjectKey{Name: imr.cluster.Name, Namespace: imr.cluster.Namespace}
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current := &apis.Elasticsearch{}
+		if err := imr.client.Get(context.TODO(), key, current); err != nil {
+			return err
+		}
+		if _, ok := current.Annotations[runIndexManagementNowAnnotation]; !ok {
+			return nil
+		}
+
+		delete(current.Annotations, runIndexManagementNowAnnotation)
+		return imr.client.Update(context.TODO(), current)
+	})
+	if err != nil {
+		return kverrors.Wrap(err, "failed to clear on demand index management run request",
+			"annotation", runIndexManagementNowAnnotation,
+		)
+	}
+
+	delete(imr.cluster.Annotations, runIndexManagementNowAnnotation)
+	return nil
+}
+
+func (imr *IndexManagementRequest) recordRun(message string) {
+	log.Info(message, "cluster", imr.cluster.Name, "namespace", imr.cluster.Namespace)
+	if imr.recorder != nil {
+		imr.recorder.Event(imr.cluster, corev1.EventTypeNormal, eventReasonIndexManagementRun, message)
+	}
+}
@@ -0,0 +1,128 @@
+package indexmanagement
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batch "k8s.io/api/batch/v1beta1"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apis "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+)
+
+var _ = Describe("On demand index management runs", func() {
+	defer GinkgoRecover()
+
+	var (
+		apiclient client.Client
+		cluster   *apis.Elasticsearch
+		cronjob   *batch.CronJob
+		mappings  []apis.IndexManagementPolicyMappingSpec
+		imr       *IndexManagementRequest
+		jobKey    client.ObjectKey
+	)
+	BeforeEach(func() {
+		_ = apis.SchemeBuilder.AddToScheme(scheme.Scheme)
+		cluster = &apis.Elasticsearch{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "mycluster",
+				Namespace:   "somenamespace",
+				Annotations: map[string]string{runIndexManagementNowAnnotation: "app"},
+			},
+		}
+		mappings = []apis.IndexManagementPolicyMappingSpec{{Name: "app"}, {Name: "infra"}}
+		cronjob = newCronJob(cluster.Name, cluster.Namespace, "mycluster-im-app", "*/5 * * * *", "./rollover", newCronJobLabels(cluster), nil, nil, []core.EnvVar{}, false)
+		jobKey = client.ObjectKey{Name: "mycluster-im-app-now", Namespace: cluster.Namespace}
+	})
+	JustBeforeEach(func() {
+		apiclient = fake.NewFakeClient(cluster.DeepCopy(), cronjob)
+		imr = &IndexManagementRequest{client: apiclient, cluster: cluster, recorder: record.NewFakeRecorder(10)}
+	})
+
+	Describe("#requestedRuns", func() {
+		It("should request all mappings unless the annotation names some", func() {
+			cluster.Annotations[runIndexManagementNowAnnotation] = "true"
+			Expect(requestedRuns(cluster, mappings)).To(Equal([]string{"app", "infra"}))
+		})
+		It("should request the named mappings only and ignore unknown ones", func() {
+			cluster.Annotations[runIndexManagementNowAnnotation] = "infra, unknown"
+			Expect(requestedRuns(cluster, mappings)).To(Equal([]string{"infra"}))
+		})
+		It("should request nothing without the annotation", func() {
+			cluster.Annotations = nil
+			Expect(requestedRuns(cluster, mappings)).To(BeEmpty())
+		})
+	})
+
+	Describe("#startOnDemandRuns", func() {
+		It("should create a job from the job template of the cronjob and clear the request", func() {
+			Expect(imr.startOnDemandRuns(mappings, sets.NewString())).To(Succeed())
+
+			job := &batchv1.Job{}
+			Expect(apiclient.Get(context.TODO(), jobKey, job)).To(Succeed())
+			Expect(job.Spec.Template.Spec.Containers).To(Equal(cronjob.Spec.JobTemplate.Spec.Template.Spec.Containers))
+			Expect(job.Labels).To(HaveKeyWithValue(onDemandMappingLabel, "app"))
+			Expect(job.OwnerReferences).To(HaveLen(1))
+
+			current := &apis.Elasticsearch{}
+			Expect(apiclient.Get(context.TODO(), client.ObjectKey{Name: cluster.Name, Namespace: cluster.Namespace}, current)).To(Succeed())
+			Expect(current.Annotations).NotTo(HaveKey(runIndexManagementNowAnnotation))
+			Expect(current.Status.IndexManagementRuns).To(HaveLen(1))
+			Expect(current.Status.IndexManagementRuns[0].State).To(Equal(apis.IndexManagementRunStateRunning))
+		})
+		Context("while a scheduled run is active", func() {
+			BeforeEach(func() {
+				cronjob.Status.Active = []core.ObjectReference{{Name: "mycluster-im-app-123"}}
+			})
+			It("should wait for it to finish and keep the request", func() {
+				Expect(imr.startOnDemandRuns(mappings, sets.NewString())).To(Succeed())
+
+				Expect(apiclient.Get(context.TODO(), jobKey, &batchv1.Job{})).NotTo(Succeed())
+				current := &apis.Elasticsearch{}
+				Expect(apiclient.Get(context.TODO(), client.ObjectKey{Name: cluster.Name, Namespace: cluster.Namespace}, current)).To(Succeed())
+				Expect(current.Annotations).To(HaveKey(runIndexManagementNowAnnotation))
+			})
+		})
+		It("should not start a mapping running on demand twice", func() {
+			Expect(imr.startOnDemandRuns(mappings, sets.NewString("app"))).To(Succeed())
+			Expect(apiclient.Get(context.TODO(), jobKey, &batchv1.Job{})).NotTo(Succeed())
+			Expect(cluster.Annotations).NotTo(HaveKey(runIndexManagementNowAnnotation))
+		})
+	})
+
+	Describe("#completeOnDemandRuns", func() {
+		It("should report running jobs and record and remove finished ones", func() {
+			Expect(imr.startOnDemandRuns(mappings, sets.NewString())).To(Succeed())
+
+			running, err := imr.completeOnDemandRuns()
+			Expect(err).To(BeNil())
+			Expect(running.List()).To(Equal([]string{"app"}))
+
+			job := &batchv1.Job{}
+			Expect(apiclient.Get(context.TODO(), jobKey, job)).To(Succeed())
+			job.Status.Conditions = []batchv1.JobCondition{
+				{Type: batchv1.JobComplete, Status: core.ConditionTrue, LastTransitionTime: metav1.Now()},
+			}
+			Expect(apiclient.Update(context.TODO(), job)).To(Succeed())
+
+			running, err = imr.completeOnDemandRuns()
+			Expect(err).To(BeNil())
+			Expect(running).To(BeEmpty())
+			Expect(apiclient.Get(context.TODO(), jobKey, &batchv1.Job{})).NotTo(Succeed(), "Exp. the finished job to be removed")
+
+			Expect(cluster.Status.IndexManagementRuns).To(HaveLen(1))
+			run := cluster.Status.IndexManagementRuns[0]
+			Expect(run.State).To(Equal(apis.IndexManagementRunStateSucceeded))
+			Expect(run.CompletionTime).NotTo(BeNil())
+		})
+	})
+})
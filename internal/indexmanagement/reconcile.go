@@ -16,7 +16,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/ViaQ/logerr/log"
@@ -24,11 +26,14 @@ import (
 	"github.com/openshift/elasticsearch-operator/internal/constants"
 	"github.com/openshift/elasticsearch-operator/internal/elasticsearch"
 	"github.com/openshift/elasticsearch-operator/internal/elasticsearch/esclient"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/apply"
 	"github.com/openshift/elasticsearch-operator/internal/manifests/configmap"
 	"github.com/openshift/elasticsearch-operator/internal/manifests/cronjob"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/image"
 	"github.com/openshift/elasticsearch-operator/internal/manifests/pod"
 	"github.com/openshift/elasticsearch-operator/internal/manifests/rbac"
 	esapi "github.com/openshift/elasticsearch-operator/internal/types/elasticsearch"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
 	"github.com/openshift/elasticsearch-operator/internal/utils/comparators"
 )
 
@@ -39,6 +44,8 @@ const (
 
 	jobHistoryLimitFailed  int32 = 1
 	jobHistoryLimitSuccess int32 = 1
+
+	eventReasonRBACReverted = "ResourceReverted"
 )
 
 var (
@@ -71,16 +78,21 @@ type IndexManagementRequest struct {
 	client   client.Client
 	cluster  *apis.Elasticsearch
 	esClient esclient.Client
+	recorder record.EventRecorder
 	ll       logr.Logger
 }
 
-func Reconcile(req *apis.Elasticsearch, reqClient client.Client) error {
+func Reconcile(req *apis.Elasticsearch, reqClient client.Client, recorder record.EventRecorder) error {
 	esClient := esclient.NewClient(req.Name, req.Namespace, reqClient)
 
+	// the cronjobs and on demand jobs are removed with the cluster by their owner reference
+	reqClient = apply.GuardOwnerReferences(reqClient, req.Namespace)
+
 	imr := IndexManagementRequest{
 		client:   reqClient,
 		esClient: esClient,
 		cluster:  req,
+		recorder: recorder,
 		ll:       log.WithValues("cluster", req.Name, "namespace", req.Namespace, "handler", "indexmanagement"),
 	}
 
@@ -136,17 +148,26 @@ func (imr *IndexManagementRequest) createOrUpdateIndexManagement() error {
 		return err
 	}
 
+	runningOnDemand, err := imr.completeOnDemandRuns()
+	if err != nil {
+		return err
+	}
+
 	suspend := len(esPods) == 0
 	primaryShards := elasticsearch.GetDataCount(imr.cluster)
 	for _, mapping := range spec.Mappings {
 		policy := policies[mapping.PolicyRef]
 		ll := log.WithValues("mapping", mapping.Name, "policy", policy.Name)
-		if err := imr.reconcileIndexManagementCronjob(policy, mapping, primaryShards, suspend); err != nil {
+		// the schedule waits on the run started on demand like on a scheduled one
+		if err := imr.reconcileIndexManagementCronjob(policy, mapping, primaryShards, suspend || runningOnDemand.Has(mapping.Name)); err != nil {
 			ll.Error(err, "could not reconcile indexmanagement cronjob")
 			return err
 		}
 	}
 
+	if !suspend {
+		return imr.startOnDemandRuns(spec.Mappings, runningOnDemand)
+	}
 	return nil
 }
 
@@ -211,15 +232,21 @@ func (imr *IndexManagementRequest) createOrUpdateIndexTemplate(mapping apis.Inde
 	replicas := int32(elasticsearch.CalculateReplicaCount(imr.cluster))
 	aliases := append(mapping.Aliases, mapping.Name)
 	template := esapi.NewIndexTemplate(pattern, aliases, primaryShards, replicas)
+	routingShards := ""
+	if count := elasticsearch.CalculateRoutingShardsCount(imr.cluster); count > 0 {
+		template.Settings.Index.NumberOfRoutingShards = int32(count)
+		routingShards = strconv.Itoa(count)
+	}
 
-	// check to compare the current index templates vs what we just generated
+	// check to compare the current index templates vs what we just generated,
+	// the routing shards being the only setting following the cluster spec
 	templates, err := imr.esClient.GetIndexTemplates()
 	if err != nil {
 		return err
 	}
 
-	for templateName := range templates {
-		if templateName == name {
+	for templateName, current := range templates {
+		if templateName == name && current.Settings.Index.NumberOfRoutingShards == routingShards {
 			return nil
 		}
 	}
@@ -260,9 +287,17 @@ func (imr *IndexManagementRequest) removeCronJobsForMappings(mappings []apis.Ind
 func createOrUpdateCurationConfigmap(apiclient client.Client, cluster *apis.Elasticsearch) error {
 	data := scriptMap
 	desired := configmap.New(indexManagementConfigmap, cluster.Namespace, imLabels, data)
+	desired.Annotations = utils.WithPropagatedAnnotations(desired.Annotations, propagatedAnnotations(cluster))
 	cluster.AddOwnerRefTo(desired)
 
-	_, err := configmap.CreateOrUpdate(context.TODO(), apiclient, desired, configmap.DataEqual, configmap.MutateDataOnly)
+	equalFunc := func(current, desired *corev1.ConfigMap) bool {
+		return configmap.DataEqual(current, desired) && utils.ContainsPropagatedAnnotations(current.Annotations, desired.Annotations)
+	}
+	mutateFunc := func(current, desired *corev1.ConfigMap) {
+		configmap.MutateDataOnly(current, desired)
+		current.Annotations = utils.MergePropagatedAnnotations(current.Annotations, desired.Annotations)
+	}
+	_, err := configmap.CreateOrUpdate(context.TODO(), apiclient, desired, equalFunc, mutateFunc)
 	if err != nil {
 		return kverrors.Wrap(err, "failed to create or update index management configmap",
 			"cluster", cluster.Name,
@@ -276,6 +311,7 @@ func createOrUpdateCurationConfigmap(apiclient client.Client, cluster *apis.Elas
 func (imr *IndexManagementRequest) reconcileIndexManagmentRbac() error {
 	cluster := imr.cluster
 	client := imr.client
+	createOnly := cluster.Spec.IndexManagement.RBACPolicy == apis.IndexManagementRBACCreateOnly
 
 	role := rbac.NewRole(
 		"elasticsearch-index-management",
@@ -293,12 +329,24 @@ func (imr *IndexManagementRequest) reconcileIndexManagmentRbac() error {
 
 	cluster.AddOwnerRefTo(role)
 
-	err := rbac.CreateOrUpdateRole(context.TODO(), client, role)
-	if err != nil {
-		return kverrors.Wrap(err, "failed to create or update index management role",
-			"cluster", cluster.Name,
-			"namespace", cluster.Namespace,
-		)
+	if createOnly {
+		if err := createIfMissing(client, role); err != nil {
+			return kverrors.Wrap(err, "failed to create index management role",
+				"cluster", cluster.Name,
+				"namespace", cluster.Namespace,
+			)
+		}
+	} else {
+		reverted, err := rbac.CreateOrUpdateRole(context.TODO(), client, role)
+		if err != nil {
+			return kverrors.Wrap(err, "failed to create or update index management role",
+				"cluster", cluster.Name,
+				"namespace", cluster.Namespace,
+			)
+		}
+		if reverted {
+			imr.recordRBACReverted("Role", role.Name, "rules")
+		}
 	}
 
 	subject := rbac.NewSubject(
@@ -315,17 +363,50 @@ func (imr *IndexManagementRequest) reconcileIndexManagmentRbac() error {
 	)
 	cluster.AddOwnerRefTo(roleBinding)
 
-	err = rbac.CreateOrUpdateRoleBinding(context.TODO(), client, roleBinding)
+	if createOnly {
+		if err := createIfMissing(client, roleBinding); err != nil {
+			return kverrors.Wrap(err, "failed to create index management rolebinding",
+				"cluster", cluster.Name,
+				"namespace", cluster.Namespace,
+			)
+		}
+		return nil
+	}
+
+	reverted, err := rbac.CreateOrUpdateRoleBinding(context.TODO(), client, roleBinding)
 	if err != nil {
 		return kverrors.Wrap(err, "failed to create or update index management rolebinding",
 			"cluster", cluster.Name,
 			"namespace", cluster.Namespace,
 		)
 	}
+	if reverted {
+		imr.recordRBACReverted("RoleBinding", roleBinding.Name, "subjects")
+	}
 
 	return nil
 }
 
+// createIfMissing creates the object unless it exists, leaving any changes to it in place
+func createIfMissing(c client.Client, obj runtime.Object) error {
+	if err := c.Create(context.TODO(), obj); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// recordRBACReverted emits a warning event for the index management RBAC resource whose
+// changes were reverted
+func (imr *IndexManagementRequest) recordRBACReverted(kind, name, field string) {
+	message := fmt.Sprintf("Reverted changes to the %s of %s %q, set the index management rbacPolicy to %s to keep them",
+		field, kind, name, apis.IndexManagementRBACCreateOnly)
+	imr.ll.Info(message)
+
+	if imr.recorder != nil {
+		imr.recorder.Event(imr.cluster, corev1.EventTypeWarning, eventReasonRBACReverted, message)
+	}
+}
+
 func (imr *IndexManagementRequest) reconcileIndexManagementCronjob(policy apis.IndexManagementPolicySpec, mapping apis.IndexManagementPolicyMappingSpec, primaryShards int32, suspend bool) error {
 	if policy.Phases.Delete == nil && policy.Phases.Hot == nil {
 		log.V(1).Info("Skipping indexmanagement cronjob for policymapping; no phases are defined", "policymapping", mapping.Name)
@@ -369,11 +450,13 @@ func (imr *IndexManagementRequest) reconcileIndexManagementCronjob(policy apis.I
 
 	name := fmt.Sprintf("%s-im-%s", imr.cluster.Name, mapping.Name)
 	script := formatCmd(policy)
-	desired := newCronJob(imr.cluster.Name, imr.cluster.Namespace, name, schedule, script, imr.cluster.Spec.Spec.NodeSelector, imr.cluster.Spec.Spec.Tolerations, envvars, suspend)
+	labels := newCronJobLabels(imr.cluster)
+	desired := newCronJob(imr.cluster.Name, imr.cluster.Namespace, name, schedule, script, labels, imr.cluster.Spec.Spec.NodeSelector, imr.cluster.Spec.Spec.Tolerations, envvars, suspend)
 
+	desired.Annotations = utils.WithPropagatedAnnotations(desired.Annotations, propagatedAnnotations(imr.cluster))
 	imr.cluster.AddOwnerRefTo(desired)
 
-	err = cronjob.CreateOrUpdate(context.TODO(), imr.client, desired, areCronJobsSame, cronjob.Mutate)
+	err = cronjob.CreateOrUpdate(context.TODO(), imr.client, desired, areCronJobsSame, mutateCronJob)
 	if err != nil {
 		return kverrors.Wrap(err, "failed to create or update cronjob",
 			"cluster", desired.Name,
@@ -402,6 +485,15 @@ func areCronJobsSame(lhs, rhs *batch.CronJob) bool {
 	if len(lhs.Spec.JobTemplate.Spec.Template.Spec.Containers) != len(lhs.Spec.JobTemplate.Spec.Template.Spec.Containers) {
 		return false
 	}
+	if !comparators.AreStringMapsSame(lhs.Labels, rhs.Labels) {
+		return false
+	}
+	if !utils.ContainsPropagatedAnnotations(lhs.Annotations, rhs.Annotations) {
+		return false
+	}
+	if !comparators.AreStringMapsSame(lhs.Spec.JobTemplate.Spec.Template.Labels, rhs.Spec.JobTemplate.Spec.Template.Labels) {
+		return false
+	}
 	if !comparators.AreStringMapsSame(lhs.Spec.JobTemplate.Spec.Template.Spec.NodeSelector, rhs.Spec.JobTemplate.Spec.Template.Spec.NodeSelector) {
 		return false
 	}
@@ -425,6 +517,18 @@ func areCronJobsSame(lhs, rhs *batch.CronJob) bool {
 	return true
 }
 
+// mutateCronJob applies the desired cronjob and its propagated annotations
+func mutateCronJob(current, desired *batch.CronJob) {
+	cronjob.Mutate(current, desired)
+	current.Annotations = utils.MergePropagatedAnnotations(current.Annotations, desired.Annotations)
+}
+
+// propagatedAnnotations returns the annotations of the cluster propagated to the objects
+// of the index management
+func propagatedAnnotations(cluster *apis.Elasticsearch) map[string]string {
+	return utils.SelectPropagatedAnnotations(cluster.Annotations, cluster.Spec.PropagatedAnnotations)
+}
+
 func areContainersSame(container, other corev1.Container) bool {
 	if container.Name != other.Name {
 		return false
@@ -478,10 +582,29 @@ func newContainer(clusterName, name, image, scriptPath string, envvars []corev1.
 	return container
 }
 
-func newCronJob(clusterName, namespace, name, schedule, script string, nodeSelector map[string]string, tolerations []corev1.Toleration, envvars []corev1.EnvVar, suspend bool) *batch.CronJob {
+// newCronJobLabels returns the labels of the cluster and the user specified index
+// management labels merged with the ones required by the operator to select the cronjobs
+func newCronJobLabels(cluster *apis.Elasticsearch) map[string]string {
+	labels := map[string]string{}
+	for k, v := range cluster.Labels {
+		labels[k] = v
+	}
+	if cluster.Spec.IndexManagement != nil {
+		for k, v := range cluster.Spec.IndexManagement.Labels {
+			labels[k] = v
+		}
+	}
+	labels["cluster-name"] = cluster.Name
+	for k, v := range imLabels {
+		labels[k] = v
+	}
+	return labels
+}
+
+func newCronJob(clusterName, namespace, name, schedule, script string, labels, nodeSelector map[string]string, tolerations []corev1.Toleration, envvars []corev1.EnvVar, suspend bool) *batch.CronJob {
 	containerName := "indexmanagement"
 	containers := []corev1.Container{
-		newContainer(clusterName, containerName, constants.PackagedCuratorImage(), script, envvars),
+		newContainer(clusterName, containerName, image.Resolve(constants.PackagedCuratorImage()), script, envvars),
 	}
 	volumes := []corev1.Volume{
 		{
@@ -513,7 +636,7 @@ func newCronJob(clusterName, namespace, name, schedule, script string, nodeSelec
 		WithTerminationGracePeriodSeconds(300 * time.Second).
 		Build()
 
-	return cronjob.New(name, namespace, imLabels).
+	return cronjob.New(name, namespace, labels).
 		WithSuspend(suspend).
 		WithConcurrencyPolicy(batch.ForbidConcurrent).
 		WithSuccessfulJobsHistoryLimit(jobHistoryLimitSuccess).
@@ -1,14 +1,19 @@
 package indexmanagement
 
 import (
+	"context"
 	"fmt"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"github.com/ViaQ/logerr/log"
+
 	batch "k8s.io/api/batch/v1beta1"
 	core "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
@@ -49,7 +54,7 @@ var _ = Describe("Index Management", func() {
 		selector := map[string]string{}
 		tolerations := []core.Toleration{}
 		name := fmt.Sprintf("%s-im-%s", cluster.Name, mapping.Name)
-		cronjob = newCronJob(cluster.Name, cluster.Namespace, name, "*/5 * * * *", "", selector, tolerations, []core.EnvVar{}, false)
+		cronjob = newCronJob(cluster.Name, cluster.Namespace, name, "*/5 * * * *", "", imLabels, selector, tolerations, []core.EnvVar{}, false)
 	})
 	Describe("#formatCmd", func() {
 		Context("with no policies", func() {
@@ -82,7 +87,7 @@ var _ = Describe("Index Management", func() {
 			selector := map[string]string{}
 			tolerations := []core.Toleration{}
 			name := fmt.Sprintf("%s-rollover-%s", cluster.Name, policy.Name)
-			cronjob = newCronJob(cluster.Name, cluster.Namespace, name, "*/5 * * * *", "", selector, tolerations, []core.EnvVar{}, false)
+			cronjob = newCronJob(cluster.Name, cluster.Namespace, name, "*/5 * * * *", "", imLabels, selector, tolerations, []core.EnvVar{}, false)
 			policy.Phases.Hot = &apis.IndexManagementHotPhaseSpec{
 				Actions: apis.IndexManagementActionsSpec{
 					Rollover: &apis.IndexManagementActionSpec{
@@ -158,6 +163,107 @@ var _ = Describe("Index Management", func() {
 					})
 				})
 			})
+			Context("with cluster and index management labels", func() {
+				It("should propagate the labels to the cronjob and its job pods", func() {
+					cluster.Labels = map[string]string{"team": "logging"}
+					cluster.Spec.IndexManagement = &apis.IndexManagementSpec{
+						Labels: map[string]string{"network": "allow-es"},
+					}
+					imr := &IndexManagementRequest{client: apiclient, cluster: cluster}
+					err := imr.reconcileIndexManagementCronjob(policy, mapping, primaryShards, false)
+					Expect(err).To(BeNil(), fmt.Sprintf("Error: %v", err))
+
+					current := &batch.CronJob{}
+					key := client.ObjectKey{Name: fmt.Sprintf("%s-im-%s", cluster.Name, mapping.Name), Namespace: cluster.Namespace}
+					Expect(apiclient.Get(context.TODO(), key, current)).To(Succeed())
+
+					for _, labels := range []map[string]string{current.Labels, current.Spec.JobTemplate.Spec.Template.Labels} {
+						Expect(labels).To(HaveKeyWithValue("team", "logging"))
+						Expect(labels).To(HaveKeyWithValue("network", "allow-es"))
+						Expect(labels).To(HaveKeyWithValue("cluster-name", cluster.Name))
+						Expect(labels).To(HaveKeyWithValue("component", "indexManagement"))
+					}
+				})
+			})
+			Context("with propagated annotations", func() {
+				It("should propagate them to the cronjob and remove them once no longer propagated", func() {
+					cluster.Annotations = map[string]string{"cost-center": "1234", "other": "value"}
+					cluster.Spec.PropagatedAnnotations = []string{"cost-center"}
+					imr := &IndexManagementRequest{client: apiclient, cluster: cluster}
+					Expect(imr.reconcileIndexManagementCronjob(policy, mapping, primaryShards, false)).To(Succeed())
+
+					current := &batch.CronJob{}
+					key := client.ObjectKey{Name: fmt.Sprintf("%s-im-%s", cluster.Name, mapping.Name), Namespace: cluster.Namespace}
+					Expect(apiclient.Get(context.TODO(), key, current)).To(Succeed())
+					Expect(current.Annotations).To(HaveKeyWithValue("cost-center", "1234"))
+					Expect(current.Annotations).NotTo(HaveKey("other"))
+
+					cluster.Spec.PropagatedAnnotations = nil
+					Expect(imr.reconcileIndexManagementCronjob(policy, mapping, primaryShards, false)).To(Succeed())
+					current = &batch.CronJob{}
+					Expect(apiclient.Get(context.TODO(), key, current)).To(Succeed())
+					Expect(current.Annotations).NotTo(HaveKey("cost-center"))
+				})
+			})
+		})
+	})
+	Describe("#reconcileIndexManagmentRbac", func() {
+		var (
+			recorder *record.FakeRecorder
+			role     *rbac.Role
+		)
+		BeforeEach(func() {
+			recorder = record.NewFakeRecorder(10)
+			cluster.Spec.IndexManagement = &apis.IndexManagementSpec{}
+			role = &rbac.Role{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "elasticsearch-index-management",
+					Namespace: cluster.Namespace,
+				},
+				Rules: []rbac.PolicyRule{
+					{
+						APIGroups: []string{"elasticsearch.openshift.io"},
+						Resources: []string{"indices"},
+						Verbs:     []string{"get"},
+					},
+				},
+			}
+			apiclient = fake.NewFakeClient(role)
+		})
+		Context("with the default policy", func() {
+			It("should revert the changed role rules and record an event", func() {
+				imr := &IndexManagementRequest{client: apiclient, cluster: cluster, recorder: recorder, ll: log.WithValues()}
+				Expect(imr.reconcileIndexManagmentRbac()).To(Succeed())
+
+				current := &rbac.Role{}
+				Expect(apiclient.Get(context.TODO(), client.ObjectKey{Name: role.Name, Namespace: role.Namespace}, current)).To(Succeed())
+				Expect(current.Rules[0].Verbs).To(Equal([]string{"*"}))
+				Expect(recorder.Events).To(HaveLen(1))
+				Expect(<-recorder.Events).To(ContainSubstring(eventReasonRBACReverted))
+			})
+			It("should not record an event if the role is unchanged", func() {
+				imr := &IndexManagementRequest{client: apiclient, cluster: cluster, recorder: recorder, ll: log.WithValues()}
+				Expect(imr.reconcileIndexManagmentRbac()).To(Succeed())
+				<-recorder.Events
+
+				Expect(imr.reconcileIndexManagmentRbac()).To(Succeed())
+				Expect(recorder.Events).To(BeEmpty())
+			})
+		})
+		Context("with the CreateOnly policy", func() {
+			It("should keep the changed role rules", func() {
+				cluster.Spec.IndexManagement.RBACPolicy = apis.IndexManagementRBACCreateOnly
+				imr := &IndexManagementRequest{client: apiclient, cluster: cluster, recorder: recorder, ll: log.WithValues()}
+				Expect(imr.reconcileIndexManagmentRbac()).To(Succeed())
+
+				current := &rbac.Role{}
+				Expect(apiclient.Get(context.TODO(), client.ObjectKey{Name: role.Name, Namespace: role.Namespace}, current)).To(Succeed())
+				Expect(current.Rules[0].Verbs).To(Equal([]string{"get"}))
+				Expect(recorder.Events).To(BeEmpty())
+
+				binding := &rbac.RoleBinding{}
+				Expect(apiclient.Get(context.TODO(), client.ObjectKey{Name: role.Name, Namespace: role.Namespace}, binding)).To(Succeed())
+			})
 		})
 	})
 })
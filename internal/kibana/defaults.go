@@ -4,6 +4,8 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
+const defaultClientNodeTopologyKey = "kubernetes.io/hostname"
+
 var (
 	defaultKibanaMemory     = resource.MustParse("736Mi")
 	defaultKibanaCPURequest = resource.MustParse("100m")
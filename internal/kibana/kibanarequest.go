@@ -3,6 +3,8 @@ package kibana
 import (
 	"context"
 
+	"github.com/ViaQ/logerr/log"
+	"github.com/go-logr/logr"
 	kibana "github.com/openshift/elasticsearch-operator/apis/logging/v1"
 	"github.com/openshift/elasticsearch-operator/internal/elasticsearch/esclient"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -15,6 +17,15 @@ type KibanaRequest struct {
 	client   client.Client
 	cluster  *kibana.Kibana
 	esClient esclient.Client
+	ll       logr.Logger
+}
+
+// L is the logger used for this request.
+func (clusterRequest *KibanaRequest) L() logr.Logger {
+	if clusterRequest.ll == nil {
+		clusterRequest.ll = log.WithValues("cluster", clusterRequest.cluster.Name, "namespace", clusterRequest.cluster.Namespace)
+	}
+	return clusterRequest.ll
 }
 
 // TODO: determine if this is even necessary
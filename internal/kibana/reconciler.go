@@ -15,12 +15,14 @@ import (
 	"github.com/openshift/elasticsearch-operator/internal/elasticsearch"
 	"github.com/openshift/elasticsearch-operator/internal/elasticsearch/esclient"
 	"github.com/openshift/elasticsearch-operator/internal/manifests/deployment"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/image"
 	"github.com/openshift/elasticsearch-operator/internal/manifests/pod"
 	"github.com/openshift/elasticsearch-operator/internal/manifests/secret"
 	"github.com/openshift/elasticsearch-operator/internal/manifests/service"
 	"github.com/openshift/elasticsearch-operator/internal/utils"
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -33,7 +35,7 @@ const (
 	expectedCLOKind              = "ClusterLogging"
 	expectedCLOName              = "instance"
 	expectedCLOKibana            = "kibana"
-	expectedCLONamespace         = "openshift-logging"
+	expectedCLONamespace         = constants.OpenshiftLoggingNamespace
 )
 
 var kibanaServiceAccountAnnotations = map[string]string{
@@ -41,14 +43,15 @@ var kibanaServiceAccountAnnotations = map[string]string{
 }
 
 func Reconcile(requestCluster *kibana.Kibana, requestClient client.Client, esClient esclient.Client, proxyConfig *configv1.Proxy, eoManagedCerts bool, ownerRef metav1.OwnerReference) error {
+	if requestCluster == nil {
+		return nil
+	}
+
 	clusterKibanaRequest := KibanaRequest{
 		client:   requestClient,
 		cluster:  requestCluster,
 		esClient: esClient,
-	}
-
-	if clusterKibanaRequest.cluster == nil {
-		return nil
+		ll:       utils.NewReconcileLogger(requestCluster.Name, requestCluster.Namespace),
 	}
 
 	if eoManagedCerts {
@@ -225,6 +228,8 @@ func (clusterRequest *KibanaRequest) createOrUpdateKibanaDeployment(proxyConfig
 		kibanaTrustBundle,
 	)
 
+	kibanaPodSpec.Affinity.PodAffinity = newClientNodeAffinity(clusterRequest.cluster.Spec.ClientNodeAffinity, clusterName)
+
 	kibanaDeployment := NewDeployment(
 		"kibana",
 		clusterRequest.cluster.Namespace,
@@ -316,6 +321,9 @@ func compareDeployments(current, desired *apps.Deployment) bool {
 	if *current.Spec.Replicas != *desired.Spec.Replicas {
 		return false
 	}
+	if !equality.Semantic.DeepEqual(current.Spec.Template.Spec.Affinity, desired.Spec.Template.Spec.Affinity) {
+		return false
+	}
 
 	currentTrustedCAHash := current.Spec.Template.ObjectMeta.Annotations[constants.TrustedCABundleHashName]
 	desiredTrustedCAHash := desired.Spec.Template.ObjectMeta.Annotations[constants.TrustedCABundleHashName]
@@ -364,6 +372,10 @@ func mutateDeployment(current *apps.Deployment, desired *apps.Deployment) {
 		*current.Spec.Replicas = *desired.Spec.Replicas
 	}
 
+	if !equality.Semantic.DeepEqual(current.Spec.Template.Spec.Affinity, desired.Spec.Template.Spec.Affinity) {
+		current.Spec.Template.Spec.Affinity = desired.Spec.Template.Spec.Affinity
+	}
+
 	currentTrustedCAHash := current.Spec.Template.ObjectMeta.Annotations[constants.TrustedCABundleHashName]
 	desiredTrustedCAHash := desired.Spec.Template.ObjectMeta.Annotations[constants.TrustedCABundleHashName]
 	if currentTrustedCAHash != desiredTrustedCAHash {
@@ -417,11 +429,11 @@ func (clusterRequest *KibanaRequest) createOrUpdateKibanaService() error {
 }
 
 func getImage() string {
-	return utils.LookupEnvWithDefault("KIBANA_IMAGE", kibanaDefaultImage)
+	return image.Resolve(utils.LookupEnvWithDefault("KIBANA_IMAGE", kibanaDefaultImage))
 }
 
 func getProxyImage() string {
-	return utils.LookupEnvWithDefault("PROXY_IMAGE", kibanaProxyDefaultImage)
+	return image.Resolve(utils.LookupEnvWithDefault("PROXY_IMAGE", kibanaProxyDefaultImage))
 }
 
 func newKibanaPodSpec(cluster *KibanaRequest, elasticsearchName string, proxyConfig *configv1.Proxy,
@@ -623,6 +635,44 @@ func newKibanaPodSpec(cluster *KibanaRequest, elasticsearchName string, proxyCon
 	return *kibanaPodSpec
 }
 
+// newClientNodeAffinity returns the affinity of the Kibana pods toward the client nodes
+// of the Elasticsearch cluster or nil if no co-location is requested
+func newClientNodeAffinity(spec *kibana.KibanaClientNodeAffinitySpec, clusterName string) *v1.PodAffinity {
+	if spec == nil {
+		return nil
+	}
+
+	topologyKey := spec.TopologyKey
+	if topologyKey == "" {
+		topologyKey = defaultClientNodeTopologyKey
+	}
+
+	term := v1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				"cluster-name":   clusterName,
+				"es-node-client": "true",
+			},
+		},
+		TopologyKey: topologyKey,
+	}
+
+	if spec.Type == kibana.KibanaAffinityRequired {
+		return &v1.PodAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{term},
+		}
+	}
+
+	return &v1.PodAffinity{
+		PreferredDuringSchedulingIgnoredDuringExecution: []v1.WeightedPodAffinityTerm{
+			{
+				Weight:          100,
+				PodAffinityTerm: term,
+			},
+		},
+	}
+}
+
 func getOwnerRef(v *kibana.Kibana) metav1.OwnerReference {
 	trueVar := true
 	return metav1.OwnerReference{
@@ -417,6 +417,62 @@ func TestDeploymentDifferentWithProxyEnvVar(t *testing.T) {
 	}
 }
 
+func TestNewClientNodeAffinity(t *testing.T) {
+	if affinity := newClientNodeAffinity(nil, "elasticsearch"); affinity != nil {
+		t.Errorf("Exp. no client node affinity by default but got %v", affinity)
+	}
+
+	affinity := newClientNodeAffinity(&kibana.KibanaClientNodeAffinitySpec{}, "elasticsearch")
+	if affinity == nil || len(affinity.PreferredDuringSchedulingIgnoredDuringExecution) != 1 || len(affinity.RequiredDuringSchedulingIgnoredDuringExecution) != 0 {
+		t.Fatalf("Exp. a preferred client node affinity by default but got %v", affinity)
+	}
+
+	term := affinity.PreferredDuringSchedulingIgnoredDuringExecution[0].PodAffinityTerm
+	expLabels := map[string]string{"cluster-name": "elasticsearch", "es-node-client": "true"}
+	if !reflect.DeepEqual(term.LabelSelector.MatchLabels, expLabels) {
+		t.Errorf("Exp. the affinity to select the client nodes %v but was %v", expLabels, term.LabelSelector.MatchLabels)
+	}
+	if term.TopologyKey != defaultClientNodeTopologyKey {
+		t.Errorf("Exp. the topology key %q but was %q", defaultClientNodeTopologyKey, term.TopologyKey)
+	}
+
+	affinity = newClientNodeAffinity(&kibana.KibanaClientNodeAffinitySpec{
+		Type:        kibana.KibanaAffinityRequired,
+		TopologyKey: "topology.kubernetes.io/zone",
+	}, "elasticsearch")
+	if affinity == nil || len(affinity.RequiredDuringSchedulingIgnoredDuringExecution) != 1 {
+		t.Fatalf("Exp. a required client node affinity but got %v", affinity)
+	}
+	if key := affinity.RequiredDuringSchedulingIgnoredDuringExecution[0].TopologyKey; key != "topology.kubernetes.io/zone" {
+		t.Errorf("Exp. the configured topology key but was %q", key)
+	}
+}
+
+func TestDeploymentDifferentWithClientNodeAffinity(t *testing.T) {
+	clusterRequest := &KibanaRequest{
+		cluster: &kibana.Kibana{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "test-namespace",
+			},
+		},
+	}
+
+	lhsDeployment := NewDeployment("kibana", "test-namespace", "kibana", "kibana", 1, newKibanaPodSpec(clusterRequest, "test-app-name", nil, nil))
+
+	rhsPodSpec := newKibanaPodSpec(clusterRequest, "test-app-name", nil, nil)
+	rhsPodSpec.Affinity.PodAffinity = newClientNodeAffinity(&kibana.KibanaClientNodeAffinitySpec{}, "elasticsearch")
+	rhsDeployment := NewDeployment("kibana", "test-namespace", "kibana", "kibana", 1, rhsPodSpec)
+
+	if compareDeployments(lhsDeployment, rhsDeployment) {
+		t.Error("Exp. the deployments to differ due to the client node affinity")
+	}
+
+	mutateDeployment(lhsDeployment, rhsDeployment)
+	if !reflect.DeepEqual(lhsDeployment, rhsDeployment) {
+		t.Errorf("Exp. the lhs affinity to be updated to match rhs affinity")
+	}
+}
+
 func checkKibanaProxyEnvVar(t *testing.T, podSpec v1.PodSpec, name string, value string) {
 	env := podSpec.Containers[1].Env
 	found := false
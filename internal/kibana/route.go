@@ -7,7 +7,7 @@ import (
 	"strings"
 
 	"github.com/ViaQ/logerr/kverrors"
-	"github.com/ViaQ/logerr/log"
+	"github.com/openshift/elasticsearch-operator/internal/constants"
 	"github.com/openshift/elasticsearch-operator/internal/manifests/console"
 	"github.com/openshift/elasticsearch-operator/internal/manifests/route"
 	"github.com/openshift/elasticsearch-operator/internal/utils"
@@ -18,7 +18,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-const KibanaConsoleLinkName = "kibana-public-url"
+const KibanaConsoleLinkName = constants.KibanaConsoleLinkName
 
 // GetRouteURL retrieves the route URL from a given route and namespace
 func (clusterRequest *KibanaRequest) GetRouteURL(routeName string) (string, error) {
@@ -26,7 +26,7 @@ func (clusterRequest *KibanaRequest) GetRouteURL(routeName string) (string, erro
 	r, err := route.Get(context.TODO(), clusterRequest.client, key)
 	if err != nil {
 		if !apierrors.IsNotFound(kverrors.Root(err)) {
-			log.Error(err, "Failed to check for kibana object")
+			clusterRequest.L().Error(err, "Failed to check for kibana object")
 		}
 		return "", err
 	}
@@ -40,7 +40,7 @@ func (clusterRequest *KibanaRequest) createOrUpdateKibanaRoute() error {
 	fp := utils.GetWorkingDirFilePath("ca.crt")
 	caCert, err := ioutil.ReadFile(fp)
 	if err != nil {
-		log.Info("could not read CA certificate for kibana route",
+		clusterRequest.L().Info("could not read CA certificate for kibana route",
 			"filePath", fp,
 			"cause", err)
 	}
@@ -107,7 +107,7 @@ func (clusterRequest *KibanaRequest) createOrUpdateKibanaConsoleExternalLogLink(
 	}
 
 	consoleExternalLogLink := console.NewConsoleExternalLogLink(
-		"kibana",
+		constants.KibanaConsoleExternalLogLinkName,
 		"Show in Kibana",
 		strings.Join([]string{
 			kibanaURL,
@@ -0,0 +1,25 @@
+package apply
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var serverSideApply bool
+
+// SetServerSideApply selects server side apply instead of get-mutate-update for the
+// manifest helpers supporting it, i.e. the configmap and deployment CreateOrUpdate.
+func SetServerSideApply(enabled bool) {
+	serverSideApply = enabled
+}
+
+// ServerSideApply returns true if the manifest helpers use server side apply.
+func ServerSideApply() bool {
+	return serverSideApply
+}
+
+// PatchOptions returns the options to apply an object on behalf of the field manager.
+// Ownership is forced, thus the operator takes over the fields it sets from any other
+// manager, like an update would overwrite them, while leaving all other fields alone.
+func PatchOptions(fieldManager string) []client.PatchOption {
+	return []client.PatchOption{client.FieldOwner(fieldManager), client.ForceOwnership}
+}
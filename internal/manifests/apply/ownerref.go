@@ -0,0 +1,89 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ViaQ/logerr/kverrors"
+	"github.com/ViaQ/logerr/log"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var strictOwnerReferences bool
+
+// SetStrictOwnerReferences makes the clients guarding owner references fail creating
+// objects without a controller reference instead of only logging a warning, e.g. in tests
+func SetStrictOwnerReferences(enabled bool) {
+	strictOwnerReferences = enabled
+}
+
+// ownerReferenceGuard is a client asserting the objects it creates or applies in the
+// namespace of their owner are controlled by it, thus garbage collected with it
+type ownerReferenceGuard struct {
+	client.Client
+	namespace string
+}
+
+// GuardOwnerReferences returns a client asserting the objects created or server side
+// applied in the namespace have a controller reference. Cluster scoped objects and objects in other namespaces
+// cannot be owned by a namespaced owner and are not checked, as are persistent volume
+// claims whose data outlives the owner.
+func GuardOwnerReferences(c client.Client, namespace string) client.Client {
+	return &ownerReferenceGuard{Client: c, namespace: namespace}
+}
+
+// Create asserts the object has a controller reference before creating it
+func (g *ownerReferenceGuard) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	if err := g.checkOwnerReference(obj); err != nil {
+		return err
+	}
+	return g.Client.Create(ctx, obj, opts...)
+}
+
+// Patch asserts the object has a controller reference before server side applying it,
+// which creates missing objects as well. Other patches only change existing objects.
+func (g *ownerReferenceGuard) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch.Type() == types.ApplyPatchType {
+		if err := g.checkOwnerReference(obj); err != nil {
+			return err
+		}
+	}
+	return g.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (g *ownerReferenceGuard) checkOwnerReference(obj runtime.Object) error {
+	if _, ok := obj.(*corev1.PersistentVolumeClaim); ok {
+		return nil
+	}
+
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return kverrors.Wrap(err, "failed to access object metadata")
+	}
+	if accessor.GetNamespace() == "" || accessor.GetNamespace() != g.namespace {
+		return nil
+	}
+	if metav1.GetControllerOf(accessor) != nil {
+		return nil
+	}
+
+	kind := fmt.Sprintf("%T", obj)
+	if strictOwnerReferences {
+		return kverrors.New("missing controller owner reference",
+			"kind", kind,
+			"name", accessor.GetName(),
+			"namespace", accessor.GetNamespace(),
+		)
+	}
+	log.Info("Warning: creating object without controller owner reference, it is not removed with the cluster",
+		"kind", kind,
+		"name", accessor.GetName(),
+		"namespace", accessor.GetNamespace(),
+	)
+	return nil
+}
@@ -0,0 +1,76 @@
+package apply_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift/elasticsearch-operator/internal/manifests/apply"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGuardOwnerReferences(t *testing.T) {
+	apply.SetStrictOwnerReferences(true)
+	defer apply.SetStrictOwnerReferences(false)
+
+	controller := true
+	owned := metav1.ObjectMeta{
+		Name:      "owned",
+		Namespace: "openshift-logging",
+		OwnerReferences: []metav1.OwnerReference{
+			{APIVersion: "logging.openshift.io/v1", Kind: "Elasticsearch", Name: "elasticsearch", Controller: &controller},
+		},
+	}
+	notControlled := *owned.DeepCopy()
+	notControlled.Name = "not-controlled"
+	notControlled.OwnerReferences[0].Controller = nil
+
+	tests := []struct {
+		desc    string
+		obj     runtime.Object
+		wantErr bool
+	}{
+		{desc: "controlled object", obj: &corev1.ConfigMap{ObjectMeta: owned}},
+		{desc: "unowned object", obj: &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "unowned", Namespace: "openshift-logging"}}, wantErr: true},
+		{desc: "owned but not controlled object", obj: &corev1.Service{ObjectMeta: notControlled}, wantErr: true},
+		{desc: "object in another namespace", obj: &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "dashboard", Namespace: "openshift-config-managed"}}},
+		{desc: "cluster scoped object", obj: &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch-metrics"}}},
+		{desc: "persistent volume claim", obj: &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "openshift-logging"}}},
+	}
+	for _, test := range tests {
+		c := apply.GuardOwnerReferences(fake.NewFakeClient(), "openshift-logging")
+		if err := c.Create(context.TODO(), test.obj); (err != nil) != test.wantErr {
+			t.Errorf("%s: got err: %v, want error %t", test.desc, err, test.wantErr)
+		}
+	}
+
+	apply.SetStrictOwnerReferences(false)
+	c := apply.GuardOwnerReferences(fake.NewFakeClient(), "openshift-logging")
+	if err := c.Create(context.TODO(), &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "unowned", Namespace: "openshift-logging"}}); err != nil {
+		t.Errorf("Exp. only a warning for unowned objects if not strict but got %v", err)
+	}
+}
+
+func TestGuardOwnerReferencesPatch(t *testing.T) {
+	apply.SetStrictOwnerReferences(true)
+	defer apply.SetStrictOwnerReferences(false)
+
+	unowned := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "unowned", Namespace: "openshift-logging"}}
+	c := apply.GuardOwnerReferences(fake.NewFakeClient(unowned.DeepCopy()), "openshift-logging")
+
+	if err := c.Patch(context.TODO(), unowned.DeepCopy(), client.Apply, client.FieldOwner("elasticsearch-operator")); err == nil {
+		t.Error("Exp. an error server side applying an unowned object")
+	}
+
+	current := unowned.DeepCopy()
+	patched := current.DeepCopy()
+	patched.Data = map[string]string{"key": "value"}
+	if err := c.Patch(context.TODO(), patched, client.MergeFrom(current)); err != nil {
+		t.Errorf("Exp. merge patches of existing objects not to be checked but got %v", err)
+	}
+}
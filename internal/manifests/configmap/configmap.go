@@ -8,6 +8,7 @@ import (
 
 	"github.com/ViaQ/logerr/kverrors"
 	"github.com/ViaQ/logerr/log"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/apply"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -38,24 +39,36 @@ func Get(ctx context.Context, c client.Client, key client.ObjectKey) (*corev1.Co
 	return cm, nil
 }
 
-// GetDataSHA256 returns the sha256 checksum of the confimap data keys
+// GetDataSHA256 returns the sha256 checksum of the confimap data keys. Returns an
+// empty hash if the configmap is missing or holds no data besides the excluded keys.
 func GetDataSHA256(ctx context.Context, c client.Client, key client.ObjectKey, excludeKeys []string) string {
-	hash := ""
-
 	cm, err := Get(ctx, c, key)
 	if err != nil {
-		return hash
+		return ""
 	}
 
+	return dataSHA256(cm.Data, excludeKeys)
+}
+
+// DataSHA256 returns the sha256 checksum of the given configmap data keys like GetDataSHA256
+func DataSHA256(data map[string]string, excludeKeys []string) string {
+	return dataSHA256(data, excludeKeys)
+}
+
+// dataSHA256 returns the checksums of the data values concatenated in the order
+// of their sorted keys, thus independent of the map iteration order
+func dataSHA256(data map[string]string, excludeKeys []string) string {
+	hash := ""
+
 	dataHashes := make(map[string][32]byte)
 outer:
-	for key, data := range cm.Data {
+	for key, value := range data {
 		for _, excludeKey := range excludeKeys {
 			if key == excludeKey {
 				continue outer
 			}
 		}
-		dataHashes[key] = sha256.Sum256([]byte(data))
+		dataHashes[key] = sha256.Sum256([]byte(value))
 	}
 
 	sortedKeys := []string{}
@@ -85,11 +98,52 @@ func Create(ctx context.Context, c client.Client, cm *corev1.ConfigMap) error {
 	return nil
 }
 
+// FieldManager is the field manager of the configmaps applied server side
+const FieldManager = "elasticsearch-operator-configmap"
+
+// Apply creates or updates the given configmap using server side apply, so that the
+// operator owns only the fields it sets. An existing configmap is left untouched if the
+// provided comparison func detects no changes. Returns true if an existing configmap changed.
+func Apply(ctx context.Context, c client.Client, cm *corev1.ConfigMap, equal EqualityFunc) (bool, error) {
+	current := &corev1.ConfigMap{}
+	key := client.ObjectKey{Name: cm.Name, Namespace: cm.Namespace}
+	exists := true
+	if err := c.Get(ctx, key, current); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, kverrors.Wrap(err, "failed to get configmap",
+				"name", cm.Name,
+				"namespace", cm.Namespace,
+			)
+		}
+		exists = false
+	}
+
+	if exists && equal(current, cm) {
+		return false, nil
+	}
+
+	if err := c.Patch(ctx, cm, client.Apply, apply.PatchOptions(FieldManager)...); err != nil {
+		return false, kverrors.Wrap(err, "failed to apply configmap",
+			"name", cm.Name,
+			"namespace", cm.Namespace,
+			"field_manager", FieldManager,
+		)
+	}
+
+	return exists, nil
+}
+
 // CreateOrUpdate attempts first to create the given configmap. If the
 // configmap already exists and the provided comparison func detects any changes
 // an update is attempted. Updates are retried with backoff (See retry.DefaultRetry).
+// If server side apply is selected the configmap is applied instead, where the
+// server merges the desired fields in place of the mutate func.
 // Returns on failure an non-nil error.
 func CreateOrUpdate(ctx context.Context, c client.Client, cm *corev1.ConfigMap, equal EqualityFunc, mutate MutateFunc) (bool, error) {
+	if apply.ServerSideApply() {
+		return Apply(ctx, c, cm, equal)
+	}
+
 	err := Create(ctx, c, cm)
 	if err == nil {
 		return false, nil
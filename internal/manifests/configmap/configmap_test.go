@@ -0,0 +1,133 @@
+package configmap
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/ViaQ/logerr/kverrors"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/apply"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// applyClient records the server side applies, which the fake client does not support
+type applyClient struct {
+	client.Client
+	patchType    types.PatchType
+	fieldManager string
+	force        bool
+	patches      int
+	err          error
+}
+
+func (c *applyClient) Patch(_ context.Context, _ runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	options := &client.PatchOptions{}
+	options.ApplyOptions(opts)
+
+	c.patchType = patch.Type()
+	c.fieldManager = options.FieldManager
+	c.force = options.Force != nil && *options.Force
+	c.patches++
+	return c.err
+}
+
+func TestGetDataSHA256MissingConfigMap(t *testing.T) {
+	c := fake.NewFakeClient()
+	key := client.ObjectKey{Name: "elasticsearch", Namespace: "openshift-logging"}
+
+	if hash := GetDataSHA256(context.TODO(), c, key, nil); hash != "" {
+		t.Errorf("Exp. an empty hash for a missing configmap but got %q", hash)
+	}
+}
+
+func TestDataSHA256(t *testing.T) {
+	many := map[string]string{}
+	reversed := map[string]string{}
+	for i := 0; i < 20; i++ {
+		many[fmt.Sprintf("key-%d", i)] = fmt.Sprintf("value-%d", i)
+	}
+	for i := 19; i >= 0; i-- {
+		reversed[fmt.Sprintf("key-%d", i)] = fmt.Sprintf("value-%d", i)
+	}
+
+	if hash := dataSHA256(nil, nil); hash != "" {
+		t.Errorf("Exp. an empty hash for nil data but got %q", hash)
+	}
+	if hash := dataSHA256(map[string]string{"index_settings": "x"}, []string{"index_settings"}); hash != "" {
+		t.Errorf("Exp. an empty hash for excluded data only but got %q", hash)
+	}
+
+	single := dataSHA256(map[string]string{"elasticsearch.yml": "a"}, nil)
+	if single == "" || single != dataSHA256(map[string]string{"elasticsearch.yml": "a"}, nil) {
+		t.Errorf("Exp. a stable non-empty hash for a single key but got %q", single)
+	}
+	if single != dataSHA256(map[string]string{"elasticsearch.yml": "a", "index_settings": "x"}, []string{"index_settings"}) {
+		t.Error("Exp. the excluded keys to not change the hash")
+	}
+
+	hash := dataSHA256(many, nil)
+	for i := 0; i < 10; i++ {
+		if got := dataSHA256(many, nil); got != hash {
+			t.Fatalf("Exp. a stable hash for many keys, got %q and %q", hash, got)
+		}
+	}
+	if got := dataSHA256(reversed, nil); got != hash {
+		t.Errorf("Exp. the hash to be independent of the insertion order, got %q and %q", hash, got)
+	}
+}
+
+func TestCreateOrUpdate(t *testing.T) {
+	c := fake.NewFakeClient(New("elasticsearch", "openshift-logging", nil, map[string]string{"a": "1"}))
+
+	desired := New("elasticsearch", "openshift-logging", nil, map[string]string{"a": "2"})
+	updated, err := CreateOrUpdate(context.TODO(), c, desired, DataEqual, MutateDataOnly)
+	if err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+	if !updated {
+		t.Error("Exp. the configmap to be updated")
+	}
+
+	current := &corev1.ConfigMap{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Name: "elasticsearch", Namespace: "openshift-logging"}, current); err != nil {
+		t.Fatalf("failed to get configmap: %s", err)
+	}
+	if !reflect.DeepEqual(current.Data, desired.Data) {
+		t.Errorf("Exp. data %v but got %v", desired.Data, current.Data)
+	}
+}
+
+func TestCreateOrUpdateServerSideApply(t *testing.T) {
+	apply.SetServerSideApply(true)
+	defer apply.SetServerSideApply(false)
+
+	c := &applyClient{Client: fake.NewFakeClient(New("elasticsearch", "openshift-logging", nil, map[string]string{"a": "1"}))}
+
+	desired := New("elasticsearch", "openshift-logging", nil, map[string]string{"a": "2"})
+	updated, err := CreateOrUpdate(context.TODO(), c, desired, DataEqual, MutateDataOnly)
+	if err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+	if !updated {
+		t.Error("Exp. the applied configmap to be reported as updated")
+	}
+	if c.patchType != types.ApplyPatchType || c.fieldManager != FieldManager || !c.force {
+		t.Errorf("Exp. a forced apply patch by %q but got %q by %q (force: %t)", FieldManager, c.patchType, c.fieldManager, c.force)
+	}
+
+	unchanged := New("elasticsearch", "openshift-logging", nil, map[string]string{"a": "1"})
+	if updated, err := CreateOrUpdate(context.TODO(), c, unchanged, DataEqual, MutateDataOnly); err != nil || updated || c.patches != 1 {
+		t.Errorf("Exp. an equal configmap to not be applied but got updated: %t, patches: %d, err: %v", updated, c.patches, err)
+	}
+
+	c.err = apierrors.NewInternalError(kverrors.New("apiserver unavailable"))
+	if _, err := CreateOrUpdate(context.TODO(), c, desired, DataEqual, MutateDataOnly); !apierrors.IsInternalError(kverrors.Root(err)) {
+		t.Errorf("Exp. the apply error to be surfaced but got %v", err)
+	}
+}
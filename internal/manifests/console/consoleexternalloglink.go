@@ -9,6 +9,7 @@ import (
 	consolev1 "github.com/openshift/api/console/v1"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -90,3 +91,20 @@ func MutateConsoleExternalLogLink(current, desired *consolev1.ConsoleExternalLog
 	current.Spec.HrefTemplate = desired.Spec.HrefTemplate
 	current.Spec.Text = desired.Spec.Text
 }
+
+// DeleteConsoleExternalLogLink attempts to delete a consoleexternalloglink if existing or returns an error.
+func DeleteConsoleExternalLogLink(ctx context.Context, c client.Client, key client.ObjectKey) error {
+	cll := &consolev1.ConsoleExternalLogLink{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: key.Name,
+		},
+	}
+
+	if err := c.Delete(ctx, cll, &client.DeleteOptions{}); err != nil {
+		return kverrors.Wrap(err, "failed to delete consoleexternalloglink",
+			"name", cll.Name,
+		)
+	}
+
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	consolev1 "github.com/openshift/api/console/v1"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -101,3 +102,20 @@ func ConsoleLinksEqual(current, desired *consolev1.ConsoleLink) bool {
 func MutateConsoleLinkSpecOnly(current, desired *consolev1.ConsoleLink) {
 	current.Spec = desired.Spec
 }
+
+// DeleteConsoleLink attempts to delete a consolelink if existing or returns an error.
+func DeleteConsoleLink(ctx context.Context, c client.Client, key client.ObjectKey) error {
+	cl := &consolev1.ConsoleLink{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: key.Name,
+		},
+	}
+
+	if err := c.Delete(ctx, cl, &client.DeleteOptions{}); err != nil {
+		return kverrors.Wrap(err, "failed to delete consolelink",
+			"name", cl.Name,
+		)
+	}
+
+	return nil
+}
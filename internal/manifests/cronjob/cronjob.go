@@ -112,5 +112,6 @@ func Equal(current, desired *batchv1beta1.CronJob) bool {
 // Mutate is a default mutation function for cronjobs
 // that copies only mutable fields from desired to current.
 func Mutate(current, desired *batchv1beta1.CronJob) {
+	current.Labels = desired.Labels
 	current.Spec = desired.Spec
 }
@@ -5,10 +5,12 @@ import (
 
 	"github.com/ViaQ/logerr/kverrors"
 	"github.com/ViaQ/logerr/log"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/apply"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -47,7 +49,9 @@ func Create(ctx context.Context, c client.Client, dpl *appsv1.Deployment) error
 	return nil
 }
 
-// Update will update an existing deployment if compare func returns true or else leave it unchanged
+// Update will update an existing deployment unless the equality func reports the current
+// deployment on the api server equal to the desired one, in which case it is left unchanged.
+// Equality funcs of partial updates, e.g. pausing, compare only the fields mutated.
 func Update(ctx context.Context, c client.Client, dpl *appsv1.Deployment, equal EqualityFunc, mutate MutateFunc) error {
 	current := &appsv1.Deployment{}
 	key := client.ObjectKey{Name: dpl.Name, Namespace: dpl.Namespace}
@@ -66,6 +70,11 @@ func Update(ctx context.Context, c client.Client, dpl *appsv1.Deployment, equal
 				return err
 			}
 
+			// a conflicting writer may have applied the change already
+			if equal(current, dpl) {
+				return nil
+			}
+
 			mutate(current, dpl)
 			if err := c.Update(ctx, current); err != nil {
 				log.Error(err, "failed to update deployment", dpl.Name)
@@ -85,11 +94,48 @@ func Update(ctx context.Context, c client.Client, dpl *appsv1.Deployment, equal
 	return nil
 }
 
+// FieldManager is the field manager of the deployments applied server side
+const FieldManager = "elasticsearch-operator-deployment"
+
+// Apply creates or updates the given deployment using server side apply, so that the
+// operator owns only the fields it sets. An existing deployment is left untouched if the
+// provided comparison func detects no changes.
+func Apply(ctx context.Context, c client.Client, dpl *appsv1.Deployment, equal EqualityFunc) error {
+	current := &appsv1.Deployment{}
+	key := client.ObjectKey{Name: dpl.Name, Namespace: dpl.Namespace}
+	if err := c.Get(ctx, key, current); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return kverrors.Wrap(err, "failed to get deployment",
+				"name", dpl.Name,
+				"namespace", dpl.Namespace,
+			)
+		}
+	} else if equal(current, dpl) {
+		return nil
+	}
+
+	if err := c.Patch(ctx, dpl, client.Apply, apply.PatchOptions(FieldManager)...); err != nil {
+		return kverrors.Wrap(err, "failed to apply deployment",
+			"name", dpl.Name,
+			"namespace", dpl.Namespace,
+			"field_manager", FieldManager,
+		)
+	}
+
+	return nil
+}
+
 // CreateOrUpdate attempts first to create the given deployment. If the
 // deployment already exists and the provided comparison func detects any changes
 // an update is attempted. Updates are retried with backoff (See retry.DefaultRetry).
+// If server side apply is selected the deployment is applied instead, where the
+// server merges the desired fields in place of the mutate func.
 // Returns on failure an non-nil error.
 func CreateOrUpdate(ctx context.Context, c client.Client, dpl *appsv1.Deployment, equal EqualityFunc, mutate MutateFunc) error {
+	if apply.ServerSideApply() {
+		return Apply(ctx, c, dpl, equal)
+	}
+
 	err := Create(ctx, c, dpl)
 	if err == nil {
 		return nil
@@ -119,6 +165,21 @@ func Delete(ctx context.Context, c client.Client, key client.ObjectKey) error {
 	return nil
 }
 
+// DeleteForeground attempts to delete a k8s deployment in the foreground, i.e. the
+// deployment is removed only after its pods are gone, or returns an error.
+func DeleteForeground(ctx context.Context, c client.Client, key client.ObjectKey) error {
+	dpl := New(key.Name, key.Namespace, nil, 1).Build()
+
+	if err := c.Delete(ctx, dpl, client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil {
+		return kverrors.Wrap(err, "failed to delete deployment",
+			"name", dpl.Name,
+			"namespace", dpl.Namespace,
+		)
+	}
+
+	return nil
+}
+
 // List returns a list of deployments that match the given selector.
 func List(ctx context.Context, c client.Client, namespace string, selector map[string]string) ([]appsv1.Deployment, error) {
 	list := &appsv1.DeploymentList{}
@@ -137,12 +198,10 @@ func List(ctx context.Context, c client.Client, namespace string, selector map[s
 
 // ListReplicaSets returns the replica sets for a deployment and given selector.
 func ListReplicaSets(ctx context.Context, c client.Client, name, namespace string, selector map[string]string) ([]appsv1.ReplicaSet, error) {
-	selector["component"] = name
-
 	list := &appsv1.ReplicaSetList{}
 	opts := []client.ListOption{
 		client.InNamespace(namespace),
-		client.MatchingLabels(selector),
+		client.MatchingLabels(componentSelector(name, selector)),
 	}
 	if err := c.List(ctx, list, opts...); err != nil {
 		return nil, kverrors.Wrap(err, "failed to list deployment replica sets",
@@ -156,12 +215,10 @@ func ListReplicaSets(ctx context.Context, c client.Client, name, namespace strin
 
 // ListPods returns the replica sets for a deployment and given selector.
 func ListPods(ctx context.Context, c client.Client, name, namespace string, selector map[string]string) ([]corev1.Pod, error) {
-	selector["component"] = name
-
 	list := &corev1.PodList{}
 	opts := []client.ListOption{
 		client.InNamespace(namespace),
-		client.MatchingLabels(selector),
+		client.MatchingLabels(componentSelector(name, selector)),
 	}
 	if err := c.List(ctx, list, opts...); err != nil {
 		return nil, kverrors.Wrap(err, "failed to list deployment pods",
@@ -172,3 +229,14 @@ func ListPods(ctx context.Context, c client.Client, name, namespace string, sele
 
 	return list.Items, nil
 }
+
+// componentSelector returns a copy of the given selector matching the component of the
+// deployment, leaving the caller's map untouched for reuse.
+func componentSelector(name string, selector map[string]string) map[string]string {
+	s := make(map[string]string, len(selector)+1)
+	for k, v := range selector {
+		s[k] = v
+	}
+	s["component"] = name
+	return s
+}
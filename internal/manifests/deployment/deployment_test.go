@@ -0,0 +1,153 @@
+package deployment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ViaQ/logerr/kverrors"
+	"github.com/google/go-cmp/cmp"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/apply"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// applyClient records the server side applies, which the fake client does not support
+type applyClient struct {
+	client.Client
+	patchType    types.PatchType
+	fieldManager string
+	force        bool
+	patches      int
+	err          error
+}
+
+func (c *applyClient) Patch(_ context.Context, _ runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	options := &client.PatchOptions{}
+	options.ApplyOptions(opts)
+
+	c.patchType = patch.Type()
+	c.fieldManager = options.FieldManager
+	c.force = options.Force != nil && *options.Force
+	c.patches++
+	return c.err
+}
+
+func TestListPodsKeepsSelector(t *testing.T) {
+	newPod := func(name, component string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "aNamespace",
+				Labels: map[string]string{
+					"cluster-name": "elasticsearch",
+					"component":    component,
+				},
+			},
+		}
+	}
+	c := fake.NewFakeClient(newPod("pod-a", "node-a"), newPod("pod-b", "node-b"))
+
+	selector := map[string]string{"cluster-name": "elasticsearch"}
+	want := map[string]string{"cluster-name": "elasticsearch"}
+
+	for _, name := range []string{"node-a", "node-b"} {
+		pods, err := ListPods(context.TODO(), c, name, "aNamespace", selector)
+		if err != nil {
+			t.Fatalf("got err: %s, want nil", err)
+		}
+		if len(pods) != 1 || pods[0].Labels["component"] != name {
+			t.Errorf("got pods %v, want only the pod of component %q", pods, name)
+		}
+		if diff := cmp.Diff(want, selector); diff != "" {
+			t.Errorf("selector mutated (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestCreateOrUpdate(t *testing.T) {
+	c := fake.NewFakeClient(New("kibana", "openshift-logging", nil, 1).Build())
+
+	equal := func(current, desired *appsv1.Deployment) bool {
+		return *current.Spec.Replicas == *desired.Spec.Replicas
+	}
+	mutate := func(current, desired *appsv1.Deployment) {
+		current.Spec.Replicas = desired.Spec.Replicas
+	}
+
+	if err := CreateOrUpdate(context.TODO(), c, New("kibana", "openshift-logging", nil, 2).Build(), equal, mutate); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+
+	current, err := Get(context.TODO(), c, client.ObjectKey{Name: "kibana", Namespace: "openshift-logging"})
+	if err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+	if *current.Spec.Replicas != 2 {
+		t.Errorf("Exp. the deployment to be updated to 2 replicas but got %d", *current.Spec.Replicas)
+	}
+}
+
+func TestUpdateOnlyMutatesUnequalDeployments(t *testing.T) {
+	c := fake.NewFakeClient(New("kibana", "openshift-logging", nil, 1).Build())
+	key := client.ObjectKey{Name: "kibana", Namespace: "openshift-logging"}
+
+	mutated := false
+	mutate := func(current, _ *appsv1.Deployment) {
+		mutated = true
+		current.Spec.Paused = true
+	}
+
+	equal := func(current, _ *appsv1.Deployment) bool {
+		return !current.Spec.Paused
+	}
+	if err := Update(context.TODO(), c, New("kibana", "openshift-logging", nil, 1).Build(), equal, mutate); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+	if current, _ := Get(context.TODO(), c, key); mutated || current.Spec.Paused {
+		t.Error("Exp. an equal deployment to be left unchanged")
+	}
+
+	equal = func(current, _ *appsv1.Deployment) bool {
+		return current.Spec.Paused
+	}
+	if err := Update(context.TODO(), c, New("kibana", "openshift-logging", nil, 1).Build(), equal, mutate); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+	if current, _ := Get(context.TODO(), c, key); !mutated || !current.Spec.Paused {
+		t.Error("Exp. an unequal deployment to be updated")
+	}
+}
+
+func TestCreateOrUpdateServerSideApply(t *testing.T) {
+	apply.SetServerSideApply(true)
+	defer apply.SetServerSideApply(false)
+
+	c := &applyClient{Client: fake.NewFakeClient()}
+	equal := func(current, desired *appsv1.Deployment) bool { return true }
+	dpl := New("kibana", "openshift-logging", nil, 1).Build()
+
+	if err := CreateOrUpdate(context.TODO(), c, dpl, equal, nil); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+	if c.patchType != types.ApplyPatchType || c.fieldManager != FieldManager || !c.force {
+		t.Errorf("Exp. a forced apply patch by %q but got %q by %q (force: %t)", FieldManager, c.patchType, c.fieldManager, c.force)
+	}
+
+	c.Client = fake.NewFakeClient(New("kibana", "openshift-logging", nil, 1).Build())
+	if err := CreateOrUpdate(context.TODO(), c, dpl, equal, nil); err != nil || c.patches != 1 {
+		t.Errorf("Exp. an equal deployment to not be applied but got patches: %d, err: %v", c.patches, err)
+	}
+
+	c.err = apierrors.NewInternalError(kverrors.New("apiserver unavailable"))
+	unequal := func(current, desired *appsv1.Deployment) bool { return false }
+	if err := CreateOrUpdate(context.TODO(), c, dpl, unequal, nil); !apierrors.IsInternalError(kverrors.Root(err)) {
+		t.Errorf("Exp. the apply error to be surfaced but got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package hpa
+
+import (
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Builder represents the type to build HorizontalPodAutoscaler objects
+type Builder struct {
+	hpa *autoscalingv2beta2.HorizontalPodAutoscaler
+}
+
+// New returns a new Builder for HorizontalPodAutoscaler objects
+func New(name, namespace string, labels map[string]string) *Builder {
+	return &Builder{hpa: newHorizontalPodAutoscaler(name, namespace, labels)}
+}
+
+func newHorizontalPodAutoscaler(name, namespace string, labels map[string]string) *autoscalingv2beta2.HorizontalPodAutoscaler {
+	return &autoscalingv2beta2.HorizontalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "HorizontalPodAutoscaler",
+			APIVersion: autoscalingv2beta2.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+	}
+}
+
+// Build returns the final HorizontalPodAutoscaler object
+func (b *Builder) Build() *autoscalingv2beta2.HorizontalPodAutoscaler { return b.hpa }
+
+// WithAnnotations sets the object meta annotations
+func (b *Builder) WithAnnotations(a map[string]string) *Builder {
+	b.hpa.Annotations = a
+	return b
+}
+
+// WithScaleTargetRef sets the object scaled by the autoscaler
+func (b *Builder) WithScaleTargetRef(apiVersion, kind, name string) *Builder {
+	b.hpa.Spec.ScaleTargetRef = autoscalingv2beta2.CrossVersionObjectReference{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Name:       name,
+	}
+	return b
+}
+
+// WithReplicas sets the lower and upper limit of the replicas the autoscaler scales to
+func (b *Builder) WithReplicas(minReplicas, maxReplicas int32) *Builder {
+	b.hpa.Spec.MinReplicas = &minReplicas
+	b.hpa.Spec.MaxReplicas = maxReplicas
+	return b
+}
+
+// WithMetrics sets the metrics the autoscaler computes the desired replicas from
+func (b *Builder) WithMetrics(m ...autoscalingv2beta2.MetricSpec) *Builder {
+	b.hpa.Spec.Metrics = m
+	return b
+}
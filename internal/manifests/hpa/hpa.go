@@ -0,0 +1,118 @@
+package hpa
+
+import (
+	"context"
+
+	"github.com/ViaQ/logerr/kverrors"
+	"github.com/ViaQ/logerr/log"
+
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// EqualityFunc is the type for functions that compare two horizontalpodautoscalers.
+// Return true if two horizontalpodautoscalers are equal.
+type EqualityFunc func(current, desired *autoscalingv2beta2.HorizontalPodAutoscaler) bool
+
+// MutateFunc is the type for functions that mutate the current horizontalpodautoscaler
+// by applying the values from the desired horizontalpodautoscaler.
+type MutateFunc func(current, desired *autoscalingv2beta2.HorizontalPodAutoscaler)
+
+// CreateOrUpdate attempts first to create the given horizontalpodautoscaler. If the
+// horizontalpodautoscaler already exists and the provided comparison func detects any changes
+// an update is attempted. Updates are retried with backoff (See retry.DefaultRetry).
+// Returns on failure an non-nil error.
+func CreateOrUpdate(ctx context.Context, c client.Client, hpa *autoscalingv2beta2.HorizontalPodAutoscaler, equal EqualityFunc, mutate MutateFunc) error {
+	err := c.Create(ctx, hpa)
+	if err == nil {
+		return nil
+	}
+
+	if !apierrors.IsAlreadyExists(kverrors.Root(err)) {
+		return kverrors.Wrap(err, "failed to create horizontalpodautoscaler",
+			"name", hpa.Name,
+			"namespace", hpa.Namespace,
+		)
+	}
+
+	current := &autoscalingv2beta2.HorizontalPodAutoscaler{}
+	key := client.ObjectKey{Name: hpa.Name, Namespace: hpa.Namespace}
+	err = c.Get(ctx, key, current)
+	if err != nil {
+		return kverrors.Wrap(err, "failed to get horizontalpodautoscaler",
+			"name", hpa.Name,
+			"namespace", hpa.Namespace,
+		)
+	}
+
+	if !equal(current, hpa) {
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := c.Get(ctx, key, current); err != nil {
+				log.Error(err, "failed to get horizontalpodautoscaler", hpa.Name)
+				return err
+			}
+
+			mutate(current, hpa)
+			if err := c.Update(ctx, current); err != nil {
+				log.Error(err, "failed to update horizontalpodautoscaler", hpa.Name)
+				return err
+			}
+			return nil
+		})
+		if err != nil {
+			return kverrors.Wrap(err, "failed to update horizontalpodautoscaler",
+				"name", hpa.Name,
+				"namespace", hpa.Namespace,
+			)
+		}
+		return nil
+	}
+
+	return nil
+}
+
+// Delete attempts to delete a k8s horizontalpodautoscaler if existing or returns an error.
+func Delete(ctx context.Context, c client.Client, key client.ObjectKey) error {
+	hpa := New(key.Name, key.Namespace, nil).Build()
+
+	if err := c.Delete(ctx, hpa, &client.DeleteOptions{}); err != nil {
+		return kverrors.Wrap(err, "failed to delete horizontalpodautoscaler",
+			"name", hpa.Name,
+			"namespace", hpa.Namespace,
+		)
+	}
+
+	return nil
+}
+
+// List returns a list of horizontalpodautoscalers that match the given selector.
+func List(ctx context.Context, c client.Client, namespace string, selector map[string]string) ([]autoscalingv2beta2.HorizontalPodAutoscaler, error) {
+	list := &autoscalingv2beta2.HorizontalPodAutoscalerList{}
+	opts := []client.ListOption{
+		client.InNamespace(namespace),
+		client.MatchingLabels(selector),
+	}
+	if err := c.List(ctx, list, opts...); err != nil {
+		return nil, kverrors.Wrap(err, "failed to list horizontalpodautoscalers",
+			"namespace", namespace,
+		)
+	}
+
+	return list.Items, nil
+}
+
+// Equal returns only true if the labels and specs of the horizontalpodautoscalers are equal
+func Equal(current, desired *autoscalingv2beta2.HorizontalPodAutoscaler) bool {
+	return equality.Semantic.DeepEqual(current.Labels, desired.Labels) &&
+		equality.Semantic.DeepEqual(current.Spec, desired.Spec)
+}
+
+// Mutate is a default mutation function for horizontalpodautoscalers
+// that copies only mutable fields from desired to current.
+func Mutate(current, desired *autoscalingv2beta2.HorizontalPodAutoscaler) {
+	current.Labels = desired.Labels
+	current.Spec = desired.Spec
+}
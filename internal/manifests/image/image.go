@@ -0,0 +1,89 @@
+package image
+
+import (
+	"strings"
+
+	"github.com/ViaQ/logerr/kverrors"
+)
+
+var (
+	registry string
+	mirrors  = map[string]string{}
+)
+
+// SetRegistry sets the registry replacing the one of every image the operator deploys,
+// e.g. mirror.example.com:5000 for quay.io/openshift-logging/elasticsearch6.
+// An empty registry keeps the images unchanged.
+func SetRegistry(r string) {
+	registry = strings.TrimSuffix(r, "/")
+}
+
+// SetMirrors sets the images replacing others from a comma separated list of
+// source=mirror pairs, e.g. quay.io/openshift-logging/curator5=mirror.example.com/curator5.
+// Mirrors take precedence over the registry.
+func SetMirrors(list string) error {
+	m := map[string]string{}
+	for _, pair := range strings.Split(list, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return kverrors.New("invalid image mirror, expected source=mirror",
+				"mirror", pair)
+		}
+		m[parts[0]] = parts[1]
+	}
+
+	mirrors = m
+	return nil
+}
+
+// Resolve returns the image to deploy for the given one. A mirror of the exact image
+// reference is used first, else a mirror of the image repository keeping the tag or
+// digest, else the image is moved to the registry if any.
+func Resolve(image string) string {
+	if mirror, ok := mirrors[image]; ok {
+		return mirror
+	}
+
+	repository, reference := splitReference(image)
+	if mirror, ok := mirrors[repository]; ok {
+		if r, _ := splitReference(mirror); r == mirror {
+			return mirror + reference
+		}
+		return mirror
+	}
+
+	if registry == "" {
+		return image
+	}
+
+	return registry + "/" + trimRegistry(repository) + reference
+}
+
+// splitReference splits the image into its repository and the tag or digest
+// including the separator, e.g. quay.io/foo/bar and :latest
+func splitReference(image string) (string, string) {
+	if i := strings.Index(image, "@"); i >= 0 {
+		return image[:i], image[i:]
+	}
+
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i], image[i:]
+	}
+
+	return image, ""
+}
+
+// trimRegistry removes the registry host from the repository if any
+func trimRegistry(repository string) string {
+	parts := strings.SplitN(repository, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		return parts[1]
+	}
+
+	return repository
+}
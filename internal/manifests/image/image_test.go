@@ -0,0 +1,83 @@
+package image
+
+import "testing"
+
+func TestResolve(t *testing.T) {
+	defer func() {
+		SetRegistry("")
+		_ = SetMirrors("")
+	}()
+
+	tests := []struct {
+		desc     string
+		registry string
+		mirrors  string
+		image    string
+		want     string
+	}{
+		{
+			desc:  "no override",
+			image: "quay.io/openshift-logging/elasticsearch6:6.8.1",
+			want:  "quay.io/openshift-logging/elasticsearch6:6.8.1",
+		},
+		{
+			desc:     "registry with tag",
+			registry: "mirror.example.com:5000/",
+			image:    "quay.io/openshift-logging/elasticsearch6:6.8.1",
+			want:     "mirror.example.com:5000/openshift-logging/elasticsearch6:6.8.1",
+		},
+		{
+			desc:     "registry with digest",
+			registry: "mirror.example.com",
+			image:    "quay.io/openshift-logging/curator5@sha256:abc",
+			want:     "mirror.example.com/openshift-logging/curator5@sha256:abc",
+		},
+		{
+			desc:     "registry for an implicit registry",
+			registry: "mirror.example.com",
+			image:    "openshift/origin-oauth-proxy:latest",
+			want:     "mirror.example.com/openshift/origin-oauth-proxy:latest",
+		},
+		{
+			desc:     "mirror of the exact image",
+			registry: "mirror.example.com",
+			mirrors:  "quay.io/openshift-logging/kibana6:latest=internal/kibana:6.8.1",
+			image:    "quay.io/openshift-logging/kibana6:latest",
+			want:     "internal/kibana:6.8.1",
+		},
+		{
+			desc:    "mirror of the repository keeps the tag",
+			mirrors: "quay.io/openshift-logging/elasticsearch6=internal/es, quay.io/foo=internal/foo",
+			image:   "quay.io/openshift-logging/elasticsearch6:6.8.1",
+			want:    "internal/es:6.8.1",
+		},
+		{
+			desc:    "mirror of the repository with a tag",
+			mirrors: "quay.io/openshift-logging/elasticsearch6=internal/es:pinned",
+			image:   "quay.io/openshift-logging/elasticsearch6:6.8.1",
+			want:    "internal/es:pinned",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			SetRegistry(test.registry)
+			if err := SetMirrors(test.mirrors); err != nil {
+				t.Fatalf("failed with error: %s", err)
+			}
+
+			if got := Resolve(test.image); got != test.want {
+				t.Errorf("expected image %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestSetMirrorsInvalid(t *testing.T) {
+	for _, mirrors := range []string{"quay.io/foo", "=internal/foo", "quay.io/foo="} {
+		if err := SetMirrors(mirrors); err == nil {
+			t.Errorf("expected mirrors %q to be invalid", mirrors)
+		}
+	}
+}
@@ -74,6 +74,46 @@ func CreateOrUpdatePVC(ctx context.Context, c client.Client, pvc *corev1.Persist
 	return nil
 }
 
+// UpdatePVC will update an existing persistentvolumeclaim unless the equality func reports
+// the current persistentvolumeclaim on the api server equal to the desired one, in which
+// case it is left unchanged. Equality funcs of partial updates compare only the fields mutated.
+func UpdatePVC(ctx context.Context, c client.Client, pvc *corev1.PersistentVolumeClaim, equal EqualityPVCFunc, mutate MutatePVCFunc) error {
+	current := &corev1.PersistentVolumeClaim{}
+	key := client.ObjectKey{Name: pvc.Name, Namespace: pvc.Namespace}
+	err := c.Get(ctx, key, current)
+	if err != nil {
+		return kverrors.Wrap(err, "failed to get persistentvolumeclaim",
+			"name", pvc.Name,
+			"namespace", pvc.Namespace,
+		)
+	}
+
+	if !equal(current, pvc) {
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := c.Get(ctx, key, current); err != nil {
+				log.Error(err, "failed to get persistentvolumeclaim", pvc.Name)
+				return err
+			}
+
+			mutate(current, pvc)
+			if err := c.Update(ctx, current); err != nil {
+				log.Error(err, "failed to update persistentvolumeclaim", pvc.Name)
+				return err
+			}
+			return nil
+		})
+		if err != nil {
+			return kverrors.Wrap(err, "failed to update persistentvolumeclaim",
+				"name", pvc.Name,
+				"namespace", pvc.Namespace,
+			)
+		}
+		return nil
+	}
+
+	return nil
+}
+
 // LabelsEqual return only true if the pvcs are equal in labels only.
 func LabelsEqual(current, desired *corev1.PersistentVolumeClaim) bool {
 	return equality.Semantic.DeepEqual(current.Labels, desired.Labels)
@@ -100,3 +140,17 @@ func ListPVC(ctx context.Context, c client.Client, namespace string, selector ma
 
 	return list.Items, nil
 }
+
+// DeletePVC attempts to delete a persistentvolumeclaim if existing or returns an error.
+func DeletePVC(ctx context.Context, c client.Client, key client.ObjectKey) error {
+	pvc := NewPVC(key.Name, key.Namespace, nil)
+
+	if err := c.Delete(ctx, pvc, &client.DeleteOptions{}); err != nil {
+		return kverrors.Wrap(err, "failed to delete persistentvolumeclaim",
+			"name", pvc.Name,
+			"namespace", pvc.Namespace,
+		)
+	}
+
+	return nil
+}
@@ -23,17 +23,25 @@ func newPodSpec(serviceAccountName string, containers []corev1.Container, volume
 		ServiceAccountName: serviceAccountName,
 		Containers:         containers,
 		Volumes:            volumes,
-		NodeSelector:       utils.EnsureLinuxNodeSelector(map[string]string{}),
+		NodeSelector:       utils.EnsureLinuxNodeSelector(utils.MergeInfraNodeSelector(map[string]string{})),
 	}
 }
 
 // Build returns the final podspec
 func (b *Builder) Build() *corev1.PodSpec { return b.spec }
 
-// WithNodeSelectors sets the podsec selectors and ensures that the
-// default linux node selector is always present.
+// WithInitContainers sets the init containers of the podspec
+func (b *Builder) WithInitContainers(c ...corev1.Container) *Builder {
+	if len(c) > 0 {
+		b.spec.InitContainers = c
+	}
+	return b
+}
+
+// WithNodeSelectors sets the podsec selectors merged into the infra node selector
+// of the operator and ensures that the default linux node selector is always present.
 func (b *Builder) WithNodeSelectors(s map[string]string) *Builder {
-	b.spec.NodeSelector = utils.EnsureLinuxNodeSelector(s)
+	b.spec.NodeSelector = utils.EnsureLinuxNodeSelector(utils.MergeInfraNodeSelector(s))
 	return b
 }
 
@@ -49,6 +57,12 @@ func (b *Builder) WithAffinity(a *corev1.Affinity) *Builder {
 	return b
 }
 
+// WithSecurityContext sets the pod security context for the podspec
+func (b *Builder) WithSecurityContext(sc *corev1.PodSecurityContext) *Builder {
+	b.spec.SecurityContext = sc
+	return b
+}
+
 // WithRestartPolicy sets the restart policy for the podspec
 func (b *Builder) WithRestartPolicy(rp corev1.RestartPolicy) *Builder {
 	b.spec.RestartPolicy = rp
@@ -61,3 +75,33 @@ func (b *Builder) WithTerminationGracePeriodSeconds(p time.Duration) *Builder {
 	b.spec.TerminationGracePeriodSeconds = &d
 	return b
 }
+
+// WithPriorityClassName sets the name of the priority class for the podspec
+func (b *Builder) WithPriorityClassName(name string) *Builder {
+	b.spec.PriorityClassName = name
+	return b
+}
+
+// WithRuntimeClassName sets the runtime class name for the podspec
+func (b *Builder) WithRuntimeClassName(name *string) *Builder {
+	b.spec.RuntimeClassName = name
+	return b
+}
+
+// WithDNSPolicy sets the DNS policy for the podspec
+func (b *Builder) WithDNSPolicy(policy corev1.DNSPolicy) *Builder {
+	b.spec.DNSPolicy = policy
+	return b
+}
+
+// WithDNSConfig sets the DNS parameters for the podspec
+func (b *Builder) WithDNSConfig(config *corev1.PodDNSConfig) *Builder {
+	b.spec.DNSConfig = config
+	return b
+}
+
+// WithAutomountServiceAccountToken sets whether the service account token is mounted into all containers
+func (b *Builder) WithAutomountServiceAccountToken(automount *bool) *Builder {
+	b.spec.AutomountServiceAccountToken = automount
+	return b
+}
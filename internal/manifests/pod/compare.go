@@ -1,17 +1,25 @@
 package pod
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/openshift/elasticsearch-operator/internal/utils/comparators"
 
 	corev1 "k8s.io/api/core/v1"
 )
 
-// ArePodTemplateSpecEqual compares two corev1.PodTemplateSpec objects
-// and returns true only if pod spec are equal and tolerations are strictly the same
+// rolloutExcludedAnnotationPrefixes are the prefixes of pod template annotations whose changes
+// do not require the pods to be recreated, e.g. the Prometheus scrape annotations or the restart
+// annotation of kubectl. They are applied with the next change of the pod template.
+var rolloutExcludedAnnotationPrefixes = []string{"prometheus.io/", "kubectl.kubernetes.io/"}
+
+// ArePodTemplateSpecEqual compares two corev1.PodTemplateSpec objects and returns true only
+// if the annotations not excluded from rollouts and the pod spec are equal and tolerations
+// are strictly the same
 func ArePodTemplateSpecEqual(lhs, rhs corev1.PodTemplateSpec) bool {
-	return ArePodSpecEqual(lhs.Spec, rhs.Spec, true)
+	return len(DiffPodTemplateSpec(lhs, rhs)) == 0
 }
 
 // ArePodSpecEqual compares two corev1.PodSpec objects and returns true
@@ -19,17 +27,78 @@ func ArePodTemplateSpecEqual(lhs, rhs corev1.PodTemplateSpec) bool {
 // - Length of containers slice
 // - Node selectors
 // - Tolerations, if strict they need to be the same, non-strict for superset check
-// - Containers: Name, Image, VolumeMounts, EnvVar, Args, Ports, ResourceRequirements
+// - SecurityContext: RunAsUser, RunAsGroup, FSGroup, RunAsNonRoot, superset check
+// - SecurityContext: Sysctls, regardless of their order
+// - AutomountServiceAccountToken
+// - PriorityClassName, if non-strict only a desired one needs to be the same
+// - DNSPolicy, DNSConfig
+// - TerminationGracePeriodSeconds
+// - Containers: Name, Image, VolumeMounts, VolumeDevices, EnvVar, Args, Ports, ResourceRequirements, Lifecycle
+// - VolumeMounts, if strict they need to be the same, non-strict for superset check
+// - VolumeDevices, regardless of their order
 func ArePodSpecEqual(lhs, rhs corev1.PodSpec, strictTolerations bool) bool {
-	equal := true
+	return len(DiffPodSpec(lhs, rhs, strictTolerations)) == 0
+}
+
+// DiffPodTemplateSpec returns the annotations and the fields of the pod spec that differ
+// between the two corev1.PodTemplateSpec objects with tolerations and the security context
+// compared strictly.
+func DiffPodTemplateSpec(lhs, rhs corev1.PodTemplateSpec) []string {
+	diff := []string{}
+
+	if !comparators.AreStringMapsSame(rolloutAnnotations(lhs.Annotations), rolloutAnnotations(rhs.Annotations)) {
+		diff = append(diff, "annotations")
+	}
+
+	diff = append(diff, DiffPodSpec(lhs.Spec, rhs.Spec, true)...)
+
+	// nothing injects security context values into templates, thus removed ones differ too
+	if comparators.ContainsSamePodSecurityContext(lhs.Spec.SecurityContext, rhs.Spec.SecurityContext) &&
+		!comparators.ArePodSecurityContextsSame(lhs.Spec.SecurityContext, rhs.Spec.SecurityContext) {
+		diff = append(diff, "securityContext")
+	}
+
+	return diff
+}
+
+// rolloutAnnotations returns the annotations whose changes roll the pods
+func rolloutAnnotations(annotations map[string]string) map[string]string {
+	filtered := map[string]string{}
+	for key, value := range annotations {
+		if isRolloutExcludedAnnotation(key) {
+			continue
+		}
+		filtered[key] = value
+	}
+	return filtered
+}
+
+func isRolloutExcludedAnnotation(key string) bool {
+	for _, prefix := range rolloutExcludedAnnotationPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffPodSpec returns the fields compared by ArePodSpecEqual that differ between
+// the two corev1.PodSpec objects, e.g. "nodeSelector" or "containers[proxy].image".
+// An empty result means both pod specs are considered equal.
+func DiffPodSpec(lhs, rhs corev1.PodSpec, strictTolerations bool) []string {
+	diff := []string{}
 
 	if len(lhs.Containers) != len(rhs.Containers) {
-		equal = false
+		diff = append(diff, "containers")
+	}
+
+	if !areInitContainersSame(lhs.InitContainers, rhs.InitContainers) {
+		diff = append(diff, "initContainers")
 	}
 
 	// check nodeselectors
 	if !comparators.AreSelectorsSame(lhs.NodeSelector, rhs.NodeSelector) {
-		equal = false
+		diff = append(diff, "nodeSelector")
 	}
 
 	// strictTolerations are for when we compare from the deployments or statefulsets
@@ -38,15 +107,51 @@ func ArePodSpecEqual(lhs, rhs corev1.PodSpec, strictTolerations bool) bool {
 	if strictTolerations {
 		// check tolerations
 		if !comparators.AreTolerationsSame(lhs.Tolerations, rhs.Tolerations) {
-			equal = false
+			diff = append(diff, "tolerations")
 		}
 	} else {
 		// check tolerations
 		if !comparators.ContainsSameTolerations(lhs.Tolerations, rhs.Tolerations) {
-			equal = false
+			diff = append(diff, "tolerations")
 		}
 	}
 
+	// k8s may inject security context values into rolled out pods, thus only the desired ones
+	// are compared. DiffPodTemplateSpec compares the ones of the templates strictly.
+	if !comparators.ContainsSamePodSecurityContext(lhs.SecurityContext, rhs.SecurityContext) {
+		diff = append(diff, "securityContext")
+	}
+
+	// k8s injects token volumes into rolled out pods, thus only the desired ones are compared
+	if !comparators.ContainsSameVolumes(lhs.Volumes, rhs.Volumes) {
+		diff = append(diff, "volumes")
+	}
+
+	if isAutomountServiceAccountToken(lhs) != isAutomountServiceAccountToken(rhs) {
+		diff = append(diff, "automountServiceAccountToken")
+	}
+
+	// k8s assigns the global default priority class to rolled out pods requesting none
+	if lhs.PriorityClassName != rhs.PriorityClassName && (strictTolerations || rhs.PriorityClassName != "") {
+		diff = append(diff, "priorityClassName")
+	}
+
+	if runtimeClassName(lhs) != runtimeClassName(rhs) {
+		diff = append(diff, "runtimeClassName")
+	}
+
+	if dnsPolicy(lhs) != dnsPolicy(rhs) {
+		diff = append(diff, "dnsPolicy")
+	}
+
+	if !reflect.DeepEqual(lhs.DNSConfig, rhs.DNSConfig) {
+		diff = append(diff, "dnsConfig")
+	}
+
+	if terminationGracePeriodSeconds(lhs) != terminationGracePeriodSeconds(rhs) {
+		diff = append(diff, "terminationGracePeriodSeconds")
+	}
+
 	// check container fields
 	for _, lContainer := range lhs.Containers {
 		found := false
@@ -59,37 +164,125 @@ func ArePodSpecEqual(lhs, rhs corev1.PodSpec, strictTolerations bool) bool {
 
 			found = true
 
-			// can't use reflect.DeepEqual here, due to k8s adding token mounts
+			// can't use reflect.DeepEqual here, due to k8s adding token mounts to rolled out pods
 			// check that rContainer is all found within lContainer and that they match by name
-			if !comparators.ContainsSameVolumeMounts(lContainer.VolumeMounts, rContainer.VolumeMounts) {
-				equal = false
+			if strictTolerations {
+				if !comparators.AreVolumeMountsSame(lContainer.VolumeMounts, rContainer.VolumeMounts) {
+					diff = append(diff, containerField(lContainer.Name, "volumeMounts"))
+				}
+			} else {
+				if !comparators.ContainsSameVolumeMounts(lContainer.VolumeMounts, rContainer.VolumeMounts) {
+					diff = append(diff, containerField(lContainer.Name, "volumeMounts"))
+				}
+			}
+
+			// k8s does not inject devices, thus they always need to be the same
+			if !comparators.AreVolumeDevicesSame(lContainer.VolumeDevices, rContainer.VolumeDevices) {
+				diff = append(diff, containerField(lContainer.Name, "volumeDevices"))
 			}
 
 			if lContainer.Image != rContainer.Image {
-				equal = false
+				diff = append(diff, containerField(lContainer.Name, "image"))
 			}
 
 			if !comparators.EnvValueEqual(lContainer.Env, rContainer.Env) {
-				equal = false
+				diff = append(diff, containerField(lContainer.Name, "env"))
 			}
 
 			if !reflect.DeepEqual(lContainer.Args, rContainer.Args) {
-				equal = false
+				diff = append(diff, containerField(lContainer.Name, "args"))
 			}
 
 			if !reflect.DeepEqual(lContainer.Ports, rContainer.Ports) {
-				equal = false
+				diff = append(diff, containerField(lContainer.Name, "ports"))
 			}
 
 			if !comparators.AreResourceRequementsSame(lContainer.Resources, rContainer.Resources) {
-				equal = false
+				diff = append(diff, containerField(lContainer.Name, "resources"))
+			}
+
+			if !comparators.AreProbesSame(lContainer.ReadinessProbe, rContainer.ReadinessProbe) {
+				diff = append(diff, containerField(lContainer.Name, "readinessProbe"))
+			}
+
+			if terminationMessagePolicy(lContainer) != terminationMessagePolicy(rContainer) {
+				diff = append(diff, containerField(lContainer.Name, "terminationMessagePolicy"))
+			}
+
+			if !reflect.DeepEqual(lContainer.Lifecycle, rContainer.Lifecycle) {
+				diff = append(diff, containerField(lContainer.Name, "lifecycle"))
 			}
 		}
 
 		if !found {
-			equal = false
+			diff = append(diff, containerField(lContainer.Name, ""))
+		}
+	}
+
+	return diff
+}
+
+// areInitContainersSame compares the init containers by the fields set by the operator
+func areInitContainersSame(lhs, rhs []corev1.Container) bool {
+	if len(lhs) != len(rhs) {
+		return false
+	}
+
+	for i := range lhs {
+		if lhs[i].Name != rhs[i].Name ||
+			lhs[i].Image != rhs[i].Image ||
+			!reflect.DeepEqual(lhs[i].Command, rhs[i].Command) ||
+			!reflect.DeepEqual(lhs[i].Args, rhs[i].Args) ||
+			terminationMessagePolicy(lhs[i]) != terminationMessagePolicy(rhs[i]) {
+			return false
 		}
 	}
 
-	return equal
+	return true
+}
+
+// terminationMessagePolicy returns the termination message policy of the container with
+// the API server default applied
+func terminationMessagePolicy(container corev1.Container) corev1.TerminationMessagePolicy {
+	if container.TerminationMessagePolicy == "" {
+		return corev1.TerminationMessageReadFile
+	}
+	return container.TerminationMessagePolicy
+}
+
+// isAutomountServiceAccountToken returns whether the token is mounted with the API server default applied
+func isAutomountServiceAccountToken(spec corev1.PodSpec) bool {
+	return spec.AutomountServiceAccountToken == nil || *spec.AutomountServiceAccountToken
+}
+
+// runtimeClassName returns the runtime class name or an empty one if unset
+func runtimeClassName(spec corev1.PodSpec) string {
+	if spec.RuntimeClassName == nil {
+		return ""
+	}
+	return *spec.RuntimeClassName
+}
+
+// terminationGracePeriodSeconds returns the termination grace period with the API server
+// default applied
+func terminationGracePeriodSeconds(spec corev1.PodSpec) int64 {
+	if spec.TerminationGracePeriodSeconds == nil {
+		return corev1.DefaultTerminationGracePeriodSeconds
+	}
+	return *spec.TerminationGracePeriodSeconds
+}
+
+// dnsPolicy returns the DNS policy with the API server default applied
+func dnsPolicy(spec corev1.PodSpec) corev1.DNSPolicy {
+	if spec.DNSPolicy == "" {
+		return corev1.DNSClusterFirst
+	}
+	return spec.DNSPolicy
+}
+
+func containerField(name, field string) string {
+	if field == "" {
+		return fmt.Sprintf("containers[%s]", name)
+	}
+	return fmt.Sprintf("containers[%s].%s", name, field)
 }
@@ -1,12 +1,14 @@
 package pod_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/openshift/elasticsearch-operator/internal/manifests/pod"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestArePodTemplateSpecEqual(t *testing.T) {
@@ -32,6 +34,8 @@ func TestArePodTemplateSpecEqual(t *testing.T) {
 		Image: "image",
 	}
 
+	fsGroup := int64(1000)
+
 	type mutateFunc func(*corev1.Container)
 	diffContainer := func(fn mutateFunc) corev1.Container {
 		c := defaultContainer.DeepCopy()
@@ -439,6 +443,40 @@ func TestArePodTemplateSpecEqual(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			desc: "different security context",
+			lhs: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{defaultContainer},
+				},
+			},
+			rhs: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{defaultContainer},
+					SecurityContext: &corev1.PodSecurityContext{
+						FSGroup: &fsGroup,
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			desc: "removed security context",
+			lhs: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{defaultContainer},
+					SecurityContext: &corev1.PodSecurityContext{
+						FSGroup: &fsGroup,
+					},
+				},
+			},
+			rhs: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{defaultContainer},
+				},
+			},
+			want: false,
+		},
 		{
 			desc: "different multiple containers",
 			lhs: corev1.PodTemplateSpec{
@@ -591,3 +629,614 @@ func TestPodSpecEqual_NonStrictTolerations(t *testing.T) {
 		})
 	}
 }
+
+func TestPodSpecEqual_NonStrictSecurityContext(t *testing.T) {
+	type table struct {
+		desc   string
+		lhs    corev1.PodSpec
+		rhs    corev1.PodSpec
+		strict bool
+		want   bool
+	}
+
+	user := int64(1000)
+	otherUser := int64(1001)
+	fsGroup := int64(1000)
+	nonRoot := true
+
+	tests := []table{
+		{
+			desc: "contains injected fsGroup",
+			lhs: corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{
+					RunAsUser: &user,
+					FSGroup:   &fsGroup,
+				},
+			},
+			rhs: corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{
+					RunAsUser: &user,
+				},
+			},
+			strict: false,
+			want:   true,
+		},
+		{
+			desc: "strict contains injected fsGroup",
+			lhs: corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{
+					RunAsUser: &user,
+					FSGroup:   &fsGroup,
+				},
+			},
+			rhs: corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{
+					RunAsUser: &user,
+				},
+			},
+			strict: true,
+			want:   true,
+		},
+		{
+			desc: "different user",
+			lhs: corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{
+					RunAsUser: &otherUser,
+				},
+			},
+			rhs: corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{
+					RunAsUser: &user,
+				},
+			},
+			strict: false,
+			want:   false,
+		},
+		{
+			desc: "different runAsNonRoot",
+			lhs: corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{
+					RunAsUser: &user,
+				},
+			},
+			rhs: corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{
+					RunAsUser:    &user,
+					RunAsNonRoot: &nonRoot,
+				},
+			},
+			strict: false,
+			want:   false,
+		},
+		{
+			desc: "same sysctls in different order",
+			lhs: corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{
+					Sysctls: []corev1.Sysctl{{Name: "net.ipv4.tcp_syncookies", Value: "1"}, {Name: "net.core.somaxconn", Value: "1024"}},
+				},
+			},
+			rhs: corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{
+					Sysctls: []corev1.Sysctl{{Name: "net.core.somaxconn", Value: "1024"}, {Name: "net.ipv4.tcp_syncookies", Value: "1"}},
+				},
+			},
+			strict: true,
+			want:   true,
+		},
+		{
+			desc: "different sysctl value",
+			lhs: corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{
+					Sysctls: []corev1.Sysctl{{Name: "net.core.somaxconn", Value: "128"}},
+				},
+			},
+			rhs: corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{
+					Sysctls: []corev1.Sysctl{{Name: "net.core.somaxconn", Value: "1024"}},
+				},
+			},
+			strict: false,
+			want:   false,
+		},
+		{
+			desc: "removed sysctls",
+			lhs: corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{
+					Sysctls: []corev1.Sysctl{{Name: "net.core.somaxconn", Value: "1024"}},
+				},
+			},
+			rhs:    corev1.PodSpec{},
+			strict: false,
+			want:   false,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			got := pod.ArePodSpecEqual(test.lhs, test.rhs, test.strict)
+			if got != test.want {
+				t.Errorf("got: %t, want: %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPodSpecEqual_NonStrictVolumeMounts(t *testing.T) {
+	type table struct {
+		desc   string
+		lhs    corev1.PodSpec
+		rhs    corev1.PodSpec
+		strict bool
+		want   bool
+	}
+
+	newSpec := func(mounts ...string) corev1.PodSpec {
+		c := corev1.Container{Name: "elasticsearch"}
+		for _, name := range mounts {
+			c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{Name: name, MountPath: "/" + name})
+		}
+		return corev1.PodSpec{Containers: []corev1.Container{c}}
+	}
+
+	tests := []table{
+		{
+			desc:   "contains injected token mount",
+			lhs:    newSpec("config", "token"),
+			rhs:    newSpec("config"),
+			strict: false,
+			want:   true,
+		},
+		{
+			desc:   "strict detects removed mount",
+			lhs:    newSpec("config", "extra"),
+			rhs:    newSpec("config"),
+			strict: true,
+			want:   false,
+		},
+		{
+			desc:   "strict detects added mount",
+			lhs:    newSpec("config"),
+			rhs:    newSpec("config", "extra"),
+			strict: true,
+			want:   false,
+		},
+		{
+			desc:   "strict same mounts",
+			lhs:    newSpec("config", "extra"),
+			rhs:    newSpec("config", "extra"),
+			strict: true,
+			want:   true,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			got := pod.ArePodSpecEqual(test.lhs, test.rhs, test.strict)
+			if got != test.want {
+				t.Errorf("got: %t, want: %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPodSpecEqual_VolumeDevices(t *testing.T) {
+	type table struct {
+		desc   string
+		lhs    corev1.PodSpec
+		rhs    corev1.PodSpec
+		strict bool
+		want   bool
+	}
+
+	newSpec := func(devices ...string) corev1.PodSpec {
+		c := corev1.Container{Name: "elasticsearch"}
+		for _, name := range devices {
+			c.VolumeDevices = append(c.VolumeDevices, corev1.VolumeDevice{Name: name, DevicePath: "/dev/" + name})
+		}
+		return corev1.PodSpec{Containers: []corev1.Container{c}}
+	}
+
+	tests := []table{
+		{
+			desc:   "added device",
+			lhs:    newSpec("data"),
+			rhs:    newSpec("data", "extra"),
+			strict: true,
+			want:   false,
+		},
+		{
+			desc:   "removed device",
+			lhs:    newSpec("data", "extra"),
+			rhs:    newSpec("data"),
+			strict: false,
+			want:   false,
+		},
+		{
+			desc: "changed device path",
+			lhs:  newSpec("data"),
+			rhs: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "elasticsearch", VolumeDevices: []corev1.VolumeDevice{{Name: "data", DevicePath: "/dev/other"}}},
+			}},
+			strict: true,
+			want:   false,
+		},
+		{
+			desc:   "same devices in different order",
+			lhs:    newSpec("data", "extra"),
+			rhs:    newSpec("extra", "data"),
+			strict: true,
+			want:   true,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			got := pod.ArePodSpecEqual(test.lhs, test.rhs, test.strict)
+			if got != test.want {
+				t.Errorf("got: %t, want: %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPodSpecEqual_ServiceAccountTokenProjections(t *testing.T) {
+	type table struct {
+		desc   string
+		lhs    corev1.PodSpec
+		rhs    corev1.PodSpec
+		strict bool
+		want   bool
+	}
+
+	newSpec := func(audience string, expirationSeconds *int64) corev1.PodSpec {
+		return corev1.PodSpec{
+			Volumes: []corev1.Volume{
+				{
+					Name: "service-account-token",
+					VolumeSource: corev1.VolumeSource{
+						Projected: &corev1.ProjectedVolumeSource{
+							Sources: []corev1.VolumeProjection{
+								{ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+									Audience:          audience,
+									Path:              "token",
+									ExpirationSeconds: expirationSeconds,
+								}},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	expiration := func(seconds int64) *int64 { return &seconds }
+
+	injected := newSpec("", expiration(3607))
+	injected.Volumes = append(injected.Volumes, corev1.Volume{
+		Name: "kube-api-access-x1y2z",
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{
+					{ServiceAccountToken: &corev1.ServiceAccountTokenProjection{Path: "token"}},
+				},
+			},
+		},
+	})
+
+	tests := []table{
+		{
+			desc:   "changed audience",
+			lhs:    newSpec("", expiration(3607)),
+			rhs:    newSpec("elasticsearch-proxy", expiration(3607)),
+			strict: true,
+			want:   false,
+		},
+		{
+			desc:   "changed expiration",
+			lhs:    newSpec("", expiration(3607)),
+			rhs:    newSpec("", expiration(7200)),
+			strict: false,
+			want:   false,
+		},
+		{
+			desc:   "defaulted expiration",
+			lhs:    newSpec("", expiration(3600)),
+			rhs:    newSpec("", nil),
+			strict: true,
+			want:   true,
+		},
+		{
+			desc:   "added token volume",
+			lhs:    corev1.PodSpec{},
+			rhs:    newSpec("", nil),
+			strict: true,
+			want:   false,
+		},
+		{
+			desc:   "injected token volume",
+			lhs:    injected,
+			rhs:    newSpec("", expiration(3607)),
+			strict: false,
+			want:   true,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			got := pod.ArePodSpecEqual(test.lhs, test.rhs, test.strict)
+			if got != test.want {
+				t.Errorf("got: %t, want: %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPodSpecEqual_VolumeSources(t *testing.T) {
+	type table struct {
+		desc   string
+		lhs    corev1.PodSpec
+		rhs    corev1.PodSpec
+		strict bool
+		want   bool
+	}
+
+	newSpec := func(source corev1.VolumeSource) corev1.PodSpec {
+		return corev1.PodSpec{
+			Volumes: []corev1.Volume{
+				{Name: "certificates", VolumeSource: source},
+			},
+		}
+	}
+	secret := func(name string, mode *int32) corev1.VolumeSource {
+		return corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: name, DefaultMode: mode},
+		}
+	}
+	mode := func(mode int32) *int32 { return &mode }
+
+	tests := []table{
+		{
+			desc:   "changed secret",
+			lhs:    newSpec(secret("elasticsearch", nil)),
+			rhs:    newSpec(secret("elasticsearch-certs", nil)),
+			strict: false,
+			want:   false,
+		},
+		{
+			desc:   "changed source type",
+			lhs:    newSpec(secret("elasticsearch", nil)),
+			rhs:    newSpec(corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}),
+			strict: true,
+			want:   false,
+		},
+		{
+			desc:   "changed mode",
+			lhs:    newSpec(secret("elasticsearch", mode(0644))),
+			rhs:    newSpec(secret("elasticsearch", mode(0600))),
+			strict: true,
+			want:   false,
+		},
+		{
+			desc:   "defaulted mode",
+			lhs:    newSpec(secret("elasticsearch", mode(0644))),
+			rhs:    newSpec(secret("elasticsearch", nil)),
+			strict: false,
+			want:   true,
+		},
+		{
+			desc:   "defaulted empty dir",
+			lhs:    newSpec(corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}),
+			rhs:    newSpec(corev1.VolumeSource{}),
+			strict: true,
+			want:   true,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			got := pod.ArePodSpecEqual(test.lhs, test.rhs, test.strict)
+			if got != test.want {
+				t.Errorf("got: %t, want: %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDiffPodTemplateSpec(t *testing.T) {
+	lhs := corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  "elasticsearch",
+					Image: "image",
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceMemory: resource.MustParse("2Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	rhs := *lhs.DeepCopy()
+	rhs.Spec.Containers[0].Image = "other"
+	rhs.Spec.Containers[0].Resources.Limits = corev1.ResourceList{
+		corev1.ResourceMemory: resource.MustParse("4Gi"),
+	}
+	rhs.Spec.NodeSelector = map[string]string{"key": "value"}
+
+	want := []string{
+		"nodeSelector",
+		"containers[elasticsearch].image",
+		"containers[elasticsearch].resources",
+	}
+
+	got := pod.DiffPodTemplateSpec(lhs, rhs)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	if got := pod.DiffPodTemplateSpec(lhs, lhs); len(got) != 0 {
+		t.Errorf("got: %v, want no differences", got)
+	}
+}
+
+func TestPodSpecEqual_AutomountServiceAccountToken(t *testing.T) {
+	enabled := true
+	disabled := false
+
+	lhs := corev1.PodSpec{}
+	rhs := corev1.PodSpec{AutomountServiceAccountToken: &enabled}
+
+	if !pod.ArePodSpecEqual(lhs, rhs, true) {
+		t.Error("Exp. an unset automountServiceAccountToken to equal the enabled default")
+	}
+
+	rhs.AutomountServiceAccountToken = &disabled
+	if got, want := pod.DiffPodSpec(lhs, rhs, true), []string{"automountServiceAccountToken"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestPodSpecEqual_PriorityClassName(t *testing.T) {
+	lhs := corev1.PodSpec{PriorityClassName: "system-default"}
+	rhs := corev1.PodSpec{}
+
+	if got, want := pod.DiffPodSpec(lhs, rhs, true), []string{"priorityClassName"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	if !pod.ArePodSpecEqual(lhs, rhs, false) {
+		t.Error("Exp. a defaulted priority class of a rolled out pod to equal none desired")
+	}
+
+	rhs.PriorityClassName = "logging-critical"
+	if got, want := pod.DiffPodSpec(lhs, rhs, false), []string{"priorityClassName"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestPodSpecEqual_DNS(t *testing.T) {
+	lhs := corev1.PodSpec{}
+	rhs := corev1.PodSpec{DNSPolicy: corev1.DNSClusterFirst}
+
+	if !pod.ArePodSpecEqual(lhs, rhs, true) {
+		t.Error("Exp. an unset dnsPolicy to equal the ClusterFirst default")
+	}
+
+	rhs.DNSPolicy = corev1.DNSNone
+	rhs.DNSConfig = &corev1.PodDNSConfig{Nameservers: []string{"10.0.0.10"}}
+	if got, want := pod.DiffPodSpec(lhs, rhs, true), []string{"dnsPolicy", "dnsConfig"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestPodSpecEqual_RuntimeClassName(t *testing.T) {
+	gvisor := "gvisor"
+	lhs := corev1.PodSpec{}
+	rhs := corev1.PodSpec{RuntimeClassName: &gvisor}
+
+	if got, want := pod.DiffPodSpec(lhs, rhs, false), []string{"runtimeClassName"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	empty := ""
+	lhs.RuntimeClassName = &empty
+	rhs.RuntimeClassName = nil
+	if !pod.ArePodSpecEqual(lhs, rhs, true) {
+		t.Error("Exp. an empty runtimeClassName to equal none")
+	}
+}
+
+func TestDiffPodTemplateSpec_Annotations(t *testing.T) {
+	lhs := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{"team": "logging"},
+		},
+	}
+
+	rhs := *lhs.DeepCopy()
+	rhs.Annotations["prometheus.io/scrape"] = "true"
+	rhs.Annotations["kubectl.kubernetes.io/restartedAt"] = "2021-01-01T00:00:00Z"
+	if got := pod.DiffPodTemplateSpec(lhs, rhs); len(got) != 0 {
+		t.Errorf("Exp. annotations excluded from rollouts to be ignored but got %v", got)
+	}
+
+	rhs.Annotations["team"] = "observability"
+	if got, want := pod.DiffPodTemplateSpec(lhs, rhs), []string{"annotations"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	if pod.ArePodTemplateSpecEqual(corev1.PodTemplateSpec{}, lhs) {
+		t.Error("Exp. added annotations to roll the pods")
+	}
+}
+
+func TestPodSpecEqual_InitContainers(t *testing.T) {
+	lhs := corev1.PodSpec{}
+	rhs := corev1.PodSpec{
+		InitContainers: []corev1.Container{
+			{Name: "chown-data-volume", Image: "elasticsearch", Command: []string{"chown", "-R", "1000:1000", "/data"}},
+		},
+	}
+
+	if got, want := pod.DiffPodSpec(lhs, rhs, true), []string{"initContainers"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	changed := *rhs.DeepCopy()
+	changed.InitContainers[0].Command[2] = "1001:1001"
+	if pod.ArePodSpecEqual(rhs, changed, true) {
+		t.Error("Exp. a changed init container command to roll the pods")
+	}
+
+	if !pod.ArePodSpecEqual(rhs, *rhs.DeepCopy(), false) {
+		t.Error("Exp. identical init containers to be equal")
+	}
+}
+
+func TestPodSpecEqual_TerminationMessagePolicy(t *testing.T) {
+	lhs := corev1.PodSpec{
+		Containers: []corev1.Container{{Name: "elasticsearch"}},
+	}
+	rhs := *lhs.DeepCopy()
+	rhs.Containers[0].TerminationMessagePolicy = corev1.TerminationMessageReadFile
+
+	if !pod.ArePodSpecEqual(lhs, rhs, true) {
+		t.Error("Exp. an unset terminationMessagePolicy to equal the File default")
+	}
+
+	rhs.Containers[0].TerminationMessagePolicy = corev1.TerminationMessageFallbackToLogsOnError
+	if got, want := pod.DiffPodSpec(lhs, rhs, true), []string{"containers[elasticsearch].terminationMessagePolicy"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	lhs.InitContainers = []corev1.Container{{Name: "chown-data-volume"}}
+	rhs.InitContainers = []corev1.Container{{Name: "chown-data-volume", TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError}}
+	if got, want := pod.DiffPodSpec(lhs, rhs, true), []string{"initContainers", "containers[elasticsearch].terminationMessagePolicy"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestPodSpecEqual_Lifecycle(t *testing.T) {
+	lhs := corev1.PodSpec{
+		Containers: []corev1.Container{{Name: "elasticsearch"}},
+	}
+	rhs := *lhs.DeepCopy()
+	grace := int64(corev1.DefaultTerminationGracePeriodSeconds)
+	rhs.TerminationGracePeriodSeconds = &grace
+
+	if !pod.ArePodSpecEqual(lhs, rhs, true) {
+		t.Error("Exp. an unset terminationGracePeriodSeconds to equal the default")
+	}
+
+	grace = 50
+	rhs.Containers[0].Lifecycle = &corev1.Lifecycle{
+		PreStop: &corev1.Handler{
+			Exec: &corev1.ExecAction{Command: []string{"sleep", "20"}},
+		},
+	}
+	if got, want := pod.DiffPodSpec(lhs, rhs, true), []string{"terminationGracePeriodSeconds", "containers[elasticsearch].lifecycle"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
@@ -61,3 +61,16 @@ func CreateOrUpdateClusterRoleBinding(ctx context.Context, c client.Client, crb
 	}
 	return nil
 }
+
+// GetClusterRoleBinding returns the clusterrolebinding for the given key or an error.
+func GetClusterRoleBinding(ctx context.Context, c client.Client, key client.ObjectKey) (*rbacv1.ClusterRoleBinding, error) {
+	crb := &rbacv1.ClusterRoleBinding{}
+
+	if err := c.Get(ctx, key, crb); err != nil {
+		return crb, kverrors.Wrap(err, "failed to get clusterrolebinding",
+			"name", key.Name,
+		)
+	}
+
+	return crb, nil
+}
@@ -16,15 +16,15 @@ import (
 // CreateOrUpdateRole attempts first to create the given role. If the
 // role already exists and the provided comparison func detects any changes
 // an update is attempted. Updates are retried with backoff (See retry.DefaultRetry).
-// Returns on failure an non-nil error.
-func CreateOrUpdateRole(ctx context.Context, c client.Client, r *rbacv1.Role) error {
+// Returns true if the existing role was updated or on failure an non-nil error.
+func CreateOrUpdateRole(ctx context.Context, c client.Client, r *rbacv1.Role) (bool, error) {
 	err := c.Create(ctx, r)
 	if err == nil {
-		return nil
+		return false, nil
 	}
 
 	if !apierrors.IsAlreadyExists(kverrors.Root(err)) {
-		return kverrors.Wrap(err, "failed to create role",
+		return false, kverrors.Wrap(err, "failed to create role",
 			"name", r.Name,
 			"namespace", r.Namespace,
 		)
@@ -34,13 +34,13 @@ func CreateOrUpdateRole(ctx context.Context, c client.Client, r *rbacv1.Role) er
 	key := client.ObjectKey{Name: r.Name, Namespace: r.Namespace}
 	err = c.Get(ctx, key, current)
 	if err != nil {
-		return kverrors.Wrap(err, "failed to get role",
+		return false, kverrors.Wrap(err, "failed to get role",
 			"name", r.Name,
 			"namespace", r.Namespace,
 		)
 	}
 
-	if !equality.Semantic.DeepEqual(current, r) {
+	if !equality.Semantic.DeepEqual(current.Rules, r.Rules) {
 		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 			if err := c.Get(ctx, key, current); err != nil {
 				log.Error(err, "failed to get role", r.Name)
@@ -55,12 +55,12 @@ func CreateOrUpdateRole(ctx context.Context, c client.Client, r *rbacv1.Role) er
 			return nil
 		})
 		if err != nil {
-			return kverrors.Wrap(err, "failed to update role",
+			return false, kverrors.Wrap(err, "failed to update role",
 				"name", r.Name,
 				"namespace", r.Namespace,
 			)
 		}
-		return nil
+		return true, nil
 	}
-	return nil
+	return false, nil
 }
@@ -16,15 +16,15 @@ import (
 // CreateOrUpdateRoleBinding attempts first to create the given rolebinding. If the
 // rolebinding already exists and the provided comparison func detects any changes
 // an update is attempted. Updates are retried with backoff (See retry.DefaultRetry).
-// Returns on failure an non-nil error.
-func CreateOrUpdateRoleBinding(ctx context.Context, c client.Client, rb *rbacv1.RoleBinding) error {
+// Returns true if the existing rolebinding was updated or on failure an non-nil error.
+func CreateOrUpdateRoleBinding(ctx context.Context, c client.Client, rb *rbacv1.RoleBinding) (bool, error) {
 	err := c.Create(ctx, rb)
 	if err == nil {
-		return nil
+		return false, nil
 	}
 
 	if !apierrors.IsAlreadyExists(kverrors.Root(err)) {
-		return kverrors.Wrap(err, "failed to create rolebinding",
+		return false, kverrors.Wrap(err, "failed to create rolebinding",
 			"name", rb.Name,
 			"namespace", rb.Namespace,
 		)
@@ -34,13 +34,13 @@ func CreateOrUpdateRoleBinding(ctx context.Context, c client.Client, rb *rbacv1.
 	key := client.ObjectKey{Name: rb.Name, Namespace: rb.Namespace}
 	err = c.Get(ctx, key, current)
 	if err != nil {
-		return kverrors.Wrap(err, "failed to get rolebinding",
+		return false, kverrors.Wrap(err, "failed to get rolebinding",
 			"name", rb.Name,
 			"namespace", rb.Namespace,
 		)
 	}
 
-	if !equality.Semantic.DeepEqual(current, rb) {
+	if !equality.Semantic.DeepEqual(current.Subjects, rb.Subjects) {
 		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 			if err := c.Get(ctx, key, current); err != nil {
 				log.Error(err, "failed to get rolebinding", rb.Name)
@@ -55,12 +55,12 @@ func CreateOrUpdateRoleBinding(ctx context.Context, c client.Client, rb *rbacv1.
 			return nil
 		})
 		if err != nil {
-			return kverrors.Wrap(err, "failed to update rolebinding",
+			return false, kverrors.Wrap(err, "failed to update rolebinding",
 				"name", rb.Name,
 				"namespace", rb.Namespace,
 			)
 		}
-		return nil
+		return true, nil
 	}
-	return nil
+	return false, nil
 }
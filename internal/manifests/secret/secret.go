@@ -38,19 +38,26 @@ func Get(ctx context.Context, c client.Client, key client.ObjectKey) (*corev1.Se
 	return s, nil
 }
 
-// GetDataSHA256 returns the sha256 checksum of the secret data keys
+// GetDataSHA256 returns the sha256 checksum of the secret data keys. Returns an
+// empty hash if the secret is missing or holds no data.
 func GetDataSHA256(ctx context.Context, c client.Client, key client.ObjectKey) string {
-	hash := ""
-
 	sec, err := Get(ctx, c, key)
 	if err != nil {
-		return hash
+		return ""
 	}
 
+	return dataSHA256(sec.Data)
+}
+
+// dataSHA256 returns the checksums of the data values concatenated in the order
+// of their sorted keys, thus independent of the map iteration order
+func dataSHA256(data map[string][]byte) string {
+	hash := ""
+
 	dataHashes := make(map[string][32]byte)
 
-	for key, data := range sec.Data {
-		dataHashes[key] = sha256.Sum256([]byte(data))
+	for key, value := range data {
+		dataHashes[key] = sha256.Sum256(value)
 	}
 
 	sortedKeys := []string{}
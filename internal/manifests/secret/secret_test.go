@@ -0,0 +1,55 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetDataSHA256MissingSecret(t *testing.T) {
+	c := fake.NewFakeClient()
+	key := client.ObjectKey{Name: "elasticsearch", Namespace: "openshift-logging"}
+
+	if hash := GetDataSHA256(context.TODO(), c, key); hash != "" {
+		t.Errorf("Exp. an empty hash for a missing secret but got %q", hash)
+	}
+}
+
+func TestDataSHA256(t *testing.T) {
+	many := map[string][]byte{}
+	reversed := map[string][]byte{}
+	for i := 0; i < 20; i++ {
+		many[fmt.Sprintf("key-%d", i)] = []byte(fmt.Sprintf("value-%d", i))
+	}
+	for i := 19; i >= 0; i-- {
+		reversed[fmt.Sprintf("key-%d", i)] = []byte(fmt.Sprintf("value-%d", i))
+	}
+
+	if hash := dataSHA256(nil); hash != "" {
+		t.Errorf("Exp. an empty hash for nil data but got %q", hash)
+	}
+	if hash := dataSHA256(map[string][]byte{}); hash != "" {
+		t.Errorf("Exp. an empty hash for empty data but got %q", hash)
+	}
+
+	single := dataSHA256(map[string][]byte{"admin-key": []byte("key")})
+	if single == "" || single != dataSHA256(map[string][]byte{"admin-key": []byte("key")}) {
+		t.Errorf("Exp. a stable non-empty hash for a single key but got %q", single)
+	}
+	if single == dataSHA256(map[string][]byte{"admin-key": []byte("changed")}) {
+		t.Error("Exp. the hash to change with the data")
+	}
+
+	hash := dataSHA256(many)
+	for i := 0; i < 10; i++ {
+		if got := dataSHA256(many); got != hash {
+			t.Fatalf("Exp. a stable hash for many keys, got %q and %q", hash, got)
+		}
+	}
+	if got := dataSHA256(reversed); got != hash {
+		t.Errorf("Exp. the hash to be independent of the insertion order, got %q and %q", hash, got)
+	}
+}
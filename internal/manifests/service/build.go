@@ -39,6 +39,13 @@ func (b *Builder) WithAnnotations(a map[string]string) *Builder {
 	return b
 }
 
+// WithManagedAnnotations sets the object meta annotations and records their keys, so that
+// MutateManagedAnnotations removes them once no longer desired while keeping foreign ones.
+func (b *Builder) WithManagedAnnotations(a map[string]string) *Builder {
+	b.svc.Annotations = managedAnnotations(a)
+	return b
+}
+
 // WithSelector sets the service selector.
 func (b *Builder) WithSelector(s map[string]string) *Builder {
 	b.svc.Spec.Selector = s
@@ -56,3 +63,9 @@ func (b *Builder) WithPublishNotReady(val bool) *Builder {
 	b.svc.Spec.PublishNotReadyAddresses = val
 	return b
 }
+
+// WithClusterIP sets the spec cluster IP, e.g. None for headless services.
+func (b *Builder) WithClusterIP(ip string) *Builder {
+	b.svc.Spec.ClusterIP = ip
+	return b
+}
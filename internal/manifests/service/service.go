@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"sort"
+	"strings"
 
 	"github.com/ViaQ/logerr/kverrors"
 	"github.com/ViaQ/logerr/log"
@@ -13,6 +15,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// ManagedAnnotationsKey is the annotation listing the keys of the annotations managed on a
+// service, i.e. the ones set with WithManagedAnnotations
+const ManagedAnnotationsKey = "logging.openshift.io/managed-annotations"
+
 // EqualityFunc is the type for functions that compare two services.
 // Return true if two services are equal.
 type EqualityFunc func(current, desired *corev1.Service) bool
@@ -21,6 +27,34 @@ type EqualityFunc func(current, desired *corev1.Service) bool
 // by applying the values from the desired service.
 type MutateFunc func(current, desired *corev1.Service)
 
+// Get returns the k8s service for the given object key or an error.
+func Get(ctx context.Context, c client.Client, key client.ObjectKey) (*corev1.Service, error) {
+	svc := New(key.Name, key.Namespace, nil).Build()
+
+	if err := c.Get(ctx, key, svc); err != nil {
+		return svc, kverrors.Wrap(err, "failed to get service",
+			"name", svc.Name,
+			"namespace", svc.Namespace,
+		)
+	}
+
+	return svc, nil
+}
+
+// Delete attempts to delete a k8s service if existing or returns an error.
+func Delete(ctx context.Context, c client.Client, key client.ObjectKey) error {
+	svc := New(key.Name, key.Namespace, nil).Build()
+
+	if err := c.Delete(ctx, svc, &client.DeleteOptions{}); err != nil {
+		return kverrors.Wrap(err, "failed to delete service",
+			"name", svc.Name,
+			"namespace", svc.Namespace,
+		)
+	}
+
+	return nil
+}
+
 // CreateOrUpdate attempts first to create the given service. If the
 // service already exists and the provided comparison func detects any changes
 // an update is attempted. Updates are retried with backoff (See retry.DefaultRetry).
@@ -79,6 +113,25 @@ func Equal(current, desired *corev1.Service) bool {
 	return equality.Semantic.DeepEqual(current, desired)
 }
 
+// RequiresRecreate returns true if the current service differs from the desired
+// in fields that are immutable or not copied by Mutate, i.e. the cluster IP and
+// the service type. Such a service cannot be updated to the desired state.
+func RequiresRecreate(current, desired *corev1.Service) bool {
+	if desired.Spec.ClusterIP != "" && current.Spec.ClusterIP != desired.Spec.ClusterIP {
+		return true
+	}
+
+	return serviceType(current) != serviceType(desired)
+}
+
+// serviceType returns the type of the service with the API server default applied
+func serviceType(svc *corev1.Service) corev1.ServiceType {
+	if svc.Spec.Type == "" {
+		return corev1.ServiceTypeClusterIP
+	}
+	return svc.Spec.Type
+}
+
 // Mutate is a default mutation function for services
 // that copies only mutable fields from desired to current.
 func Mutate(current, desired *corev1.Service) {
@@ -88,3 +141,75 @@ func Mutate(current, desired *corev1.Service) {
 	current.Spec.Selector = desired.Spec.Selector
 	current.Spec.PublishNotReadyAddresses = desired.Spec.PublishNotReadyAddresses
 }
+
+// CompareManagedAnnotations returns true if the mutable fields of the services are equal.
+// Only the annotations managed on the services are compared, foreign ones are ignored.
+func CompareManagedAnnotations(current, desired *corev1.Service) bool {
+	return annotationsEqual(current.Annotations, mergeAnnotations(current.Annotations, desired.Annotations)) &&
+		equality.Semantic.DeepEqual(current.Labels, desired.Labels) &&
+		equality.Semantic.DeepEqual(current.Spec.Ports, desired.Spec.Ports) &&
+		equality.Semantic.DeepEqual(current.Spec.Selector, desired.Spec.Selector) &&
+		current.Spec.PublishNotReadyAddresses == desired.Spec.PublishNotReadyAddresses
+}
+
+// MutateManagedAnnotations is a mutation function for services like Mutate, except that
+// it merges the annotations managed on the services into the current ones. Foreign
+// annotations, e.g. added by a load balancer controller, are kept.
+func MutateManagedAnnotations(current, desired *corev1.Service) {
+	annotations := mergeAnnotations(current.Annotations, desired.Annotations)
+	Mutate(current, desired)
+	current.Annotations = annotations
+}
+
+// managedAnnotations returns a copy of the annotations recording their keys
+func managedAnnotations(a map[string]string) map[string]string {
+	annotations := make(map[string]string, len(a)+1)
+	keys := make([]string, 0, len(a))
+	for k, v := range a {
+		annotations[k] = v
+		keys = append(keys, k)
+	}
+
+	if len(keys) > 0 {
+		sort.Strings(keys)
+		annotations[ManagedAnnotationsKey] = strings.Join(keys, ",")
+	}
+
+	return annotations
+}
+
+// mergeAnnotations returns the current annotations without the formerly managed ones,
+// updated by the desired ones
+func mergeAnnotations(current, desired map[string]string) map[string]string {
+	merged := make(map[string]string, len(current)+len(desired))
+	for k, v := range current {
+		merged[k] = v
+	}
+
+	if managed := current[ManagedAnnotationsKey]; managed != "" {
+		for _, k := range strings.Split(managed, ",") {
+			if _, ok := desired[k]; !ok {
+				delete(merged, k)
+			}
+		}
+	}
+	delete(merged, ManagedAnnotationsKey)
+
+	for k, v := range desired {
+		merged[k] = v
+	}
+
+	return merged
+}
+
+func annotationsEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
@@ -52,6 +52,18 @@ func (b *Builder) WithNamespaceSelector(nss monitoringv1.NamespaceSelector) *Bui
 	return b
 }
 
+// WithTargetLabels sets the service labels transferred to the scraped metrics
+func (b *Builder) WithTargetLabels(l ...string) *Builder {
+	b.sm.Spec.TargetLabels = l
+	return b
+}
+
+// WithPodTargetLabels sets the pod labels transferred to the scraped metrics
+func (b *Builder) WithPodTargetLabels(l ...string) *Builder {
+	b.sm.Spec.PodTargetLabels = l
+	return b
+}
+
 // WithEndpoints appends endpoints to the servicemonitor
 func (b *Builder) WithEndpoints(ep ...monitoringv1.Endpoint) *Builder {
 	b.sm.Spec.Endpoints = append(b.sm.Spec.Endpoints, ep...)
@@ -80,10 +80,25 @@ func Equal(current, desired *monitoringv1.ServiceMonitor) bool {
 	return equality.Semantic.DeepEqual(current, desired)
 }
 
+// SpecEqual returns only true if the labels and the fields mutated by Mutate are equal
+func SpecEqual(current, desired *monitoringv1.ServiceMonitor) bool {
+	return equality.Semantic.DeepEqual(current.Labels, desired.Labels) &&
+		current.Spec.JobLabel == desired.Spec.JobLabel &&
+		equality.Semantic.DeepEqual(current.Spec.Endpoints, desired.Spec.Endpoints) &&
+		equality.Semantic.DeepEqual(current.Spec.Selector, desired.Spec.Selector) &&
+		equality.Semantic.DeepEqual(current.Spec.NamespaceSelector, desired.Spec.NamespaceSelector) &&
+		equality.Semantic.DeepEqual(current.Spec.TargetLabels, desired.Spec.TargetLabels) &&
+		equality.Semantic.DeepEqual(current.Spec.PodTargetLabels, desired.Spec.PodTargetLabels)
+}
+
 // Mutate is a default mutation function for servicemonitors
 // that copies only mutable fields from desired to current.
 func Mutate(current, desired *monitoringv1.ServiceMonitor) {
 	current.Labels = desired.Labels
 	current.Spec.JobLabel = desired.Spec.JobLabel
 	current.Spec.Endpoints = desired.Spec.Endpoints
+	current.Spec.Selector = desired.Spec.Selector
+	current.Spec.NamespaceSelector = desired.Spec.NamespaceSelector
+	current.Spec.TargetLabels = desired.Spec.TargetLabels
+	current.Spec.PodTargetLabels = desired.Spec.PodTargetLabels
 }
@@ -45,7 +45,7 @@ func Create(ctx context.Context, c client.Client, sts *appsv1.StatefulSet) error
 	return nil
 }
 
-// Update will update an existing statefulset if compare func returns true or else leave it unchanged. Updates are retried with backoff (See retry.DefaultRetry).
+// Update will update an existing statefulset unless the equality func reports the current statefulset on the api server equal to the desired one, in which case it is left unchanged. Updates are retried with backoff (See retry.DefaultRetry).
 // Returns on failure an non-nil error.
 func Update(ctx context.Context, c client.Client, sts *appsv1.StatefulSet, equal EqualityFunc, mutate MutateFunc) error {
 	current := &appsv1.StatefulSet{}
@@ -1,6 +1,13 @@
 package metrics
 
 import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
@@ -11,8 +18,16 @@ const (
 	ScheduledRestart string = "scheduled_restart"
 	ManagedState     string = "managed"
 	UnmanagedState   string = "unmanaged"
+
+	ReconcileSuccess string = "success"
+	ReconcileError   string = "error"
+	ReconcileRequeue string = "requeue"
 )
 
+// DefaultReconcileDurationBuckets are the buckets in seconds of the reconcile duration
+// histograms if none are configured
+var DefaultReconcileDurationBuckets = []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
 var (
 	restartCounter = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -26,17 +41,99 @@ var (
 			Help: "Number of Elasticsearch cluster that are in Managed state or Unmanaged state.",
 		}, []string{"state"})
 
+	reconcileDuration       = newReconcileDurationHistogram(DefaultReconcileDurationBuckets)
+	reconcileBusyDuration   = newReconcileBusyDurationHistogram(DefaultReconcileDurationBuckets)
+	waitDurationNanoseconds int64
+
 	metricList = []prometheus.Collector{
 		restartCounter,
 		esClusterManagementState,
 	}
 )
 
+func newReconcileDurationHistogram(buckets []float64) *prometheus.HistogramVec {
+	return prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "eo_elasticsearch_cr_reconcile_duration_seconds",
+			Help:    "Duration of the reconciles of the Elasticsearch clusters by result.",
+			Buckets: buckets,
+		}, []string{"result"})
+}
+
+func newReconcileBusyDurationHistogram(buckets []float64) *prometheus.HistogramVec {
+	return prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "eo_elasticsearch_cr_reconcile_busy_duration_seconds",
+			Help:    "Duration of the reconciles of the Elasticsearch clusters by result, without the waits on rollouts and the cluster.",
+			Buckets: buckets,
+		}, []string{"result"})
+}
+
 // This function registers the custom metrics to the kubernetes controller-runtime default metrics.
 func RegisterCustomMetrics() {
 	for _, metric := range metricList {
 		metrics.Registry.MustRegister(metric)
 	}
+	metrics.Registry.MustRegister(reconcileDuration, reconcileBusyDuration)
+}
+
+// SetReconcileDurationBuckets sets the buckets of the reconcile duration histograms from a
+// comma separated list of seconds, e.g. "1,10,60". It must be called before the metrics are
+// registered. An empty list keeps the default buckets.
+func SetReconcileDurationBuckets(buckets string) error {
+	if buckets == "" {
+		return nil
+	}
+
+	parsed := []float64{}
+	for _, bucket := range strings.Split(buckets, ",") {
+		seconds, err := strconv.ParseFloat(strings.TrimSpace(bucket), 64)
+		if err != nil || seconds <= 0 {
+			return fmt.Errorf("invalid reconcile duration bucket %q, expected a positive number of seconds", bucket)
+		}
+		parsed = append(parsed, seconds)
+	}
+	sort.Float64s(parsed)
+
+	reconcileDuration = newReconcileDurationHistogram(parsed)
+	reconcileBusyDuration = newReconcileBusyDurationHistogram(parsed)
+	return nil
+}
+
+// ObserveWait records the time spent waiting since start, e.g. on a rollout or the cluster
+// health, to exclude it from the busy reconcile durations. Use as defer ObserveWait(time.Now())
+func ObserveWait(start time.Time) {
+	atomic.AddInt64(&waitDurationNanoseconds, int64(time.Since(start)))
+}
+
+// ReconcileTimer measures the duration of a reconcile with and without the waits in it. The
+// waits are recorded process wide, thus reconciles must not run concurrently.
+type ReconcileTimer struct {
+	start time.Time
+	waits int64
+}
+
+// NewReconcileTimer starts measuring a reconcile
+func NewReconcileTimer() *ReconcileTimer {
+	return &ReconcileTimer{
+		start: time.Now(),
+		waits: atomic.LoadInt64(&waitDurationNanoseconds),
+	}
+}
+
+// ObserveDuration records the duration of the reconcile in the histograms for the result,
+// i.e. one of ReconcileSuccess, ReconcileError or ReconcileRequeue
+func (t *ReconcileTimer) ObserveDuration(result string) {
+	duration := time.Since(t.start)
+	waits := time.Duration(atomic.LoadInt64(&waitDurationNanoseconds) - t.waits)
+
+	busy := duration - waits
+	if busy < 0 {
+		busy = 0
+	}
+
+	reconcileDuration.With(prometheus.Labels{"result": result}).Observe(duration.Seconds())
+	reconcileBusyDuration.With(prometheus.Labels{"result": result}).Observe(busy.Seconds())
 }
 
 // Increment the metric value by "1" when the node restarts due to cert.
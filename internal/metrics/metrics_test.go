@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestSetReconcileDurationBuckets(t *testing.T) {
+	defer func() {
+		reconcileDuration = newReconcileDurationHistogram(DefaultReconcileDurationBuckets)
+		reconcileBusyDuration = newReconcileBusyDurationHistogram(DefaultReconcileDurationBuckets)
+	}()
+
+	if err := SetReconcileDurationBuckets("60, 1,10"); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+
+	reconcileDuration.With(prometheus.Labels{"result": ReconcileSuccess}).Observe(5)
+	histogram := writeHistogram(t, reconcileDuration, ReconcileSuccess)
+	if got := len(histogram.GetBucket()); got != 3 {
+		t.Errorf("Exp. 3 buckets but got %d", got)
+	}
+	if upper := histogram.GetBucket()[0].GetUpperBound(); upper != 1 {
+		t.Errorf("Exp. the buckets to be sorted but the first one was %v", upper)
+	}
+
+	for _, buckets := range []string{"1,ten", "0", "-1"} {
+		if err := SetReconcileDurationBuckets(buckets); err == nil {
+			t.Errorf("Exp. an error for the buckets %q", buckets)
+		}
+	}
+}
+
+func TestReconcileTimerExcludesWaits(t *testing.T) {
+	timer := NewReconcileTimer()
+	ObserveWait(time.Now().Add(-time.Hour))
+	timer.ObserveDuration(ReconcileRequeue)
+
+	total := writeHistogram(t, reconcileDuration, ReconcileRequeue)
+	busy := writeHistogram(t, reconcileBusyDuration, ReconcileRequeue)
+
+	if total.GetSampleCount() != 1 || busy.GetSampleCount() != 1 {
+		t.Fatalf("Exp. one observation each but got %d and %d", total.GetSampleCount(), busy.GetSampleCount())
+	}
+	if busy.GetSampleSum() >= time.Hour.Seconds() {
+		t.Errorf("Exp. the wait to be excluded from the busy duration but got %vs", busy.GetSampleSum())
+	}
+}
+
+func writeHistogram(t *testing.T, vec *prometheus.HistogramVec, result string) *dto.Histogram {
+	metric := &dto.Metric{}
+	if err := vec.With(prometheus.Labels{"result": result}).(prometheus.Metric).Write(metric); err != nil {
+		t.Fatalf("failed with error: %s", err)
+	}
+	return metric.GetHistogram()
+}
@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/ViaQ/logerr/log"
+)
+
+// enabled decides whether spans are recorded. The OpenTelemetry SDK and its OTLP exporter
+// are not dependencies of the operator, thus the spans are logged once they end.
+var enabled = false
+
+// SetEnabled sets whether the reconciles are traced
+func SetEnabled(enable bool) {
+	enabled = enable
+}
+
+// Span is the timing of a part of a reconcile. A nil span, as returned while tracing is
+// disabled, records nothing.
+type Span struct {
+	traceID string
+	spanID  string
+	parent  string
+	name    string
+	start   time.Time
+	kv      []interface{}
+}
+
+// Start returns the root span of a trace, nil if tracing is disabled
+func Start(name string, keysAndValues ...interface{}) *Span {
+	if !enabled {
+		return nil
+	}
+	return newSpan(newID(16), "", name, keysAndValues)
+}
+
+// Child returns a span of the trace of s, nil if s is
+func (s *Span) Child(name string, keysAndValues ...interface{}) *Span {
+	if s == nil {
+		return nil
+	}
+	return newSpan(s.traceID, s.spanID, name, keysAndValues)
+}
+
+// End logs the span with its duration
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	kv := append([]interface{}{
+		"trace_id", s.traceID,
+		"span_id", s.spanID,
+		"parent_id", s.parent,
+		"span", s.name,
+		"duration", time.Since(s.start).String(),
+	}, s.kv...)
+	log.Info("Span ended", kv...)
+}
+
+func newSpan(traceID, parent, name string, keysAndValues []interface{}) *Span {
+	return &Span{
+		traceID: traceID,
+		spanID:  newID(8),
+		parent:  parent,
+		name:    name,
+		start:   time.Now(),
+		kv:      keysAndValues,
+	}
+}
+
+func newID(size int) string {
+	id := make([]byte, size)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,40 @@
+package tracing
+
+import (
+	"testing"
+)
+
+func TestStartDisabled(t *testing.T) {
+	if span := Start("reconcile"); span != nil {
+		t.Errorf("Exp. no span while tracing is disabled but got %v", span)
+	}
+
+	var span *Span
+	if child := span.Child("step"); child != nil {
+		t.Errorf("Exp. no child of a nil span but got %v", child)
+	}
+	span.End()
+}
+
+func TestChildSharesTrace(t *testing.T) {
+	SetEnabled(true)
+	defer SetEnabled(false)
+
+	root := Start("reconcile", "cluster", "elasticsearch")
+	child := root.Child("step", "step", "configmaps")
+	defer root.End()
+	defer child.End()
+
+	if len(root.traceID) != 32 || len(root.spanID) != 16 {
+		t.Errorf("Exp. a 16 bytes trace id and 8 bytes span id but got %q and %q", root.traceID, root.spanID)
+	}
+	if root.parent != "" {
+		t.Errorf("Exp. the root span to have no parent but got %q", root.parent)
+	}
+	if child.traceID != root.traceID {
+		t.Errorf("Exp. the child span in trace %q but got %q", root.traceID, child.traceID)
+	}
+	if child.parent != root.spanID || child.spanID == root.spanID {
+		t.Errorf("Exp. the child span %q to have the parent %q but got %q", child.spanID, root.spanID, child.parent)
+	}
+}
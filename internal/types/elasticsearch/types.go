@@ -67,11 +67,12 @@ type GetIndexTemplateSettings struct {
 }
 
 type IndexTemplateSettings struct {
-	Unassigned       UnassignedIndexSetting `json:"unassigned,omitempty"`
-	Translog         TranslogIndexSetting   `json:"translog,omitempty"`
-	RefreshInterval  string                 `json:"refresh_interval,omitempty"`
-	NumberOfShards   string                 `json:"number_of_shards,omitempty"`
-	NumberOfReplicas string                 `json:"number_of_replicas,omitempty"`
+	Unassigned            UnassignedIndexSetting `json:"unassigned,omitempty"`
+	Translog              TranslogIndexSetting   `json:"translog,omitempty"`
+	RefreshInterval       string                 `json:"refresh_interval,omitempty"`
+	NumberOfShards        string                 `json:"number_of_shards,omitempty"`
+	NumberOfReplicas      string                 `json:"number_of_replicas,omitempty"`
+	NumberOfRoutingShards string                 `json:"number_of_routing_shards,omitempty"`
 }
 
 type UnassignedIndexSetting struct {
@@ -98,12 +99,13 @@ type IndexSettings struct {
 }
 
 type IndexingSettings struct {
-	NumberOfShards   int32                 `json:"number_of_shards,string,omitempty"`
-	NumberOfReplicas int32                 `json:"number_of_replicas,string,omitempty"`
-	Format           int32                 `json:"format,omitempty"`
-	Blocks           *IndexBlocksSettings  `json:"blocks,omitempty"`
-	Mapper           *IndexMapperSettings  `json:"mapper,omitempty"`
-	Mapping          *IndexMappingSettings `json:"mapping,omitempty"`
+	NumberOfShards        int32                 `json:"number_of_shards,string,omitempty"`
+	NumberOfReplicas      int32                 `json:"number_of_replicas,string,omitempty"`
+	NumberOfRoutingShards int32                 `json:"number_of_routing_shards,string,omitempty"`
+	Format                int32                 `json:"format,omitempty"`
+	Blocks                *IndexBlocksSettings  `json:"blocks,omitempty"`
+	Mapper                *IndexMapperSettings  `json:"mapper,omitempty"`
+	Mapping               *IndexMappingSettings `json:"mapping,omitempty"`
 }
 
 type IndexBlocksSettings struct {
@@ -111,6 +113,19 @@ type IndexBlocksSettings struct {
 	ReadOnlyAllowDelete *string `json:"read_only_allow_delete"`
 }
 
+// IndexBlock is the name of an index block setting, i.e. index.blocks.<block>
+type IndexBlock string
+
+const (
+	IndexBlockWrite    IndexBlock = "write"
+	IndexBlockRead     IndexBlock = "read"
+	IndexBlockMetadata IndexBlock = "metadata"
+	IndexBlockReadOnly IndexBlock = "read_only"
+)
+
+// IndexBlocks are all the index blocks that can be set via the index settings API
+var IndexBlocks = []IndexBlock{IndexBlockWrite, IndexBlockRead, IndexBlockMetadata, IndexBlockReadOnly}
+
 type IndexMapperSettings struct {
 	Dynamic bool `json:"dynamic"`
 }
@@ -184,6 +199,28 @@ type NodeStateResponse struct {
 	Attributes       map[string]string `json:"attributes,omitempty"`
 }
 
+type LicenseResponse struct {
+	License License `json:"license"`
+}
+
+type License struct {
+	Status             string `json:"status,omitempty"`
+	UID                string `json:"uid,omitempty"`
+	Type               string `json:"type,omitempty"`
+	ExpiryDateInMillis int64  `json:"expiry_date_in_millis,omitempty"`
+}
+
+type PendingTasksResponse struct {
+	Tasks []PendingTask `json:"tasks,omitempty"`
+}
+
+type PendingTask struct {
+	InsertOrder       int64  `json:"insert_order,omitempty"`
+	Priority          string `json:"priority,omitempty"`
+	Source            string `json:"source,omitempty"`
+	TimeInQueueMillis int64  `json:"time_in_queue_millis,omitempty"`
+}
+
 type StatsNodesResponse struct {
 	Nodes StatsNode `json:"nodes,omitempty"`
 }
@@ -192,3 +229,51 @@ type StatsNode struct {
 	Versions []string       `json:"versions,omitempty"`
 	Count    map[string]int `json:"count,omitempty"`
 }
+
+type CatAllocationResponses []CatAllocationResponse
+
+type CatAllocationResponse struct {
+	Node   string `json:"node,omitempty"`
+	Shards string `json:"shards,omitempty"`
+}
+
+type CatShardsResponses []CatShardsResponse
+
+type CatShardsResponse struct {
+	Index  string `json:"index,omitempty"`
+	Shard  string `json:"shard,omitempty"`
+	PriRep string `json:"prirep,omitempty"`
+	State  string `json:"state,omitempty"`
+	Node   string `json:"node,omitempty"`
+}
+
+// AllocationExplainResponse explains why a shard is unassigned, see _cluster/allocation/explain
+type AllocationExplainResponse struct {
+	Index                   string                   `json:"index,omitempty"`
+	Shard                   int32                    `json:"shard"`
+	Primary                 bool                     `json:"primary"`
+	CurrentState            string                   `json:"current_state,omitempty"`
+	UnassignedInfo          *UnassignedInfo          `json:"unassigned_info,omitempty"`
+	CanAllocate             string                   `json:"can_allocate,omitempty"`
+	AllocateExplanation     string                   `json:"allocate_explanation,omitempty"`
+	NodeAllocationDecisions []NodeAllocationDecision `json:"node_allocation_decisions,omitempty"`
+}
+
+type UnassignedInfo struct {
+	Reason               string `json:"reason,omitempty"`
+	At                   string `json:"at,omitempty"`
+	Details              string `json:"details,omitempty"`
+	LastAllocationStatus string `json:"last_allocation_status,omitempty"`
+}
+
+type NodeAllocationDecision struct {
+	NodeName     string              `json:"node_name,omitempty"`
+	NodeDecision string              `json:"node_decision,omitempty"`
+	Deciders     []AllocationDecider `json:"deciders,omitempty"`
+}
+
+type AllocationDecider struct {
+	Decider     string `json:"decider,omitempty"`
+	Decision    string `json:"decision,omitempty"`
+	Explanation string `json:"explanation,omitempty"`
+}
@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+)
+
+// PropagatedAnnotationsKey records the keys of the annotations propagated from the
+// Elasticsearch CR to an object, so that annotations no longer propagated are removed
+const PropagatedAnnotationsKey = "logging.openshift.io/propagated-annotations"
+
+// SelectPropagatedAnnotations returns the annotations with the listed keys or the prefix
+// of a listed key ending in /
+func SelectPropagatedAnnotations(annotations map[string]string, keys []string) map[string]string {
+	selected := map[string]string{}
+	for k, v := range annotations {
+		if k == PropagatedAnnotationsKey {
+			continue
+		}
+		for _, key := range keys {
+			if k == key || (strings.HasSuffix(key, "/") && strings.HasPrefix(k, key)) {
+				selected[k] = v
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// WithPropagatedAnnotations returns a copy of the annotations of an object extended by the
+// propagated ones, recording their keys. The annotations of the object take precedence.
+func WithPropagatedAnnotations(annotations, propagated map[string]string) map[string]string {
+	merged := make(map[string]string, len(annotations)+len(propagated)+1)
+	for k, v := range annotations {
+		merged[k] = v
+	}
+
+	keys := []string{}
+	for k, v := range propagated {
+		if _, ok := merged[k]; ok {
+			continue
+		}
+		merged[k] = v
+		keys = append(keys, k)
+	}
+
+	if len(keys) > 0 {
+		sort.Strings(keys)
+		merged[PropagatedAnnotationsKey] = strings.Join(keys, ",")
+	}
+	return merged
+}
+
+// ContainsPropagatedAnnotations returns true if the current annotations hold the propagated
+// annotations of the desired ones and none formerly propagated
+func ContainsPropagatedAnnotations(current, desired map[string]string) bool {
+	if current[PropagatedAnnotationsKey] != desired[PropagatedAnnotationsKey] {
+		return false
+	}
+	for _, k := range propagatedKeys(desired) {
+		if value, ok := current[k]; !ok || value != desired[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// MergePropagatedAnnotations returns the current annotations without the formerly
+// propagated ones, updated by the propagated annotations of the desired ones. Other
+// annotations are kept.
+func MergePropagatedAnnotations(current, desired map[string]string) map[string]string {
+	merged := make(map[string]string, len(current)+len(desired))
+	for k, v := range current {
+		merged[k] = v
+	}
+
+	for _, k := range propagatedKeys(current) {
+		if _, ok := desired[k]; !ok {
+			delete(merged, k)
+		}
+	}
+	delete(merged, PropagatedAnnotationsKey)
+
+	for _, k := range propagatedKeys(desired) {
+		merged[k] = desired[k]
+	}
+	if keys, ok := desired[PropagatedAnnotationsKey]; ok {
+		merged[PropagatedAnnotationsKey] = keys
+	}
+	return merged
+}
+
+// PropagatedAnnotations returns only the annotations recorded as propagated
+func PropagatedAnnotations(annotations map[string]string) map[string]string {
+	propagated := map[string]string{}
+	for _, k := range propagatedKeys(annotations) {
+		if v, ok := annotations[k]; ok {
+			propagated[k] = v
+		}
+	}
+	return propagated
+}
+
+func propagatedKeys(annotations map[string]string) []string {
+	keys := annotations[PropagatedAnnotationsKey]
+	if keys == "" {
+		return nil
+	}
+	return strings.Split(keys, ",")
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSelectPropagatedAnnotations(t *testing.T) {
+	annotations := map[string]string{
+		"cost-center":                 "1234",
+		"example.com/owner":           "team-a",
+		"example.com/tier":            "gold",
+		"example.org/owner":           "team-b",
+		"not-propagated":              "value",
+		PropagatedAnnotationsKey:      "cost-center",
+		"logging.openshift.io/custom": "value",
+	}
+
+	got := SelectPropagatedAnnotations(annotations, []string{"cost-center", "example.com/", PropagatedAnnotationsKey})
+	want := map[string]string{
+		"cost-center":       "1234",
+		"example.com/owner": "team-a",
+		"example.com/tier":  "gold",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Exp. only the listed keys and prefixes to be selected:\n%s", diff)
+	}
+}
+
+func TestWithPropagatedAnnotations(t *testing.T) {
+	own := map[string]string{"owned": "operator"}
+	got := WithPropagatedAnnotations(own, map[string]string{"owned": "cr", "b": "2", "a": "1"})
+	want := map[string]string{
+		"owned":                  "operator",
+		"a":                      "1",
+		"b":                      "2",
+		PropagatedAnnotationsKey: "a,b",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Exp. the annotations of the object to win and the propagated keys to be recorded:\n%s", diff)
+	}
+	if _, ok := own["a"]; ok {
+		t.Errorf("Exp. the annotations of the object to be left unchanged")
+	}
+
+	if got := WithPropagatedAnnotations(own, nil); got[PropagatedAnnotationsKey] != "" {
+		t.Errorf("Exp. no record without propagated annotations but got %v", got)
+	}
+}
+
+func TestContainsAndMergePropagatedAnnotations(t *testing.T) {
+	current := map[string]string{
+		"foreign":                "kept",
+		"removed":                "old",
+		"changed":                "old",
+		PropagatedAnnotationsKey: "changed,removed",
+	}
+	desired := WithPropagatedAnnotations(nil, map[string]string{"changed": "new", "added": "new"})
+
+	if ContainsPropagatedAnnotations(current, desired) {
+		t.Fatalf("Exp. changed propagated annotations to differ")
+	}
+
+	merged := MergePropagatedAnnotations(current, desired)
+	want := map[string]string{
+		"foreign":                "kept",
+		"changed":                "new",
+		"added":                  "new",
+		PropagatedAnnotationsKey: "added,changed",
+	}
+	if diff := cmp.Diff(want, merged); diff != "" {
+		t.Errorf("Exp. formerly propagated annotations to be replaced and others kept:\n%s", diff)
+	}
+	if !ContainsPropagatedAnnotations(merged, desired) {
+		t.Errorf("Exp. the merged annotations to contain the propagated ones")
+	}
+
+	cleared := MergePropagatedAnnotations(merged, map[string]string{})
+	if diff := cmp.Diff(map[string]string{"foreign": "kept"}, cleared); diff != "" {
+		t.Errorf("Exp. all propagated annotations to be removed once none is propagated:\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"added": "new", "changed": "new"}, PropagatedAnnotations(merged)); diff != "" {
+		t.Errorf("Exp. only the recorded annotations to be returned:\n%s", diff)
+	}
+}
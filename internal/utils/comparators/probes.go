@@ -0,0 +1,39 @@
+package comparators
+
+import (
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// AreProbesSame compares two probes for equality with the API server defaults
+// applied, since those are set on the probes of created resources
+func AreProbesSame(lhs, rhs *v1.Probe) bool {
+	if lhs == nil || rhs == nil {
+		return lhs == rhs
+	}
+
+	return reflect.DeepEqual(withProbeDefaults(*lhs), withProbeDefaults(*rhs))
+}
+
+func withProbeDefaults(probe v1.Probe) v1.Probe {
+	if probe.TimeoutSeconds == 0 {
+		probe.TimeoutSeconds = 1
+	}
+	if probe.PeriodSeconds == 0 {
+		probe.PeriodSeconds = 10
+	}
+	if probe.SuccessThreshold == 0 {
+		probe.SuccessThreshold = 1
+	}
+	if probe.FailureThreshold == 0 {
+		probe.FailureThreshold = 3
+	}
+	if probe.HTTPGet != nil && probe.HTTPGet.Scheme == "" {
+		httpGet := *probe.HTTPGet
+		httpGet.Scheme = v1.URISchemeHTTP
+		probe.HTTPGet = &httpGet
+	}
+
+	return probe
+}
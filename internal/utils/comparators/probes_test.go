@@ -0,0 +1,38 @@
+package comparators
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestAreProbesSameAppliesDefaults(t *testing.T) {
+	desired := &v1.Probe{
+		TimeoutSeconds: 30,
+		Handler: v1.Handler{
+			Exec: &v1.ExecAction{Command: []string{"readiness.sh"}},
+		},
+	}
+	current := &v1.Probe{
+		TimeoutSeconds:   30,
+		PeriodSeconds:    10,
+		SuccessThreshold: 1,
+		FailureThreshold: 3,
+		Handler: v1.Handler{
+			Exec: &v1.ExecAction{Command: []string{"readiness.sh"}},
+		},
+	}
+
+	if !AreProbesSame(current, desired) {
+		t.Errorf("Exp. %v and %v to be the same", current, desired)
+	}
+
+	desired.Exec.Command = []string{"data-readiness.sh"}
+	if AreProbesSame(current, desired) {
+		t.Errorf("Exp. %v and %v to differ", current, desired)
+	}
+
+	if AreProbesSame(current, nil) {
+		t.Errorf("Exp. %v and no probe to differ", current)
+	}
+}
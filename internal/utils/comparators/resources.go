@@ -20,6 +20,14 @@ func AreResourceRequementsSame(lhs, rhs v1.ResourceRequirements) bool {
 	if rhs.Requests.Memory().Cmp(*lhs.Requests.Memory()) != 0 {
 		return false
 	}
+	// Check ephemeral storage limits
+	if rhs.Limits.StorageEphemeral().Cmp(*lhs.Limits.StorageEphemeral()) != 0 {
+		return false
+	}
+	// Check ephemeral storage requests
+	if rhs.Requests.StorageEphemeral().Cmp(*lhs.Requests.StorageEphemeral()) != 0 {
+		return false
+	}
 
 	return true
 }
@@ -0,0 +1,66 @@
+package comparators
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestAreResourceRequementsSameComparesEffectiveValues(t *testing.T) {
+	current := v1.ResourceRequirements{
+		Limits: v1.ResourceList{
+			v1.ResourceMemory: resource.MustParse("2048Mi"),
+		},
+		Requests: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("0.1"),
+			v1.ResourceMemory: resource.MustParse("1Gi"),
+		},
+	}
+	desired := v1.ResourceRequirements{
+		Limits: v1.ResourceList{
+			v1.ResourceMemory: resource.MustParse("2Gi"),
+		},
+		Requests: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("100m"),
+			v1.ResourceMemory: resource.MustParse("1024Mi"),
+		},
+	}
+
+	if !AreResourceRequementsSame(current, desired) {
+		t.Errorf("Exp. %v and %v to be the same", current, desired)
+	}
+
+	desired.Limits[v1.ResourceMemory] = resource.MustParse("4Gi")
+	if AreResourceRequementsSame(current, desired) {
+		t.Errorf("Exp. %v and %v to differ", current, desired)
+	}
+}
+
+func TestAreResourceRequementsSameComparesEphemeralStorage(t *testing.T) {
+	current := v1.ResourceRequirements{
+		Requests: v1.ResourceList{
+			v1.ResourceMemory: resource.MustParse("1Gi"),
+		},
+	}
+	desired := v1.ResourceRequirements{
+		Requests: v1.ResourceList{
+			v1.ResourceMemory:           resource.MustParse("1Gi"),
+			v1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+		},
+	}
+
+	if AreResourceRequementsSame(current, desired) {
+		t.Errorf("Exp. %v and %v to differ in the ephemeral storage request", current, desired)
+	}
+
+	current.Requests[v1.ResourceEphemeralStorage] = resource.MustParse("1024Mi")
+	if !AreResourceRequementsSame(current, desired) {
+		t.Errorf("Exp. %v and %v to be the same", current, desired)
+	}
+
+	desired.Limits = v1.ResourceList{v1.ResourceEphemeralStorage: resource.MustParse("5Gi")}
+	if AreResourceRequementsSame(current, desired) {
+		t.Errorf("Exp. %v and %v to differ in the ephemeral storage limit", current, desired)
+	}
+}
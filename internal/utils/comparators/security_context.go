@@ -0,0 +1,100 @@
+package comparators
+
+import (
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// ArePodSecurityContextsSame compares the user, group, fsGroup, runAsNonRoot and sysctls
+// of two pod security contexts for equality
+func ArePodSecurityContextsSame(lhs, rhs *v1.PodSecurityContext) bool {
+	if lhs == nil {
+		lhs = &v1.PodSecurityContext{}
+	}
+
+	if rhs == nil {
+		rhs = &v1.PodSecurityContext{}
+	}
+
+	return isInt64PtrSame(lhs.RunAsUser, rhs.RunAsUser) &&
+		isInt64PtrSame(lhs.RunAsGroup, rhs.RunAsGroup) &&
+		isInt64PtrSame(lhs.FSGroup, rhs.FSGroup) &&
+		isBoolPtrSame(lhs.RunAsNonRoot, rhs.RunAsNonRoot) &&
+		areSysctlsSame(lhs.Sysctls, rhs.Sysctls)
+}
+
+// ContainsSamePodSecurityContext checks that the user, group, fsGroup and runAsNonRoot set in rhs
+// are the same within lhs. This follows our other patterns of "current, desired"
+// since the platform may inject values into the security context of rolled out pods.
+// The sysctls are never injected and thus need to be the same.
+func ContainsSamePodSecurityContext(lhs, rhs *v1.PodSecurityContext) bool {
+	if lhs == nil {
+		lhs = &v1.PodSecurityContext{}
+	}
+
+	if rhs == nil {
+		return len(lhs.Sysctls) == 0
+	}
+
+	if !areSysctlsSame(lhs.Sysctls, rhs.Sysctls) {
+		return false
+	}
+
+	if rhs.RunAsUser != nil && !isInt64PtrSame(lhs.RunAsUser, rhs.RunAsUser) {
+		return false
+	}
+
+	if rhs.RunAsGroup != nil && !isInt64PtrSame(lhs.RunAsGroup, rhs.RunAsGroup) {
+		return false
+	}
+
+	if rhs.FSGroup != nil && !isInt64PtrSame(lhs.FSGroup, rhs.FSGroup) {
+		return false
+	}
+
+	if rhs.RunAsNonRoot != nil && !isBoolPtrSame(lhs.RunAsNonRoot, rhs.RunAsNonRoot) {
+		return false
+	}
+
+	return true
+}
+
+func isInt64PtrSame(lhs, rhs *int64) bool {
+	if lhs == nil || rhs == nil {
+		return lhs == rhs
+	}
+
+	return *lhs == *rhs
+}
+
+func isBoolPtrSame(lhs, rhs *bool) bool {
+	if lhs == nil || rhs == nil {
+		return lhs == rhs
+	}
+
+	return *lhs == *rhs
+}
+
+// areSysctlsSame compares two lists of sysctls regardless of their order
+func areSysctlsSame(lhs, rhs []v1.Sysctl) bool {
+	if len(lhs) != len(rhs) {
+		return false
+	}
+
+	sorted := func(sysctls []v1.Sysctl) []v1.Sysctl {
+		sysctls = append([]v1.Sysctl{}, sysctls...)
+		sort.Slice(sysctls, func(i, j int) bool {
+			return sysctls[i].Name < sysctls[j].Name
+		})
+		return sysctls
+	}
+
+	lhs, rhs = sorted(lhs), sorted(rhs)
+	for i := range lhs {
+		if lhs[i] != rhs[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,30 @@
+package comparators
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// AreVolumeDevicesSame checks that lhs and rhs attach the same raw block devices
+// regardless of their order
+func AreVolumeDevicesSame(lhs, rhs []corev1.VolumeDevice) bool {
+	if len(lhs) != len(rhs) {
+		return false
+	}
+
+	for _, rVolumeDevice := range rhs {
+		found := false
+
+		for _, lVolumeDevice := range lhs {
+			if lVolumeDevice.Name == rVolumeDevice.Name && lVolumeDevice.DevicePath == rVolumeDevice.DevicePath {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
@@ -28,3 +28,9 @@ func ContainsSameVolumeMounts(lhs, rhs []corev1.VolumeMount) bool {
 
 	return true
 }
+
+// AreVolumeMountsSame checks that lhs and rhs contain the same volume mounts,
+// thus a mount added to or removed from either side is found
+func AreVolumeMountsSame(lhs, rhs []corev1.VolumeMount) bool {
+	return ContainsSameVolumeMounts(lhs, rhs) && ContainsSameVolumeMounts(rhs, lhs)
+}
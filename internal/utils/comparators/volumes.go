@@ -0,0 +1,92 @@
+package comparators
+
+import (
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// defaultTokenExpirationSeconds is the expiration the API server defaults projected tokens to
+const defaultTokenExpirationSeconds int64 = 3600
+
+// defaultVolumeMode is the file mode the API server defaults volume sources to
+const defaultVolumeMode int32 = 0644
+
+// ContainsSameVolumes checks that all of rhs (desired) are found with the same source in
+// the volumes of the same name of lhs (current), the API server defaults applied to both
+func ContainsSameVolumes(lhs, rhs []corev1.Volume) bool {
+	for _, rVolume := range rhs {
+		found := false
+
+		for _, lVolume := range lhs {
+			if lVolume.Name != rVolume.Name {
+				continue
+			}
+			found = true
+
+			if !reflect.DeepEqual(defaultedVolumeSource(lVolume.VolumeSource), defaultedVolumeSource(rVolume.VolumeSource)) {
+				return false
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// defaultedVolumeSource returns a copy of the source with the defaults of the API server
+// applied to the fields left empty
+func defaultedVolumeSource(source corev1.VolumeSource) corev1.VolumeSource {
+	source = *source.DeepCopy()
+
+	if reflect.DeepEqual(source, corev1.VolumeSource{}) {
+		source.EmptyDir = &corev1.EmptyDirVolumeSource{}
+	}
+	if source.HostPath != nil && source.HostPath.Type == nil {
+		unset := corev1.HostPathUnset
+		source.HostPath.Type = &unset
+	}
+	if source.Secret != nil {
+		source.Secret.DefaultMode = defaultedVolumeMode(source.Secret.DefaultMode)
+	}
+	if source.ConfigMap != nil {
+		source.ConfigMap.DefaultMode = defaultedVolumeMode(source.ConfigMap.DefaultMode)
+	}
+	if source.DownwardAPI != nil {
+		source.DownwardAPI.DefaultMode = defaultedVolumeMode(source.DownwardAPI.DefaultMode)
+		defaultDownwardAPIVolumeFiles(source.DownwardAPI.Items)
+	}
+	if source.Projected != nil {
+		source.Projected.DefaultMode = defaultedVolumeMode(source.Projected.DefaultMode)
+		for _, projection := range source.Projected.Sources {
+			if projection.DownwardAPI != nil {
+				defaultDownwardAPIVolumeFiles(projection.DownwardAPI.Items)
+			}
+			if token := projection.ServiceAccountToken; token != nil && token.ExpirationSeconds == nil {
+				expiration := defaultTokenExpirationSeconds
+				token.ExpirationSeconds = &expiration
+			}
+		}
+	}
+
+	return source
+}
+
+func defaultedVolumeMode(mode *int32) *int32 {
+	if mode == nil {
+		defaulted := defaultVolumeMode
+		return &defaulted
+	}
+	return mode
+}
+
+func defaultDownwardAPIVolumeFiles(files []corev1.DownwardAPIVolumeFile) {
+	for _, file := range files {
+		if file.FieldRef != nil && file.FieldRef.APIVersion == "" {
+			file.FieldRef.APIVersion = "v1"
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package utils
+
+import (
+	"k8s.io/client-go/rest"
+)
+
+// SetClientRateLimits sets the rate limits of the clients built from the config. Values not
+// greater than zero keep the ones of the config, e.g. the controller-runtime defaults of 20
+// queries per second and bursts of 30.
+func SetClientRateLimits(cfg *rest.Config, qps float32, burst int) *rest.Config {
+	if qps > 0 {
+		cfg.QPS = qps
+	}
+	if burst > 0 {
+		cfg.Burst = burst
+	}
+	return cfg
+}
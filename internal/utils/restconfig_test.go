@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestSetClientRateLimits(t *testing.T) {
+	tests := []struct {
+		desc      string
+		qps       float32
+		burst     int
+		wantQPS   float32
+		wantBurst int
+	}{
+		{desc: "configured limits", qps: 50, burst: 100, wantQPS: 50, wantBurst: 100},
+		{desc: "unset limits", qps: 0, burst: 0, wantQPS: 0, wantBurst: 0},
+		{desc: "negative burst", qps: 20, burst: -1, wantQPS: 20, wantBurst: 0},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			cfg := SetClientRateLimits(&rest.Config{Host: "https://api.example.com"}, test.qps, test.burst)
+			if cfg.QPS != test.wantQPS || cfg.Burst != test.wantBurst {
+				t.Errorf("Expected QPS %v and burst %d but got %v and %d", test.wantQPS, test.wantBurst, cfg.QPS, cfg.Burst)
+			}
+			if cfg.Host != "https://api.example.com" {
+				t.Errorf("Expected the remaining config to be kept but got host %q", cfg.Host)
+			}
+		})
+	}
+}
@@ -14,9 +14,12 @@ import (
 
 	"github.com/ViaQ/logerr/kverrors"
 
+	"github.com/go-logr/logr"
 	configv1 "github.com/openshift/api/config/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/uuid"
 
 	"github.com/ViaQ/logerr/log"
 )
@@ -25,8 +28,40 @@ const (
 	DefaultWorkingDir = "/tmp/ocp-eo"
 	OsNodeLabel       = "kubernetes.io/os"
 	LinuxValue        = "linux"
+
+	// InfraNodeSelectorEnv names the operator environment variable holding the node selector
+	// applied to all pods created by the operator, e.g. node-role.kubernetes.io/infra=
+	InfraNodeSelectorEnv = "INFRA_NODE_SELECTOR"
 )
 
+// NewReconcileLogger returns the logger for a single reconcile of the named custom resource.
+// All lines logged through it carry the same reconcile_id to trace a reconcile through
+// the logs of concurrent ones.
+func NewReconcileLogger(name, namespace string) logr.Logger {
+	return log.WithValues("reconcile_id", string(uuid.NewUUID()), "cluster", name, "namespace", namespace)
+}
+
+// MergeInfraNodeSelector returns the infra node selector of the operator merged with the
+// given selectors. The given selectors take precedence on key conflicts.
+func MergeInfraNodeSelector(selectors map[string]string) map[string]string {
+	value, ok := os.LookupEnv(InfraNodeSelectorEnv)
+	if !ok || value == "" {
+		return selectors
+	}
+
+	merged, err := labels.ConvertSelectorToLabelsMap(value)
+	if err != nil {
+		log.Error(err, "Ignoring invalid infra node selector", "env", InfraNodeSelectorEnv, "value", value)
+		return selectors
+	}
+
+	for key, val := range selectors {
+		merged[key] = val
+	}
+
+	return merged
+}
+
 // EnsureLinuxNodeSelector takes given selector map and returns a selector map with linux node selector added into it.
 // If there is already a node type selector and is different from "linux" then it is overridden and warning is logged.
 // See https://kubernetes.io/docs/concepts/configuration/assign-pod-node/#interlude-built-in-node-labels
@@ -87,3 +87,28 @@ func TestCompareResources(t *testing.T) {
 		}
 	}
 }
+
+func TestMergeInfraNodeSelector(t *testing.T) {
+	os.Setenv(InfraNodeSelectorEnv, "node-role.kubernetes.io/infra=,zone=a")
+	defer os.Unsetenv(InfraNodeSelectorEnv)
+
+	res := MergeInfraNodeSelector(map[string]string{"zone": "b", "disk": "ssd"})
+
+	expected := map[string]string{
+		"node-role.kubernetes.io/infra": "",
+		"zone":                          "b",
+		"disk":                          "ssd",
+	}
+	if !AreMapsSame(res, expected) {
+		t.Errorf("Expected the component selectors to take precedence over the infra node selector %v but got %v", expected, res)
+	}
+}
+
+func TestMergeInfraNodeSelectorUndefined(t *testing.T) {
+	os.Unsetenv(InfraNodeSelectorEnv)
+
+	selectors := map[string]string{"disk": "ssd"}
+	if res := MergeInfraNodeSelector(selectors); !AreMapsSame(res, selectors) {
+		t.Errorf("Expected the selectors %v to be unchanged but got %v", selectors, res)
+	}
+}
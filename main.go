@@ -5,8 +5,14 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"time"
 
+	"github.com/openshift/elasticsearch-operator/internal/elasticsearch"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/apply"
+	"github.com/openshift/elasticsearch-operator/internal/manifests/image"
 	"github.com/openshift/elasticsearch-operator/internal/metrics"
+	"github.com/openshift/elasticsearch-operator/internal/tracing"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
 
 	apiruntime "k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -49,12 +55,88 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == validateCommand {
+		os.Exit(runValidate(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	var enableLeaderElection bool
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	var requeueInterval time.Duration
+	flag.DurationVar(&requeueInterval, "requeue-interval", controllers.DefaultRequeueInterval,
+		"The interval after which a converged Elasticsearch cluster is reconciled again "+
+			"to refresh its health and status and to catch external changes.")
+	var serverSideApply bool
+	flag.BoolVar(&serverSideApply, "server-side-apply", false,
+		"Use server side apply for the configmaps and the Kibana deployment. "+
+			"The operator then owns only the fields it sets, taking them over from other managers.")
+	var imageRegistry string
+	flag.StringVar(&imageRegistry, "image-registry", "",
+		"The registry replacing the one of every image deployed by the operator, e.g. for air-gapped mirrors.")
+	var imageMirrors string
+	flag.StringVar(&imageMirrors, "image-mirrors", "",
+		"Comma separated list of source=mirror images replacing the images deployed by the operator. "+
+			"Mirrors take precedence over the image registry.")
+	var rolloutTimeouts elasticsearch.RolloutTimeouts
+	flag.DurationVar(&rolloutTimeouts.InitialRollout, "initial-rollout-timeout", elasticsearch.DefaultRolloutTimeouts.InitialRollout,
+		"The time to wait for a created node deployment to be assigned its first revision before "+
+			"checking it again on the next reconcile, e.g. on heavily loaded API servers.")
+	flag.DurationVar(&rolloutTimeouts.NodeRollout, "node-rollout-timeout", elasticsearch.DefaultRolloutTimeouts.NodeRollout,
+		"The time to wait for the pods of a node to match its updated template.")
+	var maxUnassignedShards int
+	flag.IntVar(&maxUnassignedShards, "max-unassigned-shards", -1,
+		"The number of unassigned shards above which no new node rollout is started, "+
+			"e.g. while the cluster recovers from a previous one. A negative value disables the cap.")
+	var generateMissingSecrets bool
+	flag.BoolVar(&generateMissingSecrets, "generate-missing-secrets", false,
+		"Generate self-signed certificates into the secret of clusters missing it instead of waiting "+
+			"for it to be provided, if they opt into the operator cert management.")
+	var masterOnlyResourceDefaults bool
+	flag.BoolVar(&masterOnlyResourceDefaults, "master-only-resource-defaults", false,
+		"Default master only nodes requesting no resources to 2Gi of memory instead of the 4Gi of the "+
+			"other nodes. Enabling it rolls the master only nodes of existing clusters with less heap.")
+	var clientQPS float64
+	flag.Float64Var(&clientQPS, "kube-api-qps", 0,
+		"The maximum queries per second of the operator to the API server, e.g. 50 for fleets of "+
+			"hundreds of Elasticsearch clusters. Zero keeps the controller-runtime default of 20.")
+	var clientBurst int
+	flag.IntVar(&clientBurst, "kube-api-burst", 0,
+		"The maximum burst of queries of the operator to the API server, e.g. 100 for fleets of "+
+			"hundreds of Elasticsearch clusters. Zero keeps the controller-runtime default of 30.")
+	var unschedulableTimeout time.Duration
+	flag.DurationVar(&unschedulableTimeout, "unschedulable-timeout", elasticsearch.DefaultUnschedulableTimeout,
+		"The time a pod of an Elasticsearch node may fail to be scheduled, e.g. while nodes are added "+
+			"to the cluster, before it is reported by the PodsUnschedulable condition and a warning event.")
+	var licenseExpiryWarning time.Duration
+	flag.DurationVar(&licenseExpiryWarning, "license-expiry-warning", elasticsearch.DefaultLicenseExpiryWarning,
+		"The time before the license of an Elasticsearch cluster expires it is reported by the "+
+			"LicenseExpiring condition and a warning event.")
+	var reconcileMode string
+	flag.StringVar(&reconcileMode, "reconcile-mode", string(elasticsearch.ReconcileBestEffort),
+		"Whether failing steps of the reconcile not critical to running the Elasticsearch cluster, e.g. "+
+			"the ServiceMonitor, abort it (fail-fast) or are only reported by the Degraded condition and a "+
+			"warning event (best-effort). A failing PrometheusRule is only reported in both modes.")
+	var reconcileDurationBuckets string
+	flag.StringVar(&reconcileDurationBuckets, "reconcile-duration-buckets", "",
+		"Comma separated list of the bucket boundaries in seconds of the reconcile duration histograms, "+
+			"e.g. 1,10,60,300.")
+	var traceReconciles bool
+	flag.BoolVar(&traceReconciles, "trace-reconciles", false,
+		"Log the spans of the reconciles, their steps, node rollouts and Elasticsearch requests with their "+
+			"durations. They are logged instead of exported to OTLP, the OpenTelemetry SDK not being a dependency.")
 	flag.Parse()
 
+	apply.SetServerSideApply(serverSideApply)
+	image.SetRegistry(imageRegistry)
+	elasticsearch.SetRolloutTimeouts(rolloutTimeouts)
+	elasticsearch.SetMaxUnassignedShards(int32(maxUnassignedShards))
+	elasticsearch.SetGenerateMissingSecrets(generateMissingSecrets)
+	elasticsearch.SetMasterOnlyResourceDefaults(masterOnlyResourceDefaults)
+	elasticsearch.SetUnschedulableTimeout(unschedulableTimeout)
+	elasticsearch.SetLicenseExpiryWarning(licenseExpiryWarning)
+	tracing.SetEnabled(traceReconciles)
+
 	log.MustInit("elasticsearch-operator")
 	log.Info("starting up...",
 		"operator_version", version.Version,
@@ -63,6 +145,21 @@ func main() {
 		"go_arch", runtime.GOARCH,
 	)
 
+	if err := image.SetMirrors(imageMirrors); err != nil {
+		log.Error(err, "Failed to parse image mirrors")
+		os.Exit(1)
+	}
+
+	if err := elasticsearch.SetReconcileMode(reconcileMode); err != nil {
+		log.Error(err, "Failed to parse reconcile mode")
+		os.Exit(1)
+	}
+
+	if err := metrics.SetReconcileDurationBuckets(reconcileDurationBuckets); err != nil {
+		log.Error(err, "Failed to parse reconcile duration buckets")
+		os.Exit(1)
+	}
+
 	namespace, err := getWatchNamespace()
 	if err != nil {
 		log.Error(err, "Failed to get watch namespace")
@@ -71,7 +168,8 @@ func main() {
 
 	ll := log.WithValues("namespace", namespace)
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	cfg := utils.SetClientRateLimits(ctrl.GetConfigOrDie(), float32(clientQPS), clientBurst)
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
 		Scheme:             scheme,
 		Namespace:          namespace,
 		MetricsBindAddress: fmt.Sprintf(":%d", metricsPort),
@@ -86,9 +184,12 @@ func main() {
 	}
 
 	if err = (&controllers.ElasticsearchReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Elasticsearch"),
-		Scheme: mgr.GetScheme(),
+		Client:          mgr.GetClient(),
+		Log:             ctrl.Log.WithName("controllers").WithName("Elasticsearch"),
+		Scheme:          mgr.GetScheme(),
+		APIReader:       mgr.GetAPIReader(),
+		Recorder:        mgr.GetEventRecorderFor("elasticsearch-operator"),
+		RequeueInterval: requeueInterval,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Elasticsearch")
 		os.Exit(1)
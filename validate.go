@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/elasticsearch"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// validateCommand is the subcommand validating an Elasticsearch CR offline, e.g. in CI pipelines
+const validateCommand = "validate"
+
+// runValidate validates the Elasticsearch CR of the file given as argument, or of stdin
+// for "-", and returns the exit code of the subcommand
+func runValidate(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet(validateCommand, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: %s %s FILE\n\n", os.Args[0], validateCommand)
+		fmt.Fprintln(stderr, "Validates the Elasticsearch CR of the YAML or JSON file, or of stdin for -, with the rules of the spec")
+		fmt.Fprintln(stderr, "the operator enforces without the API server. Exits nonzero if the spec does not pass them.")
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	cluster, err := readElasticsearch(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %s\n", err)
+		return 1
+	}
+
+	result := elasticsearch.ValidateSpec(cluster)
+	for _, message := range result.Errors {
+		fmt.Fprintf(stdout, "error: %s\n", message)
+	}
+	for _, message := range result.Warnings {
+		fmt.Fprintf(stdout, "warning: %s\n", message)
+	}
+	if !result.IsValid() {
+		return 1
+	}
+	return 0
+}
+
+func readElasticsearch(path string) (*loggingv1.Elasticsearch, error) {
+	in := os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	cluster := &loggingv1.Elasticsearch{}
+	if err := yaml.NewYAMLOrJSONDecoder(in, 4096).Decode(cluster); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	if cluster.Kind != "" && cluster.Kind != "Elasticsearch" {
+		return nil, fmt.Errorf("%s holds a %s instead of an Elasticsearch", path, cluster.Kind)
+	}
+	return cluster, nil
+}